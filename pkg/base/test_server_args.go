@@ -19,6 +19,7 @@ package base
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 )
@@ -84,6 +85,12 @@ type TestServerArgs struct {
 
 	// If set, the recording of events to the event log tables is disabled.
 	DisableEventLog bool
+
+	// Clock, if set, is used as the physical clock of the server's HLC instead
+	// of the wall clock, so that time only moves when the test advances it (see
+	// TestServerInterface.AdvanceClock and ExpireLeases). The servers of a
+	// TestCluster share the clock set in TestClusterArgs.ServerArgs.
+	Clock *hlc.ManualClock
 }
 
 // TestClusterArgs contains the parameters one can set when creating a test
@@ -10,6 +10,7 @@ package sqlccl
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"sort"
 	"time"
@@ -31,6 +32,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/interval"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -44,6 +47,11 @@ const (
 	BackupDescriptorName = "BACKUP"
 	// BackupFormatInitialVersion is the first version of backup and its files.
 	BackupFormatInitialVersion uint32 = 0
+
+	// backupOptRevisionHistory makes an incremental backup keep every revision
+	// of the backed up keys, instead of only the latest one, so it can be
+	// restored to any time it covers.
+	backupOptRevisionHistory = "revision_history"
 )
 
 // exportStorageFromURI returns an ExportStorage for the given URI.
@@ -122,6 +130,65 @@ func allSQLDescriptors(ctx context.Context, txn *client.Txn) ([]sqlbase.Descript
 	return sqlDescs, nil
 }
 
+// getDescriptorRevisions returns every revision of the SQL descriptors written
+// in [startTime, endTime), in timestamp order.
+func getDescriptorRevisions(
+	ctx context.Context, db *client.DB, startTime, endTime hlc.Timestamp,
+) ([]BackupDescriptor_DescriptorRevision, error) {
+	prefix := sqlbase.MakeAllDescsMetadataKey()
+	req := &roachpb.ExportRequest{
+		Span:       roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
+		StartTime:  startTime,
+		ReturnSST:  true,
+		MVCCFilter: roachpb.MVCCFilter_All,
+	}
+	res, pErr := client.SendWrappedWith(ctx, db.GetSender(), roachpb.Header{Timestamp: endTime}, req)
+	if pErr != nil {
+		return nil, errors.Wrap(pErr.GoError(), "fetching descriptor revisions")
+	}
+
+	var revisions []BackupDescriptor_DescriptorRevision
+	for _, file := range res.(*roachpb.ExportResponse).Files {
+		if err := func() error {
+			sst := engine.MakeRocksDBSstFileReader()
+			defer sst.Close()
+			if err := sst.IngestExternalFile(file.SST); err != nil {
+				return err
+			}
+			start, end := engine.MVCCKey{Key: keys.MinKey}, engine.MVCCKey{Key: keys.MaxKey}
+			return sst.Iterate(start, end, func(kv engine.MVCCKeyValue) (bool, error) {
+				if !bytes.HasPrefix(kv.Key.Key, prefix) {
+					return false, errors.Errorf("unexpected descriptor key %s", kv.Key)
+				}
+				_, id, err := encoding.DecodeUvarintAscending(kv.Key.Key[len(prefix):])
+				if err != nil {
+					return false, err
+				}
+				revision := BackupDescriptor_DescriptorRevision{
+					Time: kv.Key.Timestamp,
+					ID:   sqlbase.ID(id),
+				}
+				// An empty value is a deletion.
+				if len(kv.Value) > 0 {
+					var desc sqlbase.Descriptor
+					if err := (roachpb.Value{RawBytes: kv.Value}).GetProto(&desc); err != nil {
+						return false, errors.Wrapf(err, "%s: unable to unmarshal SQL descriptor", kv.Key)
+					}
+					revision.Desc = &desc
+				}
+				revisions = append(revisions, revision)
+				return false, nil
+			})
+		}(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Time.Less(revisions[j].Time)
+	})
+	return revisions, nil
+}
+
 func allRangeDescriptors(ctx context.Context, txn *client.Txn) ([]roachpb.RangeDescriptor, error) {
 	rows, err := txn.Scan(ctx, keys.Meta2Prefix, keys.MetaMax, 0)
 	if err != nil {
@@ -264,12 +331,27 @@ func Backup(
 	uri string,
 	targets parser.TargetList,
 	startTime, endTime hlc.Timestamp,
-	_ parser.KVOptions,
+	opts parser.KVOptions,
 	jobLogger *jobs.JobLogger,
 ) (BackupDescriptor, error) {
 	// TODO(dan): Figure out how permissions should work. #6713 is tracking this
 	// for grpc.
 
+	mvccFilter := roachpb.MVCCFilter_Latest
+	if override, ok := opts.Get(backupOptRevisionHistory); ok {
+		if override != "" {
+			return BackupDescriptor{}, errors.Errorf("option %q does not take a value",
+				backupOptRevisionHistory)
+		}
+		// A full backup only has to be restorable to its end time, which the
+		// latest revisions already cover.
+		if startTime == (hlc.Timestamp{}) {
+			return BackupDescriptor{}, errors.Errorf("option %q requires an incremental backup",
+				backupOptRevisionHistory)
+		}
+		mvccFilter = roachpb.MVCCFilter_All
+	}
+
 	var sqlDescs []sqlbase.Descriptor
 
 	exportStore, err := exportStorageFromURI(ctx, uri)
@@ -370,6 +452,7 @@ func Backup(
 	if err := jobLogger.Started(ctx); err != nil {
 		return BackupDescriptor{}, err
 	}
+	defer log.StartActivity(fmt.Sprintf("backup job %d", *jobLogger.JobID()))()
 
 	// We're already limiting these on the server-side, but sending all the
 	// Export requests at once would fill up distsender/grpc/something and cause
@@ -404,9 +487,10 @@ func Backup(
 			defer func() { <-exportsSem }()
 
 			req := &roachpb.ExportRequest{
-				Span:      span,
-				Storage:   exportStore.Conf(),
-				StartTime: startTime,
+				Span:       span,
+				Storage:    exportStore.Conf(),
+				StartTime:  startTime,
+				MVCCFilter: mvccFilter,
 			}
 			res, pErr := client.SendWrappedWith(gCtx, db.GetSender(), header, req)
 			if pErr != nil {
@@ -437,6 +521,15 @@ func Backup(
 	}
 	files, dataSize := mu.files, mu.dataSize // No more concurrency, so this is safe.
 
+	// Restoring to a time within the backup needs the SQL descriptors as they
+	// were at that time.
+	var descChanges []BackupDescriptor_DescriptorRevision
+	if mvccFilter == roachpb.MVCCFilter_All {
+		if descChanges, err = getDescriptorRevisions(ctx, db, startTime, endTime); err != nil {
+			return BackupDescriptor{}, err
+		}
+	}
+
 	desc := BackupDescriptor{
 		StartTime:     startTime,
 		EndTime:       endTime,
@@ -448,6 +541,9 @@ func Backup(
 		BuildInfo:     build.GetInfo(),
 		NodeID:        p.ExecCfg().NodeID.Get(),
 		ClusterID:     p.ExecCfg().ClusterID(),
+
+		MVCCFilter:        mvccFilter,
+		DescriptorChanges: descChanges,
 	}
 	sort.Sort(backupFileDescriptors(desc.Files))
 
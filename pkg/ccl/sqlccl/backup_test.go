@@ -1175,6 +1175,88 @@ func TestBackupAsOfSystemTime(t *testing.T) {
 	}
 }
 
+func TestRestoreAsOfSystemTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, dir, _, sqlDB, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts)
+	defer cleanupFn()
+
+	fullDir, incDir, noRevisionsDir := dir+"/full", dir+"/inc", dir+"/norevisions"
+
+	var ts [4]string
+	sqlDB.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&ts[0])
+	sqlDB.Exec(fmt.Sprintf(`BACKUP DATABASE bench TO '%s' AS OF SYSTEM TIME %s`, fullDir, ts[0]))
+
+	sqlDB.Exec(`UPDATE bench.bank SET balance = 1`)
+	sqlDB.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&ts[1])
+	sqlDB.Exec(`DELETE FROM bench.bank WHERE id >= 5`)
+	sqlDB.Exec(`CREATE TABLE bench.extra (a INT PRIMARY KEY)`)
+	sqlDB.Exec(`INSERT INTO bench.extra VALUES (1)`)
+	sqlDB.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&ts[2])
+	sqlDB.Exec(`UPDATE bench.bank SET balance = 2`)
+	sqlDB.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&ts[3])
+
+	sqlDB.Exec(fmt.Sprintf(
+		`BACKUP DATABASE bench TO '%s' AS OF SYSTEM TIME %s INCREMENTAL FROM '%s'
+		WITH OPTIONS ('revision_history')`, incDir, ts[3], fullDir))
+	sqlDB.Exec(fmt.Sprintf(`BACKUP DATABASE bench TO '%s' AS OF SYSTEM TIME %s INCREMENTAL FROM '%s'`,
+		noRevisionsDir, ts[3], fullDir))
+
+	var expected [len(ts)][2]int64
+	for i := range ts {
+		sqlDB.QueryRow(fmt.Sprintf(
+			`SELECT COUNT(*), COALESCE(SUM(balance), 0) FROM bench.bank AS OF SYSTEM TIME %s`, ts[i],
+		)).Scan(&expected[i][0], &expected[i][1])
+	}
+
+	for i := range ts {
+		t.Run(fmt.Sprintf("ts%d", i), func(t *testing.T) {
+			sqlDB.Exec(`DROP TABLE IF EXISTS bench.bank, bench.extra`)
+			sqlDB.Exec(fmt.Sprintf(`RESTORE bench.* FROM '%s', '%s' AS OF SYSTEM TIME %s`,
+				fullDir, incDir, ts[i]))
+
+			var count, sum int64
+			sqlDB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(balance), 0) FROM bench.bank`).Scan(&count, &sum)
+			if count != expected[i][0] || sum != expected[i][1] {
+				t.Fatalf("expected %d rows with balance %d, got %d rows with balance %d",
+					expected[i][0], expected[i][1], count, sum)
+			}
+
+			// The table created between ts1 and ts2 should only be restored
+			// as of the times after its creation.
+			_, err := sqlDB.DB.Exec(`SELECT * FROM bench.extra`)
+			if exists := err == nil; exists != (i >= 2) {
+				t.Fatalf("expected bench.extra to exist: %t, got error: %v", i >= 2, err)
+			}
+		})
+	}
+
+	var later string
+	sqlDB.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&later)
+	for _, tc := range []struct {
+		from     []string
+		asOf     string
+		expected string
+	}{
+		{[]string{fullDir, incDir}, "'1999-01-01'", "is before the end time"},
+		{[]string{fullDir, incDir}, later, "is after the end time"},
+		{[]string{fullDir, noRevisionsDir}, ts[1], "can only be restored to its end time"},
+	} {
+		sqlDB.Exec(`DROP TABLE IF EXISTS bench.bank, bench.extra`)
+		_, err := sqlDB.DB.Exec(fmt.Sprintf(`RESTORE bench.* FROM '%s' AS OF SYSTEM TIME %s`,
+			strings.Join(tc.from, "', '"), tc.asOf))
+		if !testutils.IsError(err, tc.expected) {
+			t.Errorf("expected error %q, got %v", tc.expected, err)
+		}
+	}
+
+	if _, err := sqlDB.DB.Exec(fmt.Sprintf(`BACKUP DATABASE bench TO '%s' WITH OPTIONS ('revision_history')`,
+		dir+"/fullrevisions")); !testutils.IsError(err, "requires an incremental backup") {
+		t.Fatalf("expected error for a full backup with revision history, got %v", err)
+	}
+}
+
 func TestBackupRestoreChecksum(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
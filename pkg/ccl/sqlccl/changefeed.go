@@ -0,0 +1,561 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package sqlccl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+const (
+	// changefeedOptUpdated, if set, includes the MVCC timestamp of each change
+	// in the emitted value.
+	changefeedOptUpdated = "updated"
+)
+
+var changefeedPollInterval = func() *settings.DurationSetting {
+	s := settings.RegisterNonNegativeDurationSetting(
+		"changefeed.experimental_poll_interval",
+		"polling interval for the tables watched by changefeeds",
+		1*time.Second,
+	)
+	s.Hide()
+	return s
+}()
+
+// changefeed emits the changes made to a set of tables to a sink. It first
+// emits every row as of its initial highwater timestamp, then periodically
+// polls the tables for the changes made after the highwater, emits them in
+// timestamp order and advances the highwater. Once every change up to a
+// highwater has been emitted, a resolved timestamp message is sent to the sink:
+// no change at or before a resolved timestamp is emitted after it.
+//
+// Each row change is emitted with the primary key of the row, as a JSON array,
+// and a JSON object with the row as of the change (null if it was deleted).
+type changefeed struct {
+	db        *client.DB
+	clock     *hlc.Clock
+	jobLogger *jobs.JobLogger
+	sink      changefeedSink
+	tableIDs  []sqlbase.ID
+	updated   bool
+
+	// highwater is the timestamp up to which every change has been emitted.
+	highwater hlc.Timestamp
+}
+
+// run emits the changes to the sink until an error occurs or the node shuts
+// down. Either way, the job is marked as failed: changefeeds are not resumed
+// after they stop.
+func (cf *changefeed) run(ctx context.Context, stopper *stop.Stopper) {
+	ctx = stopper.WithCancel(ctx)
+	defer func() {
+		if err := cf.sink.Close(); err != nil {
+			log.Warningf(ctx, "CHANGEFEED job %d: error closing sink: %+v", *cf.jobLogger.JobID(), err)
+		}
+	}()
+
+	if err := cf.jobLogger.Started(ctx); err != nil {
+		cf.jobLogger.Failed(ctx, err)
+		return
+	}
+
+	err := cf.poll(ctx, hlc.Timestamp{}, cf.highwater)
+	for err == nil {
+		select {
+		case <-stopper.ShouldQuiesce():
+			err = errors.New("node is shutting down")
+			continue
+		case <-time.After(changefeedPollInterval.Get()):
+		}
+		err = cf.poll(ctx, cf.highwater, cf.clock.Now())
+	}
+	log.Errorf(ctx, "CHANGEFEED job %d failed: %+v", *cf.jobLogger.JobID(), err)
+	cf.jobLogger.Failed(ctx, err)
+}
+
+// poll emits the changes made to the watched tables in (start, end], followed
+// by a resolved timestamp message for end, and advances the highwater to end.
+// A zero start emits every row of the tables as of end.
+func (cf *changefeed) poll(ctx context.Context, start, end hlc.Timestamp) error {
+	for _, id := range cf.tableIDs {
+		if err := cf.pollTable(ctx, id, start, end); err != nil {
+			return err
+		}
+	}
+	resolved, err := json.Marshal(struct {
+		Resolved string `json:"resolved"`
+	}{changefeedTimestamp(end)})
+	if err != nil {
+		return err
+	}
+	if err := cf.sink.EmitResolvedTimestamp(ctx, end, resolved); err != nil {
+		return err
+	}
+	cf.highwater = end
+	return nil
+}
+
+func (cf *changefeed) pollTable(ctx context.Context, id sqlbase.ID, start, end hlc.Timestamp) error {
+	var desc *sqlbase.TableDescriptor
+	if err := cf.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(end)
+		var err error
+		desc, err = sqlbase.GetTableDescFromID(ctx, txn, id)
+		return err
+	}); err != nil {
+		return err
+	}
+	if desc.Dropped() {
+		return errors.Errorf("table %q was dropped", desc.Name)
+	}
+
+	// The export returns the latest version of every key changed in (start,
+	// end], including deletion tombstones. Reading the span at end also bumps
+	// the timestamp cache, so a transaction that later writes to the table has
+	// to commit above end and its changes are picked up by the next poll.
+	req := &roachpb.ExportRequest{
+		Span:      desc.PrimaryIndexSpan(),
+		StartTime: start,
+		ReturnSST: true,
+	}
+	res, pErr := client.SendWrappedWith(ctx, cf.db.GetSender(), roachpb.Header{Timestamp: end}, req)
+	if pErr != nil {
+		return errors.Wrapf(pErr.GoError(), "fetching changes to table %q", desc.Name)
+	}
+
+	var kvs []engine.MVCCKeyValue
+	for _, file := range res.(*roachpb.ExportResponse).Files {
+		if err := func() error {
+			sst := engine.MakeRocksDBSstFileReader()
+			defer sst.Close()
+			if err := sst.IngestExternalFile(file.SST); err != nil {
+				return err
+			}
+			start, end := engine.MVCCKey{Key: keys.MinKey}, engine.MVCCKey{Key: keys.MaxKey}
+			return sst.Iterate(start, end, func(kv engine.MVCCKeyValue) (bool, error) {
+				kvs = append(kvs, kv)
+				return false, nil
+			})
+		}(); err != nil {
+			return err
+		}
+	}
+
+	if start == (hlc.Timestamp{}) {
+		// The initial scan emits the rows as of end, even if their column
+		// families were last written at different timestamps.
+		for i := range kvs {
+			kvs[i].Key.Timestamp = end
+		}
+	}
+	return cf.emitChanges(ctx, desc, kvs, start == (hlc.Timestamp{}))
+}
+
+// emitChanges emits the rows changed by the given kvs of the primary index of
+// the table, in timestamp order.
+func (cf *changefeed) emitChanges(
+	ctx context.Context, desc *sqlbase.TableDescriptor, kvs []engine.MVCCKeyValue, initialScan bool,
+) error {
+	// Within a timestamp the kvs are in key order, so the keys of the column
+	// families of a row are adjacent.
+	sort.Slice(kvs, func(i, j int) bool {
+		if kvs[i].Key.Timestamp != kvs[j].Key.Timestamp {
+			return kvs[i].Key.Timestamp.Less(kvs[j].Key.Timestamp)
+		}
+		return kvs[i].Key.Key.Compare(kvs[j].Key.Key) < 0
+	})
+
+	colIdxMap := make(map[sqlbase.ColumnID]int, len(desc.Columns))
+	valNeededForCol := make([]bool, len(desc.Columns))
+	for i, col := range desc.Columns {
+		colIdxMap[col.ID] = i
+		valNeededForCol[i] = true
+	}
+	var rf sqlbase.RowFetcher
+	if err := rf.Init(
+		desc, colIdxMap, &desc.PrimaryIndex, false /* reverse */, false, /* isSecondaryIndex */
+		desc.Columns, valNeededForCol, false, /* returnRangeInfo */
+	); err != nil {
+		return err
+	}
+	keyVals, err := sqlbase.MakeEncodedKeyVals(desc, desc.PrimaryIndex.ColumnIDs)
+	if err != nil {
+		return err
+	}
+	_, colDirs := desc.PrimaryIndex.FullColumnIDs()
+	var alloc sqlbase.DatumAlloc
+
+	for len(kvs) > 0 {
+		ts := kvs[0].Key.Timestamp
+		var rowKeys []roachpb.Key
+		for ; len(kvs) > 0 && kvs[0].Key.Timestamp == ts; kvs = kvs[1:] {
+			kv := kvs[0]
+			if initialScan && len(kv.Value) == 0 {
+				// A deletion tombstone of a row that doesn't exist as of the
+				// initial scan.
+				continue
+			}
+			if _, ok, err := sqlbase.DecodeIndexKey(
+				&alloc, desc, desc.PrimaryIndex.ID, keyVals, colDirs, kv.Key.Key,
+			); err != nil {
+				return err
+			} else if !ok {
+				// Interleaved data of a child table.
+				continue
+			}
+			rowKey, err := keys.EnsureSafeSplitKey(kv.Key.Key)
+			if err != nil {
+				return err
+			}
+			if len(rowKeys) == 0 || !rowKeys[len(rowKeys)-1].Equal(rowKey) {
+				rowKeys = append(rowKeys, rowKey)
+			}
+		}
+		if err := cf.emitRows(ctx, desc, &rf, keyVals, colDirs, ts, rowKeys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitRows reads and emits the given rows as of the timestamp at which they
+// were changed. A row that doesn't exist as of that timestamp was deleted.
+func (cf *changefeed) emitRows(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	rf *sqlbase.RowFetcher,
+	keyVals []sqlbase.EncDatum,
+	colDirs []encoding.Direction,
+	ts hlc.Timestamp,
+	rowKeys []roachpb.Key,
+) error {
+	if len(rowKeys) == 0 {
+		return nil
+	}
+	var rows []parser.Datums
+	if err := cf.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ts)
+		rows = rows[:0]
+		for _, rowKey := range rowKeys {
+			span := roachpb.Span{Key: rowKey, EndKey: rowKey.PrefixEnd()}
+			if err := rf.StartScan(
+				ctx, txn, roachpb.Spans{span}, false /* limitBatches */, 0, /* limitHint */
+			); err != nil {
+				return err
+			}
+			row, err := rf.NextRowDecoded(ctx)
+			if err != nil {
+				return err
+			}
+			if row != nil {
+				// The fetcher reuses the returned row.
+				row = append(parser.Datums(nil), row...)
+			}
+			rows = append(rows, row)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var alloc sqlbase.DatumAlloc
+	for i, row := range rows {
+		if _, _, err := sqlbase.DecodeIndexKey(
+			&alloc, desc, desc.PrimaryIndex.ID, keyVals, colDirs, rowKeys[i],
+		); err != nil {
+			return err
+		}
+		var key bytes.Buffer
+		key.WriteByte('[')
+		for j := range keyVals {
+			if j > 0 {
+				key.WriteString(", ")
+			}
+			if err := keyVals[j].EnsureDecoded(&alloc); err != nil {
+				return err
+			}
+			if err := writeDatumJSON(&key, keyVals[j].Datum); err != nil {
+				return err
+			}
+		}
+		key.WriteByte(']')
+
+		var value bytes.Buffer
+		value.WriteString(`{"after": `)
+		if row == nil {
+			value.WriteString("null")
+		} else {
+			value.WriteByte('{')
+			for j := range desc.Columns {
+				if j > 0 {
+					value.WriteString(", ")
+				}
+				if err := writeJSONString(&value, desc.Columns[j].Name); err != nil {
+					return err
+				}
+				value.WriteString(": ")
+				if err := writeDatumJSON(&value, row[j]); err != nil {
+					return err
+				}
+			}
+			value.WriteByte('}')
+		}
+		if cf.updated {
+			value.WriteString(`, "updated": `)
+			if err := writeJSONString(&value, changefeedTimestamp(ts)); err != nil {
+				return err
+			}
+		}
+		value.WriteByte('}')
+
+		if err := cf.sink.EmitRow(ctx, desc.Name, key.Bytes(), value.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changefeedTimestamp formats a timestamp the way AS OF SYSTEM TIME accepts it.
+func changefeedTimestamp(ts hlc.Timestamp) string {
+	return fmt.Sprintf("%d.%010d", ts.WallTime, ts.Logical)
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+// writeDatumJSON writes the JSON representation of a datum. Numbers that JSON
+// can't represent exactly, like decimals and non-finite floats, are written as
+// strings.
+func writeDatumJSON(buf *bytes.Buffer, d parser.Datum) error {
+	if d == parser.DNull {
+		buf.WriteString("null")
+		return nil
+	}
+	switch t := parser.UnwrapDatum(d).(type) {
+	case *parser.DBool:
+		buf.WriteString(strconv.FormatBool(bool(*t)))
+	case *parser.DInt:
+		buf.WriteString(strconv.FormatInt(int64(*t), 10))
+	case *parser.DFloat:
+		f := float64(*t)
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return writeJSONString(buf, t.String())
+		}
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	case *parser.DJSON:
+		buf.WriteString(t.JSON.String())
+	case *parser.DBytes:
+		encoded, err := json.Marshal([]byte(*t))
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	default:
+		return writeJSONString(buf, parser.AsStringWithFlags(d, parser.FmtBareStrings))
+	}
+	return nil
+}
+
+func changefeedJobDescription(
+	changefeed *parser.CreateChangefeed, sinkURI string,
+) (string, error) {
+	c := parser.CreateChangefeed{
+		Targets: changefeed.Targets,
+		Options: changefeed.Options,
+	}
+	sinkURI, err := sanitizeChangefeedSinkURI(sinkURI)
+	if err != nil {
+		return "", err
+	}
+	c.SinkURI = parser.NewDString(sinkURI)
+	return c.String(), nil
+}
+
+// changefeedTargets returns the descriptors of the tables matching the targets
+// as of the given timestamp.
+func changefeedTargets(
+	ctx context.Context, db *client.DB, ts hlc.Timestamp, targets parser.TargetList,
+) ([]*sqlbase.TableDescriptor, error) {
+	var sqlDescs []sqlbase.Descriptor
+	txn := client.NewTxn(db)
+	opt := client.TxnExecOptions{AutoRetry: true, AutoCommit: true}
+	if err := txn.Exec(ctx, opt, func(ctx context.Context, txn *client.Txn, opt *client.TxnExecOptions) error {
+		var err error
+		txn.SetFixedTimestamp(ts)
+		sqlDescs, err = allSQLDescriptors(ctx, txn)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	// The session database isn't available to plan hooks, so the table names
+	// must be qualified.
+	sqlDescs, err := descriptorsMatchingTargets("", sqlDescs, targets)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*sqlbase.TableDescriptor
+	for _, desc := range sqlDescs {
+		tableDesc := desc.GetTable()
+		if tableDesc == nil {
+			continue
+		}
+		if !tableDesc.IsPhysicalTable() {
+			return nil, errors.Errorf("CHANGEFEED cannot be used with %q: only tables are supported",
+				tableDesc.Name)
+		}
+		if len(tableDesc.PrimaryIndex.Interleave.Ancestors) > 0 {
+			return nil, errors.Errorf("CHANGEFEED cannot be used with interleaved table %q",
+				tableDesc.Name)
+		}
+		tables = append(tables, tableDesc)
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("CHANGEFEED requires at least one table")
+	}
+	return tables, nil
+}
+
+func changefeedPlanHook(
+	baseCtx context.Context, stmt parser.Statement, p sql.PlanHookState,
+) (func() ([]parser.Datums, error), sqlbase.ResultColumns, error) {
+	changefeedStmt, ok := stmt.(*parser.CreateChangefeed)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	if err := utilccl.CheckEnterpriseEnabled("CHANGEFEED"); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.RequireSuperUser("CHANGEFEED"); err != nil {
+		return nil, nil, err
+	}
+
+	sinkURIFn, err := p.TypeAsString(changefeedStmt.SinkURI, "CHANGEFEED")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, opt := range changefeedStmt.Options {
+		if opt.Key != changefeedOptUpdated {
+			return nil, nil, errors.Errorf("unknown CHANGEFEED option %q", opt.Key)
+		}
+		if opt.Value != "" {
+			return nil, nil, errors.Errorf("option %q does not take a value", opt.Key)
+		}
+	}
+	_, updated := changefeedStmt.Options.Get(changefeedOptUpdated)
+
+	header := sqlbase.ResultColumns{
+		{Name: "job_id", Typ: parser.TypeInt},
+	}
+	fn := func() ([]parser.Datums, error) {
+		ctx, span := tracing.ChildSpan(baseCtx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		sinkURI, err := sinkURIFn()
+		if err != nil {
+			return nil, err
+		}
+
+		execCfg := p.ExecCfg()
+		highwater := execCfg.Clock.Now()
+		tables, err := changefeedTargets(ctx, execCfg.DB, highwater, changefeedStmt.Targets)
+		if err != nil {
+			return nil, err
+		}
+		description, err := changefeedJobDescription(changefeedStmt, sinkURI)
+		if err != nil {
+			return nil, err
+		}
+
+		var tableIDs []sqlbase.ID
+		var topics []string
+		seen := make(map[string]struct{}, len(tables))
+		for _, table := range tables {
+			// The sinks name their topics and files after the tables.
+			if _, ok := seen[table.Name]; ok {
+				return nil, errors.Errorf("CHANGEFEED cannot watch multiple tables named %q", table.Name)
+			}
+			seen[table.Name] = struct{}{}
+			tableIDs = append(tableIDs, table.ID)
+			topics = append(topics, table.Name)
+		}
+		// The sink is opened before the job is created so that a misconfigured
+		// sink is reported to the user instead of failing the job later.
+		sink, err := getChangefeedSink(ctx, sinkURI, topics)
+		if err != nil {
+			return nil, err
+		}
+
+		jobLogger := jobs.NewJobLogger(execCfg.DB, sql.InternalExecutor{LeaseManager: p.LeaseMgr()}, jobs.JobRecord{
+			Description:   description,
+			Username:      p.User(),
+			DescriptorIDs: tableIDs,
+			Details:       jobs.ChangefeedJobDetails{},
+		})
+		if err := jobLogger.Created(ctx); err != nil {
+			_ = sink.Close()
+			return nil, err
+		}
+
+		cf := &changefeed{
+			db:        execCfg.DB,
+			clock:     execCfg.Clock,
+			jobLogger: &jobLogger,
+			sink:      sink,
+			tableIDs:  tableIDs,
+			updated:   updated,
+			highwater: highwater,
+		}
+		// The changefeed outlives the statement, so it doesn't use its context.
+		cfCtx := execCfg.AmbientCtx.AnnotateCtx(context.Background())
+		execCfg.Stopper.RunWorker(cfCtx, func(ctx context.Context) {
+			cf.run(ctx, execCfg.Stopper)
+		})
+
+		return []parser.Datums{{parser.NewDInt(parser.DInt(*jobLogger.JobID()))}}, nil
+	}
+	return fn, header, nil
+}
+
+func init() {
+	sql.AddPlanHook(changefeedPlanHook)
+}
@@ -0,0 +1,247 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package sqlccl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+const (
+	changefeedSinkSchemeKafka = "kafka"
+
+	// changefeedSinkParamTopicPrefix is the query parameter of a kafka sink URI
+	// that is prepended to the table names to form the topic names.
+	changefeedSinkParamTopicPrefix = "topic_prefix"
+)
+
+// changefeedSink is an abstraction for anything that a changefeed may emit
+// into.
+type changefeedSink interface {
+	// EmitRow enqueues a row change event for the given table. The event may be
+	// buffered until the next call to EmitResolvedTimestamp.
+	EmitRow(ctx context.Context, table string, key, value []byte) error
+	// EmitResolvedTimestamp flushes every enqueued row change event and then
+	// emits the given resolved timestamp message.
+	EmitResolvedTimestamp(ctx context.Context, resolved hlc.Timestamp, payload []byte) error
+	Close() error
+}
+
+// getChangefeedSink opens the sink at the given URI for a changefeed watching
+// tables with the given names. Kafka sinks use the "kafka" scheme, all other
+// URIs are interpreted as export storage.
+func getChangefeedSink(
+	ctx context.Context, sinkURI string, tables []string,
+) (changefeedSink, error) {
+	uri, err := url.Parse(sinkURI)
+	if err != nil {
+		return nil, err
+	}
+	if uri.Scheme == changefeedSinkSchemeKafka {
+		return makeKafkaSink(uri.Host, uri.Query().Get(changefeedSinkParamTopicPrefix), tables)
+	}
+	es, err := exportStorageFromURI(ctx, sinkURI)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudStorageSink{es: es, rows: make(map[string]*bytes.Buffer)}, nil
+}
+
+// sanitizeChangefeedSinkURI returns the sink URI with sensitive credentials
+// stripped.
+func sanitizeChangefeedSinkURI(sinkURI string) (string, error) {
+	uri, err := url.Parse(sinkURI)
+	if err != nil {
+		return "", err
+	}
+	if uri.Scheme == changefeedSinkSchemeKafka {
+		return sinkURI, nil
+	}
+	return storageccl.SanitizeExportStorageURI(sinkURI)
+}
+
+// kafkaSink emits to kafka, with one topic per table. Row messages are keyed
+// by the primary key of the row, so all the changes to a row land in the same
+// partition, in order. Resolved timestamp messages have no key and are sent
+// to every partition of every topic.
+type kafkaSink struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	topics   map[string]string
+
+	// pending holds the row messages enqueued since the last resolved
+	// timestamp.
+	pending []*sarama.ProducerMessage
+}
+
+func makeKafkaSink(broker string, topicPrefix string, tables []string) (*kafkaSink, error) {
+	sink := &kafkaSink{topics: make(map[string]string, len(tables))}
+	for _, table := range tables {
+		sink.topics[table] = topicPrefix + table
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = newChangefeedPartitioner
+
+	var err error
+	sink.client, err = sarama.NewClient(strings.Split(broker, ","), config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to kafka: %s", broker)
+	}
+	sink.producer, err = sarama.NewSyncProducerFromClient(sink.client)
+	if err != nil {
+		_ = sink.client.Close()
+		return nil, errors.Wrapf(err, "connecting to kafka: %s", broker)
+	}
+	return sink, nil
+}
+
+// EmitRow implements the changefeedSink interface.
+func (s *kafkaSink) EmitRow(_ context.Context, table string, key, value []byte) error {
+	topic, ok := s.topics[table]
+	if !ok {
+		return errors.Errorf("cannot emit to undeclared table: %s", table)
+	}
+	s.pending = append(s.pending, &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.ByteEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	return nil
+}
+
+// EmitResolvedTimestamp implements the changefeedSink interface.
+func (s *kafkaSink) EmitResolvedTimestamp(
+	_ context.Context, _ hlc.Timestamp, payload []byte,
+) error {
+	msgs := s.pending
+	for _, topic := range s.topics {
+		partitions, err := s.client.Partitions(topic)
+		if err != nil {
+			return err
+		}
+		for _, partition := range partitions {
+			msgs = append(msgs, &sarama.ProducerMessage{
+				Topic:     topic,
+				Partition: partition,
+				Value:     sarama.ByteEncoder(payload),
+			})
+		}
+	}
+	if err := s.producer.SendMessages(msgs); err != nil {
+		return errors.Wrap(err, "emitting to kafka")
+	}
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// Close implements the changefeedSink interface.
+func (s *kafkaSink) Close() error {
+	if err := s.producer.Close(); err != nil {
+		_ = s.client.Close()
+		return err
+	}
+	return s.client.Close()
+}
+
+// changefeedPartitioner hashes the key of row messages to pick their
+// partition and respects the partition set on resolved timestamp messages,
+// which have no key.
+type changefeedPartitioner struct {
+	hash sarama.Partitioner
+}
+
+var _ sarama.Partitioner = &changefeedPartitioner{}
+
+func newChangefeedPartitioner(topic string) sarama.Partitioner {
+	return &changefeedPartitioner{hash: sarama.NewHashPartitioner(topic)}
+}
+
+func (p *changefeedPartitioner) RequiresConsistency() bool { return true }
+
+func (p *changefeedPartitioner) Partition(
+	message *sarama.ProducerMessage, numPartitions int32,
+) (int32, error) {
+	if message.Key == nil {
+		return message.Partition, nil
+	}
+	return p.hash.Partition(message, numPartitions)
+}
+
+// cloudStorageSink emits to files in export storage. The row messages
+// enqueued before a resolved timestamp are written to one newline delimited
+// JSON file per table, followed by a file with the resolved timestamp message.
+// The files are named after the resolved timestamp, so listing them in
+// lexicographic order lists them in the order they were emitted:
+//
+//   <resolved>-<table>.ndjson
+//   <resolved>.RESOLVED
+//
+// Each line of a table file is a JSON object with the key and the value of a
+// row message.
+type cloudStorageSink struct {
+	es storageccl.ExportStorage
+
+	// rows holds the row messages enqueued for each table since the last
+	// resolved timestamp.
+	rows map[string]*bytes.Buffer
+}
+
+// EmitRow implements the changefeedSink interface.
+func (s *cloudStorageSink) EmitRow(_ context.Context, table string, key, value []byte) error {
+	buf, ok := s.rows[table]
+	if !ok {
+		buf = &bytes.Buffer{}
+		s.rows[table] = buf
+	}
+	fmt.Fprintf(buf, `{"key": %s, "value": %s}`+"\n", key, value)
+	return nil
+}
+
+// EmitResolvedTimestamp implements the changefeedSink interface.
+func (s *cloudStorageSink) EmitResolvedTimestamp(
+	ctx context.Context, resolved hlc.Timestamp, payload []byte,
+) error {
+	// Zero padding both parts of the timestamp makes the names sort in
+	// timestamp order.
+	prefix := fmt.Sprintf("%019d%010d", resolved.WallTime, resolved.Logical)
+
+	tables := make([]string, 0, len(s.rows))
+	for table, buf := range s.rows {
+		if buf.Len() > 0 {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		buf := s.rows[table]
+		filename := fmt.Sprintf("%s-%s.ndjson", prefix, table)
+		if err := s.es.WriteFile(ctx, filename, bytes.NewReader(buf.Bytes())); err != nil {
+			return err
+		}
+		buf.Reset()
+	}
+	return s.es.WriteFile(ctx, prefix+".RESOLVED", bytes.NewReader(payload))
+}
+
+// Close implements the changefeedSink interface.
+func (s *cloudStorageSink) Close() error {
+	return s.es.Close()
+}
@@ -0,0 +1,169 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package sqlccl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// readChangefeedFiles returns the row messages written by a cloud storage
+// sink into dir, in the order they were emitted, and the number of resolved
+// timestamp files.
+func readChangefeedFiles(dir string) ([]string, int, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	var rows []string
+	var resolved int
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, 0, err
+		}
+		switch {
+		case strings.HasSuffix(file.Name(), ".RESOLVED"):
+			var msg map[string]string
+			if err := json.Unmarshal(contents, &msg); err != nil {
+				return nil, 0, errors.Wrapf(err, "%s: %s", file.Name(), contents)
+			}
+			if msg["resolved"] == "" {
+				return nil, 0, errors.Errorf("%s: missing resolved timestamp: %s", file.Name(), contents)
+			}
+			resolved++
+		case strings.HasSuffix(file.Name(), ".ndjson"):
+			for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+				var msg interface{}
+				if err := json.Unmarshal([]byte(line), &msg); err != nil {
+					return nil, 0, errors.Wrapf(err, "%s: %s", file.Name(), line)
+				}
+				rows = append(rows, line)
+			}
+		default:
+			return nil, 0, errors.Errorf("unexpected file: %s", file.Name())
+		}
+	}
+	return rows, resolved, nil
+}
+
+func TestChangefeedCloudStorageSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+	sqlDB.Exec(`SET CLUSTER SETTING changefeed.experimental_poll_interval = '10ms'`)
+	sqlDB.Exec(`CREATE DATABASE d`)
+	sqlDB.Exec(`CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(`INSERT INTO d.foo VALUES (1, 'a'), (2, 'b')`)
+
+	var jobID int64
+	sqlDB.QueryRow(`CREATE CHANGEFEED FOR d.foo INTO $1`, "nodelocal://"+dir).Scan(&jobID)
+	var status string
+	sqlDB.QueryRow(`SELECT status FROM crdb_internal.jobs WHERE id = $1`, jobID).Scan(&status)
+	if status != "running" {
+		t.Fatalf("expected the changefeed job to be running, got %s", status)
+	}
+
+	sqlDB.Exec(`UPDATE d.foo SET b = 'c' WHERE a = 1`)
+	sqlDB.Exec(`DELETE FROM d.foo WHERE a = 2`)
+
+	expected := []string{
+		`{"key": [1], "value": {"after": {"a": 1, "b": "a"}}}`,
+		`{"key": [2], "value": {"after": {"a": 2, "b": "b"}}}`,
+		`{"key": [1], "value": {"after": {"a": 1, "b": "c"}}}`,
+		`{"key": [2], "value": {"after": null}}`,
+	}
+	testutils.SucceedsSoon(t, func() error {
+		rows, resolved, err := readChangefeedFiles(dir)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(rows, expected) {
+			return errors.Errorf("expected rows:\n%s\ngot:\n%s",
+				strings.Join(expected, "\n"), strings.Join(rows, "\n"))
+		}
+		if resolved == 0 {
+			return errors.New("expected a resolved timestamp file")
+		}
+		return nil
+	})
+}
+
+func TestChangefeedErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+	sqlDB.Exec(`CREATE DATABASE d`)
+	sqlDB.Exec(`CREATE TABLE d.foo (a INT PRIMARY KEY)`)
+	sqlDB.Exec(`CREATE VIEW d.v AS SELECT a FROM d.foo`)
+
+	for _, tc := range []struct {
+		stmt     string
+		expected string
+	}{
+		{`CREATE CHANGEFEED FOR d.foo INTO 'nodelocal:///tmp' WITH OPTIONS ('bar')`,
+			`unknown CHANGEFEED option "bar"`},
+		{`CREATE CHANGEFEED FOR d.foo INTO 'nodelocal:///tmp' WITH OPTIONS ('updated'='yes')`,
+			`option "updated" does not take a value`},
+		{`CREATE CHANGEFEED FOR d.v INTO 'nodelocal:///tmp'`,
+			`CHANGEFEED cannot be used with "v": only tables are supported`},
+	} {
+		if _, err := db.Exec(tc.stmt); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%s: expected error %q, got %v", tc.stmt, tc.expected, err)
+		}
+	}
+}
+
+func TestWriteDatumJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		datum    parser.Datum
+		expected string
+	}{
+		{parser.DNull, `null`},
+		{parser.DBoolTrue, `true`},
+		{parser.NewDInt(-7), `-7`},
+		{parser.NewDFloat(1.5), `1.5`},
+		{parser.NewDString(`a"b`), `"a\"b"`},
+		{parser.NewDBytes("\x00\x01"), `"AAE="`},
+	} {
+		var buf bytes.Buffer
+		if err := writeDatumJSON(&buf, tc.datum); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.datum, tc.expected, buf.String())
+		}
+	}
+}
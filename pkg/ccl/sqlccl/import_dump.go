@@ -0,0 +1,727 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package sqlccl
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+const (
+	importFormatPgDump    = "PGDUMP"
+	importFormatMySQLDump = "MYSQLDUMP"
+
+	// importOptIntoDB names the database the tables of the dump are created
+	// in. It is required.
+	importOptIntoDB = "into_db"
+	// importOptTemp is the export storage URI the SSTables converted from the
+	// dump are written to before they are ingested. It is required.
+	importOptTemp = "temp"
+	// importOptSkipFKs makes IMPORT drop the foreign keys of the dump instead
+	// of failing.
+	importOptSkipFKs = "skip_foreign_keys"
+)
+
+var importOptions = map[string]bool{
+	importOptIntoDB:  true,
+	importOptTemp:    true,
+	importOptSkipFKs: false,
+}
+
+// The tables of a dump are converted with placeholder IDs, which only need to
+// be unique among themselves since RESTORE assigns them new IDs and rewrites
+// their data accordingly.
+const importDatabaseID = sqlbase.ID(keys.MaxReservedDescID + 1)
+
+// dumpTable is a table created by a dump.
+type dumpTable struct {
+	name   string
+	create *parser.CreateTable
+	desc   *sqlbase.TableDescriptor
+	kvs    []engine.MVCCKeyValue
+	// converters caches the row converters of the table by the list of
+	// columns given by the COPY and INSERT statements of its data.
+	converters map[string]*dumpRowConverter
+}
+
+// dumpImporter converts the statements of a dump into table descriptors and
+// the KVs of their rows.
+//
+// A dump is read twice: the schema of its tables is collected from the
+// first pass, since dumps commonly add the primary keys, indexes and
+// constraints of a table after its data, and the data is converted in the
+// second. The KVs of the tables are held in memory until they are written to
+// SSTables, so the size of the imported data is limited by the memory of the
+// node running IMPORT.
+type dumpImporter struct {
+	dialect  dumpDialect
+	database string
+	skipFKs  bool
+	evalCtx  parser.EvalContext
+
+	tables     map[string]*dumpTable
+	tableNames []string
+}
+
+func makeDumpImporter(
+	dialect dumpDialect, database string, skipFKs bool, ts hlc.Timestamp,
+) *dumpImporter {
+	curTime := time.Unix(0, ts.WallTime).UTC()
+	d := &dumpImporter{
+		dialect:  dialect,
+		database: database,
+		skipFKs:  skipFKs,
+		tables:   make(map[string]*dumpTable),
+	}
+	d.evalCtx.SetTxnTimestamp(curTime)
+	d.evalCtx.SetStmtTimestamp(curTime)
+	return d
+}
+
+// forEachStatement calls fn with each statement of the dump.
+func (d *dumpImporter) forEachStatement(
+	r io.Reader, fn func(stmt parser.Statement, dr *dumpReader) error,
+) error {
+	dr := makeDumpReader(r, d.dialect)
+	for {
+		text, err := dr.nextStatement()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading dump")
+		}
+		if text == "" {
+			continue
+		}
+		stmt, err := parser.ParseOne(text)
+		if err != nil {
+			return errors.Wrapf(err, "parsing: %q", text)
+		}
+		if err := fn(stmt, &dr); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *dumpImporter) table(name parser.NormalizableTableName) (*dumpTable, error) {
+	tn, err := name.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	table, ok := d.tables[tn.Table()]
+	if !ok {
+		return nil, errors.Errorf("table %s is not created by the dump", tn.Table())
+	}
+	return table, nil
+}
+
+// readSchema collects the tables created by a dump, along with the indexes
+// and constraints added to them by later statements.
+func (d *dumpImporter) readSchema(r io.Reader) error {
+	return d.forEachStatement(r, func(stmt parser.Statement, dr *dumpReader) error {
+		switch s := stmt.(type) {
+		case *parser.CreateTable:
+			if s.As() {
+				return errors.Errorf("CREATE TABLE ... AS is not supported: %s", s)
+			}
+			if s.Interleave != nil {
+				return errors.Errorf("interleaved tables are not supported: %s", s)
+			}
+			tn, err := s.Table.Normalize()
+			if err != nil {
+				return err
+			}
+			name := tn.Table()
+			if _, ok := d.tables[name]; ok {
+				return errors.Errorf("duplicate CREATE TABLE for %s", name)
+			}
+			// The tables are created in the target database, whatever the
+			// schema or database they were dumped from.
+			s.Table = parser.NormalizableTableName{TableNameReference: &parser.TableName{
+				DatabaseName: parser.Name(d.database), TableName: parser.Name(name),
+			}}
+			defs := s.Defs[:0]
+			for _, def := range s.Defs {
+				if col, ok := def.(*parser.ColumnTableDef); ok && col.References.Table.TableNameReference != nil {
+					if !d.skipFKs {
+						return d.fkError(name)
+					}
+					col.References.Table = parser.NormalizableTableName{}
+					col.References.Col = ""
+					col.References.ConstraintName = ""
+				}
+				if _, ok := def.(*parser.ForeignKeyConstraintTableDef); ok {
+					if !d.skipFKs {
+						return d.fkError(name)
+					}
+					continue
+				}
+				defs = append(defs, def)
+			}
+			s.Defs = defs
+			d.tables[name] = &dumpTable{
+				name:       name,
+				create:     s,
+				converters: make(map[string]*dumpRowConverter),
+			}
+			d.tableNames = append(d.tableNames, name)
+
+		case *parser.AlterTable:
+			table, err := d.table(s.Table)
+			if err != nil {
+				return err
+			}
+			for _, cmd := range s.Cmds {
+				switch c := cmd.(type) {
+				case *parser.AlterTableAddConstraint:
+					if _, ok := c.ConstraintDef.(*parser.ForeignKeyConstraintTableDef); ok {
+						if !d.skipFKs {
+							return d.fkError(table.name)
+						}
+						continue
+					}
+					table.create.Defs = append(table.create.Defs, c.ConstraintDef)
+				case *parser.AlterTableSetDefault:
+					col, err := findDumpColumnDef(table.create, c.Column)
+					if err != nil {
+						return err
+					}
+					col.DefaultExpr.Expr = c.Default
+				default:
+					return errors.Errorf("unsupported ALTER TABLE command: %s", stmt)
+				}
+			}
+
+		case *parser.CreateIndex:
+			table, err := d.table(s.Table)
+			if err != nil {
+				return err
+			}
+			idx := parser.IndexTableDef{
+				Name:     s.Name,
+				Columns:  s.Columns,
+				Storing:  s.Storing,
+				Inverted: s.Inverted,
+			}
+			if s.Unique {
+				table.create.Defs = append(table.create.Defs, &parser.UniqueConstraintTableDef{IndexTableDef: idx})
+			} else {
+				table.create.Defs = append(table.create.Defs, &idx)
+			}
+
+		case *parser.CopyFrom:
+			// The data is converted by the second pass.
+			for {
+				row, err := dr.nextCopyRow()
+				if err != nil {
+					return errors.Wrapf(err, "reading data of %s", s)
+				}
+				if row == nil {
+					break
+				}
+			}
+
+		case *parser.Insert:
+			// The data is converted by the second pass.
+
+		default:
+			return errors.Errorf("unsupported statement in dump: %s", stmt)
+		}
+		return nil
+	})
+}
+
+func (d *dumpImporter) fkError(table string) error {
+	return errors.Errorf("foreign keys are not supported, as found on table %s; "+
+		"use the %q option to import the tables without them", table, importOptSkipFKs)
+}
+
+func findDumpColumnDef(create *parser.CreateTable, name parser.Name) (*parser.ColumnTableDef, error) {
+	for _, def := range create.Defs {
+		if col, ok := def.(*parser.ColumnTableDef); ok && parser.ReNormalizeName(string(col.Name)) == parser.ReNormalizeName(string(name)) {
+			return col, nil
+		}
+	}
+	return nil, errors.Errorf("column %q does not exist in table %s", name, parser.AsString(&create.Table))
+}
+
+// makeTableDescs creates the descriptors of the tables of a dump.
+func (d *dumpImporter) makeTableDescs(ctx context.Context) (BackupDescriptor, error) {
+	dbDesc := &sqlbase.DatabaseDescriptor{
+		Name:       d.database,
+		ID:         importDatabaseID,
+		Privileges: sqlbase.NewDefaultPrivilegeDescriptor(),
+	}
+	backup := BackupDescriptor{
+		Descriptors: []sqlbase.Descriptor{*sqlbase.WrapDescriptor(dbDesc)},
+	}
+	for i, name := range d.tableNames {
+		table := d.tables[name]
+		// A nil txn is safe because it is only used by sql.MakeTableDesc, which
+		// only uses txn for resolving FKs and interleaved tables, neither of which
+		// are present here.
+		var txn *client.Txn
+		affected := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+		desc, err := sql.MakeTableDesc(
+			ctx, txn, sql.NilVirtualTabler, nil, table.create, dbDesc.ID, dbDesc.ID+1+sqlbase.ID(i),
+			dbDesc.Privileges, affected, dbDesc.Name, &d.evalCtx,
+		)
+		if err != nil {
+			return BackupDescriptor{}, errors.Wrapf(err, "creating table %s", name)
+		}
+		table.desc = &desc
+		backup.Descriptors = append(backup.Descriptors, *sqlbase.WrapDescriptor(table.desc))
+	}
+	return backup, nil
+}
+
+// readData converts the rows of the COPY and INSERT statements of a dump into
+// the KVs of their tables.
+func (d *dumpImporter) readData(ctx context.Context, r io.Reader) error {
+	return d.forEachStatement(r, func(stmt parser.Statement, dr *dumpReader) error {
+		switch s := stmt.(type) {
+		case *parser.CopyFrom:
+			table, err := d.table(s.Table)
+			if err != nil {
+				return err
+			}
+			conv, err := d.converter(table, s.Columns)
+			if err != nil {
+				return err
+			}
+			for {
+				fields, err := dr.nextCopyRow()
+				if err != nil {
+					return errors.Wrapf(err, "reading data of %s", s)
+				}
+				if fields == nil {
+					return nil
+				}
+				if len(fields) != len(conv.cols) {
+					return errors.Errorf("expected %d values, got %d: %q", len(conv.cols), len(fields), fields)
+				}
+				row := make(parser.Datums, len(fields))
+				for i, field := range fields {
+					if field == `\N` {
+						row[i] = parser.DNull
+						continue
+					}
+					if row[i], err = sql.ParseCopyDatum(field, conv.cols[i].Type.ToDatumType(), time.UTC); err != nil {
+						return errors.Wrapf(err, "parsing %q for column %s", field, conv.cols[i].Name)
+					}
+				}
+				if err := conv.convert(ctx, row, &d.evalCtx); err != nil {
+					return err
+				}
+			}
+
+		case *parser.Insert:
+			tableExpr := s.Table
+			if aliased, ok := tableExpr.(*parser.AliasedTableExpr); ok {
+				tableExpr = aliased.Expr
+			}
+			tableName, ok := tableExpr.(*parser.NormalizableTableName)
+			if !ok {
+				return errors.Errorf("unsupported INSERT target: %s", s)
+			}
+			table, err := d.table(*tableName)
+			if err != nil {
+				return err
+			}
+			conv, err := d.converter(table, s.Columns)
+			if err != nil {
+				return err
+			}
+			return d.convertInsert(ctx, conv, s)
+		}
+		return nil
+	})
+}
+
+func (d *dumpImporter) convertInsert(
+	ctx context.Context, conv *dumpRowConverter, stmt *parser.Insert,
+) error {
+	if stmt.OnConflict != nil || parser.HasReturningClause(stmt.Returning) ||
+		stmt.Rows.Limit != nil || stmt.Rows.OrderBy != nil {
+		return errors.Errorf("unsupported INSERT: %s", stmt)
+	}
+	values, ok := stmt.Rows.Select.(*parser.ValuesClause)
+	if !ok {
+		return errors.Errorf("expected VALUES clause: %s", stmt)
+	}
+	for _, tuple := range values.Tuples {
+		if len(tuple.Exprs) != len(conv.cols) {
+			return errors.Errorf("expected %d values, got %d: %s", len(conv.cols), len(tuple.Exprs), tuple)
+		}
+		row := make(parser.Datums, len(tuple.Exprs))
+		for i, expr := range tuple.Exprs {
+			if expr == parser.DNull {
+				row[i] = parser.DNull
+				continue
+			}
+			typedExpr, err := parser.TypeCheckAndRequire(expr, nil, conv.cols[i].Type.ToDatumType(), "IMPORT")
+			if err != nil {
+				return err
+			}
+			if row[i], err = typedExpr.Eval(&d.evalCtx); err != nil {
+				return err
+			}
+		}
+		if err := conv.convert(ctx, row, &d.evalCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpRowConverter converts the rows of a table, given for a list of its
+// columns, into KVs.
+type dumpRowConverter struct {
+	table        *dumpTable
+	cols         []sqlbase.ColumnDescriptor
+	insertCols   []sqlbase.ColumnDescriptor
+	defaultExprs []parser.TypedExpr
+	ri           sqlbase.RowInserter
+}
+
+// converter returns the row converter for the given columns of a table, or
+// its visible columns if none are given.
+func (d *dumpImporter) converter(
+	table *dumpTable, names parser.UnresolvedNames,
+) (*dumpRowConverter, error) {
+	key := parser.AsString(names)
+	if conv, ok := table.converters[key]; ok {
+		return conv, nil
+	}
+	conv := &dumpRowConverter{table: table}
+	if names == nil {
+		conv.cols = table.desc.VisibleColumns()
+	} else {
+		for _, n := range names {
+			c, err := n.NormalizeUnqualifiedColumnItem()
+			if err != nil {
+				return nil, err
+			}
+			col, err := table.desc.FindActiveColumnByName(c.ColumnName)
+			if err != nil {
+				return nil, err
+			}
+			conv.cols = append(conv.cols, col)
+		}
+	}
+	var err error
+	parse := parser.Parser{}
+	conv.insertCols, conv.defaultExprs, err = sqlbase.ProcessDefaultColumns(
+		conv.cols, table.desc, &parse, &d.evalCtx,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "process default columns")
+	}
+	conv.ri, err = sqlbase.MakeRowInserter(nil /* txn */, table.desc, nil /* fkTables */, conv.insertCols, false /* checkFKs */)
+	if err != nil {
+		return nil, errors.Wrap(err, "make row inserter")
+	}
+	table.converters[key] = conv
+	return conv, nil
+}
+
+func (c *dumpRowConverter) convert(
+	ctx context.Context, row parser.Datums, evalCtx *parser.EvalContext,
+) error {
+	row, err := sql.GenerateInsertRow(
+		c.defaultExprs, nil /* computeExprs */, c.ri.InsertColIDtoRowIndex, c.insertCols, nil, /* computedCols */
+		*evalCtx, c.table.desc, row, nil, /* computedValues */
+	)
+	if err != nil {
+		return errors.Wrapf(err, "process insert %q", row)
+	}
+	b := inserter(func(kv roachpb.KeyValue) {
+		c.table.kvs = append(c.table.kvs, engine.MVCCKeyValue{
+			Key:   engine.MVCCKey{Key: kv.Key},
+			Value: kv.Value.RawBytes,
+		})
+	})
+	if err := c.ri.InsertRow(ctx, b, row, true /* ignoreConflicts */); err != nil {
+		return errors.Wrapf(err, "insert %q", row)
+	}
+	return nil
+}
+
+// writeSSTs sorts the KVs of each table and writes them to SSTables of at
+// most chunkBytes, adding them to the backup descriptor.
+func (d *dumpImporter) writeSSTs(
+	ctx context.Context,
+	backup *BackupDescriptor,
+	es storageccl.ExportStorage,
+	chunkBytes int64,
+	ts hlc.Timestamp,
+) error {
+	for _, name := range d.tableNames {
+		kvs := d.tables[name].kvs
+		sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key.Less(kvs[j].Key) })
+		var chunkStart int
+		var size int64
+		for i, kv := range kvs {
+			if i > 0 && kvs[i-1].Key.Key.Equal(kv.Key.Key) {
+				return errors.Errorf("duplicate key in table %s: %s", name, kv.Key.Key)
+			}
+			size += int64(len(kv.Key.Key) + len(kv.Value))
+			if size > chunkBytes {
+				if err := writeSST(ctx, backup, es, "", kvs[chunkStart:i+1], ts); err != nil {
+					return errors.Wrap(err, "writeSST")
+				}
+				chunkStart, size = i+1, 0
+			}
+		}
+		if chunkStart < len(kvs) {
+			if err := writeSST(ctx, backup, es, "", kvs[chunkStart:], ts); err != nil {
+				return errors.Wrap(err, "writeSST")
+			}
+		}
+		d.tables[name].kvs = nil
+	}
+	return nil
+}
+
+// openDumpFile opens the dump file at the given export storage URI.
+func openDumpFile(ctx context.Context, file string) (io.ReadCloser, func(), error) {
+	uri, err := url.Parse(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	basename := path.Base(uri.Path)
+	uri.Path = path.Dir(uri.Path)
+	es, err := exportStorageFromURI(ctx, uri.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := es.ReadFile(ctx, basename)
+	if err != nil {
+		_ = es.Close()
+		return nil, nil, errors.Wrapf(err, "reading %s", basename)
+	}
+	return r, func() {
+		_ = r.Close()
+		_ = es.Close()
+	}, nil
+}
+
+// importDump converts the dump file written by another database at the given
+// export storage URI into SSTables in the temp export storage, which are then
+// ingested with RESTORE, creating the tables of the dump in the database into.
+func importDump(
+	ctx context.Context,
+	p sql.PlanHookState,
+	file string,
+	dialect dumpDialect,
+	into, temp string,
+	skipFKs bool,
+	jobLogger *jobs.JobLogger,
+) (dataSize int64, err error) {
+	ts := p.ExecCfg().Clock.Now()
+	d := makeDumpImporter(dialect, into, skipFKs, ts)
+
+	tempStore, err := exportStorageFromURI(ctx, temp)
+	if err != nil {
+		return 0, err
+	}
+	defer tempStore.Close()
+	if r, err := tempStore.ReadFile(ctx, BackupDescriptorName); err == nil {
+		r.Close()
+		return 0, errors.Errorf("a %s file already appears to exist in %s", BackupDescriptorName, temp)
+	}
+
+	// The dump is read twice, see dumpImporter.
+	r, closeFn, err := openDumpFile(ctx, file)
+	if err != nil {
+		return 0, err
+	}
+	err = d.readSchema(r)
+	closeFn()
+	if err != nil {
+		return 0, err
+	}
+	if len(d.tableNames) == 0 {
+		return 0, errors.Errorf("no tables found in %s", file)
+	}
+	backup, err := d.makeTableDescs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	r, closeFn, err = openDumpFile(ctx, file)
+	if err != nil {
+		return 0, err
+	}
+	err = d.readData(ctx, r)
+	closeFn()
+	if err != nil {
+		return 0, err
+	}
+	chunkBytes := config.DefaultZoneConfig().RangeMaxBytes / 2
+	if err := d.writeSSTs(ctx, &backup, tempStore, chunkBytes, ts); err != nil {
+		return 0, err
+	}
+	backup.EndTime = ts
+	descBuf, err := backup.Marshal()
+	if err != nil {
+		return 0, errors.Wrap(err, "marshal backup descriptor")
+	}
+	if err := tempStore.WriteFile(ctx, BackupDescriptorName, bytes.NewReader(descBuf)); err != nil {
+		return 0, errors.Wrap(err, "uploading backup descriptor")
+	}
+
+	targets := parser.TargetList{
+		Tables: parser.TablePatterns{&parser.AllTablesSelector{Database: parser.Name(into)}},
+	}
+	return Restore(ctx, p, []string{temp}, targets, hlc.Timestamp{}, nil /* opt */, jobLogger)
+}
+
+func importJobDescription(stmt *parser.Import, file, temp string) (string, error) {
+	i := parser.Import{
+		FileFormat: stmt.FileFormat,
+		Options:    make(parser.KVOptions, len(stmt.Options)),
+	}
+	sf, err := storageccl.SanitizeExportStorageURI(file)
+	if err != nil {
+		return "", err
+	}
+	i.File = parser.NewDString(sf)
+	for j, opt := range stmt.Options {
+		if opt.Key == importOptTemp {
+			if opt.Value, err = storageccl.SanitizeExportStorageURI(temp); err != nil {
+				return "", err
+			}
+		}
+		i.Options[j] = opt
+	}
+	return i.String(), nil
+}
+
+func importPlanHook(
+	baseCtx context.Context, stmt parser.Statement, p sql.PlanHookState,
+) (func() ([]parser.Datums, error), sqlbase.ResultColumns, error) {
+	importStmt, ok := stmt.(*parser.Import)
+	if !ok {
+		return nil, nil, nil
+	}
+	if err := utilccl.CheckEnterpriseEnabled("IMPORT"); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.RequireSuperUser("IMPORT"); err != nil {
+		return nil, nil, err
+	}
+
+	var dialect dumpDialect
+	switch importStmt.FileFormat {
+	case importFormatPgDump:
+		dialect = pgDumpDialect{}
+	case importFormatMySQLDump:
+		dialect = mysqlDumpDialect{}
+	default:
+		return nil, nil, errors.Errorf("unsupported import format: %s", importStmt.FileFormat)
+	}
+	for _, opt := range importStmt.Options {
+		takesValue, ok := importOptions[opt.Key]
+		if !ok {
+			return nil, nil, errors.Errorf("unknown IMPORT option %q", opt.Key)
+		}
+		if takesValue != (opt.Value != "") {
+			if takesValue {
+				return nil, nil, errors.Errorf("option %q requires a value", opt.Key)
+			}
+			return nil, nil, errors.Errorf("option %q does not take a value", opt.Key)
+		}
+	}
+	into, ok := importStmt.Options.Get(importOptIntoDB)
+	if !ok {
+		return nil, nil, errors.Errorf("IMPORT requires the %q option", importOptIntoDB)
+	}
+	temp, ok := importStmt.Options.Get(importOptTemp)
+	if !ok {
+		return nil, nil, errors.Errorf("IMPORT requires the %q option", importOptTemp)
+	}
+	_, skipFKs := importStmt.Options.Get(importOptSkipFKs)
+
+	fileFn, err := p.TypeAsString(importStmt.File, "IMPORT")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "job_id", Typ: parser.TypeInt},
+		{Name: "status", Typ: parser.TypeString},
+		{Name: "fraction_completed", Typ: parser.TypeFloat},
+		{Name: "bytes", Typ: parser.TypeInt},
+	}
+	fn := func() ([]parser.Datums, error) {
+		ctx, span := tracing.ChildSpan(baseCtx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		file, err := fileFn()
+		if err != nil {
+			return nil, err
+		}
+		description, err := importJobDescription(importStmt, file, temp)
+		if err != nil {
+			return nil, err
+		}
+		jobLogger := jobs.NewJobLogger(p.ExecCfg().DB, sql.InternalExecutor{LeaseManager: p.LeaseMgr()}, jobs.JobRecord{
+			Description: description,
+			Username:    p.User(),
+			Details:     jobs.ImportJobDetails{},
+		})
+		dataSize, err := importDump(ctx, p, file, dialect, into, temp, skipFKs, &jobLogger)
+		if err != nil {
+			jobLogger.Failed(ctx, err)
+			return nil, err
+		}
+		if err := jobLogger.Succeeded(ctx); err != nil {
+			// An error while marking the job as successful is not important enough to
+			// merit failing the entire import.
+			log.Errorf(ctx, "IMPORT ignoring error while marking job %d (%s) as successful: %+v",
+				jobLogger.JobID(), description, err)
+		}
+		return []parser.Datums{{
+			parser.NewDInt(parser.DInt(*jobLogger.JobID())),
+			parser.NewDString(string(jobs.JobStatusSucceeded)),
+			parser.NewDFloat(parser.DFloat(1.0)),
+			parser.NewDInt(parser.DInt(dataSize)),
+		}}, nil
+	}
+	return fn, header, nil
+}
+
+func init() {
+	sql.AddPlanHook(importPlanHook)
+}
@@ -0,0 +1,414 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package sqlccl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// dumpDialect translates the statements of a dump file written by another
+// database into statements understood by our parser.
+type dumpDialect interface {
+	// backslashEscapes returns whether a backslash escapes the next character
+	// of a quoted string or identifier that starts with the given prefix,
+	// i.e. the opening quote and the character before it.
+	backslashEscapes(prefix string) bool
+	// translate rewrites a statement of the dump, split into its quoted and
+	// unquoted parts, with the comments removed. It returns an empty string if
+	// the statement should be skipped.
+	translate(stmt []dumpToken) (string, error)
+}
+
+// dumpToken is a piece of a statement read from a dump: either a quoted
+// string or identifier, including its quotes, or the text between them.
+type dumpToken struct {
+	text   string
+	quoted bool
+}
+
+func joinDumpTokens(toks []dumpToken) string {
+	var buf bytes.Buffer
+	for _, t := range toks {
+		buf.WriteString(t.text)
+	}
+	return buf.String()
+}
+
+// dumpReader splits a dump file into its statements and, for the COPY
+// statements of pg_dump, the rows of data following them.
+type dumpReader struct {
+	r       *bufio.Reader
+	dialect dumpDialect
+}
+
+func makeDumpReader(r io.Reader, dialect dumpDialect) dumpReader {
+	return dumpReader{r: bufio.NewReader(r), dialect: dialect}
+}
+
+// nextStatement returns the next statement of the dump, translated by the
+// dialect and without its terminating semicolon. Statements that the dialect
+// skips are returned as empty strings. It returns io.EOF after the last
+// statement.
+func (d *dumpReader) nextStatement() (string, error) {
+	var toks []dumpToken
+	var cur bytes.Buffer
+	flush := func(quoted bool) {
+		if cur.Len() > 0 {
+			toks = append(toks, dumpToken{text: cur.String(), quoted: quoted})
+			cur.Reset()
+		}
+	}
+	for {
+		ch, _, err := d.r.ReadRune()
+		if err == io.EOF {
+			flush(false)
+			if strings.TrimSpace(joinDumpTokens(toks)) != "" {
+				return "", io.ErrUnexpectedEOF
+			}
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", err
+		}
+		switch ch {
+		case ';':
+			flush(false)
+			d.skipRestOfLine()
+			if strings.TrimSpace(joinDumpTokens(toks)) == "" {
+				return "", nil
+			}
+			return d.dialect.translate(toks)
+		case '\'', '"', '`':
+			prefix := string(ch)
+			if b := cur.Bytes(); len(b) > 0 {
+				prefix = string(b[len(b)-1]) + prefix
+			}
+			flush(false)
+			if err := d.readQuoted(&cur, ch, d.dialect.backslashEscapes(prefix)); err != nil {
+				return "", err
+			}
+			flush(true)
+		case '-':
+			if next, _ := d.r.Peek(1); len(next) == 1 && next[0] == '-' {
+				if _, err := d.r.ReadString('\n'); err != nil && err != io.EOF {
+					return "", err
+				}
+				cur.WriteByte('\n')
+				continue
+			}
+			cur.WriteRune(ch)
+		case '/':
+			if next, _ := d.r.Peek(1); len(next) == 1 && next[0] == '*' {
+				if err := d.skipBlockComment(); err != nil {
+					return "", err
+				}
+				cur.WriteByte(' ')
+				continue
+			}
+			cur.WriteRune(ch)
+		default:
+			cur.WriteRune(ch)
+		}
+	}
+}
+
+// readQuoted reads the rest of a string or identifier opened by quote into
+// buf, including both quotes.
+func (d *dumpReader) readQuoted(buf *bytes.Buffer, quote rune, backslashEscapes bool) error {
+	buf.WriteRune(quote)
+	for {
+		ch, _, err := d.r.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		buf.WriteRune(ch)
+		switch {
+		case ch == '\\' && backslashEscapes:
+			escaped, _, err := d.r.ReadRune()
+			if err != nil {
+				if err == io.EOF {
+					return io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			buf.WriteRune(escaped)
+		case ch == quote:
+			// A doubled quote stands for the quote itself.
+			if next, _ := d.r.Peek(1); len(next) == 1 && rune(next[0]) == quote {
+				_, _ = d.r.ReadByte()
+				buf.WriteRune(quote)
+				continue
+			}
+			return nil
+		}
+	}
+}
+
+func (d *dumpReader) skipBlockComment() error {
+	// Consume the '*' of the opening "/*".
+	if _, err := d.r.ReadByte(); err != nil {
+		return err
+	}
+	var prev byte
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if prev == '*' && b == '/' {
+			return nil
+		}
+		prev = b
+	}
+}
+
+// skipRestOfLine consumes the whitespace following a statement up to the end
+// of its line, so the data of a COPY statement starts on the next read.
+func (d *dumpReader) skipRestOfLine() {
+	for {
+		next, err := d.r.Peek(1)
+		if err != nil {
+			return
+		}
+		switch next[0] {
+		case ' ', '\t', '\r':
+			_, _ = d.r.ReadByte()
+		case '\n':
+			_, _ = d.r.ReadByte()
+			return
+		default:
+			return
+		}
+	}
+}
+
+// copyDataEnd is the line terminating the data of a COPY statement.
+const copyDataEnd = `\.`
+
+// nextCopyRow returns the next row of data of the COPY statement the dump is
+// positioned after, as its tab separated fields. It returns nil after the last
+// row of the statement.
+func (d *dumpReader) nextCopyRow() ([]string, error) {
+	line, err := d.r.ReadString('\n')
+	if err == io.EOF {
+		if line == "" {
+			return nil, io.ErrUnexpectedEOF
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	if line == copyDataEnd {
+		return nil, nil
+	}
+	return strings.Split(line, "\t"), nil
+}
+
+// pgDumpDialect handles the plain SQL format written by pg_dump.
+type pgDumpDialect struct{}
+
+var _ dumpDialect = pgDumpDialect{}
+
+// pgDumpSkipPrefixes lists the prefixes of the statements of pg_dump that
+// configure the session or the objects we don't import. They are compared to
+// upper-cased statements.
+var pgDumpSkipPrefixes = []string{
+	"SET ",
+	"SELECT PG_CATALOG.",
+	"COMMENT ON ",
+	"CREATE EXTENSION ",
+	"CREATE SCHEMA ",
+	"CREATE SEQUENCE ",
+	"ALTER SEQUENCE ",
+	"GRANT ",
+	"REVOKE ",
+}
+
+var (
+	pgDumpOwnerRE = regexp.MustCompile(`(?is)^ALTER\s.*\sOWNER\s+TO\s`)
+	// Columns defaulting to a sequence are imported without a default, since
+	// the sequences are not imported.
+	pgDumpNextvalDefaultRE = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s.*\sSET\s+DEFAULT\s+nextval\(`)
+	pgDumpIndexMethodRE    = regexp.MustCompile(`(?i)\s+USING\s+btree\s*\(`)
+)
+
+func (pgDumpDialect) backslashEscapes(prefix string) bool {
+	// Backslashes only escape characters of escape strings, e.g. E'\n'.
+	return prefix == "E'" || prefix == "e'"
+}
+
+func (pgDumpDialect) translate(toks []dumpToken) (string, error) {
+	stmt := strings.TrimSpace(joinDumpTokens(toks))
+	upper := strings.ToUpper(stmt)
+	for _, prefix := range pgDumpSkipPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return "", nil
+		}
+	}
+	if pgDumpOwnerRE.MatchString(stmt) || pgDumpNextvalDefaultRE.MatchString(stmt) {
+		return "", nil
+	}
+	if strings.HasPrefix(upper, "CREATE INDEX ") || strings.HasPrefix(upper, "CREATE UNIQUE INDEX ") {
+		stmt = pgDumpIndexMethodRE.ReplaceAllString(stmt, " (")
+	}
+	return stmt, nil
+}
+
+// mysqlDumpDialect handles the files written by mysqldump.
+type mysqlDumpDialect struct{}
+
+var _ dumpDialect = mysqlDumpDialect{}
+
+// mysqlDumpSkipPrefixes lists the prefixes of the statements of mysqldump
+// that configure the session, lock the tables or recreate the database. They
+// are compared to upper-cased statements.
+var mysqlDumpSkipPrefixes = []string{
+	"SET ",
+	"USE ",
+	"LOCK TABLES ",
+	"UNLOCK TABLES",
+	"DROP TABLE ",
+	"CREATE DATABASE ",
+}
+
+// mysqlDumpTypes maps the MySQL column types without an equivalent of the
+// same name to our types.
+var mysqlDumpTypes = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)\b(tinyint|smallint|mediumint|int|integer|bigint)\s*\(\d+\)`), "$1"},
+	{regexp.MustCompile(`(?i)\b(tinyint|mediumint)\b`), "INT"},
+	{regexp.MustCompile(`(?i)\bdatetime\b(\s*\(\d+\))?`), "TIMESTAMP"},
+	{regexp.MustCompile(`(?i)\bdouble\b(\s*\(\d+,\s*\d+\))?`), "FLOAT"},
+	{regexp.MustCompile(`(?i)\b(tinytext|mediumtext|longtext)\b`), "STRING"},
+	{regexp.MustCompile(`(?i)\b(tinyblob|mediumblob|longblob|varbinary\s*\(\d+\)|binary\s*\(\d+\))`), "BYTES"},
+}
+
+var (
+	mysqlDumpColumnAttrsRE = regexp.MustCompile(
+		`(?i)\s+(unsigned|zerofill|auto_increment|on\s+update\s+current_timestamp|` +
+			`(character\s+set|charset|collate)\s+\w+)\b`)
+	// mysqldump lists the secondary indexes of a table as "KEY name (...)".
+	mysqlDumpKeyRE     = regexp.MustCompile(`(?i)(,\s*)(UNIQUE\s+)?KEY\b`)
+	mysqlDumpCommentRE = regexp.MustCompile(`(?i)\s+COMMENT\s*$`)
+)
+
+func (mysqlDumpDialect) backslashEscapes(string) bool {
+	return true
+}
+
+func (mysqlDumpDialect) translate(toks []dumpToken) (string, error) {
+	upper := strings.ToUpper(strings.TrimSpace(joinDumpTokens(toks)))
+	for _, prefix := range mysqlDumpSkipPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return "", nil
+		}
+	}
+	createTable := strings.HasPrefix(upper, "CREATE TABLE ")
+
+	if createTable {
+		// Drop the table options following the closing parenthesis of the
+		// column definitions, e.g. ENGINE=InnoDB.
+		for i := len(toks) - 1; i >= 0; i-- {
+			if toks[i].quoted {
+				continue
+			}
+			if j := strings.LastIndexByte(toks[i].text, ')'); j >= 0 {
+				toks = append(toks[:i:i], dumpToken{text: toks[i].text[:j+1]})
+				break
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.quoted {
+			buf.WriteString(translateMySQLQuoted(tok.text))
+			continue
+		}
+		text := tok.text
+		if createTable {
+			// Column comments are dropped along with the string following them.
+			if mysqlDumpCommentRE.MatchString(text) && i+1 < len(toks) && toks[i+1].quoted {
+				text = mysqlDumpCommentRE.ReplaceAllString(text, "")
+				i++
+			}
+			for _, t := range mysqlDumpTypes {
+				text = t.re.ReplaceAllString(text, t.repl)
+			}
+			text = mysqlDumpColumnAttrsRE.ReplaceAllString(text, "")
+			text = mysqlDumpKeyRE.ReplaceAllString(text, "${1}${2}INDEX")
+		}
+		buf.WriteString(text)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// translateMySQLQuoted translates a quoted MySQL identifier or string,
+// including its quotes. Identifiers quoted with backticks are quoted with
+// double quotes instead, and strings using backslash escapes are turned into
+// escape strings.
+func translateMySQLQuoted(s string) string {
+	quote, inner := s[0], s[1:len(s)-1]
+	if quote == '`' {
+		return `"` + strings.Replace(strings.Replace(inner, "``", "`", -1), `"`, `""`, -1) + `"`
+	}
+	escapes := strings.ContainsRune(inner, '\\')
+	var buf bytes.Buffer
+	if escapes {
+		buf.WriteString("e")
+	}
+	buf.WriteByte('\'')
+	for i := 0; i < len(inner); i++ {
+		ch := inner[i]
+		switch {
+		case ch == '\\' && i+1 < len(inner):
+			i++
+			switch esc := inner[i]; esc {
+			case '0':
+				buf.WriteString(`\x00`)
+			case 'Z':
+				buf.WriteString(`\x1a`)
+			case '%', '_':
+				// These are only escapes in LIKE patterns, where the backslash
+				// is kept.
+				buf.WriteString(`\\`)
+				buf.WriteByte(esc)
+			case '\'', '\\', 'b', 'n', 'r', 't':
+				buf.WriteByte('\\')
+				buf.WriteByte(esc)
+			default:
+				buf.WriteByte(esc)
+			}
+		case quote == '"' && ch == '\'':
+			// MySQL also quotes strings with double quotes.
+			buf.WriteString("''")
+		case quote == '"' && ch == '"':
+			// A doubled double quote stands for a double quote.
+			buf.WriteByte('"')
+			i++
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}
@@ -0,0 +1,269 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/LICENSE
+
+package sqlccl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+const testPgDump = `--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SET client_encoding = 'UTF8';
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE TABLE public.customers (
+    id integer NOT NULL,
+    name character varying(20),
+    note text DEFAULT 'x;y'
+);
+
+ALTER TABLE public.customers OWNER TO postgres;
+
+CREATE SEQUENCE public.customers_id_seq
+    START WITH 1
+    INCREMENT BY 1;
+
+ALTER TABLE ONLY public.customers ALTER COLUMN id SET DEFAULT nextval('public.customers_id_seq'::regclass);
+
+CREATE TABLE public.orders (
+    id integer NOT NULL,
+    customer integer,
+    placed timestamp without time zone
+);
+
+COPY public.customers (id, name, note) FROM stdin;
+1	alice	\N
+2	bob	tab\there
+\.
+
+COPY public.orders (id, customer, placed) FROM stdin;
+10	2	2017-01-02 03:04:05
+11	1	2017-06-07 08:09:10
+\.
+
+SELECT pg_catalog.setval('public.customers_id_seq', 2, true);
+
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+
+CREATE INDEX orders_customer_idx ON public.orders USING btree (customer);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_customer_fkey FOREIGN KEY (customer) REFERENCES public.customers(id);
+`
+
+const testMySQLDump = "-- MySQL dump 10.13\n" +
+	"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+	"/*!40101 SET NAMES utf8 */;\n" +
+	"\n" +
+	"DROP TABLE IF EXISTS `items`;\n" +
+	"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+	"CREATE TABLE `items` (\n" +
+	"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+	"  `label` varchar(32) CHARACTER SET utf8 DEFAULT NULL COMMENT 'the label',\n" +
+	"  `qty` tinyint(4) unsigned NOT NULL,\n" +
+	"  `added` datetime DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `label_idx` (`label`),\n" +
+	"  KEY `qty_idx` (`qty`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=4 DEFAULT CHARSET=latin1 COMMENT='some (items)';\n" +
+	"\n" +
+	"LOCK TABLES `items` WRITE;\n" +
+	"/*!40000 ALTER TABLE `items` DISABLE KEYS */;\n" +
+	"INSERT INTO `items` VALUES (1,'it\\'s',-3,'2017-01-02 03:04:05'),(2,'a\\\\b',7,NULL),(3,NULL,0,NULL);\n" +
+	"/*!40000 ALTER TABLE `items` ENABLE KEYS */;\n" +
+	"UNLOCK TABLES;\n"
+
+func readDumpStatements(t *testing.T, dump string, dialect dumpDialect) []string {
+	dr := makeDumpReader(strings.NewReader(dump), dialect)
+	var stmts []string
+	for {
+		stmt, err := dr.nextStatement()
+		if err == io.EOF {
+			return stmts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stmt == "" {
+			continue
+		}
+		stmts = append(stmts, stmt)
+		if strings.HasPrefix(stmt, "COPY ") {
+			var rows int
+			for {
+				row, err := dr.nextCopyRow()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if row == nil {
+					break
+				}
+				rows++
+			}
+			if rows != 2 {
+				t.Fatalf("%s: expected 2 rows, got %d", stmt, rows)
+			}
+		}
+	}
+}
+
+func TestPgDumpDialect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stmts := readDumpStatements(t, testPgDump, pgDumpDialect{})
+	expected := []string{
+		"CREATE TABLE public.customers (\n    id integer NOT NULL,\n    name character varying(20),\n    note text DEFAULT 'x;y'\n)",
+		"CREATE TABLE public.orders (\n    id integer NOT NULL,\n    customer integer,\n    placed timestamp without time zone\n)",
+		"COPY public.customers (id, name, note) FROM stdin",
+		"COPY public.orders (id, customer, placed) FROM stdin",
+		"ALTER TABLE ONLY public.customers\n    ADD CONSTRAINT customers_pkey PRIMARY KEY (id)",
+		"ALTER TABLE ONLY public.orders\n    ADD CONSTRAINT orders_pkey PRIMARY KEY (id)",
+		"CREATE INDEX orders_customer_idx ON public.orders (customer)",
+		"ALTER TABLE ONLY public.orders\n    ADD CONSTRAINT orders_customer_fkey FOREIGN KEY (customer) REFERENCES public.customers(id)",
+	}
+	if !reflect.DeepEqual(stmts, expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(stmts, "\n"))
+	}
+}
+
+func TestMySQLDumpDialect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stmts := readDumpStatements(t, testMySQLDump, mysqlDumpDialect{})
+	expected := []string{
+		"CREATE TABLE \"items\" (\n" +
+			"  \"id\" int NOT NULL,\n" +
+			"  \"label\" varchar(32) DEFAULT NULL,\n" +
+			"  \"qty\" INT NOT NULL,\n" +
+			"  \"added\" TIMESTAMP DEFAULT NULL,\n" +
+			"  PRIMARY KEY (\"id\"),\n" +
+			"  UNIQUE INDEX \"label_idx\" (\"label\"),\n" +
+			"  INDEX \"qty_idx\" (\"qty\")\n" +
+			")",
+		`INSERT INTO "items" VALUES (1,e'it\'s',-3,'2017-01-02 03:04:05'),(2,e'a\\b',7,NULL),(3,NULL,0,NULL)`,
+	}
+	if !reflect.DeepEqual(stmts, expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(stmts, "\n"))
+	}
+}
+
+func TestImportDump(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+	for name, dump := range map[string]string{
+		"pg.sql":    testPgDump,
+		"mysql.sql": testMySQLDump,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(dump), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+	sqlDB.Exec(`CREATE DATABASE pg`)
+	sqlDB.Exec(`CREATE DATABASE mysql`)
+
+	// Option values can't be placeholders.
+	sqlDB.Exec(fmt.Sprintf(`IMPORT PGDUMP $1 WITH OPTIONS ('into_db'='pg', 'temp'='%s', 'skip_foreign_keys')`,
+		"nodelocal://"+filepath.Join(dir, "pg")), "nodelocal://"+filepath.Join(dir, "pg.sql"))
+	sqlDB.CheckQueryResults(`SELECT id, name, note FROM pg.customers ORDER BY id`, [][]string{
+		{"1", "alice", "NULL"},
+		{"2", "bob", "tab\there"},
+	})
+	sqlDB.CheckQueryResults(`SELECT id FROM pg.orders@orders_customer_idx WHERE customer = 2`, [][]string{
+		{"10"},
+	})
+	sqlDB.CheckQueryResults(`SELECT id FROM pg.orders WHERE placed = '2017-06-07 08:09:10'`, [][]string{
+		{"11"},
+	})
+
+	sqlDB.Exec(fmt.Sprintf(`IMPORT MYSQLDUMP $1 WITH OPTIONS ('into_db'='mysql', 'temp'='%s')`,
+		"nodelocal://"+filepath.Join(dir, "mysql")), "nodelocal://"+filepath.Join(dir, "mysql.sql"))
+	sqlDB.CheckQueryResults(`SELECT id, label, qty FROM mysql.items ORDER BY id`, [][]string{
+		{"1", "it's", "-3"},
+		{"2", `a\b`, "7"},
+		{"3", "NULL", "0"},
+	})
+	sqlDB.CheckQueryResults(`SELECT id FROM mysql.items@label_idx WHERE label = 'it''s'`, [][]string{
+		{"1"},
+	})
+
+	var status string
+	sqlDB.QueryRow(`SELECT status FROM crdb_internal.jobs WHERE type = 'IMPORT' LIMIT 1`).Scan(&status)
+	if status != "succeeded" {
+		t.Fatalf("expected the import job to have succeeded, got %s", status)
+	}
+}
+
+func TestImportDumpErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+	if err := ioutil.WriteFile(filepath.Join(dir, "pg.sql"), []byte(testPgDump), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := "nodelocal://" + filepath.Join(dir, "pg.sql")
+	temp := "nodelocal://" + filepath.Join(dir, "temp")
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+	sqlDB.Exec(`CREATE DATABASE d`)
+
+	for _, tc := range []struct {
+		stmt     string
+		expected string
+	}{
+		{`IMPORT CSV $1 WITH OPTIONS ('into_db'='d', 'temp'='$temp')`,
+			`unsupported import format: CSV`},
+		{`IMPORT PGDUMP $1 WITH OPTIONS ('into_db'='d', 'temp'='$temp', 'foo')`,
+			`unknown IMPORT option "foo"`},
+		{`IMPORT PGDUMP $1 WITH OPTIONS ('into_db'='d', 'temp'='$temp', 'skip_foreign_keys'='yes')`,
+			`option "skip_foreign_keys" does not take a value`},
+		{`IMPORT PGDUMP $1 WITH OPTIONS ('temp'='$temp')`,
+			`IMPORT requires the "into_db" option`},
+		{`IMPORT PGDUMP $1 WITH OPTIONS ('into_db'='d', 'temp'='$temp')`,
+			`foreign keys are not supported, as found on table orders; use the "skip_foreign_keys" option`},
+		{`IMPORT PGDUMP $1 WITH OPTIONS ('into_db'='nope', 'temp'='$temp', 'skip_foreign_keys')`,
+			`a database named "nope" needs to exist`},
+	} {
+		stmt := strings.Replace(tc.stmt, "$temp", temp, -1)
+		if _, err := db.Exec(stmt, file); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%s: expected error %q, got %v", tc.stmt, tc.expected, err)
+		}
+	}
+}
@@ -282,7 +282,10 @@ func insertStmtToKVs(
 				return err
 			}
 		}
-		row, err := sql.GenerateInsertRow(defaultExprs, ri.InsertColIDtoRowIndex, cols, evalCtx, tableDesc, row)
+		row, err := sql.GenerateInsertRow(
+			defaultExprs, nil /* computeExprs */, ri.InsertColIDtoRowIndex, cols, nil, /* computedCols */
+			evalCtx, tableDesc, row, nil, /* computedValues */
+		)
 		if err != nil {
 			return errors.Wrapf(err, "process insert %q", row)
 		}
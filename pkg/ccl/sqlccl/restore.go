@@ -9,6 +9,9 @@
 package sqlccl
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"golang.org/x/sync/errgroup"
@@ -45,6 +48,7 @@ func Import(
 	db client.DB,
 	startKey, endKey roachpb.Key,
 	files []roachpb.ImportRequest_File,
+	endTime hlc.Timestamp,
 	kr *storageccl.KeyRewriter,
 	rekeys []roachpb.ImportRequest_TableRekey,
 ) (*roachpb.ImportResponse, error) {
@@ -77,8 +81,9 @@ func Import(
 			Key:    startKey,
 			EndKey: endKey,
 		},
-		Files:  files,
-		Rekeys: rekeys,
+		Files:   files,
+		Rekeys:  rekeys,
+		EndTime: endTime,
 	}
 	res, pErr := client.SendWrapped(ctx, db.GetSender(), req)
 	if pErr != nil {
@@ -104,6 +109,78 @@ func loadBackupDescs(ctx context.Context, uris []string) ([]BackupDescriptor, er
 	return backupDescs, nil
 }
 
+// backupsAsOf returns the prefix of the given backups, which are ordered by
+// time, that holds the data as of the given time, along with the SQL
+// descriptors as of that time. It's possible to restore to the end time of
+// every backup and to any time covered by an incremental backup taken with
+// revision history.
+func backupsAsOf(
+	backups []BackupDescriptor, asOf hlc.Timestamp,
+) ([]BackupDescriptor, []sqlbase.Descriptor, error) {
+	for i, b := range backups {
+		if asOf == b.EndTime {
+			return backups[:i+1], b.Descriptors, nil
+		}
+		if b.EndTime.Less(asOf) {
+			continue
+		}
+		if i == 0 {
+			return nil, nil, errors.Errorf(
+				"invalid RESTORE timestamp: %s is before the end time %s of the full backup",
+				asOf, b.EndTime)
+		}
+		if b.MVCCFilter != roachpb.MVCCFilter_All {
+			return nil, nil, errors.Errorf(
+				"invalid RESTORE timestamp: the backup from %s to %s was taken without %q "+
+					"and can only be restored to its end time", b.StartTime, b.EndTime,
+				backupOptRevisionHistory)
+		}
+		return backups[:i+1], descriptorsAsOf(backups[i-1].Descriptors, b, asOf), nil
+	}
+	return nil, nil, errors.Errorf(
+		"invalid RESTORE timestamp: %s is after the end time %s of the last backup",
+		asOf, backups[len(backups)-1].EndTime)
+}
+
+// descriptorsAsOf returns the SQL descriptors as of the given time within the
+// given backup, by applying the revisions written before that time to the
+// descriptors as of the start of the backup.
+func descriptorsAsOf(
+	prevDescs []sqlbase.Descriptor, b BackupDescriptor, asOf hlc.Timestamp,
+) []sqlbase.Descriptor {
+	byID := make(map[sqlbase.ID]sqlbase.Descriptor, len(prevDescs))
+	// The revisions cover every descriptor in the cluster, but only the ones
+	// backed up at the start or the end of the backup are of interest.
+	backedUp := make(map[sqlbase.ID]struct{})
+	for _, desc := range prevDescs {
+		byID[desc.GetID()] = desc
+		backedUp[desc.GetID()] = struct{}{}
+	}
+	for _, desc := range b.Descriptors {
+		backedUp[desc.GetID()] = struct{}{}
+	}
+	for _, rev := range b.DescriptorChanges {
+		if !rev.Time.Less(asOf) {
+			break
+		}
+		if _, ok := backedUp[rev.ID]; !ok {
+			continue
+		}
+		if rev.Desc == nil {
+			delete(byID, rev.ID)
+		} else {
+			byID[rev.ID] = *rev.Desc
+		}
+	}
+
+	descs := make([]sqlbase.Descriptor, 0, len(byID))
+	for _, desc := range byID {
+		descs = append(descs, desc)
+	}
+	sort.Slice(descs, func(i, j int) bool { return descs[i].GetID() < descs[j].GetID() })
+	return descs
+}
+
 func reassignParentIDs(
 	ctx context.Context,
 	txn *client.Txn,
@@ -617,6 +694,7 @@ func Restore(
 	p sql.PlanHookState,
 	uris []string,
 	targets parser.TargetList,
+	asOf hlc.Timestamp,
 	opt parser.KVOptions,
 	jobLogger *jobs.JobLogger,
 ) (dataSize int64, err error) {
@@ -632,14 +710,18 @@ func Restore(
 	if err != nil {
 		return 0, err
 	}
-	lastBackupDesc := backupDescs[len(backupDescs)-1]
+	sqlDescs := backupDescs[len(backupDescs)-1].Descriptors
+	if asOf != (hlc.Timestamp{}) {
+		if backupDescs, sqlDescs, err = backupsAsOf(backupDescs, asOf); err != nil {
+			return 0, err
+		}
+	}
 
 	databasesByID := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
 	var tables []*sqlbase.TableDescriptor
 	{
 		// TODO(dan): Plumb the session database down.
 		sessionDatabase := ""
-		sqlDescs := lastBackupDesc.Descriptors
 		var err error
 		if sqlDescs, err = descriptorsMatchingTargets(sessionDatabase, sqlDescs, targets); err != nil {
 			return 0, err
@@ -697,6 +779,7 @@ func Restore(
 	if err := jobLogger.Started(ctx); err != nil {
 		return 0, err
 	}
+	defer log.StartActivity(fmt.Sprintf("restore job %d", *jobLogger.JobID()))()
 
 	progressLogger := jobProgressLogger{
 		jobLogger:   jobLogger,
@@ -779,7 +862,7 @@ func Restore(
 		g.Go(func() error {
 			defer func() { <-importsSem }()
 
-			res, err := Import(gCtx, db, ir.Key, ir.EndKey, ir.files, kr, rekeys)
+			res, err := Import(gCtx, db, ir.Key, ir.EndKey, ir.files, asOf, kr, rekeys)
 			if err != nil {
 				return err
 			}
@@ -852,6 +935,13 @@ func restorePlanHook(
 		if err != nil {
 			return nil, err
 		}
+		var asOf hlc.Timestamp
+		if restore.AsOf.Expr != nil {
+			var err error
+			if asOf, err = sql.EvalAsOfTimestamp(nil, restore.AsOf, p.ExecCfg().Clock.Now()); err != nil {
+				return nil, err
+			}
+		}
 		description, err := restoreJobDescription(restore, from)
 		if err != nil {
 			return nil, err
@@ -866,6 +956,7 @@ func restorePlanHook(
 			p,
 			from,
 			restore.Targets,
+			asOf,
 			restore.Options,
 			&jobLogger,
 		)
@@ -167,6 +167,19 @@ func (i *MVCCIncrementalIterator) Next() {
 	}
 }
 
+// NextVersion advances the iterator to the next older version of the current
+// key within the time range or, if there is none, to the next key/value in the
+// iteration. Iterating with NextVersion instead of Next returns every version
+// of each key written between startTime and endTime.
+func (i *MVCCIncrementalIterator) NextVersion() {
+	if !i.valid {
+		return
+	}
+	i.nextkey = false
+	i.iter.Next()
+	i.Next()
+}
+
 // Valid returns true if the iterator is currently valid. An iterator that
 // hasn't had Reset called on it or has gone past the end of the key range is
 // invalid.
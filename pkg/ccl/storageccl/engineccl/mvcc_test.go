@@ -48,12 +48,33 @@ func assertEqualKVs(
 	startKey, endKey roachpb.Key,
 	startTime, endTime hlc.Timestamp,
 	expected []engine.MVCCKeyValue,
+) func(*testing.T) {
+	return assertIteratedKVs(
+		e, startKey, endKey, startTime, endTime, (*MVCCIncrementalIterator).Next, expected)
+}
+
+func assertEqualAllRevisions(
+	e engine.Engine,
+	startKey, endKey roachpb.Key,
+	startTime, endTime hlc.Timestamp,
+	expected []engine.MVCCKeyValue,
+) func(*testing.T) {
+	return assertIteratedKVs(
+		e, startKey, endKey, startTime, endTime, (*MVCCIncrementalIterator).NextVersion, expected)
+}
+
+func assertIteratedKVs(
+	e engine.Engine,
+	startKey, endKey roachpb.Key,
+	startTime, endTime hlc.Timestamp,
+	next func(*MVCCIncrementalIterator),
+	expected []engine.MVCCKeyValue,
 ) func(*testing.T) {
 	return func(t *testing.T) {
 		iter := NewMVCCIncrementalIterator(e, startTime, endTime)
 		defer iter.Close()
 		var kvs []engine.MVCCKeyValue
-		for iter.Reset(startKey, endKey); iter.Valid(); iter.Next() {
+		for iter.Reset(startKey, endKey); iter.Valid(); next(iter) {
 			kvs = append(kvs, engine.MVCCKeyValue{Key: iter.Key(), Value: iter.Value()})
 		}
 
@@ -142,6 +163,13 @@ func runMVCCIterateIncremental(t *testing.T) {
 	t.Run("ts 2-3", assertEqualKVs(e, keyMin, keyMax, ts2, ts3, kvs(kv1_2_2, kv2_2_2)))
 	t.Run("ts 3-3", assertEqualKVs(e, keyMin, keyMax, ts3, ts3, nil))
 
+	// Exercise iterating over every revision.
+	t.Run("all ts 1-∞",
+		assertEqualAllRevisions(e, keyMin, keyMax, ts1, tsMax, kvs(kv1_2_2, kv1_1_1, kv2_2_2)))
+	t.Run("all ts 2-3",
+		assertEqualAllRevisions(e, keyMin, keyMax, ts2, ts3, kvs(kv1_2_2, kv2_2_2)))
+	t.Run("all ts 0-2", assertEqualAllRevisions(e, keyMin, keyMax, ts0, ts2, kvs(kv1_1_1)))
+
 	// Exercise key ranges.
 	t.Run("kv 1-1", assertEqualKVs(e, testKey1, testKey1, ts0, tsMax, nil))
 	t.Run("kv 1-2", assertEqualKVs(e, testKey1, testKey2, ts0, tsMax, kvs(kv1_2_2)))
@@ -152,6 +180,8 @@ func runMVCCIterateIncremental(t *testing.T) {
 	}
 	mustFlush()
 	t.Run("del", assertEqualKVs(e, keyMin, keyMax, ts0, tsMax, kvs(kv1_3Deleted, kv2_2_2)))
+	t.Run("del all", assertEqualAllRevisions(e, keyMin, keyMax, ts0, tsMax,
+		kvs(kv1_3Deleted, kv1_2_2, kv1_1_1, kv2_2_2)))
 
 	// Exercise intent handling.
 	txn1ID := uuid.MakeV4()
@@ -195,6 +225,8 @@ func runMVCCIterateIncremental(t *testing.T) {
 	}
 	mustFlush()
 	t.Run("intents4", assertEqualKVs(e, keyMin, keyMax, ts0, tsMax, kvs(kv1_4_4, kv2_2_2)))
+	t.Run("intents4 all", assertEqualAllRevisions(e, keyMin, keyMax, ts0, tsMax,
+		kvs(kv1_4_4, kv1_3Deleted, kv1_2_2, kv1_1_1, kv2_2_2)))
 }
 
 func TestMVCCIterateIncremental(t *testing.T) {
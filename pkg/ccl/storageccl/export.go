@@ -85,12 +85,6 @@ func evalExport(
 	defer exportRequestLimiter.endLimitedRequest()
 	log.Infof(ctx, "export [%s,%s)", args.Key, args.EndKey)
 
-	exportStore, err := MakeExportStorage(ctx, args.Storage)
-	if err != nil {
-		return storage.EvalResult{}, err
-	}
-	defer exportStore.Close()
-
 	sst, err := engine.MakeRocksDBSstFileWriter()
 	if err != nil {
 		return storage.EvalResult{}, err
@@ -101,7 +95,11 @@ func evalExport(
 	// TODO(dan): Consider checking ctx periodically during the MVCCIterate call.
 	iter := engineccl.NewMVCCIncrementalIterator(batch, args.StartTime, h.Timestamp)
 	defer iter.Close()
-	for iter.Reset(args.Key, args.EndKey); iter.Valid(); iter.Next() {
+	nextFn := (*engineccl.MVCCIncrementalIterator).Next
+	if args.MVCCFilter == roachpb.MVCCFilter_All {
+		nextFn = (*engineccl.MVCCIncrementalIterator).NextVersion
+	}
+	for iter.Reset(args.Key, args.EndKey); iter.Valid(); nextFn(iter) {
 		if log.V(3) {
 			v := roachpb.Value{RawBytes: iter.UnsafeValue()}
 			log.Infof(ctx, "Export %s %s", iter.UnsafeKey(), v.PrettyPrint())
@@ -128,6 +126,21 @@ func evalExport(
 		return storage.EvalResult{}, err
 	}
 
+	if args.ReturnSST {
+		reply.Files = []roachpb.ExportResponse_File{{
+			Span:     args.Span,
+			DataSize: size,
+			SST:      sstContents,
+		}}
+		return storage.EvalResult{}, nil
+	}
+
+	exportStore, err := MakeExportStorage(ctx, args.Storage)
+	if err != nil {
+		return storage.EvalResult{}, err
+	}
+	defer exportStore.Close()
+
 	// Compute the checksum before we upload and remove the local file.
 	checksum, err := sha512ChecksumData(sstContents)
 	if err != nil {
@@ -125,6 +125,102 @@ func TestExport(t *testing.T) {
 	}
 }
 
+// exportedKVs sends the given Export request, which must set ReturnSST, and
+// returns the kvs in the returned files.
+func exportedKVs(
+	t *testing.T, ctx context.Context, kvDB *client.DB, req *roachpb.ExportRequest,
+) ([]roachpb.ExportResponse_File, []engine.MVCCKeyValue) {
+	res, pErr := client.SendWrapped(ctx, kvDB.GetSender(), req)
+	if pErr != nil {
+		t.Fatalf("%+v", pErr)
+	}
+	files := res.(*roachpb.ExportResponse).Files
+	var kvs []engine.MVCCKeyValue
+	for _, file := range files {
+		sst := engine.MakeRocksDBSstFileReader()
+		defer sst.Close()
+		if err := sst.IngestExternalFile(file.SST); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		start, end := engine.MVCCKey{Key: keys.MinKey}, engine.MVCCKey{Key: keys.MaxKey}
+		if err := sst.Iterate(start, end, func(kv engine.MVCCKeyValue) (bool, error) {
+			kvs = append(kvs, kv)
+			return false, nil
+		}); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	return files, kvs
+}
+
+func TestExportReturnSST(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(t, tc.Conns[0])
+	kvDB := tc.Server(0).KVClient().(*client.DB)
+
+	sqlDB.Exec(`CREATE DATABASE export`)
+	sqlDB.Exec(`CREATE TABLE export.export (id INT PRIMARY KEY)`)
+	sqlDB.Exec(`INSERT INTO export.export VALUES (1), (2), (3)`)
+
+	// No storage is configured, so this fails unless the data is returned in
+	// the response.
+	files, kvs := exportedKVs(t, ctx, kvDB, &roachpb.ExportRequest{
+		Span:      roachpb.Span{Key: keys.UserTableDataMin, EndKey: keys.MaxKey},
+		ReturnSST: true,
+	})
+	if expected := 1; len(files) != expected {
+		t.Fatalf("expected %d files in export got %d", expected, len(files))
+	}
+	if files[0].Path != "" {
+		t.Fatalf("expected no path got %q", files[0].Path)
+	}
+	if expected := 3; len(kvs) != expected {
+		t.Fatalf("expected %d kvs in export got %d", expected, len(kvs))
+	}
+}
+
+func TestExportAllRevisions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(t, tc.Conns[0])
+	kvDB := tc.Server(0).KVClient().(*client.DB)
+
+	sqlDB.Exec(`CREATE DATABASE export`)
+	sqlDB.Exec(`CREATE TABLE export.export (id INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(`INSERT INTO export.export VALUES (1, 1), (2, 1)`)
+	sqlDB.Exec(`UPDATE export.export SET v = 2 WHERE id = 1`)
+	sqlDB.Exec(`DELETE FROM export.export WHERE id = 2`)
+
+	span := roachpb.Span{Key: keys.UserTableDataMin, EndKey: keys.MaxKey}
+	for _, testCase := range []struct {
+		filter   roachpb.MVCCFilter
+		expected int
+	}{
+		// The latest version of each row: the update and the deletion.
+		{roachpb.MVCCFilter_Latest, 2},
+		// Both inserts, the update and the deletion.
+		{roachpb.MVCCFilter_All, 4},
+	} {
+		t.Run(testCase.filter.String(), func(t *testing.T) {
+			_, kvs := exportedKVs(t, ctx, kvDB, &roachpb.ExportRequest{
+				Span:       span,
+				ReturnSST:  true,
+				MVCCFilter: testCase.filter,
+			})
+			if len(kvs) != testCase.expected {
+				t.Fatalf("expected %d kvs in export got %d: %v", testCase.expected, len(kvs), kvs)
+			}
+		})
+	}
+}
+
 func TestExportGCThreshold(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
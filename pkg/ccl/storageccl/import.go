@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -293,6 +294,17 @@ func evalImport(ctx context.Context, cArgs storage.CommandArgs) (*roachpb.Import
 	iter := engineccl.MakeMultiIterator(iters)
 	var keyScratch, valueScratch []byte
 	for iter.Seek(startKeyMVCC); iter.Valid() && iter.UnsafeKey().Less(endKeyMVCC); iter.NextKey() {
+		if args.EndTime != (hlc.Timestamp{}) {
+			// Files exported with every revision of their keys may contain
+			// versions written at or after the end time. Skip them, so the
+			// latest version before the end time is the one imported.
+			for iter.Valid() && !iter.UnsafeKey().Timestamp.Less(args.EndTime) {
+				iter.Next()
+			}
+			if !iter.Valid() || !iter.UnsafeKey().Less(endKeyMVCC) {
+				break
+			}
+		}
 		if len(iter.UnsafeValue()) == 0 {
 			// Value is deleted.
 			continue
@@ -48,6 +48,12 @@ func Main() {
 	)
 	defer log.RecoverAndReportPanic(context.Background())
 
+	// Client commands report crashes according to the consent of the user
+	// running them; the server uses the cluster settings instead.
+	if os.Args[1] != "start" {
+		applyDiagnosticsConsent(false /* prompt */)
+	}
+
 	if err := Run(os.Args[1:]); err != nil {
 		fmt.Fprintf(stderr, "Failed running %q\n", os.Args[1])
 		os.Exit(ErrorCode)
@@ -121,6 +127,7 @@ func init() {
 		zoneCmd,
 		nodeCmd,
 		dumpCmd,
+		workloadCmd,
 
 		// Miscellaneous commands.
 		// TODO(pmattis): stats
@@ -289,6 +289,30 @@ func (c cliTest) RunWithCAArgs(origArgs []string) {
 	}
 }
 
+func TestNodeDrain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := newCLITest(cliTestParams{t: t})
+	defer c.cleanup()
+
+	out, err := c.RunWithCapture("node drain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(out), "ok") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	// The node is drained but keeps running.
+	if !c.GetNode().IsDraining() {
+		t.Error("expected the node to be draining")
+	}
+	select {
+	case <-c.Stopper().ShouldQuiesce():
+		t.Error("expected the node to keep running")
+	default:
+	}
+}
+
 func TestQuit(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1205,8 +1229,9 @@ Available Commands:
   sql         open a sql shell
   user        get, set, list and remove users
   zone        get, set, list and remove zones
-  node        list nodes and show their status
+  node        list, inspect, drain or decommission nodes
   dump        dump sql tables
+  workload    generate load against a cluster
 
   gen         generate auxiliary files
   version     output version information
@@ -1566,12 +1591,18 @@ func TestZip(t *testing.T) {
 	c := newCLITest(cliTestParams{})
 	defer c.cleanup()
 
-	out, err := c.RunWithCapture("debug zip " + os.DevNull)
+	// The heap profile is retrieved from the HTTP port of the node.
+	_, httpPort, err := net.SplitHostPort(c.Cfg.HTTPAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := "debug zip --http-port=" + httpPort + " " + os.DevNull
+	out, err := c.RunWithCapture(cmd)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	const expected = `debug zip ` + os.DevNull + `
+	expected := cmd + `
 writing ` + os.DevNull + `
   debug/events
   debug/liveness
@@ -1579,6 +1610,7 @@ writing ` + os.DevNull + `
   debug/nodes/1/status
   debug/nodes/1/gossip
   debug/nodes/1/stacks
+  debug/nodes/1/heap.pprof
   debug/nodes/1/ranges/1
   debug/nodes/1/ranges/2
   debug/nodes/1/ranges/3
@@ -1607,6 +1639,37 @@ writing ` + os.DevNull + `
 	}
 }
 
+func TestZipSelectRecentLogFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	file := func(name string, t, size int64) log.FileInfo {
+		return log.FileInfo{Name: name, SizeBytes: size, Details: log.FileDetails{Time: t}}
+	}
+	files := []log.FileInfo{
+		file("a", 1, 10), file("d", 4, 50), file("b", 2, 20), file("c", 3, 30),
+	}
+	for _, tc := range []struct {
+		maxBytes int64
+		selected string
+		skipped  string
+	}{
+		{1000, "[d c b a]", "[]"},
+		{100, "[d c b]", "[a]"},
+		{90, "[d c a]", "[b]"},
+		{10, "[d]", "[c b a]"},
+	} {
+		selected, skipped := selectRecentLogFiles(files, tc.maxBytes)
+		var names []string
+		for _, f := range selected {
+			names = append(names, f.Name)
+		}
+		if fmt.Sprint(names) != tc.selected || fmt.Sprint(skipped) != tc.skipped {
+			t.Errorf("%d: expected %s and %s skipped, got %s and %s skipped",
+				tc.maxBytes, tc.selected, tc.skipped, names, skipped)
+		}
+	}
+}
+
 func Example_in_memory() {
 	spec, err := base.NewStoreSpec("type=mem,size=1GiB")
 	if err != nil {
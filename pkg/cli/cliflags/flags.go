@@ -82,6 +82,22 @@ Disable replication in the zone by setting the desired replica count to 1.
 Equivalent to setting 'num_replicas: 1' via -f.`,
 	}
 
+	Wait = FlagInfo{
+		Name: "wait",
+		Description: `
+Specifies when to return after having marked the targets as decommissioning.
+Takes any of the following values:
+<PRE>
+
+  - all:  waits until all target nodes' replica counts have dropped to zero.
+    This is the default.
+  - live: waits until all live target nodes' replica counts have dropped to
+    zero, ignoring the replicas of nodes which are down.
+  - none: marks the targets as decommissioning, but does not wait for the
+    process to complete. Use when polling manually from an external system.
+</PRE>`,
+	}
+
 	Background = FlagInfo{
 		Name: "background",
 		Description: `
@@ -110,6 +126,23 @@ If left unspecified, defaults to 25% of the physical memory, or
 512MB if the memory size cannot be determined.`,
 	}
 
+	SettingsOverride = FlagInfo{
+		Name:   "settings-override",
+		EnvVar: "COCKROACH_SETTINGS_OVERRIDE",
+		Description: `
+Cluster settings to override on this node, e.g. to size caches or throttle
+rebalancing according to its hardware. An overridden setting keeps the
+specified value on this node regardless of its cluster-wide value, and is
+marked as such in SHOW ALL CLUSTER SETTINGS. This flag can be specified
+separately for each setting, or as a comma separated list of key=value
+pairs, for example:
+<PRE>
+
+  --settings-override=kv.snapshot_rebalance.max_rate=4MiB
+
+</PRE>`,
+	}
+
 	ClientHost = FlagInfo{
 		Name:        "host",
 		EnvVar:      "COCKROACH_HOST",
@@ -123,6 +156,12 @@ If left unspecified, defaults to 25% of the physical memory, or
 		Description: `Database server port to connect to.`,
 	}
 
+	ClientHTTPPort = FlagInfo{
+		Name:        "http-port",
+		EnvVar:      "COCKROACH_HTTP_PORT",
+		Description: `Database server HTTP port to connect to.`,
+	}
+
 	Database = FlagInfo{
 		Name:        "database",
 		Shorthand:   "d",
@@ -413,6 +452,16 @@ Also, if you use equal signs in the file path to a store, you must use the
 "path" field label.`,
 	}
 
+	TempDir = FlagInfo{
+		Name: "temp-dir",
+		Description: `
+The parent directory of the temporary storage to which the sorts and hash joins
+of large queries spill their rows, in a "cockroach-temp" subdirectory. Defaults
+to the first on-disk store, or to the system's temporary directory if all the
+stores are in memory. The temporary storage is removed when the node shuts down
+and when it starts, so the directory must not be shared with other nodes.`,
+	}
+
 	URL = FlagInfo{
 		Name:   "url",
 		EnvVar: "COCKROACH_URL",
@@ -459,6 +508,13 @@ is the prefix for range local keys.`}
 		Description: "Restrict scan to replicated data.",
 	}
 
+	RangeDataFormat = FlagInfo{
+		Name: "format",
+		Description: `
+Selects how to print the keys and values of the range. Possible values:
+text, json. With json, each key is printed as a JSON object on its own line.`,
+	}
+
 	GossipInputFile = FlagInfo{
 		Name:      "file",
 		Shorthand: "f",
@@ -467,10 +523,51 @@ File containing the JSON output from a node's /_status/gossip/ endpoint.
 If specified, takes priority over host/port flags.`,
 	}
 
+	ZipMaxLogBytes = FlagInfo{
+		Name: "max-log-bytes",
+		Description: `
+Maximum combined size of the log files retrieved from each node. The most
+recent files are retrieved first.`,
+	}
+
 	PrintSystemConfig = FlagInfo{
 		Name: "print-system-config",
 		Description: `
 If specified, print the system config contents. Beware that the output will be
 long and not particularly human-readable.`,
 	}
+
+	WorkloadConcurrency = FlagInfo{
+		Name:        "concurrency",
+		Description: `Number of workers running operations concurrently.`,
+	}
+
+	WorkloadDuration = FlagInfo{
+		Name: "duration",
+		Description: `
+How long to run the workload for. If 0, the workload runs until interrupted.`,
+	}
+
+	WorkloadReadPercent = FlagInfo{
+		Name:        "read-percent",
+		Description: `Percentage of the operations which are reads, from 0 to 100.`,
+	}
+
+	WorkloadRows = FlagInfo{
+		Name: "rows",
+		Description: `
+Number of rows of the kv table, or of accounts of the bank table.`,
+	}
+
+	WorkloadWarehouses = FlagInfo{
+		Name:        "warehouses",
+		Description: `Number of warehouses loaded by the tpcc workload.`,
+	}
+
+	WorkloadInitOnly = FlagInfo{
+		Name: "init-only",
+		Description: `
+Only create the schema of the workload and load its initial data, without
+running any operations.`,
+	}
 )
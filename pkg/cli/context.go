@@ -20,10 +20,12 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/pkg/errors"
 )
@@ -45,6 +47,23 @@ func (s *statementsValue) Set(value string) error {
 	return nil
 }
 
+// settingsOverrideValue is an implementation of pflag.Value that applies
+// each comma-separated key=value pair of its arguments as a local override
+// of a cluster setting.
+type settingsOverrideValue struct{}
+
+func (settingsOverrideValue) String() string {
+	return strings.Join(settings.LocalOverrides(), ",")
+}
+
+func (settingsOverrideValue) Type() string {
+	return "key=value"
+}
+
+func (settingsOverrideValue) Set(value string) error {
+	return settings.SetLocalOverrides(value)
+}
+
 type cliContext struct {
 	// Embed the base context.
 	*base.Config
@@ -170,6 +189,45 @@ func (m *dumpMode) Set(s string) error {
 	return nil
 }
 
+type nodeDecommissionWaitType int
+
+const (
+	nodeDecommissionWaitAll nodeDecommissionWaitType = iota
+	nodeDecommissionWaitLive
+	nodeDecommissionWaitNone
+)
+
+// Type implements the pflag.Value interface.
+func (s *nodeDecommissionWaitType) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (s *nodeDecommissionWaitType) String() string {
+	switch *s {
+	case nodeDecommissionWaitAll:
+		return "all"
+	case nodeDecommissionWaitLive:
+		return "live"
+	case nodeDecommissionWaitNone:
+		return "none"
+	}
+	return ""
+}
+
+// Set implements the pflag.Value interface.
+func (s *nodeDecommissionWaitType) Set(value string) error {
+	switch value {
+	case "all":
+		*s = nodeDecommissionWaitAll
+	case "live":
+		*s = nodeDecommissionWaitLive
+	case "none":
+		*s = nodeDecommissionWaitNone
+	default:
+		return fmt.Errorf("invalid value for --wait: %s", value)
+	}
+	return nil
+}
+
 type keyType int
 
 //go:generate stringer -type=keyType
@@ -267,4 +325,30 @@ type debugContext struct {
 	replicated        bool
 	inputFile         string
 	printSystemConfig bool
+	// zipMaxLogBytes bounds the size of the log files retrieved from each
+	// node by debug zip.
+	zipMaxLogBytes int64
+	// rangeDataFormat is the output format of debug range-data, either text
+	// or json.
+	rangeDataFormat string
+}
+
+type workloadContext struct {
+	// concurrency is the number of workers running operations concurrently.
+	concurrency int
+	// duration is how long the workload runs for, or until interrupted if
+	// zero.
+	duration time.Duration
+	// initOnly skips running the workload after creating its schema and
+	// loading its initial data.
+	initOnly bool
+
+	// The percentage of the operations which are reads, and the size of the
+	// data, of each workload.
+	kvReadPercent   int
+	kvRows          int
+	bankReadPercent int
+	bankRows        int
+	tpccReadPercent int
+	tpccWarehouses  int
 }
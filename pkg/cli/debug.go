@@ -19,7 +19,11 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"sort"
 	"strconv"
@@ -110,7 +114,20 @@ func printKeyValue(kv engine.MVCCKeyValue) (bool, error) {
 	if debugCtx.sizes {
 		fmt.Printf("%d %d: ", len(kv.Key.Key), len(kv.Value))
 	}
+	if out, ok := tryDecodeValue(kv); ok {
+		fmt.Println(out)
+		return false, nil
+	}
+	// No better idea, just print raw bytes and hope that folks use `less -S`.
+	fmt.Printf("%q\n\n", kv.Value)
+	return false, nil
+}
+
+// tryDecodeValue pretty-prints the value of kv using the first decoder which
+// understands it.
+func tryDecodeValue(kv engine.MVCCKeyValue) (string, bool) {
 	decoders := []func(kv engine.MVCCKeyValue) (string, error){
+		tryIntent,
 		tryRaftLogEntry,
 		tryRangeDescriptor,
 		tryMeta,
@@ -118,16 +135,11 @@ func printKeyValue(kv engine.MVCCKeyValue) (bool, error) {
 		tryRangeIDKey,
 	}
 	for _, decoder := range decoders {
-		out, err := decoder(kv)
-		if err != nil {
-			continue
+		if out, err := decoder(kv); err == nil {
+			return out, true
 		}
-		fmt.Println(out)
-		return false, nil
 	}
-	// No better idea, just print raw bytes and hope that folks use `less -S`.
-	fmt.Printf("%q\n\n", kv.Value)
-	return false, nil
+	return "", false
 }
 
 func runDebugKeys(cmd *cobra.Command, args []string) error {
@@ -155,9 +167,10 @@ var debugRangeDataCmd = &cobra.Command{
 	Use:   "range-data [directory] range-id",
 	Short: "dump all the data in a range",
 	Long: `
-Pretty-prints all keys and values in a range. By default, includes unreplicated
-state like the raft HardState. With --replicated, only includes data covered by
- the consistency checker.
+Pretty-prints all keys and values in a range, including MVCC metadata and
+intents. By default, includes unreplicated state like the raft HardState and
+the raft log. With --replicated, only includes data covered by the consistency
+checker. With --format=json, prints one JSON object per key instead.
 `,
 	RunE: MaybeDecorateGRPCError(runDebugRangeData),
 }
@@ -185,14 +198,31 @@ func runDebugRangeData(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var printer func(engine.MVCCKeyValue) error
+	switch debugCtx.rangeDataFormat {
+	case "text":
+		printer = func(kv engine.MVCCKeyValue) error {
+			_, err := printKeyValue(kv)
+			return err
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		printer = func(kv engine.MVCCKeyValue) error {
+			return printRangeDataJSON(enc, kv)
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected text or json", debugCtx.rangeDataFormat)
+	}
+
 	iter := storage.NewReplicaDataIterator(&desc, db, debugCtx.replicated)
+	defer iter.Close()
 	for ; ; iter.Next() {
 		if ok, err := iter.Valid(); err != nil {
 			return err
 		} else if !ok {
 			break
 		}
-		if _, err := printKeyValue(engine.MVCCKeyValue{
+		if err := printer(engine.MVCCKeyValue{
 			Key:   iter.Key(),
 			Value: iter.Value(),
 		}); err != nil {
@@ -202,6 +232,63 @@ func runDebugRangeData(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// The kinds of keys found in a range by debug range-data.
+const (
+	rangeDataKindRaftLog = "raft-log"
+	rangeDataKindRangeID = "range-id"
+	rangeDataKindIntent  = "intent"
+	rangeDataKindMeta    = "meta"
+	rangeDataKindValue   = "value"
+)
+
+// rangeDataKV is the JSON representation of a key printed by debug
+// range-data.
+type rangeDataKV struct {
+	Kind      string `json:"kind"`
+	Key       string `json:"key"`
+	RawKey    []byte `json:"raw_key"`
+	Timestamp string `json:"timestamp,omitempty"`
+	// Value is the pretty-printed value, if it could be decoded.
+	Value    string `json:"value,omitempty"`
+	RawValue []byte `json:"raw_value"`
+}
+
+// rangeDataKind classifies a key found in a range: versioned values, MVCC
+// metadata (inline values and the metadata of versioned keys), intents, and
+// the range-ID local keys, among which the raft log.
+func rangeDataKind(kv engine.MVCCKeyValue) string {
+	if kv.Key.IsValue() {
+		return rangeDataKindValue
+	}
+	if _, _, suffix, _, err := keys.DecodeRangeIDKey(kv.Key.Key); err == nil {
+		if bytes.Equal(suffix, keys.LocalRaftLogSuffix) {
+			return rangeDataKindRaftLog
+		}
+		return rangeDataKindRangeID
+	}
+	var meta enginepb.MVCCMetadata
+	if err := meta.Unmarshal(kv.Value); err == nil && meta.Txn != nil {
+		return rangeDataKindIntent
+	}
+	return rangeDataKindMeta
+}
+
+func printRangeDataJSON(enc *json.Encoder, kv engine.MVCCKeyValue) error {
+	out := rangeDataKV{
+		Kind:     rangeDataKind(kv),
+		Key:      kv.Key.Key.String(),
+		RawKey:   kv.Key.Key,
+		RawValue: kv.Value,
+	}
+	if kv.Key.IsValue() {
+		out.Timestamp = kv.Key.Timestamp.String()
+	}
+	if value, ok := tryDecodeValue(kv); ok {
+		out.Value = strings.TrimSpace(value)
+	}
+	return enc.Encode(out)
+}
+
 var debugRangeDescriptorsCmd = &cobra.Command{
 	Use:   "range-descriptors [directory]",
 	Short: "print all range descriptors in a store",
@@ -242,6 +329,20 @@ func maybeUnmarshalInline(v []byte, dest proto.Message) error {
 	return value.GetProto(dest)
 }
 
+func tryIntent(kv engine.MVCCKeyValue) (string, error) {
+	if kv.Key.IsValue() {
+		return "", fmt.Errorf("intents are stored on the metadata key: %s", kv.Key)
+	}
+	var meta enginepb.MVCCMetadata
+	if err := meta.Unmarshal(kv.Value); err != nil {
+		return "", err
+	}
+	if meta.Txn == nil {
+		return "", errors.New("not an intent")
+	}
+	return fmt.Sprintf("intent of txn %s at %s\n", meta.Txn, meta.Timestamp), nil
+}
+
 func tryTxn(kv engine.MVCCKeyValue) (string, error) {
 	var txn roachpb.Transaction
 	if err := maybeUnmarshalInline(kv.Value, &txn); err != nil {
@@ -894,6 +995,51 @@ func init() {
 	debugCmd.AddCommand(debugCmds...)
 }
 
+var debugDiagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics",
+	Short: "print the reports a node sends to Cockroach Labs",
+	Long: `
+Prints, as JSON, the diagnostics report and the crash report that a running
+node would send to Cockroach Labs, without sending them. The reports are
+built exactly as they would be sent, and show whether reporting is enabled.
+
+The node is contacted over HTTP; unless server.remote_debugging.mode is set
+to "any", the command must run on the same machine as the node.
+`,
+	RunE: runDebugDiagnostics,
+}
+
+func runDebugDiagnostics(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return usageAndError(cmd)
+	}
+	host := clientConnHost
+	if host == "" {
+		host = "localhost"
+	}
+	client, err := baseCfg.GetHTTPClient()
+	if err != nil {
+		return err
+	}
+	url := baseCfg.HTTPRequestScheme() + "://" + net.JoinHostPort(host, serverHTTPPort) +
+		server.DiagnosticsPreviewEndpoint
+	resp, err := client.Get(url)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve the reports from the server")
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to retrieve the reports from the server: %s: %s",
+			resp.Status, strings.TrimSpace(string(body)))
+	}
+	_, err = os.Stdout.Write(body)
+	return err
+}
+
 var debugCmds = []*cobra.Command{
 	debugKeysCmd,
 	debugRangeDataCmd,
@@ -905,6 +1051,7 @@ var debugCmds = []*cobra.Command{
 	debugCompactCmd,
 	debugSSTablesCmd,
 	debugGossipValuesCmd,
+	debugDiagnosticsCmd,
 	rangeCmd,
 	debugEnvCmd,
 	debugZipCmd,
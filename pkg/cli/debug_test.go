@@ -0,0 +1,82 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestRangeDataKind(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	marshalMeta := func(meta enginepb.MVCCMetadata) []byte {
+		data, err := meta.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	var value roachpb.Value
+	value.SetInt(5)
+	inline := marshalMeta(enginepb.MVCCMetadata{RawBytes: value.RawBytes})
+	txnID := uuid.MakeV4()
+	intent := marshalMeta(enginepb.MVCCMetadata{
+		Txn:       &enginepb.TxnMeta{ID: &txnID, Key: roachpb.Key("a")},
+		Timestamp: hlc.Timestamp{WallTime: 1},
+	})
+
+	testCases := []struct {
+		key      engine.MVCCKey
+		value    []byte
+		expected string
+	}{
+		{engine.MVCCKey{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: 1}},
+			value.RawBytes, rangeDataKindValue},
+		{engine.MakeMVCCMetadataKey(roachpb.Key("a")), intent, rangeDataKindIntent},
+		{engine.MakeMVCCMetadataKey(roachpb.Key("a")), inline, rangeDataKindMeta},
+		{engine.MakeMVCCMetadataKey(keys.RaftLogKey(1, 5)), inline, rangeDataKindRaftLog},
+		{engine.MakeMVCCMetadataKey(keys.RaftAppliedIndexKey(1)), inline, rangeDataKindRangeID},
+	}
+	for i, tc := range testCases {
+		kv := engine.MVCCKeyValue{Key: tc.key, Value: tc.value}
+		if kind := rangeDataKind(kv); kind != tc.expected {
+			t.Errorf("%d: expected kind %s for %s, got %s", i, tc.expected, tc.key, kind)
+		}
+
+		// The JSON output can be parsed back and holds the raw key and value.
+		var buf bytes.Buffer
+		if err := printRangeDataJSON(json.NewEncoder(&buf), kv); err != nil {
+			t.Fatal(err)
+		}
+		var out rangeDataKV
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if out.Kind != tc.expected || !bytes.Equal(out.RawKey, tc.key.Key) ||
+			!bytes.Equal(out.RawValue, tc.value) {
+			t.Errorf("%d: unexpected JSON output %s", i, buf.String())
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// diagnosticsConsentFile is the name of the file, in the user's home
+// directory, which records whether the user agreed to send diagnostics
+// and crash reports from client commands.
+var diagnosticsConsentFile = envutil.EnvOrDefaultString(
+	"COCKROACH_DIAGNOSTICS_CONSENT_FILE", ".cockroachdb_diagnostics_consent")
+
+const diagnosticsConsentPrompt = `
+CockroachDB can send anonymous crash reports from client commands to
+Cockroach Labs to help improve the product. No SQL data is ever sent.
+Your choice is saved in %s.
+Send crash reports? [y/N] `
+
+// applyDiagnosticsConsent reflects the diagnostics consent previously
+// given by the user in the reporting settings used by the crash reporter
+// of client commands. If the user has not made a choice yet and prompt
+// is set, the user is asked once and the answer is persisted.
+//
+// This must not be used by server commands, whose reporting settings
+// are controlled by the cluster.
+func applyDiagnosticsConsent(prompt bool) {
+	ctx := context.TODO()
+	homeDir, err := envutil.HomeDir()
+	if err != nil {
+		if log.V(2) {
+			log.Warningf(ctx, "cannot retrieve user information: %v", err)
+		}
+		return
+	}
+	path := filepath.Join(homeDir, diagnosticsConsentFile)
+	consent, ok, err := readDiagnosticsConsent(path)
+	if err != nil {
+		log.Warningf(ctx, "cannot read diagnostics consent: %v", err)
+		return
+	}
+	if !ok {
+		if !prompt {
+			return
+		}
+		consent, err = askDiagnosticsConsent(os.Stdin, stderr, path)
+		if err != nil {
+			log.Warningf(ctx, "cannot read diagnostics consent: %v", err)
+			return
+		}
+		if err := writeDiagnosticsConsent(path, consent); err != nil {
+			log.Warningf(ctx, "cannot save diagnostics consent: %v", err)
+		}
+	}
+	u := settings.MakeUpdater()
+	for _, key := range []string{
+		"diagnostics.reporting.enabled", "diagnostics.reporting.send_crash_reports",
+	} {
+		if err := u.Set(key, settings.EncodeBool(consent), "b"); err != nil {
+			log.Warningf(ctx, "cannot apply diagnostics consent: %v", err)
+		}
+	}
+}
+
+// readDiagnosticsConsent reads the consent recorded at path. The
+// boolean return value is false if no choice was recorded.
+func readDiagnosticsConsent(path string) (consent bool, ok bool, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	consent, err = strconv.ParseBool(strings.TrimSpace(string(data)))
+	if err != nil {
+		return false, false, err
+	}
+	return consent, true, nil
+}
+
+func writeDiagnosticsConsent(path string, consent bool) error {
+	return ioutil.WriteFile(path, []byte(strconv.FormatBool(consent)+"\n"), 0600)
+}
+
+// askDiagnosticsConsent prompts the user on out and reads the answer
+// from in. Anything but an explicit "yes" is a refusal.
+func askDiagnosticsConsent(in io.Reader, out io.Writer, path string) (bool, error) {
+	fmt.Fprintf(out, diagnosticsConsentPrompt, path)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDiagnosticsConsent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, err := ioutil.TempDir("", "TestDiagnosticsConsent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	path := filepath.Join(dir, diagnosticsConsentFile)
+
+	if _, ok, err := readDiagnosticsConsent(path); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("unexpected consent before any choice was made")
+	}
+
+	testCases := []struct {
+		answer   string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"maybe\n", false},
+	}
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		consent, err := askDiagnosticsConsent(strings.NewReader(tc.answer), &out, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if consent != tc.expected {
+			t.Errorf("%q: expected consent %t, got %t", tc.answer, tc.expected, consent)
+		}
+		if !strings.Contains(out.String(), path) {
+			t.Errorf("%q: prompt does not mention %s: %s", tc.answer, path, out.String())
+		}
+
+		if err := writeDiagnosticsConsent(path, consent); err != nil {
+			t.Fatal(err)
+		}
+		if persisted, ok, err := readDiagnosticsConsent(path); err != nil {
+			t.Fatal(err)
+		} else if !ok || persisted != consent {
+			t.Errorf("%q: expected persisted consent %t, got %t (ok=%t)", tc.answer, consent, persisted, ok)
+		}
+	}
+}
@@ -33,7 +33,7 @@ import (
 // dumpCmd dumps SQL tables.
 var dumpCmd = &cobra.Command{
 	Use:   "dump [options] <database> [<table> [<table>...]]",
-	Short: "dump sql tables\n",
+	Short: "dump sql tables",
 	Long: `
 Dump SQL tables of a cockroach database. If the table name
 is omitted, dump all tables in the database.
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"runtime"
 	"strings"
 	"time"
 
@@ -47,16 +48,19 @@ var serverHTTPHost, serverHTTPPort string
 var clientConnHost, clientConnPort string
 var zoneConfig string
 var zoneDisableReplication bool
+var nodeDecommissionWait = nodeDecommissionWaitAll
 
 var serverCfg = server.MakeConfig()
 var baseCfg = serverCfg.Config
 var cliCtx = cliContext{Config: baseCfg}
 var sqlCtx = sqlContext{cliContext: &cliCtx}
 var dumpCtx = dumpContext{cliContext: &cliCtx, dumpMode: dumpBoth}
+var workloadCtx = workloadContext{}
 var debugCtx = debugContext{
-	startKey:   engine.NilKey,
-	endKey:     engine.MVCCKeyMax,
-	replicated: false,
+	startKey:       engine.NilKey,
+	endKey:         engine.MVCCKeyMax,
+	replicated:     false,
+	zipMaxLogBytes: 100 << 20, // 100 MiB
 }
 
 // server-specific values of some flags.
@@ -67,6 +71,7 @@ var serverSSLCertsDir string
 func InitCLIDefaults() {
 	cliCtx.tableDisplayFormat = tableDisplayTSV
 	dumpCtx.dumpMode = dumpBoth
+	nodeDecommissionWait = nodeDecommissionWaitAll
 }
 
 const usageIndentation = 8
@@ -241,6 +246,7 @@ func init() {
 		varFlag(f, &serverCfg.Locality, cliflags.Locality)
 
 		varFlag(f, &serverCfg.Stores, cliflags.Store)
+		stringFlag(f, &serverCfg.TempDir, cliflags.TempDir, "")
 		durationFlag(f, &serverCfg.MaxOffset, cliflags.MaxOffset, base.DefaultMaxClockOffset)
 
 		// Usage for the unix socket is odd as we use a real file, whereas
@@ -272,6 +278,8 @@ func init() {
 
 		sqlSize := humanizeutil.NewBytesValue(&serverCfg.SQLMemoryPoolSize)
 		varFlag(f, sqlSize, cliflags.SQLMem)
+
+		varFlag(f, settingsOverrideValue{}, cliflags.SettingsOverride)
 	}
 
 	for _, cmd := range certCmds {
@@ -303,7 +311,10 @@ func init() {
 
 	boolFlag(setUserCmd.Flags(), &password, cliflags.Password, false)
 
+	stringFlag(debugDiagnosticsCmd.PersistentFlags(), &serverHTTPPort, cliflags.ClientHTTPPort, base.DefaultHTTPPort)
+
 	clientCmds := []*cobra.Command{
+		debugDiagnosticsCmd,
 		debugGossipValuesCmd,
 		debugZipCmd,
 		dumpCmd,
@@ -312,6 +323,7 @@ func init() {
 		sqlShellCmd,
 		/* startCmd is covered above */
 	}
+	clientCmds = append(clientCmds, workloadCmds...)
 	clientCmds = append(clientCmds, rangeCmds...)
 	clientCmds = append(clientCmds, userCmds...)
 	clientCmds = append(clientCmds, zoneCmds...)
@@ -331,6 +343,8 @@ func init() {
 	stringFlag(zf, &zoneConfig, cliflags.ZoneConfig, "")
 	boolFlag(zf, &zoneDisableReplication, cliflags.ZoneDisableReplication, false)
 
+	varFlag(decommissionNodeCmd.Flags(), &nodeDecommissionWait, cliflags.Wait)
+
 	varFlag(sqlShellCmd.Flags(), &sqlCtx.execStmts, cliflags.Execute)
 	varFlag(dumpCmd.Flags(), &dumpCtx.dumpMode, cliflags.DumpMode)
 	stringFlag(dumpCmd.Flags(), &dumpCtx.asOf, cliflags.DumpTime, "")
@@ -339,6 +353,7 @@ func init() {
 	sqlCmds := []*cobra.Command{sqlShellCmd, dumpCmd}
 	sqlCmds = append(sqlCmds, zoneCmds...)
 	sqlCmds = append(sqlCmds, userCmds...)
+	sqlCmds = append(sqlCmds, workloadCmds...)
 	for _, cmd := range sqlCmds {
 		f := cmd.PersistentFlags()
 		stringFlag(f, &sqlConnURL, cliflags.URL, "")
@@ -349,6 +364,20 @@ func init() {
 		}
 	}
 
+	// Workload commands.
+	for _, cmd := range workloadCmds {
+		f := cmd.Flags()
+		intFlag(f, &workloadCtx.concurrency, cliflags.WorkloadConcurrency, 2*runtime.NumCPU())
+		durationFlag(f, &workloadCtx.duration, cliflags.WorkloadDuration, 0)
+		boolFlag(f, &workloadCtx.initOnly, cliflags.WorkloadInitOnly, false)
+	}
+	intFlag(workloadKVCmd.Flags(), &workloadCtx.kvReadPercent, cliflags.WorkloadReadPercent, 95)
+	intFlag(workloadKVCmd.Flags(), &workloadCtx.kvRows, cliflags.WorkloadRows, 100000)
+	intFlag(workloadBankCmd.Flags(), &workloadCtx.bankReadPercent, cliflags.WorkloadReadPercent, 10)
+	intFlag(workloadBankCmd.Flags(), &workloadCtx.bankRows, cliflags.WorkloadRows, 1000)
+	intFlag(workloadTPCCCmd.Flags(), &workloadCtx.tpccReadPercent, cliflags.WorkloadReadPercent, 8)
+	intFlag(workloadTPCCCmd.Flags(), &workloadCtx.tpccWarehouses, cliflags.WorkloadWarehouses, 1)
+
 	// Commands that print tables.
 	tableOutputCommands := []*cobra.Command{sqlShellCmd}
 	tableOutputCommands = append(tableOutputCommands, userCmds...)
@@ -380,12 +409,18 @@ func init() {
 	{
 		f := debugRangeDataCmd.Flags()
 		boolFlag(f, &debugCtx.replicated, cliflags.Replicated, false)
+		stringFlag(f, &debugCtx.rangeDataFormat, cliflags.RangeDataFormat, "text")
 	}
 	{
 		f := debugGossipValuesCmd.Flags()
 		stringFlag(f, &debugCtx.inputFile, cliflags.GossipInputFile, "")
 		boolFlag(f, &debugCtx.printSystemConfig, cliflags.PrintSystemConfig, false)
 	}
+	{
+		f := debugZipCmd.Flags()
+		varFlag(f, humanizeutil.NewBytesValue(&debugCtx.zipMaxLogBytes), cliflags.ZipMaxLogBytes)
+		stringFlag(f, &serverHTTPPort, cliflags.ClientHTTPPort, base.DefaultHTTPPort)
+	}
 }
 
 func extraServerFlagInit() {
@@ -416,12 +451,13 @@ func setDefaultStderrVerbosity(cmd *cobra.Command, defaultSeverity log.Severity)
 	pf := cmd.Flags()
 
 	vf := pf.Lookup(logflags.LogToStderrName)
+	profileSet := pf.Lookup(logflags.LogProfileName).Changed
 
-	// if `--logtostderr` was not specified and no log directory was
-	// set, or `--logtostderr` was specified but without explicit level,
-	// then set stderr logging to the level considered default by the
-	// specific command.
-	if (!vf.Changed && !log.DirSet()) ||
+	// if `--logtostderr` was not specified and neither a log directory
+	// nor a logging profile was set, or `--logtostderr` was specified but
+	// without explicit level, then set stderr logging to the level
+	// considered default by the specific command.
+	if (!vf.Changed && !profileSet && !log.DirSet()) ||
 		(vf.Changed && vf.Value.String() == log.Severity_DEFAULT.String()) {
 		if err := vf.Value.Set(defaultSeverity.String()); err != nil {
 			return err
@@ -18,15 +18,20 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 )
 
@@ -185,16 +190,216 @@ func nodeStatusesToRows(statuses []status.NodeStatus) [][]string {
 	return rows
 }
 
+var decommissionNodesColumnHeaders = []string{
+	"id",
+	"is_live",
+	"replicas",
+	"is_decommissioning",
+	"is_draining",
+}
+
+var decommissionNodeCmd = &cobra.Command{
+	Use:   "decommission <nodeID>...",
+	Short: "decommissions the node(s)",
+	Long: `
+Marks the nodes with the supplied IDs as decommissioning. This causes their
+replicas to be moved to other nodes, and reports the number of replicas left
+on each node until none remain. A decommissioning node refuses to be shut down
+with the quit command as long as it holds replicas.
+`,
+	RunE: MaybeDecorateGRPCError(runDecommissionNode),
+}
+
+func parseNodeIDs(strNodeIDs []string) ([]roachpb.NodeID, error) {
+	nodeIDs := make([]roachpb.NodeID, 0, len(strNodeIDs))
+	for _, str := range strNodeIDs {
+		i, err := strconv.ParseInt(str, 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("unable to parse %s: %s", str, err)
+		}
+		nodeIDs = append(nodeIDs, roachpb.NodeID(i))
+	}
+	return nodeIDs, nil
+}
+
+func runDecommissionNode(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return usageAndError(cmd)
+	}
+	nodeIDs, err := parseNodeIDs(args)
+	if err != nil {
+		return err
+	}
+
+	c, stopper, err := getAdminClient()
+	if err != nil {
+		return err
+	}
+	ctx := stopperContext(stopper)
+	defer stopper.Stop(ctx)
+
+	return runDecommissionNodeImpl(ctx, c, nodeDecommissionWait, nodeIDs)
+}
+
+func runDecommissionNodeImpl(
+	ctx context.Context,
+	c serverpb.AdminClient,
+	wait nodeDecommissionWaitType,
+	nodeIDs []roachpb.NodeID,
+) error {
+	opts := retry.Options{
+		InitialBackoff: 5 * time.Millisecond,
+		Multiplier:     2,
+		MaxBackoff:     20 * time.Second,
+	}
+	var prevResponse *serverpb.DecommissionStatusResponse
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		req := &serverpb.DecommissionRequest{
+			NodeIDs:         nodeIDs,
+			Decommissioning: true,
+		}
+		resp, err := c.Decommission(ctx, req)
+		if err != nil {
+			return errors.Wrap(err, "while trying to mark as decommissioning")
+		}
+		if !reflect.DeepEqual(prevResponse, resp) {
+			fmt.Fprintln(stderr)
+			if err := printDecommissionStatus(*resp); err != nil {
+				return err
+			}
+			prevResponse = resp
+		} else {
+			fmt.Fprintf(stderr, ".")
+		}
+		var replicaCount int64
+		allDecommissioning := true
+		for _, status := range resp.Status {
+			if wait != nodeDecommissionWaitLive || status.IsLive {
+				replicaCount += status.ReplicaCount
+			}
+			allDecommissioning = allDecommissioning && status.Decommissioning
+		}
+		if replicaCount == 0 && allDecommissioning {
+			fmt.Fprintln(os.Stdout, "\nAll target nodes report that they hold no more data. "+
+				"Please verify cluster health before removing the nodes.")
+			return nil
+		}
+		if wait == nodeDecommissionWaitNone {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+var recommissionNodeCmd = &cobra.Command{
+	Use:   "recommission <nodeID>...",
+	Short: "recommissions the node(s)",
+	Long: `
+For the nodes with the supplied IDs, resets the decommissioning state and
+signals the affected nodes to participate in the cluster again.
+`,
+	RunE: MaybeDecorateGRPCError(runRecommissionNode),
+}
+
+func runRecommissionNode(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return usageAndError(cmd)
+	}
+	nodeIDs, err := parseNodeIDs(args)
+	if err != nil {
+		return err
+	}
+
+	c, stopper, err := getAdminClient()
+	if err != nil {
+		return err
+	}
+	ctx := stopperContext(stopper)
+	defer stopper.Stop(ctx)
+
+	req := &serverpb.DecommissionRequest{
+		NodeIDs:         nodeIDs,
+		Decommissioning: false,
+	}
+	resp, err := c.Decommission(ctx, req)
+	if err != nil {
+		return err
+	}
+	return printDecommissionStatus(*resp)
+}
+
+func printDecommissionStatus(resp serverpb.DecommissionStatusResponse) error {
+	var rows [][]string
+	for _, status := range resp.Status {
+		rows = append(rows, []string{
+			strconv.FormatInt(int64(status.NodeID), 10),
+			strconv.FormatBool(status.IsLive),
+			strconv.FormatInt(status.ReplicaCount, 10),
+			strconv.FormatBool(status.Decommissioning),
+			strconv.FormatBool(status.Draining),
+		})
+	}
+	return printQueryOutput(os.Stdout, decommissionNodesColumnHeaders,
+		newRowSliceIter(rows), "", cliCtx.tableDisplayFormat)
+}
+
+var drainNodeCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "drain the node without shutting it down",
+	Long: `
+Drains the node the command connects to: the node stops accepting new SQL
+connections, waits for the sessions with ongoing transactions to finish for up
+to the duration of the server.shutdown.query_wait cluster setting, and
+transfers its range leases to other nodes. The node keeps running in this state
+until it is restarted, so that it can then be stopped without client errors or
+leaseholder failovers.
+`,
+	RunE: MaybeDecorateGRPCError(runDrainNode),
+}
+
+func runDrainNode(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return usageAndError(cmd)
+	}
+
+	c, stopper, err := getAdminClient()
+	if err != nil {
+		return err
+	}
+	ctx := stopperContext(stopper)
+	defer stopper.Stop(ctx)
+
+	stream, err := c.Drain(ctx, &serverpb.DrainRequest{
+		On:       gracefulDrainModes(),
+		Shutdown: false,
+	})
+	if err != nil {
+		return errors.Wrap(err, "while trying to drain the node")
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "while trying to drain the node")
+		}
+	}
+	fmt.Println("ok")
+	return nil
+}
+
 // Sub-commands for node command.
 var nodeCmds = []*cobra.Command{
 	lsNodesCmd,
 	statusNodeCmd,
+	decommissionNodeCmd,
+	recommissionNodeCmd,
+	drainNodeCmd,
 }
 
 var nodeCmd = &cobra.Command{
 	Use:   "node [command]",
-	Short: "list nodes and show their status",
-	Long:  "List nodes and show their status.",
+	Short: "list, inspect, drain or decommission nodes",
+	Long:  "List, inspect, drain or decommission nodes.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cmd.Usage()
 	},
@@ -1001,6 +1001,10 @@ func runTerm(cmd *cobra.Command, args []string) error {
 		// Single-line sql; run as simple as possible, without noise on stdout.
 		return runStatements(conn, sqlCtx.execStmts, cliCtx.tableDisplayFormat)
 	}
+	if isInteractive {
+		applyDiagnosticsConsent(true /* prompt */)
+	}
+
 	// Use the same as the default global readline config.
 	conf := readline.Config{
 		DisableAutoSaveHistory: true,
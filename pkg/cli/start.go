@@ -30,6 +30,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strings"
 	"syscall"
 	"text/tabwriter"
@@ -38,6 +39,7 @@ import (
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
@@ -47,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -280,6 +283,44 @@ func initBlockProfile() {
 // can change this.
 var ErrorCode = 1
 
+// makeStartupBanner returns the startup banner of the node s, started
+// by cmd. status describes how the node joined the cluster.
+func makeStartupBanner(cmd *cobra.Command, s *server.Server, status string) log.StartupBanner {
+	b := log.StartupBanner{
+		Directories: map[string]string{},
+		Details: map[string]string{
+			"status":     status,
+			"cluster_id": s.ClusterID().String(),
+			"node_id":    s.NodeID().String(),
+			"admin":      serverCfg.AdminURL(),
+		},
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		b.SettingsSources = append(b.SettingsSources, "--"+f.Name)
+	})
+	envVarsUsed := envutil.GetEnvVarsUsed()
+	sort.Strings(envVarsUsed)
+	for _, v := range envVarsUsed {
+		b.SettingsSources = append(b.SettingsSources, "env:"+v)
+	}
+	if len(serverCfg.SocketFile) != 0 {
+		b.Directories["socket"] = serverCfg.SocketFile
+	}
+	for i, spec := range serverCfg.Stores.Specs {
+		if spec.InMemory {
+			continue
+		}
+		b.Directories[fmt.Sprintf("store[%d]", i)] = spec.Path
+	}
+	if serverCfg.Attrs != "" {
+		b.Details["attrs"] = serverCfg.Attrs
+	}
+	if len(serverCfg.Locality.Tiers) > 0 {
+		b.Details["locality"] = serverCfg.Locality.String()
+	}
+	return b
+}
+
 // runStart starts the cockroach node using --store as the list of
 // storage devices ("stores") on this machine and --join as the list
 // of other active nodes used to join this node to the cockroach
@@ -339,9 +380,6 @@ func runStart(cmd *cobra.Command, args []string) error {
 			}
 
 			log.Info(startCtx, "starting cockroach node")
-			if envVarsUsed := envutil.GetEnvVarsUsed(); len(envVarsUsed) > 0 {
-				log.Infof(startCtx, "using local environment variables: %s", strings.Join(envVarsUsed, ", "))
-			}
 
 			var err error
 			s, err = server.NewServer(serverCfg, stopper)
@@ -376,6 +414,16 @@ func runStart(cmd *cobra.Command, args []string) error {
 				s.PeriodicallyCheckForUpdates()
 			}
 
+			// Send the crash reports which previous runs could not send.
+			s.PeriodicallyUploadSpooledCrashReports()
+
+			// Let operators know early if crash reports cannot be sent.
+			if err := stopper.RunAsyncTask(
+				s.AnnotateCtx(context.Background()), "crash reporter check", log.CheckCrashReporter,
+			); err != nil {
+				return err
+			}
+
 			pgURL, err := serverCfg.PGURL(url.User(sqlConnUser))
 			if err != nil {
 				return err
@@ -403,24 +451,25 @@ func runStart(cmd *cobra.Command, args []string) error {
 			}
 			initialBoot := s.InitialBoot()
 			nodeID := s.NodeID()
+			var status string
 			if initialBoot {
 				if nodeID == server.FirstNodeID {
-					fmt.Fprintf(tw, "status:\tinitialized new cluster\n")
+					status = "initialized new cluster"
 				} else {
-					fmt.Fprintf(tw, "status:\tinitialized new node, joined pre-existing cluster\n")
+					status = "initialized new node, joined pre-existing cluster"
 				}
 			} else {
-				fmt.Fprintf(tw, "status:\trestarted pre-existing node\n")
+				status = "restarted pre-existing node"
 			}
+			fmt.Fprintf(tw, "status:\t%s\n", status)
 			fmt.Fprintf(tw, "clusterID:\t%s\n", s.ClusterID())
 			fmt.Fprintf(tw, "nodeID:\t%d\n", nodeID)
 			if err := tw.Flush(); err != nil {
 				return err
 			}
-			msg := buf.String()
-			log.Infof(startCtx, "node startup completed:\n%s", msg)
+			log.LogStartupBanner(startCtx, makeStartupBanner(cmd, s, status))
 			if !log.LoggingToStderr(log.Severity_INFO) {
-				fmt.Print(msg)
+				fmt.Print(buf.String())
 			}
 			return nil
 		}(); err != nil {
@@ -431,6 +480,9 @@ func runStart(cmd *cobra.Command, args []string) error {
 	shutdownSpan := tracer.StartSpan("server shutdown")
 	defer shutdownSpan.Finish()
 	shutdownCtx := opentracing.ContextWithSpan(context.Background(), shutdownSpan)
+	// Deliver the entries buffered by the log sinks before the process
+	// exits, however the server stops.
+	defer log.FlushAll(shutdownCtx)
 	var returnErr error
 
 	// Block until one of the signals above is received or the stopper
@@ -557,23 +609,29 @@ func setupAndInitializeLoggingAndProfiling(startCtx context.Context) (*stop.Stop
 		outputDirectory = logDir
 
 		ls := pf.Lookup(logflags.LogToStderrName)
-		if !ls.Changed {
-			// Unless the settings were overridden by the user, silence
-			// logging to stderr because the messages will go to a log file.
+		if !ls.Changed && !pf.Lookup(logflags.LogProfileName).Changed {
+			// Unless the settings were overridden by the user, either
+			// directly or with a logging profile, silence logging to
+			// stderr because the messages will go to a log file.
 			if err := ls.Value.Set(log.Severity_NONE.String()); err != nil {
 				return nil, err
 			}
 		}
 
-		// Make sure the path exists.
-		if err := os.MkdirAll(logDir, 0755); err != nil {
+		// Make sure the path exists. If the file system is read-only, the
+		// log files are disabled below instead.
+		if err := os.MkdirAll(logDir, 0755); err != nil && !log.IsReadOnlyError(err) {
 			return nil, err
 		}
-		log.Eventf(startCtx, "created log directory %s", logDir)
-
-		// Start the log file GC daemon to remove files that make the log
-		// directory too large.
-		log.StartGCDaemon()
+		if log.FallBackIfLogDirReadOnly(startCtx) {
+			outputDirectory = "."
+		} else {
+			log.Eventf(startCtx, "created log directory %s", logDir)
+
+			// Start the log file GC daemon to remove files that make the log
+			// directory too large.
+			log.StartGCDaemon()
+		}
 	}
 
 	if ambiguousLogDirs {
@@ -604,6 +662,22 @@ func setupAndInitializeLoggingAndProfiling(startCtx context.Context) (*stop.Stop
 	info := build.GetInfo()
 	log.Infof(startCtx, info.Short())
 
+	if overrides := settings.LocalOverrides(); len(overrides) > 0 {
+		log.Infof(startCtx, "cluster settings overridden on this node: %s", strings.Join(overrides, ", "))
+	}
+
+	// Report why the previous process using the same log directory went
+	// away, if it did not shut down cleanly.
+	if reason, ok, err := log.ReadLastExitReason(); err != nil {
+		log.Warningf(startCtx, "unable to read the exit reason of the previous process: %s", err)
+	} else if ok {
+		log.Warningf(startCtx, "previous process exited abnormally: %s", reason)
+		log.SetRestartCount(reason.Restarts)
+	}
+	if err := log.RotateLastExitReason(); err != nil {
+		log.Warningf(startCtx, "unable to rotate the exit reason of the previous process: %s", err)
+	}
+
 	initMemProfile(startCtx, outputDirectory)
 	initCPUProfile(startCtx, outputDirectory)
 	initBlockProfile()
@@ -744,6 +818,16 @@ func doShutdown(ctx context.Context, c serverpb.AdminClient, onModes []int32) er
 
 type errTryHardShutdown struct{ error }
 
+// gracefulDrainModes returns the drain modes entered by a node before it
+// shuts down gracefully, as expected in a DrainRequest.
+func gracefulDrainModes() []int32 {
+	onModes := make([]int32, len(server.GracefulDrainModes))
+	for i, m := range server.GracefulDrainModes {
+		onModes[i] = int32(m)
+	}
+	return onModes
+}
+
 // runQuit accesses the quit shutdown path.
 func runQuit(_ *cobra.Command, _ []string) (err error) {
 	defer func() {
@@ -751,10 +835,7 @@ func runQuit(_ *cobra.Command, _ []string) (err error) {
 			fmt.Println("ok")
 		}
 	}()
-	onModes := make([]int32, len(server.GracefulDrainModes))
-	for i, m := range server.GracefulDrainModes {
-		onModes[i] = int32(m)
-	}
+	onModes := gracefulDrainModes()
 
 	c, stopper, err := getAdminClient()
 	if err != nil {
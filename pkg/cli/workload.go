@@ -0,0 +1,671 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codahale/hdrhistogram"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var workloadCmd = &cobra.Command{
+	Use:   "workload [command]",
+	Short: "generate load against a cluster\n",
+	Long: `
+Generate load against a cluster using one of the built-in workloads, and print
+a summary of the throughput and latency of its operations. Each workload first
+creates its schema and loads its initial data if they don't exist yet.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+var workloadKVCmd = &cobra.Command{
+	Use:   "kv",
+	Short: "read and write random keys of a key-value table",
+	Long: `
+Read and write the rows of the kv.kv table, picking keys uniformly at random
+among --rows keys.
+`,
+	RunE: MaybeDecorateGRPCError(func(cmd *cobra.Command, args []string) error {
+		return runWorkload(cmd, args, kvWorkload{
+			rows:        workloadCtx.kvRows,
+			readPercent: workloadCtx.kvReadPercent,
+		})
+	}),
+}
+
+var workloadBankCmd = &cobra.Command{
+	Use:   "bank",
+	Short: "transfer money between the accounts of a bank",
+	Long: `
+Transfer random amounts between random pairs of the --rows accounts of the
+bank.accounts table, and read the balance of random accounts.
+`,
+	RunE: MaybeDecorateGRPCError(func(cmd *cobra.Command, args []string) error {
+		return runWorkload(cmd, args, bankWorkload{
+			accounts:    workloadCtx.bankRows,
+			readPercent: workloadCtx.bankReadPercent,
+		})
+	}),
+}
+
+var workloadTPCCCmd = &cobra.Command{
+	Use:   "tpcc",
+	Short: "run a simplified TPC-C-like order entry workload",
+	Long: `
+Run a simplified version of the TPC-C order entry workload against the tables
+of the tpcc database. The writes are evenly split between the new order and
+payment transactions, and the reads between the order status and stock level
+transactions.
+
+This workload is loosely modeled after TPC-C and its results are not
+comparable to TPC-C results.
+`,
+	RunE: MaybeDecorateGRPCError(func(cmd *cobra.Command, args []string) error {
+		return runWorkload(cmd, args, tpccWorkload{
+			warehouses:  workloadCtx.tpccWarehouses,
+			readPercent: workloadCtx.tpccReadPercent,
+		})
+	}),
+}
+
+var workloadCmds = []*cobra.Command{
+	workloadKVCmd,
+	workloadBankCmd,
+	workloadTPCCCmd,
+}
+
+func init() {
+	workloadCmd.AddCommand(workloadCmds...)
+}
+
+// A workload creates its schema and initial data, and runs random operations
+// against them.
+type workload interface {
+	// validate checks the parameters of the workload.
+	validate() error
+	// setup creates the schema of the workload and loads its initial data if
+	// they don't exist yet.
+	setup(conn *sqlConn) error
+	// run runs a random operation and returns its name.
+	run(conn *sqlConn, rng *rand.Rand) (string, error)
+}
+
+func runWorkload(cmd *cobra.Command, args []string, w workload) error {
+	if len(args) > 0 {
+		return usageAndError(cmd)
+	}
+	if workloadCtx.concurrency < 1 {
+		return errors.Errorf("--%s must be positive", cliflags.WorkloadConcurrency.Name)
+	}
+	if err := w.validate(); err != nil {
+		return err
+	}
+
+	conn, err := getPasswordAndMakeSQLClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	fmt.Println("creating the schema and loading the initial data...")
+	if err := w.setup(conn); err != nil {
+		return err
+	}
+	if workloadCtx.initOnly {
+		return nil
+	}
+
+	stats := newWorkloadStats()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workloadCtx.concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			workerConn := makeSQLConn(conn.url)
+			defer workerConn.Close()
+			rng := rand.New(rand.NewSource(seed))
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				start := timeutil.Now()
+				op, err := w.run(workerConn, rng)
+				stats.record(op, timeutil.Since(start), err)
+			}
+		}(timeutil.Now().UnixNano() + int64(i))
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	var timeout <-chan time.Time
+	if workloadCtx.duration > 0 {
+		timeout = time.After(workloadCtx.duration)
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	start := timeutil.Now()
+	fmt.Println("_elapsed___ops/sec(inst)___ops/sec(cum)___errors")
+	var lastOps int64
+	for running := true; running; {
+		select {
+		case <-ticker.C:
+			ops, errs := stats.totals()
+			elapsed := timeutil.Since(start)
+			fmt.Printf("%7.0fs %16.1f %14.1f %8d\n", elapsed.Seconds(),
+				float64(ops-lastOps), float64(ops)/elapsed.Seconds(), errs)
+			lastOps = ops
+		case <-timeout:
+			running = false
+		case <-signals:
+			running = false
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	fmt.Println()
+	return stats.printSummary(os.Stdout, timeutil.Since(start))
+}
+
+// workloadMaxLatency is the largest latency recorded by the histograms of
+// the workload statistics. Larger latencies are recorded as this value.
+const workloadMaxLatency = time.Minute
+
+// workloadStats collects the number of operations, errors and latencies of
+// each type of operation of a workload.
+type workloadStats struct {
+	// ops and errors are the total number of operations and errors. They are
+	// accessed atomically.
+	ops, errors int64
+
+	mu struct {
+		syncutil.Mutex
+		byOp map[string]*workloadOpStats
+		// lastErr is the most recent error returned by an operation.
+		lastErr error
+	}
+}
+
+type workloadOpStats struct {
+	ops, errors int64
+	latency     *hdrhistogram.Histogram
+}
+
+func newWorkloadStats() *workloadStats {
+	s := &workloadStats{}
+	s.mu.byOp = make(map[string]*workloadOpStats)
+	return s
+}
+
+// record records an operation which took the given latency.
+func (s *workloadStats) record(op string, latency time.Duration, err error) {
+	atomic.AddInt64(&s.ops, 1)
+	if err != nil {
+		atomic.AddInt64(&s.errors, 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	opStats, ok := s.mu.byOp[op]
+	if !ok {
+		opStats = &workloadOpStats{
+			latency: hdrhistogram.New(1, int64(workloadMaxLatency/time.Microsecond), 2),
+		}
+		s.mu.byOp[op] = opStats
+	}
+	opStats.ops++
+	if err != nil {
+		opStats.errors++
+		s.mu.lastErr = err
+		return
+	}
+	if opStats.latency.RecordValue(int64(latency/time.Microsecond)) != nil {
+		_ = opStats.latency.RecordValue(int64(workloadMaxLatency / time.Microsecond))
+	}
+}
+
+// totals returns the total number of operations and errors.
+func (s *workloadStats) totals() (ops int64, errors int64) {
+	return atomic.LoadInt64(&s.ops), atomic.LoadInt64(&s.errors)
+}
+
+// printSummary prints the throughput and latency of each type of operation,
+// as measured over the given duration.
+func (s *workloadStats) printSummary(w io.Writer, elapsed time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ops := make([]string, 0, len(s.mu.byOp))
+	for op := range s.mu.byOp {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	millis := func(h *hdrhistogram.Histogram, q float64) float64 {
+		return float64(h.ValueAtQuantile(q)) / 1000
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tops\terrors\tops/sec\tp50(ms)\tp95(ms)\tp99(ms)\tmax(ms)\t")
+	for _, op := range ops {
+		opStats := s.mu.byOp[op]
+		h := opStats.latency
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n",
+			op, opStats.ops, opStats.errors, float64(opStats.ops)/elapsed.Seconds(),
+			millis(h, 50), millis(h, 95), millis(h, 99), float64(h.Max())/1000)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if s.mu.lastErr != nil {
+		fmt.Fprintf(w, "\nlast error: %s\n", s.mu.lastErr)
+	}
+	return nil
+}
+
+// queryAll runs the query and returns the values of all its rows.
+func queryAll(conn *sqlConn, query string, args ...driver.Value) ([][]driver.Value, error) {
+	rows, err := makeQuery(query, args...)(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var result [][]driver.Value
+	for {
+		vals := make([]driver.Value, len(rows.Columns()))
+		if err := rows.Next(vals); err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+		result = append(result, vals)
+	}
+}
+
+// workloadBatchSize is the number of rows inserted by each statement loading
+// the initial data of a workload.
+const workloadBatchSize = 500
+
+// insertRows inserts n rows into the table, skipping the rows which already
+// exist. The row function returns the tuple of values of the i-th row.
+func insertRows(conn *sqlConn, table string, n int, row func(i int) string) error {
+	var buf bytes.Buffer
+	for start := 0; start < n; start += workloadBatchSize {
+		buf.Reset()
+		fmt.Fprintf(&buf, "INSERT INTO %s VALUES ", table)
+		for i := start; i < n && i < start+workloadBatchSize; i++ {
+			if i > start {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(row(i))
+		}
+		buf.WriteString(" ON CONFLICT DO NOTHING")
+		if err := conn.Exec(buf.String(), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execStatements executes each of the statements.
+func execStatements(conn *sqlConn, stmts ...string) error {
+	for _, stmt := range stmts {
+		if err := conn.Exec(stmt, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateReadPercent(readPercent int) error {
+	if readPercent < 0 || readPercent > 100 {
+		return errors.Errorf("invalid read percentage %d; expected a value between 0 and 100",
+			readPercent)
+	}
+	return nil
+}
+
+// kvValueSize is the size of the values written by the kv workload.
+const kvValueSize = 64
+
+type kvWorkload struct {
+	rows        int
+	readPercent int
+}
+
+func (w kvWorkload) validate() error {
+	if w.rows < 1 {
+		return errors.Errorf("invalid number of rows %d; expected a positive value", w.rows)
+	}
+	return validateReadPercent(w.readPercent)
+}
+
+func (w kvWorkload) setup(conn *sqlConn) error {
+	return execStatements(conn,
+		`CREATE DATABASE IF NOT EXISTS kv`,
+		`CREATE TABLE IF NOT EXISTS kv.kv (k INT PRIMARY KEY, v BYTES NOT NULL)`,
+	)
+}
+
+func (w kvWorkload) run(conn *sqlConn, rng *rand.Rand) (string, error) {
+	k := int64(rng.Intn(w.rows))
+	if rng.Intn(100) < w.readPercent {
+		_, err := queryAll(conn, `SELECT v FROM kv.kv WHERE k = $1`, k)
+		return "read", err
+	}
+	v := make([]byte, kvValueSize)
+	_, _ = rng.Read(v)
+	return "write", conn.Exec(`UPSERT INTO kv.kv (k, v) VALUES ($1, $2)`, []driver.Value{k, v})
+}
+
+const (
+	// bankInitialBalance is the initial balance of each account of the bank
+	// workload.
+	bankInitialBalance = 1000
+	// bankMaxTransfer is the largest amount transferred between two accounts.
+	bankMaxTransfer = 100
+)
+
+type bankWorkload struct {
+	accounts    int
+	readPercent int
+}
+
+func (w bankWorkload) validate() error {
+	if w.accounts < 2 {
+		return errors.Errorf("invalid number of accounts %d; expected at least 2", w.accounts)
+	}
+	return validateReadPercent(w.readPercent)
+}
+
+func (w bankWorkload) setup(conn *sqlConn) error {
+	if err := execStatements(conn,
+		`CREATE DATABASE IF NOT EXISTS bank`,
+		`CREATE TABLE IF NOT EXISTS bank.accounts (id INT PRIMARY KEY, balance INT NOT NULL)`,
+	); err != nil {
+		return err
+	}
+	return insertRows(conn, "bank.accounts", w.accounts, func(i int) string {
+		return fmt.Sprintf("(%d, %d)", i, bankInitialBalance)
+	})
+}
+
+func (w bankWorkload) run(conn *sqlConn, rng *rand.Rand) (string, error) {
+	from := int64(rng.Intn(w.accounts))
+	if rng.Intn(100) < w.readPercent {
+		_, err := queryAll(conn, `SELECT balance FROM bank.accounts WHERE id = $1`, from)
+		return "balance", err
+	}
+	to := int64(rng.Intn(w.accounts - 1))
+	if to >= from {
+		to++
+	}
+	amount := int64(rng.Intn(bankMaxTransfer) + 1)
+	// The transfer is a single statement so that the server retries it
+	// automatically in case of conflicts.
+	const transfer = `
+UPDATE bank.accounts
+   SET balance = CASE id WHEN $1 THEN balance - $3 WHEN $2 THEN balance + $3 END
+ WHERE id IN ($1, $2)`
+	return "transfer", conn.Exec(transfer, []driver.Value{from, to, amount})
+}
+
+const (
+	tpccDistrictsPerWarehouse = 10
+	tpccCustomersPerDistrict  = 300
+	tpccItems                 = 1000
+	// tpccMinOrderLines and tpccMaxOrderLines bound the number of lines of
+	// each new order.
+	tpccMinOrderLines = 5
+	tpccMaxOrderLines = 15
+	// tpccStockThreshold is the quantity below which the stock level
+	// transaction counts the items recently ordered in a district.
+	tpccStockThreshold = 15
+)
+
+var tpccSchema = []string{
+	`CREATE DATABASE IF NOT EXISTS tpcc`,
+	`CREATE TABLE IF NOT EXISTS tpcc.warehouse (
+  w_id INT PRIMARY KEY,
+  w_ytd INT NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS tpcc.district (
+  d_w_id INT,
+  d_id INT,
+  d_ytd INT NOT NULL,
+  d_next_o_id INT NOT NULL,
+  PRIMARY KEY (d_w_id, d_id)
+)`,
+	`CREATE TABLE IF NOT EXISTS tpcc.customer (
+  c_w_id INT,
+  c_d_id INT,
+  c_id INT,
+  c_balance INT NOT NULL,
+  PRIMARY KEY (c_w_id, c_d_id, c_id)
+)`,
+	`CREATE TABLE IF NOT EXISTS tpcc.orders (
+  o_w_id INT,
+  o_d_id INT,
+  o_id INT,
+  o_c_id INT NOT NULL,
+  o_ol_cnt INT NOT NULL,
+  o_entry_d TIMESTAMP NOT NULL,
+  PRIMARY KEY (o_w_id, o_d_id, o_id),
+  INDEX order_customer (o_w_id, o_d_id, o_c_id, o_id)
+)`,
+	`CREATE TABLE IF NOT EXISTS tpcc.order_line (
+  ol_w_id INT,
+  ol_d_id INT,
+  ol_o_id INT,
+  ol_number INT,
+  ol_i_id INT NOT NULL,
+  ol_quantity INT NOT NULL,
+  PRIMARY KEY (ol_w_id, ol_d_id, ol_o_id, ol_number)
+)`,
+	`CREATE TABLE IF NOT EXISTS tpcc.stock (
+  s_w_id INT,
+  s_i_id INT,
+  s_quantity INT NOT NULL,
+  PRIMARY KEY (s_w_id, s_i_id)
+)`,
+}
+
+type tpccWorkload struct {
+	warehouses  int
+	readPercent int
+}
+
+func (w tpccWorkload) validate() error {
+	if w.warehouses < 1 {
+		return errors.Errorf("invalid number of warehouses %d; expected a positive value",
+			w.warehouses)
+	}
+	return validateReadPercent(w.readPercent)
+}
+
+func (w tpccWorkload) setup(conn *sqlConn) error {
+	if err := execStatements(conn, tpccSchema...); err != nil {
+		return err
+	}
+	if err := insertRows(conn, "tpcc.warehouse", w.warehouses, func(i int) string {
+		return fmt.Sprintf("(%d, 0)", i)
+	}); err != nil {
+		return err
+	}
+	for wID := 0; wID < w.warehouses; wID++ {
+		if err := insertRows(conn, "tpcc.district", tpccDistrictsPerWarehouse, func(i int) string {
+			return fmt.Sprintf("(%d, %d, 0, 1)", wID, i)
+		}); err != nil {
+			return err
+		}
+		const customers = tpccDistrictsPerWarehouse * tpccCustomersPerDistrict
+		if err := insertRows(conn, "tpcc.customer", customers, func(i int) string {
+			return fmt.Sprintf("(%d, %d, %d, 0)",
+				wID, i/tpccCustomersPerDistrict, i%tpccCustomersPerDistrict)
+		}); err != nil {
+			return err
+		}
+		if err := insertRows(conn, "tpcc.stock", tpccItems, func(i int) string {
+			return fmt.Sprintf("(%d, %d, 100)", wID, i)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w tpccWorkload) run(conn *sqlConn, rng *rand.Rand) (string, error) {
+	wID := int64(rng.Intn(w.warehouses))
+	dID := int64(rng.Intn(tpccDistrictsPerWarehouse))
+	cID := int64(rng.Intn(tpccCustomersPerDistrict))
+	if rng.Intn(100) < w.readPercent {
+		if rng.Intn(2) == 0 {
+			return "order_status", tpccOrderStatus(conn, wID, dID, cID)
+		}
+		return "stock_level", tpccStockLevel(conn, wID, dID)
+	}
+	if rng.Intn(2) == 0 {
+		return "new_order", tpccNewOrder(conn, rng, wID, dID, cID)
+	}
+	amount := int64(rng.Intn(5000) + 1)
+	return "payment", tpccPayment(conn, wID, dID, cID, amount)
+}
+
+// tpccNewOrder enters a new order of random items for the customer.
+func tpccNewOrder(conn *sqlConn, rng *rand.Rand, wID, dID, cID int64) error {
+	lines := tpccMinOrderLines + rng.Intn(tpccMaxOrderLines-tpccMinOrderLines+1)
+	items := make([]int, lines)
+	quantities := make([]int, lines)
+	for i := range items {
+		items[i] = rng.Intn(tpccItems)
+		quantities[i] = rng.Intn(10) + 1
+	}
+	// Update the stock in item order to limit deadlocks between concurrent
+	// orders.
+	sort.Ints(items)
+
+	return conn.ExecTxn(func(conn *sqlConn) error {
+		rows, err := queryAll(conn, `
+UPDATE tpcc.district SET d_next_o_id = d_next_o_id + 1
+ WHERE d_w_id = $1 AND d_id = $2
+RETURNING d_next_o_id - 1`, wID, dID)
+		if err != nil {
+			return err
+		}
+		if len(rows) != 1 {
+			return errors.Errorf("district %d of warehouse %d not found", dID, wID)
+		}
+		oID := rows[0][0]
+		if err := conn.Exec(`
+INSERT INTO tpcc.orders (o_w_id, o_d_id, o_id, o_c_id, o_ol_cnt, o_entry_d)
+VALUES ($1, $2, $3, $4, $5, now())`,
+			[]driver.Value{wID, dID, oID, cID, int64(lines)}); err != nil {
+			return err
+		}
+
+		var orderLines bytes.Buffer
+		for i, item := range items {
+			if err := conn.Exec(`
+UPDATE tpcc.stock
+   SET s_quantity = CASE WHEN s_quantity >= $3 + 10 THEN s_quantity - $3 ELSE s_quantity - $3 + 91 END
+ WHERE s_w_id = $1 AND s_i_id = $2`,
+				[]driver.Value{wID, int64(item), int64(quantities[i])}); err != nil {
+				return err
+			}
+			if i > 0 {
+				orderLines.WriteString(", ")
+			}
+			fmt.Fprintf(&orderLines, "(%d, %d, %d, %d, %d, %d)",
+				wID, dID, oID, i, item, quantities[i])
+		}
+		return conn.Exec(`INSERT INTO tpcc.order_line VALUES `+orderLines.String(), nil)
+	})
+}
+
+// tpccPayment records a payment of the customer.
+func tpccPayment(conn *sqlConn, wID, dID, cID, amount int64) error {
+	return conn.ExecTxn(func(conn *sqlConn) error {
+		if err := conn.Exec(`UPDATE tpcc.warehouse SET w_ytd = w_ytd + $2 WHERE w_id = $1`,
+			[]driver.Value{wID, amount}); err != nil {
+			return err
+		}
+		if err := conn.Exec(`
+UPDATE tpcc.district SET d_ytd = d_ytd + $3 WHERE d_w_id = $1 AND d_id = $2`,
+			[]driver.Value{wID, dID, amount}); err != nil {
+			return err
+		}
+		return conn.Exec(`
+UPDATE tpcc.customer SET c_balance = c_balance - $4
+ WHERE c_w_id = $1 AND c_d_id = $2 AND c_id = $3`,
+			[]driver.Value{wID, dID, cID, amount})
+	})
+}
+
+// tpccOrderStatus reads the balance of the customer and the lines of its
+// latest order.
+func tpccOrderStatus(conn *sqlConn, wID, dID, cID int64) error {
+	if _, err := queryAll(conn, `
+SELECT c_balance FROM tpcc.customer WHERE c_w_id = $1 AND c_d_id = $2 AND c_id = $3`,
+		wID, dID, cID); err != nil {
+		return err
+	}
+	rows, err := queryAll(conn, `
+SELECT o_id, o_entry_d FROM tpcc.orders@order_customer
+ WHERE o_w_id = $1 AND o_d_id = $2 AND o_c_id = $3
+ ORDER BY o_id DESC LIMIT 1`, wID, dID, cID)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	_, err = queryAll(conn, `
+SELECT ol_i_id, ol_quantity FROM tpcc.order_line
+ WHERE ol_w_id = $1 AND ol_d_id = $2 AND ol_o_id = $3`, wID, dID, rows[0][0])
+	return err
+}
+
+// tpccStockLevel counts the items of the last 20 orders of the district whose
+// stock is low.
+func tpccStockLevel(conn *sqlConn, wID, dID int64) error {
+	_, err := queryAll(conn, `
+SELECT count(DISTINCT s_i_id)
+  FROM tpcc.order_line JOIN tpcc.stock ON s_w_id = ol_w_id AND s_i_id = ol_i_id
+ WHERE ol_w_id = $1 AND ol_d_id = $2
+   AND ol_o_id >= (SELECT d_next_o_id - 20 FROM tpcc.district WHERE d_w_id = $1 AND d_id = $2)
+   AND s_quantity < $3`, wID, dID, int64(tpccStockThreshold))
+	return err
+}
@@ -0,0 +1,157 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestWorkloads(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingAddr(), "TestWorkloads", url.User(security.RootUser))
+	defer cleanup()
+
+	conn := makeSQLConn(pgURL.String())
+	defer conn.Close()
+
+	testCases := []struct {
+		name string
+		w    workload
+	}{
+		{"kv", kvWorkload{rows: 10, readPercent: 50}},
+		{"bank", bankWorkload{accounts: 10, readPercent: 50}},
+		{"tpcc", tpccWorkload{warehouses: 1, readPercent: 50}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.w.validate(); err != nil {
+				t.Fatal(err)
+			}
+			// The setup can be run repeatedly.
+			for i := 0; i < 2; i++ {
+				if err := tc.w.setup(conn); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rng := rand.New(rand.NewSource(0))
+			for i := 0; i < 20; i++ {
+				if op, err := tc.w.run(conn, rng); err != nil {
+					t.Fatalf("%s: %s", op, err)
+				}
+			}
+		})
+	}
+
+	// The transfers of the bank workload preserve the total balance.
+	rows, err := queryAll(conn, `SELECT sum(balance) FROM bank.accounts`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := rows[0][0]; total != int64(10*bankInitialBalance) {
+		t.Errorf("expected total balance %d, got %v", 10*bankInitialBalance, total)
+	}
+}
+
+func TestWorkloadValidate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		w        workload
+		expected string
+	}{
+		{kvWorkload{rows: 0, readPercent: 50}, "invalid number of rows"},
+		{kvWorkload{rows: 1, readPercent: 101}, "invalid read percentage"},
+		{bankWorkload{accounts: 1, readPercent: 50}, "invalid number of accounts"},
+		{tpccWorkload{warehouses: 0, readPercent: 50}, "invalid number of warehouses"},
+		{tpccWorkload{warehouses: 1, readPercent: -1}, "invalid read percentage"},
+	}
+	for i, tc := range testCases {
+		if err := tc.w.validate(); err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.expected, err)
+		}
+	}
+}
+
+func TestWorkloadStatsSummary(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stats := newWorkloadStats()
+	for i := 1; i <= 100; i++ {
+		stats.record("read", time.Duration(i)*time.Millisecond, nil)
+	}
+	stats.record("write", time.Hour, nil)
+	stats.record("write", 0, errors.New("boom"))
+
+	if ops, errs := stats.totals(); ops != 102 || errs != 1 {
+		t.Fatalf("expected 102 ops and 1 error, got %d and %d", ops, errs)
+	}
+
+	var buf bytes.Buffer
+	if err := stats.printSummary(&buf, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	header := strings.Fields(lines[0])
+	if strings.Join(header, " ") !=
+		"op ops errors ops/sec p50(ms) p95(ms) p99(ms) max(ms)" {
+		t.Fatalf("unexpected header %v", header)
+	}
+	// The latencies are approximated by the histograms.
+	approxEqual := func(s string, expected float64) bool {
+		f, err := strconv.ParseFloat(s, 64)
+		return err == nil && math.Abs(f-expected) <= expected/100
+	}
+	expected := []struct {
+		fields    []string
+		latencies []float64
+	}{
+		{[]string{"read", "100", "0", "10.0"}, []float64{50, 95, 99, 100}},
+		// Latencies above the maximum are recorded as the maximum.
+		{[]string{"write", "2", "1", "0.2"}, []float64{60000, 60000, 60000, 60000}},
+	}
+	for i, e := range expected {
+		fields := strings.Fields(lines[i+1])
+		if len(fields) != 8 || strings.Join(fields[:4], " ") != strings.Join(e.fields, " ") {
+			t.Fatalf("%d: expected %v, got %v", i, e.fields, fields)
+		}
+		for j, latency := range e.latencies {
+			if !approxEqual(fields[4+j], latency) {
+				t.Errorf("%d: expected %s to be about %.1f, got %s", i, header[4+j], latency, fields[4+j])
+			}
+		}
+	}
+	if !strings.Contains(buf.String(), "last error: boom") {
+		t.Errorf("expected the last error in the summary, got:\n%s", buf.String())
+	}
+}
@@ -17,16 +17,24 @@ package cli
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"sort"
 
 	"github.com/spf13/cobra"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 var debugZipCmd = &cobra.Command{
@@ -35,12 +43,17 @@ var debugZipCmd = &cobra.Command{
 	Long: `
 
 Gather cluster debug data into a zip file. Data includes cluster events, node
-liveness, node status, range status, node stack traces, log files, and SQL
-schema.
+liveness, cluster settings, node status, range status, node stack traces, heap
+profiles, log files, and SQL schema. The details of each node are stored in a
+subdirectory of debug/nodes.
 
 Retrieval of per-node details (status, stack traces, range status) requires the
-node to be live and operating properly. Retrieval of SQL data requires the
-cluster to be live.
+node to be live and operating properly; only the status of the nodes which are
+not live is retrieved. The most recent log files of each node are retrieved, up
+to --max-log-bytes per node. Heap profiles are retrieved over HTTP, from the
+--http-port of each node, which requires the server.remote_debugging.mode
+cluster setting to be "any". Retrieval of SQL data requires the cluster to be
+live.
 `,
 	RunE: MaybeDecorateGRPCError(runDebugZip),
 }
@@ -138,6 +151,9 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// live records which nodes are live. If the liveness of the nodes
+	// can't be retrieved, they are all assumed to be live.
+	var live map[roachpb.NodeID]bool
 	if liveness, err := admin.Liveness(ctx, &serverpb.LivenessRequest{}); err != nil {
 		if err := z.createError(livenessName, err); err != nil {
 			return err
@@ -146,6 +162,16 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 		if err := z.createJSON(livenessName, liveness); err != nil {
 			return err
 		}
+		now := timeutil.Now().UnixNano()
+		live = make(map[roachpb.NodeID]bool, len(liveness.Livenesses))
+		for _, l := range liveness.Livenesses {
+			live[l.NodeID] = l.Expiration.WallTime > now
+		}
+	}
+
+	httpClient, err := baseCfg.GetHTTPClient()
+	if err != nil {
+		return err
 	}
 
 	if settings, err := admin.Settings(ctx, &serverpb.SettingsRequest{}); err != nil {
@@ -169,6 +195,13 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 			if err := z.createJSON(prefix+"/status", node); err != nil {
 				return err
 			}
+			if isLive, ok := live[node.Desc.NodeID]; ok && !isLive {
+				if err := z.createError(prefix+"/not_live",
+					errors.New("the node is not live, only its status was retrieved")); err != nil {
+					return err
+				}
+				continue
+			}
 
 			if gossip, err := status.Gossip(ctx, &serverpb.GossipRequest{NodeId: id}); err != nil {
 				if err := z.createError(prefix+"/gossip", err); err != nil {
@@ -186,13 +219,30 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
+			if heap, err := fetchHeapProfile(httpClient, node.Desc.Address.String()); err != nil {
+				if err := z.createError(prefix+"/heap.pprof", err); err != nil {
+					return err
+				}
+			} else if err := z.createRaw(prefix+"/heap.pprof", heap); err != nil {
+				return err
+			}
+
 			if logs, err := status.LogFilesList(
 				ctx, &serverpb.LogFilesListRequest{NodeId: id}); err != nil {
 				if err := z.createError(prefix+"/logs", err); err != nil {
 					return err
 				}
 			} else {
-				for _, file := range logs.Files {
+				files, skipped := selectRecentLogFiles(logs.Files, debugCtx.zipMaxLogBytes)
+				if len(skipped) > 0 {
+					if err := z.createError(prefix+"/logs/skipped", fmt.Errorf(
+						"%d older log files exceeding the budget of %s were skipped: %s",
+						len(skipped), humanizeutil.IBytes(debugCtx.zipMaxLogBytes), skipped,
+					)); err != nil {
+						return err
+					}
+				}
+				for _, file := range files {
 					name := prefix + "/logs/" + file.Name
 					entries, err := status.LogFile(
 						ctx, &serverpb.LogFileRequest{NodeId: id, File: file.Name})
@@ -271,3 +321,48 @@ func runDebugZip(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// selectRecentLogFiles returns the most recent log files of files whose
+// combined size is within maxBytes, the most recent one being selected
+// regardless of its size, and the names of the other files.
+func selectRecentLogFiles(files []log.FileInfo, maxBytes int64) ([]log.FileInfo, []string) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Details.Time > files[j].Details.Time
+	})
+	var selected []log.FileInfo
+	var skipped []string
+	var total int64
+	for _, file := range files {
+		if len(selected) > 0 && total+file.SizeBytes > maxBytes {
+			skipped = append(skipped, file.Name)
+			continue
+		}
+		selected = append(selected, file)
+		total += file.SizeBytes
+	}
+	return selected, skipped
+}
+
+// fetchHeapProfile retrieves a heap profile from the node whose RPC
+// address is addr, on the HTTP port given by --http-port.
+func fetchHeapProfile(client http.Client, addr string) ([]byte, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	url := baseCfg.HTTPRequestScheme() + "://" + net.JoinHostPort(host, serverHTTPPort) +
+		"/debug/pprof/heap"
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s: %s", url, resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
@@ -113,16 +113,80 @@ func queryZone(conn *sqlConn, id sqlbase.ID) (config.ZoneConfig, bool, error) {
 	return zone, true, unmarshalProto(vals[0], &zone)
 }
 
+// queryZonePath returns the zone config which applies to the last element of
+// path. Placeholder zone configs, which only store the configs of a table's
+// partitions, are skipped.
 func queryZonePath(conn *sqlConn, path []sqlbase.ID) (sqlbase.ID, config.ZoneConfig, error) {
 	for i := len(path) - 1; i >= 0; i-- {
 		zone, found, err := queryZone(conn, path[i])
-		if err != nil || found {
+		if err != nil {
 			return path[i], zone, err
 		}
+		if found && !zone.IsSubzonePlaceholder() {
+			zone.Subzones, zone.SubzoneSpans = nil, nil
+			return path[i], zone, nil
+		}
 	}
 	return 0, config.ZoneConfig{}, nil
 }
 
+func queryTableDescriptor(conn *sqlConn, id sqlbase.ID) (*sqlbase.TableDescriptor, error) {
+	rows, err := makeQuery(`SELECT descriptor FROM system.descriptor WHERE id = $1`, id)(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	vals := make([]driver.Value, 1)
+	if err := rows.Next(vals); err != nil {
+		return nil, err
+	}
+	desc := &sqlbase.Descriptor{}
+	if err := unmarshalProto(vals[0], desc); err != nil {
+		return nil, err
+	}
+	tableDesc := desc.GetTable()
+	if tableDesc == nil {
+		return nil, fmt.Errorf("%s is not a table", desc.GetName())
+	}
+	return tableDesc, nil
+}
+
+// queryPartitionedTable returns the descriptor of the table at the end of path
+// after checking that its primary index has the named partition.
+func queryPartitionedTable(
+	conn *sqlConn, path []sqlbase.ID, partition string,
+) (*sqlbase.TableDescriptor, error) {
+	if len(path) != 3 {
+		return nil, fmt.Errorf("partition %q must be qualified by a database and table name", partition)
+	}
+	tableDesc, err := queryTableDescriptor(conn, path[2])
+	if err != nil {
+		return nil, err
+	}
+	if !tableDesc.PrimaryIndex.Partitioning.FindPartitionByName(partition) {
+		return nil, fmt.Errorf("partition %q does not exist on table %q", partition, tableDesc.Name)
+	}
+	return tableDesc, nil
+}
+
+// writeZone writes the zone config of the given ID, deleting it instead if it
+// is empty.
+func writeZone(conn *sqlConn, id sqlbase.ID, zone config.ZoneConfig) error {
+	if zone.NumReplicas == 0 && len(zone.Subzones) == 0 {
+		_, _, _, err := runQuery(conn, makeQuery(`DELETE FROM system.zones WHERE id=$1`, id), false)
+		return err
+	}
+	buf, err := protoutil.Marshal(&zone)
+	if err != nil {
+		return err
+	}
+	_, _, _, err = runQuery(conn, makeQuery(
+		`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
+		id, buf), false)
+	return err
+}
+
 func queryDescriptors(conn *sqlConn) (map[sqlbase.ID]*sqlbase.Descriptor, error) {
 	rows, err := makeQuery(`SELECT descriptor FROM system.descriptor`)(conn)
 	if err != nil {
@@ -200,39 +264,50 @@ func queryDescriptorIDPath(conn *sqlConn, names []string) ([]sqlbase.ID, error)
 	return path, nil
 }
 
-func parseZoneName(s string) ([]string, error) {
+// parseZoneName parses a zone name of the form database[.table[.partition]],
+// or one of the special zone names. The name of the partition, if any, is
+// returned separately from the database and table names.
+func parseZoneName(s string) (names []string, partition string, err error) {
 	switch t := strings.ToLower(s); s {
 	case defaultZoneName, metaZoneName, timeseriesZoneName, systemZoneName:
-		return []string{t}, nil
+		return []string{t}, "", nil
 	}
 
 	// TODO(knz): we are passing a name that might not be escaped correctly.
 	// See #8389.
 	tn, err := parser.ParseTableName(s)
 	if err != nil {
-		return nil, fmt.Errorf("malformed name: %s", s)
+		// A name with three parts refers to a partition of a table.
+		i := strings.LastIndexByte(s, '.')
+		if i < 0 {
+			return nil, "", fmt.Errorf("malformed name: %s", s)
+		}
+		tn, err = parser.ParseTableName(s[:i])
+		if err != nil || tn.DatabaseName == "" || i == len(s)-1 {
+			return nil, "", fmt.Errorf("malformed name: %s", s)
+		}
+		partition = parser.ReNormalizeName(s[i+1:])
 	}
 	// This is a bit of a hack: "." is not a valid database name.
 	// We use this to detect when a database name was not specified, in
 	// which case we interpret the table name as a database name below.
 	if err := tn.QualifyWithDatabase("."); err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	var names []string
 	if n := tn.Database(); n != "." {
 		names = append(names, n)
 	}
 	names = append(names, tn.Table())
-	return names, nil
+	return names, partition, nil
 }
 
 // A getZoneCmd command displays a zone config.
 var getZoneCmd = &cobra.Command{
-	Use:   "get [options] <database[.table]>",
+	Use:   "get [options] <database[.table[.partition]]>",
 	Short: "fetches and displays the zone config",
 	Long: `
-Fetches and displays the zone configuration for the specified database or
-table.
+Fetches and displays the zone configuration for the specified database, table
+or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runGetZone),
 }
@@ -244,7 +319,7 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	names, partition, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -264,6 +339,29 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if partition != "" {
+		tableDesc, err := queryPartitionedTable(conn, path, partition)
+		if err != nil {
+			return err
+		}
+		tableZone, _, err := queryZone(conn, path[len(path)-1])
+		if err != nil {
+			return err
+		}
+		if subzone := tableZone.GetSubzone(
+			uint32(tableDesc.PrimaryIndex.ID), partition,
+		); subzone != nil {
+			fmt.Println(args[0])
+			res, err := yaml.Marshal(subzone.Config)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(res))
+			return nil
+		}
+		// The partition inherits the zone config of its table.
+	}
+
 	id, zone, err := queryZonePath(conn, path)
 	if err != nil {
 		return err
@@ -347,7 +445,13 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 			name = parser.Name(dbDesc.GetName()).String() + "."
 		}
 		name += parser.Name(desc.GetName()).String()
-		output = append(output, name)
+		zone := zones[id]
+		if !zone.IsSubzonePlaceholder() {
+			output = append(output, name)
+		}
+		for _, subzone := range zone.Subzones {
+			output = append(output, name+"."+parser.Name(subzone.PartitionName).String())
+		}
 	}
 
 	for id, zoneName := range specialZonesByID {
@@ -366,10 +470,10 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 
 // A rmZoneCmd command removes a zone config.
 var rmZoneCmd = &cobra.Command{
-	Use:   "rm [options] <database[.table]>",
+	Use:   "rm [options] <database[.table[.partition]]>",
 	Short: "remove a zone config",
 	Long: `
-Remove an existing zone config for the specified database or table.
+Remove an existing zone config for the specified database, table or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runRmZone),
 }
@@ -379,7 +483,7 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	names, partition, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -404,6 +508,41 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("unable to remove special zone %s", args[0])
 		}
 
+		zone, found, err := queryZone(conn, id)
+		if err != nil {
+			return err
+		}
+
+		if partition != "" {
+			tableDesc, err := queryPartitionedTable(conn, path, partition)
+			if err != nil {
+				return err
+			}
+			var subzones []config.Subzone
+			for _, s := range zone.Subzones {
+				if s.IndexID != uint32(tableDesc.PrimaryIndex.ID) || s.PartitionName != partition {
+					subzones = append(subzones, s)
+				}
+			}
+			if len(subzones) == len(zone.Subzones) {
+				fmt.Printf("%s has no zone config\n", args[0])
+				return nil
+			}
+			zone.Subzones = subzones
+			if zone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(tableDesc, zone.Subzones); err != nil {
+				return err
+			}
+			return writeZone(conn, id, zone)
+		}
+
+		if found && len(zone.Subzones) > 0 {
+			// Keep the configs of the table's partitions in a placeholder.
+			return writeZone(conn, id, config.ZoneConfig{
+				Subzones:     zone.Subzones,
+				SubzoneSpans: zone.SubzoneSpans,
+			})
+		}
+
 		if err := runQueryAndFormatResults(conn, os.Stdout,
 			makeQuery(`DELETE FROM system.zones WHERE id=$1`, id), cliCtx.tableDisplayFormat); err != nil {
 			return err
@@ -415,11 +554,11 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 
 // A setZoneCmd command creates a new or updates an existing zone config.
 var setZoneCmd = &cobra.Command{
-	Use:   "set [options] <database[.table]> <zone-config>",
+	Use:   "set [options] <database[.table[.partition]]> <zone-config>",
 	Short: "create or update zone config for object ID",
 	Long: `
-Create or update the zone config for the specified database or table to the
-specified zone-config.
+Create or update the zone config for the specified database, table or
+partition to the specified zone-config.
 
 The zone config format has the following YAML schema:
 
@@ -437,7 +576,7 @@ constraints: [ssd, -mem]
 EOF
 
 Note that the specified zone config is merged with the existing zone config for
-the database or table.
+the database, table or partition.
 `,
 	RunE: MaybeDecorateGRPCError(runSetZone),
 }
@@ -474,7 +613,7 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 
-	names, err := parseZoneName(args[0])
+	names, partition, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -494,10 +633,30 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 				"try setting your config on the entire \"system\" database instead")
 		}
 
+		id := path[len(path)-1]
+		ownZone, _, err := queryZone(conn, id)
+		if err != nil {
+			return err
+		}
+
+		var tableDesc *sqlbase.TableDescriptor
+		if partition != "" {
+			if tableDesc, err = queryPartitionedTable(conn, path, partition); err != nil {
+				return err
+			}
+		}
+
 		_, zone, err := queryZonePath(conn, path)
 		if err != nil {
 			return err
 		}
+		var subzone *config.Subzone
+		if tableDesc != nil {
+			subzone = ownZone.GetSubzone(uint32(tableDesc.PrimaryIndex.ID), partition)
+			if subzone != nil {
+				zone = subzone.Config
+			}
+		}
 		// Convert it to proto and marshal it again to put into the table. This is a
 		// bit more tedious than taking protos directly, but yaml is a more widely
 		// understood format.
@@ -515,16 +674,29 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		buf, err := protoutil.Marshal(&zone)
-		if err != nil {
-			return fmt.Errorf("unable to parse zone config file %q: %s", args[1], err)
+		if tableDesc != nil {
+			// The partition's config is stored as a subzone of the table's
+			// config, which remains a placeholder if the table has no config of
+			// its own.
+			if subzone != nil {
+				subzone.Config = zone
+			} else {
+				ownZone.Subzones = append(ownZone.Subzones, config.Subzone{
+					IndexID:       uint32(tableDesc.PrimaryIndex.ID),
+					PartitionName: partition,
+					Config:        zone,
+				})
+			}
+			ownZone.SubzoneSpans, err = sqlbase.GenerateSubzoneSpans(tableDesc, ownZone.Subzones)
+			if err != nil {
+				return err
+			}
+		} else {
+			zone.Subzones, zone.SubzoneSpans = ownZone.Subzones, ownZone.SubzoneSpans
+			ownZone = zone
 		}
 
-		id := path[len(path)-1]
-		_, _, _, err = runQuery(conn, makeQuery(
-			`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
-			id, buf), false)
-		if err != nil {
+		if err := writeZone(conn, id, ownZone); err != nil {
 			return err
 		}
 
@@ -170,9 +170,47 @@ func (z ZoneConfig) Validate() error {
 		return fmt.Errorf("RangeMinBytes %d is greater than or equal to RangeMaxBytes %d",
 			z.RangeMinBytes, z.RangeMaxBytes)
 	}
+	for _, subzone := range z.Subzones {
+		if err := subzone.Config.Validate(); err != nil {
+			return errors.Wrapf(err, "partition %q", subzone.PartitionName)
+		}
+	}
 	return nil
 }
 
+// IsSubzonePlaceholder returns whether the zone config exists only to store
+// the subzones of a table which otherwise inherits its parent's config.
+func (z ZoneConfig) IsSubzonePlaceholder() bool {
+	return z.NumReplicas == 0 && len(z.Subzones) > 0
+}
+
+// GetSubzone returns the subzone for the given index and partition, or nil if
+// there is none.
+func (z *ZoneConfig) GetSubzone(indexID uint32, partition string) *Subzone {
+	for i := range z.Subzones {
+		if s := &z.Subzones[i]; s.IndexID == indexID && s.PartitionName == partition {
+			return s
+		}
+	}
+	return nil
+}
+
+// GetSubzoneForKey returns the subzone whose span contains key, or nil if the
+// key is not covered by any subzone.
+func (z *ZoneConfig) GetSubzoneForKey(key roachpb.RKey) *Subzone {
+	i := sort.Search(len(z.SubzoneSpans), func(i int) bool {
+		return roachpb.Key(key).Less(z.SubzoneSpans[i].EndKey)
+	})
+	if i == len(z.SubzoneSpans) {
+		return nil
+	}
+	span := z.SubzoneSpans[i]
+	if roachpb.Key(key).Compare(span.Key) < 0 {
+		return nil
+	}
+	return &z.Subzones[span.SubzoneIndex]
+}
+
 // ObjectIDForKey returns the object ID (table or database) for 'key',
 // or (_, false) if not within the structured key space.
 func ObjectIDForKey(key roachpb.RKey) (uint32, bool) {
@@ -367,7 +405,14 @@ func (s SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (ZoneConfig, error)
 		objectID = keys.SystemRangesID
 	}
 
-	return s.getZoneConfigForID(objectID)
+	zone, err := s.getZoneConfigForID(objectID)
+	if err != nil {
+		return ZoneConfig{}, err
+	}
+	if subzone := zone.GetSubzoneForKey(key); subzone != nil {
+		return subzone.Config, nil
+	}
+	return zone, nil
 }
 
 // getZoneConfigForID looks up the zone config for the object (table or database)
@@ -416,10 +461,11 @@ var StaticSplits = []struct {
 // to split the span [start, end). Returns nil if no splits are required.
 //
 // Splits are required between user tables (i.e. /table/<id>), at the start
-// of the system-config tables (i.e. /table/0), and at certain points within the
-// system ranges that come before the system tables. The system-config range is
-// somewhat special in that it can contain multiple SQL tables
-// (/table/0-/table/<max-system-config-desc>) within a single range.
+// of the system-config tables (i.e. /table/0), at the boundaries of the
+// partitions of a table that have their own zone config, and at certain points
+// within the system ranges that come before the system tables. The
+// system-config range is somewhat special in that it can contain multiple SQL
+// tables (/table/0-/table/<max-system-config-desc>) within a single range.
 func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKey {
 	// Before dealing with splits necessitated by SQL tables, handle all of the
 	// static splits earlier in the keyspace. Note that this list must be kept in
@@ -450,7 +496,11 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 		startID = keys.MaxSystemConfigDescID + 1
 	} else {
 		// The start key is either already a split key, or after the split
-		// key for its ID. We can skip straight to the next one.
+		// key for its ID. Split at the partitions of its table, if any, before
+		// skipping straight to the next ID.
+		if splitKey := s.subzoneSplitKey(startID, startKey, endKey); splitKey != nil {
+			return splitKey
+		}
 		startID++
 	}
 
@@ -501,6 +551,27 @@ func (s SystemConfig) ComputeSplitKey(startKey, endKey roachpb.RKey) roachpb.RKe
 	return findSplitKey(startID, endID)
 }
 
+// subzoneSplitKey returns the first boundary of a subzone span of the table
+// with the given ID which falls within (startKey, endKey), or nil if there is
+// none.
+func (s SystemConfig) subzoneSplitKey(id uint32, startKey, endKey roachpb.RKey) roachpb.RKey {
+	zone, err := s.getZoneConfigForID(id)
+	if err != nil {
+		log.Errorf(context.TODO(), "unable to determine zone config for object %d: %s", id, err)
+		return nil
+	}
+	for _, span := range zone.SubzoneSpans {
+		for _, key := range []roachpb.RKey{roachpb.RKey(span.Key), roachpb.RKey(span.EndKey)} {
+			if startKey.Less(key) && key.Less(endKey) {
+				// Partition boundaries are prefixes of row keys, so they are made
+				// into row sentinels like the table boundaries above.
+				return roachpb.RKey(keys.MakeRowSentinelKey(key))
+			}
+		}
+	}
+	return nil
+}
+
 // NeedsSplit returns whether the range [startKey, endKey) needs a split due
 // to zone configs.
 func (s SystemConfig) NeedsSplit(startKey, endKey roachpb.RKey) bool {
@@ -425,6 +425,44 @@ func TestGetZoneConfigForKey(t *testing.T) {
 	}
 }
 
+func TestGetSubzoneForKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	zone := config.ZoneConfig{
+		Subzones: []config.Subzone{
+			{IndexID: 1, PartitionName: "p0", Config: config.ZoneConfig{NumReplicas: 1}},
+			{IndexID: 1, PartitionName: "p1", Config: config.ZoneConfig{NumReplicas: 5}},
+		},
+		SubzoneSpans: []config.SubzoneSpan{
+			{Key: roachpb.Key("b"), EndKey: roachpb.Key("c"), SubzoneIndex: 1},
+			{Key: roachpb.Key("c"), EndKey: roachpb.Key("d"), SubzoneIndex: 0},
+			{Key: roachpb.Key("f"), EndKey: roachpb.Key("g"), SubzoneIndex: 1},
+		},
+	}
+
+	testCases := []struct {
+		key       string
+		partition string
+	}{
+		{"a", ""},
+		{"b", "p1"},
+		{"bz", "p1"},
+		{"c", "p0"},
+		{"d", ""},
+		{"f", "p1"},
+		{"g", ""},
+	}
+	for _, tc := range testCases {
+		var partition string
+		if subzone := zone.GetSubzoneForKey(roachpb.RKey(tc.key)); subzone != nil {
+			partition = subzone.PartitionName
+		}
+		if partition != tc.partition {
+			t.Errorf("%s: expected partition %q, got %q", tc.key, tc.partition, partition)
+		}
+	}
+}
+
 func TestZoneConfigValidate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
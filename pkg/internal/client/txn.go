@@ -57,6 +57,9 @@ type Txn struct {
 		Proto roachpb.Transaction
 		// UserPriority is the transaction's priority.
 		UserPriority roachpb.UserPriority
+		// followerReads is set if the reads of the transaction may be served
+		// by followers (see SetFollowerReads).
+		followerReads bool
 		// txnAnchorKey is the key at which to anchor the transaction record. If
 		// unset, the first key written in the transaction will be used.
 		txnAnchorKey roachpb.Key
@@ -811,6 +814,7 @@ func (txn *Txn) send(
 		if txn.mu.UserPriority != 0 {
 			ba.UserPriority = txn.mu.UserPriority
 		}
+		ba.FollowerRead = txn.mu.followerReads
 
 		needBeginTxn = !(txn.mu.Proto.Writing || txn.mu.writingTxnRecord) && haveTxnWrite
 		needEndTxn := txn.mu.Proto.Writing || txn.mu.writingTxnRecord || haveTxnWrite
@@ -1089,6 +1093,17 @@ func (txn *Txn) recordPreviousTxnIDLocked(prevTxnID uuid.UUID) {
 	txn.mu.previousIDs[*txn.mu.Proto.ID] = struct{}{}
 }
 
+// SetFollowerReads marks the read-only batches of the transaction as
+// explicit historical reads, which may be served by the nearest replica of
+// each range instead of its lease holder if they are old enough (see
+// storagebase.CanServeFollowerRead). It is used by AS OF SYSTEM TIME queries,
+// along with SetFixedTimestamp.
+func (txn *Txn) SetFollowerReads() {
+	txn.mu.Lock()
+	txn.mu.followerReads = true
+	txn.mu.Unlock()
+}
+
 // SetFixedTimestamp makes the transaction run in an unusual way, at a "fixed
 // timestamp": Timestamp and OrigTimestamp are set to ts, there's no clock
 // uncertainty, and the txn's deadline is set to ts such that the transaction
@@ -241,6 +241,8 @@ func (r *RSG) GenerateRandomArg(typ parser.Type) string {
 	case parser.TypeUUID:
 		u := uuid.MakeV4()
 		v = fmt.Sprintf(`'%s'`, u)
+	case parser.TypeJSON:
+		v = fmt.Sprintf(`'{"a": %d}'`, r.Intn(1000))
 	case parser.TypeIntArray,
 		parser.TypeStringArray,
 		parser.TypeOid,
@@ -557,6 +557,21 @@ func MakeTablePrefix(tableID uint32) []byte {
 	return encoding.EncodeUvarintAscending(nil, uint64(tableID))
 }
 
+// SequenceIndexID is the ID of the single index of the sequences, under
+// which their value is stored.
+const SequenceIndexID = 1
+
+// MakeSequenceKey returns the key used to store the value of the sequence
+// with the given ID. The key is laid out like the sole column family of
+// the sole row of a table, so that it is deleted and split on like one.
+func MakeSequenceKey(tableID uint32) []byte {
+	key := MakeTablePrefix(tableID)
+	key = encoding.EncodeUvarintAscending(key, SequenceIndexID)
+	// The primary key of the row.
+	key = encoding.EncodeUvarintAscending(key, 0)
+	return MakeFamilyKey(key, SentinelFamilyID)
+}
+
 // DecodeTablePrefix validates that the given key has a table prefix, returning
 // the remainder of the key (with the prefix removed) and the decoded descriptor
 // ID of the table.
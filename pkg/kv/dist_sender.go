@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -460,8 +461,10 @@ func (ds *DistSender) sendSingleRange(
 	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor())
 
 	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
+	// it to the front. Inconsistent reads and reads old enough to be served by a
+	// follower go to the nearest replica instead.
+	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) &&
+		!storagebase.CanServeFollowerRead(&ba, ds.clock.Now(), ds.clock.MaxOffset()) {
 		if leaseHolder, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(leaseHolder.StoreID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -17,12 +17,14 @@
 package rpc
 
 import (
+	"fmt"
 	"math"
 	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/VividCortex/ewma"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -35,6 +37,7 @@ import (
 type RemoteClockMetrics struct {
 	ClockOffsetMeanNanos   *metric.Gauge
 	ClockOffsetStdDevNanos *metric.Gauge
+	ClockOffsetMaxAbsNanos *metric.Gauge
 	LatencyHistogramNanos  *metric.Histogram
 }
 
@@ -45,6 +48,24 @@ type RemoteClockMetrics struct {
 // minute old.
 const avgLatencyMeasurementAge = 20.0
 
+// offsetWarningFraction is the fraction of the maximum clock offset above
+// which the offset to another node is logged as a warning.
+var offsetWarningFraction = settings.RegisterValidatedFloatSetting(
+	"server.clock.offset_warning_fraction",
+	"fraction of the maximum clock offset above which the offset to another node is logged as a warning (0 disables the warnings)",
+	0.5,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("cannot set server.clock.offset_warning_fraction to %f, must be between 0 and 1", v)
+		}
+		return nil
+	},
+)
+
+// offsetWarningInterval is the minimum interval between two warnings about
+// the clock offset to the same node.
+const offsetWarningInterval = time.Minute
+
 var (
 	metaClockOffsetMeanNanos = metric.Metadata{
 		Name: "clock-offset.meannanos",
@@ -52,6 +73,9 @@ var (
 	metaClockOffsetStdDevNanos = metric.Metadata{
 		Name: "clock-offset.stddevnanos",
 		Help: "Stdddev clock offset with other nodes"}
+	metaClockOffsetMaxAbsNanos = metric.Metadata{
+		Name: "clock-offset.maxabsnanos",
+		Help: "Maximum absolute clock offset with other nodes"}
 	metaLatencyHistogramNanos = metric.Metadata{
 		Name: "round-trip-latency",
 		Help: "Distribution of round-trip latencies with other nodes"}
@@ -67,6 +91,9 @@ type RemoteClockMonitor struct {
 		syncutil.Mutex
 		offsets        map[string]RemoteOffset
 		latenciesNanos map[string]ewma.MovingAverage
+		// lastWarnings holds the time at which the offset to each node was last
+		// logged as a warning.
+		lastWarnings map[string]time.Time
 	}
 
 	metrics RemoteClockMetrics
@@ -82,12 +109,14 @@ func newRemoteClockMonitor(
 	}
 	r.mu.offsets = make(map[string]RemoteOffset)
 	r.mu.latenciesNanos = make(map[string]ewma.MovingAverage)
+	r.mu.lastWarnings = make(map[string]time.Time)
 	if histogramWindowInterval == 0 {
 		histogramWindowInterval = time.Duration(math.MaxInt64)
 	}
 	r.metrics = RemoteClockMetrics{
 		ClockOffsetMeanNanos:   metric.NewGauge(metaClockOffsetMeanNanos),
 		ClockOffsetStdDevNanos: metric.NewGauge(metaClockOffsetStdDevNanos),
+		ClockOffsetMaxAbsNanos: metric.NewGauge(metaClockOffsetMaxAbsNanos),
 		LatencyHistogramNanos:  metric.NewLatency(metaLatencyHistogramNanos, histogramWindowInterval),
 	}
 	return &r
@@ -124,6 +153,21 @@ func (r *RemoteClockMonitor) AllLatencies() map[string]time.Duration {
 	return result
 }
 
+// AllOffsets returns a map of all the currently valid clock offset
+// measurements, keyed by node address.
+func (r *RemoteClockMonitor) AllOffsets() map[string]RemoteOffset {
+	now := r.clock.PhysicalTime()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make(map[string]RemoteOffset)
+	for addr, offset := range r.mu.offsets {
+		if !offset.isStale(r.offsetTTL, now) {
+			result[addr] = offset
+		}
+	}
+	return result
+}
+
 // UpdateOffset is a thread-safe way to update the remote clock and latency
 // measurements.
 //
@@ -183,7 +227,7 @@ func (r *RemoteClockMonitor) UpdateOffset(
 // is healthy (as defined by RemoteOffset.isHealthy). It returns nil iff more
 // than half the known offsets are healthy, and an error otherwise. A non-nil
 // return indicates that this node's clock is unreliable, and that the node
-// should terminate.
+// should stop serving.
 func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 	// By the contract of the hlc, if the value is 0, then safety checking
 	// of the max offset is disabled. However we may still want to
@@ -192,6 +236,9 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 		now := r.clock.PhysicalTime()
 
 		healthyOffsetCount := 0
+		var maxAbsOffset time.Duration
+		warningOffset := time.Duration(offsetWarningFraction.Get() * float64(maxOffset))
+		var warnings []string
 
 		r.mu.Lock()
 		// Each measurement is recorded as its minimum and maximum value.
@@ -199,6 +246,7 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 		for addr, offset := range r.mu.offsets {
 			if offset.isStale(r.offsetTTL, now) {
 				delete(r.mu.offsets, addr)
+				delete(r.mu.lastWarnings, addr)
 				continue
 			}
 			offsets = append(offsets, float64(offset.Offset+offset.Uncertainty))
@@ -206,10 +254,29 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 			if offset.isHealthy(ctx, maxOffset) {
 				healthyOffsetCount++
 			}
+			absOffset := time.Duration(offset.Offset)
+			if absOffset < 0 {
+				absOffset = -absOffset
+			}
+			if absOffset > maxAbsOffset {
+				maxAbsOffset = absOffset
+			}
+			if warningOffset > 0 && absOffset > warningOffset &&
+				now.Sub(r.mu.lastWarnings[addr]) >= offsetWarningInterval {
+				r.mu.lastWarnings[addr] = now
+				warnings = append(warnings, fmt.Sprintf(
+					"clock offset to %s is %s (uncertainty %s), more than %.0f%% of the maximum offset of %s",
+					addr, time.Duration(offset.Offset), time.Duration(offset.Uncertainty),
+					offsetWarningFraction.Get()*100, maxOffset))
+			}
 		}
 		numClocks := len(r.mu.offsets)
 		r.mu.Unlock()
 
+		for _, warning := range warnings {
+			log.Warning(ctx, warning)
+		}
+
 		mean, err := offsets.Mean()
 		if err != nil && err != stats.EmptyInput {
 			return err
@@ -220,6 +287,7 @@ func (r *RemoteClockMonitor) VerifyClockOffset(ctx context.Context) error {
 		}
 		r.metrics.ClockOffsetMeanNanos.Update(int64(mean))
 		r.metrics.ClockOffsetStdDevNanos.Update(int64(stdDev))
+		r.metrics.ClockOffsetMaxAbsNanos.Update(maxAbsOffset.Nanoseconds())
 
 		if numClocks > 0 && healthyOffsetCount <= numClocks/2 {
 			return errors.Errorf("fewer than half the known nodes are within the maximum offset of %s (%d of %d)", maxOffset, healthyOffsetCount, numClocks)
@@ -188,6 +188,13 @@ func TestClockOffsetMetrics(t *testing.T) {
 	if a, e := monitor.Metrics().ClockOffsetStdDevNanos.Value(), int64(7); a != e {
 		t.Errorf("stdDev %d != expected %d", a, e)
 	}
+	if a, e := monitor.Metrics().ClockOffsetMaxAbsNanos.Value(), int64(13); a != e {
+		t.Errorf("max abs %d != expected %d", a, e)
+	}
+	// The offset exceeds half the maximum offset, so it was logged as a warning.
+	if _, ok := monitor.mu.lastWarnings["0"]; !ok {
+		t.Errorf("expected the offset to be logged as a warning")
+	}
 }
 
 // TestLatencies tests the tracking of round-trip latency between nodes.
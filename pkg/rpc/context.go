@@ -31,6 +31,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -117,11 +118,25 @@ func NewServer(ctx *Context) *grpc.Server {
 		}
 		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
+	unaryInterceptor := traceparentUnaryServerInterceptor
 	if tracer := ctx.AmbientCtx.Tracer; tracer != nil {
-		opts = append(opts, grpc.UnaryInterceptor(
-			otgrpc.OpenTracingServerInterceptor(tracer),
-		))
+		tracingInterceptor := otgrpc.OpenTracingServerInterceptor(tracer)
+		unaryInterceptor = func(
+			ctx context.Context,
+			req interface{},
+			info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			return traceparentUnaryServerInterceptor(ctx, req, info,
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return tracingInterceptor(ctx, req, info, handler)
+				})
+		}
 	}
+	opts = append(opts,
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(traceparentStreamServerInterceptor),
+	)
 	s := grpc.NewServer(opts...)
 	RegisterHeartbeatServer(s, &HeartbeatService{
 		clock:              ctx.LocalClock,
@@ -130,6 +145,50 @@ func NewServer(ctx *Context) *grpc.Server {
 	return s
 }
 
+// traceparentFromMetadata returns the W3C trace context propagated in the
+// metadata of an incoming RPC, if any.
+func traceparentFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if vals := md[log.TraceparentHeader]; len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// traceparentUnaryServerInterceptor tags the log entries of the handling
+// of a unary RPC with the ID of the external trace of the request.
+func traceparentUnaryServerInterceptor(
+	ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return handler(log.WithTraceparent(ctx, traceparentFromMetadata(ctx)), req)
+}
+
+// serverStreamWithCtx overrides the context of a grpc.ServerStream.
+type serverStreamWithCtx struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context is part of the grpc.ServerStream interface.
+func (s serverStreamWithCtx) Context() context.Context {
+	return s.ctx
+}
+
+// traceparentStreamServerInterceptor is the equivalent of
+// traceparentUnaryServerInterceptor for streaming RPCs.
+func traceparentStreamServerInterceptor(
+	srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	ctx := ss.Context()
+	if tp := traceparentFromMetadata(ctx); tp != "" {
+		ss = serverStreamWithCtx{ServerStream: ss, ctx: log.WithTraceparent(ctx, tp)}
+	}
+	return handler(srv, ss)
+}
+
 type connMeta struct {
 	sync.Once
 	conn         *grpc.ClientConn
@@ -163,8 +222,24 @@ type Context struct {
 
 	// For unittesting.
 	BreakerFactory func() *circuit.Breaker
+
+	// Knobs are the testing knobs of the context. They must be set before the
+	// first connection is dialed.
+	Knobs ContextTestingKnobs
 }
 
+// ContextTestingKnobs provides hooks to aid in testing the RPC context.
+type ContextTestingKnobs struct {
+	// WrapConn, if set, is called with every connection dialed by the context
+	// and the address it was dialed to. The returned connection is used in its
+	// place, which lets tests inject network faults between nodes. Returning
+	// an error fails the dial.
+	WrapConn func(target string, conn net.Conn) (net.Conn, error)
+}
+
+// ModuleTestingKnobs is part of the base.ModuleTestingKnobs interface.
+func (*ContextTestingKnobs) ModuleTestingKnobs() {}
+
 // NewContext creates an rpc Context with the supplied values.
 func NewContext(
 	ambient log.AmbientContext, baseCtx *base.Config, hlcClock *hlc.Clock, stopper *stop.Stopper,
@@ -293,16 +368,8 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 		}))
 		dialOpts = append(dialOpts, opts...)
 
-		if SourceAddr != nil {
-			dialOpts = append(dialOpts, grpc.WithDialer(
-				func(addr string, timeout time.Duration) (net.Conn, error) {
-					dialer := net.Dialer{
-						Timeout:   timeout,
-						LocalAddr: SourceAddr,
-					}
-					return dialer.Dial("tcp", addr)
-				},
-			))
+		if SourceAddr != nil || ctx.Knobs.WrapConn != nil {
+			dialOpts = append(dialOpts, grpc.WithDialer(ctx.dial))
 		}
 
 		if tracer := ctx.AmbientCtx.Tracer; tracer != nil {
@@ -339,6 +406,25 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 	return meta.conn, meta.dialErr
 }
 
+// dial opens a TCP connection to addr from SourceAddr, if set, and hands it
+// to the WrapConn testing knob, if set.
+func (ctx *Context) dial(addr string, timeout time.Duration) (net.Conn, error) {
+	dialer := net.Dialer{
+		Timeout:   timeout,
+		LocalAddr: SourceAddr,
+	}
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil || ctx.Knobs.WrapConn == nil {
+		return conn, err
+	}
+	wrapped, err := ctx.Knobs.WrapConn(addr, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return wrapped, nil
+}
+
 // NewBreaker creates a new circuit breaker properly configured for RPC
 // connections.
 func (ctx *Context) NewBreaker() *circuit.Breaker {
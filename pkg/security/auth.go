@@ -60,7 +60,7 @@ type RequestWithUser interface {
 func ProtoAuthHook(
 	insecureMode bool, tlsState *tls.ConnectionState,
 ) (func(proto.Message, bool) error, error) {
-	userHook, err := UserAuthCertHook(insecureMode, tlsState)
+	userHook, err := UserAuthCertHook(insecureMode, tlsState, nil /* identityMap */)
 	if err != nil {
 		return nil, err
 	}
@@ -80,8 +80,11 @@ func ProtoAuthHook(
 }
 
 // UserAuthCertHook builds an authentication hook based on the security
-// mode and client certificate.
-func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAuthHook, error) {
+// mode and client certificate. The identity map lets the certificate
+// authenticate as users other than its common name.
+func UserAuthCertHook(
+	insecureMode bool, tlsState *tls.ConnectionState, identityMap IdentityMap,
+) (UserAuthHook, error) {
 	var certUser string
 
 	if !insecureMode {
@@ -109,8 +112,10 @@ func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAut
 
 		// The client certificate user must match the requested user,
 		// except if the certificate user is NodeUser, which is allowed to
-		// act on behalf of all other users.
-		if !(certUser == NodeUser || certUser == requestedUser) {
+		// act on behalf of all other users, or if the identity map allows
+		// the certificate user to act as the requested user.
+		if !(certUser == NodeUser || certUser == requestedUser ||
+			identityMap.Allows(certUser, requestedUser)) {
 			return errors.Errorf("requested user is %s, but certificate is for %s", requestedUser, certUser)
 		}
 
@@ -0,0 +1,163 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// splitConfigLines splits a configuration in the format of the identity map
+// and the authentication rules into the fields of its non-empty lines. Lines
+// are separated by newlines or semicolons, and '#' starts a comment.
+func splitConfigLines(s string) [][]string {
+	var lines [][]string
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\n' || r == ';'
+	}) {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines = append(lines, fields)
+		}
+	}
+	return lines
+}
+
+type identityMapping struct {
+	pattern *regexp.Regexp
+	user    string
+}
+
+// IdentityMap maps the common names of client certificates to the SQL users
+// they can authenticate as, similarly to pg_ident.conf in PostgreSQL.
+//
+// Each line of an identity map has the form:
+//
+//   <common name pattern> <user>
+//
+// The pattern is a regular expression that must match the whole common name.
+// If the user contains \1, it is replaced with the first parenthesized
+// subexpression of the pattern; for example "(.*)@example\.com \1" lets the
+// certificate for "alice@example.com" authenticate as the user alice.
+type IdentityMap []identityMapping
+
+// ParseIdentityMap parses an identity map as described in IdentityMap.
+func ParseIdentityMap(s string) (IdentityMap, error) {
+	var m IdentityMap
+	for _, fields := range splitConfigLines(s) {
+		if len(fields) != 2 {
+			return nil, errors.Errorf("invalid identity mapping %q: expected <pattern> <user>",
+				strings.Join(fields, " "))
+		}
+		pattern, err := regexp.Compile("^(?:" + fields[0] + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid identity mapping pattern %q", fields[0])
+		}
+		if strings.Contains(fields[1], `\1`) && pattern.NumSubexp() == 0 {
+			return nil, errors.Errorf(
+				"invalid identity mapping %q: user references \\1 but the pattern has no subexpression",
+				strings.Join(fields, " "))
+		}
+		m = append(m, identityMapping{pattern: pattern, user: fields[1]})
+	}
+	return m, nil
+}
+
+// Allows returns whether the identity map lets the certificate with the given
+// common name authenticate as the requested user.
+func (m IdentityMap) Allows(certUser, requestedUser string) bool {
+	for _, mapping := range m {
+		matches := mapping.pattern.FindStringSubmatch(certUser)
+		if matches == nil {
+			continue
+		}
+		user := mapping.user
+		if len(matches) > 1 {
+			user = strings.Replace(user, `\1`, matches[1], -1)
+		}
+		if user == requestedUser {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthMethod is a way for a client to authenticate as a SQL user.
+type AuthMethod int
+
+const (
+	// AuthAny accepts either a client certificate or a password.
+	AuthAny AuthMethod = iota
+	// AuthCert requires a client certificate.
+	AuthCert
+	// AuthPassword requires a password, even if the client presents a
+	// certificate.
+	AuthPassword
+)
+
+var authMethodNames = map[string]AuthMethod{
+	"any":      AuthAny,
+	"cert":     AuthCert,
+	"password": AuthPassword,
+}
+
+type authRule struct {
+	user   string
+	method AuthMethod
+}
+
+// AuthRules selects the authentication method of the SQL users, similarly
+// to pg_hba.conf in PostgreSQL.
+//
+// Each line of the rules has the form:
+//
+//   <user> <method>
+//
+// where user is a SQL user or "all", and method is one of "cert", "password"
+// or "any". The first line matching a user applies; users matched by no line
+// can use any method.
+type AuthRules []authRule
+
+// ParseAuthRules parses authentication rules as described in AuthRules.
+func ParseAuthRules(s string) (AuthRules, error) {
+	var r AuthRules
+	for _, fields := range splitConfigLines(s) {
+		if len(fields) != 2 {
+			return nil, errors.Errorf("invalid authentication rule %q: expected <user> <method>",
+				strings.Join(fields, " "))
+		}
+		method, ok := authMethodNames[fields[1]]
+		if !ok {
+			return nil, errors.Errorf("invalid authentication rule %q: unknown method %q",
+				strings.Join(fields, " "), fields[1])
+		}
+		r = append(r, authRule{user: fields[0], method: method})
+	}
+	return r, nil
+}
+
+// Method returns the authentication method required for the user.
+func (r AuthRules) Method(user string) AuthMethod {
+	for _, rule := range r {
+		if rule.user == "all" || rule.user == user {
+			return rule.method
+		}
+	}
+	return AuthAny
+}
@@ -132,3 +132,99 @@ func TestAuthenticationHook(t *testing.T) {
 		}
 	}
 }
+
+func TestIdentityMap(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	m, err := security.ParseIdentityMap(`
+# Employees authenticate as themselves.
+(.*)@example\.com \1
+ops-[0-9]+ ops; backup backup_user
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		certUser, requestedUser string
+		allowed                 bool
+	}{
+		{"alice@example.com", "alice", true},
+		{"alice@example.com", "bob", false},
+		{"alice@example.com.evil", "alice", false},
+		{"ops-12", "ops", true},
+		{"ops-", "ops", false},
+		{"backup", "backup_user", true},
+		{"backup", "backup", false},
+		{"root", "backup_user", false},
+	}
+	for _, tc := range testCases {
+		if allowed := m.Allows(tc.certUser, tc.requestedUser); allowed != tc.allowed {
+			t.Errorf("%s as %s: expected allowed=%t, got %t",
+				tc.certUser, tc.requestedUser, tc.allowed, allowed)
+		}
+	}
+
+	for _, s := range []string{
+		`foo`,
+		`foo bar baz`,
+		`( bar`,
+		`foo \1`,
+	} {
+		if _, err := security.ParseIdentityMap(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+
+	// The identity map extends the users a certificate can authenticate as.
+	hook, err := security.UserAuthCertHook(
+		false /* insecure */, makeFakeTLSState([]string{"alice@example.com"}, []int{1}), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hook("alice", true /* public */); err != nil {
+		t.Error(err)
+	}
+	if err := hook("bob", true /* public */); err == nil {
+		t.Error("expected bob not to be allowed")
+	}
+}
+
+func TestAuthRules(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	r, err := security.ParseAuthRules(`alice cert; bob password
+carol any
+all cert`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		user   string
+		method security.AuthMethod
+	}{
+		{"alice", security.AuthCert},
+		{"bob", security.AuthPassword},
+		{"carol", security.AuthAny},
+		{"dave", security.AuthCert},
+	}
+	for _, tc := range testCases {
+		if method := r.Method(tc.user); method != tc.method {
+			t.Errorf("%s: expected method %d, got %d", tc.user, tc.method, method)
+		}
+	}
+
+	// Without rules, any method is accepted.
+	if method := (security.AuthRules(nil)).Method("alice"); method != security.AuthAny {
+		t.Errorf("expected method %d, got %d", security.AuthAny, method)
+	}
+
+	for _, s := range []string{
+		`alice`,
+		`alice cert extra`,
+		`alice kerberos`,
+	} {
+		if _, err := security.ParseAuthRules(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -1077,6 +1078,17 @@ func (s *adminServer) Drain(req *serverpb.DrainRequest, stream serverpb.Admin_Dr
 		return nil
 	}
 
+	// Refuse to shut down a decommissioning node that still holds replicas:
+	// its ranges would be left under-replicated until the node is declared
+	// dead.
+	if liveness, err := s.server.nodeLiveness.Self(); err == nil && liveness.Decommissioning {
+		if count := s.server.localReplicaCount(); count > 0 {
+			return errors.Errorf(
+				"node %d is decommissioning but still holds %d replicas; refusing to shut down",
+				liveness.NodeID, count)
+		}
+	}
+
 	s.server.grpc.Stop()
 
 	ctx := stream.Context()
@@ -1090,6 +1102,171 @@ func (s *adminServer) Drain(req *serverpb.DrainRequest, stream serverpb.Admin_Dr
 	}
 }
 
+// DecommissionStatus returns the DecommissionStatus for all or the given nodes.
+func (s *adminServer) DecommissionStatus(
+	ctx context.Context, req *serverpb.DecommissionStatusRequest,
+) (*serverpb.DecommissionStatusResponse, error) {
+	// Get the number of replicas on each node. We *may* not need all of them,
+	// but that would be more complicated than seems worth it right now.
+	nodeIDs := req.NodeIDs
+
+	// If no nodeIDs given, use all nodes.
+	if len(nodeIDs) == 0 {
+		for _, liveness := range s.server.nodeLiveness.GetLivenesses() {
+			nodeIDs = append(nodeIDs, liveness.NodeID)
+		}
+	}
+
+	// Compute the replica counts for the target nodes only. This map doubles
+	// as a lookup table to check whether we care about a given node.
+	replicaCounts := make(map[roachpb.NodeID]int64)
+	for _, nodeID := range nodeIDs {
+		replicaCounts[nodeID] = 0
+	}
+
+	kvs, err := s.server.db.Scan(ctx, keys.Meta2Prefix, keys.MetaMax, 0)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	for _, row := range kvs {
+		var rangeDesc roachpb.RangeDescriptor
+		if err := row.ValueProto(&rangeDesc); err != nil {
+			return nil, errors.Wrapf(err, "%s: unable to unmarshal range descriptor", row.Key)
+		}
+		for _, r := range rangeDesc.Replicas {
+			if _, ok := replicaCounts[r.NodeID]; ok {
+				replicaCounts[r.NodeID]++
+			}
+		}
+	}
+
+	var res serverpb.DecommissionStatusResponse
+	isLiveMap := s.server.nodeLiveness.GetIsLiveMap()
+	for _, nodeID := range nodeIDs {
+		l, err := s.server.nodeLiveness.GetLiveness(nodeID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get liveness for %d", nodeID)
+		}
+		res.Status = append(res.Status, serverpb.DecommissionStatusResponse_Status{
+			NodeID:          l.NodeID,
+			IsLive:          isLiveMap[l.NodeID],
+			ReplicaCount:    replicaCounts[l.NodeID],
+			Decommissioning: l.Decommissioning,
+			Draining:        l.Draining,
+		})
+	}
+	return &res, nil
+}
+
+// Decommission sets the decommission flag to the specified value on the
+// specified node(s).
+func (s *adminServer) Decommission(
+	ctx context.Context, req *serverpb.DecommissionRequest,
+) (*serverpb.DecommissionStatusResponse, error) {
+	if err := requireRootOrNode(ctx, "decommission nodes"); err != nil {
+		return nil, err
+	}
+	nodeIDs := req.NodeIDs
+	if nodeIDs == nil {
+		// If no NodeIDs are specified, decommission the current node.
+		nodeIDs = []roachpb.NodeID{s.server.NodeID()}
+	}
+
+	// Mark the target nodes as decommissioning. They'll find out as they
+	// heartbeat their liveness.
+	if err := s.server.Decommission(ctx, req.Decommissioning, nodeIDs); err != nil {
+		return nil, err
+	}
+	return s.DecommissionStatus(ctx, &serverpb.DecommissionStatusRequest{NodeIDs: nodeIDs})
+}
+
+// SetKVTraceFilter sets or clears the filter logging the KV requests which
+// touch a span of keys on the given nodes, or on all the live nodes if none
+// are given. The request is forwarded to the other nodes. As the filter logs
+// keys, which are user data, only root and the nodes may set it.
+func (s *adminServer) SetKVTraceFilter(
+	ctx context.Context, req *serverpb.SetKVTraceFilterRequest,
+) (*serverpb.SetKVTraceFilterResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if err := requireRootOrNode(ctx, "set the kv trace filter"); err != nil {
+		return nil, err
+	}
+	if len(req.EndKey) > 0 && !req.StartKey.Less(req.EndKey) {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"invalid span: start key %s must be less than end key %s", req.StartKey, req.EndKey)
+	}
+	if req.MaxPerSecond < 0 || req.DurationSeconds < 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "rate and duration must not be negative")
+	}
+
+	nodeIDs := req.NodeIDs
+	if len(nodeIDs) == 0 {
+		// Skip the nodes which are not live, so that a dead node doesn't fail
+		// the request.
+		isLiveMap := s.server.nodeLiveness.GetIsLiveMap()
+		for _, liveness := range s.server.nodeLiveness.GetLivenesses() {
+			if isLiveMap[liveness.NodeID] || liveness.NodeID == s.server.NodeID() {
+				nodeIDs = append(nodeIDs, liveness.NodeID)
+			}
+		}
+	}
+
+	var resp serverpb.SetKVTraceFilterResponse
+	for _, nodeID := range nodeIDs {
+		if nodeID == s.server.NodeID() {
+			s.setLocalKVTraceFilter(ctx, req)
+		} else {
+			client, err := s.dialNode(nodeID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to dial into node %d", nodeID)
+			}
+			nodeReq := *req
+			nodeReq.NodeIDs = []roachpb.NodeID{nodeID}
+			if _, err := client.SetKVTraceFilter(ctx, &nodeReq); err != nil {
+				return nil, errors.Wrapf(err, "failed to set kv trace filter on node %d", nodeID)
+			}
+		}
+		resp.NodeIDs = append(resp.NodeIDs, nodeID)
+	}
+	return &resp, nil
+}
+
+func (s *adminServer) setLocalKVTraceFilter(
+	ctx context.Context, req *serverpb.SetKVTraceFilterRequest,
+) {
+	filter := &s.server.node.kvTraceFilter
+	if len(req.StartKey) == 0 {
+		log.Info(ctx, "clearing kv trace filter")
+		filter.clear()
+		return
+	}
+	maxPerSecond := req.MaxPerSecond
+	if maxPerSecond == 0 {
+		maxPerSecond = defaultKVTraceFilterRate
+	}
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if duration == 0 {
+		duration = defaultKVTraceFilterDuration
+	}
+	span := roachpb.Span{Key: req.StartKey, EndKey: req.EndKey}
+	log.Infof(ctx, "setting kv trace filter on %s at %.2f requests/s for %s",
+		span, maxPerSecond, duration)
+	filter.set(span, maxPerSecond, timeutil.Now().Add(duration))
+}
+
+// dialNode returns an AdminClient for the given node.
+func (s *adminServer) dialNode(nodeID roachpb.NodeID) (serverpb.AdminClient, error) {
+	addr, err := s.server.gossip.GetNodeIDAddress(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.server.rpcContext.GRPCDial(addr.String())
+	if err != nil {
+		return nil, err
+	}
+	return serverpb.NewAdminClient(conn), nil
+}
+
 // sqlQuery allows you to incrementally build a SQL query that uses
 // placeholders. Instead of specific placeholders like $1, you instead use the
 // temporary placeholder $.
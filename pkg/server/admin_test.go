@@ -27,6 +27,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1109,3 +1110,41 @@ func TestAdminAPIRangeLog(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminAPISetKVTraceFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	ts := s.(*TestServer)
+
+	var resp serverpb.SetKVTraceFilterResponse
+	req := &serverpb.SetKVTraceFilterRequest{
+		StartKey:     roachpb.Key("a"),
+		EndKey:       roachpb.Key("b"),
+		MaxPerSecond: 5,
+	}
+	if err := postAdminJSONProto(s, "kv_trace_filter", req, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []roachpb.NodeID{s.NodeID()}; !reflect.DeepEqual(expected, resp.NodeIDs) {
+		t.Errorf("expected filter to be set on %v, got %v", expected, resp.NodeIDs)
+	}
+	if atomic.LoadInt32(&ts.node.kvTraceFilter.enabled) != 1 {
+		t.Fatal("expected kv trace filter to be set")
+	}
+
+	req = &serverpb.SetKVTraceFilterRequest{StartKey: roachpb.Key("b"), EndKey: roachpb.Key("a")}
+	if err := postAdminJSONProto(s, "kv_trace_filter", req, &resp); !testutils.IsError(err, "invalid span") {
+		t.Fatalf("expected invalid span error, got %v", err)
+	}
+
+	// An empty start key clears the filter.
+	if err := postAdminJSONProto(
+		s, "kv_trace_filter", &serverpb.SetKVTraceFilterRequest{}, &resp,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&ts.node.kvTraceFilter.enabled) != 0 {
+		t.Fatal("expected kv trace filter to be cleared")
+	}
+}
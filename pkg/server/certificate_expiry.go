@@ -0,0 +1,123 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var certificateExpiryWarningThreshold = settings.RegisterNonNegativeDurationSetting(
+	"server.certificate_expiration.warning_threshold",
+	"warn and record an event when a certificate of a node expires within this duration "+
+		"(set to 0 to disable)",
+	30*24*time.Hour,
+)
+
+// certificateExpiryCheckInterval is the interval at which the expiration of
+// the certificates of the node is checked.
+const certificateExpiryCheckInterval = time.Hour
+
+// expiringCertificate describes a certificate which expires within the
+// warning threshold.
+type expiringCertificate struct {
+	CertificateType string
+	ExpirationTime  time.Time
+}
+
+// expiringCertificates returns the valid certificates among the given ones,
+// keyed by type, which expire before the cutoff.
+func expiringCertificates(
+	certs map[string]*security.CertInfo, cutoff time.Time,
+) []expiringCertificate {
+	var expiring []expiringCertificate
+	for certType, cert := range certs {
+		if cert == nil || cert.Error != nil || cert.ExpirationTime.After(cutoff) {
+			continue
+		}
+		expiring = append(expiring, expiringCertificate{certType, cert.ExpirationTime})
+	}
+	return expiring
+}
+
+// startCertificateExpiryChecks starts a worker which periodically warns about
+// the certificates of the node which are about to expire, and records a
+// "certificate expiry" event for each of them.
+func (s *Server) startCertificateExpiryChecks() {
+	if s.cfg.Insecure {
+		return
+	}
+	ctx := s.AnnotateCtx(context.Background())
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		// reported holds the expiration time of the certificate of each type
+		// which was last recorded, so that each certificate is recorded once.
+		// Loading a renewed certificate resets it.
+		reported := make(map[string]time.Time)
+		ticker := time.NewTicker(certificateExpiryCheckInterval)
+		defer ticker.Stop()
+		for {
+			s.checkCertificateExpiry(ctx, reported)
+			select {
+			case <-ticker.C:
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+func (s *Server) checkCertificateExpiry(ctx context.Context, reported map[string]time.Time) {
+	threshold := certificateExpiryWarningThreshold.Get()
+	if threshold == 0 {
+		return
+	}
+	cm, err := s.cfg.GetCertificateManager()
+	if err != nil {
+		log.Warningf(ctx, "unable to check certificate expiration: %s", err)
+		return
+	}
+	certs := map[string]*security.CertInfo{
+		"ca":   cm.CACert(),
+		"node": cm.NodeCert(),
+	}
+	for _, cert := range expiringCertificates(certs, timeutil.Now().Add(threshold)) {
+		log.Warningf(ctx, "%s certificate expires at %s", cert.CertificateType, cert.ExpirationTime)
+		if reported[cert.CertificateType].Equal(cert.ExpirationTime) || !s.cfg.EventLogEnabled {
+			continue
+		}
+		if err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return s.node.eventLogger.InsertEventRecord(
+				ctx,
+				txn,
+				sql.EventLogCertificateExpiry,
+				int32(s.NodeID()),
+				int32(s.NodeID()),
+				cert,
+			)
+		}); err != nil {
+			log.Warningf(ctx, "unable to log %s event: %s", sql.EventLogCertificateExpiry, err)
+			continue
+		}
+		reported[cert.CertificateType] = cert.ExpirationTime
+	}
+}
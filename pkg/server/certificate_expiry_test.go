@@ -0,0 +1,42 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestExpiringCertificates(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cutoff := time.Unix(1000, 0)
+	certs := map[string]*security.CertInfo{
+		"ca":      {ExpirationTime: cutoff.Add(time.Second)},
+		"node":    {ExpirationTime: cutoff.Add(-time.Second)},
+		"invalid": {ExpirationTime: cutoff.Add(-time.Second), Error: errors.New("bad")},
+		"missing": nil,
+	}
+	expected := []expiringCertificate{{"node", cutoff.Add(-time.Second)}}
+	if expiring := expiringCertificates(certs, cutoff); !reflect.DeepEqual(expiring, expected) {
+		t.Errorf("expected %v, got %v", expected, expiring)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var clockOffsetQuarantineEnabled = settings.RegisterBoolSetting(
+	"server.clock.quarantine_on_offset.enabled",
+	"if set, a node whose clock offset to the other nodes is unhealthy stops serving SQL clients and range leases instead of terminating, and resumes once the offset is healthy again",
+	false,
+)
+
+// clockQuarantineDrainModes are the drain modes activated while a node is
+// quarantined because of its clock offset.
+var clockQuarantineDrainModes = []serverpb.DrainMode{
+	serverpb.DrainMode_CLIENT,
+	serverpb.DrainMode_LEASES,
+}
+
+// clockQuarantine tracks whether a node stopped serving because its clock
+// offset to the other nodes is unhealthy.
+type clockQuarantine struct {
+	syncutil.Mutex
+	// quarantined is set while the node is drained because of its clock
+	// offset.
+	quarantined bool
+	// changing is set while the node is being drained or undrained.
+	changing bool
+}
+
+// verifyClockOffset checks the clock offset of the node to the other nodes.
+// When the offset is unhealthy, the node terminates, unless the quarantine is
+// enabled in which case the node is drained until the offset is healthy
+// again.
+func (s *Server) verifyClockOffset(ctx context.Context) {
+	err := s.rpcContext.RemoteClocks.VerifyClockOffset(ctx)
+	if err != nil && !clockOffsetQuarantineEnabled.Get() {
+		log.Fatal(ctx, err)
+	}
+
+	quarantine := err != nil
+	q := &s.clockQuarantine
+	q.Lock()
+	if q.changing || q.quarantined == quarantine {
+		q.Unlock()
+		return
+	}
+	q.changing = true
+	q.Unlock()
+
+	// Draining waits for the SQL clients to finish their work, so it must not
+	// block the heartbeat which verified the offset.
+	if taskErr := s.stopper.RunAsyncTask(ctx, "server.clockQuarantine", func(ctx context.Context) {
+		var drainErr error
+		if quarantine {
+			log.Errorf(ctx, "stopping serving until the clock offset is healthy: %s", err)
+			_, drainErr = s.Drain(clockQuarantineDrainModes)
+		} else {
+			log.Infof(ctx, "clock offset is healthy again, resuming serving")
+			s.Undrain(clockQuarantineDrainModes)
+		}
+		if drainErr != nil {
+			log.Warningf(ctx, "failed to stop serving: %s", drainErr)
+		}
+
+		q.Lock()
+		defer q.Unlock()
+		q.changing = false
+		if drainErr == nil {
+			q.quarantined = quarantine
+		}
+	}); taskErr != nil {
+		q.Lock()
+		defer q.Unlock()
+		q.changing = false
+	}
+}
@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -81,6 +82,12 @@ type Config struct {
 	// Stores is specified to enable durable key-value storage.
 	Stores base.StoreSpecList
 
+	// TempDir is the parent directory of the temporary storage used by DistSQL
+	// to spill the rows of large sorts and hash joins, which must not be shared
+	// with other nodes. If empty, the first on-disk store is used, or the
+	// system's temporary directory if all the stores are in memory.
+	TempDir string
+
 	// Attrs specifies a colon-separated list of node topography or machine
 	// capabilities, used to match capabilities or location preferences specified
 	// in zone configs.
@@ -180,6 +187,10 @@ type Config struct {
 	// TestingKnobs is used for internal test controls only.
 	TestingKnobs base.TestingKnobs
 
+	// manualClock, if set, is used as the physical clock of the node's HLC.
+	// It is only set by test servers.
+	manualClock *hlc.ManualClock
+
 	// AmbientCtx is used to annotate contexts used inside the server.
 	AmbientCtx log.AmbientContext
 
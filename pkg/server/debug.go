@@ -135,7 +135,7 @@ func init() {
     <table>
       <tr>
         <td>trace (local node only)</td>
-        <td><a href="./requests">requests</a>, <a href="./events">events</a></td>
+        <td><a href="./requests">requests</a>, <a href="./events">events</a>, <a href="./traces/sampled">sampled traces</a></td>
       </tr>
       <tr>
         <td>stopper</td>
@@ -151,14 +151,19 @@ func init() {
       <tr>
         <td>node status</td>
         <td>
-          <a href="/_status/gossip/local">gossip</a><br />
+          <a href="/_status/gossip/local">gossip</a> (<a href="/debug/gossip?node_id=local">by age</a>)<br />
           <a href="/_status/ranges/local">ranges</a><br />
+          <a href="/_status/feature-usage">feature usage</a><br />
         </td>
       </tr>
       <tr>
         <td>node diagnostics</td>
         <td><a href="/debug/nodes?node_ids=local">this node</a>, <a href="/debug/nodes">all nodes</a></td>
       </tr>
+      <tr>
+        <td>reporting</td>
+        <td><a href="/debug/diagnostics">diagnostics and crash reports</a></td>
+      </tr>
       <tr>
         <td>range status</td>
         <td><a href="/debug/range?id=1">range</a></td>
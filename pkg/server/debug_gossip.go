@@ -0,0 +1,170 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Returns an HTML page displaying the infos in the gossip network of the
+// requested node, along with how long ago each of them was gossiped by its
+// originating node.
+func (s *statusServer) handleDebugGossip(w http.ResponseWriter, r *http.Request) {
+	ctx := s.AnnotateCtx(r.Context())
+	w.Header().Add("Content-type", "text/html")
+	nodeIDString := r.URL.Query().Get("node_id")
+
+	nodeID, _, err := s.parseNodeID(nodeIDString)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	infoStatus, err := s.Gossip(ctx, &serverpb.GossipRequest{NodeId: nodeIDString})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	t, err := template.New("webpage").Parse(debugGossipTemplate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := t.Execute(w, prepareGossipWebData(nodeID, timeutil.Now(), infoStatus)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type gossipWebData struct {
+	NodeID roachpb.NodeID
+	Infos  []gossipInfoWebData
+}
+
+type gossipInfoWebData struct {
+	Key          string
+	OriginNodeID roachpb.NodeID
+	Hops         uint32
+	Age          time.Duration
+	ExpiresIn    time.Duration
+}
+
+// prepareGossipWebData returns the infos of the gossip network sorted by key.
+// Ages are relative to now, which uses the local clock while the timestamps
+// of the infos use the clock of their originating node.
+func prepareGossipWebData(
+	nodeID roachpb.NodeID, now time.Time, infoStatus *gossip.InfoStatus,
+) gossipWebData {
+	data := gossipWebData{NodeID: nodeID}
+	for key, info := range infoStatus.Infos {
+		infoData := gossipInfoWebData{
+			Key:          key,
+			OriginNodeID: info.NodeID,
+			Hops:         info.Hops,
+			Age:          truncateToMillis(now.Sub(time.Unix(0, info.OrigStamp))),
+		}
+		// Infos which never expire have a TTL stamp of math.MaxInt64.
+		if info.TTLStamp != math.MaxInt64 {
+			infoData.ExpiresIn = truncateToMillis(time.Unix(0, info.TTLStamp).Sub(now))
+		}
+		data.Infos = append(data.Infos, infoData)
+	}
+	sort.Slice(data.Infos, func(i, j int) bool {
+		return data.Infos[i].Key < data.Infos[j].Key
+	})
+	return data
+}
+
+func truncateToMillis(d time.Duration) time.Duration {
+	return d - d%time.Millisecond
+}
+
+const debugGossipTemplate = `
+<!DOCTYPE html>
+<HTML>
+  <HEAD>
+    <META CHARSET="UTF-8"/>
+    <TITLE>Gossip n{{.NodeID}}</TITLE>
+    <STYLE>
+      body {
+        font-family: "Helvetica Neue", Helvetica, Arial;
+        font-size: 14px;
+        line-height: 20px;
+        font-weight: 400;
+        color: #3b3b3b;
+        -webkit-font-smoothing: antialiased;
+        font-smoothing: antialiased;
+        background-color: #e4e4e4;
+      }
+      .wrapper {
+        margin: 0 auto;
+        padding: 0 40px;
+      }
+      .table {
+        margin: 0 0 40px 0;
+        display: table;
+      }
+      .row {
+        display: table-row;
+        background-color: white;
+      }
+      .row.header {
+        font-weight: 900;
+        color: #ffffff;
+        background-color: #2980b9;
+      }
+      .cell {
+        padding: 6px 12px;
+        display: table-cell;
+        border-width: 1px 1px 0 0;
+        border-color: rgba(0, 0, 0, 0.1);
+        border-style: solid;
+      }
+    </STYLE>
+  </HEAD>
+  <BODY>
+    <DIV CLASS="wrapper">
+      <H1>Gossip n{{.NodeID}}</H1>
+      <DIV CLASS="table">
+        <DIV CLASS="row header">
+          <DIV CLASS="cell">Key</DIV>
+          <DIV CLASS="cell">Origin Node</DIV>
+          <DIV CLASS="cell">Hops</DIV>
+          <DIV CLASS="cell">Age</DIV>
+          <DIV CLASS="cell">Expires In</DIV>
+        </DIV>
+        {{- range $_, $info := .Infos}}
+          <DIV CLASS="row">
+            <DIV CLASS="cell">{{$info.Key}}</DIV>
+            <DIV CLASS="cell">n{{$info.OriginNodeID}}</DIV>
+            <DIV CLASS="cell">{{$info.Hops}}</DIV>
+            <DIV CLASS="cell">{{$info.Age}}</DIV>
+            <DIV CLASS="cell">{{if $info.ExpiresIn}}{{$info.ExpiresIn}}{{else}}never{{end}}</DIV>
+          </DIV>
+        {{- end}}
+      </DIV>
+    </DIV>
+  </BODY>
+</HTML>
+`
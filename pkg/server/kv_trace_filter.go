@@ -0,0 +1,126 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+const (
+	// defaultKVTraceFilterRate is the number of requests logged per second
+	// when the rate of a filter isn't specified.
+	defaultKVTraceFilterRate = 10
+	// defaultKVTraceFilterDuration is how long a filter remains active when
+	// its duration isn't specified.
+	defaultKVTraceFilterDuration = 10 * time.Minute
+)
+
+// kvTraceFilter logs the KV requests received by a node which touch a given
+// span of keys, so that the traffic hitting a hot range can be inspected.
+// The logged requests are rate limited and the filter expires on its own so
+// that it can't be forgotten.
+type kvTraceFilter struct {
+	// enabled is 1 if a filter is set. It is accessed atomically so that
+	// requests don't acquire the mutex when there is no filter.
+	enabled int32
+
+	mu struct {
+		syncutil.Mutex
+		span       roachpb.Span
+		limiter    *rate.Limiter
+		expiration time.Time
+		// skipped is the number of matching requests which weren't logged
+		// because of the rate limit since the last logged request.
+		skipped int
+	}
+}
+
+// set sets the span of keys to log the requests of. The requests are logged
+// at most maxPerSecond times per second, until the expiration.
+func (f *kvTraceFilter) set(span roachpb.Span, maxPerSecond float64, expiration time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.span = span
+	f.mu.limiter = rate.NewLimiter(rate.Limit(maxPerSecond), 1 /* burst */)
+	f.mu.expiration = expiration
+	f.mu.skipped = 0
+	atomic.StoreInt32(&f.enabled, 1)
+}
+
+// clear removes the filter.
+func (f *kvTraceFilter) clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clearLocked()
+}
+
+func (f *kvTraceFilter) clearLocked() {
+	atomic.StoreInt32(&f.enabled, 0)
+	f.mu.span = roachpb.Span{}
+	f.mu.limiter = nil
+}
+
+// shouldLog returns whether the batch touches the filtered span and should
+// be logged, along with the number of matching requests which were skipped
+// since the last logged one.
+func (f *kvTraceFilter) shouldLog(now time.Time, ba *roachpb.BatchRequest) (bool, int) {
+	if atomic.LoadInt32(&f.enabled) == 0 {
+		return false, 0
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.limiter == nil {
+		return false, 0
+	}
+	if !now.Before(f.mu.expiration) {
+		f.clearLocked()
+		return false, 0
+	}
+	var matches bool
+	for _, union := range ba.Requests {
+		if f.mu.span.Overlaps(union.GetInner().Header()) {
+			matches = true
+			break
+		}
+	}
+	if !matches {
+		return false, 0
+	}
+	if !f.mu.limiter.AllowN(now, 1) {
+		f.mu.skipped++
+		return false, 0
+	}
+	skipped := f.mu.skipped
+	f.mu.skipped = 0
+	return true, skipped
+}
+
+// maybeLog logs the batch if it touches the filtered span, both to the log
+// and to the trace of the request.
+func (f *kvTraceFilter) maybeLog(ctx context.Context, now time.Time, ba *roachpb.BatchRequest) {
+	if ok, skipped := f.shouldLog(now, ba); ok {
+		log.Infof(ctx, "kv trace filter: %s (%d matching requests skipped)", ba, skipped)
+		log.Event(ctx, "request matches kv trace filter")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestKVTraceFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	getBatch := func(key, endKey string) *roachpb.BatchRequest {
+		ba := &roachpb.BatchRequest{}
+		ba.Add(&roachpb.ScanRequest{Span: roachpb.Span{
+			Key: roachpb.Key(key), EndKey: roachpb.Key(endKey),
+		}})
+		return ba
+	}
+	start := time.Unix(0, 0)
+
+	var f kvTraceFilter
+	if ok, _ := f.shouldLog(start, getBatch("a", "z")); ok {
+		t.Fatal("expected no request to be logged without a filter")
+	}
+
+	f.set(roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("e")}, 1, start.Add(time.Minute))
+	testCases := []struct {
+		key, endKey string
+		expected    bool
+	}{
+		{"a", "b", false},
+		{"a", "c", false},
+		{"a", "d", true},
+		{"d", "z", true},
+		{"e", "z", false},
+	}
+	for i, tc := range testCases {
+		// Space the requests to stay below the rate limit.
+		now := start.Add(time.Duration(i) * time.Second)
+		if ok, _ := f.shouldLog(now, getBatch(tc.key, tc.endKey)); ok != tc.expected {
+			t.Errorf("%d: expected [%s,%s) to be logged: %t, got %t", i, tc.key, tc.endKey, tc.expected, ok)
+		}
+	}
+
+	// Matching requests above the rate limit are skipped and counted.
+	now := start.Add(10 * time.Second)
+	if ok, _ := f.shouldLog(now, getBatch("c", "d")); !ok {
+		t.Fatal("expected request to be logged")
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _ := f.shouldLog(now, getBatch("c", "d")); ok {
+			t.Fatal("expected request to be skipped because of the rate limit")
+		}
+	}
+	if ok, skipped := f.shouldLog(now.Add(time.Second), getBatch("c", "d")); !ok || skipped != 3 {
+		t.Fatalf("expected request to be logged with 3 skipped, got %t, %d", ok, skipped)
+	}
+
+	// The filter expires.
+	if ok, _ := f.shouldLog(start.Add(time.Minute), getBatch("c", "d")); ok {
+		t.Fatal("expected expired filter not to log requests")
+	}
+
+	f.set(roachpb.Span{Key: roachpb.Key("c")}, 1, start.Add(time.Minute))
+	if ok, _ := f.shouldLog(start, getBatch("a", "d")); !ok {
+		t.Fatal("expected request touching the filtered key to be logged")
+	}
+	f.clear()
+	if ok, _ := f.shouldLog(start.Add(time.Second), getBatch("a", "d")); ok {
+		t.Fatal("expected cleared filter not to log requests")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+var (
+	metaLogSinksDisconnected = metric.Metadata{
+		Name: "log.sinks.disconnected",
+		Help: "Number of log sinks currently unable to deliver entries"}
+	metaLogSinksBacklog = metric.Metadata{
+		Name: "log.sinks.backlog",
+		Help: "Number of log entries waiting to be delivered by log sinks"}
+	metaLogSinksBytesSent = metric.Metadata{
+		Name: "log.sinks.bytes_sent",
+		Help: "Number of bytes delivered by log sinks"}
+	metaLogSinksDropped = metric.Metadata{
+		Name: "log.sinks.dropped",
+		Help: "Number of log entries which log sinks could not deliver"}
+)
+
+// logSinkMetrics aggregates the health of the log sinks. The health of
+// individual sinks is available at /debug/logsinks. The gauges are
+// computed when read, while the counters are updated by sample.
+type logSinkMetrics struct {
+	Disconnected *metric.Gauge
+	Backlog      *metric.Gauge
+	BytesSent    *metric.Counter
+	Dropped      *metric.Counter
+
+	// lastBytesSent and lastDropped are the totals of the sinks at the
+	// last sample.
+	lastBytesSent, lastDropped int64
+}
+
+// sumSinkStatuses returns the sum of f over the statuses of the log sinks.
+func sumSinkStatuses(f func(log.SinkStatus) int64) int64 {
+	var total int64
+	for _, s := range log.GetSinkStatuses() {
+		total += f(s)
+	}
+	return total
+}
+
+func makeLogSinkMetrics() *logSinkMetrics {
+	return &logSinkMetrics{
+		Disconnected: metric.NewFunctionalGauge(metaLogSinksDisconnected, func() int64 {
+			return sumSinkStatuses(func(s log.SinkStatus) int64 {
+				if s.Connected {
+					return 0
+				}
+				return 1
+			})
+		}),
+		Backlog: metric.NewFunctionalGauge(metaLogSinksBacklog, func() int64 {
+			return sumSinkStatuses(func(s log.SinkStatus) int64 {
+				return int64(s.Backlog)
+			})
+		}),
+		BytesSent: metric.NewCounter(metaLogSinksBytesSent),
+		Dropped:   metric.NewCounter(metaLogSinksDropped),
+	}
+}
+
+// sample adds to the counters what the sinks delivered and dropped since
+// the last sample. The totals of the sinks decrease when sinks are
+// closed, in which case nothing is added until they increase again.
+func (m *logSinkMetrics) sample() {
+	bytesSent := sumSinkStatuses(func(s log.SinkStatus) int64 {
+		return s.BytesSent
+	})
+	dropped := sumSinkStatuses(func(s log.SinkStatus) int64 {
+		return int64(s.Dropped)
+	})
+	if d := bytesSent - m.lastBytesSent; d > 0 {
+		m.BytesSent.Inc(d)
+	}
+	if d := dropped - m.lastDropped; d > 0 {
+		m.Dropped.Inc(d)
+	}
+	m.lastBytesSent, m.lastDropped = bytesSent, dropped
+}
@@ -134,12 +134,26 @@ type Node struct {
 	eventLogger sql.EventLogger
 	stores      *storage.Stores // Access to node-local stores
 	metrics     nodeMetrics
+	tables      tableMetrics
 	recorder    *status.MetricsRecorder
 	startedAt   int64
 	lastUp      int64
 	initialBoot bool // True if this is the first time this node has started.
 	txnMetrics  kv.TxnMetrics
 
+	// diskSpaceStates holds the last disk space state of each store seen by
+	// computePeriodicMetrics, to record its changes in the event log.
+	diskSpaceStates map[roachpb.StoreID]storage.DiskSpaceState
+	// unavailableRanges and corruptRanges hold the problem ranges of each
+	// store seen by computePeriodicMetrics, to record the new ones in the
+	// event log.
+	unavailableRanges rangeIDSets
+	corruptRanges     rangeIDSets
+
+	// kvTraceFilter logs the requests touching a span of keys, as set through
+	// the SetKVTraceFilter admin RPC.
+	kvTraceFilter kvTraceFilter
+
 	storesServer storage.Server
 }
 
@@ -278,9 +292,14 @@ func NewNode(
 		stopper:     stopper,
 		recorder:    recorder,
 		metrics:     makeNodeMetrics(reg, cfg.HistogramWindowInterval),
+		tables:      makeTableMetrics(reg),
 		stores:      storage.NewStores(cfg.AmbientCtx, cfg.Clock),
 		txnMetrics:  txnMetrics,
 		eventLogger: eventLogger,
+
+		diskSpaceStates:   make(map[roachpb.StoreID]storage.DiskSpaceState),
+		unavailableRanges: make(rangeIDSets),
+		corruptRanges:     make(rangeIDSets),
 	}
 	n.storesServer = storage.MakeServer(&n.Descriptor, n.stores)
 	return n
@@ -708,14 +727,33 @@ func (n *Node) startComputePeriodicMetrics(stopper *stop.Stopper, interval time.
 }
 
 // computePeriodicMetrics instructs each store to compute the value of
-// complicated metrics.
+// complicated metrics, and updates the per-table metrics of the node.
 func (n *Node) computePeriodicMetrics(ctx context.Context, tick int) error {
-	return n.stores.VisitStores(func(store *storage.Store) error {
+	tables := make(map[uint32]*storage.TableStats)
+	if err := n.stores.VisitStores(func(store *storage.Store) error {
 		if err := store.ComputeMetrics(ctx, tick); err != nil {
 			log.Warningf(ctx, "%s: unable to compute metrics: %s", store, err)
 		}
+		n.maybeRecordDiskSpaceEvent(ctx, store)
+		n.maybeRecordRangeProblemEvents(ctx, store)
+		if tableMetricsMaxTables.Get() > 0 {
+			for id, stats := range store.ComputeTableStats() {
+				if t, ok := tables[id]; ok {
+					t.Add(stats.MVCCStats)
+					t.QPS += stats.QPS
+				} else {
+					tables[id] = stats
+				}
+			}
+		}
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	if cfg, ok := n.storeCfg.Gossip.GetSystemConfig(); ok {
+		n.tables.update(cfg, tables)
+	}
+	return nil
 }
 
 // startWriteSummaries begins periodically persisting status summaries for the
@@ -756,6 +794,95 @@ func (n *Node) writeSummaries(ctx context.Context) error {
 	return err
 }
 
+// maybeRecordDiskSpaceEvent begins an asynchronous task which logs a "store
+// disk space" event if the disk space state of the store changed since the
+// last call. The state of a store at startup is only logged if it isn't ok.
+func (n *Node) maybeRecordDiskSpaceEvent(ctx context.Context, store *storage.Store) {
+	state := store.DiskSpaceState()
+	old, ok := n.diskSpaceStates[store.StoreID()]
+	n.diskSpaceStates[store.StoreID()] = state
+	if !ok {
+		old = storage.DiskSpaceOK
+	}
+	if old == state || !n.storeCfg.LogRangeEvents {
+		return
+	}
+
+	n.recordStoreEvent(ctx, store, sql.EventLogStoreDiskSpace, struct {
+		StoreID  roachpb.StoreID
+		OldState string
+		NewState string
+	}{store.StoreID(), old.String(), state.String()})
+}
+
+// maybeRecordRangeProblemEvents begins asynchronous tasks which log a "range
+// unavailable" event listing the ranges of the store which became unavailable
+// and a "replica corruption" event listing its replicas which became corrupt
+// since the last call.
+func (n *Node) maybeRecordRangeProblemEvents(ctx context.Context, store *storage.Store) {
+	unavailable, corrupt := store.ProblemRangeIDs()
+	newUnavailable := n.unavailableRanges.update(store.StoreID(), unavailable)
+	newCorrupt := n.corruptRanges.update(store.StoreID(), corrupt)
+	if !n.storeCfg.LogRangeEvents {
+		return
+	}
+
+	type rangesInfo struct {
+		StoreID  roachpb.StoreID
+		RangeIDs []roachpb.RangeID
+	}
+	if len(newUnavailable) > 0 {
+		n.recordStoreEvent(ctx, store, sql.EventLogRangeUnavailable,
+			rangesInfo{store.StoreID(), newUnavailable})
+	}
+	if len(newCorrupt) > 0 {
+		n.recordStoreEvent(ctx, store, sql.EventLogReplicaCorruption,
+			rangesInfo{store.StoreID(), newCorrupt})
+	}
+}
+
+// recordStoreEvent begins an asynchronous task which logs an event targeting
+// the store.
+func (n *Node) recordStoreEvent(
+	ctx context.Context, store *storage.Store, eventType sql.EventLogType, info interface{},
+) {
+	if err := n.stopper.RunAsyncTask(ctx, "record-store-event", func(ctx context.Context) {
+		if err := n.storeCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return n.eventLogger.InsertEventRecord(
+				ctx,
+				txn,
+				eventType,
+				int32(store.StoreID()),
+				int32(n.Descriptor.NodeID),
+				info,
+			)
+		}); err != nil {
+			log.Warningf(ctx, "%s: unable to log %s event: %s", store, eventType, err)
+		}
+	}); err != nil {
+		log.Warningf(ctx, "%s: unable to log %s event: %s", store, eventType, err)
+	}
+}
+
+// rangeIDSets holds a set of range IDs for each store.
+type rangeIDSets map[roachpb.StoreID]map[roachpb.RangeID]struct{}
+
+// update replaces the set of the store with the given IDs and returns those
+// which weren't in the previous set.
+func (s rangeIDSets) update(storeID roachpb.StoreID, ids []roachpb.RangeID) []roachpb.RangeID {
+	old := s[storeID]
+	set := make(map[roachpb.RangeID]struct{}, len(ids))
+	var added []roachpb.RangeID
+	for _, id := range ids {
+		set[id] = struct{}{}
+		if _, ok := old[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	s[storeID] = set
+	return added
+}
+
 // recordJoinEvent begins an asynchronous task which attempts to log a "node
 // join" or "node restart" event. This query will retry until it succeeds or the
 // server stops.
@@ -835,6 +962,7 @@ func (n *Node) batchInternal(
 		}
 
 		tStart := timeutil.Now()
+		n.kvTraceFilter.maybeLog(ctx, tStart, args)
 		var pErr *roachpb.Error
 		br, pErr = n.stores.Send(ctx, *args)
 		if pErr != nil {
@@ -747,3 +747,26 @@ func TestStartNodeWithLocality(t *testing.T) {
 		testLocalityWithNewNode(testCase)
 	}
 }
+
+func TestRangeIDSetsUpdate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s := make(rangeIDSets)
+	testCases := []struct {
+		storeID  roachpb.StoreID
+		ids      []roachpb.RangeID
+		expected []roachpb.RangeID
+	}{
+		{1, []roachpb.RangeID{1, 2}, []roachpb.RangeID{1, 2}},
+		{1, []roachpb.RangeID{1, 2}, nil},
+		{2, []roachpb.RangeID{1}, []roachpb.RangeID{1}},
+		{1, []roachpb.RangeID{2, 3}, []roachpb.RangeID{3}},
+		{1, nil, nil},
+		{1, []roachpb.RangeID{2}, []roachpb.RangeID{2}},
+	}
+	for i, tc := range testCases {
+		if added := s.update(tc.storeID, tc.ids); !reflect.DeepEqual(added, tc.expected) {
+			t.Errorf("%d: expected %v to be added, got %v", i, tc.expected, added)
+		}
+	}
+}
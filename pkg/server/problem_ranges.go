@@ -59,6 +59,10 @@ func (s *statusServer) ProblemRanges(
 		err    error
 	}
 	noRaftLeader := make(map[roachpb.RangeID]struct{})
+	raftStalled := make(map[roachpb.RangeID]struct{})
+	// descriptors holds the descriptor of every range with a problem, keyed by
+	// range ID so that ranges reported by several replicas appear only once.
+	descriptors := make(map[roachpb.RangeID]roachpb.RangeDescriptor)
 	numNodes := len(isLiveMap)
 	responses := make(chan nodeResponse)
 	nodeCtx, cancel := context.WithTimeout(ctx, base.NetworkTimeout)
@@ -135,6 +139,16 @@ func (s *statusServer) ProblemRanges(
 					response.NoLeaseRangeIDs =
 						append(response.NoLeaseRangeIDs, info.State.Desc.RangeID)
 				}
+				if info.Problems.TooLarge {
+					response.TooLargeRangeIDs =
+						append(response.TooLargeRangeIDs, info.State.Desc.RangeID)
+				}
+				if info.Problems.RaftStalled {
+					raftStalled[info.State.Desc.RangeID] = struct{}{}
+				}
+				if info.Problems != (serverpb.RangeProblems{}) {
+					descriptors[info.State.Desc.RangeID] = *info.State.Desc
+				}
 			}
 		case <-ctx.Done():
 			return nil, grpc.Errorf(codes.DeadlineExceeded, ctx.Err().Error())
@@ -145,12 +159,24 @@ func (s *statusServer) ProblemRanges(
 		response.NoRaftLeaderRangeIDs =
 			append(response.NoRaftLeaderRangeIDs, rangeID)
 	}
+	for rangeID := range raftStalled {
+		response.RaftStalledRangeIDs =
+			append(response.RaftStalledRangeIDs, rangeID)
+	}
+	for _, desc := range descriptors {
+		response.RangeDescriptors = append(response.RangeDescriptors, desc)
+	}
 
 	sort.Sort(roachpb.RangeIDSlice(response.UnavailableRangeIDs))
 	sort.Sort(roachpb.RangeIDSlice(response.RaftLeaderNotLeaseHolderRangeIDs))
 	sort.Sort(roachpb.RangeIDSlice(response.NoRaftLeaderRangeIDs))
 	sort.Sort(roachpb.RangeIDSlice(response.NoLeaseRangeIDs))
 	sort.Sort(roachpb.RangeIDSlice(response.UnderreplicatedRangeIDs))
+	sort.Sort(roachpb.RangeIDSlice(response.TooLargeRangeIDs))
+	sort.Sort(roachpb.RangeIDSlice(response.RaftStalledRangeIDs))
+	sort.Slice(response.RangeDescriptors, func(i, j int) bool {
+		return response.RangeDescriptors[i].RangeID < response.RangeDescriptors[j].RangeID
+	})
 
 	return response, nil
 }
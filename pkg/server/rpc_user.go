@@ -0,0 +1,105 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
+)
+
+// httpUserMetadataKey is the gRPC metadata under which the HTTP gateway
+// forwards the user of the HTTP requests it proxies, which it makes as the
+// node user.
+const httpUserMetadataKey = "cockroach-http-user"
+
+// httpUserHeader is the HTTP header forwarded by the gateway as the
+// httpUserMetadataKey metadata.
+const httpUserHeader = gwruntime.MetadataHeaderPrefix + httpUserMetadataKey
+
+// anonymousHTTPUser is the user of the HTTP requests without a client
+// certificate. It is not a valid SQL user name.
+const anonymousHTTPUser = "(anonymous)"
+
+// setHTTPUser sets the header under which the gateway forwards the user of
+// the HTTP request r: the user of its client certificate, or root in
+// insecure mode. Any such header sent by the client is overridden.
+func setHTTPUser(r *http.Request, insecure bool) {
+	user := anonymousHTTPUser
+	if insecure {
+		user = security.RootUser
+	} else if certUser, err := security.GetCertificateUser(r.TLS); err == nil {
+		user = certUser
+	}
+	r.Header.Set(httpUserHeader, user)
+}
+
+// httpUser returns the user of the HTTP request r, as set by setHTTPUser
+// when the server received it.
+func httpUser(r *http.Request) string {
+	return r.Header.Get(httpUserHeader)
+}
+
+// rpcUser returns the user on behalf of which the RPC handled with ctx is
+// made:
+// - in-process requests are made by the node user;
+// - the requests proxied by the HTTP gateway are made by the user of the
+//   HTTP request (see setHTTPUser);
+// - other requests are made by the user of their client certificate, or by
+//   root in insecure mode.
+func rpcUser(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || grpcutil.IsLocalRequestContext(ctx) {
+		return security.NodeUser, nil
+	}
+	user := security.RootUser
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		var err error
+		if user, err = security.GetCertificateUser(&tlsInfo.State); err != nil {
+			return "", err
+		}
+	}
+	if user == security.NodeUser || p.AuthInfo == nil {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md[httpUserMetadataKey]; len(vals) > 0 {
+				return vals[0], nil
+			}
+		}
+	}
+	return user, nil
+}
+
+// requireRootOrNode returns an error unless the RPC handled with ctx is made
+// by root or by a node, over HTTP or gRPC. op describes the operation of the
+// RPC.
+func requireRootOrNode(ctx context.Context, op string) error {
+	user, err := rpcUser(ctx)
+	if err != nil {
+		return grpc.Errorf(codes.Unauthenticated, "%s", err)
+	}
+	if user != security.RootUser && user != security.NodeUser {
+		return grpc.Errorf(codes.PermissionDenied, "user %s is not allowed to %s", user, op)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func tlsStateForUser(user string) tls.ConnectionState {
+	return tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: user}}},
+	}
+}
+
+func TestRPCUser(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	withPeer := func(certUser string) context.Context {
+		p := &peer.Peer{}
+		if certUser != "" {
+			p.AuthInfo = credentials.TLSInfo{State: tlsStateForUser(certUser)}
+		}
+		return peer.NewContext(context.Background(), p)
+	}
+	withHTTPUser := func(ctx context.Context, user string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(httpUserMetadataKey, user))
+	}
+
+	testCases := []struct {
+		ctx      context.Context
+		expected string
+		allowed  bool
+	}{
+		// In-process requests.
+		{context.Background(), security.NodeUser, true},
+		// gRPC requests.
+		{withPeer(security.NodeUser), security.NodeUser, true},
+		{withPeer(security.RootUser), security.RootUser, true},
+		{withPeer("foo"), "foo", false},
+		// Only the nodes may forward the user of an HTTP request.
+		{withHTTPUser(withPeer("foo"), security.RootUser), "foo", false},
+		// HTTP requests.
+		{withHTTPUser(withPeer(security.NodeUser), security.RootUser), security.RootUser, true},
+		{withHTTPUser(withPeer(security.NodeUser), "foo"), "foo", false},
+		{withHTTPUser(withPeer(security.NodeUser), anonymousHTTPUser), anonymousHTTPUser, false},
+		// Insecure mode.
+		{withPeer(""), security.RootUser, true},
+		{withHTTPUser(withPeer(""), security.RootUser), security.RootUser, true},
+	}
+	for i, tc := range testCases {
+		user, err := rpcUser(tc.ctx)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if user != tc.expected {
+			t.Errorf("%d: expected user %s, got %s", i, tc.expected, user)
+		}
+		if err := requireRootOrNode(tc.ctx, "test"); (err == nil) != tc.allowed {
+			t.Errorf("%d: expected allowed=%t, got %v", i, tc.allowed, err)
+		}
+	}
+}
+
+func TestSetHTTPUser(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		tlsState *tls.ConnectionState
+		insecure bool
+		expected string
+	}{
+		{nil, false, anonymousHTTPUser},
+		{&tls.ConnectionState{}, false, anonymousHTTPUser},
+		{func() *tls.ConnectionState { s := tlsStateForUser("foo"); return &s }(), false, "foo"},
+		{nil, true, security.RootUser},
+	}
+	for i, tc := range testCases {
+		r, err := http.NewRequest("POST", "/_status/cancel_query/local", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.TLS = tc.tlsState
+		// The header sent by the client is never trusted.
+		r.Header.Set(httpUserHeader, security.RootUser)
+		setHTTPUser(r, tc.insecure)
+		if vals := r.Header[http.CanonicalHeaderKey(httpUserHeader)]; len(vals) != 1 || vals[0] != tc.expected {
+			t.Errorf("%d: expected %s, got %v", i, tc.expected, vals)
+		}
+	}
+}
+
+func TestHandleLogEntriesRequiresRootOrNode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		tlsState *tls.ConnectionState
+		expected int
+	}{
+		{nil, http.StatusForbidden},
+		{func() *tls.ConnectionState { s := tlsStateForUser("foo"); return &s }(), http.StatusForbidden},
+		{func() *tls.ConnectionState { s := tlsStateForUser(security.RootUser); return &s }(), http.StatusOK},
+		{func() *tls.ConnectionState { s := tlsStateForUser(security.NodeUser); return &s }(), http.StatusOK},
+	}
+	var s statusServer
+	for i, tc := range testCases {
+		r := httptest.NewRequest("GET", logEntriesEndpoint, nil)
+		r.TLS = tc.tlsState
+		setHTTPUser(r, false /* insecure */)
+		w := httptest.NewRecorder()
+		s.handleLogEntries(w, r)
+		if w.Code != tc.expected {
+			t.Errorf("%d: expected status %d, got %d: %s", i, tc.expected, w.Code, w.Body.String())
+		}
+	}
+}
@@ -107,6 +107,7 @@ type Server struct {
 	registry           *metric.Registry
 	recorder           *status.MetricsRecorder
 	runtime            status.RuntimeStatSampler
+	logSinkMetrics     *logSinkMetrics
 	admin              *adminServer
 	status             *statusServer
 	tsDB               *ts.DB
@@ -116,9 +117,15 @@ type Server struct {
 	sqlExecutor        *sql.Executor
 	leaseMgr           *sql.LeaseManager
 	sessionRegistry    *sql.SessionRegistry
+	sqlMemoryMonitor   *mon.MemoryMonitor
+	sqlMemoryMetrics   sqlMemoryMetrics
 	engines            Engines
 	internalMemMetrics sql.MemoryMetrics
 	adminMemMetrics    sql.MemoryMetrics
+	// startTime is the time at which Start was called, from which the
+	// uptime sent to the registration server is computed.
+	startTime       time.Time
+	clockQuarantine clockQuarantine
 }
 
 // NewServer creates a Server from a server.Context.
@@ -131,9 +138,14 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		cfg.AmbientCtx.Tracer = tracing.NewTracer()
 	}
 
+	physicalClock := hlc.UnixNano
+	if cfg.manualClock != nil {
+		physicalClock = cfg.manualClock.UnixNano
+	}
+
 	s := &Server{
 		mux:      http.NewServeMux(),
-		clock:    hlc.NewClock(hlc.UnixNano, cfg.MaxOffset),
+		clock:    hlc.NewClock(physicalClock, cfg.MaxOffset),
 		stopper:  stopper,
 		cfg:      cfg,
 		registry: metric.NewRegistry(),
@@ -164,10 +176,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	ctx := s.AnnotateCtx(context.Background())
 
 	s.rpcContext = rpc.NewContext(s.cfg.AmbientCtx, s.cfg.Config, s.clock, s.stopper)
+	if s.cfg.TestingKnobs.RPC != nil {
+		s.rpcContext.Knobs = *s.cfg.TestingKnobs.RPC.(*rpc.ContextTestingKnobs)
+	}
 	s.rpcContext.HeartbeatCB = func() {
-		if err := s.rpcContext.RemoteClocks.VerifyClockOffset(ctx); err != nil {
-			log.Fatal(ctx, err)
-		}
+		s.verifyClockOffset(ctx)
 	}
 	s.grpc = rpc.NewServer(s.rpcContext)
 
@@ -267,11 +280,29 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		math.MaxInt64, /* noteworthy */
 	)
 	rootSQLMemoryMonitor.Start(context.Background(), nil, mon.MakeStandaloneBudget(s.cfg.SQLMemoryPoolSize))
+	s.sqlMemoryMonitor = &rootSQLMemoryMonitor
+	s.sqlMemoryMetrics = s.makeSQLMemoryMetrics()
+	s.registry.AddMetricStruct(s.sqlMemoryMetrics)
 
 	distSQLMetrics := sql.MakeMemMetrics("distsql", cfg.HistogramWindowInterval())
 	s.registry.AddMetric(distSQLMetrics.CurBytesCount)
 	s.registry.AddMetric(distSQLMetrics.MaxBytesHist)
 
+	tempDir := s.cfg.TempDir
+	if tempDir == "" {
+		for _, spec := range s.cfg.Stores.Specs {
+			if !spec.InMemory {
+				tempDir = spec.Path
+				break
+			}
+		}
+	}
+	tempStorage, err := distsqlrun.NewTempStorage(tempDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create temporary storage")
+	}
+	s.stopper.AddCloser(tempStorage)
+
 	// Set up the DistSQL server.
 	distSQLCfg := distsqlrun.ServerConfig{
 		AmbientContext: s.cfg.AmbientCtx,
@@ -285,6 +316,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		ParentMemoryMonitor: &rootSQLMemoryMonitor,
 		Counter:             distSQLMetrics.CurBytesCount,
 		Hist:                distSQLMetrics.MaxBytesHist,
+		TempStorage:         tempStorage,
 	}
 	if s.cfg.TestingKnobs.DistSQL != nil {
 		distSQLCfg.TestingKnobs = *s.cfg.TestingKnobs.DistSQL.(*distsqlrun.TestingKnobs)
@@ -335,6 +367,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 
 	s.runtime = status.MakeRuntimeStatSampler(s.clock)
 	s.registry.AddMetricStruct(s.runtime)
+	s.logSinkMetrics = makeLogSinkMetrics()
+	s.registry.AddMetricStruct(s.logSinkMetrics)
 
 	s.node = NewNode(storeCfg, s.recorder, s.registry, s.stopper, txnMetrics, sql.MakeEventLogger(s.leaseMgr))
 	roachpb.RegisterInternalServer(s.grpc, s.node)
@@ -371,6 +405,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		RPCContext:              s.rpcContext,
 		LeaseManager:            s.leaseMgr,
 		Clock:                   s.clock,
+		Stopper:                 s.stopper,
 		DistSQLSrv:              s.distSQLServer,
 		StatusServer:            s.status,
 		SessionRegistry:         s.sessionRegistry,
@@ -461,6 +496,7 @@ func (s *Server) Start(ctx context.Context) error {
 	ctx = s.AnnotateCtx(ctx)
 
 	startTime := timeutil.Now()
+	s.startTime = startTime
 
 	tlsConfig, err := s.cfg.GetServerTLSConfig()
 	if err != nil {
@@ -728,6 +764,17 @@ func (s *Server) Start(ctx context.Context) error {
 	// Begin recording runtime statistics.
 	s.startSampleEnvironment(s.cfg.MetricsSampleInterval)
 
+	// Keep a connection open to every node, so that the heartbeats of the
+	// connections measure the network latency between all the nodes.
+	s.startConnectingToAllNodes(s.cfg.MetricsSampleInterval)
+
+	// Warn about the certificates which are about to expire.
+	s.startCertificateExpiryChecks()
+
+	// Warn about the sessions using the most memory when the SQL memory
+	// pool is about to be exhausted.
+	s.startSQLMemoryChecks()
+
 	// Begin recording time series data collected by the status monitor.
 	s.tsDB.PollSource(
 		s.cfg.AmbientCtx, s.recorder, s.cfg.MetricsSampleInterval, ts.Resolution10s, s.stopper,
@@ -754,6 +801,16 @@ func (s *Server) Start(ctx context.Context) error {
 		s.clock,
 	).Start(s.stopper)
 
+	// Start deleting the expired rows of the tables with a row-level TTL.
+	sql.NewRowTTLManager(
+		*s.db,
+		s.gossip,
+		s.leaseMgr,
+		s.nodeLiveness,
+		s.node.Descriptor.NodeID,
+		s.clock,
+	).Start(s.stopper)
+
 	s.sqlExecutor.Start(ctx, &s.adminMemMetrics, s.node.Descriptor)
 	s.distSQLServer.Start()
 
@@ -851,15 +908,24 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// TODO(marc): when cookie-based authentication exists,
 	// apply it for all web endpoints.
-	s.mux.Handle(adminPrefix, gwMux)
-	s.mux.Handle(ts.URLPrefix, gwMux)
-	s.mux.Handle(statusPrefix, gwMux)
-	s.mux.Handle("/health", gwMux)
-	s.mux.Handle(statusVars, http.HandlerFunc(s.status.handleVars))
-	s.mux.Handle(rangeDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugRange)))
-	s.mux.Handle(certificatesDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugCertificates)))
-	s.mux.Handle(networkDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugNetwork)))
-	s.mux.Handle(nodesDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugNodes)))
+	// A panic in the handling of an API request is reported, and fails the
+	// request rather than the node.
+	handleAPI := func(route string, h http.Handler) {
+		s.mux.Handle(route, recoverHandler(route, h))
+	}
+	handleAPI(adminPrefix, gwMux)
+	handleAPI(ts.URLPrefix, gwMux)
+	handleAPI(statusPrefix, gwMux)
+	handleAPI("/health", gwMux)
+	handleAPI(statusVars, http.HandlerFunc(s.status.handleVars))
+	handleAPI(featureUsageEndpoint, http.HandlerFunc(s.status.handleFeatureUsage))
+	handleAPI(logEntriesEndpoint, http.HandlerFunc(s.status.handleLogEntries))
+	handleAPI(rangeDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugRange)))
+	handleAPI(certificatesDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugCertificates)))
+	handleAPI(networkDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugNetwork)))
+	handleAPI(gossipDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugGossip)))
+	handleAPI(nodesDebugEndpoint, authorizedHandler(http.HandlerFunc(s.status.handleDebugNodes)))
+	handleAPI(DiagnosticsPreviewEndpoint, authorizedHandler(http.HandlerFunc(s.handleDiagnosticsPreview)))
 	log.Event(ctx, "added http endpoints")
 
 	// Before serving SQL requests, we have to make sure the database is
@@ -879,6 +945,10 @@ func (s *Server) Start(ctx context.Context) error {
 	// executes a SQL query, this must be done after the SQL layer is ready.
 	s.node.recordJoinEvent()
 
+	// Propagate the backoffs requested by the crash reporting server to the
+	// rest of the cluster, which is also done through SQL.
+	log.SetCrashReportBackoffHook(s.pauseCrashReports)
+
 	if s.cfg.PIDFile != "" {
 		if err := ioutil.WriteFile(s.cfg.PIDFile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
 			log.Error(ctx, err)
@@ -904,6 +974,25 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// pauseCrashReports sets the cluster setting which stops all the nodes
+// from sending crash reports until the given time.
+func (s *Server) pauseCrashReports(until time.Time) {
+	ctx := s.AnnotateCtx(context.Background())
+	if err := s.stopper.RunAsyncTask(ctx, "server: pausing crash reports", func(ctx context.Context) {
+		setStmt := fmt.Sprintf("SET CLUSTER SETTING diagnostics.reporting.crash_reports_paused_until = %d",
+			until.Unix())
+		ie := sql.InternalExecutor{LeaseManager: s.leaseMgr}
+		if err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			_, err := ie.ExecuteStatementInTransaction(ctx, "pause-crash-reports", txn, setStmt)
+			return err
+		}); err != nil {
+			log.Warningf(ctx, "unable to pause crash reports on the cluster: %s", err)
+		}
+	}); err != nil {
+		log.Warningf(ctx, "unable to pause crash reports on the cluster: %s", err)
+	}
+}
+
 func (s *Server) doDrain(modes []serverpb.DrainMode, setTo bool) ([]serverpb.DrainMode, error) {
 	for _, mode := range modes {
 		switch mode {
@@ -959,6 +1048,28 @@ func (s *Server) Undrain(off []serverpb.DrainMode) []serverpb.DrainMode {
 	return nowActive
 }
 
+// Decommission idempotently sets the decommissioning flag for the specified
+// nodes.
+func (s *Server) Decommission(ctx context.Context, setTo bool, nodeIDs []roachpb.NodeID) error {
+	for _, nodeID := range nodeIDs {
+		if err := s.nodeLiveness.SetDecommissioning(ctx, nodeID, setTo); err != nil {
+			return errors.Wrapf(err, "while trying to set decommissioning for node %d", nodeID)
+		}
+	}
+	return nil
+}
+
+// localReplicaCount returns the number of replicas held by the stores of this
+// node.
+func (s *Server) localReplicaCount() int {
+	var count int
+	_ = s.node.stores.VisitStores(func(store *storage.Store) error {
+		count += store.ReplicaCount()
+		return nil
+	})
+	return count
+}
+
 // startSampleEnvironment begins a worker that periodically instructs the
 // runtime stat sampler to sample the environment.
 func (s *Server) startSampleEnvironment(frequency time.Duration) {
@@ -971,6 +1082,40 @@ func (s *Server) startSampleEnvironment(frequency time.Duration) {
 			select {
 			case <-ticker.C:
 				s.runtime.SampleEnvironment(ctx)
+				s.logSinkMetrics.sample()
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// startConnectingToAllNodes periodically dials the live nodes this node has
+// no connection to. The RPC connections are heartbeated, which records the
+// round-trip latency to each node in the remote clock monitor.
+func (s *Server) startConnectingToAllNodes(frequency time.Duration) {
+	ctx := s.AnnotateCtx(context.Background())
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(frequency)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for nodeID, alive := range s.nodeLiveness.GetIsLiveMap() {
+					if !alive || nodeID == s.NodeID() {
+						continue
+					}
+					addr, err := s.gossip.GetNodeIDAddress(nodeID)
+					if err != nil {
+						log.Warning(ctx, err)
+						continue
+					}
+					// Connections are cached, so this only dials the nodes which
+					// aren't connected yet.
+					if _, err := s.rpcContext.GRPCDial(addr.String()); err != nil {
+						log.Warningf(ctx, "failed to dial n%d: %s", nodeID, err)
+					}
+				}
 			case <-s.stopper.ShouldStop():
 				return
 			}
@@ -991,6 +1136,14 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Disable caching of responses.
 	w.Header().Set("Cache-control", "no-cache")
 
+	if tp := r.Header.Get(log.TraceparentHeader); tp != "" {
+		r = r.WithContext(log.WithTraceparent(r.Context(), tp))
+	}
+
+	// The RPC handlers authorize the requests proxied by the gateway based
+	// on the user of the HTTP request.
+	setHTTPUser(r, s.cfg.Insecure)
+
 	ae := r.Header.Get(httputil.AcceptEncodingHeader)
 	switch {
 	case strings.Contains(ae, httputil.GzipEncoding):
@@ -1002,6 +1155,43 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
 }
 
+// reportedMethods are the HTTP methods included verbatim in the reports
+// of panics in the handling of API requests. Other methods are reported as
+// "other", since the method of a request is arbitrary.
+var reportedMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// recoverHandler returns a handler which recovers from the panics of h,
+// reports them and fails the request with an internal error. Reports are
+// tagged with the method of the request and the route of h, and never
+// include the path or the body of the request, which may contain user
+// data.
+func recoverHandler(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				method := r.Method
+				if !reportedMethods[method] {
+					method = "other"
+				}
+				ctx := log.WithReportTag(r.Context(), "http.method", method)
+				ctx = log.WithReportTag(ctx, "http.route", route)
+				log.ReportRecoveredPanic(ctx, p, 1)
+				http.Error(w, http.StatusText(http.StatusInternalServerError),
+					http.StatusInternalServerError)
+			}
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
+
 type gzipResponseWriter struct {
 	io.WriteCloser
 	http.ResponseWriter
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"reflect"
@@ -497,3 +498,71 @@ func TestListenURLFileCreation(t *testing.T) {
 		t.Fatalf("expected URL %s to match host %s", u, s.ServingAddr())
 	}
 }
+
+func TestRecoverHandler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h := recoverHandler("/_admin/v1/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	for method, expected := range map[string]int{
+		http.MethodGet:  http.StatusAccepted,
+		http.MethodPost: http.StatusInternalServerError,
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "/_admin/v1/databases/secret", nil))
+		if w.Code != expected {
+			t.Errorf("%s: expected status %d, got %d", method, expected, w.Code)
+		}
+	}
+}
+
+func TestManualClockExpireLeases(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	if err := (&TestServer{Cfg: &Config{}}).AdvanceClock(time.Second); err == nil {
+		t.Fatal("expected an error advancing the clock of a server using the wall clock")
+	}
+
+	manual := hlc.NewManualClock(123)
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{Clock: manual})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	if s.ManualClock() != manual {
+		t.Fatal("expected the server to expose its manual clock")
+	}
+	before := s.Clock().Now()
+	if err := s.AdvanceClock(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if now := s.Clock().Now(); now.WallTime < before.WallTime+time.Second.Nanoseconds() {
+		t.Fatalf("expected the clock to advance by 1s from %s, got %s", before, now)
+	}
+
+	// The first range uses an expiration-based lease.
+	lease, _, err := ts.GetRangeLease(context.TODO(), keys.Meta1Prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	livenesses := ts.nodeLiveness.GetLivenesses()
+	if len(livenesses) == 0 {
+		t.Fatal("expected at least one liveness record")
+	}
+	if err := s.ExpireLeases(); err != nil {
+		t.Fatal(err)
+	}
+	now := s.Clock().Now()
+	maxOffset := s.Clock().MaxOffset().Nanoseconds()
+	if now.WallTime <= lease.Expiration.WallTime+maxOffset {
+		t.Errorf("expected lease %s to be expired at %s", lease, now)
+	}
+	for _, l := range livenesses {
+		if now.WallTime <= l.Expiration.WallTime+maxOffset {
+			t.Errorf("expected liveness of node %d to be expired at %s", l.NodeID, now)
+		}
+	}
+}
@@ -313,8 +313,8 @@ func TestSettingsShowAll(t *testing.T) {
 	if len(rows) < 2 {
 		t.Fatalf("show all returned too few rows (%d)", len(rows))
 	}
-	if len(rows[0]) != 4 {
-		t.Fatalf("show all must return 4 columns, found %d", len(rows[0]))
+	if len(rows[0]) != 5 {
+		t.Fatalf("show all must return 5 columns, found %d", len(rows[0]))
 	}
 	hasIntKey := false
 	hasStrKey := false
@@ -0,0 +1,152 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+var sqlMemoryWarningThreshold = settings.RegisterNonNegativeFloatSetting(
+	"sql.mem.warning_threshold",
+	"fraction of --max-sql-memory beyond which the sessions using the most memory are logged "+
+		"(set to 0 to disable)",
+	0.8,
+)
+
+// sqlMemoryCheckInterval is the interval at which the memory used by the
+// SQL sessions is checked against the warning threshold.
+const sqlMemoryCheckInterval = 10 * time.Second
+
+// sqlMemoryWarningTopSessions is the number of sessions logged when the
+// memory usage crosses the warning threshold.
+const sqlMemoryWarningTopSessions = 5
+
+var (
+	metaSQLMemRootCurBytes = metric.Metadata{
+		Name: "sql.mem.root.current",
+		Help: "Current bytes allocated from the SQL memory pool"}
+	metaSQLMemLargestSessionBytes = metric.Metadata{
+		Name: "sql.mem.session.largest",
+		Help: "Bytes allocated by the SQL session using the most memory, as of the last check"}
+	metaSQLMemWarnings = metric.Metadata{
+		Name: "sql.mem.warnings",
+		Help: "Number of times the SQL memory usage crossed the warning threshold"}
+)
+
+// sqlMemoryMetrics tracks the memory used by the SQL sessions of the
+// node, for comparison with the --max-sql-memory pool.
+type sqlMemoryMetrics struct {
+	RootCurBytes        *metric.Gauge
+	LargestSessionBytes *metric.Gauge
+	Warnings            *metric.Counter
+}
+
+func (s *Server) makeSQLMemoryMetrics() sqlMemoryMetrics {
+	return sqlMemoryMetrics{
+		RootCurBytes:        metric.NewFunctionalGauge(metaSQLMemRootCurBytes, s.sqlMemoryMonitor.AllocBytes),
+		LargestSessionBytes: metric.NewGauge(metaSQLMemLargestSessionBytes),
+		Warnings:            metric.NewCounter(metaSQLMemWarnings),
+	}
+}
+
+// topSQLMemoryConsumers returns the n sessions using the most memory,
+// largest first.
+func topSQLMemoryConsumers(sessions []serverpb.Session, n int) []log.SQLMemoryConsumer {
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].AllocBytes > sessions[j].AllocBytes
+	})
+	if len(sessions) > n {
+		sessions = sessions[:n]
+	}
+	consumers := make([]log.SQLMemoryConsumer, 0, len(sessions))
+	for _, session := range sessions {
+		queryIDs := make([]string, 0, len(session.ActiveQueries))
+		for _, query := range session.ActiveQueries {
+			queryIDs = append(queryIDs, query.ID)
+		}
+		consumers = append(consumers, log.SQLMemoryConsumer{
+			SessionID:       session.ID,
+			Username:        session.Username,
+			ApplicationName: session.ApplicationName,
+			AllocBytes:      session.AllocBytes,
+			QueryIDs:        queryIDs,
+		})
+	}
+	return consumers
+}
+
+// startSQLMemoryChecks starts a worker which periodically compares the
+// memory used by the SQL sessions with the --max-sql-memory pool, and logs
+// the sessions using the most memory when the usage crosses the warning
+// threshold, before queries start failing for lack of memory.
+func (s *Server) startSQLMemoryChecks() {
+	ctx := s.AnnotateCtx(context.Background())
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		// warned is set while the usage remains above the threshold, so that
+		// each crossing is logged once.
+		warned := false
+		ticker := time.NewTicker(sqlMemoryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				warned = s.checkSQLMemory(ctx, warned)
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// checkSQLMemory updates the SQL memory metrics and logs a warning if the
+// memory usage is above the threshold and warned is not set. It returns
+// whether the usage is above the threshold.
+func (s *Server) checkSQLMemory(ctx context.Context, warned bool) bool {
+	sessions := s.sessionRegistry.SerializeAll()
+	var largest int64
+	for _, session := range sessions {
+		if session.AllocBytes > largest {
+			largest = session.AllocBytes
+		}
+	}
+	s.sqlMemoryMetrics.LargestSessionBytes.Update(largest)
+
+	threshold := sqlMemoryWarningThreshold.Get()
+	pool := s.cfg.SQLMemoryPoolSize
+	if threshold <= 0 || pool <= 0 {
+		return false
+	}
+	alloc := s.sqlMemoryMonitor.AllocBytes()
+	if float64(alloc) < threshold*float64(pool) {
+		return false
+	}
+	if !warned {
+		s.sqlMemoryMetrics.Warnings.Inc(1)
+		log.LogSQLMemoryWarning(ctx, log.SQLMemoryWarning{
+			AllocBytes:  alloc,
+			PoolBytes:   pool,
+			TopSessions: topSQLMemoryConsumers(sessions, sqlMemoryWarningTopSessions),
+		})
+	}
+	return true
+}
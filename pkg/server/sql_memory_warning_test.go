@@ -0,0 +1,43 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestTopSQLMemoryConsumers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sessions := []serverpb.Session{
+		{ID: "a", Username: "u1", AllocBytes: 10},
+		{ID: "b", Username: "u2", ApplicationName: "app", AllocBytes: 30,
+			ActiveQueries: []serverpb.ActiveQuery{{ID: "q1"}, {ID: "q2"}}},
+		{ID: "c", Username: "u1", AllocBytes: 20},
+	}
+	expected := []log.SQLMemoryConsumer{
+		{SessionID: "b", Username: "u2", ApplicationName: "app", AllocBytes: 30,
+			QueryIDs: []string{"q1", "q2"}},
+		{SessionID: "c", Username: "u1", AllocBytes: 20, QueryIDs: []string{}},
+	}
+	if top := topSQLMemoryConsumers(sessions, 2); !reflect.DeepEqual(top, expected) {
+		t.Errorf("expected %+v, got %+v", expected, top)
+	}
+}
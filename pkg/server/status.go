@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -45,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
@@ -67,6 +69,13 @@ const (
 	// statusVars exposes prometheus metrics for monitoring consumption.
 	statusVars = statusPrefix + "vars"
 
+	// featureUsageEndpoint exposes the feature usage counts of the node.
+	featureUsageEndpoint = statusPrefix + "feature-usage"
+
+	// logEntriesEndpoint streams the filtered entries of the log files of
+	// the node.
+	logEntriesEndpoint = statusPrefix + "logentries"
+
 	// rangeDebugEndpoint exposes an html page with information about a specific range.
 	rangeDebugEndpoint = "/debug/range"
 
@@ -77,6 +86,10 @@ const (
 	// information about network connections.
 	networkDebugEndpoint = "/debug/network"
 
+	// gossipDebugEndpoint exposes an html page listing the gossiped infos of
+	// a node along with their age.
+	gossipDebugEndpoint = "/debug/gossip"
+
 	// nodesDebugEndpoint exposes an html page with a list of active nodes
 	// and their statuses.
 	nodesDebugEndpoint = "/debug/nodes"
@@ -257,6 +270,7 @@ func (s *statusServer) Certificates(
 
 		if cert.Error == nil {
 			details.Data = cert.FileContents
+			details.ExpirationTime = cert.ExpirationTime
 		} else {
 			details.ErrorMessage = cert.Error.Error()
 		}
@@ -266,6 +280,44 @@ func (s *statusServer) Certificates(
 	return cr, nil
 }
 
+// ReloadCertificates reloads the certificates of a node from its certificates
+// directory. New connections use the reloaded certificates. It is restricted
+// to root and the nodes.
+func (s *statusServer) ReloadCertificates(
+	ctx context.Context, req *serverpb.ReloadCertificatesRequest,
+) (*serverpb.ReloadCertificatesResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	if err := requireRootOrNode(ctx, "reload certificates"); err != nil {
+		return nil, err
+	}
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if s.cfg.Insecure {
+		return nil, errors.New("server is in insecure mode, cannot reload certificates")
+	}
+
+	if !local {
+		status, err := s.dialNode(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.ReloadCertificates(ctx, req)
+	}
+
+	cm, err := s.cfg.GetCertificateManager()
+	if err != nil {
+		return nil, err
+	}
+	log.Info(ctx, "reloading certificates on request")
+	if err := cm.LoadCertificates(); err != nil {
+		return nil, errors.Wrap(err, "could not reload certificates")
+	}
+	return &serverpb.ReloadCertificatesResponse{}, nil
+}
+
 // Details returns node details.
 func (s *statusServer) Details(
 	ctx context.Context, req *serverpb.DetailsRequest,
@@ -660,6 +712,27 @@ func (s *statusServer) handleVars(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleLogEntries streams the entries of the log files of this node, see
+// log.ServeLogEntries. It is restricted to root and the nodes.
+func (s *statusServer) handleLogEntries(w http.ResponseWriter, r *http.Request) {
+	if user := httpUser(r); user != security.RootUser && user != security.NodeUser {
+		http.Error(w, fmt.Sprintf("user %s is not allowed to read the log entries", user),
+			http.StatusForbidden)
+		return
+	}
+	log.ServeLogEntries(w, r)
+}
+
+// handleFeatureUsage serves the usage count of each feature used on this
+// node since it started, as reported in the diagnostics report.
+func (s *statusServer) handleFeatureUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	if err := json.NewEncoder(w).Encode(telemetry.GetFeatureCounts()); err != nil {
+		log.Error(r.Context(), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // Ranges returns range info for the specified node.
 func (s *statusServer) Ranges(
 	ctx context.Context, req *serverpb.RangesRequest,
@@ -733,6 +806,8 @@ func (s *statusServer) Ranges(
 				NoRaftLeader:         !storage.HasRaftLeader(raftStatus) && !metrics.Quiescent,
 				Underreplicated:      metrics.Leader && metrics.Underreplicated,
 				NoLease:              metrics.Leader && !metrics.LeaseValid && !metrics.Quiescent,
+				TooLarge:             metrics.TooLarge,
+				RaftStalled:          metrics.RaftStalled,
 			},
 		}
 	}
@@ -898,6 +973,208 @@ func (s *statusServer) ListSessions(
 	return &resp, nil
 }
 
+// ListLocalContentionEvents returns the contention events recorded by the
+// stores of this node.
+func (s *statusServer) ListLocalContentionEvents(
+	ctx context.Context, req *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	nodeID := s.gossip.NodeID.Get()
+	resp := serverpb.ListContentionEventsResponse{
+		Events: make([]serverpb.ContentionEvent, 0),
+	}
+	err := s.stores.VisitStores(func(store *storage.Store) error {
+		for _, event := range store.ContentionEvents() {
+			resp.Events = append(resp.Events, serverpb.ContentionEvent{
+				NodeID:            nodeID,
+				StoreID:           store.StoreID(),
+				Key:               event.Key,
+				TxnID:             event.TxnID,
+				PusheeTxnID:       event.PusheeTxnID,
+				PushType:          event.PushType.String(),
+				Outcome:           event.Outcome,
+				Time:              event.Time,
+				WaitDurationNanos: event.WaitDuration.Nanoseconds(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, err.Error())
+	}
+	return &resp, nil
+}
+
+// ListContentionEvents returns the contention events recorded by all the
+// nodes in the cluster.
+func (s *statusServer) ListContentionEvents(
+	ctx context.Context, req *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	nodes, err := s.Nodes(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := serverpb.ListContentionEventsResponse{
+		Events: make([]serverpb.ContentionEvent, 0),
+		Errors: make([]serverpb.ListContentionEventsError, 0),
+	}
+
+	// Issue ListLocalContentionEvents requests in parallel, not more than
+	// maxConcurrentRequests at once.
+	sem := make(chan struct{}, maxConcurrentRequests)
+	numNodes := len(nodes.Nodes)
+
+	eventsChan := make(chan *serverpb.ListContentionEventsResponse, numNodes)
+	errorsChan := make(chan serverpb.ListContentionEventsError, numNodes)
+
+	getNodeEvents := func(ctx context.Context, nodeID roachpb.NodeID) {
+		rpcCtx, cancel := context.WithTimeout(ctx, base.NetworkTimeout)
+		defer cancel()
+
+		status, err := s.dialNode(nodeID)
+		if err != nil {
+			errorsChan <- serverpb.ListContentionEventsError{
+				NodeID:  nodeID,
+				Message: errors.Wrapf(err, "failed to dial into node %d", nodeID).Error(),
+			}
+			return
+		}
+
+		events, err := status.ListLocalContentionEvents(rpcCtx, req)
+		if err != nil {
+			errorsChan <- serverpb.ListContentionEventsError{
+				NodeID:  nodeID,
+				Message: errors.Wrapf(err, "failed to get contention events from node %d", nodeID).Error(),
+			}
+			return
+		}
+		eventsChan <- events
+	}
+
+	for _, node := range nodes.Nodes {
+		nodeID := node.Desc.NodeID
+		if err := s.stopper.RunLimitedAsyncTask(
+			ctx, "server.statusServer: requesting remote contention events", sem, true, /* wait */
+			func(ctx context.Context) {
+				getNodeEvents(ctx, nodeID)
+			},
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	for numNodes > 0 {
+		select {
+		case events := <-eventsChan:
+			resp.Events = append(resp.Events, events.Events...)
+		case err := <-errorsChan:
+			resp.Errors = append(resp.Errors, err)
+		case <-ctx.Done():
+			resp.Errors = append(resp.Errors, serverpb.ListContentionEventsError{
+				Message: "ListContentionEvents cancelled before completion",
+			})
+		}
+		numNodes--
+	}
+	return &resp, nil
+}
+
+// NetworkLatencies returns the round-trip latencies measured by every node
+// to every other node, as recorded in their latest status summaries.
+func (s *statusServer) NetworkLatencies(
+	ctx context.Context, req *serverpb.NetworkLatenciesRequest,
+) (*serverpb.NetworkLatenciesResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	nodes, err := s.Nodes(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := serverpb.NetworkLatenciesResponse{
+		Latencies: make([]serverpb.NetworkLatency, 0),
+	}
+	for _, node := range nodes.Nodes {
+		for targetNodeID, nanos := range node.Latencies {
+			resp.Latencies = append(resp.Latencies, serverpb.NetworkLatency{
+				OriginNodeID: node.Desc.NodeID,
+				TargetNodeID: targetNodeID,
+				LatencyNanos: nanos,
+			})
+		}
+	}
+	sort.Slice(resp.Latencies, func(i, j int) bool {
+		a, b := resp.Latencies[i], resp.Latencies[j]
+		if a.OriginNodeID != b.OriginNodeID {
+			return a.OriginNodeID < b.OriginNodeID
+		}
+		return a.TargetNodeID < b.TargetNodeID
+	})
+	return &resp, nil
+}
+
+// CancelQuery cancels a SQL query running on the node given in the request,
+// forwarding the request to that node if needed. It is restricted to root
+// and the nodes.
+func (s *statusServer) CancelQuery(
+	ctx context.Context, req *serverpb.CancelQueryRequest,
+) (*serverpb.CancelQueryResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	if err := requireRootOrNode(ctx, "cancel queries"); err != nil {
+		return nil, err
+	}
+	nodeID, local, err := s.parseNodeID(req.NodeID)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.CancelQuery(ctx, req)
+	}
+
+	output := &serverpb.CancelQueryResponse{}
+	output.Canceled, err = s.sessionRegistry.CancelQuery(req.QueryID)
+	if err != nil {
+		output.Error = err.Error()
+	}
+	return output, nil
+}
+
+// CancelSession closes a SQL session held by the node given in the request,
+// forwarding the request to that node if needed. It is restricted to root
+// and the nodes.
+func (s *statusServer) CancelSession(
+	ctx context.Context, req *serverpb.CancelSessionRequest,
+) (*serverpb.CancelSessionResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	if err := requireRootOrNode(ctx, "cancel sessions"); err != nil {
+		return nil, err
+	}
+	nodeID, local, err := s.parseNodeID(req.NodeID)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.CancelSession(ctx, req)
+	}
+
+	output := &serverpb.CancelSessionResponse{}
+	output.Canceled, err = s.sessionRegistry.CancelSession(req.SessionID)
+	if err != nil {
+		output.Error = err.Error()
+	}
+	return output, nil
+}
+
 // SpanStats requests the total statistics stored on a node for a given key
 // span, which may include multiple ranges.
 func (s *statusServer) SpanStats(
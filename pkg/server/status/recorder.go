@@ -294,11 +294,36 @@ func (mr *MetricsRecorder) getLatencies(ctx context.Context) map[roachpb.NodeID]
 	return latencies
 }
 
+// getClockOffsets produces a map of the clock offsets from this node to all
+// other live nodes. Offsets are stored as nanos.
+func (mr *MetricsRecorder) getClockOffsets(ctx context.Context) map[roachpb.NodeID]int64 {
+	offsets := make(map[roachpb.NodeID]int64)
+	if mr.nodeLiveness != nil && mr.gossip != nil && mr.remoteClockMonitor != nil {
+		isLiveMap := mr.nodeLiveness.GetIsLiveMap()
+		currentOffsets := mr.remoteClockMonitor.AllOffsets()
+		for nodeID, alive := range isLiveMap {
+			if !alive {
+				continue
+			}
+			address, err := mr.gossip.GetNodeIDAddress(nodeID)
+			if err != nil {
+				log.Warning(ctx, err.Error())
+				continue
+			}
+			if offset, ok := currentOffsets[address.String()]; ok {
+				offsets[nodeID] = offset.Offset
+			}
+		}
+	}
+	return offsets
+}
+
 // GetStatusSummary returns a status summary message for the node. The summary
 // includes the recent values of metrics for both the node and all of its
 // component stores.
 func (mr *MetricsRecorder) GetStatusSummary(ctx context.Context) *NodeStatus {
 	latencies := mr.getLatencies(ctx)
+	clockOffsets := mr.getClockOffsets(ctx)
 
 	mr.mu.Lock()
 	defer mr.mu.Unlock()
@@ -324,6 +349,7 @@ func (mr *MetricsRecorder) GetStatusSummary(ctx context.Context) *NodeStatus {
 		Args:          os.Args,
 		Env:           envutil.GetEnvVarsUsed(),
 		Latencies:     latencies,
+		ClockOffsets:  clockOffsets,
 	}
 
 	eachRecordableValue(mr.mu.nodeRegistry, func(name string, val float64) {
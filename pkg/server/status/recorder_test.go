@@ -358,6 +358,7 @@ func TestMetricsRecorder(t *testing.T) {
 	nodeSummary.Args = nil
 	nodeSummary.Env = nil
 	nodeSummary.Latencies = nil
+	nodeSummary.ClockOffsets = nil
 
 	sort.Sort(byStoreDescID(nodeSummary.StoreStatuses))
 	if a, e := nodeSummary, expectedNodeSummary; !reflect.DeepEqual(a, e) {
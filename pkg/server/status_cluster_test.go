@@ -0,0 +1,70 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestNetworkLatencies verifies that every node measures the latency to
+// every other node and that the full matrix is returned by any node.
+func TestNetworkLatencies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const nodeCount = 3
+	tc := testcluster.StartTestCluster(t, nodeCount, base.TestClusterArgs{
+		ServerArgs: base.TestServerArgs{
+			MetricsSampleInterval: 10 * time.Millisecond,
+		},
+	})
+	defer tc.Stopper().Stop(context.TODO())
+
+	testutils.SucceedsSoon(t, func() error {
+		var response serverpb.NetworkLatenciesResponse
+		if err := serverutils.GetJSONProto(
+			tc.Server(0), "/_status/network_latencies", &response,
+		); err != nil {
+			return err
+		}
+		type pair struct{ origin, target roachpb.NodeID }
+		measured := make(map[pair]bool)
+		for _, latency := range response.Latencies {
+			if latency.LatencyNanos > 0 {
+				measured[pair{latency.OriginNodeID, latency.TargetNodeID}] = true
+			}
+		}
+		for i := 0; i < nodeCount; i++ {
+			for j := 0; j < nodeCount; j++ {
+				origin, target := tc.Server(i).NodeID(), tc.Server(j).NodeID()
+				if i != j && !measured[pair{origin, target}] {
+					return errors.Errorf("no latency from n%d to n%d in %+v", origin, target, response)
+				}
+			}
+		}
+		return nil
+	})
+}
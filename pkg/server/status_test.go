@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
@@ -58,6 +59,12 @@ func getStatusJSONProto(
 	return serverutils.GetJSONProto(ts, statusPrefix+path, response)
 }
 
+func postStatusJSONProto(
+	ts serverutils.TestServerInterface, path string, request, response proto.Message,
+) error {
+	return serverutils.PostJSONProto(ts, statusPrefix+path, request, response)
+}
+
 // TestStatusLocalStacks verifies that goroutine stack traces are available
 // via the /_status/stacks/local endpoint.
 func TestStatusLocalStacks(t *testing.T) {
@@ -518,6 +525,78 @@ func TestStatusVars(t *testing.T) {
 	}
 }
 
+// TestStatusVarsStatementLatencies verifies that the latencies of SQL
+// statements are exported as prometheus histograms per statement type.
+func TestStatusVarsStatementLatencies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	for _, stmt := range []string{
+		"CREATE DATABASE t",
+		"CREATE TABLE t.kv (k INT PRIMARY KEY, v INT)",
+		"INSERT INTO t.kv VALUES (1, 1)",
+		"SELECT * FROM t.kv",
+		"UPDATE t.kv SET v = 2",
+		"DELETE FROM t.kv",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, err := getText(s, s.AdminURL()+statusPrefix+"vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"sql_select_latency", "sql_insert_latency", "sql_update_latency",
+		"sql_delete_latency", "sql_ddl_latency",
+	} {
+		if !bytes.Contains(body, []byte("# TYPE "+name+" histogram\n")) {
+			t.Errorf("expected %s histogram, got: %s", name, body)
+		}
+		if !bytes.Contains(body, []byte(name+"_bucket{")) {
+			t.Errorf("expected %s buckets, got: %s", name, body)
+		}
+	}
+}
+
+// TestStatusVarsTableMetrics verifies that the per-table metrics are
+// exported via the /_status/vars endpoint.
+func TestStatusVarsTableMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	for _, stmt := range []string{
+		"CREATE DATABASE t",
+		"CREATE TABLE t.kv (k INT PRIMARY KEY, v INT)",
+		"INSERT INTO t.kv VALUES (1, 1), (2, 2)",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutils.SucceedsSoon(t, func() error {
+		if err := ts.node.computePeriodicMetrics(context.TODO(), 0); err != nil {
+			return err
+		}
+		body, err := getText(s, s.AdminURL()+statusPrefix+"vars")
+		if err != nil {
+			return err
+		}
+		for _, name := range []string{"sql_table_live_bytes", "sql_table_key_count", "sql_table_qps"} {
+			if !bytes.Contains(body, []byte(name+`{database="t",table="kv"}`)) {
+				return errors.Errorf("expected %s for t.kv, got: %s", name, body)
+			}
+		}
+		return nil
+	})
+}
+
 func TestSpanStatsResponse(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	ts := startServer(t)
@@ -668,4 +747,78 @@ func TestCertificatesResponse(t *testing.T) {
 		t.Errorf("mismatched contents: %s vs %s", a, e)
 	}
 
+	for _, cert := range response.Certificates {
+		if !cert.ExpirationTime.After(timeutil.Now()) {
+			t.Errorf("expected %s cert to expire in the future, got %s", cert.Type, cert.ExpirationTime)
+		}
+	}
+}
+
+func TestReloadCertificatesResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := startServer(t)
+	defer ts.Stopper().Stop(context.TODO())
+
+	var response serverpb.ReloadCertificatesResponse
+	if err := postStatusJSONProto(
+		ts, "reload_certificates/local", &serverpb.ReloadCertificatesRequest{}, &response,
+	); err != nil {
+		t.Fatal(err)
+	}
+	// The certificates are not reloaded on GET requests.
+	if err := getStatusJSONProto(ts, "reload_certificates/local", &response); err == nil {
+		t.Error("expected GET requests to fail")
+	}
+
+	// The node keeps serving its certificates after the reload.
+	var certs serverpb.CertificatesResponse
+	if err := getStatusJSONProto(ts, "certificates/local", &certs); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := len(certs.Certificates), 2; a != e {
+		t.Errorf("expected %d certificates, found %d", e, a)
+	}
+}
+
+func TestContentionEventsResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ctx := context.TODO()
+
+	// Write an intent and overwrite it with a high priority request, which
+	// aborts the transaction of the intent.
+	key := roachpb.Key("a")
+	txn := client.NewTxn(kvDB)
+	if err := txn.Put(ctx, key, "a"); err != nil {
+		t.Fatal(err)
+	}
+	b := &client.Batch{}
+	b.Header.UserPriority = roachpb.MaxUserPriority
+	b.Put(key, "b")
+	if err := kvDB.Run(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var response serverpb.ListContentionEventsResponse
+	if err := getStatusJSONProto(s, "contention_events", &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", response.Errors)
+	}
+	for _, event := range response.Events {
+		if !event.Key.Equal(key) || event.PusheeTxnID != *txn.Proto().ID {
+			continue
+		}
+		if event.TxnID != nil || event.PushType != roachpb.PUSH_ABORT.String() ||
+			event.Outcome != storage.ContentionOutcomeAborted || event.NodeID != s.NodeID() {
+			t.Errorf("unexpected contention event %+v", event)
+		}
+		return
+	}
+	t.Errorf("no contention event for key %s in %+v", key, response.Events)
 }
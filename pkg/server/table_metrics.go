@@ -0,0 +1,147 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+var tableMetricsMaxTables = settings.RegisterValidatedIntSetting(
+	"server.table_metrics.max_tables",
+	"maximum number of tables with their own size and throughput metrics on each node; "+
+		"the smaller tables are aggregated under the database and table \"other\" (0 disables per-table metrics)",
+	100,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set server.table_metrics.max_tables to %d: must be non-negative", v)
+		}
+		return nil
+	},
+)
+
+// The per-table metrics only cover the ranges whose lease is held on the
+// node, so that summing them across the nodes gives the totals of the
+// tables.
+var (
+	metaTableLiveBytes = metric.Metadata{
+		Name: "sql.table.live_bytes",
+		Help: "Number of live bytes in the ranges of a table whose lease is held by this node"}
+	metaTableKeyCount = metric.Metadata{
+		Name: "sql.table.key_count",
+		Help: "Number of keys, across all indexes, in the ranges of a table whose lease is held by this node"}
+	metaTableQPS = metric.Metadata{
+		Name: "sql.table.qps",
+		Help: "Number of requests per second received by the ranges of a table whose lease is held by this node"}
+)
+
+// tableMetrics holds the size and throughput metrics of the tables,
+// labeled by database and table name.
+type tableMetrics struct {
+	LiveBytes *metric.GaugeVec
+	KeyCount  *metric.GaugeVec
+	QPS       *metric.GaugeVec
+}
+
+func makeTableMetrics(reg *metric.Registry) tableMetrics {
+	maxTables := func() int { return int(tableMetricsMaxTables.Get()) }
+	tm := tableMetrics{
+		LiveBytes: metric.NewGaugeVec(metaTableLiveBytes, maxTables, "database", "table"),
+		KeyCount:  metric.NewGaugeVec(metaTableKeyCount, maxTables, "database", "table"),
+		QPS:       metric.NewGaugeVec(metaTableQPS, maxTables, "database", "table"),
+	}
+	reg.AddMetricStruct(tm)
+	return tm
+}
+
+// namedTableStats are the statistics of a table along with its names.
+type namedTableStats struct {
+	database, table string
+	*storage.TableStats
+}
+
+// update replaces the values of the metrics with the given statistics,
+// using the descriptors of cfg to name the tables. The largest tables
+// are the ones which keep their own metrics when there are more tables
+// than allowed by server.table_metrics.max_tables.
+func (tm tableMetrics) update(cfg config.SystemConfig, stats map[uint32]*storage.TableStats) {
+	var tables []namedTableStats
+	if tableMetricsMaxTables.Get() > 0 {
+		tables = make([]namedTableStats, 0, len(stats))
+		for id, s := range stats {
+			database, table := tableNames(cfg, id)
+			tables = append(tables, namedTableStats{database: database, table: table, TableStats: s})
+		}
+		sort.Slice(tables, func(i, j int) bool {
+			return tables[i].LiveBytes > tables[j].LiveBytes
+		})
+	}
+
+	tm.LiveBytes.Update(func(add func(float64, ...string)) {
+		for _, t := range tables {
+			add(float64(t.LiveBytes), t.database, t.table)
+		}
+	})
+	tm.KeyCount.Update(func(add func(float64, ...string)) {
+		for _, t := range tables {
+			add(float64(t.KeyCount), t.database, t.table)
+		}
+	})
+	tm.QPS.Update(func(add func(float64, ...string)) {
+		for _, t := range tables {
+			add(t.QPS, t.database, t.table)
+		}
+	})
+}
+
+// tableNames returns the names of the table with the given ID and of its
+// database. The IDs are used instead of the names of the descriptors which
+// cannot be found, e.g. those of dropped tables whose data has not been
+// deleted yet.
+func tableNames(cfg config.SystemConfig, id uint32) (database, table string) {
+	database, table = "", strconv.FormatUint(uint64(id), 10)
+	tableDesc := getDescriptor(cfg, sqlbase.ID(id)).GetTable()
+	if tableDesc == nil {
+		return database, table
+	}
+	table = tableDesc.Name
+	database = strconv.FormatUint(uint64(tableDesc.ParentID), 10)
+	if dbDesc := getDescriptor(cfg, tableDesc.ParentID).GetDatabase(); dbDesc != nil {
+		database = dbDesc.Name
+	}
+	return database, table
+}
+
+// getDescriptor returns the descriptor with the given ID from the system
+// config, or nil if it cannot be found.
+func getDescriptor(cfg config.SystemConfig, id sqlbase.ID) *sqlbase.Descriptor {
+	val := cfg.GetValue(sqlbase.MakeDescMetadataKey(id))
+	if val == nil {
+		return nil
+	}
+	var desc sqlbase.Descriptor
+	if err := val.GetProto(&desc); err != nil {
+		return nil
+	}
+	return &desc
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package telemetry counts the use of features, e.g. the execution of a
+// given kind of statement, on this node. The counts are included in the
+// diagnostics report when diagnostics reporting is enabled, and are
+// served locally on /_status/feature-usage.
+package telemetry
+
+import (
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// counters maps the name of each feature used since the start of the
+// process to the number of times it was used.
+var counters struct {
+	syncutil.RWMutex
+	m map[string]*int32
+}
+
+// Inc increments the usage count of the named feature. Feature names are
+// dot-separated, starting with the subsystem the feature belongs to, e.g.
+// "sql.schema.create_view", and must not include user data.
+func Inc(feature string) {
+	counters.RLock()
+	c, ok := counters.m[feature]
+	counters.RUnlock()
+	if !ok {
+		counters.Lock()
+		if c, ok = counters.m[feature]; !ok {
+			if counters.m == nil {
+				counters.m = make(map[string]*int32)
+			}
+			c = new(int32)
+			counters.m[feature] = c
+		}
+		counters.Unlock()
+	}
+	atomic.AddInt32(c, 1)
+}
+
+// GetFeatureCounts returns the usage count of each feature used since the
+// start of the process.
+func GetFeatureCounts() map[string]int32 {
+	counters.RLock()
+	defer counters.RUnlock()
+	res := make(map[string]int32, len(counters.m))
+	for feature, c := range counters.m {
+		res[feature] = atomic.LoadInt32(c)
+	}
+	return res
+}
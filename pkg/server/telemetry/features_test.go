@@ -0,0 +1,44 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package telemetry
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInc(t *testing.T) {
+	const feature = "test.telemetry.inc"
+	before := GetFeatureCounts()[feature]
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Inc(feature)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if expected, actual := before+1000, GetFeatureCounts()[feature]; expected != actual {
+		t.Fatalf("expected %d uses of %s, got %d", expected, feature, actual)
+	}
+	if _, ok := GetFeatureCounts()["test.telemetry.unused"]; ok {
+		t.Fatal("unexpected count for an unused feature")
+	}
+}
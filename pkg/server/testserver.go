@@ -133,6 +133,7 @@ func makeTestConfigFromParams(params base.TestServerArgs) Config {
 	if params.PendingRPCTimeout != 0 {
 		cfg.PendingRPCTimeout = params.PendingRPCTimeout
 	}
+	cfg.manualClock = params.Clock
 	cfg.JoinList = []string{params.JoinAddr}
 	if cfg.Insecure {
 		// Whenever we can (i.e. in insecure mode), use IsolatedTestAddr
@@ -226,6 +227,57 @@ func (ts *TestServer) Clock() *hlc.Clock {
 	return nil
 }
 
+// ManualClock returns the manual clock driving the TestServer's HLC, or nil
+// if the server uses the wall clock.
+func (ts *TestServer) ManualClock() *hlc.ManualClock {
+	if ts != nil {
+		return ts.Cfg.manualClock
+	}
+	return nil
+}
+
+// AdvanceClock moves the manual clock of the TestServer forward by the given
+// duration. All the stores of the server share its clock, and so do the
+// servers of a TestCluster started with a manual clock.
+func (ts *TestServer) AdvanceClock(d time.Duration) error {
+	manual := ts.ManualClock()
+	if manual == nil {
+		return errors.New("the server doesn't use a manual clock")
+	}
+	manual.Increment(d.Nanoseconds())
+	return nil
+}
+
+// ExpireLeases moves the manual clock of the TestServer past the expiration
+// of all the range leases its stores could have granted and of the liveness
+// records of all the nodes it knows about. Afterwards, expiration-based leases
+// have to be reacquired and epoch-based leases can be taken over once the
+// epoch of their holder is incremented.
+func (ts *TestServer) ExpireLeases() error {
+	manual := ts.ManualClock()
+	if manual == nil {
+		return errors.New("the server doesn't use a manual clock")
+	}
+	// Range leases and liveness records are both extended by the liveness
+	// active duration from at most the current HLC time.
+	active, _ := storage.NodeLivenessDurations(
+		storage.RaftElectionTimeout(ts.Cfg.RaftTickInterval, ts.Cfg.RaftElectionTimeoutTicks))
+	maxOffset := ts.clock.MaxOffset().Nanoseconds()
+	target := ts.clock.Now().WallTime + active.Nanoseconds()
+	for _, l := range ts.nodeLiveness.GetLivenesses() {
+		if l.Expiration.WallTime > target {
+			target = l.Expiration.WallTime
+		}
+	}
+	// Leases are only considered expired by all nodes once the clock is past
+	// their expiration by more than the maximum clock offset.
+	target += maxOffset + 1
+	if manual.UnixNano() < target {
+		manual.Set(target)
+	}
+	return nil
+}
+
 // RPCContext returns the rpc context used by the TestServer.
 func (ts *TestServer) RPCContext() *rpc.Context {
 	if ts != nil {
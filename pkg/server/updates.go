@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -69,10 +70,14 @@ const (
 	updateMaxVersionsToReport = 3
 
 	updateCheckJitterSeconds = 120
+
+	// spooledCrashReportRetryFrequency is the interval between the attempts
+	// to upload the crash reports spooled by previous runs.
+	spooledCrashReportRetryFrequency = time.Minute * 10
 )
 
 var (
-	diagnosticReportFrequency = settings.RegisterDurationSetting(
+	diagnosticReportFrequency = settings.RegisterPositiveDurationSetting(
 		"diagnostics.reporting.interval",
 		"interval at which diagnostics data should be reported",
 		time.Hour,
@@ -104,6 +109,9 @@ type reportingInfo struct {
 	QueryStats map[string]map[string]roachpb.StatementStatistics `json:"sqlstats"`
 	// UnimplementedErrors reports when unimplemented features are encountered.
 	UnimplementedErrors map[string]uint `json:"unimplemented"`
+	// FeatureUsage reports how often features were used since the node
+	// started; see the telemetry package.
+	FeatureUsage map[string]int32 `json:"feature_usage"`
 }
 
 type nodeInfo struct {
@@ -154,6 +162,37 @@ func (s *Server) PeriodicallyCheckForUpdates() {
 	})
 }
 
+// PeriodicallyUploadSpooledCrashReports starts a background worker that
+// uploads the crash reports which previous runs could not send, for
+// instance because the network was down when the process crashed. It
+// retries until the spool is empty.
+func (s *Server) PeriodicallyUploadSpooledCrashReports() {
+	ctx := s.AnnotateCtx(context.Background())
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		// Abandon the uploads in progress when the server shuts down.
+		ctx = s.stopper.WithCancel(ctx)
+
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			remaining, err := log.UploadSpooledCrashReports(ctx)
+			if err != nil {
+				log.Warningf(ctx, "unable to upload spooled crash reports: %s", err)
+			}
+			if remaining == 0 {
+				return
+			}
+			timer.Reset(addJitter(spooledCrashReportRetryFrequency, updateCheckJitterSeconds))
+			select {
+			case <-s.stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+		}
+	})
+}
+
 // maybeCheckForUpdates determines if it is time to check for updates and does
 // so if it is, before returning the time at which the next check be done.
 func (s *Server) maybeCheckForUpdates(
@@ -292,6 +331,7 @@ func (s *Server) getReportingInfo(ctx context.Context) reportingInfo {
 	info.QueryStats = s.sqlExecutor.GetScrubbedStmtStats()
 	info.UnimplementedErrors = make(map[string]uint)
 	s.sqlExecutor.FillUnimplementedErrorCounts(info.UnimplementedErrors)
+	info.FeatureUsage = telemetry.GetFeatureCounts()
 	return info
 }
 
@@ -324,6 +364,53 @@ func (s *Server) reportDiagnostics(runningTime time.Duration) {
 	}
 }
 
+// DiagnosticsPreviewEndpoint serves the reports this node sends to
+// Cockroach Labs, built as they would be sent, without sending them. It
+// backs `cockroach debug diagnostics`.
+const DiagnosticsPreviewEndpoint = "/debug/diagnostics"
+
+// diagnosticsPreview is the JSON document served by
+// DiagnosticsPreviewEndpoint.
+type diagnosticsPreview struct {
+	// DiagnosticsReportingEnabled is true if the diagnostics report is sent
+	// every diagnostics.reporting.interval.
+	DiagnosticsReportingEnabled bool `json:"diagnostics_reporting_enabled"`
+	// ReportingURL is the URL the diagnostics report is posted to, with the
+	// parameters describing the node.
+	ReportingURL string        `json:"reporting_url"`
+	Report       reportingInfo `json:"report"`
+	// CrashReportingEnabled is true if crash reports are sent.
+	CrashReportingEnabled bool `json:"crash_reporting_enabled"`
+	// CrashReport is the report a panic would send, less the stack trace
+	// specific to the panic.
+	CrashReport json.RawMessage `json:"crash_report"`
+}
+
+// handleDiagnosticsPreview serves DiagnosticsPreviewEndpoint.
+func (s *Server) handleDiagnosticsPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := s.AnnotateCtx(r.Context())
+	crashReport, err := log.PreviewCrashReport(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	u := *reportingURL
+	addInfoToURL(&u, s, timeutil.Since(s.startTime))
+	preview := diagnosticsPreview{
+		DiagnosticsReportingEnabled: log.DiagnosticsReportingEnabled.Get() && diagnosticsMetricsEnabled.Get(),
+		ReportingURL:                u.String(),
+		Report:                      s.getReportingInfo(ctx),
+		CrashReportingEnabled:       log.CrashReportingEnabled(),
+		CrashReport:                 crashReport,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(preview); err != nil {
+		log.Warningf(ctx, "unable to serve the diagnostics preview: %s", err)
+	}
+}
+
 func (s *Server) collectSchemaInfo(ctx context.Context) ([]sqlbase.TableDescriptor, error) {
 	startKey := roachpb.Key(keys.MakeTablePrefix(keys.DescriptorTableID))
 	endKey := startKey.PrefixEnd()
@@ -348,11 +435,13 @@ func (s *Server) collectSchemaInfo(ctx context.Context) ([]sqlbase.TableDescript
 	return tables, nil
 }
 
+// stringRedactor anonymizes the strings of the values it walks, according
+// to the anonymization strategy of the reports.
 type stringRedactor struct{}
 
 func (stringRedactor) Primitive(v reflect.Value) error {
 	if v.Kind() == reflect.String && v.String() != "" {
-		v.Set(reflect.ValueOf("_"))
+		v.Set(reflect.ValueOf(log.AnonymizeForReport(v.String(), "_")))
 	}
 	return nil
 }
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -162,6 +163,11 @@ func TestReportUsage(t *testing.T) {
 		}
 	}
 
+	createTableUses := telemetry.GetFeatureCounts()["sql.schema.create_table"]
+	if createTableUses < 1 {
+		t.Fatalf("expected at least 1 use of CREATE TABLE, got %d", createTableUses)
+	}
+
 	expectedUsageReports := 0
 
 	testutils.SucceedsSoon(t, func() error {
@@ -253,6 +259,10 @@ func TestReportUsage(t *testing.T) {
 		)
 	}
 
+	if minExpected, actual := createTableUses, r.last.FeatureUsage["sql.schema.create_table"]; minExpected > actual {
+		t.Fatalf("expected at least %d uses of CREATE TABLE in the report, got %d", minExpected, actual)
+	}
+
 	if expected, actual := 2, len(r.last.QueryStats); expected != actual {
 		t.Fatalf("expected %d apps in stats report, got %d", expected, actual)
 	}
@@ -337,3 +347,56 @@ func makeMockRecorder(t *testing.T) *mockRecorder {
 
 	return rec
 }
+
+func TestDiagnosticsPreview(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := makeMockRecorder(t)
+	defer stubURL(&reportingURL, r.url)()
+	defer r.Close()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+
+	client, err := s.GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(s.AdminURL() + DiagnosticsPreviewEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %s", resp.Status)
+	}
+	var preview struct {
+		ReportingURL string          `json:"reporting_url"`
+		Report       reportingInfo   `json:"report"`
+		CrashReport  json.RawMessage `json:"crash_report"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&preview); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, actual := ts.NodeID(), preview.Report.Node.NodeID; expected != actual {
+		t.Errorf("expected node id %v, got %v", expected, actual)
+	}
+	if !strings.Contains(preview.ReportingURL, ts.node.ClusterID.String()) {
+		t.Errorf("expected the cluster id in the reporting url, got %s", preview.ReportingURL)
+	}
+	if len(preview.CrashReport) == 0 {
+		t.Error("expected a crash report")
+	}
+
+	// Nothing was sent, and the reporting URL was left alone.
+	r.Lock()
+	defer r.Unlock()
+	if r.requests != 0 {
+		t.Errorf("expected no reports, got %d", r.requests)
+	}
+	if reportingURL.RawQuery != "" {
+		t.Errorf("expected the reporting url to be unchanged, got %s", reportingURL)
+	}
+}
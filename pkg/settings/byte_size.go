@@ -43,19 +43,40 @@ func RegisterByteSizeSetting(key, desc string, defaultValue int64) *ByteSizeSett
 	return RegisterValidatedByteSizeSetting(key, desc, defaultValue, nil)
 }
 
+// RegisterPositiveByteSizeSetting defines a new setting with type bytesize
+// which cannot be set to zero, e.g. a rate limit.
+func RegisterPositiveByteSizeSetting(key, desc string, defaultValue int64) *ByteSizeSetting {
+	return RegisterValidatedByteSizeSetting(key, desc, defaultValue, func(v int64) error {
+		if v == 0 {
+			return errors.Errorf("cannot set %s to zero bytes", key)
+		}
+		return nil
+	})
+}
+
 // RegisterValidatedByteSizeSetting defines a new setting with type bytesize
-// with a validation function.
+// with a validation function. Negative sizes are rejected whether or not there
+// is a validation function.
 func RegisterValidatedByteSizeSetting(
 	key, desc string, defaultValue int64, validateFn func(int64) error,
 ) *ByteSizeSetting {
-	if validateFn != nil {
-		if err := validateFn(defaultValue); err != nil {
-			panic(errors.Wrap(err, "invalid default"))
+	nonNegativeFn := func(v int64) error {
+		if validateFn != nil {
+			if err := validateFn(v); err != nil {
+				return err
+			}
 		}
+		if v < 0 {
+			return errors.Errorf("cannot set %s to a negative byte size: %s", key, humanizeutil.IBytes(v))
+		}
+		return nil
+	}
+	if err := nonNegativeFn(defaultValue); err != nil {
+		panic(errors.Wrap(err, "invalid default"))
 	}
 	setting := &ByteSizeSetting{IntSetting{
 		defaultValue: defaultValue,
-		validateFn:   validateFn,
+		validateFn:   nonNegativeFn,
 	}}
 	register(key, desc, setting)
 	return setting
@@ -46,6 +46,12 @@ then use a migration to write an explicit `true`: in practice you'd still expect
 to read `true` unless a preference is expressed, but in the rare cases where you
 read a default, you don't risk ignoring an expressed opt-out.
 
+A setting can also be overridden on a single node, e.g. to size a cache to the
+hardware of that node, with SetLocalOverride (exposed as the
+--settings-override flag of `cockroach start`). A local override takes
+precedence over the cluster-wide value, which in turn takes precedence over the
+default value.
+
 Ideally, when passing configuration into some structure or subsystem, e.g.
 a rate limit into a client or something, passing a `*FooSetting rather than a
 `Foo` and waiting to call `.Get()` until the value is actually used ensures
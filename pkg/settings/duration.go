@@ -90,6 +90,19 @@ func RegisterNonNegativeDurationSetting(
 	})
 }
 
+// RegisterPositiveDurationSetting defines a new setting with type duration
+// which must be greater than zero, e.g. the interval of a periodic task.
+func RegisterPositiveDurationSetting(
+	key, desc string, defaultValue time.Duration,
+) *DurationSetting {
+	return RegisterValidatedDurationSetting(key, desc, defaultValue, func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set %s to a non-positive duration: %s", key, v)
+		}
+		return nil
+	})
+}
+
 // RegisterValidatedDurationSetting defines a new setting with type duration.
 func RegisterValidatedDurationSetting(
 	key, desc string, defaultValue time.Duration, validateFn func(time.Duration) error,
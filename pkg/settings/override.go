@@ -0,0 +1,152 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// localOverrides contains the values, as specified by the operator, of the
+// settings overridden on this node. An overridden setting keeps its local
+// value regardless of the cluster-wide value applied by an Updater.
+var localOverrides struct {
+	syncutil.Mutex
+	values map[string]string
+}
+
+// SetLocalOverride sets the setting key to value on this node, where it
+// takes precedence over the cluster-wide value of the setting. The value
+// is parsed in the format used by SET CLUSTER SETTING, e.g. "64MiB" for
+// byte sizes or "1m30s" for durations, and enum values may be given by
+// name.
+func SetLocalOverride(key, value string) error {
+	s, ok := registry[key]
+	if !ok {
+		return errors.Errorf("unknown setting '%s'", key)
+	}
+	if err := parseOverride(s, value); err != nil {
+		return errors.Wrapf(err, "invalid override of setting '%s'", key)
+	}
+	localOverrides.Lock()
+	defer localOverrides.Unlock()
+	if localOverrides.values == nil {
+		localOverrides.values = make(map[string]string)
+	}
+	localOverrides.values[key] = value
+	return nil
+}
+
+// SetLocalOverrides calls SetLocalOverride for each of the comma-separated
+// key=value pairs of spec.
+func SetLocalOverrides(spec string) error {
+	for _, kv := range strings.Split(spec, ",") {
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("setting override %q is not of the form key=value", kv)
+		}
+		if err := SetLocalOverride(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsLocallyOverridden returns true if the setting key is overridden on
+// this node.
+func IsLocallyOverridden(key string) bool {
+	localOverrides.Lock()
+	defer localOverrides.Unlock()
+	_, ok := localOverrides.values[key]
+	return ok
+}
+
+// LocalOverrides returns the overridden settings as a sorted list of
+// key=value pairs.
+func LocalOverrides() []string {
+	localOverrides.Lock()
+	defer localOverrides.Unlock()
+	res := make([]string, 0, len(localOverrides.values))
+	for k, v := range localOverrides.values {
+		res = append(res, k+"="+v)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// TestingClearLocalOverrides removes all the local overrides, leaving the
+// settings with the values they were overridden with until the next
+// update.
+func TestingClearLocalOverrides() {
+	localOverrides.Lock()
+	defer localOverrides.Unlock()
+	localOverrides.values = nil
+}
+
+// parseOverride parses value according to the type of s and sets s to it.
+func parseOverride(s Setting, value string) error {
+	switch setting := s.(type) {
+	case *StringSetting:
+		return setting.set(value)
+	case *BoolSetting:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		setting.set(b)
+		return nil
+	case *ByteSizeSetting:
+		i, err := humanizeutil.ParseBytes(value)
+		if err != nil {
+			return err
+		}
+		return setting.set(i)
+	case *EnumSetting:
+		i, ok := setting.ParseEnum(value)
+		if !ok {
+			return errors.Errorf("unrecognized value %q", value)
+		}
+		return setting.set(i)
+	case *IntSetting:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		return setting.set(i)
+	case *FloatSetting:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		return setting.set(f)
+	case *DurationSetting:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		return setting.set(d)
+	}
+	return errors.Errorf("cannot override settings of type %s", s.Typ())
+}
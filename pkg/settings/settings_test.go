@@ -15,6 +15,7 @@
 package settings_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 	"unicode"
@@ -70,6 +71,13 @@ var byteSizeVal = settings.RegisterValidatedByteSizeSetting(
 		}
 		return nil
 	})
+var overrideZ = settings.RegisterByteSizeSetting("override.z", "", mb)
+var overrideE = settings.RegisterEnumSetting("override.e", "", "foo", map[int64]string{1: "foo", 2: "bar"})
+var overrideD = settings.RegisterDurationSetting("override.d", "", time.Second)
+
+var zPos = settings.RegisterPositiveByteSizeSetting("zPos", "", mb)
+var dPos = settings.RegisterPositiveDurationSetting("dPos", "", time.Second)
+
 var iVal = settings.RegisterValidatedIntSetting(
 	"i.Val", "", 0, func(v int64) error {
 		if v < 0 {
@@ -532,3 +540,96 @@ func TestHide(t *testing.T) {
 		t.Errorf("expected 'sekretz' to be hidden")
 	}
 }
+
+func TestLocalOverride(t *testing.T) {
+	defer settings.MakeUpdater().Done()
+	defer settings.TestingClearLocalOverrides()
+
+	if err := settings.SetLocalOverrides("override.z=64MiB, override.e=bar"); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := 64*mb, overrideZ.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if expected, actual := int64(2), overrideE.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if expected, actual := []string{"override.e=bar", "override.z=64MiB"}, settings.LocalOverrides(); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	// The cluster-wide values of the overridden settings are ignored, and
+	// those of the other settings applied.
+	u := settings.MakeUpdater()
+	if err := u.Set("override.z", settings.EncodeInt(mb), "z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Set("override.d", settings.EncodeDuration(time.Minute), "d"); err != nil {
+		t.Fatal(err)
+	}
+	u.Done()
+	if expected, actual := 64*mb, overrideZ.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if expected, actual := int64(2), overrideE.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if expected, actual := time.Minute, overrideD.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if !settings.IsLocallyOverridden("override.z") || settings.IsLocallyOverridden("override.d") {
+		t.Fatalf("unexpected overrides: %v", settings.LocalOverrides())
+	}
+
+	for _, tc := range []struct {
+		spec, err string
+	}{
+		{"override.z", `setting override "override.z" is not of the form key=value`},
+		{"dne=1", `unknown setting 'dne'`},
+		{"override.e=qux", `invalid override of setting 'override.e': unrecognized value "qux"`},
+		{"override.d=-", `invalid override of setting 'override.d': time: invalid duration -`},
+	} {
+		if err := settings.SetLocalOverrides(tc.spec); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected %q, got %v", tc.spec, tc.err, err)
+		}
+	}
+}
+
+func TestValidatedSizesAndDurations(t *testing.T) {
+	defer settings.MakeUpdater().Done()
+
+	for _, tc := range []struct {
+		key, val, typ, err string
+	}{
+		{"zzz", settings.EncodeInt(-mb), "z", `cannot set zzz to a negative byte size: -1.0 MiB`},
+		{"zPos", settings.EncodeInt(0), "z", `cannot set zPos to zero bytes`},
+		{"zPos", settings.EncodeInt(-1), "z", `cannot set zPos to a negative byte size: -1 B`},
+		{"dPos", settings.EncodeDuration(0), "d", `cannot set dPos to a non-positive duration: 0s`},
+		{"dPos", settings.EncodeDuration(-time.Minute), "d", `cannot set dPos to a non-positive duration: -1m0s`},
+	} {
+		u := settings.MakeUpdater()
+		if err := u.Set(tc.key, tc.val, tc.typ); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s=%s: expected %q, got %v", tc.key, tc.val, tc.err, err)
+		}
+	}
+	if expected, actual := mb, zPos.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if expected, actual := time.Second, dPos.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	u := settings.MakeUpdater()
+	if err := u.Set("zPos", settings.EncodeInt(2*mb), "z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Set("dPos", settings.EncodeDuration(time.Minute), "d"); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := 2*mb, zPos.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if expected, actual := time.Minute, dPos.Get(); expected != actual {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
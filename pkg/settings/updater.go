@@ -66,6 +66,10 @@ func (u Updater) Set(key, rawValue, vt string) error {
 	if expected := d.Typ(); vt != expected {
 		return errors.Errorf("setting '%s' defined as type %s, not %s", key, expected, vt)
 	}
+	if IsLocallyOverridden(key) {
+		// The local value takes precedence over the cluster-wide one.
+		return nil
+	}
 
 	switch setting := d.(type) {
 	case *StringSetting:
@@ -105,10 +109,11 @@ func (u Updater) Set(key, rawValue, vt string) error {
 	return nil
 }
 
-// Done sets all settings not updated by the updater to their default values.
+// Done sets all settings not updated by the updater and not overridden
+// locally to their default values.
 func (u Updater) Done() {
 	for k, v := range registry {
-		if _, ok := u[k]; !ok {
+		if _, ok := u[k]; !ok && !IsLocallyOverridden(k) {
 			v.setToDefault()
 		}
 	}
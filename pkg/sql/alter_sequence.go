@@ -0,0 +1,96 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type alterSequenceNode struct {
+	p       *planner
+	n       *parser.AlterSequence
+	seqDesc *sqlbase.TableDescriptor
+}
+
+// AlterSequence changes the options of a sequence.
+// Privileges: CREATE on sequence.
+//   notes: postgres requires the sequence owner.
+func (p *planner) AlterSequence(ctx context.Context, n *parser.AlterSequence) (planNode, error) {
+	tn, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	seqDesc, err := getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if seqDesc == nil {
+		if n.IfExists {
+			return &emptyNode{}, nil
+		}
+		return nil, sqlbase.NewUndefinedSequenceError(tn.String())
+	}
+
+	if err := p.CheckPrivilege(seqDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	return &alterSequenceNode{n: n, p: p, seqDesc: seqDesc}, nil
+}
+
+func (n *alterSequenceNode) Start(ctx context.Context) error {
+	desc := n.seqDesc
+	if err := assignSequenceOptions(desc.SequenceOpts, n.n.Options, false /* setDefaults */); err != nil {
+		return err
+	}
+
+	// Bumping the version of the descriptor also invalidates the ranges of
+	// values of the sequence cached by the nodes.
+	if err := n.p.saveNonmutationAndNotify(ctx, desc); err != nil {
+		return err
+	}
+
+	// Log Alter Sequence event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	return MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		n.p.txn,
+		EventLogAlterSequence,
+		int32(desc.ID),
+		int32(n.p.evalCtx.NodeID),
+		struct {
+			SequenceName string
+			Statement    string
+			User         string
+		}{n.n.Name.String(), n.n.String(), n.p.session.User},
+	)
+}
+
+func (*alterSequenceNode) Next(context.Context) (bool, error) { return false, nil }
+func (*alterSequenceNode) Close(context.Context)              {}
+func (*alterSequenceNode) Columns() sqlbase.ResultColumns     { return make(sqlbase.ResultColumns, 0) }
+func (*alterSequenceNode) Ordering() orderingInfo             { return orderingInfo{} }
+func (*alterSequenceNode) Values() parser.Datums              { return parser.Datums{} }
+func (*alterSequenceNode) DebugValues() debugValues           { return debugValues{} }
+func (*alterSequenceNode) MarkDebug(mode explainMode)         {}
+
+func (*alterSequenceNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
@@ -87,6 +87,13 @@ func (n *alterTableNode) Start(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
+			if col.IsComputed() {
+				if err := sqlbase.ValidateComputedColumn(
+					col, n.tableDesc, n.p.session.SearchPath,
+				); err != nil {
+					return err
+				}
+			}
 			_, dropped, err := n.tableDesc.FindColumnByName(d.Name)
 			if err == nil {
 				if dropped {
@@ -215,6 +222,14 @@ func (n *alterTableNode) Start(ctx context.Context) error {
 			if n.tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
 				return fmt.Errorf("column %q is referenced by the primary key", col.Name)
 			}
+			if err := checkColumnNotReferencedByComputedColumns(
+				n.tableDesc, col, n.p.session.SearchPath,
+			); err != nil {
+				return err
+			}
+			if ttl := n.tableDesc.RowLevelTTL; ttl != nil && ttl.ColumnID == col.ID {
+				return fmt.Errorf("column %q is referenced by the row-level TTL of the table", col.Name)
+			}
 			for _, idx := range n.tableDesc.AllNonDropIndexes() {
 				// We automatically drop indexes on that column that only
 				// index that column (and no other columns). If CASCADE is
@@ -396,6 +411,18 @@ func (n *alterTableNode) Start(ctx context.Context) error {
 				return errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, t.Constraint)
 			}
 
+		case *parser.AlterTableSetStorageParams:
+			if err := applyStorageParams(n.tableDesc, t.Params); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
+		case *parser.AlterTableResetStorageParams:
+			if err := resetStorageParams(n.tableDesc, t.Params); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
 		case parser.ColumnMutationCmd:
 			// Column mutations
 			col, dropped, err := n.tableDesc.FindColumnByName(t.GetColumn())
@@ -487,6 +514,9 @@ func applyColumnMutation(
 ) error {
 	switch t := mut.(type) {
 	case *parser.AlterTableSetDefault:
+		if t.Default != nil && col.IsComputed() {
+			return fmt.Errorf("computed column %q cannot also have a DEFAULT expression", col.Name)
+		}
 		if t.Default == nil {
 			col.DefaultExpr = nil
 		} else {
@@ -506,6 +536,40 @@ func applyColumnMutation(
 	return nil
 }
 
+// checkColumnNotReferencedByComputedColumns returns an error if the given
+// column is used in the expression of a computed column of the table.
+func checkColumnNotReferencedByComputedColumns(
+	tableDesc *sqlbase.TableDescriptor, col sqlbase.ColumnDescriptor, searchPath parser.SearchPath,
+) error {
+	for i := range tableDesc.Columns {
+		computedCol := &tableDesc.Columns[i]
+		if !computedCol.IsComputed() || computedCol.ID == col.ID {
+			continue
+		}
+		expr, err := sqlbase.MakeComputedExpr(
+			computedCol, tableDesc, &sqlbase.RowIndexedVarContainer{Cols: tableDesc.Columns}, searchPath,
+		)
+		if err != nil {
+			return err
+		}
+		referenced := false
+		if _, err := parser.SimpleVisit(expr, func(
+			expr parser.Expr,
+		) (err error, recurse bool, newExpr parser.Expr) {
+			if ivar, ok := expr.(*parser.IndexedVar); ok && tableDesc.Columns[ivar.Idx].ID == col.ID {
+				referenced = true
+			}
+			return nil, !referenced, expr
+		}); err != nil {
+			return err
+		}
+		if referenced {
+			return fmt.Errorf("column %q is referenced by computed column %q", col.Name, computedCol.Name)
+		}
+	}
+	return nil
+}
+
 func labeledRowValues(cols []sqlbase.ColumnDescriptor, values parser.Datums) string {
 	var s bytes.Buffer
 	for i := range cols {
@@ -164,6 +164,7 @@ func (s *Session) resetApplicationName(appName string) {
 	s.mu.Lock()
 	s.mu.ApplicationName = appName
 	s.mu.Unlock()
+	s.verbosityScope.SetName(appName)
 	if s.sqlStats != nil {
 		s.appStats = s.sqlStats.getStatsForApplication(appName)
 	}
@@ -316,6 +317,15 @@ func (e *Executor) ResetStatementStats(ctx context.Context) {
 	e.sqlStats.resetStats(ctx)
 }
 
+// ResetStatementStatistics implements the parser.EvalPlanner interface.
+func (p *planner) ResetStatementStatistics(ctx context.Context) error {
+	if p.session.sqlStats == nil {
+		return errors.New("cannot access sql statistics from this context")
+	}
+	p.session.sqlStats.resetStats(ctx)
+	return nil
+}
+
 // FillUnimplementedErrorCounts fills the passed map with the executor's current
 // counts of how often individual unimplemented features have been encountered.
 func (e *Executor) FillUnimplementedErrorCounts(fill map[string]uint) {
@@ -0,0 +1,90 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// The kinds of the operations recorded in the audit log.
+const (
+	auditKindDDL            = "ddl"
+	auditKindPrivileges     = "privileges"
+	auditKindUser           = "user"
+	auditKindClusterSetting = "cluster_setting"
+	auditKindLogin          = "login"
+)
+
+// auditKind returns the kind of the operation requested by stmt. The
+// boolean return value is false if the statement is not audited.
+func auditKind(stmt parser.Statement) (string, bool) {
+	switch t := stmt.(type) {
+	case *parser.Grant, *parser.Revoke:
+		return auditKindPrivileges, true
+	case *parser.CreateUser:
+		return auditKindUser, true
+	case *parser.Set:
+		if t.SetMode == parser.SetModeClusterSetting {
+			return auditKindClusterSetting, true
+		}
+		return "", false
+	}
+	if stmt.StatementType() == parser.DDL {
+		return auditKindDDL, true
+	}
+	return "", false
+}
+
+// auditStatement records the execution of stmt in the audit log if it
+// is audited. err is the error with which the statement failed, if it
+// did. Statements executed within a transaction are recorded even if
+// the transaction is later rolled back.
+func (s *Session) auditStatement(stmt Statement, err error) {
+	if !log.AuditLogEnabled() {
+		return
+	}
+	kind, ok := auditKind(stmt.AST)
+	if !ok {
+		return
+	}
+	ev := log.AuditEvent{
+		Kind:       kind,
+		User:       s.User,
+		ClientAddr: s.ClientAddr,
+		// Passwords are elided from the text of statements.
+		Statement: stmt.AST.String(),
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	log.Audit(s.Ctx(), ev)
+}
+
+// AuditLogin records the attempt of user to log in from clientAddr in
+// the audit log. err is the reason why authentication failed, if it did.
+func AuditLogin(ctx context.Context, user, clientAddr string, err error) {
+	ev := log.AuditEvent{
+		Kind:       auditKindLogin,
+		User:       user,
+		ClientAddr: clientAddr,
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	log.Audit(ctx, ev)
+}
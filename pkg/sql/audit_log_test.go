@@ -0,0 +1,50 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+func TestAuditKind(t *testing.T) {
+	testCases := []struct {
+		stmt string
+		kind string
+	}{
+		{`CREATE DATABASE d`, auditKindDDL},
+		{`CREATE TABLE t (a INT)`, auditKindDDL},
+		{`ALTER TABLE t ADD COLUMN b INT`, auditKindDDL},
+		{`DROP INDEX t@i`, auditKindDDL},
+		{`GRANT SELECT ON TABLE t TO u`, auditKindPrivileges},
+		{`REVOKE ALL ON DATABASE d FROM u`, auditKindPrivileges},
+		{`CREATE USER u WITH PASSWORD 'secret'`, auditKindUser},
+		{`SET CLUSTER SETTING diagnostics.reporting.enabled = false`, auditKindClusterSetting},
+		{`SET application_name = 'app'`, ""},
+		{`SELECT 1`, ""},
+		{`INSERT INTO t VALUES (1)`, ""},
+	}
+	for _, tc := range testCases {
+		stmt, err := parser.ParseOne(tc.stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kind, ok := auditKind(stmt)
+		if ok != (tc.kind != "") || kind != tc.kind {
+			t.Errorf("%s: expected kind %q, got %q, %t", tc.stmt, tc.kind, kind, ok)
+		}
+	}
+}
@@ -93,6 +93,17 @@ func (sc *SchemaChanger) getChunkSize(chunkSize int64) int64 {
 	return chunkSize
 }
 
+// checkJobStatus returns an error if the job of the schema change was paused
+// or canceled, in which case the backfill stops. The progress of the backfill
+// is checkpointed in the mutations, so that it continues where it left off
+// when the job is resumed.
+func (sc *SchemaChanger) checkJobStatus(ctx context.Context) error {
+	if sc.jobLogger == nil {
+		return nil
+	}
+	return sc.jobLogger.CheckStatus(ctx)
+}
+
 // runBackfill runs the backfill for the schema changer.
 func (sc *SchemaChanger) runBackfill(
 	ctx context.Context, lease *sqlbase.TableDescriptor_SchemaChangeLease, evalCtx parser.EvalContext,
@@ -110,9 +121,10 @@ func (sc *SchemaChanger) runBackfill(
 	// mutations. Collect the elements that are part of the mutation.
 	var droppedIndexDescs []sqlbase.IndexDescriptor
 	var addedIndexDescs []sqlbase.IndexDescriptor
-	// Indexes within the Mutations slice for checkpointing.
-	mutationSentinel := -1
-	var droppedIndexMutationIdx int
+	// Indexes within the Mutations slice of the dropped indexes for
+	// checkpointing, and the spans at which to resume their truncation.
+	var droppedIndexMutationIdxs []int
+	var droppedIndexResumeSpans []roachpb.Span
 
 	var tableDesc *sqlbase.TableDescriptor
 	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -156,9 +168,9 @@ func (sc *SchemaChanger) runBackfill(
 				needColumnBackfill = true
 			case *sqlbase.DescriptorMutation_Index:
 				droppedIndexDescs = append(droppedIndexDescs, *t.Index)
-				if droppedIndexMutationIdx == mutationSentinel {
-					droppedIndexMutationIdx = i
-				}
+				droppedIndexMutationIdxs = append(droppedIndexMutationIdxs, i)
+				droppedIndexResumeSpans = append(droppedIndexResumeSpans,
+					droppedIndexResumeSpan(tableDesc, t.Index, m))
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -169,7 +181,7 @@ func (sc *SchemaChanger) runBackfill(
 
 	// Drop indexes.
 	if err := sc.truncateIndexes(
-		ctx, lease, version, droppedIndexDescs, droppedIndexMutationIdx,
+		ctx, lease, version, droppedIndexDescs, droppedIndexMutationIdxs, droppedIndexResumeSpans,
 	); err != nil {
 		return err
 	}
@@ -232,6 +244,27 @@ func (sc *SchemaChanger) maybeWriteResumeSpan(
 	return nil
 }
 
+// droppedIndexResumeSpan returns the span at which to resume the truncation
+// of a dropped index from the checkpoint of a previous attempt, or an empty
+// span to truncate the whole index. The resume span of a mutation starts out
+// as the primary index span, which is only where the truncation starts for
+// interleaved indexes.
+func droppedIndexResumeSpan(
+	tableDesc *sqlbase.TableDescriptor, idx *sqlbase.IndexDescriptor, m sqlbase.DescriptorMutation,
+) roachpb.Span {
+	if len(m.ResumeSpans) == 0 || m.ResumeSpans[0].Key == nil {
+		return roachpb.Span{}
+	}
+	span := tableDesc.IndexSpan(idx.ID)
+	if len(idx.Interleave.Ancestors) > 0 || len(idx.InterleavedBy) > 0 {
+		span = tableDesc.PrimaryIndexSpan()
+	}
+	if !span.Contains(m.ResumeSpans[0]) {
+		return roachpb.Span{}
+	}
+	return m.ResumeSpans[0]
+}
+
 func (sc *SchemaChanger) getTableLease(
 	ctx context.Context, txn *client.Txn, lc *LeaseCollection, version sqlbase.DescriptorVersion,
 ) (*sqlbase.TableDescriptor, error) {
@@ -250,20 +283,26 @@ func (sc *SchemaChanger) truncateIndexes(
 	lease *sqlbase.TableDescriptor_SchemaChangeLease,
 	version sqlbase.DescriptorVersion,
 	dropped []sqlbase.IndexDescriptor,
-	mutationIdx int,
+	mutationIdxs []int,
+	resumeSpans []roachpb.Span,
 ) error {
 	chunkSize := sc.getChunkSize(indexTruncateChunkSize)
 	if sc.testingKnobs.BackfillChunkSize > 0 {
 		chunkSize = sc.testingKnobs.BackfillChunkSize
 	}
-	for _, desc := range dropped {
-		var resume roachpb.Span
+	for i, desc := range dropped {
+		mutationIdx := mutationIdxs[i]
+		resume := resumeSpans[i]
 		lastCheckpoint := timeutil.Now()
 		for row, done := int64(0), false; !done; row += chunkSize {
 			// First extend the schema change lease.
 			if err := sc.ExtendLease(ctx, lease); err != nil {
 				return err
 			}
+			// Stop if the job was paused or canceled since the last chunk.
+			if err := sc.checkJobStatus(ctx); err != nil {
+				return err
+			}
 
 			resumeAt := resume
 			if log.V(2) {
@@ -400,6 +439,10 @@ func (sc *SchemaChanger) distBackfill(
 		if err := sc.ExtendLease(ctx, lease); err != nil {
 			return err
 		}
+		// Stop if the job was paused or canceled since the last checkpoint.
+		if err := sc.checkJobStatus(ctx); err != nil {
+			return err
+		}
 		log.VEventf(ctx, 2, "backfill: process %+v spans", spans)
 		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 			lc := &LeaseCollection{leaseMgr: sc.leaseMgr}
@@ -0,0 +1,141 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type cancelQueryNode struct {
+	p  *planner
+	id func() (string, error)
+}
+
+// CancelQuery cancels a query running on any node of the cluster, given its
+// ID as shown in crdb_internal.cluster_queries.
+// Privileges: root user.
+func (p *planner) CancelQuery(ctx context.Context, n *parser.CancelQuery) (planNode, error) {
+	if err := p.RequireSuperUser("CANCEL QUERY"); err != nil {
+		return nil, err
+	}
+	id, err := p.TypeAsString(n.ID, "CANCEL QUERY")
+	if err != nil {
+		return nil, err
+	}
+	return &cancelQueryNode{p: p, id: id}, nil
+}
+
+func (n *cancelQueryNode) Start(ctx context.Context) error {
+	queryIDStr, err := n.id()
+	if err != nil {
+		return err
+	}
+	queryID, err := StringToClusterWideID(queryIDStr)
+	if err != nil {
+		return errors.Wrapf(err, "invalid query ID %q", queryIDStr)
+	}
+
+	// The query ID contains the ID of the node running the query.
+	request := &serverpb.CancelQueryRequest{
+		NodeID:  fmt.Sprintf("%d", queryID.GetNodeID()),
+		QueryID: queryIDStr,
+	}
+	response, err := n.p.session.execCfg.StatusServer.CancelQuery(ctx, request)
+	if err != nil {
+		return err
+	}
+	if !response.Canceled {
+		return errors.Errorf("could not cancel query %s: %s", queryIDStr, response.Error)
+	}
+	return nil
+}
+
+func (*cancelQueryNode) Next(context.Context) (bool, error) { return false, nil }
+func (*cancelQueryNode) Close(context.Context)              {}
+func (*cancelQueryNode) Columns() sqlbase.ResultColumns     { return make(sqlbase.ResultColumns, 0) }
+func (*cancelQueryNode) Ordering() orderingInfo             { return orderingInfo{} }
+func (*cancelQueryNode) Values() parser.Datums              { return parser.Datums{} }
+func (*cancelQueryNode) DebugValues() debugValues           { return debugValues{} }
+func (*cancelQueryNode) MarkDebug(mode explainMode)         {}
+
+func (*cancelQueryNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
+
+type cancelSessionNode struct {
+	p  *planner
+	id func() (string, error)
+}
+
+// CancelSession cancels a session open on any node of the cluster, given its
+// ID as shown in crdb_internal.cluster_sessions. The queries of the session
+// are canceled and its connection is closed, which rolls back its open
+// transaction.
+// Privileges: root user.
+func (p *planner) CancelSession(ctx context.Context, n *parser.CancelSession) (planNode, error) {
+	if err := p.RequireSuperUser("CANCEL SESSION"); err != nil {
+		return nil, err
+	}
+	id, err := p.TypeAsString(n.ID, "CANCEL SESSION")
+	if err != nil {
+		return nil, err
+	}
+	return &cancelSessionNode{p: p, id: id}, nil
+}
+
+func (n *cancelSessionNode) Start(ctx context.Context) error {
+	sessionIDStr, err := n.id()
+	if err != nil {
+		return err
+	}
+	sessionID, err := StringToClusterWideID(sessionIDStr)
+	if err != nil {
+		return errors.Wrapf(err, "invalid session ID %q", sessionIDStr)
+	}
+
+	// The session ID contains the ID of the node of the session.
+	request := &serverpb.CancelSessionRequest{
+		NodeID:    fmt.Sprintf("%d", sessionID.GetNodeID()),
+		SessionID: sessionIDStr,
+	}
+	response, err := n.p.session.execCfg.StatusServer.CancelSession(ctx, request)
+	if err != nil {
+		return err
+	}
+	if !response.Canceled {
+		return errors.Errorf("could not cancel session %s: %s", sessionIDStr, response.Error)
+	}
+	return nil
+}
+
+func (*cancelSessionNode) Next(context.Context) (bool, error) { return false, nil }
+func (*cancelSessionNode) Close(context.Context)              {}
+func (*cancelSessionNode) Columns() sqlbase.ResultColumns     { return make(sqlbase.ResultColumns, 0) }
+func (*cancelSessionNode) Ordering() orderingInfo             { return orderingInfo{} }
+func (*cancelSessionNode) Values() parser.Datums              { return parser.Datums{} }
+func (*cancelSessionNode) DebugValues() debugValues           { return debugValues{} }
+func (*cancelSessionNode) MarkDebug(mode explainMode)         {}
+
+func (*cancelSessionNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
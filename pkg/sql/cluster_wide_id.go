@@ -0,0 +1,52 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+)
+
+// ClusterWideID identifies a session or a query uniquely across the
+// cluster. It is made of the 64 bits of wall time of an HLC timestamp
+// followed by the node ID (32 bits) and the logical time (32 bits) of that
+// timestamp, which makes it possible to find the node holding the session or
+// running the query from its ID alone.
+type ClusterWideID struct {
+	uint128.Uint128
+}
+
+// GenerateClusterWideID generates a ClusterWideID from a timestamp of the
+// clock of a node and the ID of that node.
+func GenerateClusterWideID(timestamp hlc.Timestamp, nodeID roachpb.NodeID) ClusterWideID {
+	lo := uint64(uint32(nodeID))<<32 | uint64(uint32(timestamp.Logical))
+	return ClusterWideID{Uint128: uint128.FromInts(uint64(timestamp.WallTime), lo)}
+}
+
+// StringToClusterWideID parses a ClusterWideID from its string
+// representation, as returned by String.
+func StringToClusterWideID(s string) (ClusterWideID, error) {
+	id, err := uint128.FromString(s)
+	if err != nil {
+		return ClusterWideID{}, err
+	}
+	return ClusterWideID{Uint128: id}, nil
+}
+
+// GetNodeID returns the ID of the node which generated the ClusterWideID.
+func (id ClusterWideID) GetNodeID() roachpb.NodeID {
+	return roachpb.NodeID(uint32(id.Lo() >> 32))
+}
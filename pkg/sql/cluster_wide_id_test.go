@@ -0,0 +1,57 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestClusterWideID(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		timestamp hlc.Timestamp
+		nodeID    roachpb.NodeID
+		str       string
+	}{
+		{hlc.Timestamp{}, 0, "00000000000000000000000000000000"},
+		{hlc.Timestamp{WallTime: 1, Logical: 2}, 3, "00000000000000010000000300000002"},
+		{hlc.Timestamp{WallTime: 1500000000000000000, Logical: 12}, 7, "14d1120d7b160000000000070000000c"},
+	}
+	for _, test := range testData {
+		id := GenerateClusterWideID(test.timestamp, test.nodeID)
+		if nodeID := id.GetNodeID(); nodeID != test.nodeID {
+			t.Errorf("expected node ID %d, got %d", test.nodeID, nodeID)
+		}
+		if str := id.String(); str != test.str {
+			t.Errorf("expected %v to be formatted as %s, got %s", id, test.str, str)
+		}
+		parsed, err := StringToClusterWideID(test.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != id {
+			t.Errorf("expected %s to be parsed as %v, got %v", test.str, id, parsed)
+		}
+	}
+
+	if _, err := StringToClusterWideID("not an ID"); err == nil {
+		t.Error("expected an error parsing a malformed ID")
+	}
+}
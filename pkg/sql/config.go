@@ -31,10 +31,16 @@ func init() {
 // GetZoneConfig returns the zone config for the object with 'id'.
 func GetZoneConfig(cfg config.SystemConfig, id uint32) (config.ZoneConfig, bool, error) {
 	// Look in the zones table.
+	var placeholder *config.ZoneConfig
 	if zoneVal := cfg.GetValue(sqlbase.MakeZoneKey(sqlbase.ID(id))); zoneVal != nil {
-		// We're done.
 		zone, err := config.MigrateZoneConfig(zoneVal)
-		return zone, true, err
+		if err != nil || !zone.IsSubzonePlaceholder() {
+			// We're done.
+			return zone, true, err
+		}
+		// The table only has zone configs for some of its partitions. The
+		// rest of the table inherits its parent's config.
+		placeholder = &zone
 	}
 
 	// No zone config for this ID. We need to figure out if it's a database
@@ -47,7 +53,16 @@ func GetZoneConfig(cfg config.SystemConfig, id uint32) (config.ZoneConfig, bool,
 		}
 		if tableDesc := desc.GetTable(); tableDesc != nil {
 			// This is a table descriptor. Lookup its parent database zone config.
-			return GetZoneConfig(cfg, uint32(tableDesc.ParentID))
+			zone, found, err := GetZoneConfig(cfg, uint32(tableDesc.ParentID))
+			if err != nil || placeholder == nil {
+				return zone, found, err
+			}
+			if !found {
+				zone = config.DefaultZoneConfig()
+			}
+			zone.Subzones = placeholder.Subzones
+			zone.SubzoneSpans = placeholder.SubzoneSpans
+			return zone, true, nil
 		}
 	}
 
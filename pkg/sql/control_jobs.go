@@ -0,0 +1,107 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// controlJobNode implements PAUSE JOB, RESUME JOB and CANCEL JOB, which
+// change the status of a job recorded in system.jobs.
+type controlJobNode struct {
+	p             *planner
+	jobID         parser.TypedExpr
+	desiredStatus jobs.JobStatus
+}
+
+// PauseJob pauses a running job, given its ID as shown in crdb_internal.jobs.
+// The job stops at its next checkpoint and can later be resumed.
+// Privileges: root user.
+func (p *planner) PauseJob(ctx context.Context, n *parser.PauseJob) (planNode, error) {
+	return p.controlJob(n.ID, jobs.JobStatusPaused, "PAUSE JOB")
+}
+
+// ResumeJob resumes a paused job, given its ID as shown in crdb_internal.jobs.
+// Privileges: root user.
+func (p *planner) ResumeJob(ctx context.Context, n *parser.ResumeJob) (planNode, error) {
+	return p.controlJob(n.ID, jobs.JobStatusRunning, "RESUME JOB")
+}
+
+// CancelJob cancels a job, given its ID as shown in crdb_internal.jobs. The
+// changes already made by the job are rolled back.
+// Privileges: root user.
+func (p *planner) CancelJob(ctx context.Context, n *parser.CancelJob) (planNode, error) {
+	return p.controlJob(n.ID, jobs.JobStatusCanceled, "CANCEL JOB")
+}
+
+func (p *planner) controlJob(
+	id parser.Expr, desiredStatus jobs.JobStatus, op string,
+) (planNode, error) {
+	if err := p.RequireSuperUser(op); err != nil {
+		return nil, err
+	}
+	typedID, err := parser.TypeCheckAndRequire(id, &p.semaCtx, parser.TypeInt, op)
+	if err != nil {
+		return nil, err
+	}
+	return &controlJobNode{p: p, jobID: typedID, desiredStatus: desiredStatus}, nil
+}
+
+func (n *controlJobNode) Start(ctx context.Context) error {
+	d, err := n.jobID.Eval(&n.p.evalCtx)
+	if err != nil {
+		return err
+	}
+	if d == parser.DNull {
+		return errors.New("job ID cannot be NULL")
+	}
+	jobID := int64(parser.MustBeDInt(d))
+
+	jl, err := jobs.GetJobLogger(
+		ctx, n.p.ExecCfg().DB, InternalExecutor{LeaseManager: n.p.LeaseMgr()}, jobID,
+	)
+	if err != nil {
+		return err
+	}
+	jl.WithTxn(n.p.txn)
+	switch n.desiredStatus {
+	case jobs.JobStatusPaused:
+		return jl.Paused(ctx)
+	case jobs.JobStatusRunning:
+		return jl.Resumed(ctx)
+	case jobs.JobStatusCanceled:
+		return jl.Canceled(ctx)
+	default:
+		return errors.Errorf("unexpected desired job status %s", n.desiredStatus)
+	}
+}
+
+func (*controlJobNode) Next(context.Context) (bool, error) { return false, nil }
+func (*controlJobNode) Close(context.Context)              {}
+func (*controlJobNode) Columns() sqlbase.ResultColumns     { return make(sqlbase.ResultColumns, 0) }
+func (*controlJobNode) Ordering() orderingInfo             { return orderingInfo{} }
+func (*controlJobNode) Values() parser.Datums              { return parser.Datums{} }
+func (*controlJobNode) DebugValues() debugValues           { return debugValues{} }
+func (*controlJobNode) MarkDebug(mode explainMode)         {}
+
+func (*controlJobNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
@@ -185,7 +185,6 @@ func (s *Session) ProcessCopyData(
 }
 
 func (n *copyNode) addRow(ctx context.Context, line []byte) error {
-	var err error
 	parts := bytes.Split(line, fieldDelim)
 	if len(parts) != len(n.resultColumns) {
 		return fmt.Errorf("expected %d values, got %d", len(n.resultColumns), len(parts))
@@ -198,55 +197,7 @@ func (n *copyNode) addRow(ctx context.Context, line []byte) error {
 			exprs[i] = parser.DNull
 			continue
 		}
-		var d parser.Datum
-		switch t := n.resultColumns[i].Typ; t {
-		case parser.TypeBool:
-			d, err = parser.ParseDBool(s)
-		case parser.TypeBytes:
-			s, err = decodeCopy(s)
-			d = parser.NewDBytes(parser.DBytes(s))
-		case parser.TypeDate:
-			s, err = decodeCopy(s)
-			if err != nil {
-				break
-			}
-			d, err = parser.ParseDDate(s, n.p.session.Location)
-		case parser.TypeDecimal:
-			d, err = parser.ParseDDecimal(s)
-		case parser.TypeFloat:
-			d, err = parser.ParseDFloat(s)
-		case parser.TypeInt:
-			d, err = parser.ParseDInt(s)
-		case parser.TypeInterval:
-			s, err = decodeCopy(s)
-			if err != nil {
-				break
-			}
-			d, err = parser.ParseDInterval(s)
-		case parser.TypeString:
-			s, err = decodeCopy(s)
-			d = parser.NewDString(s)
-		case parser.TypeTimestamp:
-			s, err = decodeCopy(s)
-			if err != nil {
-				break
-			}
-			d, err = parser.ParseDTimestamp(s, time.Microsecond)
-		case parser.TypeTimestampTZ:
-			s, err = decodeCopy(s)
-			if err != nil {
-				break
-			}
-			d, err = parser.ParseDTimestampTZ(s, n.p.session.Location, time.Microsecond)
-		case parser.TypeUUID:
-			s, err = decodeCopy(s)
-			if err != nil {
-				break
-			}
-			d, err = parser.ParseDUuidFromString(s)
-		default:
-			return fmt.Errorf("unknown type %s", t)
-		}
+		d, err := ParseCopyDatum(s, n.resultColumns[i].Typ, n.p.session.Location)
 		if err != nil {
 			return err
 		}
@@ -267,6 +218,68 @@ func (n *copyNode) addRow(ctx context.Context, line []byte) error {
 	return nil
 }
 
+// ParseCopyDatum parses a single, escaped, non-NULL field of the text format
+// used by COPY as a datum of the given type.
+func ParseCopyDatum(s string, t parser.Type, loc *time.Location) (parser.Datum, error) {
+	var d parser.Datum
+	var err error
+	switch t {
+	case parser.TypeBool:
+		d, err = parser.ParseDBool(s)
+	case parser.TypeBytes:
+		s, err = decodeCopy(s)
+		d = parser.NewDBytes(parser.DBytes(s))
+	case parser.TypeDate:
+		s, err = decodeCopy(s)
+		if err != nil {
+			break
+		}
+		d, err = parser.ParseDDate(s, loc)
+	case parser.TypeDecimal:
+		d, err = parser.ParseDDecimal(s)
+	case parser.TypeFloat:
+		d, err = parser.ParseDFloat(s)
+	case parser.TypeInt:
+		d, err = parser.ParseDInt(s)
+	case parser.TypeInterval:
+		s, err = decodeCopy(s)
+		if err != nil {
+			break
+		}
+		d, err = parser.ParseDInterval(s)
+	case parser.TypeString:
+		s, err = decodeCopy(s)
+		d = parser.NewDString(s)
+	case parser.TypeTimestamp:
+		s, err = decodeCopy(s)
+		if err != nil {
+			break
+		}
+		d, err = parser.ParseDTimestamp(s, time.Microsecond)
+	case parser.TypeTimestampTZ:
+		s, err = decodeCopy(s)
+		if err != nil {
+			break
+		}
+		d, err = parser.ParseDTimestampTZ(s, loc, time.Microsecond)
+	case parser.TypeUUID:
+		s, err = decodeCopy(s)
+		if err != nil {
+			break
+		}
+		d, err = parser.ParseDUuidFromString(s)
+	case parser.TypeJSON:
+		s, err = decodeCopy(s)
+		if err != nil {
+			break
+		}
+		d, err = parser.ParseDJSON(s)
+	default:
+		return nil, fmt.Errorf("unknown type %s", t)
+	}
+	return d, err
+}
+
 // decodeCopy unescapes a single COPY field.
 //
 // See: https://www.postgresql.org/docs/9.5/static/sql-copy.html#AEN74432
@@ -17,18 +17,30 @@
 package sql
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 var crdbInternal = virtualSchema{
@@ -39,8 +51,21 @@ var crdbInternal = virtualSchema{
 		crdbInternalLeasesTable,
 		crdbInternalSchemaChangesTable,
 		crdbInternalStmtStatsTable,
+		crdbInternalRecentLogTable,
 		crdbInternalJobsTable,
 		crdbInternalSessionTraceTable,
+		crdbInternalSessionLogsTable,
+		crdbInternalClusterSettingChangesTable,
+		crdbInternalSampledTracesTable,
+		crdbInternalLocalQueriesTable,
+		crdbInternalClusterQueriesTable,
+		crdbInternalLocalSessionsTable,
+		crdbInternalClusterSessionsTable,
+		crdbInternalClusterContentionEventsTable,
+		crdbInternalClusterNetworkLatenciesTable,
+		crdbInternalGossipNodesTable,
+		crdbInternalGossipLivenessTable,
+		crdbInternalGossipStoresTable,
 	},
 }
 
@@ -334,6 +359,57 @@ CREATE TABLE crdb_internal.jobs (
 	},
 }
 
+// crdbInternalClusterSettingChangesTable exposes the changes to the cluster
+// settings recorded in the event log, oldest first. The values are encoded
+// as in system.settings, and NULL when the setting had its default value.
+var crdbInternalClusterSettingChangesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.cluster_setting_changes (
+  timestamp TIMESTAMP NOT NULL,
+  name      STRING NOT NULL,
+  old_value STRING,
+  new_value STRING,
+  username  STRING NOT NULL,
+  node_id   INT NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		rows, err := p.queryRows(ctx,
+			`SELECT timestamp, reportingID, info FROM system.eventlog WHERE eventType = $1 ORDER BY timestamp, uniqueID`,
+			string(EventLogSetClusterSetting),
+		)
+		if err != nil {
+			return err
+		}
+		strOrNull := func(s *string) parser.Datum {
+			if s == nil {
+				return parser.DNull
+			}
+			return parser.NewDString(*s)
+		}
+		for _, r := range rows {
+			if r[2] == parser.DNull {
+				continue
+			}
+			var change clusterSettingChange
+			if err := json.Unmarshal([]byte(parser.MustBeDString(r[2])), &change); err != nil {
+				return err
+			}
+			if err := addRow(
+				r[0],
+				parser.NewDString(change.SettingName),
+				strOrNull(change.OldValue),
+				strOrNull(change.Value),
+				parser.NewDString(change.User),
+				r[1],
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 type stmtList []stmtKey
 
 func (s stmtList) Len() int {
@@ -456,6 +532,116 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+// crdbInternalRecentLogTable exposes the latest entries of severity
+// WARNING or above logged by this node.
+var crdbInternalRecentLogTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.node_recent_log (
+  node_id   INT NOT NULL,
+  timestamp TIMESTAMPTZ NOT NULL,
+  severity  STRING NOT NULL,
+  goroutine INT NOT NULL,
+  file      STRING NOT NULL,
+  line      INT NOT NULL,
+  message   STRING NOT NULL
+);
+`,
+	populate: func(_ context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		if p.session.User != security.RootUser {
+			return errors.New("only root can access the recent log entries")
+		}
+
+		leaseMgr := p.LeaseMgr()
+		nodeID := parser.NewDInt(parser.DInt(int64(leaseMgr.nodeID.Get())))
+
+		for _, e := range log.GetRecentEntries(0 /* since */) {
+			if err := addRow(
+				nodeID,
+				parser.MakeDTimestampTZ(time.Unix(0, e.Time), time.Microsecond),
+				parser.NewDString(e.Severity.String()),
+				parser.NewDInt(parser.DInt(e.Goroutine)),
+				parser.NewDString(e.File),
+				parser.NewDInt(parser.DInt(e.Line)),
+				parser.NewDString(e.Message),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalSampledTracesTable exposes the traces retained on this node
+// by the trace sampler (see the trace.sampling.rate cluster setting).
+var crdbInternalSampledTracesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.sampled_traces (
+  node_id    INT NOT NULL,
+  trace_id   INT NOT NULL,
+  start_time TIMESTAMPTZ NOT NULL,
+  duration   INTERVAL NOT NULL,
+  operation  STRING NOT NULL,
+  num_spans  INT NOT NULL,
+  trace      STRING NOT NULL
+);
+`,
+	populate: func(_ context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		if p.session.User != security.RootUser {
+			return errors.New("only root can access the sampled traces")
+		}
+
+		leaseMgr := p.LeaseMgr()
+		nodeID := parser.NewDInt(parser.DInt(int64(leaseMgr.nodeID.Get())))
+
+		for _, t := range tracing.GetSampledTraces() {
+			if err := addRow(
+				nodeID,
+				parser.NewDInt(parser.DInt(int64(t.TraceID))),
+				parser.MakeDTimestampTZ(t.StartTime, time.Microsecond),
+				&parser.DInterval{Duration: duration.Duration{Nanos: t.Duration.Nanoseconds()}},
+				parser.NewDString(t.Operation),
+				parser.NewDInt(parser.DInt(len(t.Spans))),
+				parser.NewDString(tracing.FormatRecordedSpans(t.Spans)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalSessionLogsTable exposes the log entries captured on this
+// session (via SET capture_logs = {on/off}).
+var crdbInternalSessionLogsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.session_logs(
+  TIMESTAMP TIMESTAMPTZ NOT NULL,  -- The entry's timestamp.
+  SEVERITY STRING NOT NULL,        -- The entry's severity.
+  FILE STRING NOT NULL,            -- The file which logged the entry.
+  LINE INT NOT NULL,               -- The line which logged the entry.
+  MESSAGE STRING NOT NULL          -- The logged message.
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		entries, dropped := p.session.logCapture.Entries()
+		for _, e := range entries {
+			if err := addRow(
+				parser.MakeDTimestampTZ(time.Unix(0, e.Time), time.Microsecond),
+				parser.NewDString(e.Severity.String()),
+				parser.NewDString(e.File),
+				parser.NewDInt(parser.DInt(e.Line)),
+				parser.NewDString(e.Message),
+			); err != nil {
+				return err
+			}
+		}
+		if dropped > 0 {
+			log.Warningf(ctx, "%d captured log entries were discarded", dropped)
+		}
+		return nil
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACE={ON/OFF})
 var crdbInternalSessionTraceTable = virtualSchemaTable{
@@ -487,3 +673,441 @@ CREATE TABLE crdb_internal.session_trace(
 		return nil
 	},
 }
+
+const queriesSchemaPattern = `
+CREATE TABLE crdb_internal.%s (
+  query_id         STRING,         -- the cluster-unique ID of the query
+  node_id          INT NOT NULL,   -- the node on which the query is running
+  username         STRING,         -- the user running the query
+  start            TIMESTAMP,      -- the start time of the query
+  query            STRING,         -- the SQL code of the query
+  client_address   STRING,         -- the address of the client that issued the query
+  application_name STRING,         -- the name of the application as per SET application_name
+  distributed      BOOL,           -- whether the query is running distributed
+  phase            STRING,         -- the current execution phase
+  memory_usage     INT             -- the bytes allocated by the transaction running the query
+);
+`
+
+// crdbInternalLocalQueriesTable exposes the queries running on the current
+// node. Only root can see the queries of the other users.
+var crdbInternalLocalQueriesTable = virtualSchemaTable{
+	schema: fmt.Sprintf(queriesSchemaPattern, "node_queries"),
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		req := &serverpb.ListSessionsRequest{Username: p.session.User}
+		response, err := p.session.execCfg.StatusServer.ListLocalSessions(ctx, req)
+		if err != nil {
+			return err
+		}
+		return populateQueriesTable(ctx, addRow, response)
+	},
+}
+
+// crdbInternalClusterQueriesTable exposes the queries running on all the
+// nodes of the cluster. Only root can see the queries of the other users.
+var crdbInternalClusterQueriesTable = virtualSchemaTable{
+	schema: fmt.Sprintf(queriesSchemaPattern, "cluster_queries"),
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		req := &serverpb.ListSessionsRequest{Username: p.session.User}
+		response, err := p.session.execCfg.StatusServer.ListSessions(ctx, req)
+		if err != nil {
+			return err
+		}
+		return populateQueriesTable(ctx, addRow, response)
+	},
+}
+
+func populateQueriesTable(
+	ctx context.Context, addRow func(...parser.Datum) error, response *serverpb.ListSessionsResponse,
+) error {
+	for _, session := range response.Sessions {
+		for _, query := range session.ActiveQueries {
+			isDistributedDatum := parser.DNull
+			if query.Phase == serverpb.ActiveQuery_EXECUTING {
+				isDistributedDatum = parser.DBoolFalse
+				if query.IsDistributed {
+					isDistributedDatum = parser.DBoolTrue
+				}
+			}
+			if err := addRow(
+				parser.NewDString(query.ID),
+				parser.NewDInt(parser.DInt(session.NodeID)),
+				parser.NewDString(session.Username),
+				parser.MakeDTimestamp(query.Start, time.Microsecond),
+				parser.NewDString(query.Sql),
+				parser.NewDString(session.ClientAddress),
+				parser.NewDString(session.ApplicationName),
+				isDistributedDatum,
+				parser.NewDString(strings.ToLower(query.Phase.String())),
+				parser.NewDInt(parser.DInt(query.AllocBytes)),
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, rpcErr := range response.Errors {
+		log.Warning(ctx, rpcErr.Message)
+		if rpcErr.NodeID != 0 {
+			// Add a row with this node ID, and nulls for all other columns.
+			if err := addRow(
+				parser.DNull,
+				parser.NewDInt(parser.DInt(rpcErr.NodeID)),
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const sessionsSchemaPattern = `
+CREATE TABLE crdb_internal.%s (
+  node_id            INT NOT NULL,   -- the node on which the session is open
+  session_id         STRING,         -- the cluster-unique ID of the session
+  username           STRING,         -- the user of the session
+  client_address     STRING,         -- the address of the client of the session
+  application_name   STRING,         -- the name of the application as per SET application_name
+  active_queries     STRING,         -- the queries currently running in the session
+  session_start      TIMESTAMP,      -- the time at which the session started
+  oldest_query_start TIMESTAMP,      -- the start time of the oldest running query
+  kv_txn             STRING,         -- the ID of the current KV transaction
+  memory_usage       INT,            -- the bytes currently allocated by the session
+  max_memory_usage   INT             -- the high-water mark of the bytes allocated by the session
+);
+`
+
+// crdbInternalLocalSessionsTable exposes the sessions open on the current
+// node. Only root can see the sessions of the other users.
+var crdbInternalLocalSessionsTable = virtualSchemaTable{
+	schema: fmt.Sprintf(sessionsSchemaPattern, "node_sessions"),
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		req := &serverpb.ListSessionsRequest{Username: p.session.User}
+		response, err := p.session.execCfg.StatusServer.ListLocalSessions(ctx, req)
+		if err != nil {
+			return err
+		}
+		return populateSessionsTable(ctx, addRow, response)
+	},
+}
+
+// crdbInternalClusterSessionsTable exposes the sessions open on all the
+// nodes of the cluster. Only root can see the sessions of the other users.
+var crdbInternalClusterSessionsTable = virtualSchemaTable{
+	schema: fmt.Sprintf(sessionsSchemaPattern, "cluster_sessions"),
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		req := &serverpb.ListSessionsRequest{Username: p.session.User}
+		response, err := p.session.execCfg.StatusServer.ListSessions(ctx, req)
+		if err != nil {
+			return err
+		}
+		return populateSessionsTable(ctx, addRow, response)
+	},
+}
+
+func populateSessionsTable(
+	ctx context.Context, addRow func(...parser.Datum) error, response *serverpb.ListSessionsResponse,
+) error {
+	for _, session := range response.Sessions {
+		// Generate active_queries and oldest_query_start.
+		var activeQueries bytes.Buffer
+		var oldestStart time.Time
+		var oldestStartDatum parser.Datum
+
+		for _, query := range session.ActiveQueries {
+			activeQueries.WriteString(query.Sql)
+			activeQueries.WriteString("; ")
+
+			if oldestStart.IsZero() || query.Start.Before(oldestStart) {
+				oldestStart = query.Start
+			}
+		}
+
+		if oldestStart.IsZero() {
+			oldestStartDatum = parser.DNull
+		} else {
+			oldestStartDatum = parser.MakeDTimestamp(oldestStart, time.Microsecond)
+		}
+
+		kvTxnIDDatum := parser.DNull
+		if session.KvTxnID != nil {
+			kvTxnIDDatum = parser.NewDString(session.KvTxnID.String())
+		}
+
+		if err := addRow(
+			parser.NewDInt(parser.DInt(session.NodeID)),
+			parser.NewDString(session.ID),
+			parser.NewDString(session.Username),
+			parser.NewDString(session.ClientAddress),
+			parser.NewDString(session.ApplicationName),
+			parser.NewDString(activeQueries.String()),
+			parser.MakeDTimestamp(session.Start, time.Microsecond),
+			oldestStartDatum,
+			kvTxnIDDatum,
+			parser.NewDInt(parser.DInt(session.AllocBytes)),
+			parser.NewDInt(parser.DInt(session.MaxAllocBytes)),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, rpcErr := range response.Errors {
+		log.Warning(ctx, rpcErr.Message)
+		if rpcErr.NodeID != 0 {
+			// Add a row with this node ID, and nulls for all other columns.
+			if err := addRow(
+				parser.NewDInt(parser.DInt(rpcErr.NodeID)),
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+				parser.DNull,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// crdbInternalClusterContentionEventsTable exposes the most recent contention
+// events recorded by all the nodes of the cluster, i.e. the requests which
+// had to push the transactions of conflicting intents. Only root can access
+// it, since it exposes the keys of the conflicting intents.
+var crdbInternalClusterContentionEventsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.cluster_contention_events (
+  node_id        INT NOT NULL,  -- the node which recorded the event
+  store_id       INT,           -- the store which recorded the event
+  key            STRING,        -- the key of the conflicting intent
+  txn_id         STRING,        -- the ID of the pushing transaction, if any
+  pushee_txn_id  STRING,        -- the ID of the transaction which wrote the intent
+  push_type      STRING,        -- PUSH_ABORT or PUSH_TIMESTAMP
+  outcome        STRING,        -- pushed, aborted, committed or failed
+  start          TIMESTAMP,     -- the time at which the push started
+  wait           INTERVAL       -- how long the request waited for the push
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		if p.session.User != security.RootUser {
+			return errors.New("only root can access the contention events")
+		}
+		response, err := p.session.execCfg.StatusServer.ListContentionEvents(
+			ctx, &serverpb.ListContentionEventsRequest{})
+		if err != nil {
+			return err
+		}
+
+		for _, event := range response.Events {
+			txnIDDatum := parser.DNull
+			if event.TxnID != nil {
+				txnIDDatum = parser.NewDString(event.TxnID.String())
+			}
+			if err := addRow(
+				parser.NewDInt(parser.DInt(event.NodeID)),
+				parser.NewDInt(parser.DInt(event.StoreID)),
+				parser.NewDString(event.Key.String()),
+				txnIDDatum,
+				parser.NewDString(event.PusheeTxnID.String()),
+				parser.NewDString(event.PushType),
+				parser.NewDString(event.Outcome),
+				parser.MakeDTimestamp(event.Time, time.Microsecond),
+				&parser.DInterval{Duration: duration.Duration{Nanos: event.WaitDurationNanos}},
+			); err != nil {
+				return err
+			}
+		}
+
+		for _, rpcErr := range response.Errors {
+			log.Warning(ctx, rpcErr.Message)
+			if rpcErr.NodeID != 0 {
+				// Add a row with this node ID, and nulls for all other columns.
+				if err := addRow(
+					parser.NewDInt(parser.DInt(rpcErr.NodeID)),
+					parser.DNull,
+					parser.DNull,
+					parser.DNull,
+					parser.DNull,
+					parser.DNull,
+					parser.DNull,
+					parser.DNull,
+					parser.DNull,
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalClusterNetworkLatenciesTable exposes the round-trip network
+// latencies measured by every node to every other node.
+var crdbInternalClusterNetworkLatenciesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.cluster_network_latencies (
+  origin_node_id  INT NOT NULL,  -- the node which measured the latency
+  target_node_id  INT NOT NULL,  -- the node the latency was measured to
+  latency         INTERVAL       -- the moving average of the round-trip latency
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		response, err := p.session.execCfg.StatusServer.NetworkLatencies(
+			ctx, &serverpb.NetworkLatenciesRequest{})
+		if err != nil {
+			return err
+		}
+
+		for _, latency := range response.Latencies {
+			if err := addRow(
+				parser.NewDInt(parser.DInt(latency.OriginNodeID)),
+				parser.NewDInt(parser.DInt(latency.TargetNodeID)),
+				&parser.DInterval{Duration: duration.Duration{Nanos: latency.LatencyNanos}},
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// forEachGossipInfo calls fn for each info in the local gossip network whose
+// key was created by gossip.MakeKey with the given prefix, in key order.
+func forEachGossipInfo(
+	g *gossip.Gossip, prefix string, fn func(info gossip.Info) error,
+) error {
+	infos := g.GetInfoStatus().Infos
+	pattern := regexp.MustCompile(gossip.MakePrefixPattern(prefix))
+	keys := make([]string, 0, len(infos))
+	for key := range infos {
+		if pattern.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(infos[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gossipUpdatedAt returns the time at which the info was last gossiped by its
+// originating node.
+func gossipUpdatedAt(info gossip.Info) parser.Datum {
+	return parser.MakeDTimestamp(time.Unix(0, info.OrigStamp), time.Microsecond)
+}
+
+var crdbInternalGossipNodesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.gossip_nodes (
+  node_id     INT NOT NULL,
+  network     STRING NOT NULL,
+  address     STRING NOT NULL,
+  attrs       STRING NOT NULL,
+  locality    STRING NOT NULL,
+  updated_at  TIMESTAMP,        -- when the descriptor was gossiped by the node
+  hops        INT NOT NULL      -- the number of hops the descriptor traveled
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		g := p.session.execCfg.Gossip
+		return forEachGossipInfo(g, gossip.KeyNodeIDPrefix, func(info gossip.Info) error {
+			var d roachpb.NodeDescriptor
+			if err := info.Value.GetProto(&d); err != nil {
+				return errors.Wrapf(err, "failed to parse node descriptor")
+			}
+			return addRow(
+				parser.NewDInt(parser.DInt(d.NodeID)),
+				parser.NewDString(d.Address.NetworkField),
+				parser.NewDString(d.Address.AddressField),
+				parser.NewDString(d.Attrs.String()),
+				parser.NewDString(d.Locality.String()),
+				gossipUpdatedAt(info),
+				parser.NewDInt(parser.DInt(info.Hops)),
+			)
+		})
+	},
+}
+
+var crdbInternalGossipLivenessTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.gossip_liveness (
+  node_id          INT NOT NULL,
+  epoch            INT NOT NULL,
+  expiration       STRING NOT NULL,
+  draining         BOOL NOT NULL,
+  decommissioning  BOOL NOT NULL,
+  is_live          BOOL NOT NULL,  -- whether the record is unexpired at the local clock
+  updated_at       TIMESTAMP       -- when the record was gossiped by its node
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		g := p.session.execCfg.Gossip
+		clock := p.session.execCfg.Clock
+		now := clock.Now()
+		return forEachGossipInfo(g, gossip.KeyNodeLivenessPrefix, func(info gossip.Info) error {
+			var l storage.Liveness
+			if err := info.Value.GetProto(&l); err != nil {
+				return errors.Wrapf(err, "failed to parse liveness record")
+			}
+			return addRow(
+				parser.NewDInt(parser.DInt(l.NodeID)),
+				parser.NewDInt(parser.DInt(l.Epoch)),
+				parser.NewDString(l.Expiration.String()),
+				parser.MakeDBool(parser.DBool(l.Draining)),
+				parser.MakeDBool(parser.DBool(l.Decommissioning)),
+				parser.MakeDBool(parser.DBool(l.IsLive(now, clock.MaxOffset()))),
+				gossipUpdatedAt(info),
+			)
+		})
+	},
+}
+
+var crdbInternalGossipStoresTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.gossip_stores (
+  store_id     INT NOT NULL,
+  node_id      INT NOT NULL,
+  attrs        STRING NOT NULL,
+  capacity     INT NOT NULL,
+  available    INT NOT NULL,
+  range_count  INT NOT NULL,
+  lease_count  INT NOT NULL,
+  updated_at   TIMESTAMP        -- when the descriptor was gossiped by the store's node
+);
+`,
+	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
+		g := p.session.execCfg.Gossip
+		return forEachGossipInfo(g, gossip.KeyStorePrefix, func(info gossip.Info) error {
+			var d roachpb.StoreDescriptor
+			if err := info.Value.GetProto(&d); err != nil {
+				return errors.Wrapf(err, "failed to parse store descriptor")
+			}
+			return addRow(
+				parser.NewDInt(parser.DInt(d.StoreID)),
+				parser.NewDInt(parser.DInt(d.Node.NodeID)),
+				parser.NewDString(d.Attrs.String()),
+				parser.NewDInt(parser.DInt(d.Capacity.Capacity)),
+				parser.NewDInt(parser.DInt(d.Capacity.Available)),
+				parser.NewDInt(parser.DInt(d.Capacity.RangeCount)),
+				parser.NewDInt(parser.DInt(d.Capacity.LeaseCount)),
+				gossipUpdatedAt(info),
+			)
+		})
+	},
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -158,6 +159,7 @@ func (p *planner) CreateIndex(ctx context.Context, n *parser.CreateIndex) (planN
 }
 
 func (n *createIndexNode) Start(ctx context.Context) error {
+	telemetry.Inc("sql.schema.create_index")
 	_, dropped, err := n.tableDesc.FindIndexByName(n.n.Name)
 	if err == nil {
 		if dropped {
@@ -173,6 +175,9 @@ func (n *createIndexNode) Start(ctx context.Context) error {
 		Unique:           n.n.Unique,
 		StoreColumnNames: n.n.Storing.ToStrings(),
 	}
+	if n.n.Inverted {
+		indexDesc.Type = sqlbase.IndexDescriptor_INVERTED
+	}
 	if err := indexDesc.FillColumns(n.n.Columns); err != nil {
 		return err
 	}
@@ -441,6 +446,7 @@ func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNod
 }
 
 func (n *createViewNode) Start(ctx context.Context) error {
+	telemetry.Inc("sql.schema.create_view")
 	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Name.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(ctx, n.p.txn, key); err == nil && exists {
@@ -525,6 +531,111 @@ func (*createViewNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
 	panic("unimplemented")
 }
 
+type createSequenceNode struct {
+	p      *planner
+	n      *parser.CreateSequence
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateSequence creates a sequence.
+// Privileges: CREATE on database.
+//   Notes: postgres requires CREATE on the schema.
+func (p *planner) CreateSequence(ctx context.Context, n *parser.CreateSequence) (planNode, error) {
+	name, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name.Database())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &createSequenceNode{
+		p:      p,
+		n:      n,
+		dbDesc: dbDesc,
+	}, nil
+}
+
+func (n *createSequenceNode) Start(ctx context.Context) error {
+	telemetry.Inc("sql.schema.create_sequence")
+	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Name.TableName().Table()}
+	key := tKey.Key()
+	if exists, err := descExists(ctx, n.p.txn, key); err == nil && exists {
+		if n.n.IfNotExists {
+			return nil
+		}
+		return sqlbase.NewRelationAlreadyExistsError(tKey.Name())
+	} else if err != nil {
+		return err
+	}
+
+	id, err := GenerateUniqueDescID(ctx, n.p.txn)
+	if err != nil {
+		return err
+	}
+
+	// Inherit permissions from the database descriptor.
+	privs := n.dbDesc.GetPrivileges()
+
+	desc, err := makeSequenceTableDesc(n.n, n.dbDesc.ID, id, privs)
+	if err != nil {
+		return err
+	}
+
+	if err := desc.ValidateTable(); err != nil {
+		return err
+	}
+
+	if err := n.p.createDescriptorWithID(ctx, key, id, &desc); err != nil {
+		return err
+	}
+
+	// Initialize the value of the sequence, so that the first call to
+	// nextval() returns the start value.
+	seqValueKey := keys.MakeSequenceKey(uint32(id))
+	opts := desc.SequenceOpts
+	if err := n.p.txn.Put(ctx, seqValueKey, opts.Start-opts.Increment); err != nil {
+		return err
+	}
+
+	if err := desc.Validate(ctx, n.p.txn); err != nil {
+		return err
+	}
+
+	// Log Create Sequence event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	return MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		n.p.txn,
+		EventLogCreateSequence,
+		int32(desc.ID),
+		int32(n.p.evalCtx.NodeID),
+		struct {
+			SequenceName string
+			Statement    string
+			User         string
+		}{n.n.Name.String(), n.n.String(), n.p.session.User},
+	)
+}
+
+func (*createSequenceNode) Next(context.Context) (bool, error) { return false, nil }
+func (*createSequenceNode) Close(context.Context)              {}
+func (*createSequenceNode) Columns() sqlbase.ResultColumns     { return make(sqlbase.ResultColumns, 0) }
+func (*createSequenceNode) Ordering() orderingInfo             { return orderingInfo{} }
+func (*createSequenceNode) Values() parser.Datums              { return parser.Datums{} }
+func (*createSequenceNode) DebugValues() debugValues           { return debugValues{} }
+func (*createSequenceNode) MarkDebug(mode explainMode)         {}
+
+func (*createSequenceNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
+
 type createTableNode struct {
 	p          *planner
 	n          *parser.CreateTable
@@ -615,6 +726,13 @@ func hoistConstraints(n *parser.CreateTable) {
 }
 
 func (n *createTableNode) Start(ctx context.Context) error {
+	telemetry.Inc("sql.schema.create_table")
+	if n.n.Interleave != nil {
+		telemetry.Inc("sql.schema.create_table.interleaved")
+	}
+	if n.n.PartitionBy != nil {
+		telemetry.Inc("sql.schema.create_table.partitioned")
+	}
 	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(ctx, n.p.txn, key); err == nil && exists {
@@ -1197,6 +1315,41 @@ func (n *createViewNode) makeViewTableDesc(
 	return desc, desc.AllocateIDs()
 }
 
+// makeSequenceTableDesc returns the table descriptor for a new sequence.
+// The value of the sequence is not stored as a row of the table, but
+// the descriptor has a single column describing it.
+func makeSequenceTableDesc(
+	n *parser.CreateSequence,
+	parentID sqlbase.ID,
+	id sqlbase.ID,
+	privileges *sqlbase.PrivilegeDescriptor,
+) (sqlbase.TableDescriptor, error) {
+	desc := sqlbase.TableDescriptor{
+		ID:            id,
+		ParentID:      parentID,
+		FormatVersion: sqlbase.FamilyFormatVersion,
+		Version:       1,
+		Privileges:    privileges,
+		SequenceOpts:  &sqlbase.SequenceOpts{},
+	}
+	seqName, err := n.Name.Normalize()
+	if err != nil {
+		return desc, err
+	}
+	desc.Name = seqName.Table()
+
+	if err := assignSequenceOptions(desc.SequenceOpts, n.Options, true /* setDefaults */); err != nil {
+		return desc, err
+	}
+
+	desc.AddColumn(sqlbase.ColumnDescriptor{
+		Name: "value",
+		Type: sqlbase.ColumnType{Kind: sqlbase.ColumnType_INT},
+	})
+
+	return desc, desc.AllocateIDs()
+}
+
 // makeTableDescIfAs is the MakeTableDesc method for when we have a table
 // that is created with the CREATE AS format.
 func makeTableDescIfAs(
@@ -1298,6 +1451,16 @@ func MakeTableDesc(
 		}
 	}
 
+	// Now that all the columns are known, check the expressions of the computed
+	// columns.
+	for i := range desc.Columns {
+		if desc.Columns[i].IsComputed() {
+			if err := sqlbase.ValidateComputedColumn(&desc.Columns[i], &desc, searchPath); err != nil {
+				return desc, err
+			}
+		}
+	}
+
 	var primaryIndexColumnSet map[string]struct{}
 	for _, def := range n.Defs {
 		switch d := def.(type) {
@@ -1309,6 +1472,9 @@ func MakeTableDesc(
 				Name:             string(d.Name),
 				StoreColumnNames: d.Storing.ToStrings(),
 			}
+			if d.Inverted {
+				idx.Type = sqlbase.IndexDescriptor_INVERTED
+			}
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
@@ -1380,6 +1546,15 @@ func MakeTableDesc(
 		}
 	}
 
+	if n.PartitionBy != nil {
+		partitioning, err := createPartitioning(
+			evalCtx, searchPath, &desc, &desc.PrimaryIndex, n.PartitionBy)
+		if err != nil {
+			return desc, err
+		}
+		desc.PrimaryIndex.Partitioning = partitioning
+	}
+
 	// With all structural elements in place and IDs allocated, we can resolve the
 	// constraints and qualifications.
 	// FKs are resolved after the descriptor is otherwise complete and IDs have
@@ -1427,6 +1602,10 @@ func MakeTableDesc(
 		}
 	}
 
+	if err := applyStorageParams(&desc, n.StorageParams); err != nil {
+		return desc, err
+	}
+
 	return desc, desc.AllocateIDs()
 }
 
@@ -309,7 +309,21 @@ func (p *planner) getDataSource(
 	switch t := src.(type) {
 	case *parser.NormalizableTableName:
 		// Usual case: a table.
-		tn, err := p.QualifyWithDatabase(ctx, t)
+		tn, err := t.Normalize()
+		if err != nil {
+			return planDataSource{}, err
+		}
+
+		// Is this perhaps the name of a common table expression?
+		ds, foundCTE, err := p.getCTEDataSource(ctx, tn)
+		if err != nil {
+			return planDataSource{}, err
+		}
+		if foundCTE {
+			return ds, nil
+		}
+
+		tn, err = p.QualifyWithDatabase(ctx, t)
 		if err != nil {
 			return planDataSource{}, err
 		}
@@ -570,10 +584,20 @@ func (p *planner) getViewPlan(
 		defer func() { p.skipSelectPrivilegeChecks = false }()
 	}
 
+	// The view query cannot refer to the common table expressions of the
+	// query using the view, only to those it defines itself.
+	savedCTEs := p.ctes
+	p.ctes = nil
+	defer func() { p.ctes = savedCTEs }()
+	selStmt := sel.Select
+	if sel.With != nil {
+		selStmt = &parser.ParenSelect{Select: &parser.Select{With: sel.With, Select: sel.Select}}
+	}
+
 	// TODO(a-robinson): Support ORDER BY and LIMIT in views. Is it as simple as
 	// just passing the entire select here or will inserting an ORDER BY in the
 	// middle of a query plan break things?
-	plan, err := p.getSubqueryPlan(ctx, *tn, sel.Select, sqlbase.ResultColumnsFromColDescs(desc.Columns))
+	plan, err := p.getSubqueryPlan(ctx, *tn, selStmt, sqlbase.ResultColumnsFromColDescs(desc.Columns))
 	if err != nil {
 		return plan, err
 	}
@@ -0,0 +1,101 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var backfillMaxRowsPerSecond = settings.RegisterValidatedIntSetting(
+	"sql.backfill.max_rows_per_second",
+	"maximum number of rows written per second by the schema change backfills on each node (0 for no limit)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.backfill.max_rows_per_second to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+var backfillMaxBytesPerSecond = settings.RegisterByteSizeSetting(
+	"sql.backfill.max_bytes_per_second",
+	"maximum number of bytes written per second by the schema change backfills on each node (0 for no limit)",
+	0,
+)
+
+// backfillThrottle limits the throughput of the backfills running on a node,
+// according to the sql.backfill cluster settings. It is shared by all the
+// backfill processors of the node.
+type backfillThrottle struct {
+	mu struct {
+		syncutil.Mutex
+		// next is the time at which the work done so far is paid for.
+		next time.Time
+	}
+}
+
+// backfillThrottleDelay returns how long it should take to write the given
+// number of rows and bytes without exceeding the limits, where a limit of 0
+// means no limit.
+func backfillThrottleDelay(rows, bytes, maxRowsPerSecond, maxBytesPerSecond int64) time.Duration {
+	var delay time.Duration
+	if maxRowsPerSecond > 0 {
+		delay = time.Duration(rows) * time.Second / time.Duration(maxRowsPerSecond)
+	}
+	if maxBytesPerSecond > 0 {
+		if d := time.Duration(bytes) * time.Second / time.Duration(maxBytesPerSecond); d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+// wait accounts for a chunk of rows and bytes written by a backfill, and
+// blocks until the throughput of the backfills of the node is back under the
+// limits. It is a no-op on a nil backfillThrottle.
+func (t *backfillThrottle) wait(ctx context.Context, rows, bytes int64) error {
+	if t == nil {
+		return nil
+	}
+	delay := backfillThrottleDelay(
+		rows, bytes, backfillMaxRowsPerSecond.Get(), backfillMaxBytesPerSecond.Get(),
+	)
+	if delay == 0 {
+		return nil
+	}
+	now := timeutil.Now()
+	t.mu.Lock()
+	if t.mu.next.Before(now) {
+		t.mu.next = now
+	}
+	t.mu.next = t.mu.next.Add(delay)
+	wait := t.mu.next.Sub(now)
+	t.mu.Unlock()
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestBackfillThrottleDelay(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		rows, bytes                         int64
+		maxRowsPerSecond, maxBytesPerSecond int64
+		expected                            time.Duration
+	}{
+		{100, 1000, 0, 0, 0},
+		{100, 1000, 1000, 0, 100 * time.Millisecond},
+		{100, 1000, 0, 2000, 500 * time.Millisecond},
+		// The most restrictive limit applies.
+		{100, 1000, 1000, 2000, 500 * time.Millisecond},
+		{100, 1000, 50, 2000, 2 * time.Second},
+		{0, 0, 1000, 2000, 0},
+	}
+	for i, tc := range testCases {
+		if d := backfillThrottleDelay(
+			tc.rows, tc.bytes, tc.maxRowsPerSecond, tc.maxBytesPerSecond,
+		); d != tc.expected {
+			t.Errorf("%d: expected delay %s, got %s", i, tc.expected, d)
+		}
+	}
+}
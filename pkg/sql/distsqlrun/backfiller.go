@@ -34,14 +34,15 @@ import (
 )
 
 type chunkBackfiller interface {
-	// runChunk returns the next-key and an error. next-key is nil
-	// once the backfill is complete.
+	// runChunk returns the next-key, the number of rows and the approximate
+	// number of bytes written, and an error. next-key is nil once the
+	// backfill is complete.
 	runChunk(
 		ctx context.Context,
 		mutations []sqlbase.DescriptorMutation,
 		span roachpb.Span,
 		chunkSize int64,
-	) (roachpb.Key, error)
+	) (nextKey roachpb.Key, rows int64, bytes int64, err error)
 }
 
 // MutationFilter is the type of a simple predicate on a mutation.
@@ -124,11 +125,17 @@ func (b *backfiller) mainLoop(ctx context.Context) error {
 			log.Infof(ctx, "%s backfill (%d, %d) at row: %d, span: %s",
 				b.name, desc.ID, mutationID, row, sp)
 		}
+		var rows, bytes int64
 		var err error
-		sp.Key, err = b.runChunk(ctx, mutations, sp, chunkSize)
+		sp.Key, rows, bytes, err = b.runChunk(ctx, mutations, sp, chunkSize)
 		if err != nil {
 			return err
 		}
+		// Slow down if the backfills of the node exceed their throughput
+		// limits.
+		if err := b.flowCtx.backfillThrottle.wait(ctx, rows, bytes); err != nil {
+			return err
+		}
 		if timeutil.Since(start) > b.spec.Duration && sp.Key != nil {
 			resume = sp
 			break
@@ -35,6 +35,11 @@ type columnBackfiller struct {
 	// updateCols is a slice of all column descriptors that are being modified.
 	updateCols  []sqlbase.ColumnDescriptor
 	updateExprs []parser.TypedExpr
+
+	// computedValues is the container over which the expressions of the
+	// added computed columns are evaluated; its current row is the row being
+	// backfilled.
+	computedValues sqlbase.RowIndexedVarContainer
 }
 
 var _ processor = &columnBackfiller{}
@@ -86,7 +91,7 @@ func (cb *columnBackfiller) init() error {
 				case sqlbase.DescriptorMutation_ADD:
 					desc := *m.GetColumn()
 					cb.added = append(cb.added, desc)
-					if desc.DefaultExpr == nil && !desc.Nullable {
+					if desc.DefaultExpr == nil && !desc.IsComputed() && !desc.Nullable {
 						addingNonNullableColumn = true
 					}
 				case sqlbase.DescriptorMutation_DROP:
@@ -100,12 +105,32 @@ func (cb *columnBackfiller) init() error {
 		return err
 	}
 
+	colIdxMap = make(map[sqlbase.ColumnID]int, len(desc.Columns))
+	for i, c := range desc.Columns {
+		colIdxMap[c.ID] = i
+	}
+	cb.computedValues = sqlbase.RowIndexedVarContainer{Cols: desc.Columns, Mapping: colIdxMap}
+	addingComputedColumn := false
+	for j := range cb.added {
+		if cb.added[j].IsComputed() {
+			addingComputedColumn = true
+		}
+	}
+
 	cb.updateCols = append(cb.added, cb.dropped...)
-	if len(cb.dropped) > 0 || addingNonNullableColumn || len(defaultExprs) > 0 {
-		// Populate default values.
+	if len(cb.dropped) > 0 || addingNonNullableColumn || addingComputedColumn || len(defaultExprs) > 0 {
+		// Populate default and computed values.
 		cb.updateExprs = make([]parser.TypedExpr, len(cb.updateCols))
 		for j := range cb.added {
-			if defaultExprs == nil || defaultExprs[j] == nil {
+			if cb.added[j].IsComputed() {
+				computeExpr, err := sqlbase.MakeComputedExpr(
+					&cb.added[j], &desc, &cb.computedValues, cb.flowCtx.evalCtx.SearchPath,
+				)
+				if err != nil {
+					return err
+				}
+				cb.updateExprs[j] = computeExpr
+			} else if defaultExprs == nil || defaultExprs[j] == nil {
 				cb.updateExprs[j] = parser.DNull
 			} else {
 				cb.updateExprs[j] = defaultExprs[j]
@@ -122,10 +147,6 @@ func (cb *columnBackfiller) init() error {
 		valNeededForCol[i] = true
 	}
 
-	colIdxMap = make(map[sqlbase.ColumnID]int, len(desc.Columns))
-	for i, c := range desc.Columns {
-		colIdxMap[c.ID] = i
-	}
 	return cb.fetcher.Init(
 		&desc, colIdxMap, &desc.PrimaryIndex, false, false, desc.Columns, valNeededForCol, false,
 	)
@@ -134,9 +155,11 @@ func (cb *columnBackfiller) init() error {
 // runChunk implements the chunkBackfiller interface.
 func (cb *columnBackfiller) runChunk(
 	ctx context.Context, mutations []sqlbase.DescriptorMutation, sp roachpb.Span, chunkSize int64,
-) (roachpb.Key, error) {
+) (roachpb.Key, int64, int64, error) {
 	tableDesc := cb.backfiller.spec.Table
+	var rows, bytes int64
 	err := cb.flowCtx.clientDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, bytes = 0, 0
 		if cb.flowCtx.testingKnobs.RunBeforeBackfillChunk != nil {
 			if err := cb.flowCtx.testingKnobs.RunBeforeBackfillChunk(sp); err != nil {
 				return err
@@ -209,7 +232,9 @@ func (cb *columnBackfiller) runChunk(
 				break
 			}
 			// Evaluate the new values. This must be done separately for
-			// each row so as to handle impure functions correctly.
+			// each row so as to handle impure functions correctly, and so
+			// that computed columns are computed from the values of the row.
+			cb.computedValues.CurSourceRow = row
 			for j, e := range cb.updateExprs {
 				val, err := e.Eval(&cb.flowCtx.evalCtx)
 				if err != nil {
@@ -232,6 +257,11 @@ func (cb *columnBackfiller) runChunk(
 			if _, err := ru.UpdateRow(ctx, b, oldValues, updateValues); err != nil {
 				return err
 			}
+			// The size of the new values approximates the bytes written.
+			for _, val := range updateValues {
+				bytes += int64(val.Size())
+			}
+			rows++
 		}
 		// Write the new row values.
 		if err := txn.CommitInBatch(ctx, b); err != nil {
@@ -239,5 +269,5 @@ func (cb *columnBackfiller) runChunk(
 		}
 		return nil
 	})
-	return cb.fetcher.Key(), err
+	return cb.fetcher.Key(), rows, bytes, err
 }
@@ -0,0 +1,141 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// diskRowFile is a temporary file to which rows are spilled. The rows are
+// appended and then read back in the same order. Each row is stored as the
+// length of its encoding followed by the value encoding of its datums.
+type diskRowFile struct {
+	fs    *flowTempStorage
+	file  *os.File
+	w     *bufio.Writer
+	types []sqlbase.ColumnType
+	// rows and size are the number of rows and bytes written so far.
+	rows int
+	size int64
+
+	scratch    []byte
+	datumAlloc sqlbase.DatumAlloc
+}
+
+func newDiskRowFile(fs *flowTempStorage, f *os.File, types []sqlbase.ColumnType) *diskRowFile {
+	return &diskRowFile{
+		fs:    fs,
+		file:  f,
+		w:     bufio.NewWriter(f),
+		types: types,
+	}
+}
+
+// AddRow appends a row to the file.
+func (f *diskRowFile) AddRow(row sqlbase.EncDatumRow) error {
+	if len(row) != len(f.types) {
+		return errors.Errorf("invalid row length %d, expected %d", len(row), len(f.types))
+	}
+	encoded := f.scratch[:0]
+	for i := range row {
+		var err error
+		encoded, err = row[i].Encode(&f.datumAlloc, sqlbase.DatumEncoding_VALUE, encoded)
+		if err != nil {
+			return err
+		}
+	}
+	f.scratch = encoded
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(encoded)))
+	size := int64(n + len(encoded))
+	if err := f.fs.reserve(size); err != nil {
+		return err
+	}
+	f.size += size
+	f.rows++
+	if _, err := f.w.Write(lenBuf[:n]); err != nil {
+		return err
+	}
+	_, err := f.w.Write(encoded)
+	return err
+}
+
+// Len returns the number of rows in the file.
+func (f *diskRowFile) Len() int {
+	return f.rows
+}
+
+// Size returns the size of the file in bytes.
+func (f *diskRowFile) Size() int64 {
+	return f.size
+}
+
+// NewReader returns a reader of the rows of the file, from the first one.
+// No more rows can be added once the file is read.
+func (f *diskRowFile) NewReader() (*diskRowReader, error) {
+	if err := f.w.Flush(); err != nil {
+		return nil, err
+	}
+	r := io.NewSectionReader(f.file, 0, f.size)
+	return &diskRowReader{r: bufio.NewReader(r), types: f.types}, nil
+}
+
+// Close removes the file and releases its quota.
+func (f *diskRowFile) Close() error {
+	f.fs.release(f.size)
+	f.size = 0
+	closeErr := f.file.Close()
+	if err := os.Remove(f.file.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// diskRowReader reads the rows of a diskRowFile.
+type diskRowReader struct {
+	r     *bufio.Reader
+	types []sqlbase.ColumnType
+}
+
+// Next returns the next row of the file, or nil once all the rows were read.
+// The returned row remains valid after the following calls.
+func (r *diskRowReader) Next() (sqlbase.EncDatumRow, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, err
+	}
+	row := make(sqlbase.EncDatumRow, len(r.types))
+	for i := range row {
+		row[i], buf, err = sqlbase.EncDatumFromBuffer(r.types[i], sqlbase.DatumEncoding_VALUE, buf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
@@ -68,6 +68,12 @@ type FlowCtx struct {
 	// run.
 	nodeID       roachpb.NodeID
 	testingKnobs TestingKnobs
+	// backfillThrottle limits the throughput of the backfills of the node.
+	backfillThrottle *backfillThrottle
+	// tempStorage is the temporary storage to which the sorters and hash
+	// joiners spill their rows when they exceed their memory budget. It is
+	// nil if spilling is disabled.
+	tempStorage *flowTempStorage
 }
 
 func (flowCtx *FlowCtx) setupTxn() *client.Txn {
@@ -18,6 +18,7 @@
 package distsqlrun
 
 import (
+	"hash/fnv"
 	"sync"
 	"unsafe"
 
@@ -44,6 +45,17 @@ type bucket struct {
 // (see hashJoiner).
 const hashJoinerInitialBufferSize = 4 * 1024 * 1024
 
+// hashJoinerSpillPartitionBits is the number of bits of the hash of the
+// equality columns which determine the partition of a row spilled to
+// temporary storage (see hashJoiner).
+const hashJoinerSpillPartitionBits = 4
+
+// hashJoinerMaxSpillLevel is the number of times the rows of a partition too
+// large for the working memory are partitioned again. Past that, the rows
+// likely have the same equality columns, and the partition is joined in
+// memory regardless.
+const hashJoinerMaxSpillLevel = 2
+
 const sizeOfBucket = int64(unsafe.Sizeof(bucket{}))
 const sizeOfRowIdx = int64(unsafe.Sizeof(int(0)))
 
@@ -62,10 +74,18 @@ const sizeOfRowIdx = int64(unsafe.Sizeof(int(0)))
 //  3. Probe phase: in this phase we process all the rows from the other stream
 //     and look for matching rows from the stored stream using the map.
 //
+// If the rows buffered during the first phase exceed the working memory or
+// the memory budget, and the flow has temporary storage, the rows of both
+// streams are instead spilled to partitions in temporary storage according to
+// the hash of their equality columns. The build and probe phases then run on
+// each pair of partitions in turn.
+//
 // There is no guarantee on the output ordering.
 type hashJoiner struct {
 	joinerBase
 
+	flowCtx *FlowCtx
+
 	// initialBufferSize is the maximum amount of data we buffer from each stream
 	// as part of the initial buffering phase. Normally
 	// hashJoinerInitialBufferSize, can be tweaked for tests.
@@ -93,6 +113,11 @@ type hashJoiner struct {
 
 	buckets    map[string]bucket
 	datumAlloc sqlbase.DatumAlloc
+
+	// partitions are set if the rows were spilled to temporary storage, in
+	// which case both streams were fully consumed by the initial buffering
+	// phase.
+	partitions [2][]*diskRowFile
 }
 
 var _ processor = &hashJoiner{}
@@ -106,6 +131,7 @@ func newHashJoiner(
 	output RowReceiver,
 ) (*hashJoiner, error) {
 	h := &hashJoiner{
+		flowCtx:           flowCtx,
 		initialBufferSize: hashJoinerInitialBufferSize,
 		buckets:           make(map[string]bucket),
 		bucketsAcc:        flowCtx.evalCtx.Mon.MakeBoundAccount(),
@@ -145,6 +171,10 @@ func (h *hashJoiner) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer h.rows[leftSide].Close(ctx)
 	defer h.rows[rightSide].Close(ctx)
 	defer h.bucketsAcc.Close(ctx)
+	defer func() {
+		closeRowFiles(ctx, h.partitions[leftSide])
+		closeRowFiles(ctx, h.partitions[rightSide])
+	}()
 
 	if earlyExit, err := h.bufferPhase(ctx); earlyExit || err != nil {
 		if err != nil {
@@ -157,6 +187,19 @@ func (h *hashJoiner) Run(ctx context.Context, wg *sync.WaitGroup) {
 		return
 	}
 
+	if h.partitions[leftSide] != nil {
+		// Both sources were consumed while spilling the rows.
+		if earlyExit, err := h.joinPartitions(ctx, h.partitions, 0 /* level */); earlyExit || err != nil {
+			if err != nil {
+				log.Infof(ctx, "spilled join error %s", err)
+			}
+			DrainAndClose(ctx, h.out.output, err /* cause */)
+			return
+		}
+		h.out.close()
+		return
+	}
+
 	// From this point, we are done with the source for h.storedSide.
 	srcToClose := h.leftSource
 	if h.storedSide == leftSide {
@@ -169,19 +212,9 @@ func (h *hashJoiner) Run(ctx context.Context, wg *sync.WaitGroup) {
 		return
 	}
 
-	// Allocate seen slices to produce results for unmatched stored rows,
-	// for FULL OUTER AND LEFT/RIGHT OUTER (depending on which stream we store).
-	if shouldEmitUnmatchedRow(h.storedSide, h.joinType) {
-		for k, bucket := range h.buckets {
-			if err := h.bucketsAcc.Grow(
-				ctx, int64(sizeOfBoolSlice+uintptr(len(bucket.rows))*sizeOfBool),
-			); err != nil {
-				DrainAndClose(ctx, h.out.output, err, srcToClose)
-				return
-			}
-			bucket.seen = make([]bool, len(bucket.rows))
-			h.buckets[k] = bucket
-		}
+	if err := h.initSeen(ctx); err != nil {
+		DrainAndClose(ctx, h.out.output, err, srcToClose)
+		return
 	}
 	log.VEventf(ctx, 1, "build phase complete")
 	if earlyExit, err := h.probePhase(ctx); earlyExit || err != nil {
@@ -277,8 +310,8 @@ func (h *hashJoiner) bufferPhase(ctx context.Context) (earlyExit bool, _ error)
 			return false, nil
 		}
 		// Add the row to the correct container.
-		if err := h.rows[side].AddRow(ctx, row); err != nil {
-			return false, err
+		if spilled, earlyExit, err := h.bufferRow(ctx, side, row); spilled || err != nil {
+			return earlyExit, err
 		}
 	}
 
@@ -294,10 +327,260 @@ func (h *hashJoiner) bufferPhase(ctx context.Context) (earlyExit bool, _ error)
 			}
 			return earlyExit, nil
 		}
-		if err := h.rows[rightSide].AddRow(ctx, row); err != nil {
+		if spilled, earlyExit, err := h.bufferRow(ctx, rightSide, row); spilled || err != nil {
+			return earlyExit, err
+		}
+	}
+}
+
+// bufferRow adds a row to the container of the given side. If the buffered
+// rows exceed the working memory or the memory budget, they are spilled to
+// temporary storage instead (see spill) and spilled is set.
+func (h *hashJoiner) bufferRow(
+	ctx context.Context, side joinSide, row sqlbase.EncDatumRow,
+) (spilled bool, earlyExit bool, _ error) {
+	if err := h.rows[side].AddRow(ctx, row); err != nil {
+		if !h.flowCtx.canSpill(err) {
+			return false, false, err
+		}
+		earlyExit, err := h.spill(ctx, side, row)
+		return true, earlyExit, err
+	}
+	if h.flowCtx.exceedsWorkMem(h.rows[leftSide].MemUsage() + h.rows[rightSide].MemUsage()) {
+		earlyExit, err := h.spill(ctx, side, nil /* pending */)
+		return true, earlyExit, err
+	}
+	return false, false, nil
+}
+
+// spill moves the rows buffered so far, as well as the pending row if it
+// couldn't be buffered, to partitions in temporary storage. It then consumes
+// the rest of both streams into the partitions, and sets h.storedSide to the
+// side with the least data.
+func (h *hashJoiner) spill(
+	ctx context.Context, pendingSide joinSide, pending sqlbase.EncDatumRow,
+) (earlyExit bool, _ error) {
+	for _, side := range []joinSide{leftSide, rightSide} {
+		partitions, err := h.newPartitions(ctx, side)
+		if err != nil {
+			return false, err
+		}
+		h.partitions[side] = partitions
+		rows := &h.rows[side]
+		for i := 0; i < rows.Len(); i++ {
+			if err := h.spillRow(partitions, side, rows.EncRow(i), 0 /* level */); err != nil {
+				return false, err
+			}
+		}
+		rows.Clear(ctx)
+	}
+	if pending != nil {
+		if err := h.spillRow(h.partitions[pendingSide], pendingSide, pending, 0 /* level */); err != nil {
 			return false, err
 		}
 	}
+
+	srcs := [2]RowSource{h.leftSource, h.rightSource}
+	for _, side := range []joinSide{leftSide, rightSide} {
+		for {
+			row, earlyExit, err := h.receiveRow(ctx, srcs[side], side)
+			if row == nil {
+				if earlyExit || err != nil {
+					return earlyExit, err
+				}
+				break
+			}
+			if err := h.spillRow(h.partitions[side], side, row, 0 /* level */); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	var sizes [2]int64
+	for side := range h.partitions {
+		for _, f := range h.partitions[side] {
+			sizes[side] += f.Size()
+		}
+	}
+	h.storedSide = rightSide
+	if sizes[leftSide] < sizes[rightSide] {
+		h.storedSide = leftSide
+	}
+	log.VEventf(ctx, 1, "spilled %d bytes to temporary storage", sizes[leftSide]+sizes[rightSide])
+	return false, nil
+}
+
+// newPartitions creates the temporary files to which the rows of the given
+// side are partitioned.
+func (h *hashJoiner) newPartitions(ctx context.Context, side joinSide) ([]*diskRowFile, error) {
+	types := h.leftSource.Types()
+	if side == rightSide {
+		types = h.rightSource.Types()
+	}
+	partitions := make([]*diskRowFile, 0, 1<<hashJoinerSpillPartitionBits)
+	for i := 0; i < cap(partitions); i++ {
+		f, err := h.flowCtx.tempStorage.newRowFile(types)
+		if err != nil {
+			closeRowFiles(ctx, partitions)
+			return nil, err
+		}
+		partitions = append(partitions, f)
+	}
+	return partitions, nil
+}
+
+// spillRow appends a row of the given side to its partition. Each level of
+// partitioning uses different bits of the hash of the equality columns, so
+// that the rows of a partition are split when they are partitioned again.
+func (h *hashJoiner) spillRow(
+	partitions []*diskRowFile, side joinSide, row sqlbase.EncDatumRow, level int,
+) error {
+	encoded, hasNull, err := encodeColumnsOfRow(
+		&h.datumAlloc, h.scratch, row, h.eqCols[side], false, /* encodeNull */
+	)
+	if err != nil {
+		return err
+	}
+	h.scratch = encoded[:0]
+
+	if hasNull {
+		panic("NULLs not detected during receive")
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write(encoded)
+	shift := uint(32 - hashJoinerSpillPartitionBits*(level+1))
+	partition := (hash.Sum32() >> shift) & (1<<hashJoinerSpillPartitionBits - 1)
+	return partitions[partition].AddRow(row)
+}
+
+// joinPartitions joins each pair of partitions of the spilled rows in turn.
+// The partitions whose stored rows exceed the working memory are partitioned
+// again, up to hashJoinerMaxSpillLevel.
+func (h *hashJoiner) joinPartitions(
+	ctx context.Context, partitions [2][]*diskRowFile, level int,
+) (earlyExit bool, _ error) {
+	for i := range partitions[h.storedSide] {
+		var err error
+		stored := partitions[h.storedSide][i]
+		other := partitions[otherSide(h.storedSide)][i]
+		if stored.Size() > tempStorageWorkMem.Get() && level < hashJoinerMaxSpillLevel {
+			earlyExit, err = h.repartition(ctx, partitions[leftSide][i], partitions[rightSide][i], level+1)
+		} else {
+			earlyExit, err = h.joinPartition(ctx, stored, other)
+		}
+		if earlyExit || err != nil {
+			return earlyExit, err
+		}
+		// Release the temporary storage of the partitions already joined.
+		closeRowFiles(ctx, partitions[leftSide][i:i+1])
+		closeRowFiles(ctx, partitions[rightSide][i:i+1])
+	}
+	return false, nil
+}
+
+// repartition splits the rows of a pair of partitions into new partitions at
+// the given level, and joins them.
+func (h *hashJoiner) repartition(
+	ctx context.Context, left, right *diskRowFile, level int,
+) (earlyExit bool, _ error) {
+	var partitions [2][]*diskRowFile
+	defer func() {
+		closeRowFiles(ctx, partitions[leftSide])
+		closeRowFiles(ctx, partitions[rightSide])
+	}()
+	for side, f := range [2]*diskRowFile{left, right} {
+		var err error
+		if partitions[side], err = h.newPartitions(ctx, joinSide(side)); err != nil {
+			return false, err
+		}
+		r, err := f.NewReader()
+		if err != nil {
+			return false, err
+		}
+		for {
+			row, err := r.Next()
+			if err != nil {
+				return false, err
+			}
+			if row == nil {
+				break
+			}
+			if err := h.spillRow(partitions[side], joinSide(side), row, level); err != nil {
+				return false, err
+			}
+		}
+	}
+	log.VEventf(ctx, 2, "partitioned %d spilled rows again", left.Len()+right.Len())
+	return h.joinPartitions(ctx, partitions, level)
+}
+
+// joinPartition joins a pair of partitions of the spilled rows: the stored
+// rows are loaded in memory to build the buckets, which are then probed with
+// the other rows.
+func (h *hashJoiner) joinPartition(
+	ctx context.Context, stored, other *diskRowFile,
+) (earlyExit bool, _ error) {
+	storedRows := &h.rows[h.storedSide]
+	defer func() {
+		storedRows.Clear(ctx)
+		h.buckets = make(map[string]bucket)
+		h.bucketsAcc.Clear(ctx)
+	}()
+
+	r, err := stored.NewReader()
+	if err != nil {
+		return false, err
+	}
+	for {
+		row, err := r.Next()
+		if err != nil {
+			return false, err
+		}
+		if row == nil {
+			break
+		}
+		if err := storedRows.AddRow(ctx, row); err != nil {
+			return false, err
+		}
+	}
+	if err := h.buildPhase(ctx); err != nil {
+		return false, err
+	}
+	if err := h.initSeen(ctx); err != nil {
+		return false, err
+	}
+
+	if r, err = other.NewReader(); err != nil {
+		return false, err
+	}
+	for {
+		row, err := r.Next()
+		if err != nil {
+			return false, err
+		}
+		if row == nil {
+			break
+		}
+		if earlyExit, err := h.probeRow(ctx, row); earlyExit || err != nil {
+			return earlyExit, err
+		}
+	}
+	return h.emitUnmatchedStored(ctx), nil
+}
+
+// closeRowFiles removes the given temporary files, if they haven't been
+// removed already.
+func closeRowFiles(ctx context.Context, files []*diskRowFile) {
+	for i, f := range files {
+		if f == nil {
+			continue
+		}
+		if err := f.Close(); err != nil {
+			log.Warningf(ctx, "unable to remove temporary file: %s", err)
+		}
+		files[i] = nil
+	}
 }
 
 // buildPhase constructs our internal hash map of rows seen. This is done
@@ -344,6 +627,25 @@ func (h *hashJoiner) buildPhase(ctx context.Context) error {
 	return nil
 }
 
+// initSeen allocates the seen slices used to produce results for unmatched
+// stored rows, for FULL OUTER AND LEFT/RIGHT OUTER (depending on which stream
+// we store).
+func (h *hashJoiner) initSeen(ctx context.Context) error {
+	if !shouldEmitUnmatchedRow(h.storedSide, h.joinType) {
+		return nil
+	}
+	for k, bucket := range h.buckets {
+		if err := h.bucketsAcc.Grow(
+			ctx, int64(sizeOfBoolSlice+uintptr(len(bucket.rows))*sizeOfBool),
+		); err != nil {
+			return err
+		}
+		bucket.seen = make([]bool, len(bucket.rows))
+		h.buckets[k] = bucket
+	}
+	return nil
+}
+
 func (h *hashJoiner) probeRow(
 	ctx context.Context, row sqlbase.EncDatumRow,
 ) (earlyExit bool, _ error) {
@@ -436,23 +738,32 @@ func (h *hashJoiner) probePhase(ctx context.Context) (earlyExit bool, _ error) {
 		}
 	}
 
-	if shouldEmitUnmatchedRow(h.storedSide, h.joinType) {
-		// Produce results for unmatched rows, for FULL OUTER AND LEFT/RIGHT OUTER
-		// (depending on which stream we use).
-		storedRows := &h.rows[h.storedSide]
-		for _, b := range h.buckets {
-			for i, seen := range b.seen {
-				if !seen && !h.maybeEmitUnmatchedRow(ctx, storedRows.EncRow(b.rows[i]), h.storedSide) {
-					return true, nil
-				}
-			}
-		}
+	if h.emitUnmatchedStored(ctx) {
+		return true, nil
 	}
 
 	h.out.close()
 	return false, nil
 }
 
+// emitUnmatchedStored produces results for unmatched stored rows, for FULL
+// OUTER AND LEFT/RIGHT OUTER (depending on which stream we use). It returns
+// true if the output doesn't need more rows.
+func (h *hashJoiner) emitUnmatchedStored(ctx context.Context) (earlyExit bool) {
+	if !shouldEmitUnmatchedRow(h.storedSide, h.joinType) {
+		return false
+	}
+	storedRows := &h.rows[h.storedSide]
+	for _, b := range h.buckets {
+		for i, seen := range b.seen {
+			if !seen && !h.maybeEmitUnmatchedRow(ctx, storedRows.EncRow(b.rows[i]), h.storedSide) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // encodeColumnsOfRow returns the encoding for the grouping columns. This is
 // then used as our group key to determine which bucket to add to.
 // If the row contains any NULLs and encodeNull is false, hasNull is true and
@@ -24,6 +24,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -505,8 +506,9 @@ func TestHashJoiner(t *testing.T) {
 
 	for _, c := range testCases {
 		t.Run("", func(t *testing.T) {
-			// Run tests with a variety of initial buffer sizes.
-			for _, initialBuffer := range []int64{0, 32, 64, 128, 1024 * 1024} {
+			// Run tests with a variety of initial buffer sizes, and with every
+			// row spilled to temporary storage (-1).
+			for _, initialBuffer := range []int64{-1, 0, 32, 64, 128, 1024 * 1024} {
 				t.Run(fmt.Sprintf("%d", initialBuffer), func(t *testing.T) {
 					hs := c.spec
 					leftInput := NewRowBuffer(nil /* types */, c.inputs[0], RowBufferArgs{})
@@ -515,6 +517,12 @@ func TestHashJoiner(t *testing.T) {
 					evalCtx := parser.MakeTestingEvalContext()
 					defer evalCtx.Stop(context.Background())
 					flowCtx := FlowCtx{evalCtx: evalCtx}
+					if initialBuffer < 0 {
+						defer settings.TestingSetByteSize(&tempStorageWorkMem, 1)()
+						var cleanup func()
+						flowCtx.tempStorage, cleanup = newTestFlowTempStorage(t)
+						defer cleanup()
+					}
 
 					post := PostProcessSpec{Projection: true, OutputColumns: c.outCols}
 					h, err := newHashJoiner(&flowCtx, &hs, leftInput, rightInput, &post, out)
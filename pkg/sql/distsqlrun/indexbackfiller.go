@@ -107,13 +107,15 @@ func (ib *indexBackfiller) init() error {
 
 func (ib *indexBackfiller) runChunk(
 	ctx context.Context, mutations []sqlbase.DescriptorMutation, sp roachpb.Span, chunkSize int64,
-) (roachpb.Key, error) {
+) (roachpb.Key, int64, int64, error) {
 	added := make([]sqlbase.IndexDescriptor, len(mutations))
 	for i, m := range mutations {
 		added[i] = *m.GetIndex()
 	}
-	secondaryIndexEntries := make([]sqlbase.IndexEntry, len(mutations))
+	secondaryIndexEntries := make([]sqlbase.IndexEntry, 0, len(mutations))
+	var rows, bytes int64
 	err := ib.flowCtx.clientDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, bytes = 0, 0
 		if ib.flowCtx.testingKnobs.RunBeforeBackfillChunk != nil {
 			if err := ib.flowCtx.testingKnobs.RunBeforeBackfillChunk(sp); err != nil {
 				return err
@@ -153,16 +155,19 @@ func (ib *indexBackfiller) runChunk(
 			if err := sqlbase.EncDatumRowToDatums(ib.rowVals, encRow, &ib.da); err != nil {
 				return err
 			}
-			if err := sqlbase.EncodeSecondaryIndexes(
+			secondaryIndexEntries, err = sqlbase.EncodeSecondaryIndexes(
 				&ib.spec.Table, added, ib.colIdxMap,
-				ib.rowVals, secondaryIndexEntries); err != nil {
+				ib.rowVals, secondaryIndexEntries[:0])
+			if err != nil {
 				return err
 			}
 			for _, secondaryIndexEntry := range secondaryIndexEntries {
 				log.VEventf(ctx, 3, "InitPut %s -> %v", secondaryIndexEntry.Key,
 					secondaryIndexEntry.Value)
 				b.InitPut(secondaryIndexEntry.Key, &secondaryIndexEntry.Value)
+				bytes += int64(len(secondaryIndexEntry.Key) + len(secondaryIndexEntry.Value.RawBytes))
 			}
+			rows++
 		}
 		// Write the new index values.
 		if err := txn.CommitInBatch(ctx, b); err != nil {
@@ -170,5 +175,5 @@ func (ib *indexBackfiller) runChunk(
 		}
 		return nil
 	})
-	return ib.fetcher.Key(), err
+	return ib.fetcher.Key(), rows, bytes, err
 }
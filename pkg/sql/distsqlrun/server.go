@@ -90,6 +90,9 @@ type ServerConfig struct {
 	Hist                *metric.Histogram
 	// NodeID is the id of the node on which this Server is running.
 	NodeID *base.NodeIDContainer
+	// TempStorage is where the processors spill their rows when they exceed
+	// their memory budget. Spilling is disabled if it is nil.
+	TempStorage *TempStorage
 }
 
 // ServerImpl implements the server for the distributed SQL APIs.
@@ -99,6 +102,8 @@ type ServerImpl struct {
 	flowScheduler *flowScheduler
 	memMonitor    mon.MemoryMonitor
 	regexpCache   *parser.RegexpCache
+	// backfillThrottle is shared by the backfill processors of the node.
+	backfillThrottle backfillThrottle
 }
 
 var _ DistSQLServer = &ServerImpl{}
@@ -186,15 +191,17 @@ func (ds *ServerImpl) setupFlow(
 	// TODO(radu): we should sanity check some of these fields (especially
 	// txnProto).
 	flowCtx := FlowCtx{
-		AmbientContext: ds.AmbientContext,
-		id:             req.Flow.FlowID,
-		evalCtx:        evalCtx,
-		rpcCtx:         ds.RPCContext,
-		txnProto:       &req.Txn,
-		clientDB:       ds.DB,
-		remoteTxnDB:    ds.FlowDB,
-		testingKnobs:   ds.TestingKnobs,
-		nodeID:         nodeID,
+		AmbientContext:   ds.AmbientContext,
+		id:               req.Flow.FlowID,
+		evalCtx:          evalCtx,
+		rpcCtx:           ds.RPCContext,
+		txnProto:         &req.Txn,
+		clientDB:         ds.DB,
+		remoteTxnDB:      ds.FlowDB,
+		testingKnobs:     ds.TestingKnobs,
+		nodeID:           nodeID,
+		backfillThrottle: &ds.backfillThrottle,
+		tempStorage:      newFlowTempStorage(ds.TempStorage),
 	}
 
 	ctx = flowCtx.AnnotateCtx(ctx)
@@ -93,8 +93,9 @@ func (s *sorter) Run(ctx context.Context, wg *sync.WaitGroup) {
 			// No specified ordering match length and unspecified limit; no
 			// optimizations are possible so we simply load all rows into memory and
 			// sort all values in-place. It has a worst-case time complexity of
-			// O(n*log(n)) and a worst-case space complexity of O(n).
-			ss = newSortAllStrategy(sv)
+			// O(n*log(n)) and a worst-case space complexity of O(n), part of which
+			// can be spilled to temporary storage.
+			ss = newSortAllStrategy(makeSpillingRowContainer(s.flowCtx, sv))
 		} else {
 			// No specified ordering match length but specified limit; we can optimize
 			// our sort procedure by maintaining a max-heap populated with only the
@@ -110,7 +111,7 @@ func (s *sorter) Run(ctx context.Context, wg *sync.WaitGroup) {
 		// chunk and then output.
 		// TODO(irfansharif): Add optimization for case where both ordering match
 		// length and limit is specified.
-		ss = newSortChunksStrategy(sv)
+		ss = newSortChunksStrategy(makeSpillingRowContainer(s.flowCtx, sv))
 	}
 
 	sortErr := ss.Execute(ctx, s)
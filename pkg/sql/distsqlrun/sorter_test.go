@@ -17,8 +17,10 @@
 package distsqlrun
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
@@ -170,45 +172,60 @@ func TestSorter(t *testing.T) {
 
 	for _, c := range testCases {
 		t.Run("", func(t *testing.T) {
-			ss := c.spec
-			types := make([]sqlbase.ColumnType, len(c.input[0]))
-			for i := range types {
-				types[i] = columnTypeInt
-			}
-			in := NewRowBuffer(types, c.input, RowBufferArgs{})
-			out := &RowBuffer{}
-			evalCtx := parser.MakeTestingEvalContext()
-			defer evalCtx.Stop(context.Background())
-			flowCtx := FlowCtx{
-				evalCtx: evalCtx,
-			}
+			// Run the tests with and without spilling every row to temporary
+			// storage.
+			for _, spill := range []bool{false, true} {
+				t.Run(fmt.Sprintf("spill=%t", spill), func(t *testing.T) {
+					ss := c.spec
+					types := make([]sqlbase.ColumnType, len(c.input[0]))
+					for i := range types {
+						types[i] = columnTypeInt
+					}
+					in := NewRowBuffer(types, c.input, RowBufferArgs{})
+					out := &RowBuffer{}
+					evalCtx := parser.MakeTestingEvalContext()
+					defer evalCtx.Stop(context.Background())
+					flowCtx := FlowCtx{
+						evalCtx: evalCtx,
+					}
+					if spill {
+						defer settings.TestingSetByteSize(&tempStorageWorkMem, 1)()
+						// Merge the runs of one row in several passes.
+						defer func(fanIn int) { maxMergeFanIn = fanIn }(maxMergeFanIn)
+						maxMergeFanIn = 2
+						var cleanup func()
+						flowCtx.tempStorage, cleanup = newTestFlowTempStorage(t)
+						defer cleanup()
+					}
 
-			s, err := newSorter(&flowCtx, &ss, in, &c.post, out)
-			if err != nil {
-				t.Fatal(err)
-			}
-			s.Run(context.Background(), nil)
-			if !out.ProducerClosed {
-				t.Fatalf("output RowReceiver not closed")
-			}
+					s, err := newSorter(&flowCtx, &ss, in, &c.post, out)
+					if err != nil {
+						t.Fatal(err)
+					}
+					s.Run(context.Background(), nil)
+					if !out.ProducerClosed {
+						t.Fatalf("output RowReceiver not closed")
+					}
 
-			var retRows sqlbase.EncDatumRows
-			for {
-				row, meta := out.Next()
-				if !meta.Empty() {
-					t.Fatalf("unexpected metadata: %v", meta)
-				}
-				if row == nil {
-					break
-				}
-				retRows = append(retRows, row)
-			}
+					var retRows sqlbase.EncDatumRows
+					for {
+						row, meta := out.Next()
+						if !meta.Empty() {
+							t.Fatalf("unexpected metadata: %v", meta)
+						}
+						if row == nil {
+							break
+						}
+						retRows = append(retRows, row)
+					}
 
-			expStr := c.expected.String()
-			retStr := retRows.String()
-			if expStr != retStr {
-				t.Errorf("invalid results; expected:\n   %s\ngot:\n   %s",
-					expStr, retStr)
+					expStr := c.expected.String()
+					retStr := retRows.String()
+					if expStr != retStr {
+						t.Errorf("invalid results; expected:\n   %s\ngot:\n   %s",
+							expStr, retStr)
+					}
+				})
 			}
 		})
 	}
@@ -40,23 +40,25 @@ type sorterStrategy interface {
 // sortAllStrategy reads in all values into the wrapped rows and
 // uses sort.Sort to sort all values in-place. It has a worst-case time
 // complexity of O(n*log(n)) and a worst-case space complexity of O(n).
+// The rows exceeding the working memory are spilled to temporary storage as
+// sorted runs, which are merged when the rows are sent out.
 //
 // The strategy is intended to be used when all values need to be sorted.
 type sortAllStrategy struct {
-	rows rowContainer
+	rows spillingRowContainer
 }
 
 var _ sorterStrategy = &sortAllStrategy{}
 
-func newSortAllStrategy(rows rowContainer) sorterStrategy {
+func newSortAllStrategy(rows spillingRowContainer) sorterStrategy {
 	return &sortAllStrategy{
 		rows: rows,
 	}
 }
 
 // The execution loop for the SortAll strategy:
-//  - loads all rows into memory;
-//  - runs sort.Sort to sort rows in place;
+//  - loads all rows into memory, or temporary storage;
+//  - runs sort.Sort to sort rows in place, and merges the spilled runs;
 //  - sends each row out to the output stream.
 func (ss *sortAllStrategy) Execute(ctx context.Context, s *sorter) error {
 	defer ss.rows.Close(ctx)
@@ -72,17 +74,8 @@ func (ss *sortAllStrategy) Execute(ctx context.Context, s *sorter) error {
 			return err
 		}
 	}
-	ss.rows.Sort()
-
-	for ss.rows.Len() > 0 {
-		// Push the row to the output; stop if they don't need more rows.
-		consumerStatus, err := s.out.emitRow(ctx, ss.rows.EncRow(0))
-		if err != nil || consumerStatus != NeedMoreRows {
-			return err
-		}
-		ss.rows.PopFirst()
-	}
-	return nil
+	_, err := ss.rows.emit(ctx, s)
+	return err
 }
 
 // sortTopKStrategy creates a max-heap in its wrapped rows and keeps
@@ -167,14 +160,15 @@ func (ss *sortTopKStrategy) Execute(ctx context.Context, s *sorter) error {
 
 // If we're scanning an index with a prefix matching an ordering prefix, we only accumulate values
 // for equal fields in this prefix, sort the accumulated chunk and then output.
+// The chunks exceeding the working memory are spilled to temporary storage.
 type sortChunksStrategy struct {
-	rows  rowContainer
+	rows  spillingRowContainer
 	alloc sqlbase.DatumAlloc
 }
 
 var _ sorterStrategy = &sortChunksStrategy{}
 
-func newSortChunksStrategy(rows rowContainer) sorterStrategy {
+func newSortChunksStrategy(rows spillingRowContainer) sorterStrategy {
 	return &sortChunksStrategy{
 		rows: rows,
 	}
@@ -186,7 +180,7 @@ func (ss *sortChunksStrategy) Execute(ctx context.Context, s *sorter) error {
 	// first s.matchLen ordering columns with the given pivot.
 	pivoted := func(row, pivot sqlbase.EncDatumRow) (bool, error) {
 		for _, ord := range s.ordering[:s.matchLen] {
-			cmp, err := row[ord.ColIdx].Compare(&ss.alloc, ss.rows.evalCtx, &pivot[ord.ColIdx])
+			cmp, err := row[ord.ColIdx].Compare(&ss.alloc, ss.rows.rows.evalCtx, &pivot[ord.ColIdx])
 			if err != nil || cmp != 0 {
 				return false, err
 			}
@@ -229,7 +223,7 @@ func (ss *sortChunksStrategy) Execute(ctx context.Context, s *sorter) error {
 			}
 
 			// We verify if the nextRow here is infact 'greater' than pivot.
-			if cmp, err := nextRow.Compare(&ss.alloc, s.ordering, ss.rows.evalCtx, pivot); err != nil {
+			if cmp, err := nextRow.Compare(&ss.alloc, s.ordering, ss.rows.rows.evalCtx, pivot); err != nil {
 				return err
 			} else if cmp < 0 {
 				return errors.Errorf("incorrectly ordered row %s before %s", pivot, nextRow)
@@ -237,19 +231,10 @@ func (ss *sortChunksStrategy) Execute(ctx context.Context, s *sorter) error {
 			break
 		}
 
-		// Sort the rows that have been pushed onto the buffer.
-		ss.rows.Sort()
-
-		// Stream out sorted rows in order to row receiver.
-		for ss.rows.Len() > 0 {
-			consumerStatus, err := s.out.emitRow(ctx, ss.rows.EncRow(0))
-			if err != nil || consumerStatus != NeedMoreRows {
-				// We don't need any more rows; clear out ss so to not hold on to that
-				// memory.
-				ss = &sortChunksStrategy{}
-				return err
-			}
-			ss.rows.PopFirst()
+		// Sort the rows that have been pushed onto the buffer and stream them
+		// out in order to row receiver.
+		if earlyExit, err := ss.rows.emit(ctx, s); earlyExit || err != nil {
+			return err
 		}
 		ss.rows.Clear(ctx)
 
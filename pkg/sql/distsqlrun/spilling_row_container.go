@@ -0,0 +1,247 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"container/heap"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// maxMergeFanIn is the maximum number of runs merged at once. The runs in
+// excess are first merged into larger runs, so that the number of open files
+// and read buffers of a sorter is bounded.
+var maxMergeFanIn = 128
+
+// spillingRowContainer accumulates the rows to be sorted by a sorter. The rows
+// are buffered in memory until they exceed the working memory or the memory
+// budget of the flow, at which point they are sorted and spilled to a run in
+// temporary storage. The runs are merged when the rows are emitted, in several
+// passes if there are more than maxMergeFanIn of them, which makes for an
+// external merge sort.
+//
+// Without temporary storage for the flow, the rows are only kept in memory.
+type spillingRowContainer struct {
+	flowCtx *FlowCtx
+	rows    rowContainer
+	// runs are the sorted runs spilled so far.
+	runs []*diskRowFile
+}
+
+func makeSpillingRowContainer(flowCtx *FlowCtx, rows rowContainer) spillingRowContainer {
+	return spillingRowContainer{flowCtx: flowCtx, rows: rows}
+}
+
+// AddRow adds a row to the container, spilling the rows buffered in memory
+// if needed.
+func (sc *spillingRowContainer) AddRow(ctx context.Context, row sqlbase.EncDatumRow) error {
+	if err := sc.rows.AddRow(ctx, row); err != nil {
+		if sc.rows.Len() == 0 || !sc.flowCtx.canSpill(err) {
+			return err
+		}
+		if err := sc.spill(ctx); err != nil {
+			return err
+		}
+		return sc.rows.AddRow(ctx, row)
+	}
+	if sc.flowCtx.exceedsWorkMem(sc.rows.MemUsage()) {
+		return sc.spill(ctx)
+	}
+	return nil
+}
+
+// spill sorts the rows buffered in memory and moves them to a new run.
+func (sc *spillingRowContainer) spill(ctx context.Context) error {
+	run, err := sc.flowCtx.tempStorage.newRowFile(sc.rows.types)
+	if err != nil {
+		return err
+	}
+	sc.runs = append(sc.runs, run)
+	sc.rows.Sort()
+	for i := 0; i < sc.rows.Len(); i++ {
+		if err := run.AddRow(sc.rows.EncRow(i)); err != nil {
+			return err
+		}
+	}
+	sc.rows.Clear(ctx)
+	log.VEventf(ctx, 2, "spilled %d rows to temporary storage", run.Len())
+	return nil
+}
+
+// emit sorts all the rows and pushes them to the output of the sorter, until
+// it doesn't need more rows, in which case earlyExit is set.
+func (sc *spillingRowContainer) emit(ctx context.Context, s *sorter) (earlyExit bool, _ error) {
+	if len(sc.runs) == 0 {
+		sc.rows.Sort()
+		for sc.rows.Len() > 0 {
+			// Push the row to the output; stop if they don't need more rows.
+			consumerStatus, err := s.out.emitRow(ctx, sc.rows.EncRow(0))
+			if err != nil || consumerStatus != NeedMoreRows {
+				return true, err
+			}
+			sc.rows.PopFirst()
+		}
+		return false, nil
+	}
+
+	if sc.rows.Len() > 0 {
+		if err := sc.spill(ctx); err != nil {
+			return false, err
+		}
+	}
+	// Merge the runs in several passes if needed, so that the number of files
+	// read at once is bounded.
+	for len(sc.runs) > maxMergeFanIn {
+		if err := sc.mergeFirstRuns(ctx); err != nil {
+			return false, err
+		}
+	}
+	err := sc.mergeRuns(sc.runs, func(row sqlbase.EncDatumRow) (bool, error) {
+		// Push the row to the output; stop if they don't need more rows.
+		consumerStatus, err := s.out.emitRow(ctx, row)
+		if err != nil || consumerStatus != NeedMoreRows {
+			earlyExit = true
+			return false, err
+		}
+		return true, nil
+	})
+	return earlyExit, err
+}
+
+// mergeFirstRuns merges the first maxMergeFanIn runs into a new run, which is
+// appended to the runs.
+func (sc *spillingRowContainer) mergeFirstRuns(ctx context.Context) error {
+	merged, err := sc.flowCtx.tempStorage.newRowFile(sc.rows.types)
+	if err != nil {
+		return err
+	}
+	runs := sc.runs[:maxMergeFanIn:maxMergeFanIn]
+	sc.runs = append(sc.runs[maxMergeFanIn:], merged)
+	defer closeRowFiles(ctx, runs)
+	if err := sc.mergeRuns(runs, func(row sqlbase.EncDatumRow) (bool, error) {
+		return true, merged.AddRow(row)
+	}); err != nil {
+		return err
+	}
+	log.VEventf(ctx, 2, "merged %d runs into a run of %d rows", len(runs), merged.Len())
+	return nil
+}
+
+// mergeRuns calls fn with the rows of the runs in order, until it returns
+// false or an error.
+func (sc *spillingRowContainer) mergeRuns(
+	runs []*diskRowFile, fn func(sqlbase.EncDatumRow) (bool, error),
+) error {
+	m := runMerger{ordering: sc.rows.ordering, evalCtx: sc.rows.evalCtx}
+	for _, run := range runs {
+		r, err := run.NewReader()
+		if err != nil {
+			return err
+		}
+		row, err := r.Next()
+		if err != nil {
+			return err
+		}
+		if row != nil {
+			m.runs = append(m.runs, mergedRun{reader: r, row: row})
+		}
+	}
+	heap.Init(&m)
+	for len(m.runs) > 0 {
+		if m.err != nil {
+			return m.err
+		}
+		if more, err := fn(m.runs[0].row); err != nil || !more {
+			return err
+		}
+		row, err := m.runs[0].reader.Next()
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			heap.Pop(&m)
+		} else {
+			m.runs[0].row = row
+			heap.Fix(&m, 0)
+		}
+	}
+	return m.err
+}
+
+// Clear removes all the rows from the container.
+func (sc *spillingRowContainer) Clear(ctx context.Context) {
+	sc.rows.Clear(ctx)
+	sc.closeRuns(ctx)
+}
+
+// Close releases the memory and the temporary storage used by the container.
+func (sc *spillingRowContainer) Close(ctx context.Context) {
+	sc.rows.Close(ctx)
+	sc.closeRuns(ctx)
+}
+
+func (sc *spillingRowContainer) closeRuns(ctx context.Context) {
+	closeRowFiles(ctx, sc.runs)
+	sc.runs = nil
+}
+
+// mergedRun is a run being merged by a runMerger, along with its smallest row
+// not yet emitted.
+type mergedRun struct {
+	reader *diskRowReader
+	row    sqlbase.EncDatumRow
+}
+
+// runMerger is a min-heap of the runs being merged, ordered by their
+// smallest row not yet emitted.
+type runMerger struct {
+	runs     []mergedRun
+	ordering sqlbase.ColumnOrdering
+	evalCtx  *parser.EvalContext
+	alloc    sqlbase.DatumAlloc
+	// err is set if the comparison of two rows failed.
+	err error
+}
+
+var _ heap.Interface = &runMerger{}
+
+// Len is part of heap.Interface.
+func (m *runMerger) Len() int { return len(m.runs) }
+
+// Less is part of heap.Interface.
+func (m *runMerger) Less(i, j int) bool {
+	cmp, err := m.runs[i].row.Compare(&m.alloc, m.ordering, m.evalCtx, m.runs[j].row)
+	if err != nil && m.err == nil {
+		m.err = err
+	}
+	return cmp < 0
+}
+
+// Swap is part of heap.Interface.
+func (m *runMerger) Swap(i, j int) { m.runs[i], m.runs[j] = m.runs[j], m.runs[i] }
+
+// Push is part of heap.Interface.
+func (m *runMerger) Push(x interface{}) { m.runs = append(m.runs, x.(mergedRun)) }
+
+// Pop is part of heap.Interface.
+func (m *runMerger) Pop() interface{} {
+	x := m.runs[len(m.runs)-1]
+	m.runs = m.runs[:len(m.runs)-1]
+	return x
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+var tempStorageWorkMem = settings.RegisterByteSizeSetting(
+	"sql.distsql.temp_storage.workmem",
+	"maximum memory used by the rows buffered by a sorter or hash joiner before they are "+
+		"spilled to temporary storage",
+	64<<20, // 64 MiB
+)
+
+var tempStorageQueryDiskQuota = settings.RegisterByteSizeSetting(
+	"sql.distsql.temp_storage.query_disk_quota",
+	"maximum temporary storage used by the rows spilled by a query on each node "+
+		"(0 disables spilling)",
+	1<<30, // 1 GiB
+)
+
+// TempStorage is the directory of a node in which the sorters and hash
+// joiners spill their rows to temporary files when they exceed their
+// memory budget.
+type TempStorage struct {
+	dir string
+}
+
+// tempStorageDirName is the name of the temporary storage directory of a node
+// within its parent directory.
+const tempStorageDirName = "cockroach-temp"
+
+// NewTempStorage creates the temporary storage directory of the node within
+// parent. The directory has a fixed name, so that the files left behind by a
+// node which didn't shut down cleanly are removed when it restarts: parent
+// must not be shared with other nodes. If parent is empty, a uniquely named
+// directory is created within the default directory for temporary files
+// instead. Close removes the directory along with the files left in it.
+func NewTempStorage(parent string) (*TempStorage, error) {
+	if parent == "" {
+		dir, err := ioutil.TempDir("", tempStorageDirName)
+		if err != nil {
+			return nil, err
+		}
+		return &TempStorage{dir: dir}, nil
+	}
+	dir := filepath.Join(parent, tempStorageDirName)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, errors.Wrap(err, "could not remove stale temporary storage")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &TempStorage{dir: dir}, nil
+}
+
+// Dir returns the directory of the temporary storage.
+func (ts *TempStorage) Dir() string {
+	return ts.dir
+}
+
+// Close is part of the stop.Closer interface.
+func (ts *TempStorage) Close() {
+	if err := os.RemoveAll(ts.dir); err != nil {
+		log.Warningf(context.TODO(), "unable to remove temporary storage %s: %s", ts.dir, err)
+	}
+}
+
+// flowTempStorage is the temporary storage available to the processors of a
+// flow, whose combined usage is limited by the disk quota of the query.
+type flowTempStorage struct {
+	ts    *TempStorage
+	quota int64
+	// used is accessed atomically, as the processors of the flow run
+	// concurrently.
+	used int64
+}
+
+// newFlowTempStorage returns the temporary storage of a flow, or nil if the
+// node has no temporary storage or the spilling is disabled.
+func newFlowTempStorage(ts *TempStorage) *flowTempStorage {
+	quota := tempStorageQueryDiskQuota.Get()
+	if ts == nil || quota <= 0 {
+		return nil
+	}
+	return &flowTempStorage{ts: ts, quota: quota}
+}
+
+// reserve accounts for n more bytes written to the temporary storage, or
+// returns an error if the quota would be exceeded.
+func (fs *flowTempStorage) reserve(n int64) error {
+	if used := atomic.AddInt64(&fs.used, n); used > fs.quota {
+		atomic.AddInt64(&fs.used, -n)
+		return pgerror.NewErrorf(pgerror.CodeDiskFullError,
+			"temporary storage quota exceeded: %d bytes requested, %d bytes in quota "+
+				"(see sql.distsql.temp_storage.query_disk_quota)", n, fs.quota)
+	}
+	return nil
+}
+
+// release accounts for n bytes removed from the temporary storage.
+func (fs *flowTempStorage) release(n int64) {
+	atomic.AddInt64(&fs.used, -n)
+}
+
+// newRowFile creates a temporary file of rows with the given types.
+func (fs *flowTempStorage) newRowFile(types []sqlbase.ColumnType) (*diskRowFile, error) {
+	f, err := ioutil.TempFile(fs.ts.dir, "rows")
+	if err != nil {
+		return nil, err
+	}
+	return newDiskRowFile(fs, f, types), nil
+}
+
+// isMemoryBudgetExceeded returns whether err was returned by a memory monitor
+// refusing an allocation.
+func isMemoryBudgetExceeded(err error) bool {
+	pgErr, ok := pgerror.GetPGCause(err)
+	return ok && pgErr.Code == pgerror.CodeOutOfMemoryError
+}
+
+// canSpill returns whether a processor which couldn't buffer a row because of
+// err can spill its rows to temporary storage instead.
+func (flowCtx *FlowCtx) canSpill(err error) bool {
+	return flowCtx.tempStorage != nil && isMemoryBudgetExceeded(err)
+}
+
+// exceedsWorkMem returns whether a processor buffering rows using the given
+// amount of memory should spill them to temporary storage.
+func (flowCtx *FlowCtx) exceedsWorkMem(usage int64) bool {
+	return flowCtx.tempStorage != nil && usage > tempStorageWorkMem.Get()
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// newTestFlowTempStorage returns the temporary storage of a test flow, and a
+// function removing it.
+func newTestFlowTempStorage(t *testing.T) (*flowTempStorage, func()) {
+	ts, err := NewTempStorage("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newFlowTempStorage(ts), ts.Close
+}
+
+func TestDiskRowFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fs, cleanup := newTestFlowTempStorage(t)
+	defer cleanup()
+
+	types := []sqlbase.ColumnType{
+		{Kind: sqlbase.ColumnType_INT},
+		{Kind: sqlbase.ColumnType_STRING},
+	}
+	var rows sqlbase.EncDatumRows
+	for i := 0; i < 100; i++ {
+		rows = append(rows, sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(types[0], parser.NewDInt(parser.DInt(i))),
+			sqlbase.DatumToEncDatum(types[1], parser.NewDString("row")),
+		})
+	}
+	rows = append(rows, sqlbase.EncDatumRow{
+		sqlbase.DatumToEncDatum(types[0], parser.DNull),
+		sqlbase.DatumToEncDatum(types[1], parser.DNull),
+	})
+
+	f, err := fs.newRowFile(types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := f.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f.Len() != len(rows) {
+		t.Errorf("expected %d rows, got %d", len(rows), f.Len())
+	}
+	if fs.used != f.Size() {
+		t.Errorf("expected %d bytes used, got %d", f.Size(), fs.used)
+	}
+
+	r, err := f.NewReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read sqlbase.EncDatumRows
+	for {
+		row, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row == nil {
+			break
+		}
+		read = append(read, row)
+	}
+	if expected, actual := rows.String(), read.String(); expected != actual {
+		t.Errorf("expected rows:\n   %s\ngot:\n   %s", expected, actual)
+	}
+
+	name := f.file.Name()
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", name, err)
+	}
+	if fs.used != 0 {
+		t.Errorf("expected no bytes used, got %d", fs.used)
+	}
+}
+
+func TestTempStorageQuota(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer settings.TestingSetByteSize(&tempStorageQueryDiskQuota, 10)()
+	fs, cleanup := newTestFlowTempStorage(t)
+	defer cleanup()
+
+	types := []sqlbase.ColumnType{{Kind: sqlbase.ColumnType_INT}}
+	f, err := fs.newRowFile(types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	row := sqlbase.EncDatumRow{sqlbase.DatumToEncDatum(types[0], parser.NewDInt(1))}
+	for i := 0; ; i++ {
+		err := f.AddRow(row)
+		if err == nil {
+			continue
+		}
+		if !testutils.IsError(err, "temporary storage quota exceeded") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pgErr, ok := pgerror.GetPGCause(err); !ok || pgErr.Code != pgerror.CodeDiskFullError {
+			t.Errorf("expected a disk full error, got %v", err)
+		}
+		if i == 0 || f.Size() > 10 {
+			t.Errorf("unexpected size %d after %d rows", f.Size(), i)
+		}
+		break
+	}
+}
+
+func TestTempStorageDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, err := ioutil.TempDir("", "TestTempStorageDisabled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Error(err)
+		}
+	}()
+	ts, err := NewTempStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	if newFlowTempStorage(nil) != nil {
+		t.Errorf("expected no temporary storage without a directory")
+	}
+	defer settings.TestingSetByteSize(&tempStorageQueryDiskQuota, 0)()
+	if newFlowTempStorage(ts) != nil {
+		t.Errorf("expected no temporary storage with a zero quota")
+	}
+}
+
+func TestTempStorageRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, err := ioutil.TempDir("", "TestTempStorageRestart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Error(err)
+		}
+	}()
+	ts, err := NewTempStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(ts.Dir(), "rows")
+	if err := ioutil.WriteFile(stale, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The files left behind by a node which didn't shut down cleanly are
+	// removed when it restarts.
+	ts2, err := NewTempStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts2.Close()
+	if ts2.Dir() != ts.Dir() {
+		t.Errorf("expected the directory %s to be reused, got %s", ts.Dir(), ts2.Dir())
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected the stale file to be removed, got %v", err)
+	}
+}
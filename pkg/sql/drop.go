@@ -529,6 +529,97 @@ func (*dropViewNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
 	panic("unimplemented")
 }
 
+type dropSequenceNode struct {
+	p  *planner
+	n  *parser.DropSequence
+	td []*sqlbase.TableDescriptor
+}
+
+// DropSequence drops a sequence.
+// Privileges: DROP on sequence.
+//   Notes: postgres allows only the sequence owner to DROP a sequence.
+func (p *planner) DropSequence(ctx context.Context, n *parser.DropSequence) (planNode, error) {
+	td := make([]*sqlbase.TableDescriptor, 0, len(n.Names))
+	for _, name := range n.Names {
+		tn, err := name.NormalizeTableName()
+		if err != nil {
+			return nil, err
+		}
+		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+			return nil, err
+		}
+
+		droppedDesc, err := p.dropTableOrViewPrepare(ctx, tn)
+		if err != nil {
+			return nil, err
+		}
+		if droppedDesc == nil {
+			if n.IfExists {
+				continue
+			}
+			// Sequence does not exist, but we want it to: error out.
+			return nil, sqlbase.NewUndefinedSequenceError(name.String())
+		}
+		if !droppedDesc.IsSequence() {
+			return nil, sqlbase.NewWrongObjectTypeError(name.String(), "sequence")
+		}
+
+		td = append(td, droppedDesc)
+	}
+
+	if len(td) == 0 {
+		return &emptyNode{}, nil
+	}
+	return &dropSequenceNode{p: p, n: n, td: td}, nil
+}
+
+func (n *dropSequenceNode) Start(ctx context.Context) error {
+	for _, droppedDesc := range n.td {
+		if droppedDesc == nil {
+			continue
+		}
+		// The value of the sequence is deleted along with the data of the
+		// table by the schema changer.
+		if err := n.p.initiateDropTable(ctx, droppedDesc); err != nil {
+			return err
+		}
+		seqID := droppedDesc.ID
+		n.p.session.setTestingVerifyMetadata(func(systemConfig config.SystemConfig) error {
+			return verifyDropTableMetadata(systemConfig, seqID, "sequence")
+		})
+		// Log a Drop Sequence event for this sequence. This is an auditable
+		// log event and is recorded in the same transaction as the table
+		// descriptor update.
+		if err := MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			n.p.txn,
+			EventLogDropSequence,
+			int32(droppedDesc.ID),
+			int32(n.p.evalCtx.NodeID),
+			struct {
+				SequenceName string
+				Statement    string
+				User         string
+			}{droppedDesc.Name, n.n.String(), n.p.session.User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropSequenceNode) Next(context.Context) (bool, error) { return false, nil }
+func (*dropSequenceNode) Close(context.Context)              {}
+func (*dropSequenceNode) Columns() sqlbase.ResultColumns     { return make(sqlbase.ResultColumns, 0) }
+func (*dropSequenceNode) Ordering() orderingInfo             { return orderingInfo{} }
+func (*dropSequenceNode) Values() parser.Datums              { return parser.Datums{} }
+func (*dropSequenceNode) DebugValues() debugValues           { return debugValues{} }
+func (*dropSequenceNode) MarkDebug(mode explainMode)         {}
+
+func (*dropSequenceNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
+
 type dropTableNode struct {
 	p  *planner
 	n  *parser.DropTable
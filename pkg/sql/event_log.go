@@ -25,6 +25,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // EventLogType represents an event type that can be recorded in the event log.
@@ -55,6 +56,13 @@ const (
 	// EventLogDropView is recorded when a view is dropped.
 	EventLogDropView EventLogType = "drop_view"
 
+	// EventLogCreateSequence is recorded when a sequence is created.
+	EventLogCreateSequence EventLogType = "create_sequence"
+	// EventLogAlterSequence is recorded when a sequence is altered.
+	EventLogAlterSequence EventLogType = "alter_sequence"
+	// EventLogDropSequence is recorded when a sequence is dropped.
+	EventLogDropSequence EventLogType = "drop_sequence"
+
 	// EventLogReverseSchemaChange is recorded when an in-progress schema change
 	// encounters a problem and is reversed.
 	EventLogReverseSchemaChange EventLogType = "reverse_schema_change"
@@ -62,11 +70,28 @@ const (
 	// change has completed.
 	EventLogFinishSchemaChange EventLogType = "finish_schema_change"
 
+	// EventLogSetClusterSetting is recorded when a cluster setting is changed.
+	EventLogSetClusterSetting EventLogType = "set_cluster_setting"
+
 	// EventLogNodeJoin is recorded when a node joins the cluster.
 	EventLogNodeJoin EventLogType = "node_join"
 	// EventLogNodeRestart is recorded when an existing node rejoins the cluster
 	// after being offline.
 	EventLogNodeRestart EventLogType = "node_restart"
+
+	// EventLogStoreDiskSpace is recorded when a store starts or stops declining
+	// snapshots or rejecting writes because of its free disk space.
+	EventLogStoreDiskSpace EventLogType = "store_disk_space"
+	// EventLogRangeUnavailable is recorded when ranges of a store become
+	// unavailable.
+	EventLogRangeUnavailable EventLogType = "range_unavailable"
+	// EventLogReplicaCorruption is recorded when replicas of a store are found
+	// corrupt.
+	EventLogReplicaCorruption EventLogType = "replica_corruption"
+
+	// EventLogCertificateExpiry is recorded when a certificate of a node is
+	// about to expire.
+	EventLogCertificateExpiry EventLogType = "certificate_expiry"
 )
 
 // An EventLogger exposes methods used to record events to the event table.
@@ -91,7 +116,16 @@ func (ev EventLogger) InsertEventRecord(
 	targetID, reportingID int32,
 	info interface{},
 ) error {
-	// Record event record insertion in local log output.
+	var infoBytes []byte
+	if info != nil {
+		var err error
+		if infoBytes, err = json.Marshal(info); err != nil {
+			return err
+		}
+	}
+
+	// Record event record insertion in local log output, and notify the
+	// webhook of critical events.
 	txn.AddCommitTrigger(func() {
 		log.Infof(
 			ctx, "Event: %q, target: %d, info: %+v",
@@ -99,6 +133,13 @@ func (ev EventLogger) InsertEventRecord(
 			targetID,
 			info,
 		)
+		ev.maybeNotifyWebhook(ctx, eventLogWebhookEvent{
+			Timestamp:   timeutil.Now(),
+			EventType:   eventType,
+			TargetID:    targetID,
+			ReportingID: reportingID,
+			Info:        infoBytes,
+		})
 	})
 
 	const insertEventTableStmt = `
@@ -115,11 +156,7 @@ VALUES(
 		reportingID,
 		nil, // info
 	}
-	if info != nil {
-		infoBytes, err := json.Marshal(info)
-		if err != nil {
-			return err
-		}
+	if infoBytes != nil {
 		args[3] = string(infoBytes)
 	}
 
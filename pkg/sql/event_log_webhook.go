@@ -0,0 +1,108 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+var eventLogWebhookURL = settings.RegisterValidatedStringSetting(
+	"server.eventlog.webhook_url",
+	"if set, critical events recorded in the event log are posted as JSON to this http(s) URL",
+	"",
+	func(v string) error {
+		if v == "" {
+			return nil
+		}
+		u, err := url.Parse(v)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("invalid webhook URL scheme %q; expected http or https", u.Scheme)
+		}
+		return nil
+	},
+)
+
+// eventLogWebhookTimeout bounds the time spent posting an event to the
+// webhook.
+const eventLogWebhookTimeout = 10 * time.Second
+
+// criticalEventLogTypes are the types of the events posted to the webhook.
+var criticalEventLogTypes = map[EventLogType]struct{}{
+	EventLogNodeRestart:       {},
+	EventLogStoreDiskSpace:    {},
+	EventLogRangeUnavailable:  {},
+	EventLogReplicaCorruption: {},
+	EventLogCertificateExpiry: {},
+}
+
+// eventLogWebhookEvent is the JSON payload posted to the webhook for an event.
+type eventLogWebhookEvent struct {
+	Timestamp   time.Time       `json:"timestamp"`
+	EventType   EventLogType    `json:"eventType"`
+	TargetID    int32           `json:"targetID"`
+	ReportingID int32           `json:"reportingID"`
+	Info        json.RawMessage `json:"info"`
+}
+
+// maybeNotifyWebhook begins an asynchronous task which posts the event to the
+// webhook if one is set and the event is critical.
+func (ev EventLogger) maybeNotifyWebhook(ctx context.Context, event eventLogWebhookEvent) {
+	webhookURL := eventLogWebhookURL.Get()
+	if webhookURL == "" || ev.LeaseManager == nil || ev.LeaseManager.stopper == nil {
+		return
+	}
+	if _, ok := criticalEventLogTypes[event.EventType]; !ok {
+		return
+	}
+	if err := ev.LeaseManager.stopper.RunAsyncTask(ctx, "eventlog-webhook", func(ctx context.Context) {
+		if err := postEventLogWebhook(webhookURL, event); err != nil {
+			log.Warningf(ctx, "unable to post %s event to webhook: %s", event.EventType, err)
+		}
+	}); err != nil {
+		log.Warningf(ctx, "unable to post %s event to webhook: %s", event.EventType, err)
+	}
+}
+
+// postEventLogWebhook posts the event as JSON to the given URL.
+func postEventLogWebhook(webhookURL string, event eventLogWebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: eventLogWebhookTimeout}
+	resp, err := client.Post(webhookURL, httputil.JSONContentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
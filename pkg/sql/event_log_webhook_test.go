@@ -0,0 +1,67 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPostEventLogWebhook(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	received := make(chan eventLogWebhookEvent, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event eventLogWebhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received <- event
+	}))
+	defer ts.Close()
+
+	event := eventLogWebhookEvent{
+		Timestamp:   time.Unix(100, 0).UTC(),
+		EventType:   EventLogRangeUnavailable,
+		TargetID:    2,
+		ReportingID: 1,
+		Info:        json.RawMessage(`{"StoreID":2,"RangeIDs":[5]}`),
+	}
+	if err := postEventLogWebhook(ts.URL, event); err != nil {
+		t.Fatal(err)
+	}
+	got := <-received
+	if !got.Timestamp.Equal(event.Timestamp) || got.EventType != event.EventType ||
+		got.TargetID != event.TargetID || got.ReportingID != event.ReportingID ||
+		string(got.Info) != string(event.Info) {
+		t.Errorf("expected %+v, got %+v", event, got)
+	}
+
+	// Errors of the webhook are returned.
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if err := postEventLogWebhook(failing.URL, event); !testutils.IsError(err, "503") {
+		t.Errorf("expected 503 error, got %v", err)
+	}
+}
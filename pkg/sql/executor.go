@@ -18,6 +18,7 @@
 package sql
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -117,6 +118,21 @@ var (
 	MetaQuery = metric.Metadata{
 		Name: "sql.query.count",
 		Help: "Number of SQL queries"}
+	MetaSelectLatency = metric.Metadata{
+		Name: "sql.select.latency",
+		Help: "Service latency of SQL SELECT statements"}
+	MetaUpdateLatency = metric.Metadata{
+		Name: "sql.update.latency",
+		Help: "Service latency of SQL UPDATE statements"}
+	MetaInsertLatency = metric.Metadata{
+		Name: "sql.insert.latency",
+		Help: "Service latency of SQL INSERT statements"}
+	MetaDeleteLatency = metric.Metadata{
+		Name: "sql.delete.latency",
+		Help: "Service latency of SQL DELETE statements"}
+	MetaDdlLatency = metric.Metadata{
+		Name: "sql.ddl.latency",
+		Help: "Service latency of SQL DDL statements"}
 )
 
 type traceResult struct {
@@ -214,6 +230,14 @@ type Executor struct {
 	MiscCount        *metric.Counter
 	QueryCount       *metric.Counter
 
+	// Service latencies broken down by statement type, so that quantiles
+	// can be computed for each type from the exported histograms.
+	SelectLatency *metric.Histogram
+	UpdateLatency *metric.Histogram
+	InsertLatency *metric.Histogram
+	DeleteLatency *metric.Histogram
+	DdlLatency    *metric.Histogram
+
 	// System Config and mutex.
 	systemConfig config.SystemConfig
 	// databaseCache is updated with systemConfigMu held, but read atomically in
@@ -227,6 +251,12 @@ type Executor struct {
 	// Application-level SQL statistics
 	sqlStats sqlStats
 
+	// Ranges of values of sequences reserved by this node.
+	sequenceCache sequenceCache
+
+	// Resources used by the sessions of each user on this node.
+	userLimits userLimits
+
 	// Attempts to use unimplemented features.
 	unimplementedErrors struct {
 		syncutil.Mutex
@@ -248,6 +278,7 @@ type ExecutorConfig struct {
 	RPCContext      *rpc.Context
 	LeaseManager    *LeaseManager
 	Clock           *hlc.Clock
+	Stopper         *stop.Stopper
 	DistSQLSrv      *distsqlrun.ServerImpl
 	StatusServer    serverpb.StatusServer
 	SessionRegistry *SessionRegistry
@@ -346,7 +377,17 @@ func NewExecutor(cfg ExecutorConfig, stopper *stop.Stopper) *Executor {
 		DdlCount:    metric.NewCounter(MetaDdl),
 		MiscCount:   metric.NewCounter(MetaMisc),
 		QueryCount:  metric.NewCounter(MetaQuery),
-		sqlStats:    sqlStats{apps: make(map[string]*appStats)},
+		SelectLatency: metric.NewLatency(MetaSelectLatency,
+			6*metricsSampleInterval),
+		UpdateLatency: metric.NewLatency(MetaUpdateLatency,
+			6*metricsSampleInterval),
+		InsertLatency: metric.NewLatency(MetaInsertLatency,
+			6*metricsSampleInterval),
+		DeleteLatency: metric.NewLatency(MetaDeleteLatency,
+			6*metricsSampleInterval),
+		DdlLatency: metric.NewLatency(MetaDdlLatency,
+			6*metricsSampleInterval),
+		sqlStats: sqlStats{apps: make(map[string]*appStats)},
 	}
 }
 
@@ -752,6 +793,9 @@ func (e *Executor) execParsed(
 
 			if protoTS != nil {
 				txnState.mu.txn.SetFixedTimestamp(*protoTS)
+				// The reads of explicit historical queries may be served by
+				// the nearest replica, if old enough.
+				txnState.mu.txn.SetFollowerReads()
 			}
 
 			var err error
@@ -1392,8 +1436,48 @@ func (e *Executor) execStmtInOpenTxn(
 		return Result{PGTag: s.StatementTag()}, nil
 	}
 
-	var p *planner
 	runInParallel := parallelize && !implicitTxn
+
+	// Run the statement with a context canceled by CANCEL QUERY or once the
+	// statement runs for longer than statement_timeout, including the time
+	// spent waiting for a statement slot. Parallelized statements can't be
+	// canceled, as they keep running after this function returns.
+	if !runInParallel && stmt.queryHandle != nil {
+		ctx, cancelQuery := context.WithCancel(session.Ctx())
+		defer cancelQuery()
+		session.setQueryCancelFunc(stmt.queryHandle, cancelQuery)
+		if timeout := session.StatementTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		defer session.hijackCtx(ctx)()
+		defer func() {
+			if err == nil {
+				return
+			}
+			if session.isQueryCanceled(stmt.queryHandle) {
+				err = pgerror.NewError(pgerror.CodeQueryCanceledError,
+					"canceling statement due to user request")
+			} else if ctx.Err() == context.DeadlineExceeded {
+				err = pgerror.NewError(pgerror.CodeQueryCanceledError,
+					"canceling statement due to statement timeout")
+			}
+		}()
+	}
+
+	// Wait for the other statements of the user to leave room for this one.
+	// The transaction control statements above are not limited, so that a
+	// session can always end its transaction.
+	releaseSlot := func() {}
+	if ur := session.userResources; ur != nil {
+		if err := ur.acquireStatementSlot(session.Ctx()); err != nil {
+			return Result{}, err
+		}
+		releaseSlot = ur.releaseStatementSlot
+	}
+
+	var p *planner
 	if runInParallel {
 		// Create a new planner from the Session to execute the statement, since
 		// we're executing in parallel.
@@ -1423,8 +1507,9 @@ func (e *Executor) execStmtInOpenTxn(
 		// statements outside of a transaction are run synchronously with mocked
 		// results, which has the same effect as running asynchronously but
 		// immediately blocking.
-		result, err = e.execStmtInParallel(stmt, p)
+		result, err = e.execStmtInParallel(stmt, p, releaseSlot)
 	} else {
+		defer releaseSlot()
 		p.autoCommit = implicitTxn && !e.cfg.TestingKnobs.DisableAutoCommit
 		result, err = e.execStmt(stmt, p,
 			automaticRetryCount, parallelize /* mockResults */)
@@ -1644,6 +1729,12 @@ func (e *Executor) shouldUseDistSQL(planner *planner, plan planNode) (bool, erro
 		return false, nil
 	}
 
+	if distSQLMode == DistSQLAuto && planner.session.Vectorize &&
+		isVectorized(planner.session.Ctx(), plan) {
+		log.VEventf(planner.session.Ctx(), 1, "running vectorized query locally")
+		return false, nil
+	}
+
 	if distSQLMode == DistSQLAuto && !distribute {
 		log.VEventf(planner.session.Ctx(), 1, "not distributing query")
 		return false, nil
@@ -1678,8 +1769,21 @@ func makeRes(stmt Statement, planner *planner, plan planNode) (Result, error) {
 // statement's result type, identical to the mock results returned by execStmtInParallel.
 func (e *Executor) execStmt(
 	stmt Statement, planner *planner, automaticRetryCount int, mockResults bool,
-) (Result, error) {
+) (_ Result, err error) {
 	session := planner.session
+	// Privileged operations are audited whether they succeed or not,
+	// including when they fail because of a panic, recovered below.
+	defer func() { session.auditStatement(stmt, err) }()
+
+	var result Result
+	if recoverStatementPanics.Get() {
+		defer func() {
+			if r := recover(); r != nil {
+				result.Close(session.Ctx())
+				err = convertStatementPanic(session.Ctx(), stmt, r)
+			}
+		}()
+	}
 
 	planner.phaseTimes[plannerStartLogicalPlan] = timeutil.Now()
 	plan, err := planner.makePlan(session.Ctx(), stmt)
@@ -1690,7 +1794,7 @@ func (e *Executor) execStmt(
 
 	defer plan.Close(session.Ctx())
 
-	result, err := makeRes(stmt, planner, plan)
+	result, err = makeRes(stmt, planner, plan)
 	if err != nil {
 		return Result{}, err
 	}
@@ -1710,7 +1814,7 @@ func (e *Executor) execStmt(
 	}
 	planner.phaseTimes[plannerEndExecStmt] = timeutil.Now()
 	e.recordStatementSummary(
-		planner, stmt, useDistSQL, automaticRetryCount, result, err,
+		planner, stmt, plan, useDistSQL, automaticRetryCount, result, err,
 	)
 	if err != nil {
 		result.Close(session.Ctx())
@@ -1724,29 +1828,68 @@ func (e *Executor) execStmt(
 	return result, nil
 }
 
+// recoverStatementPanics controls whether a panic during the execution
+// of a statement fails the statement, or crashes the node.
+var recoverStatementPanics = settings.RegisterBoolSetting(
+	"sql.panic_recovery.enabled",
+	"set to fail statements with an internal error when their execution panics, instead of crashing the node",
+	true,
+)
+
+// maxReportedStmtLen bounds the length of the statement fingerprints with
+// which crash reports are tagged.
+const maxReportedStmtLen = 200
+
+// statementPanicMsg is the message of the error with which the statements
+// whose execution panicked fail. It is fixed, as the panic value may contain
+// datums or keys: the panic is only detailed in the log and crash report.
+const statementPanicMsg = "internal error while executing the statement; see the server log for details"
+
+// convertStatementPanic logs and reports the panic r, recovered during the
+// execution of stmt, and returns the internal error with which the
+// statement fails. The report is tagged with the fingerprint of the
+// statement, in which constants are hidden.
+func convertStatementPanic(ctx context.Context, stmt Statement, r interface{}) error {
+	var buf bytes.Buffer
+	parser.FormatNode(&buf, parser.FmtHideConstants, stmt.AST)
+	fingerprint := buf.String()
+	if len(fingerprint) > maxReportedStmtLen {
+		fingerprint = fingerprint[:maxReportedStmtLen]
+	}
+	log.ReportRecoveredPanic(log.WithReportTag(ctx, "statement", fingerprint), r, 1)
+	return pgerror.NewError(pgerror.CodeInternalError, statementPanicMsg)
+}
+
 // execStmtInParallel executes the statement asynchronously and returns mocked out
 // results. These mocked out results will be the "zero value" of the statement's
 // result type:
 // - parser.Rows -> an empty set of rows
 // - parser.RowsAffected -> zero rows affected
 //
+// releaseSlot is called once the statement has finished executing.
+//
 // TODO(nvanbenschoten): We do not currently support parallelizing distributed SQL
 // queries, so this method can only be used with classical SQL.
-func (e *Executor) execStmtInParallel(stmt Statement, planner *planner) (Result, error) {
+func (e *Executor) execStmtInParallel(
+	stmt Statement, planner *planner, releaseSlot func(),
+) (Result, error) {
 	session := planner.session
 	ctx := session.Ctx()
 
 	plan, err := planner.makePlan(ctx, stmt)
 	if err != nil {
+		releaseSlot()
 		return Result{}, err
 	}
 
 	mockResult, err := makeRes(stmt, planner, plan)
 	if err != nil {
+		releaseSlot()
 		return Result{}, err
 	}
 
 	session.parallelizeQueue.Add(ctx, plan, func(plan planNode) error {
+		defer releaseSlot()
 		defer plan.Close(ctx)
 
 		result, err := makeRes(stmt, planner, plan)
@@ -1758,7 +1901,7 @@ func (e *Executor) execStmtInParallel(stmt Statement, planner *planner) (Result,
 		planner.phaseTimes[plannerStartExecStmt] = timeutil.Now()
 		err = e.execClassic(planner, plan, &result)
 		planner.phaseTimes[plannerEndExecStmt] = timeutil.Now()
-		e.recordStatementSummary(planner, stmt, false, 0, result, err)
+		e.recordStatementSummary(planner, stmt, plan, false, 0, result, err)
 		return err
 	})
 	return mockResult, nil
@@ -1792,6 +1935,26 @@ func (e *Executor) updateStmtCounts(stmt Statement) {
 	}
 }
 
+// stmtLatencyHistogram returns the histogram tracking the service latency
+// of the statements of the type of stmt, or nil if that type has no
+// dedicated histogram.
+func (e *Executor) stmtLatencyHistogram(stmt Statement) *metric.Histogram {
+	switch stmt.AST.(type) {
+	case *parser.Select:
+		return e.SelectLatency
+	case *parser.Update:
+		return e.UpdateLatency
+	case *parser.Insert:
+		return e.InsertLatency
+	case *parser.Delete:
+		return e.DeleteLatency
+	}
+	if stmt.AST.StatementType() == parser.DDL {
+		return e.DdlLatency
+	}
+	return nil
+}
+
 // golangFillQueryArguments populates the placeholder map with
 // types and values from an array of Go values.
 // TODO: This does not support arguments of the SQL 'Date' type, as there is not
@@ -1870,6 +2033,7 @@ func checkResultType(typ parser.Type) error {
 	case parser.TypeTimestampTZ:
 	case parser.TypeInterval:
 	case parser.TypeUUID:
+	case parser.TypeJSON:
 	case parser.TypeStringArray:
 	case parser.TypeNameArray:
 	case parser.TypeIntArray:
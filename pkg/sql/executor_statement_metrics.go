@@ -18,6 +18,7 @@ package sql
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
@@ -61,9 +62,18 @@ const (
 // phaseTimes is the type of the session.phaseTimes array.
 type phaseTimes [sessionNumPhases]time.Time
 
+// slowQueryLatencyThreshold is the service latency above which
+// statements are recorded in the slow query log.
+var slowQueryLatencyThreshold = settings.RegisterDurationSetting(
+	"sql.log.slow_query.latency_threshold",
+	"when non-zero, record the statements whose service latency exceeds this threshold, anonymized, in the slow query log files",
+	0,
+)
+
 // recordStatementSummery gathers various details pertaining to the
 // last executed statement/query and performs the associated
 // accounting.
+// - plan is the plan of the statement, which is still open.
 // - distSQLUsed reports whether the query was distributed.
 // - automaticRetryCount is the count of implicit txn retries
 //   so far.
@@ -72,6 +82,7 @@ type phaseTimes [sessionNumPhases]time.Time
 func (e *Executor) recordStatementSummary(
 	planner *planner,
 	stmt Statement,
+	plan planNode,
 	distSQLUsed bool,
 	automaticRetryCount int,
 	result Result,
@@ -116,6 +127,9 @@ func (e *Executor) recordStatementSummary(
 			e.SQLExecLatency.RecordValue(runLatRaw.Nanoseconds())
 			e.SQLServiceLatency.RecordValue(svcLatRaw.Nanoseconds())
 		}
+		if h := e.stmtLatencyHistogram(stmt); h != nil {
+			h.RecordValue(svcLatRaw.Nanoseconds())
+		}
 	}
 
 	planner.session.appStats.recordStatement(
@@ -123,6 +137,18 @@ func (e *Executor) recordStatementSummary(
 		parseLat, planLat, runLat, svcLat, execOverhead,
 	)
 
+	if threshold := slowQueryLatencyThreshold.Get(); threshold > 0 && svcLatRaw > threshold {
+		log.LogSlowQuery(planner.session.Ctx(), log.SlowQueryEvent{
+			Statement:    parser.AsStringWithFlags(stmt.AST, parser.FmtHideConstants),
+			Plan:         planToAnonymizedString(planner.session.Ctx(), plan),
+			Rows:         numRows,
+			LatencyNanos: svcLatRaw.Nanoseconds(),
+			DistSQL:      distSQLUsed,
+			Retries:      automaticRetryCount,
+			Failed:       err != nil,
+		})
+	}
+
 	if log.V(2) {
 		// ages since significant epochs
 		batchAge := phaseTimes[plannerEndExecStmt].
@@ -18,6 +18,7 @@ package sql
 
 import (
 	gosql "database/sql"
+	"strings"
 	"sync/atomic"
 	"testing"
 
@@ -132,3 +133,29 @@ func TestPrepareCanAcquireLeases(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// Test that a panic during the execution of a statement fails the
+// statement rather than the node.
+func TestStatementPanicRecovery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// DistSQL evaluates expressions in processors, which do not recover.
+	// The setting is per connection.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("SET DISTSQL = off"); err != nil {
+		t.Fatal(err)
+	}
+	// The panic value is not returned to the client.
+	if _, err := db.Exec("SELECT crdb_internal.force_panic('boom')"); !testutils.IsError(
+		err, "internal error while executing the statement",
+	) || strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+	var one int
+	if err := db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -224,13 +224,19 @@ func doExpandPlan(
 		n.rows, err = doExpandPlan(ctx, p, noParams, n.rows)
 
 	case *valuesNode:
+	case *alterSequenceNode:
 	case *alterTableNode:
+	case *cancelQueryNode:
+	case *cancelSessionNode:
+	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSequenceNode:
 	case *createUserNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSequenceNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
@@ -240,6 +246,7 @@ func doExpandPlan(
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *scrubNode:
 	case nil:
 
 	default:
@@ -509,13 +516,19 @@ func simplifyOrderings(plan planNode, usefulOrdering sqlbase.ColumnOrdering) pla
 		n.rows = simplifyOrderings(n.rows, nil)
 
 	case *valuesNode:
+	case *alterSequenceNode:
 	case *alterTableNode:
+	case *cancelQueryNode:
+	case *cancelSessionNode:
+	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSequenceNode:
 	case *createUserNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSequenceNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
@@ -525,6 +538,7 @@ func simplifyOrderings(plan planNode, usefulOrdering sqlbase.ColumnOrdering) pla
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *scrubNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -56,6 +56,7 @@ var explainStrings = map[explainMode]string{
 func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, error) {
 	mode := explainNone
 
+	analyze := false
 	optimized := true
 	expanded := true
 	normalizeExprs := true
@@ -85,6 +86,9 @@ func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, err
 				// TYPES implies METADATA.
 				explainer.showMetadata = true
 
+			case "analyze":
+				analyze = true
+
 			case "indent":
 				explainer.doIndent = true
 
@@ -131,6 +135,18 @@ func (p *planner) Explain(ctx context.Context, n *parser.Explain) (planNode, err
 	if mode == explainNone {
 		mode = explainPlan
 	}
+	if analyze {
+		// ANALYZE executes the plan, which must therefore be expanded and
+		// optimized like the plan of the statement itself would be.
+		if mode != explainPlan {
+			return nil, fmt.Errorf("cannot use EXPLAIN ANALYZE with mode %s",
+				strings.ToUpper(explainStrings[mode]))
+		}
+		if !expanded || !optimized {
+			return nil, fmt.Errorf("cannot use EXPLAIN ANALYZE with NOEXPAND or NOOPTIMIZE")
+		}
+		explainer.analyze = true
+	}
 
 	p.evalCtx.SkipNormalize = !normalizeExprs
 
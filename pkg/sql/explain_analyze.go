@@ -0,0 +1,181 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/mon"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// planNodeStats are the statistics collected about the execution of a
+// planNode for EXPLAIN ANALYZE.
+type planNodeStats struct {
+	// rows is the number of rows produced by the node.
+	rows int64
+
+	// duration is the time spent in the Start() and Next() calls of the
+	// node, including the time spent in its children.
+	duration time.Duration
+
+	// maxMemory is the peak growth of the memory accounted for in the
+	// transaction's monitor since the node was started, as observed at
+	// the end of each call to the node. Like duration, it includes the
+	// memory used by the children of the node.
+	maxMemory int64
+}
+
+// analyzeColumns are the columns appended to the output of EXPLAIN
+// ANALYZE. They are NULL for the rows which do not describe a node, or
+// which describe a node which was not instrumented.
+var analyzeColumns = sqlbase.ResultColumns{
+	// Rows is the number of rows produced by the node.
+	{Name: "Rows", Typ: parser.TypeInt},
+	// Time is the time spent executing the node and its children.
+	{Name: "Time", Typ: parser.TypeString},
+	// Memory is the peak memory used by the node and its children.
+	{Name: "Memory", Typ: parser.TypeString},
+}
+
+func (s *planNodeStats) AsRow() parser.Datums {
+	return parser.Datums{
+		parser.NewDInt(parser.DInt(s.rows)),
+		parser.NewDString(fmt.Sprintf("%.3fms", s.duration.Seconds()*1000)),
+		parser.NewDString(humanizeutil.IBytes(s.maxMemory)),
+	}
+}
+
+// instrumentedNode is a planNode that wraps another node and collects
+// statistics about its execution. It is transparent to walkPlan(), so
+// that the statistics can be reported next to the description of the
+// wrapped node.
+type instrumentedNode struct {
+	plan  planNode
+	stats *planNodeStats
+	mon   *mon.MemoryMonitor
+
+	// startMem is the memory allocated in mon when the node was started.
+	startMem int64
+}
+
+// instrumentPlan wraps the nodes of an expanded plan with
+// instrumentedNodes recording their statistics in stats, and returns
+// the new root of the plan.
+//
+// Only the children held in planNode fields can be instrumented, which
+// leaves out the table readers of index joins. The sources of mutations
+// are not instrumented either, as they are inspected by the fast paths
+// of the mutations when these are started.
+func instrumentPlan(
+	plan planNode, stats map[planNode]*planNodeStats, monitor *mon.MemoryMonitor,
+) planNode {
+	switch n := plan.(type) {
+	case *filterNode:
+		n.source.plan = instrumentPlan(n.source.plan, stats, monitor)
+	case *renderNode:
+		n.source.plan = instrumentPlan(n.source.plan, stats, monitor)
+	case *joinNode:
+		n.left.plan = instrumentPlan(n.left.plan, stats, monitor)
+		n.right.plan = instrumentPlan(n.right.plan, stats, monitor)
+	case *limitNode:
+		n.plan = instrumentPlan(n.plan, stats, monitor)
+	case *distinctNode:
+		n.plan = instrumentPlan(n.plan, stats, monitor)
+	case *sortNode:
+		n.plan = instrumentPlan(n.plan, stats, monitor)
+	case *groupNode:
+		n.plan = instrumentPlan(n.plan, stats, monitor)
+	case *windowNode:
+		n.plan = instrumentPlan(n.plan, stats, monitor)
+	case *unionNode:
+		n.right = instrumentPlan(n.right, stats, monitor)
+		n.left = instrumentPlan(n.left, stats, monitor)
+	case *ordinalityNode:
+		n.source = instrumentPlan(n.source, stats, monitor)
+	}
+
+	s := &planNodeStats{}
+	stats[plan] = s
+	return &instrumentedNode{plan: plan, stats: s, mon: monitor}
+}
+
+func (n *instrumentedNode) Columns() sqlbase.ResultColumns { return n.plan.Columns() }
+func (n *instrumentedNode) Ordering() orderingInfo         { return n.plan.Ordering() }
+func (n *instrumentedNode) Values() parser.Datums          { return n.plan.Values() }
+func (n *instrumentedNode) DebugValues() debugValues       { return n.plan.DebugValues() }
+func (n *instrumentedNode) MarkDebug(mode explainMode)     { n.plan.MarkDebug(mode) }
+func (n *instrumentedNode) Close(ctx context.Context)      { n.plan.Close(ctx) }
+
+func (n *instrumentedNode) Spans(ctx context.Context) (_, _ roachpb.Spans, _ error) {
+	return n.plan.Spans(ctx)
+}
+
+func (n *instrumentedNode) Start(ctx context.Context) error {
+	if n.mon != nil {
+		n.startMem = n.mon.AllocBytes()
+	}
+	start := timeutil.Now()
+	err := n.plan.Start(ctx)
+	n.record(start)
+	return err
+}
+
+func (n *instrumentedNode) Next(ctx context.Context) (bool, error) {
+	start := timeutil.Now()
+	next, err := n.plan.Next(ctx)
+	if next {
+		n.stats.rows++
+	}
+	n.record(start)
+	return next, err
+}
+
+// record updates the statistics of the node at the end of a call which
+// began at the given time.
+func (n *instrumentedNode) record(start time.Time) {
+	n.stats.duration += timeutil.Since(start)
+	if n.mon != nil {
+		if mem := n.mon.AllocBytes() - n.startMem; mem > n.stats.maxMemory {
+			n.stats.maxMemory = mem
+		}
+	}
+}
+
+// analyze executes the plan of an EXPLAIN ANALYZE statement to
+// completion, collecting the statistics displayed next to its nodes.
+func (e *explainPlanNode) analyze(ctx context.Context) error {
+	e.explainer.stats = make(map[planNode]*planNodeStats)
+	e.plan = instrumentPlan(e.plan, e.explainer.stats, e.p.evalCtx.Mon)
+	if err := e.plan.Start(ctx); err != nil {
+		return err
+	}
+	for {
+		next, err := e.plan.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if !next {
+			return nil
+		}
+	}
+}
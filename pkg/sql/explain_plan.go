@@ -59,6 +59,14 @@ type explainer struct {
 	// with leading white spaces.
 	doIndent bool
 
+	// analyze indicates whether the plan is executed, so that the output
+	// has columns for the statistics collected about each node.
+	analyze bool
+
+	// stats holds the statistics collected about the nodes of the plan
+	// when it is executed for EXPLAIN ANALYZE.
+	stats map[planNode]*planNodeStats
+
 	// makeRow produces one row of EXPLAIN output.
 	makeRow func(level int, typ, field, desc string, plan planNode)
 
@@ -86,6 +94,9 @@ func (p *planner) makeExplainPlanNode(
 		// Ordering indicates the known ordering of the data from this source.
 		columns = append(columns, sqlbase.ResultColumn{Name: "Ordering", Typ: parser.TypeString})
 	}
+	if explainer.analyze {
+		columns = append(columns, analyzeColumns...)
+	}
 
 	explainer.fmtFlags = parser.FmtExpr(
 		parser.FmtSimple, explainer.showTypes, explainer.symbolicVars, explainer.qualifyNames,
@@ -128,6 +139,13 @@ func (p *planner) populateExplain(
 				row = append(row, emptyString, emptyString)
 			}
 		}
+		if e.analyze {
+			if s, ok := e.stats[plan]; ok {
+				row = append(row, s.AsRow()...)
+			} else {
+				row = append(row, parser.DNull, parser.DNull, parser.DNull)
+			}
+		}
 		if _, err := v.rows.AddRow(ctx, row); err != nil {
 			e.err = err
 		}
@@ -164,6 +182,34 @@ func planToString(ctx context.Context, plan planNode) string {
 	return buf.String()
 }
 
+// anonymizedPlanAttrs are the attributes of the nodes of a plan which
+// describe the tables and algorithms it uses, as opposed to those which
+// may contain the constants of the statement, such as the spans.
+var anonymizedPlanAttrs = map[string]bool{
+	"table": true,
+	"from":  true,
+	"type":  true,
+	"hint":  true,
+}
+
+// planToAnonymizedString builds a string representation of the planNode
+// like planToString, but without the expressions and the attributes
+// which may contain user data.
+func planToAnonymizedString(ctx context.Context, plan planNode) string {
+	var buf bytes.Buffer
+	e := explainer{
+		makeRow: func(level int, name, field, description string, plan planNode) {
+			if field == "" {
+				fmt.Fprintf(&buf, "%d %s\n", level, name)
+			} else if anonymizedPlanAttrs[field] {
+				fmt.Fprintf(&buf, "%d .%s %s\n", level, field, description)
+			}
+		},
+	}
+	_ = walkPlan(ctx, plan, e.observer())
+	return buf.String()
+}
+
 func (e *explainer) observer() planObserver {
 	return planObserver{
 		enterNode: e.enterNode,
@@ -285,9 +331,14 @@ func (e *explainPlanNode) Spans(ctx context.Context) (_, _ roachpb.Spans, _ erro
 }
 
 func (e *explainPlanNode) Start(ctx context.Context) error {
-	// Note that we don't call start on e.plan. That's on purpose, Start() can
-	// have side effects. And it's supposed to not be needed for the way in which
-	// we're going to use e.plan.
+	// Note that we don't call start on e.plan, unless the plan is to be
+	// analyzed. That's on purpose, Start() can have side effects. And it's
+	// supposed to not be needed for the way in which we're going to use e.plan.
+	if e.explainer.analyze {
+		if err := e.analyze(ctx); err != nil {
+			return err
+		}
+	}
 	return e.p.populateExplain(ctx, &e.explainer, e.results, e.plan)
 }
 
@@ -0,0 +1,50 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPlanToAnonymizedString(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sql := `SELECT x FROM (VALUES (1, 'secret'), (2, 'other')) AS a(x, y)
+WHERE y = 'secret' ORDER BY x LIMIT 77777`
+	p := makeTestPlanner()
+	stmts, err := p.parser.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := p.makePlan(context.TODO(), Statement{AST: stmts[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plan.Close(context.TODO())
+
+	s := planToAnonymizedString(context.TODO(), plan)
+	if !strings.Contains(s, "values") {
+		t.Errorf("expected the plan to include its nodes, got:\n%s", s)
+	}
+	for _, c := range []string{"secret", "other", "77777"} {
+		if strings.Contains(s, c) {
+			t.Errorf("expected the plan not to include %q, got:\n%s", c, s)
+		}
+	}
+}
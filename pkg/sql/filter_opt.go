@@ -317,14 +317,20 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *alterSequenceNode:
 	case *alterTableNode:
+	case *cancelQueryNode:
+	case *cancelSessionNode:
+	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSequenceNode:
 	case *createUserNode:
 	case *delayedNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSequenceNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
@@ -334,6 +340,7 @@ func (p *planner) propagateFilters(
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *scrubNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
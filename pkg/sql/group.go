@@ -301,6 +301,10 @@ type groupNode struct {
 	needOnlyOneRow  bool
 	gotOneRow       bool
 
+	// vec is set if the aggregation runs on the vectorized execution engine,
+	// in which case it reads the rows from the scanNode underneath directly.
+	vec *vecAggregation
+
 	explain explainMode
 }
 
@@ -322,6 +326,8 @@ func (n *groupNode) MarkDebug(mode explainMode) {
 		panic(fmt.Sprintf("unknown debug mode %d", mode))
 	}
 	n.explain = mode
+	// The vectorized execution doesn't produce debug values.
+	n.vec = nil
 	n.plan.MarkDebug(mode)
 }
 
@@ -352,6 +358,18 @@ func (n *groupNode) Start(ctx context.Context) error {
 }
 
 func (n *groupNode) Next(ctx context.Context) (bool, error) {
+	if n.vec != nil {
+		if n.populated {
+			return false, nil
+		}
+		n.populated = true
+		n.values = make(parser.Datums, len(n.funcs))
+		if err := n.vec.run(ctx, n.values); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
 	var scratch []byte
 	// We're going to consume n.plan until it's exhausted (feeding all the rows to
 	// n.funcs), and then call n.setupOutput.
@@ -167,7 +167,11 @@ func (p *planner) makeIndexJoin(
 		if !ok {
 			panic(fmt.Sprintf("Unknown column %d in index!", colID))
 		}
-		valProvidedIndex[idx] = true
+		// The values of the column of an inverted index can't be decoded
+		// from its keys.
+		if indexScan.index.Type == sqlbase.IndexDescriptor_FORWARD {
+			valProvidedIndex[idx] = true
+		}
 		colIDtoRowIndex[colID] = idx
 	}
 
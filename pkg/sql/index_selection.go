@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/pkg/errors"
 )
@@ -115,6 +116,11 @@ func (p *planner) selectIndex(
 			index: &s.desc.PrimaryIndex,
 		})
 		for i := range s.desc.Indexes {
+			if s.desc.Indexes[i].Type == sqlbase.IndexDescriptor_INVERTED {
+				// Inverted indexes are only candidates for the filters they can
+				// constrain, see below.
+				continue
+			}
 			candidates = append(candidates, &indexInfo{
 				desc:  &s.desc,
 				index: &s.desc.Indexes[i],
@@ -165,10 +171,34 @@ func (p *planner) selectIndex(
 		// use.
 
 		for _, c := range candidates {
+			if c.index.Type == sqlbase.IndexDescriptor_INVERTED {
+				continue
+			}
 			c.analyzeExprs(exprs)
 		}
 	}
 
+	if s.specifiedIndex == nil {
+		for i := range s.desc.Indexes {
+			if s.desc.Indexes[i].Type != sqlbase.IndexDescriptor_INVERTED {
+				continue
+			}
+			c := &indexInfo{
+				desc:  &s.desc,
+				index: &s.desc.Indexes[i],
+			}
+			c.init(s)
+			if c.analyzeInvertedFilter(&p.evalCtx, s.filter) {
+				candidates = append(candidates, c)
+			}
+		}
+	} else if s.specifiedIndex.Type == sqlbase.IndexDescriptor_INVERTED {
+		if !candidates[0].analyzeInvertedFilter(&p.evalCtx, s.filter) {
+			return nil, fmt.Errorf("index \"%s\" is inverted and cannot be used for this query",
+				s.specifiedIndex.Name)
+		}
+	}
+
 	if s.noIndexJoin {
 		// Eliminate non-covering indexes. We do this after the check above for
 		// constant false filter.
@@ -212,6 +242,13 @@ func (p *planner) selectIndex(
 	s.index = c.index
 	s.specifiedIndex = nil
 	s.isSecondaryIndex = (c.index != &s.desc.PrimaryIndex)
+	if c.invertedSpan.Key != nil {
+		// The span of an inverted index only restricts the rows to those which
+		// may satisfy the filter: the whole filter still needs to be applied.
+		s.spans = roachpb.Spans{c.invertedSpan}
+		plan, _ := s.p.makeIndexJoin(s, 0 /* exactPrefix */)
+		return plan, nil
+	}
 	var err error
 	s.spans, err = makeSpans(c.constraints, c.desc, c.index)
 	if err != nil {
@@ -342,6 +379,9 @@ type indexInfo struct {
 	covering    bool // Does the index cover the required IndexedVars?
 	reverse     bool
 	exactPrefix int
+	// invertedSpan is the span of an inverted index containing the rows which
+	// may satisfy the filter; see analyzeInvertedFilter.
+	invertedSpan roachpb.Span
 }
 
 func (v *indexInfo) init(s *scanNode) {
@@ -400,6 +440,49 @@ func (v *indexInfo) analyzeExprs(exprs []parser.TypedExprs) {
 	}
 }
 
+// analyzeInvertedFilter looks for a conjunction of the filter of the form
+// `col @> <json>`, where col is the column of the inverted index, and sets
+// invertedSpan to the span of the index keys of a path which any document
+// containing <json> has. It returns false if there is no such conjunction.
+func (v *indexInfo) analyzeInvertedFilter(
+	evalCtx *parser.EvalContext, filter parser.TypedExpr,
+) bool {
+	if filter == nil {
+		return false
+	}
+	colID := v.index.ColumnIDs[0]
+	for _, e := range splitAndExpr(evalCtx, filter, nil) {
+		c, ok := e.(*parser.ComparisonExpr)
+		if !ok {
+			continue
+		}
+		left, right := c.TypedLeft(), c.TypedRight()
+		switch c.Operator {
+		case parser.Contains:
+		case parser.ContainedBy:
+			left, right = right, left
+		default:
+			continue
+		}
+		ok, colIdx := getColVarIdx(left)
+		if !ok || v.desc.Columns[colIdx].ID != colID {
+			continue
+		}
+		d, ok := right.(*parser.DJSON)
+		if !ok {
+			continue
+		}
+		prefix := sqlbase.MakeIndexKeyPrefix(v.desc, v.index.ID)
+		key, ok := json.EncodeContainingInvertedIndexKey(prefix, d.JSON)
+		if !ok {
+			continue
+		}
+		v.invertedSpan = roachpb.Span{Key: key, EndKey: roachpb.Key(key).PrefixEnd()}
+		return true
+	}
+	return false
+}
+
 // analyzeOrdering analyzes the ordering provided by the index and determines
 // if it matches the ordering requested by the query. Non-matching orderings
 // increase the cost of using the index.
@@ -796,6 +879,12 @@ func (v *indexInfo) isCoveringIndex(scan *scanNode) bool {
 		return true
 	}
 
+	if v.index.Type == sqlbase.IndexDescriptor_INVERTED {
+		// The JSON documents can't be decoded from the keys of the index, and
+		// the scan always needs them to apply the filter.
+		return false
+	}
+
 	for i, needed := range scan.valNeededForCol {
 		if needed {
 			colID := v.desc.Columns[i].ID
@@ -482,6 +482,7 @@ var (
 	tableTypeSystemView = parser.NewDString("SYSTEM VIEW")
 	tableTypeBaseTable  = parser.NewDString("BASE TABLE")
 	tableTypeView       = parser.NewDString("VIEW")
+	tableTypeSequence   = parser.NewDString("SEQUENCE")
 )
 
 var informationSchemaTablesTable = virtualSchemaTable{
@@ -500,6 +501,8 @@ CREATE TABLE information_schema.tables (
 				tableType = tableTypeSystemView
 			} else if table.IsView() {
 				tableType = tableTypeView
+			} else if table.IsSequence() {
+				tableType = tableTypeSequence
 			}
 			return addRow(
 				defString,                     // table_catalog
@@ -19,6 +19,7 @@ package sql
 import (
 	"fmt"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -35,6 +36,13 @@ type insertNode struct {
 	n            *parser.Insert
 	checkHelper  checkHelper
 
+	// computedCols are the computed columns of the table, whose values are
+	// computed with computeExprs for every row; computedValues is the
+	// container over which computeExprs are evaluated.
+	computedCols   []sqlbase.ColumnDescriptor
+	computeExprs   []parser.TypedExpr
+	computedValues *sqlbase.RowIndexedVarContainer
+
 	insertCols            []sqlbase.ColumnDescriptor
 	insertColIDtoRowIndex map[sqlbase.ColumnID]int
 	tw                    tableWriter
@@ -75,6 +83,10 @@ func (p *planner) Insert(
 			return nil, pgerror.UnimplementedWithIssueErrorf(6637,
 				"RETURNING is not supported with UPSERT")
 		}
+		if !n.OnConflict.DoNothing && en.tableDesc.HasComputedColumns() {
+			return nil, pgerror.Unimplemented("upsert with computed columns",
+				"UPSERT and INSERT ... ON CONFLICT DO UPDATE are not supported on tables with computed columns")
+		}
 	}
 
 	var cols []sqlbase.ColumnDescriptor
@@ -87,10 +99,28 @@ func (p *planner) Insert(
 			return nil, err
 		}
 	}
+	if n.DefaultValues() || len(n.Columns) == 0 {
+		// Computed columns are not part of the implicit list of target
+		// columns; their values are always computed below.
+		cols = withoutComputedColumns(cols)
+	} else {
+		for _, col := range cols {
+			if col.IsComputed() {
+				return nil, sqlbase.CannotWriteToComputedColError(col)
+			}
+		}
+	}
 	// Number of columns expecting an input. This doesn't include the
-	// columns receiving a default value.
+	// columns receiving a default value or a computed value.
 	numInputColumns := len(cols)
 
+	computedValues := &sqlbase.RowIndexedVarContainer{Cols: en.tableDesc.Columns}
+	cols, computedCols, computeExprs, err := sqlbase.ProcessComputedColumns(
+		cols, en.tableDesc, computedValues, p.session.SearchPath)
+	if err != nil {
+		return nil, err
+	}
+
 	cols, defaultExprs, err :=
 		sqlbase.ProcessDefaultColumns(cols, en.tableDesc, &p.parser, &p.evalCtx)
 	if err != nil {
@@ -137,6 +167,7 @@ func (p *planner) Insert(
 	if err != nil {
 		return nil, err
 	}
+	computedValues.Mapping = ri.InsertColIDtoRowIndex
 
 	var tw tableWriter
 	if n.OnConflict == nil {
@@ -178,7 +209,8 @@ func (p *planner) Insert(
 			}
 
 			helper, err := p.makeUpsertHelper(
-				ctx, tn, en.tableDesc, ri.InsertCols, updateCols, updateExprs, conflictIndex)
+				ctx, tn, en.tableDesc, ri.InsertCols, updateCols, updateExprs, n.OnConflict.Where,
+				conflictIndex)
 			if err != nil {
 				return nil, err
 			}
@@ -203,6 +235,9 @@ func (p *planner) Insert(
 		n:                     n,
 		editNodeBase:          en,
 		defaultExprs:          defaultExprs,
+		computedCols:          computedCols,
+		computeExprs:          computeExprs,
+		computedValues:        computedValues,
 		insertCols:            ri.InsertCols,
 		insertColIDtoRowIndex: ri.InsertColIDtoRowIndex,
 		tw: tw,
@@ -268,7 +303,10 @@ func (n *insertNode) Next(ctx context.Context) (bool, error) {
 		return true, nil
 	}
 
-	rowVals, err := GenerateInsertRow(n.defaultExprs, n.insertColIDtoRowIndex, n.insertCols, n.p.evalCtx, n.tableDesc, n.run.rows.Values())
+	rowVals, err := GenerateInsertRow(
+		n.defaultExprs, n.computeExprs, n.insertColIDtoRowIndex, n.insertCols, n.computedCols,
+		n.p.evalCtx, n.tableDesc, n.run.rows.Values(), n.computedValues,
+	)
 	if err != nil {
 		return false, err
 	}
@@ -301,14 +339,18 @@ func (n *insertNode) Next(ctx context.Context) (bool, error) {
 }
 
 // GenerateInsertRow prepares a row tuple for insertion. It fills in default
-// expressions, verifies non-nullable columns, and checks column widths.
+// expressions, computes the values of the computed columns, verifies
+// non-nullable columns, and checks column widths.
 func GenerateInsertRow(
 	defaultExprs []parser.TypedExpr,
+	computeExprs []parser.TypedExpr,
 	insertColIDtoRowIndex map[sqlbase.ColumnID]int,
 	insertCols []sqlbase.ColumnDescriptor,
+	computedCols []sqlbase.ColumnDescriptor,
 	evalCtx parser.EvalContext,
 	tableDesc *sqlbase.TableDescriptor,
 	rowVals parser.Datums,
+	computedValues *sqlbase.RowIndexedVarContainer,
 ) (parser.Datums, error) {
 	// The values for the row may be shorter than the number of columns being
 	// inserted into. Generate default values for those columns using the
@@ -333,6 +375,20 @@ func GenerateInsertRow(
 		}
 	}
 
+	// Compute the values of the computed columns. Computed columns cannot
+	// reference other computed columns, so all the values they depend on are
+	// already in the row.
+	if len(computeExprs) > 0 {
+		computedValues.CurSourceRow = rowVals
+		for i := range computedCols {
+			d, err := computeExprs[i].Eval(&evalCtx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "computed column %q", computedCols[i].Name)
+			}
+			rowVals[insertColIDtoRowIndex[computedCols[i].ID]] = d
+		}
+	}
+
 	// Check to see if NULL is being inserted into any non-nullable column.
 	for _, col := range tableDesc.Columns {
 		if !col.Nullable {
@@ -389,15 +445,32 @@ func (p *planner) processColumns(
 	return cols, nil
 }
 
+// withoutComputedColumns returns the columns which are not computed.
+func withoutComputedColumns(cols []sqlbase.ColumnDescriptor) []sqlbase.ColumnDescriptor {
+	ret := make([]sqlbase.ColumnDescriptor, 0, len(cols))
+	for _, col := range cols {
+		if !col.IsComputed() {
+			ret = append(ret, col)
+		}
+	}
+	return ret
+}
+
 // extractInsertSource removes the parentheses around the data source of an INSERT statement.
 // If the data source is a VALUES clause not further qualified with LIMIT/OFFSET and ORDER BY,
 // the 2nd return value is a pre-casted pointer to the VALUES clause.
 func extractInsertSource(s *parser.Select) (parser.SelectStatement, *parser.ValuesClause, error) {
+	if s.With != nil {
+		// The source must be planned in the scope of its common table
+		// expressions.
+		return &parser.ParenSelect{Select: s}, nil, nil
+	}
+
 	wrapped := s.Select
 	limit := s.Limit
 	orderBy := s.OrderBy
 
-	for s, ok := wrapped.(*parser.ParenSelect); ok; s, ok = wrapped.(*parser.ParenSelect) {
+	for s, ok := wrapped.(*parser.ParenSelect); ok && s.Select.With == nil; s, ok = wrapped.(*parser.ParenSelect) {
 		wrapped = s.Select.Select
 		if s.Select.OrderBy != nil {
 			if orderBy != nil {
@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"fmt"
 	"time"
 
 	"golang.org/x/net/context"
@@ -71,8 +72,48 @@ const (
 	JobStatusFailed JobStatus = "failed"
 	// JobStatusSucceeded is for jobs that have successfully completed.
 	JobStatusSucceeded JobStatus = "succeeded"
+	// JobStatusPaused is for jobs that are not currently performing work, but
+	// have saved their state and can be resumed by the user later.
+	JobStatusPaused JobStatus = "paused"
+	// JobStatusCanceled is for jobs that were canceled by the user. Canceled
+	// jobs are finished and can't be resumed.
+	JobStatusCanceled JobStatus = "canceled"
 )
 
+// haltedError is returned when a job can't make progress because it was
+// paused or canceled.
+type haltedError struct {
+	jobID  int64
+	status JobStatus
+}
+
+func (e *haltedError) Error() string {
+	return fmt.Sprintf("job %d is %s", e.jobID, e.status)
+}
+
+// IsPausedError returns true if the error was returned because the job was
+// paused. The work of the job should be stopped and continued once the job
+// is resumed.
+func IsPausedError(err error) bool {
+	e, ok := errors.Cause(err).(*haltedError)
+	return ok && e.status == JobStatusPaused
+}
+
+// IsCanceledError returns true if the error was returned because the job was
+// canceled. The work of the job should be stopped and rolled back.
+func IsCanceledError(err error) bool {
+	e, ok := errors.Cause(err).(*haltedError)
+	return ok && e.status == JobStatusCanceled
+}
+
+// checkNotHalted returns a haltedError if the job is paused or canceled.
+func (jl *JobLogger) checkNotHalted(status JobStatus) error {
+	if status == JobStatusPaused || status == JobStatusCanceled {
+		return &haltedError{jobID: *jl.jobID, status: status}
+	}
+	return nil
+}
+
 // NewJobLogger creates a new JobLogger.
 func NewJobLogger(db *client.DB, ex sqlutil.InternalExecutor, job JobRecord) JobLogger {
 	return JobLogger{
@@ -93,7 +134,7 @@ func GetJobLogger(
 		jobID: &jobID,
 	}
 	if err := jl.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
-		payload, err := jl.retrieveJobPayload(ctx, txn)
+		_, payload, err := jl.retrieveJob(ctx, txn)
 		if err != nil {
 			return err
 		}
@@ -161,27 +202,110 @@ func (jl *JobLogger) Created(ctx context.Context) error {
 		payload.Details = &JobPayload_Restore{Restore: &d}
 	case SchemaChangeJobDetails:
 		payload.Details = &JobPayload_SchemaChange{SchemaChange: &d}
+	case ChangefeedJobDetails:
+		payload.Details = &JobPayload_Changefeed{Changefeed: &d}
+	case ImportJobDetails:
+		payload.Details = &JobPayload_Import{Import: &d}
 	default:
 		return errors.Errorf("JobLogger: unsupported job details type %T", d)
 	}
 	return jl.insertJobRecord(ctx, payload)
 }
 
-// Started marks the tracked job as started.
+// Started marks the tracked job as started. It returns an error for which
+// IsPausedError or IsCanceledError is true if the job was paused or canceled.
 func (jl *JobLogger) Started(ctx context.Context) error {
-	return jl.updateJobRecord(ctx, JobStatusRunning, func(payload *JobPayload) (bool, error) {
+	return jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
+		if err := jl.checkNotHalted(status); err != nil {
+			return status, false, err
+		}
 		if payload.StartedMicros != 0 {
 			// Already started - do nothing.
-			return false, nil
+			return status, false, nil
 		}
 		payload.StartedMicros = jobTimestamp(timeutil.Now())
-		return true, nil
+		return JobStatusRunning, true, nil
+	})
+}
+
+// CheckStatus returns an error for which IsPausedError or IsCanceledError is
+// true if the tracked job was paused or canceled. Jobs which can be paused or
+// canceled call it periodically to stop their work.
+func (jl *JobLogger) CheckStatus(ctx context.Context) error {
+	if jl.jobID == nil {
+		return errors.New("JobLogger cannot check job status: job not created")
+	}
+	return jl.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		status, _, err := jl.retrieveJob(ctx, txn)
+		if err != nil {
+			return err
+		}
+		return jl.checkNotHalted(status)
+	})
+}
+
+// Paused marks the tracked job as paused. Only pending or running schema
+// change jobs can be paused: the other jobs don't checkpoint their progress
+// and couldn't be resumed. The schema changer stops working on the job the
+// next time it checks its status.
+func (jl *JobLogger) Paused(ctx context.Context) error {
+	return jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
+		if status == JobStatusPaused {
+			// Already paused - do nothing.
+			return status, false, nil
+		}
+		if status != JobStatusPending && status != JobStatusRunning {
+			return status, false, errors.Errorf("JobLogger: cannot pause %s job %d", status, *jl.jobID)
+		}
+		if _, ok := payload.Details.(*JobPayload_SchemaChange); !ok {
+			return status, false, errors.Errorf(
+				"JobLogger: cannot pause %s job %d, only schema change jobs can be paused",
+				payload.Typ(), *jl.jobID)
+		}
+		return JobStatusPaused, true, nil
+	})
+}
+
+// Resumed marks the tracked job, which must be paused, as running again, or
+// as pending if it was paused before starting.
+func (jl *JobLogger) Resumed(ctx context.Context) error {
+	return jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
+		if status == JobStatusPending || status == JobStatusRunning {
+			// Already resumed - do nothing.
+			return status, false, nil
+		}
+		if status != JobStatusPaused {
+			return status, false, errors.Errorf("JobLogger: cannot resume %s job %d", status, *jl.jobID)
+		}
+		if payload.StartedMicros == 0 {
+			return JobStatusPending, true, nil
+		}
+		return JobStatusRunning, true, nil
+	})
+}
+
+// Canceled marks the tracked job as canceled. The job stops working and rolls
+// back its changes, if it can, the next time it checks its status or reports
+// progress.
+func (jl *JobLogger) Canceled(ctx context.Context) error {
+	return jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
+		if status == JobStatusCanceled {
+			// Already canceled - do nothing.
+			return status, false, nil
+		}
+		if payload.FinishedMicros != 0 {
+			return status, false, errors.Errorf("JobLogger: cannot cancel %s job %d", status, *jl.jobID)
+		}
+		payload.Error = "job canceled by user"
+		payload.FinishedMicros = jobTimestamp(timeutil.Now())
+		return JobStatusCanceled, true, nil
 	})
 }
 
 // Progressed updates the progress of the tracked job to fractionCompleted. A
 // fractionCompleted that is less than the currently-recorded fractionCompleted
-// will be silently ignored.
+// will be silently ignored. It returns an error for which IsPausedError or
+// IsCanceledError is true if the job was paused or canceled.
 func (jl *JobLogger) Progressed(ctx context.Context, fractionCompleted float32) error {
 	if fractionCompleted < 0.0 || fractionCompleted > 1.0 {
 		return errors.Errorf(
@@ -189,18 +313,21 @@ func (jl *JobLogger) Progressed(ctx context.Context, fractionCompleted float32)
 			fractionCompleted, jl.jobID,
 		)
 	}
-	return jl.updateJobRecord(ctx, JobStatusRunning, func(payload *JobPayload) (bool, error) {
+	return jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
+		if err := jl.checkNotHalted(status); err != nil {
+			return status, false, err
+		}
 		if payload.StartedMicros == 0 {
-			return false, errors.Errorf("JobLogger: job %d not started", jl.jobID)
+			return status, false, errors.Errorf("JobLogger: job %d not started", jl.jobID)
 		}
 		if payload.FinishedMicros != 0 {
-			return false, errors.Errorf("JobLogger: job %d already finished", jl.jobID)
+			return status, false, errors.Errorf("JobLogger: job %d already finished", jl.jobID)
 		}
 		if fractionCompleted <= payload.FractionCompleted {
-			return false, nil
+			return status, false, nil
 		}
 		payload.FractionCompleted = fractionCompleted
-		return true, nil
+		return JobStatusRunning, true, nil
 	})
 }
 
@@ -214,14 +341,14 @@ func (jl *JobLogger) Failed(ctx context.Context, err error) {
 	if jl.jobID == nil {
 		return
 	}
-	internalErr := jl.updateJobRecord(ctx, JobStatusFailed, func(payload *JobPayload) (bool, error) {
+	internalErr := jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
 		if payload.FinishedMicros != 0 {
 			// Already finished - do nothing.
-			return false, nil
+			return status, false, nil
 		}
 		payload.Error = err.Error()
 		payload.FinishedMicros = jobTimestamp(timeutil.Now())
-		return true, nil
+		return JobStatusFailed, true, nil
 	})
 	if internalErr != nil {
 		log.Errorf(ctx, "JobLogger: ignoring error %v while logging failure for job %d: %+v",
@@ -232,14 +359,14 @@ func (jl *JobLogger) Failed(ctx context.Context, err error) {
 // Succeeded marks the tracked job as having succeeded and sets its fraction
 // completed to 1.0.
 func (jl *JobLogger) Succeeded(ctx context.Context) error {
-	return jl.updateJobRecord(ctx, JobStatusSucceeded, func(payload *JobPayload) (bool, error) {
+	return jl.updateJobRecord(ctx, func(status JobStatus, payload *JobPayload) (JobStatus, bool, error) {
 		if payload.FinishedMicros != 0 {
 			// Already finished - do nothing.
-			return false, nil
+			return status, false, nil
 		}
 		payload.FinishedMicros = jobTimestamp(timeutil.Now())
 		payload.FractionCompleted = 1.0
-		return true, nil
+		return JobStatusSucceeded, true, nil
 	})
 }
 
@@ -268,29 +395,46 @@ func (jl *JobLogger) insertJobRecord(ctx context.Context, payload *JobPayload) e
 	return nil
 }
 
-func (jl *JobLogger) retrieveJobPayload(ctx context.Context, txn *client.Txn) (*JobPayload, error) {
-	const selectStmt = "SELECT payload FROM system.jobs WHERE id = $1"
+func (jl *JobLogger) retrieveJob(
+	ctx context.Context, txn *client.Txn,
+) (JobStatus, *JobPayload, error) {
+	const selectStmt = "SELECT status, payload FROM system.jobs WHERE id = $1"
 	row, err := jl.ex.QueryRowInTransaction(ctx, "log-job", txn, selectStmt, *jl.jobID)
 	if err != nil {
-		return nil, err
+		return "", nil, err
+	}
+	if row == nil {
+		return "", nil, errors.Errorf("JobLogger: job %d not found", *jl.jobID)
 	}
 
-	return UnmarshalJobPayload(row[0])
+	status, ok := row[0].(*parser.DString)
+	if !ok {
+		return "", nil, errors.Errorf("JobLogger: job %d: unexpected status %s", *jl.jobID, row[0])
+	}
+	payload, err := UnmarshalJobPayload(row[1])
+	if err != nil {
+		return "", nil, err
+	}
+	return JobStatus(*status), payload, nil
 }
 
+// updateJobRecord updates the job in a transaction. updateFn is passed the
+// current status of the job and its payload, which it can modify, and returns
+// the new status of the job and whether the job should be written.
 func (jl *JobLogger) updateJobRecord(
-	ctx context.Context, newStatus JobStatus, updateFn func(*JobPayload) (doUpdate bool, err error),
+	ctx context.Context,
+	updateFn func(JobStatus, *JobPayload) (newStatus JobStatus, doUpdate bool, err error),
 ) error {
 	if jl.jobID == nil {
 		return errors.New("JobLogger cannot update job: job not created")
 	}
 
 	return jl.runInTxn(ctx, func(ctx context.Context, txn *client.Txn) error {
-		payload, err := jl.retrieveJobPayload(ctx, txn)
+		status, payload, err := jl.retrieveJob(ctx, txn)
 		if err != nil {
 			return err
 		}
-		doUpdate, err := updateFn(payload)
+		newStatus, doUpdate, err := updateFn(status, payload)
 		if err != nil {
 			return err
 		}
@@ -322,6 +466,8 @@ const (
 	JobTypeBackup       string = "BACKUP"
 	JobTypeRestore      string = "RESTORE"
 	JobTypeSchemaChange string = "SCHEMA CHANGE"
+	JobTypeChangefeed   string = "CHANGEFEED"
+	JobTypeImport       string = "IMPORT"
 )
 
 // Typ returns the payload's job type.
@@ -333,6 +479,10 @@ func (jp *JobPayload) Typ() string {
 		return JobTypeRestore
 	case *JobPayload_SchemaChange:
 		return JobTypeSchemaChange
+	case *JobPayload_Changefeed:
+		return JobTypeChangefeed
+	case *JobPayload_Import:
+		return JobTypeImport
 	default:
 		panic("JobPayload.Typ called on a payload with an unknown details type")
 	}
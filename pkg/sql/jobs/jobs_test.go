@@ -125,7 +125,7 @@ func verifyJobRecord(
 	if started.Valid && created.Time.After(started.Time) {
 		return errors.Errorf("created time %v is after started time %v", created, started)
 	}
-	if status == jobs.JobStatusRunning {
+	if status == jobs.JobStatusRunning || status == jobs.JobStatusPaused {
 		return verifyModifiedAgainst("started", started.Time)
 	}
 
@@ -400,4 +400,96 @@ func TestJobLogger(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+
+	t.Run("paused job can be resumed and canceled", func(t *testing.T) {
+		db := sqlutils.MakeSQLRunner(t, rawSQLDB)
+		job := jobs.JobRecord{Details: jobs.SchemaChangeJobDetails{}}
+		expectation := jobExpectation{
+			Job:    job,
+			Type:   jobs.JobTypeSchemaChange,
+			Before: timeutil.Now(),
+		}
+		logger := jobs.NewJobLogger(kvDB, sql.InternalExecutor{LeaseManager: s.LeaseManager().(*sql.LeaseManager)}, job)
+		if err := logger.Created(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Started(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Progressed(ctx, 0.2); err != nil {
+			t.Fatal(err)
+		}
+		expectation.FractionCompleted = 0.2
+
+		if err := logger.Paused(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Paused(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := verifyJobRecord(db, jobs.JobStatusPaused, expectation); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Progressed(ctx, 0.5); !jobs.IsPausedError(err) {
+			t.Fatalf("expected paused error, but got %v", err)
+		}
+		if err := logger.CheckStatus(ctx); !jobs.IsPausedError(err) {
+			t.Fatalf("expected paused error, but got %v", err)
+		}
+
+		if err := logger.Resumed(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := verifyJobRecord(db, jobs.JobStatusRunning, expectation); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Progressed(ctx, 0.5); err != nil {
+			t.Fatal(err)
+		}
+		expectation.FractionCompleted = 0.5
+
+		if err := logger.Canceled(ctx); err != nil {
+			t.Fatal(err)
+		}
+		expectation.Error = "job canceled by user"
+		if err := verifyJobRecord(db, jobs.JobStatusCanceled, expectation); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Progressed(ctx, 0.8); !jobs.IsCanceledError(err) {
+			t.Fatalf("expected canceled error, but got %v", err)
+		}
+		if err := logger.Resumed(ctx); !testutils.IsError(err, `cannot resume canceled job \d+`) {
+			t.Fatalf("expected 'cannot resume canceled job' error, but got %v", err)
+		}
+	})
+
+	t.Run("pause non-schema change job fails", func(t *testing.T) {
+		logger := jobs.NewJobLogger(kvDB, sql.InternalExecutor{LeaseManager: s.LeaseManager().(*sql.LeaseManager)}, jobs.JobRecord{
+			Details: jobs.BackupJobDetails{},
+		})
+		if err := logger.Created(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Paused(ctx); !testutils.IsError(err, "only schema change jobs can be paused") {
+			t.Fatalf("expected 'only schema change jobs can be paused' error, but got %v", err)
+		}
+	})
+
+	t.Run("cancel finished job fails", func(t *testing.T) {
+		logger := jobs.NewJobLogger(kvDB, sql.InternalExecutor{LeaseManager: s.LeaseManager().(*sql.LeaseManager)}, jobs.JobRecord{
+			Details: jobs.BackupJobDetails{},
+		})
+		if err := logger.Created(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Started(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Succeeded(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Canceled(ctx); !testutils.IsError(err, `cannot cancel succeeded job \d+`) {
+			t.Fatalf("expected 'cannot cancel succeeded job' error, but got %v", err)
+		}
+	})
 }
@@ -165,13 +165,19 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 		setUnlimited(n.rows)
 
 	case *valuesNode:
+	case *alterSequenceNode:
 	case *alterTableNode:
+	case *cancelQueryNode:
+	case *cancelSessionNode:
+	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSequenceNode:
 	case *createUserNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSequenceNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
@@ -181,6 +187,7 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *scrubNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -192,6 +192,11 @@ type MemoryMonitor struct {
 		curBudget MemoryAccount
 	}
 
+	// limit is the maximum amount of memory that can be allocated at this
+	// monitor, regardless of the budget available at the pool. Zero means
+	// no limit. It is only accessed with mu locked.
+	limit int64
+
 	// name identifies this monitor in logging messages.
 	name string
 
@@ -255,12 +260,28 @@ func MakeMonitor(
 	maxHist *metric.Histogram,
 	increment int64,
 	noteworthy int64,
+) MemoryMonitor {
+	return MakeMonitorWithLimit(name, 0 /* limit */, curCount, maxHist, increment, noteworthy)
+}
+
+// MakeMonitorWithLimit creates a new monitor which refuses the
+// allocations that would make its total allocated size exceed limit,
+// even when more memory is available at its pool. A limit of 0 means no
+// limit.
+func MakeMonitorWithLimit(
+	name string,
+	limit int64,
+	curCount *metric.Counter,
+	maxHist *metric.Histogram,
+	increment int64,
+	noteworthy int64,
 ) MemoryMonitor {
 	if increment <= 0 {
 		increment = DefaultPoolAllocationSize
 	}
 	return MemoryMonitor{
 		name:                 name,
+		limit:                limit,
 		noteworthyUsageBytes: noteworthy,
 		curBytesCount:        curCount,
 		maxBytesHist:         maxHist,
@@ -268,6 +289,15 @@ func MakeMonitor(
 	}
 }
 
+// SetLimit changes the limit of the monitor. Memory already allocated
+// beyond the new limit is not reclaimed, but further allocations are
+// refused until the usage drops below it.
+func (mm *MemoryMonitor) SetLimit(limit int64) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.limit = limit
+}
+
 // Start begins a monitoring region.
 // Arguments:
 // - pool is the upstream memory monitor that provision allocations
@@ -284,9 +314,13 @@ func (mm *MemoryMonitor) Start(ctx context.Context, pool *MemoryMonitor, reserve
 		panic(fmt.Sprintf("%s: already started with pool %s", mm.name, mm.pool.name))
 	}
 	mm.pool = pool
+	// The counters are reset with mm.mu locked as they may be concurrently
+	// read by AllocBytes() and MaximumBytes() when the monitor is restarted.
+	mm.mu.Lock()
 	mm.mu.curAllocated = 0
 	mm.mu.maxAllocated = 0
 	mm.mu.curBudget.curAllocated = 0
+	mm.mu.Unlock()
 	mm.reserved = reserved
 	if log.V(2) {
 		poolname := "(none)"
@@ -512,6 +546,9 @@ func (b *BoundAccount) Grow(ctx context.Context, x int64) error {
 func (mm *MemoryMonitor) reserveMemory(ctx context.Context, x int64) error {
 	mm.mu.Lock()
 	defer mm.mu.Unlock()
+	if mm.limit > 0 && mm.mu.curAllocated > mm.limit-x {
+		return newMemoryError(mm.name, x, mm.limit)
+	}
 	if mm.mu.curAllocated > mm.mu.curBudget.curAllocated+mm.reserved.curAllocated-x {
 		if err := mm.increaseBudget(ctx, x); err != nil {
 			return err
@@ -626,6 +663,22 @@ func (mm *MemoryMonitor) adjustBudget(ctx context.Context) {
 	}
 }
 
+// AllocBytes returns the number of bytes currently allocated in the
+// MemoryMonitor by its client components.
+func (mm *MemoryMonitor) AllocBytes() int64 {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return mm.mu.curAllocated
+}
+
+// MaximumBytes returns the high water mark of the allocations in the
+// MemoryMonitor since it was started.
+func (mm *MemoryMonitor) MaximumBytes() int64 {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return mm.mu.maxAllocated
+}
+
 // GetCurrentAllocationForTesting returns the number of bytes that have
 // currently been allocated in the MemoryMonitor. Intended for use in testing.
 func (mm *MemoryMonitor) GetCurrentAllocationForTesting() int64 {
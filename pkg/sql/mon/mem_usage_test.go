@@ -298,6 +298,42 @@ func TestMemoryMonitor(t *testing.T) {
 	if m.mu.curAllocated != 0 {
 		t.Fatalf("incorrect current allocation: got %d, expected %d", m.mu.curAllocated, 0)
 	}
+	if cur, max := m.AllocBytes(), m.MaximumBytes(); cur != 0 || max != 100 {
+		t.Fatalf("incorrect reported allocations: got %d/%d, expected %d/%d", cur, max, 0, 100)
+	}
+
+	m.Stop(ctx)
+}
+
+func TestMemoryMonitorLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	pool := MakeMonitor("pool", nil, nil, 1, 1000)
+	pool.Start(ctx, nil, MakeStandaloneBudget(1000))
+	m := MakeMonitorWithLimit("test", 100, nil, nil, 1, 1000)
+	m.Start(ctx, &pool, BoundAccount{})
+
+	if err := m.reserveMemory(ctx, 100); err != nil {
+		t.Fatalf("monitor refused allocation within its limit: %v", err)
+	}
+	if err := m.reserveMemory(ctx, 1); err == nil {
+		t.Fatalf("monitor accepted allocation beyond its limit")
+	}
+	if pool.mu.curAllocated != 100 {
+		t.Fatalf("incorrect pool allocation: got %d, expected %d", pool.mu.curAllocated, 100)
+	}
+
+	m.SetLimit(0)
+	if err := m.reserveMemory(ctx, 200); err != nil {
+		t.Fatalf("monitor without a limit refused allocation: %v", err)
+	}
+	m.SetLimit(200)
+	if err := m.reserveMemory(ctx, 1); err == nil {
+		t.Fatalf("monitor accepted allocation beyond its lowered limit")
+	}
 
+	m.releaseMemory(ctx, 300)
 	m.Stop(ctx)
+	pool.Stop(ctx)
 }
@@ -185,14 +185,20 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *relocateNode:
 		setNeededColumns(n.rows, allColumns(n.rows))
 
+	case *alterSequenceNode:
 	case *alterTableNode:
+	case *cancelQueryNode:
+	case *cancelSessionNode:
+	case *controlJobNode:
 	case *copyNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
+	case *createSequenceNode:
 	case *createUserNode:
 	case *delayedNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropSequenceNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropUserNode:
@@ -202,6 +208,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *scrubNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -50,12 +50,21 @@ func (p *planner) optimizePlan(
 	// the needed columns are properly computed for newly expanded nodes.
 	setNeededColumns(newPlan, needed)
 
-	// Now do the same work for all sub-queries.
+	// Now do the same work for all sub-queries, and select the aggregations
+	// which run on the vectorized execution engine.
 	i := &subqueryInitializer{p: p}
 	observer := planObserver{
 		subqueryNode: i.subqueryNode,
 		enterNode:    i.enterNode,
 	}
+	if p.session.Vectorize {
+		observer.enterNode = func(ctx context.Context, name string, plan planNode) bool {
+			if n, ok := plan.(*groupNode); ok {
+				n.vec = vectorizeGroup(n)
+			}
+			return i.enterNode(ctx, name, plan)
+		}
+	}
 	if err := walkPlan(ctx, newPlan, observer); err != nil {
 		return plan, err
 	}
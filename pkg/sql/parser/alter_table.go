@@ -65,7 +65,9 @@ func (*AlterTableAddConstraint) alterTableCmd()      {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
+func (*AlterTableResetStorageParams) alterTableCmd() {}
 func (*AlterTableSetDefault) alterTableCmd()         {}
+func (*AlterTableSetStorageParams) alterTableCmd()   {}
 func (*AlterTableValidateConstraint) alterTableCmd() {}
 
 var _ AlterTableCmd = &AlterTableAddColumn{}
@@ -73,7 +75,9 @@ var _ AlterTableCmd = &AlterTableAddConstraint{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
+var _ AlterTableCmd = &AlterTableResetStorageParams{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
+var _ AlterTableCmd = &AlterTableSetStorageParams{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 
 // ColumnMutationCmd is the subset of AlterTableCmds that modify an
@@ -229,3 +233,27 @@ func (node *AlterTableDropNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Column)
 	buf.WriteString(" DROP NOT NULL")
 }
+
+// AlterTableSetStorageParams represents a SET (...) command.
+type AlterTableSetStorageParams struct {
+	Params StorageParams
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetStorageParams) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SET (")
+	FormatNode(buf, f, node.Params)
+	buf.WriteByte(')')
+}
+
+// AlterTableResetStorageParams represents a RESET (...) command.
+type AlterTableResetStorageParams struct {
+	Params NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableResetStorageParams) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("RESET (")
+	FormatNode(buf, f, node.Params)
+	buf.WriteByte(')')
+}
@@ -84,6 +84,7 @@ const (
 	categoryDateAndTime   = "Date and Time"
 	categoryIDGeneration  = "ID Generation"
 	categoryMath          = "Math and Numeric"
+	categorySequences     = "Sequence"
 	categoryString        = "String and Byte"
 	categorySystemInfo    = "System Info"
 )
@@ -884,6 +885,54 @@ var Builtins = map[string][]Builtin{
 		},
 	},
 
+	// Sequence functions.
+
+	"nextval": {
+		Builtin{
+			Types:                   ArgTypes{{"sequence_name", TypeString}},
+			ReturnType:              fixedReturnType(TypeInt),
+			impure:                  true,
+			needsRepeatedEvaluation: true,
+			distsqlBlacklist:        true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				seqName, err := ParseTableName(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				res, err := ctx.Planner.IncrementSequence(ctx.Ctx(), seqName)
+				if err != nil {
+					return nil, err
+				}
+				return NewDInt(DInt(res)), nil
+			},
+			category: categorySequences,
+			Info:     "Advances the given sequence and returns its new value.",
+		},
+	},
+
+	"currval": {
+		Builtin{
+			Types:            ArgTypes{{"sequence_name", TypeString}},
+			ReturnType:       fixedReturnType(TypeInt),
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				seqName, err := ParseTableName(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				res, err := ctx.Planner.GetLatestValueInSessionForSequence(ctx.Ctx(), seqName)
+				if err != nil {
+					return nil, err
+				}
+				return NewDInt(DInt(res)), nil
+			},
+			category: categorySequences,
+			Info: "Returns the latest value obtained with nextval for this sequence in " +
+				"this session.",
+		},
+	},
+
 	"experimental_uuid_v4": {uuidV4Impl},
 	"uuid_v4":              {uuidV4Impl},
 
@@ -1683,6 +1732,48 @@ var Builtins = map[string][]Builtin{
 		},
 	},
 
+	"crdb_internal.set_app_log_verbosity": {
+		Builtin{
+			Types: ArgTypes{
+				{"app_name", TypeString},
+				{"level", TypeInt},
+				{"duration", TypeInterval}},
+			ReturnType: fixedReturnType(TypeBool),
+			impure:     true,
+			privileged: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				appName := string(*args[0].(*DString))
+				level := int(*args[1].(*DInt))
+				now := timeutil.Now()
+				d := duration.Add(now, args[2].(*DInterval).Duration).Sub(now)
+				log.EscalateVerbosity(ctx.Ctx(), appName, level, d)
+				return DBoolTrue, nil
+			},
+			category: categorySystemInfo,
+			Info: "Temporarily raises the verbosity of the logging of the SQL sessions of " +
+				"an application on the current node. A duration which is not positive " +
+				"removes the escalation.",
+		},
+	},
+
+	"crdb_internal.reset_statement_statistics": {
+		Builtin{
+			Types:      ArgTypes{},
+			ReturnType: fixedReturnType(TypeBool),
+			impure:     true,
+			privileged: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				if err := ctx.Planner.ResetStatementStatistics(ctx.Ctx()); err != nil {
+					return nil, err
+				}
+				return DBoolTrue, nil
+			},
+			category: categorySystemInfo,
+			Info: "Clears the per-statement statistics collected on the current node, " +
+				"as shown in crdb_internal.node_statement_statistics.",
+		},
+	},
+
 	"crdb_internal.force_retry": {
 		Builtin{
 			Types:      ArgTypes{{"val", TypeInterval}},
@@ -50,6 +50,7 @@ func (*TimestampColType) columnType()      {}
 func (*TimestampTZColType) columnType()    {}
 func (*IntervalColType) columnType()       {}
 func (*UUIDColType) columnType()           {}
+func (*JSONColType) columnType()           {}
 func (*StringColType) columnType()         {}
 func (*NameColType) columnType()           {}
 func (*BytesColType) columnType()          {}
@@ -68,6 +69,7 @@ func (*TimestampColType) castTargetType()      {}
 func (*TimestampTZColType) castTargetType()    {}
 func (*IntervalColType) castTargetType()       {}
 func (*UUIDColType) castTargetType()           {}
+func (*JSONColType) castTargetType()           {}
 func (*StringColType) castTargetType()         {}
 func (*NameColType) castTargetType()           {}
 func (*BytesColType) castTargetType()          {}
@@ -292,6 +294,22 @@ func (node *UUIDColType) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("UUID")
 }
 
+// Pre-allocated immutable JSON column types.
+var (
+	jsonColTypeJSON  = &JSONColType{Name: "JSON"}
+	jsonColTypeJSONB = &JSONColType{Name: "JSONB"}
+)
+
+// JSONColType represents the JSON column type.
+type JSONColType struct {
+	Name string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *JSONColType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(node.Name)
+}
+
 // Pre-allocated immutable string column types.
 var (
 	stringColTypeChar    = &StringColType{Name: "CHAR"}
@@ -476,6 +494,7 @@ func (node *TimestampColType) String() string      { return AsString(node) }
 func (node *TimestampTZColType) String() string    { return AsString(node) }
 func (node *IntervalColType) String() string       { return AsString(node) }
 func (node *UUIDColType) String() string           { return AsString(node) }
+func (node *JSONColType) String() string           { return AsString(node) }
 func (node *StringColType) String() string         { return AsString(node) }
 func (node *NameColType) String() string           { return AsString(node) }
 func (node *BytesColType) String() string          { return AsString(node) }
@@ -505,6 +524,8 @@ func DatumTypeToColumnType(t Type) (ColumnType, error) {
 		return intervalColTypeInterval, nil
 	case TypeUUID:
 		return uuidColTypeUUID, nil
+	case TypeJSON:
+		return jsonColTypeJSONB, nil
 	case TypeDate:
 		return dateColTypeDate, nil
 	case TypeString:
@@ -566,6 +587,8 @@ func CastTargetToDatumType(t CastTargetType) Type {
 		return TypeInterval
 	case *UUIDColType:
 		return TypeUUID
+	case *JSONColType:
+		return TypeJSON
 	case *CollatedStringColType:
 		return TCollatedString{Locale: ct.Locale}
 	case *ArrayColType:
@@ -369,6 +369,7 @@ var (
 		TypeTimestampTZ,
 		TypeInterval,
 		TypeUUID,
+		TypeJSON,
 	}
 	strValAvailBytesString = []Type{TypeBytes, TypeString, TypeUUID}
 	strValAvailBytes       = []Type{TypeBytes, TypeUUID}
@@ -440,6 +441,8 @@ func (expr *StrVal) ResolveAsType(ctx *SemaContext, typ Type) (Datum, error) {
 			return ParseDUuidFromBytes([]byte(expr.s))
 		}
 		return ParseDUuidFromString(expr.s)
+	case TypeJSON:
+		return ParseDJSON(expr.s)
 	default:
 		return nil, fmt.Errorf("could not resolve %T %v into a %T", expr, expr, typ)
 	}
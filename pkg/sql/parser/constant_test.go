@@ -219,6 +219,14 @@ func mustParseDInterval(t *testing.T, s string) Datum {
 	return d
 }
 
+func mustParseDJSON(t *testing.T, s string) Datum {
+	d, err := ParseDJSON(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
 var parseFuncs = map[Type]func(*testing.T, string) Datum{
 	TypeString:      func(t *testing.T, s string) Datum { return NewDString(s) },
 	TypeBytes:       func(t *testing.T, s string) Datum { return NewDBytes(DBytes(s)) },
@@ -227,6 +235,7 @@ var parseFuncs = map[Type]func(*testing.T, string) Datum{
 	TypeTimestamp:   mustParseDTimestamp,
 	TypeTimestampTZ: mustParseDTimestampTZ,
 	TypeInterval:    mustParseDInterval,
+	TypeJSON:        mustParseDJSON,
 }
 
 func typeSet(types ...Type) map[Type]struct{} {
@@ -253,7 +262,11 @@ func TestStringConstantResolveAvailableTypes(t *testing.T) {
 		},
 		{
 			c:            &StrVal{s: "true", bytesEsc: false},
-			parseOptions: typeSet(TypeString, TypeBytes, TypeBool),
+			parseOptions: typeSet(TypeString, TypeBytes, TypeBool, TypeJSON),
+		},
+		{
+			c:            &StrVal{s: `{"a": [1, "b"]}`, bytesEsc: false},
+			parseOptions: typeSet(TypeString, TypeBytes, TypeJSON),
 		},
 		{
 			c:            &StrVal{s: "2010-09-28", bytesEsc: false},
@@ -106,6 +106,7 @@ type CreateIndex struct {
 	// for improved reading performance.
 	Storing    NameList
 	Interleave *InterleaveDef
+	Inverted   bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -114,6 +115,9 @@ func (node *CreateIndex) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Unique {
 		buf.WriteString("UNIQUE ")
 	}
+	if node.Inverted {
+		buf.WriteString("INVERTED ")
+	}
 	buf.WriteString("INDEX ")
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
@@ -202,6 +206,10 @@ type ColumnTableDef struct {
 		Create      bool
 		IfNotExists bool
 	}
+	Computed struct {
+		Computed bool
+		Expr     Expr
+	}
 }
 
 // ColumnTableDefCheckExpr represents a check constraint on a column definition
@@ -271,6 +279,12 @@ func newColumnTableDef(
 			d.References.Table = t.Table
 			d.References.Col = t.Col
 			d.References.ConstraintName = c.Name
+		case *ColumnComputedDef:
+			if d.IsComputed() {
+				return nil, errors.Errorf("multiple computed expressions specified for column %q", name)
+			}
+			d.Computed.Computed = true
+			d.Computed.Expr = t.Expr
 		case *ColumnFamilyConstraint:
 			if d.HasColumnFamily() {
 				return nil, errors.Errorf("multiple column families specified for column %q", name)
@@ -282,6 +296,9 @@ func newColumnTableDef(
 			panic(fmt.Sprintf("unexpected column qualification: %T", c))
 		}
 	}
+	if d.IsComputed() && d.HasDefaultExpr() {
+		return nil, errors.Errorf("computed column %q cannot also have a DEFAULT expression", name)
+	}
 	return d, nil
 }
 
@@ -304,6 +321,11 @@ func (node *ColumnTableDef) HasColumnFamily() bool {
 	return node.Family.Name != "" || node.Family.Create
 }
 
+// IsComputed returns if the ColumnTableDef is a computed column.
+func (node *ColumnTableDef) IsComputed() bool {
+	return node.Computed.Computed
+}
+
 // Format implements the NodeFormatter interface.
 func (node *ColumnTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Name)
@@ -367,6 +389,11 @@ func (node *ColumnTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 			FormatNode(buf, f, node.Family.Name)
 		}
 	}
+	if node.IsComputed() {
+		buf.WriteString(" AS (")
+		FormatNode(buf, f, node.Computed.Expr)
+		buf.WriteString(") STORED")
+	}
 }
 
 // NamedColumnQualification wraps a NamedColumnQualification with a name.
@@ -389,6 +416,7 @@ func (UniqueConstraint) columnQualification()        {}
 func (*ColumnCheckConstraint) columnQualification()  {}
 func (*ColumnFKConstraint) columnQualification()     {}
 func (*ColumnFamilyConstraint) columnQualification() {}
+func (*ColumnComputedDef) columnQualification()      {}
 
 // ColumnCollation represents a COLLATE clause for a column.
 type ColumnCollation string
@@ -415,6 +443,11 @@ type ColumnCheckConstraint struct {
 	Expr Expr
 }
 
+// ColumnComputedDef represents the description of a computed column.
+type ColumnComputedDef struct {
+	Expr Expr
+}
+
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
 	Table NormalizableTableName
@@ -435,6 +468,7 @@ type IndexTableDef struct {
 	Columns    IndexElemList
 	Storing    NameList
 	Interleave *InterleaveDef
+	Inverted   bool
 }
 
 func (node *IndexTableDef) setName(name Name) {
@@ -443,6 +477,9 @@ func (node *IndexTableDef) setName(name Name) {
 
 // Format implements the NodeFormatter interface.
 func (node *IndexTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Inverted {
+		buf.WriteString("INVERTED ")
+	}
 	buf.WriteString("INDEX ")
 	if node.Name != "" {
 		FormatNode(buf, f, node.Name)
@@ -614,14 +651,95 @@ func (node *InterleaveDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// PartitionByType is an enum of each type of partitioning (LIST/RANGE).
+type PartitionByType string
+
+const (
+	// PartitionByList indicates a PARTITION BY LIST clause.
+	PartitionByList PartitionByType = "LIST"
+	// PartitionByRange indicates a PARTITION BY RANGE clause.
+	PartitionByRange PartitionByType = "RANGE"
+)
+
+// PartitionBy represents a PARTITION BY definition within a CREATE TABLE
+// statement. Exactly one of List or Range is set.
+type PartitionBy struct {
+	Fields NameList
+	List   []ListPartition
+	Range  []RangePartition
+}
+
+// Type returns whether this is a LIST or RANGE partitioning.
+func (node *PartitionBy) Type() PartitionByType {
+	if node.List != nil {
+		return PartitionByList
+	}
+	return PartitionByRange
+}
+
+// Format implements the NodeFormatter interface.
+func (node *PartitionBy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(" PARTITION BY ")
+	buf.WriteString(string(node.Type()))
+	buf.WriteString(" (")
+	FormatNode(buf, f, node.Fields)
+	buf.WriteString(") (")
+	for i := range node.List {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &node.List[i])
+	}
+	for i := range node.Range {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, &node.Range[i])
+	}
+	buf.WriteString(")")
+}
+
+// ListPartition represents a PARTITION definition within a PARTITION BY LIST.
+type ListPartition struct {
+	Name  Name
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ListPartition) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("PARTITION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" VALUES IN (")
+	FormatNode(buf, f, node.Exprs)
+	buf.WriteString(")")
+}
+
+// RangePartition represents a PARTITION definition within a PARTITION BY
+// RANGE. The partition holds all rows less than Expr and not covered by an
+// earlier partition.
+type RangePartition struct {
+	Name Name
+	Expr Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RangePartition) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("PARTITION ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" VALUES < ")
+	FormatNode(buf, f, node.Expr)
+}
+
 // CreateTable represents a CREATE TABLE statement.
 type CreateTable struct {
 	IfNotExists   bool
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
+	PartitionBy   *PartitionBy
 	Defs          TableDefs
 	AsSource      *Select
 	AsColumnNames NameList // Only to be used in conjunction with AsSource
+	StorageParams StorageParams
 }
 
 // As returns true if this table represents a CREATE TABLE ... AS statement,
@@ -652,6 +770,36 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 		if node.Interleave != nil {
 			FormatNode(buf, f, node.Interleave)
 		}
+		if node.PartitionBy != nil {
+			FormatNode(buf, f, node.PartitionBy)
+		}
+		if node.StorageParams != nil {
+			buf.WriteString(" WITH (")
+			FormatNode(buf, f, node.StorageParams)
+			buf.WriteByte(')')
+		}
+	}
+}
+
+// StorageParam is a key-value parameter for table storage.
+type StorageParam struct {
+	Key   Name
+	Value Expr
+}
+
+// StorageParams is a list of StorageParams.
+type StorageParams []StorageParam
+
+// Format implements the NodeFormatter interface.
+func (o StorageParams) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i := range o {
+		n := &o[i]
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, n.Key)
+		buf.WriteString(" = ")
+		FormatNode(buf, f, n.Value)
 	}
 }
 
@@ -702,3 +850,69 @@ func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(" AS ")
 	FormatNode(buf, f, node.AsSource)
 }
+
+// CreateSequence represents a CREATE SEQUENCE statement.
+type CreateSequence struct {
+	IfNotExists bool
+	Name        NormalizableTableName
+	Options     SequenceOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE SEQUENCE ")
+	if node.IfNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	FormatNode(buf, f, node.Options)
+}
+
+// Names of the options of CREATE SEQUENCE and ALTER SEQUENCE.
+const (
+	SeqOptCache     = "CACHE"
+	SeqOptNoCycle   = "NO CYCLE"
+	SeqOptIncrement = "INCREMENT"
+	SeqOptMinValue  = "MINVALUE"
+	SeqOptMaxValue  = "MAXVALUE"
+	SeqOptStart     = "START"
+)
+
+// SequenceOption represents an option of a CREATE SEQUENCE or ALTER
+// SEQUENCE statement.
+type SequenceOption struct {
+	Name string
+	// IntVal is the value of the option. It is nil for the options which
+	// take no value, and for NO MINVALUE and NO MAXVALUE.
+	IntVal *int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *SequenceOption) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.IntVal == nil {
+		if node.Name != SeqOptNoCycle {
+			buf.WriteString("NO ")
+		}
+		buf.WriteString(node.Name)
+		return
+	}
+	buf.WriteString(node.Name)
+	switch node.Name {
+	case SeqOptIncrement:
+		buf.WriteString(" BY")
+	case SeqOptStart:
+		buf.WriteString(" WITH")
+	}
+	fmt.Fprintf(buf, " %d", *node.IntVal)
+}
+
+// SequenceOptions represents a list of sequence options.
+type SequenceOptions []SequenceOption
+
+// Format implements the NodeFormatter interface.
+func (node SequenceOptions) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i := range node {
+		buf.WriteByte(' ')
+		FormatNode(buf, f, &node[i])
+	}
+}
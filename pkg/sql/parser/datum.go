@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -1052,6 +1053,90 @@ func (d *DUuid) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DJSON is the JSON Datum.
+type DJSON struct {
+	JSON json.JSON
+}
+
+// NewDJSON is a helper routine to create a *DJSON initialized from its
+// argument.
+func NewDJSON(j json.JSON) *DJSON {
+	return &DJSON{j}
+}
+
+// ParseDJSON parses and returns the *DJSON Datum value represented by the
+// provided input string, or an error.
+func ParseDJSON(s string) (*DJSON, error) {
+	j, err := json.ParseJSON(s)
+	if err != nil {
+		return nil, makeParseError(s, TypeJSON, err)
+	}
+	return NewDJSON(j), nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DJSON) ResolvedType() Type {
+	return TypeJSON
+}
+
+// Compare implements the Datum interface.
+func (d *DJSON) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(other).(*DJSON)
+	if !ok {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return d.JSON.Compare(v.JSON)
+}
+
+// Prev implements the Datum interface.
+func (d *DJSON) Prev() (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DJSON) Next() (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DJSON) IsMax() bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DJSON) IsMin() bool {
+	return d.JSON == json.NullJSONValue
+}
+
+// min implements the Datum interface.
+func (*DJSON) min() (Datum, bool) {
+	return dMinJSON, true
+}
+
+// max implements the Datum interface.
+func (*DJSON) max() (Datum, bool) {
+	return nil, false
+}
+
+var dMinJSON = NewDJSON(json.NullJSONValue)
+
+// AmbiguousFormat implements the Datum interface.
+func (*DJSON) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DJSON) Format(buf *bytes.Buffer, f FmtFlags) {
+	encodeSQLString(buf, d.JSON.String())
+}
+
+// Size implements the Datum interface.
+func (d *DJSON) Size() uintptr {
+	return unsafe.Sizeof(*d) + d.JSON.Size()
+}
+
 // DDate is the date Datum represented as the number of days after
 // the Unix epoch.
 type DDate int64
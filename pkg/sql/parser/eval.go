@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
@@ -877,6 +878,52 @@ var BinOps = map[BinaryOperator]binOpOverload{
 		},
 	},
 
+	JSONFetchVal: {
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeString,
+			ReturnType: TypeJSON,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				j := left.(*DJSON).JSON.FetchValKey(string(MustBeDString(right)))
+				if j == nil {
+					return DNull, nil
+				}
+				return NewDJSON(j), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeInt,
+			ReturnType: TypeJSON,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				j := left.(*DJSON).JSON.FetchValIdx(int(MustBeDInt(right)))
+				if j == nil {
+					return DNull, nil
+				}
+				return NewDJSON(j), nil
+			},
+		},
+	},
+
+	JSONFetchText: {
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeString,
+			ReturnType: TypeString,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return jsonAsText(left.(*DJSON).JSON.FetchValKey(string(MustBeDString(right)))), nil
+			},
+		},
+		BinOp{
+			LeftType:   TypeJSON,
+			RightType:  TypeInt,
+			ReturnType: TypeString,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return jsonAsText(left.(*DJSON).JSON.FetchValIdx(int(MustBeDInt(right)))), nil
+			},
+		},
+	},
+
 	// TODO(pmattis): Check that the shift is valid.
 	LShift: {
 		BinOp{
@@ -959,6 +1006,19 @@ var BinOps = map[BinaryOperator]binOpOverload{
 	},
 }
 
+// jsonAsText returns the text of a fetched JSON value, which is NULL if the
+// value does not exist or is the JSON null literal.
+func jsonAsText(j json.JSON) Datum {
+	if j == nil {
+		return DNull
+	}
+	text := j.AsText()
+	if text == nil {
+		return DNull
+	}
+	return NewDString(*text)
+}
+
 var timestampMinusBinOp BinOp
 
 func init() {
@@ -1140,6 +1200,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeUUID,
 			fn:        cmpOpScalarEQFn,
 		},
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn:        cmpOpScalarEQFn,
+		},
 		CmpOp{
 			LeftType:  TypeOid,
 			RightType: TypeOid,
@@ -1275,6 +1340,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeUUID,
 			fn:        cmpOpScalarLTFn,
 		},
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn:        cmpOpScalarLTFn,
+		},
 		CmpOp{
 			LeftType:  TypeTuple,
 			RightType: TypeTuple,
@@ -1405,6 +1475,11 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			RightType: TypeUUID,
 			fn:        cmpOpScalarLEFn,
 		},
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn:        cmpOpScalarLEFn,
+		},
 		CmpOp{
 			LeftType:  TypeTuple,
 			RightType: TypeTuple,
@@ -1427,6 +1502,7 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(TypeTimestampTZ),
 		makeEvalTupleIn(TypeInterval),
 		makeEvalTupleIn(TypeUUID),
+		makeEvalTupleIn(TypeJSON),
 		makeEvalTupleIn(TypeTuple),
 	},
 
@@ -1482,6 +1558,26 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 			},
 		},
 	},
+
+	Contains: {
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeJSON,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(json.Contains(left.(*DJSON).JSON, right.(*DJSON).JSON))), nil
+			},
+		},
+	},
+
+	JSONExists: {
+		CmpOp{
+			LeftType:  TypeJSON,
+			RightType: TypeString,
+			fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(left.(*DJSON).JSON.Exists(string(MustBeDString(right))))), nil
+			},
+		},
+	},
 }
 
 func isNaN(d Datum) bool {
@@ -1702,6 +1798,19 @@ type EvalPlanner interface {
 	// QualifyWithDatabase resolves a possibly unqualified table name into a
 	// table name that is qualified by database.
 	QualifyWithDatabase(ctx context.Context, t *NormalizableTableName) (*TableName, error)
+
+	// ResetStatementStatistics clears the per-statement statistics
+	// collected on the current node.
+	ResetStatementStatistics(ctx context.Context) error
+
+	// IncrementSequence advances the given sequence and returns its new
+	// value.
+	IncrementSequence(ctx context.Context, seqName *TableName) (int64, error)
+
+	// GetLatestValueInSessionForSequence returns the value most recently
+	// obtained with IncrementSequence for the given sequence in the
+	// current session.
+	GetLatestValueInSessionForSequence(ctx context.Context, seqName *TableName) (int64, error)
 }
 
 // contextHolder is a wrapper that returns a Context.
@@ -2264,6 +2373,8 @@ func (expr *CastExpr) Eval(ctx *EvalContext) (Datum, error) {
 			s = t.ValueAsString()
 		case *DUuid:
 			s = t.UUID.String()
+		case *DJSON:
+			s = t.JSON.String()
 		case *DString:
 			s = string(*t)
 		case *DCollatedString:
@@ -2319,6 +2430,16 @@ func (expr *CastExpr) Eval(ctx *EvalContext) (Datum, error) {
 			return d, nil
 		}
 
+	case *JSONColType:
+		switch t := d.(type) {
+		case *DString:
+			return ParseDJSON(string(*t))
+		case *DCollatedString:
+			return ParseDJSON(t.Contents)
+		case *DJSON:
+			return d, nil
+		}
+
 	case *DateColType:
 		switch d := d.(type) {
 		case *DString:
@@ -2856,6 +2977,11 @@ func (t *DUuid) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DJSON) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DDate) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
@@ -2983,6 +3109,9 @@ func foldComparisonExpr(
 	case NotRegIMatch:
 		// NotRegIMatch(left, right) is implemented as !RegIMatch(left, right)
 		return RegIMatch, left, right, false, true
+	case ContainedBy:
+		// ContainedBy(left, right) is implemented as Contains(right, left)
+		return Contains, right, left, true, false
 	case IsDistinctFrom:
 		// IsDistinctFrom(left, right) is implemented as !EQ(left, right)
 		//
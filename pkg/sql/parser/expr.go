@@ -295,6 +295,9 @@ const (
 	IsNotDistinctFrom
 	Is
 	IsNot
+	Contains
+	ContainedBy
+	JSONExists
 
 	// The following operators will always be used with an associated SubOperator.
 	// If Go had algebraic data types they would be defined in a self-contained
@@ -337,6 +340,9 @@ var comparisonOpName = [...]string{
 	IsNotDistinctFrom: "IS NOT DISTINCT FROM",
 	Is:                "IS",
 	IsNot:             "IS NOT",
+	Contains:          "@>",
+	ContainedBy:       "<@",
+	JSONExists:        "?",
 	Any:               "ANY",
 	Some:              "SOME",
 	All:               "ALL",
@@ -750,22 +756,26 @@ const (
 	Concat
 	LShift
 	RShift
+	JSONFetchVal
+	JSONFetchText
 )
 
 var binaryOpName = [...]string{
-	Bitand:   "&",
-	Bitor:    "|",
-	Bitxor:   "#",
-	Plus:     "+",
-	Minus:    "-",
-	Mult:     "*",
-	Div:      "/",
-	FloorDiv: "//",
-	Mod:      "%",
-	Pow:      "^",
-	Concat:   "||",
-	LShift:   "<<",
-	RShift:   ">>",
+	Bitand:        "&",
+	Bitor:         "|",
+	Bitxor:        "#",
+	Plus:          "+",
+	Minus:         "-",
+	Mult:          "*",
+	Div:           "/",
+	FloorDiv:      "//",
+	Mod:           "%",
+	Pow:           "^",
+	Concat:        "||",
+	LShift:        "<<",
+	RShift:        ">>",
+	JSONFetchVal:  "->",
+	JSONFetchText: "->>",
 }
 
 func (i BinaryOperator) String() string {
@@ -1081,13 +1091,14 @@ var (
 	decimalCastTypes = []Type{TypeNull, TypeBool, TypeInt, TypeFloat, TypeDecimal, TypeString, TypeCollatedString,
 		TypeTimestamp, TypeTimestampTZ, TypeDate, TypeInterval}
 	stringCastTypes = []Type{TypeNull, TypeBool, TypeInt, TypeFloat, TypeDecimal, TypeString, TypeCollatedString,
-		TypeBytes, TypeTimestamp, TypeTimestampTZ, TypeInterval, TypeUUID, TypeDate, TypeOid}
+		TypeBytes, TypeTimestamp, TypeTimestampTZ, TypeInterval, TypeUUID, TypeJSON, TypeDate, TypeOid}
 	bytesCastTypes     = []Type{TypeNull, TypeString, TypeCollatedString, TypeBytes, TypeUUID}
 	dateCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeDate, TypeTimestamp, TypeTimestampTZ, TypeInt}
 	timestampCastTypes = []Type{TypeNull, TypeString, TypeCollatedString, TypeDate, TypeTimestamp, TypeTimestampTZ, TypeInt}
 	intervalCastTypes  = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeInterval}
 	oidCastTypes       = []Type{TypeNull, TypeString, TypeCollatedString, TypeInt, TypeOid}
 	uuidCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeBytes, TypeUUID}
+	jsonCastTypes      = []Type{TypeNull, TypeString, TypeCollatedString, TypeJSON}
 )
 
 // validCastTypes returns a set of types that can be cast into the provided type.
@@ -1113,6 +1124,8 @@ func validCastTypes(t Type) []Type {
 		return intervalCastTypes
 	case TypeUUID:
 		return uuidCastTypes
+	case TypeJSON:
+		return jsonCastTypes
 	case TypeOid, TypeRegClass, TypeRegNamespace, TypeRegProc, TypeRegProcedure, TypeRegType:
 		return oidCastTypes
 	default:
@@ -1225,6 +1238,7 @@ func (node *DFloat) String() string           { return AsString(node) }
 func (node *DInt) String() string             { return AsString(node) }
 func (node *DInterval) String() string        { return AsString(node) }
 func (node *DUuid) String() string            { return AsString(node) }
+func (node *DJSON) String() string            { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
 func (node *DCollatedString) String() string  { return AsString(node) }
 func (node *DTimestamp) String() string       { return AsString(node) }
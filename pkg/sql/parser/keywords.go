@@ -30,9 +30,12 @@ var keywords = map[string]int{
 	"BY":                        BY,
 	"BYTEA":                     BYTEA,
 	"BYTES":                     BYTES,
+	"CACHE":                     CACHE,
+	"CANCEL":                    CANCEL,
 	"CASCADE":                   CASCADE,
 	"CASE":                      CASE,
 	"CAST":                      CAST,
+	"CHANGEFEED":                CHANGEFEED,
 	"CHAR":                      CHAR,
 	"CHARACTER":                 CHARACTER,
 	"CHARACTERISTICS":           CHARACTERISTICS,
@@ -83,6 +86,7 @@ var keywords = map[string]int{
 	"EXCEPT":                    EXCEPT,
 	"EXECUTE":                   EXECUTE,
 	"EXISTS":                    EXISTS,
+	"EXPERIMENTAL":              EXPERIMENTAL,
 	"EXPERIMENTAL_FINGERPRINTS": EXPERIMENTAL_FINGERPRINTS,
 	"EXPLAIN":                   EXPLAIN,
 	"EXTRACT":                   EXTRACT,
@@ -111,7 +115,9 @@ var keywords = map[string]int{
 	"IF":                        IF,
 	"IFNULL":                    IFNULL,
 	"ILIKE":                     ILIKE,
+	"IMPORT":                    IMPORT,
 	"IN":                        IN,
+	"INCREMENT":                 INCREMENT,
 	"INCREMENTAL":               INCREMENTAL,
 	"INDEX":                     INDEX,
 	"INDEXES":                   INDEXES,
@@ -127,9 +133,13 @@ var keywords = map[string]int{
 	"INTERSECT":                 INTERSECT,
 	"INTERVAL":                  INTERVAL,
 	"INTO":                      INTO,
+	"INVERTED":                  INVERTED,
 	"IS":                        IS,
 	"ISOLATION":                 ISOLATION,
+	"JOB":                       JOB,
 	"JOIN":                      JOIN,
+	"JSON":                      JSON,
+	"JSONB":                     JSONB,
 	"KEY":                       KEY,
 	"KEYS":                      KEYS,
 	"LATERAL":                   LATERAL,
@@ -141,12 +151,15 @@ var keywords = map[string]int{
 	"LEVEL":                     LEVEL,
 	"LIKE":                      LIKE,
 	"LIMIT":                     LIMIT,
+	"LIST":                      LIST,
 	"LOCAL":                     LOCAL,
 	"LOCALTIME":                 LOCALTIME,
 	"LOCALTIMESTAMP":            LOCALTIMESTAMP,
 	"LOW":                       LOW,
 	"MATCH":                     MATCH,
+	"MAXVALUE":                  MAXVALUE,
 	"MINUTE":                    MINUTE,
+	"MINVALUE":                  MINVALUE,
 	"MONTH":                     MONTH,
 	"NAME":                      NAME,
 	"NAMES":                     NAMES,
@@ -181,6 +194,8 @@ var keywords = map[string]int{
 	"PARTIAL":                   PARTIAL,
 	"PARTITION":                 PARTITION,
 	"PASSWORD":                  PASSWORD,
+	"PAUSE":                     PAUSE,
+	"PHYSICAL":                  PHYSICAL,
 	"PLACING":                   PLACING,
 	"POSITION":                  POSITION,
 	"PRECEDING":                 PRECEDING,
@@ -189,6 +204,7 @@ var keywords = map[string]int{
 	"PRIMARY":                   PRIMARY,
 	"PRIORITY":                  PRIORITY,
 	"QUERIES":                   QUERIES,
+	"QUERY":                     QUERY,
 	"RANGE":                     RANGE,
 	"READ":                      READ,
 	"REAL":                      REAL,
@@ -206,6 +222,7 @@ var keywords = map[string]int{
 	"RESET":                     RESET,
 	"RESTORE":                   RESTORE,
 	"RESTRICT":                  RESTRICT,
+	"RESUME":                    RESUME,
 	"RETURNING":                 RETURNING,
 	"REVOKE":                    REVOKE,
 	"RIGHT":                     RIGHT,
@@ -215,9 +232,11 @@ var keywords = map[string]int{
 	"ROWS":                      ROWS,
 	"SAVEPOINT":                 SAVEPOINT,
 	"SCATTER":                   SCATTER,
+	"SCRUB":                     SCRUB,
 	"SEARCH":                    SEARCH,
 	"SECOND":                    SECOND,
 	"SELECT":                    SELECT,
+	"SEQUENCE":                  SEQUENCE,
 	"SERIAL":                    SERIAL,
 	"SERIALIZABLE":              SERIALIZABLE,
 	"SESSION":                   SESSION,
@@ -238,6 +257,7 @@ var keywords = map[string]int{
 	"START":                     START,
 	"STATUS":                    STATUS,
 	"STDIN":                     STDIN,
+	"STORED":                    STORED,
 	"STORING":                   STORING,
 	"STRICT":                    STRICT,
 	"STRING":                    STRING,
@@ -451,6 +451,7 @@ func (expr *ComparisonExpr) normalize(v *normalizeVisitor) TypedExpr {
 		SimilarTo, NotSimilarTo,
 		RegMatch, NotRegMatch,
 		RegIMatch, NotRegIMatch,
+		Contains, ContainedBy, JSONExists,
 		Any, Some, All:
 		if expr.TypedLeft() == DNull || expr.TypedRight() == DNull {
 			return DNull
@@ -82,6 +82,8 @@ func TestParse(t *testing.T) {
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
 		{`CREATE UNIQUE INDEX a ON b.c (d)`},
+		{`CREATE INVERTED INDEX a ON b (c)`},
+		{`CREATE INVERTED INDEX IF NOT EXISTS a ON b (c)`},
 
 		{`CREATE TABLE a ()`},
 		{`CREATE TABLE a (b INT)`},
@@ -97,6 +99,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b SMALLSERIAL)`},
 		{`CREATE TABLE a (b BIGSERIAL)`},
 		{`CREATE TABLE a (b UUID)`},
+		{`CREATE TABLE a (b JSON)`},
+		{`CREATE TABLE a (b JSONB)`},
 		{`CREATE TABLE a (b INT NULL)`},
 		{`CREATE TABLE a (b INT CONSTRAINT maybe NULL)`},
 		{`CREATE TABLE a (b INT NOT NULL)`},
@@ -107,6 +111,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT DEFAULT 1)`},
 		{`CREATE TABLE a (b INT CONSTRAINT one DEFAULT 1)`},
 		{`CREATE TABLE a (b INT DEFAULT now())`},
+		{`CREATE TABLE a (b INT, c INT AS (b + 1) STORED)`},
+		{`CREATE TABLE a (b STRING, c STRING NOT NULL UNIQUE AS (lower(b)) STORED)`},
 		{`CREATE TABLE a (a INT CHECK (a > 0))`},
 		{`CREATE TABLE a (a INT CONSTRAINT positive CHECK (a > 0))`},
 		{`CREATE TABLE a (a INT DEFAULT 1 CHECK (a > 0))`},
@@ -133,10 +139,19 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, INDEX (b) STORING (c))`},
 		{`CREATE TABLE a (b INT, c TEXT, INDEX (b ASC, c DESC) STORING (c))`},
 		{`CREATE TABLE a (b INT, INDEX (b) INTERLEAVE IN PARENT c (d, e))`},
+		{`CREATE TABLE a (b JSONB, INVERTED INDEX (b))`},
+		{`CREATE TABLE a (b JSONB, INVERTED INDEX c (b))`},
 		{`CREATE TABLE a (b INT, FAMILY (b))`},
 		{`CREATE TABLE a (b INT, c STRING, FAMILY foo (b), FAMILY (c))`},
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c, d)`},
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c) CASCADE`},
+		{`CREATE TABLE a (b TIMESTAMP) WITH (ttl_expire_after = '30 days', ttl_column = 'b')`},
+		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c) WITH (foo = 1)`},
+		{`CREATE TABLE a (b STRING, c INT, PRIMARY KEY (b, c)) PARTITION BY LIST (b) (PARTITION eu VALUES IN ('de', 'fr'), PARTITION other VALUES IN (DEFAULT))`},
+		{`CREATE TABLE a (b INT, c INT, PRIMARY KEY (b, c)) PARTITION BY LIST (b, c) (PARTITION p1 VALUES IN ((1, 2), (3, 4)))`},
+		{`CREATE TABLE a (b INT PRIMARY KEY) PARTITION BY RANGE (b) (PARTITION small VALUES < 10, PARTITION big VALUES < maxvalue)`},
+		{`CREATE TABLE a (b INT, c INT, PRIMARY KEY (b, c)) PARTITION BY RANGE (b, c) (PARTITION p1 VALUES < (1, maxvalue))`},
+		{`CREATE TABLE a (b INT PRIMARY KEY) INTERLEAVE IN PARENT foo (b) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1))`},
 		{`CREATE TABLE a.b (b INT)`},
 		{`CREATE TABLE IF NOT EXISTS a (b INT)`},
 
@@ -164,6 +179,13 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
 
+		{`CREATE SEQUENCE a`},
+		{`CREATE SEQUENCE IF NOT EXISTS a`},
+		{`CREATE SEQUENCE a.b`},
+		{`CREATE SEQUENCE a INCREMENT BY 5 START WITH 1000`},
+		{`CREATE SEQUENCE a INCREMENT BY -1 MINVALUE -100 MAXVALUE -1`},
+		{`CREATE SEQUENCE a NO MINVALUE NO MAXVALUE CACHE 10 NO CYCLE`},
+
 		{`DELETE FROM a`},
 		{`DELETE FROM a.b`},
 		{`DELETE FROM a WHERE a = b`},
@@ -203,6 +225,12 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW a.b CASCADE`},
 		{`DROP VIEW a, b CASCADE`},
 
+		{`DROP SEQUENCE a`},
+		{`DROP SEQUENCE a.b`},
+		{`DROP SEQUENCE IF EXISTS a, b`},
+		{`DROP SEQUENCE a RESTRICT`},
+		{`DROP SEQUENCE a, b CASCADE`},
+
 		{`DROP USER a`},
 		{`DROP USER a, b`},
 
@@ -210,6 +238,9 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN EXPLAIN SELECT 1`},
 		{`EXPLAIN (DEBUG) SELECT 1`},
 		{`EXPLAIN (A, B, C) SELECT 1`},
+		{`EXPLAIN (ANALYZE) SELECT 1`},
+		{`EXPLAIN (ANALYZE, VERBOSE) SELECT 1`},
+		{`SELECT * FROM [EXPLAIN (ANALYZE) SELECT 1]`},
 		{`SELECT * FROM [EXPLAIN SELECT 1]`},
 		{`SELECT * FROM [SHOW TRANSACTION STATUS]`},
 
@@ -238,6 +269,21 @@ func TestParse(t *testing.T) {
 		{`SHOW LOCAL QUERIES`},
 		{`SHOW CLUSTER SESSIONS`},
 		{`SHOW LOCAL SESSIONS`},
+
+		{`CANCEL QUERY a`},
+		{`CANCEL QUERY 'abc'`},
+		{`CANCEL QUERY $1`},
+		{`CANCEL SESSION a`},
+		{`CANCEL SESSION 'abc'`},
+
+		{`PAUSE JOB a`},
+		{`PAUSE JOB 123`},
+		{`PAUSE JOB $1`},
+		{`RESUME JOB a`},
+		{`RESUME JOB 123`},
+		{`CANCEL JOB a`},
+		{`CANCEL JOB 123`},
+
 		{`SHOW TESTING_RANGES FROM TABLE d.t`},
 		{`SHOW TESTING_RANGES FROM TABLE t`},
 		{`SHOW TESTING_RANGES FROM INDEX d.t@i`},
@@ -330,6 +376,9 @@ func TestParse(t *testing.T) {
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a, b) DO UPDATE SET a = 1`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1, b = excluded.a`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1 WHERE b > 2`},
+		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1 WHERE a.b > excluded.b`},
+		{`INSERT INTO a VALUES (1) ON CONFLICT ON CONSTRAINT a_idx DO NOTHING`},
+		{`INSERT INTO a VALUES (1) ON CONFLICT ON CONSTRAINT a_idx DO UPDATE SET a = excluded.a`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = DEFAULT`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET (a, b) = (SELECT 1, 2)`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET (a, b) = (SELECT 1, 2) RETURNING a, b`},
@@ -468,6 +517,12 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t WHERE a !~ b`},
 		{`SELECT a FROM t WHERE a ~* c`},
 		{`SELECT a FROM t WHERE a !~* c`},
+		{`SELECT a FROM t WHERE a @> b`},
+		{`SELECT a FROM t WHERE a <@ b`},
+		{`SELECT a FROM t WHERE a ? b`},
+		{`SELECT a -> b FROM t`},
+		{`SELECT a ->> b FROM t`},
+		{`SELECT a -> b -> c FROM t`},
 		{`SELECT a FROM t WHERE a BETWEEN b AND c`},
 		{`SELECT a FROM t WHERE a NOT BETWEEN b AND c`},
 		{`SELECT a FROM t WHERE a IS NULL`},
@@ -577,6 +632,11 @@ func TestParse(t *testing.T) {
 		{`SELECT * FROM (VALUES (1, 2)) AS foo`},
 		{`SELECT * FROM (VALUES (1, 2)) AS foo (a, b)`},
 
+		{`WITH a AS (SELECT 1) SELECT * FROM a`},
+		{`WITH a (x, y) AS (SELECT 1, 2), b AS (SELECT * FROM a) SELECT * FROM b ORDER BY x LIMIT 1`},
+		{`SELECT * FROM (WITH a AS (SELECT 1) SELECT * FROM a)`},
+		{`INSERT INTO t WITH a AS (SELECT 1) SELECT * FROM a`},
+
 		{`SELECT * FROM [123] AS t`},
 		{`SELECT * FROM [123(1, 2, 3)] AS t`},
 		{`SELECT * FROM [123()] AS t`},
@@ -609,6 +669,9 @@ func TestParse(t *testing.T) {
 		{`SELECT * FROM "0" JOIN "0" USING (id, "0")`}, // last "0" lost its quotes.
 
 		{`ALTER DATABASE a RENAME TO b`},
+		{`ALTER SEQUENCE a INCREMENT BY 2`},
+		{`ALTER SEQUENCE IF EXISTS a.b MAXVALUE 10 NO MINVALUE`},
+
 		{`ALTER TABLE a RENAME TO b`},
 		{`ALTER TABLE IF EXISTS a RENAME TO b`},
 		{`ALTER INDEX a@b RENAME TO b`},
@@ -629,6 +692,7 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a ADD b INT CREATE FAMILY`},
 		{`ALTER TABLE a ADD b INT CREATE FAMILY fam_b`},
 		{`ALTER TABLE a ADD b INT CREATE IF NOT EXISTS FAMILY fam_b`},
+		{`ALTER TABLE a ADD COLUMN c INT AS (b * 2) STORED`},
 
 		{`ALTER TABLE a DROP b, DROP CONSTRAINT a_idx`},
 		{`ALTER TABLE a DROP IF EXISTS b, DROP CONSTRAINT a_idx`},
@@ -643,6 +707,10 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a SET (ttl_expire_after = '1 day')`},
+		{`ALTER TABLE a SET (ttl_expire_after = '1 day', ttl_column = 'b')`},
+		{`ALTER TABLE a RESET (ttl_expire_after)`},
+		{`ALTER TABLE a RESET (ttl_expire_after, ttl_column)`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT 42`},
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
@@ -689,6 +757,19 @@ func TestParse(t *testing.T) {
 		{`RESTORE DATABASE foo, baz FROM 'bar' AS OF SYSTEM TIME '1'`},
 		{`BACKUP foo TO 'bar' WITH OPTIONS ('key1', 'key2'='value')`},
 		{`RESTORE foo FROM 'bar' WITH OPTIONS ('key1', 'key2'='value')`},
+
+		{`CREATE CHANGEFEED FOR foo INTO 'sink'`},
+		{`CREATE CHANGEFEED FOR foo, db.bar INTO $1`},
+		{`CREATE CHANGEFEED FOR foo INTO 'sink' WITH OPTIONS ('updated')`},
+
+		{`IMPORT PGDUMP 'nodelocal:///foo/dump.sql'`},
+		{`IMPORT MYSQLDUMP $1 WITH OPTIONS ('into_db'='foo', 'temp'='bar')`},
+
+		{`EXPERIMENTAL SCRUB TABLE t`},
+		{`EXPERIMENTAL SCRUB TABLE d.t AS OF SYSTEM TIME '2017-01-01'`},
+		{`EXPERIMENTAL SCRUB TABLE t WITH OPTIONS INDEX ALL`},
+		{`EXPERIMENTAL SCRUB TABLE t WITH OPTIONS INDEX (a, b), CONSTRAINT ALL`},
+		{`EXPERIMENTAL SCRUB TABLE t WITH OPTIONS CONSTRAINT (c), PHYSICAL`},
 		{`SET ROW (1, true, NULL)`},
 
 		// Regression for #15926
@@ -724,7 +805,11 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b))`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) INTERLEAVE IN PARENT c (d))`,
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
+		{`CREATE SEQUENCE a INCREMENT 5 START 1000`,
+			`CREATE SEQUENCE a INCREMENT BY 5 START WITH 1000`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
+		{`EXPLAIN ANALYZE SELECT 1`, `EXPLAIN (ANALYZE) SELECT 1`},
+		{`SELECT * FROM [EXPLAIN ANALYZE SELECT 1]`, `SELECT * FROM [EXPLAIN (ANALYZE) SELECT 1]`},
 
 		{`SELECT TIMESTAMP WITHOUT TIME ZONE 'foo'`, `SELECT TIMESTAMP 'foo'`},
 		{`SELECT CAST('foo' AS TIMESTAMP WITHOUT TIME ZONE)`, `SELECT CAST('foo' AS TIMESTAMP)`},
@@ -962,6 +1047,10 @@ func TestParse2(t *testing.T) {
 			`BACKUP DATABASE foo TO 'bar.12' INCREMENTAL FROM 'baz.34'`},
 		{`RESTORE DATABASE foo FROM bar`,
 			`RESTORE DATABASE foo FROM 'bar'`},
+		{`CREATE CHANGEFEED FOR TABLE foo INTO sink`,
+			`CREATE CHANGEFEED FOR foo INTO 'sink'`},
+		{`IMPORT pgdump 'dump.sql'`,
+			`IMPORT PGDUMP 'dump.sql'`},
 
 		{`SHOW ALL CLUSTER SETTINGS`, `SHOW CLUSTER SETTING all`},
 
@@ -1080,6 +1169,14 @@ CREATE TABLE test (
   foo INT DEFAULT 1 DEFAULT 2
 )
 ^
+`},
+		{`CREATE TABLE test (
+  foo INT DEFAULT 1 AS (2) STORED
+)`, `computed column "foo" cannot also have a DEFAULT expression at or near ")"
+CREATE TABLE test (
+  foo INT DEFAULT 1 AS (2) STORED
+)
+^
 `},
 		{`CREATE TABLE test (
   foo INT REFERENCES t1 REFERENCES t2
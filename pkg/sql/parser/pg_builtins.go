@@ -39,6 +39,7 @@ var typeBuiltinsHaveUnderscore = map[oid.Oid]struct{}{
 	TypeDecimal.Oid():     {},
 	TypeInterval.Oid():    {},
 	TypeUUID.Oid():        {},
+	TypeJSON.Oid():        {},
 	TypeTimestamp.Oid():   {},
 	TypeTimestampTZ.Oid(): {},
 	TypeTuple.Oid():       {},
@@ -0,0 +1,112 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "bytes"
+
+// Scrub represents an EXPERIMENTAL SCRUB TABLE statement.
+type Scrub struct {
+	Table *NormalizableTableName
+	AsOf  AsOfClause
+	// Options is nil when every check is to be run.
+	Options ScrubOptions
+}
+
+var _ Statement = &Scrub{}
+
+// Format implements the NodeFormatter interface.
+func (node *Scrub) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("EXPERIMENTAL SCRUB TABLE ")
+	FormatNode(buf, f, node.Table)
+	if node.AsOf.Expr != nil {
+		buf.WriteByte(' ')
+		FormatNode(buf, f, node.AsOf)
+	}
+	if node.Options != nil {
+		buf.WriteString(" WITH OPTIONS ")
+		FormatNode(buf, f, node.Options)
+	}
+}
+
+// ScrubOptions is the list of checks of a SCRUB statement.
+type ScrubOptions []ScrubOption
+
+// Format implements the NodeFormatter interface.
+func (n ScrubOptions) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i, option := range n {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, option)
+	}
+}
+
+// ScrubOption is one of the checks of a SCRUB statement.
+type ScrubOption interface {
+	NodeFormatter
+
+	scrubOptionType()
+}
+
+func (*ScrubOptionIndex) scrubOptionType()      {}
+func (*ScrubOptionConstraint) scrubOptionType() {}
+func (*ScrubOptionPhysical) scrubOptionType()   {}
+
+// ScrubOptionIndex checks that the entries of the secondary indexes match
+// the rows of the primary index, as in INDEX ALL or INDEX (a, b).
+type ScrubOptionIndex struct {
+	// IndexNames is nil for INDEX ALL.
+	IndexNames NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (n *ScrubOptionIndex) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("INDEX ")
+	if n.IndexNames == nil {
+		buf.WriteString("ALL")
+		return
+	}
+	buf.WriteByte('(')
+	FormatNode(buf, f, n.IndexNames)
+	buf.WriteByte(')')
+}
+
+// ScrubOptionConstraint checks that the rows satisfy the CHECK and FOREIGN
+// KEY constraints of the table, as in CONSTRAINT ALL or CONSTRAINT (a, b).
+type ScrubOptionConstraint struct {
+	// ConstraintNames is nil for CONSTRAINT ALL.
+	ConstraintNames NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (n *ScrubOptionConstraint) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CONSTRAINT ")
+	if n.ConstraintNames == nil {
+		buf.WriteString("ALL")
+		return
+	}
+	buf.WriteByte('(')
+	FormatNode(buf, f, n.ConstraintNames)
+	buf.WriteByte(')')
+}
+
+// ScrubOptionPhysical checks that every key and value of the indexes of the
+// table can be decoded.
+type ScrubOptionPhysical struct{}
+
+// Format implements the NodeFormatter interface.
+func (n *ScrubOptionPhysical) Format(buf *bytes.Buffer, _ FmtFlags) {
+	buf.WriteString("PHYSICAL")
+}
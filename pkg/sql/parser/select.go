@@ -38,8 +38,10 @@ func (*SelectClause) selectStatement() {}
 func (*UnionClause) selectStatement()  {}
 func (*ValuesClause) selectStatement() {}
 
-// Select represents a SelectStatement with an ORDER and/or LIMIT.
+// Select represents a SelectStatement with an ORDER and/or LIMIT, and
+// the common table expressions it can refer to.
 type Select struct {
+	With    *With
 	Select  SelectStatement
 	OrderBy OrderBy
 	Limit   *Limit
@@ -47,11 +49,41 @@ type Select struct {
 
 // Format implements the NodeFormatter interface.
 func (node *Select) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.With)
 	FormatNode(buf, f, node.Select)
 	FormatNode(buf, f, node.OrderBy)
 	FormatNode(buf, f, node.Limit)
 }
 
+// With represents a WITH clause.
+type With struct {
+	CTEList []*CTE
+}
+
+// Format implements the NodeFormatter interface.
+func (node *With) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node == nil {
+		return
+	}
+	buf.WriteString("WITH ")
+	for i, cte := range node.CTEList {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, cte.Name)
+		buf.WriteString(" AS (")
+		FormatNode(buf, f, cte.Stmt)
+		buf.WriteByte(')')
+	}
+	buf.WriteByte(' ')
+}
+
+// CTE represents a common table expression inside of a WITH clause.
+type CTE struct {
+	Name AliasClause
+	Stmt Statement
+}
+
 // ParenSelect represents a parenthesized SELECT/UNION/VALUES statement.
 type ParenSelect struct {
 	Select *Select
@@ -24,6 +24,7 @@ import (
 
     "go/constant"
     "go/token"
+    "strings"
 
     "github.com/cockroachdb/cockroach/pkg/sql/privilege"
 )
@@ -345,9 +346,54 @@ func (u *sqlSymUnion) kvOptions() []KVOption {
     }
     return nil
 }
+func (u *sqlSymUnion) scrubOptions() ScrubOptions {
+    return u.val.(ScrubOptions)
+}
+func (u *sqlSymUnion) scrubOption() ScrubOption {
+    return u.val.(ScrubOption)
+}
 func (u *sqlSymUnion) transactionModes() TransactionModes {
     return u.val.(TransactionModes)
 }
+func (u *sqlSymUnion) with() *With {
+    return u.val.(*With)
+}
+func (u *sqlSymUnion) cte() *CTE {
+    return u.val.(*CTE)
+}
+func (u *sqlSymUnion) ctes() []*CTE {
+    return u.val.([]*CTE)
+}
+func (u *sqlSymUnion) int64() int64 {
+    return u.val.(int64)
+}
+func (u *sqlSymUnion) seqOpt() SequenceOption {
+    return u.val.(SequenceOption)
+}
+func (u *sqlSymUnion) seqOpts() SequenceOptions {
+    return u.val.(SequenceOptions)
+}
+func (u *sqlSymUnion) storageParam() StorageParam {
+    return u.val.(StorageParam)
+}
+func (u *sqlSymUnion) storageParams() StorageParams {
+    return u.val.(StorageParams)
+}
+func (u *sqlSymUnion) partitionBy() *PartitionBy {
+    return u.val.(*PartitionBy)
+}
+func (u *sqlSymUnion) listPartition() ListPartition {
+    return u.val.(ListPartition)
+}
+func (u *sqlSymUnion) listPartitions() []ListPartition {
+    return u.val.([]ListPartition)
+}
+func (u *sqlSymUnion) rangePartition() RangePartition {
+    return u.val.(RangePartition)
+}
+func (u *sqlSymUnion) rangePartitions() []RangePartition {
+    return u.val.([]RangePartition)
+}
 
 %}
 
@@ -361,6 +407,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   TYPECAST TYPEANNOTATE DOT_DOT
 %token <str>   LESS_EQUALS GREATER_EQUALS NOT_EQUALS
 %token <str>   NOT_REGMATCH REGIMATCH NOT_REGIMATCH
+%token <str>   FETCHVAL FETCHTEXT CONTAINS CONTAINED_BY
 %token <str>   ERROR
 
 // If you want to make any keyword changes, update the keyword table in
@@ -376,7 +423,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
-%token <str>   CASCADE CASE CAST CHAR
+%token <str>   CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
 %token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED CONCAT CONFLICT CONSTRAINT CONSTRAINTS
@@ -390,7 +437,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   DISTINCT DO DOUBLE DROP
 
 %token <str>   ELSE ENCODING END ESCAPE EXCEPT
-%token <str>   EXISTS EXECUTE EXPERIMENTAL_FINGERPRINTS EXPLAIN EXTRACT EXTRACT_DURATION
+%token <str>   EXISTS EXECUTE EXPERIMENTAL EXPERIMENTAL_FINGERPRINTS EXPLAIN EXTRACT EXTRACT_DURATION
 
 %token <str>   FALSE FAMILY FETCH FILTER FIRST FLOAT FLOORDIV FOLLOWING FOR
 %token <str>   FORCE_INDEX FOREIGN FROM FULL
@@ -399,20 +446,20 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %token <str>   HAVING HELP HIGH HOUR
 
-%token <str>   INCREMENTAL IF IFNULL ILIKE IN INTERLEAVE
+%token <str>   IMPORT INCREMENT INCREMENTAL IF IFNULL ILIKE IN INTERLEAVE
 %token <str>   INDEX INDEXES INITIALLY
 %token <str>   INNER INSERT INT INT2VECTOR INT8 INT64 INTEGER
-%token <str>   INTERSECT INTERVAL INTO IS ISOLATION
+%token <str>   INTERSECT INTERVAL INTO INVERTED IS ISOLATION
 
-%token <str>   JOIN
+%token <str>   JOB JOIN JSON JSONB
 
 %token <str>   KEY KEYS
 
 %token <str>   LATERAL LC_CTYPE LC_COLLATE
-%token <str>   LEADING LEAST LEFT LEVEL LIKE LIMIT LOCAL
+%token <str>   LEADING LEAST LEFT LEVEL LIKE LIMIT LIST LOCAL
 %token <str>   LOCALTIME LOCALTIMESTAMP LOW LSHIFT
 
-%token <str>   MATCH MINUTE MONTH
+%token <str>   MATCH MAXVALUE MINUTE MINVALUE MONTH
 
 %token <str>   NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
 %token <str>   NOT NOTHING NULL NULLIF
@@ -421,21 +468,21 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %token <str>   OF OFF OFFSET OID ON ONLY OPTIONS OR
 %token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY
 
-%token <str>   PARENT PARTIAL PARTITION PASSWORD PLACING POSITION
+%token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING POSITION
 %token <str>   PRECEDING PRECISION PREPARE PRIMARY PRIORITY
 
-%token <str>   QUERIES
+%token <str>   QUERIES QUERY
 
 %token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
 %token <str>   REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str>   RENAME REPEATABLE
-%token <str>   RELEASE RESET RESTORE RESTRICT RETURNING REVOKE RIGHT ROLLBACK ROLLUP
+%token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT ROLLBACK ROLLUP
 %token <str>   ROW ROWS RSHIFT
 
-%token <str>   SAVEPOINT SCATTER SEARCH SECOND SELECT
+%token <str>   SAVEPOINT SCATTER SCRUB SEARCH SECOND SELECT SEQUENCE
 %token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str>   SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
-%token <str>   START STATUS STDIN STRICT STRING STORING SUBSTRING
+%token <str>   START STATUS STDIN STRICT STRING STORED STORING SUBSTRING
 %token <str>   SYMMETRIC SYSTEM
 
 %token <str>   TABLE TABLES TEMPLATE TESTING_RANGES TESTING_RELOCATE TEXT THEN
@@ -475,12 +522,17 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <[]Statement> stmt_list
 %type <Statement> stmt
 
+%type <Statement> alter_sequence_stmt
 %type <Statement> alter_table_stmt
 %type <Statement> backup_stmt
+%type <Statement> cancel_stmt
+%type <Statement> import_stmt
 %type <Statement> copy_from_stmt
 %type <Statement> create_stmt
+%type <Statement> create_changefeed_stmt
 %type <Statement> create_database_stmt
 %type <Statement> create_index_stmt
+%type <Statement> create_sequence_stmt
 %type <Statement> create_table_stmt
 %type <Statement> create_table_as_stmt
 %type <Statement> create_user_stmt
@@ -490,6 +542,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> explain_stmt
 %type <Statement> explainable_stmt
 %type <Statement> help_stmt
+%type <Statement> pause_stmt
 %type <Statement> prepare_stmt
 %type <Statement> preparable_stmt
 %type <Statement> execute_stmt
@@ -499,9 +552,11 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <Statement> release_stmt
 %type <Statement> rename_stmt
 %type <Statement> reset_stmt
+%type <Statement> resume_stmt
 %type <Statement> revoke_stmt
 %type <*Select> select_stmt
 %type <Statement> savepoint_stmt
+%type <Statement> scrub_stmt
 %type <Statement> set_stmt
 %type <Statement> show_stmt
 %type <Statement> split_stmt
@@ -516,6 +571,8 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <KVOption> kv_option
 %type <[]KVOption> kv_option_list opt_with_options
 %type <str> opt_equal_value
+%type <ScrubOptions> opt_scrub_options_clause scrub_option_list
+%type <ScrubOption> scrub_option
 
 %type <*Select> select_no_parens
 %type <SelectStatement> select_clause select_with_parens simple_select values_clause
@@ -563,6 +620,13 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <TableDefs> opt_table_elem_list table_elem_list
 %type <*InterleaveDef> opt_interleave
+%type <*PartitionBy> opt_partition_by
+%type <ListPartition> list_partition
+%type <[]ListPartition> list_partitions
+%type <RangePartition> range_partition
+%type <[]RangePartition> range_partitions
+%type <StorageParam> storage_parameter
+%type <StorageParams> storage_parameter_list opt_table_with
 %type <empty> opt_all_clause
 %type <bool> distinct_clause
 %type <NameList> opt_column_list
@@ -616,7 +680,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <empty> first_or_next
 
 %type <Statement>  insert_rest
-%type <NameList> opt_conf_expr
+%type <*OnConflict> opt_conf_expr
 %type <*OnConflict> on_conflict
 
 %type <Statement>  generic_set set_rest set_rest_more
@@ -678,6 +742,10 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 %type <empty> opt_varying
 
 %type <*NumVal>  signed_iconst
+%type <int64>  signed_iconst64
+%type <SequenceOptions> opt_sequence_option_list sequence_option_list
+%type <SequenceOption> sequence_option_elem
+%type <empty> opt_by
 %type <Expr>  opt_boolean_or_string
 %type <Exprs> var_list
 %type <UnresolvedName> var_name
@@ -702,9 +770,10 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 
 %type <Expr>  func_application func_expr_common_subexpr
 %type <Expr>  func_expr func_expr_windowless
-%type <empty> common_table_expr
-%type <empty> with_clause opt_with opt_with_clause
-%type <empty> cte_list
+%type <*CTE> common_table_expr
+%type <*With> with_clause
+%type <empty> opt_with opt_with_clause
+%type <[]*CTE> cte_list
 
 %type <empty> within_group_clause
 %type <Expr> filter_clause
@@ -761,7 +830,7 @@ func (u *sqlSymUnion) transactionModes() TransactionModes {
 // funny behavior of UNBOUNDED on the SQL standard, though.
 %nonassoc  UNBOUNDED         // ideally should have same precedence as IDENT
 %nonassoc  IDENT NULL PARTITION RANGE ROWS PRECEDING FOLLOWING CUBE ROLLUP
-%left      CONCAT       // multi-character ops
+%left      CONCAT FETCHVAL FETCHTEXT CONTAINS CONTAINED_BY '?' // multi-character ops
 %left      '|'
 %left      '#'
 %left      '&'
@@ -810,22 +879,27 @@ stmt_list:
   }
 
 stmt:
-  alter_table_stmt
+  alter_sequence_stmt
+| alter_table_stmt
 | backup_stmt
+| cancel_stmt
 | copy_from_stmt
 | create_stmt
 | delete_stmt
 | drop_stmt
 | explain_stmt
 | help_stmt
+| pause_stmt
 | prepare_stmt
 | execute_stmt
 | deallocate_stmt
 | grant_stmt
+| import_stmt
 | insert_stmt
 | rename_stmt
 | revoke_stmt
 | savepoint_stmt
+| scrub_stmt
 | select_stmt
   {
     $$.val = $1.slct()
@@ -838,6 +912,7 @@ stmt:
 | transaction_stmt
 | release_stmt
 | reset_stmt
+| resume_stmt
 | truncate_stmt
 | update_stmt
 | use_stmt
@@ -856,6 +931,17 @@ alter_table_stmt:
     $$.val = &AlterTable{Table: $5.normalizableTableName(), IfExists: true, Cmds: $6.alterTableCmds()}
   }
 
+// ALTER SEQUENCE [ IF EXISTS ] name sequence_option [ ... ]
+alter_sequence_stmt:
+  ALTER SEQUENCE relation_expr sequence_option_list
+  {
+    $$.val = &AlterSequence{Name: $3.normalizableTableName(), IfExists: false, Options: $4.seqOpts()}
+  }
+| ALTER SEQUENCE IF EXISTS relation_expr sequence_option_list
+  {
+    $$.val = &AlterSequence{Name: $5.normalizableTableName(), IfExists: true, Options: $6.seqOpts()}
+  }
+
 alter_table_cmds:
   alter_table_cmd
   {
@@ -957,6 +1043,16 @@ alter_table_cmd:
       DropBehavior: $4.dropBehavior(),
     }
   }
+  // ALTER TABLE <name> SET (<param> = <value> [, ...])
+| SET '(' storage_parameter_list ')'
+  {
+    $$.val = &AlterTableSetStorageParams{Params: $3.storageParams()}
+  }
+  // ALTER TABLE <name> RESET (<param> [, ...])
+| RESET '(' name_list ')'
+  {
+    $$.val = &AlterTableResetStorageParams{Params: $3.nameList()}
+  }
 
 alter_column_default:
   SET DEFAULT a_expr
@@ -1010,6 +1106,19 @@ backup_stmt:
     $$.val = &Restore{Targets: $2.targetList(), From: $4.exprs(), AsOf: $5.asOfClause(), Options: $6.kvOptions()}
   }
 
+// IMPORT <format> <file> [WITH OPTIONS (...)]
+import_stmt:
+  IMPORT name string_or_placeholder opt_with_options
+  {
+    $$.val = &Import{FileFormat: strings.ToUpper($2), File: $3.expr(), Options: $4.kvOptions()}
+  }
+
+create_changefeed_stmt:
+  CREATE CHANGEFEED FOR targets INTO string_or_placeholder opt_with_options
+  {
+    $$.val = &CreateChangefeed{Targets: $4.targetList(), SinkURI: $6.expr(), Options: $7.kvOptions()}
+  }
+
 string_or_placeholder:
   non_reserved_word_or_sconst
   {
@@ -1087,10 +1196,12 @@ copy_from_stmt:
     $$.val = &CopyFrom{Table: $2.normalizableTableName(), Columns: $4.unresolvedNames(), Stdin: true}
   }
 
-// CREATE [DATABASE|INDEX|TABLE|TABLE AS|VIEW]
+// CREATE [DATABASE|INDEX|SEQUENCE|TABLE|TABLE AS|VIEW]
 create_stmt:
-  create_database_stmt
+  create_changefeed_stmt
+| create_database_stmt
 | create_index_stmt
+| create_sequence_stmt
 | create_table_stmt
 | create_table_as_stmt
 | create_user_stmt
@@ -1145,6 +1256,14 @@ drop_stmt:
   {
     $$.val = &DropView{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
   }
+| DROP SEQUENCE table_name_list opt_drop_behavior
+  {
+    $$.val = &DropSequence{Names: $3.tableNameReferences(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP SEQUENCE IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &DropSequence{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
 | DROP USER name_list
   {
     $$.val = &DropUser{Names: $3.nameList(), IfExists: false}
@@ -1194,6 +1313,10 @@ explain_stmt:
   {
     $$.val = &Explain{Options: $3.strs(), Statement: $5.stmt()}
   }
+| EXPLAIN ANALYZE explainable_stmt
+  {
+    $$.val = &Explain{Options: []string{$2}, Statement: $3.stmt()}
+  }
 
 explainable_stmt:
   select_stmt
@@ -1202,6 +1325,7 @@ explainable_stmt:
   }
 | create_stmt
 | drop_stmt
+| alter_sequence_stmt
 | alter_table_stmt
 | insert_stmt
 | update_stmt
@@ -1225,6 +1349,7 @@ explain_option_list:
 
 explain_option_name:
   non_reserved_word
+| ANALYZE
 
 // PREPARE <plan_name> [(args, ...)] AS <query>
 prepare_stmt:
@@ -1772,6 +1897,96 @@ show_stmt:
     $$.val = &ShowFingerprints{Table: $5.newNormalizableTableName(), AsOf: $6.asOfClause()}
   }
 
+// CANCEL QUERY <query_id>
+// CANCEL SESSION <session_id>
+// CANCEL JOB <job_id>
+//
+// The IDs of the queries and the sessions are shown in the
+// crdb_internal.{node,cluster}_{queries,sessions} tables, and the IDs of the
+// jobs in crdb_internal.jobs.
+cancel_stmt:
+  CANCEL QUERY a_expr
+  {
+    $$.val = &CancelQuery{ID: $3.expr()}
+  }
+| CANCEL SESSION a_expr
+  {
+    $$.val = &CancelSession{ID: $3.expr()}
+  }
+| CANCEL JOB a_expr
+  {
+    $$.val = &CancelJob{ID: $3.expr()}
+  }
+
+// PAUSE JOB <job_id>
+pause_stmt:
+  PAUSE JOB a_expr
+  {
+    $$.val = &PauseJob{ID: $3.expr()}
+  }
+
+// RESUME JOB <job_id>
+resume_stmt:
+  RESUME JOB a_expr
+  {
+    $$.val = &ResumeJob{ID: $3.expr()}
+  }
+
+// EXPERIMENTAL SCRUB TABLE <table> [AS OF SYSTEM TIME <expr>] [WITH OPTIONS <option> [, ...]]
+//
+// The options are:
+//   INDEX ALL | INDEX (<index> [, ...])
+//   CONSTRAINT ALL | CONSTRAINT (<constraint> [, ...])
+//   PHYSICAL
+scrub_stmt:
+  EXPERIMENTAL SCRUB TABLE qualified_name opt_as_of_clause opt_scrub_options_clause
+  {
+    /* SKIP DOC */
+    $$.val = &Scrub{Table: $4.newNormalizableTableName(), AsOf: $5.asOfClause(), Options: $6.scrubOptions()}
+  }
+
+opt_scrub_options_clause:
+  WITH OPTIONS scrub_option_list
+  {
+    $$.val = $3.scrubOptions()
+  }
+| /* EMPTY */
+  {
+    $$.val = ScrubOptions(nil)
+  }
+
+scrub_option_list:
+  scrub_option
+  {
+    $$.val = ScrubOptions{$1.scrubOption()}
+  }
+| scrub_option_list ',' scrub_option
+  {
+    $$.val = append($1.scrubOptions(), $3.scrubOption())
+  }
+
+scrub_option:
+  INDEX ALL
+  {
+    $$.val = &ScrubOptionIndex{}
+  }
+| INDEX '(' name_list ')'
+  {
+    $$.val = &ScrubOptionIndex{IndexNames: $3.nameList()}
+  }
+| CONSTRAINT ALL
+  {
+    $$.val = &ScrubOptionConstraint{}
+  }
+| CONSTRAINT '(' name_list ')'
+  {
+    $$.val = &ScrubOptionConstraint{ConstraintNames: $3.nameList()}
+  }
+| PHYSICAL
+  {
+    $$.val = &ScrubOptionPhysical{}
+  }
+
 help_stmt:
   HELP unrestricted_name
   {
@@ -1845,13 +2060,13 @@ scatter_stmt:
 
 // CREATE TABLE relname
 create_table_stmt:
-  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave
+  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by opt_table_with
   {
-    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: $7.interleave(), Defs: $5.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: $7.interleave(), PartitionBy: $8.partitionBy(), Defs: $5.tblDefs(), AsSource: nil, AsColumnNames: nil, StorageParams: $9.storageParams()}
   }
-| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave
+| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by opt_table_with
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: $10.interleave(), Defs: $8.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: $10.interleave(), PartitionBy: $11.partitionBy(), Defs: $8.tblDefs(), AsSource: nil, AsColumnNames: nil, StorageParams: $12.storageParams()}
   }
 
 create_table_as_stmt:
@@ -1907,6 +2122,87 @@ opt_interleave:
     $$.val = (*InterleaveDef)(nil)
   }
 
+opt_partition_by:
+  PARTITION BY LIST '(' name_list ')' '(' list_partitions ')'
+  {
+    $$.val = &PartitionBy{
+      Fields: $5.nameList(),
+      List: $8.listPartitions(),
+    }
+  }
+| PARTITION BY RANGE '(' name_list ')' '(' range_partitions ')'
+  {
+    $$.val = &PartitionBy{
+      Fields: $5.nameList(),
+      Range: $8.rangePartitions(),
+    }
+  }
+| /* EMPTY */
+  {
+    $$.val = (*PartitionBy)(nil)
+  }
+
+list_partitions:
+  list_partition
+  {
+    $$.val = []ListPartition{$1.listPartition()}
+  }
+| list_partitions ',' list_partition
+  {
+    $$.val = append($1.listPartitions(), $3.listPartition())
+  }
+
+// Each value is either a tuple matching the partitioning columns (or a single
+// expression when there is only one), or DEFAULT to match all other rows.
+list_partition:
+  PARTITION name VALUES IN '(' ctext_expr_list ')'
+  {
+    $$.val = ListPartition{Name: Name($2), Exprs: $6.exprs()}
+  }
+
+range_partitions:
+  range_partition
+  {
+    $$.val = []RangePartition{$1.rangePartition()}
+  }
+| range_partitions ',' range_partition
+  {
+    $$.val = append($1.rangePartitions(), $3.rangePartition())
+  }
+
+// The upper bound is exclusive and may use MAXVALUE for any of its columns.
+range_partition:
+  PARTITION name VALUES '<' a_expr
+  {
+    $$.val = RangePartition{Name: Name($2), Expr: $5.expr()}
+  }
+
+opt_table_with:
+  WITH '(' storage_parameter_list ')'
+  {
+    $$.val = $3.storageParams()
+  }
+| /* EMPTY */
+  {
+    $$.val = StorageParams(nil)
+  }
+
+storage_parameter_list:
+  storage_parameter
+  {
+    $$.val = StorageParams{$1.storageParam()}
+  }
+| storage_parameter_list ',' storage_parameter
+  {
+    $$.val = append($1.storageParams(), $3.storageParam())
+  }
+
+storage_parameter:
+  name '=' a_expr
+  {
+    $$.val = StorageParam{Key: Name($1), Value: $3.expr()}
+  }
+
 // TODO(dan): This can be removed in favor of opt_drop_behavior when #7854 is fixed.
 opt_interleave_drop_behavior:
   CASCADE
@@ -2008,6 +2304,10 @@ col_qualification_elem:
   {
     $$.val = &ColumnDefault{Expr: $2.expr()}
   }
+| AS '(' a_expr ')' STORED
+  {
+    $$.val = &ColumnComputedDef{Expr: $3.expr()}
+  }
 | REFERENCES qualified_name opt_name_parens key_match key_actions
  {
     $$.val = &ColumnFKConstraint{
@@ -2037,6 +2337,14 @@ index_def:
       },
     }
   }
+| INVERTED INDEX opt_name '(' index_params ')'
+  {
+    $$.val = &IndexTableDef{
+      Name:     Name($3),
+      Columns:  $5.idxElems(),
+      Inverted: true,
+    }
+  }
 
 family_def:
   FAMILY opt_name '(' name_list ')'
@@ -2212,6 +2520,81 @@ create_view_stmt:
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
+// CREATE SEQUENCE [ IF NOT EXISTS ] name [ sequence_option ... ]
+create_sequence_stmt:
+  CREATE SEQUENCE any_name opt_sequence_option_list
+  {
+    $$.val = &CreateSequence{Name: $3.normalizableTableName(), Options: $4.seqOpts()}
+  }
+| CREATE SEQUENCE IF NOT EXISTS any_name opt_sequence_option_list
+  {
+    $$.val = &CreateSequence{Name: $6.normalizableTableName(), IfNotExists: true, Options: $7.seqOpts()}
+  }
+
+opt_sequence_option_list:
+  sequence_option_list
+| /* EMPTY */
+  {
+    $$.val = SequenceOptions(nil)
+  }
+
+sequence_option_list:
+  sequence_option_elem
+  {
+    $$.val = SequenceOptions{$1.seqOpt()}
+  }
+| sequence_option_list sequence_option_elem
+  {
+    $$.val = append($1.seqOpts(), $2.seqOpt())
+  }
+
+sequence_option_elem:
+  CACHE signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptCache, IntVal: &x}
+  }
+| CYCLE
+  {
+    return unimplemented(sqllex, "sequence cycle")
+  }
+| NO CYCLE
+  {
+    $$.val = SequenceOption{Name: SeqOptNoCycle}
+  }
+| INCREMENT opt_by signed_iconst64
+  {
+    x := $3.int64()
+    $$.val = SequenceOption{Name: SeqOptIncrement, IntVal: &x}
+  }
+| MINVALUE signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptMinValue, IntVal: &x}
+  }
+| NO MINVALUE
+  {
+    $$.val = SequenceOption{Name: SeqOptMinValue}
+  }
+| MAXVALUE signed_iconst64
+  {
+    x := $2.int64()
+    $$.val = SequenceOption{Name: SeqOptMaxValue, IntVal: &x}
+  }
+| NO MAXVALUE
+  {
+    $$.val = SequenceOption{Name: SeqOptMaxValue}
+  }
+| START opt_with signed_iconst64
+  {
+    x := $3.int64()
+    $$.val = SequenceOption{Name: SeqOptStart, IntVal: &x}
+  }
+
+opt_by:
+  BY {}
+| /* EMPTY */ {}
+
 // CREATE INDEX
 create_index_stmt:
   CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave
@@ -2237,6 +2620,25 @@ create_index_stmt:
       Interleave: $14.interleave(),
     }
   }
+| CREATE INVERTED INDEX opt_name ON qualified_name '(' index_params ')'
+  {
+    $$.val = &CreateIndex{
+      Name:     Name($4),
+      Table:    $6.normalizableTableName(),
+      Inverted: true,
+      Columns:  $8.idxElems(),
+    }
+  }
+| CREATE INVERTED INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')'
+  {
+    $$.val = &CreateIndex{
+      Name:        Name($7),
+      Table:       $9.normalizableTableName(),
+      Inverted:    true,
+      IfNotExists: true,
+      Columns:     $11.idxElems(),
+    }
+  }
 
 opt_unique:
   UNIQUE
@@ -2593,23 +2995,33 @@ insert_rest:
 on_conflict:
   ON CONFLICT opt_conf_expr DO UPDATE SET set_clause_list where_clause
   {
-    $$.val = &OnConflict{Columns: $3.nameList(), Exprs: $7.updateExprs(), Where: newWhere(astWhere, $8.expr())}
+    $$.val = $3.onConflict()
+    $$.val.(*OnConflict).Exprs = $7.updateExprs()
+    $$.val.(*OnConflict).Where = newWhere(astWhere, $8.expr())
   }
 | ON CONFLICT opt_conf_expr DO NOTHING
   {
-    $$.val = &OnConflict{Columns: $3.nameList(), DoNothing: true}
+    $$.val = $3.onConflict()
+    $$.val.(*OnConflict).DoNothing = true
   }
 
 opt_conf_expr:
   '(' name_list ')' where_clause
   {
-    // TODO(dan): Support the where_clause.
-    $$.val = $2.nameList()
+    if $4.expr() != nil {
+      // Partial indexes are not supported, so the conflict target cannot
+      // have an index predicate.
+      return unimplemented(sqllex, "on conflict with index predicate")
+    }
+    $$.val = &OnConflict{Columns: $2.nameList()}
+  }
+| ON CONSTRAINT name
+  {
+    $$.val = &OnConflict{Constraint: Name($3)}
   }
-| ON CONSTRAINT name { return unimplemented(sqllex, "on conflict on constraint") }
 | /* EMPTY */
   {
-    $$.val = NameList(nil)
+    $$.val = &OnConflict{}
   }
 
 returning_clause:
@@ -2753,15 +3165,15 @@ select_no_parens:
   }
 | with_clause select_clause
   {
-    $$.val = &Select{Select: $2.selectStmt()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt()}
   }
 | with_clause select_clause sort_clause
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy()}
   }
 | with_clause select_clause opt_sort_clause select_limit
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
   }
 
 select_clause:
@@ -2864,16 +3276,34 @@ simple_select:
 //
 // Recognizing WITH_LA here allows a CTE to be named TIME or ORDINALITY.
 with_clause:
-WITH cte_list { return unimplemented(sqllex, "with cte_list") }
-| WITH_LA cte_list { return unimplemented(sqllex, "with cte_list") }
-| WITH RECURSIVE cte_list { return unimplemented(sqllex, "with cte_list") }
+  WITH cte_list
+  {
+    $$.val = &With{CTEList: $2.ctes()}
+  }
+| WITH_LA cte_list
+  {
+    $$.val = &With{CTEList: $2.ctes()}
+  }
+| WITH RECURSIVE cte_list { return unimplemented(sqllex, "with recursive") }
 
 cte_list:
-  common_table_expr { return unimplemented(sqllex, "cte_list") }
-| cte_list ',' common_table_expr { return unimplemented(sqllex, "cte_list") }
+  common_table_expr
+  {
+    $$.val = []*CTE{$1.cte()}
+  }
+| cte_list ',' common_table_expr
+  {
+    $$.val = append($1.ctes(), $3.cte())
+  }
 
 common_table_expr:
-  name opt_name_list AS '(' preparable_stmt ')' { return unimplemented(sqllex, "cte") }
+  name opt_name_list AS '(' preparable_stmt ')'
+  {
+    $$.val = &CTE{
+      Name: AliasClause{Alias: Name($1), Cols: $2.nameList()},
+      Stmt: $5.stmt(),
+    }
+  }
 
 opt_with:
   WITH {}
@@ -3233,6 +3663,10 @@ table_ref:
   {
     $$.val = &AliasedTableExpr{Expr: &Explain{ Options: $4.strs(), Statement: $6.stmt(), Enclosed: true }, Ordinality: $8.bool(), As: $9.aliasClause() }
   }
+| '[' EXPLAIN ANALYZE explainable_stmt ']' opt_ordinality opt_alias_clause
+  {
+    $$.val = &AliasedTableExpr{Expr: &Explain{ Options: []string{$3}, Statement: $4.stmt(), Enclosed: true }, Ordinality: $6.bool(), As: $7.aliasClause() }
+  }
 | '[' show_stmt ']' opt_ordinality opt_alias_clause
   {
     $$.val = &AliasedTableExpr{Expr: &ShowSource{ Statement: $2.stmt() }, Ordinality: $4.bool(), As: $5.aliasClause() }
@@ -3519,6 +3953,14 @@ simple_typename:
   {
     $$.val = uuidColTypeUUID
   }
+| JSON
+  {
+    $$.val = jsonColTypeJSON
+  }
+| JSONB
+  {
+    $$.val = jsonColTypeJSONB
+  }
 | BIGSERIAL
   {
     $$.val = intColTypeBigSerial
@@ -3981,6 +4423,26 @@ a_expr:
   {
     $$.val = &BinaryExpr{Operator: Concat, Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr FETCHVAL a_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchVal, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr FETCHTEXT a_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchText, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr CONTAINS a_expr
+  {
+    $$.val = &ComparisonExpr{Operator: Contains, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr CONTAINED_BY a_expr
+  {
+    $$.val = &ComparisonExpr{Operator: ContainedBy, Left: $1.expr(), Right: $3.expr()}
+  }
+| a_expr '?' a_expr
+  {
+    $$.val = &ComparisonExpr{Operator: JSONExists, Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr LSHIFT a_expr
   {
     $$.val = &BinaryExpr{Operator: LShift, Left: $1.expr(), Right: $3.expr()}
@@ -4242,6 +4704,26 @@ b_expr:
   {
     $$.val = &BinaryExpr{Operator: Concat, Left: $1.expr(), Right: $3.expr()}
   }
+| b_expr FETCHVAL b_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchVal, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr FETCHTEXT b_expr
+  {
+    $$.val = &BinaryExpr{Operator: JSONFetchText, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr CONTAINS b_expr
+  {
+    $$.val = &ComparisonExpr{Operator: Contains, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr CONTAINED_BY b_expr
+  {
+    $$.val = &ComparisonExpr{Operator: ContainedBy, Left: $1.expr(), Right: $3.expr()}
+  }
+| b_expr '?' b_expr
+  {
+    $$.val = &ComparisonExpr{Operator: JSONExists, Left: $1.expr(), Right: $3.expr()}
+  }
 | b_expr LSHIFT b_expr
   {
     $$.val = &BinaryExpr{Operator: LShift, Left: $1.expr(), Right: $3.expr()}
@@ -5215,6 +5697,17 @@ signed_iconst:
     $$.val = &NumVal{Value: constant.UnaryOp(token.SUB, $2.numVal().Value, 0)}
   }
 
+signed_iconst64:
+  signed_iconst
+  {
+    val, err := $1.numVal().AsInt64()
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = val
+  }
+
 interval:
   const_interval SCONST opt_interval
   {
@@ -5309,7 +5802,10 @@ unreserved_keyword:
 | BEGIN
 | BLOB
 | BY
+| CACHE
+| CANCEL
 | CASCADE
+| CHANGEFEED
 | CLUSTER
 | COLUMNS
 | COMMIT
@@ -5331,6 +5827,7 @@ unreserved_keyword:
 | DROP
 | ENCODING
 | EXECUTE
+| EXPERIMENTAL
 | EXPERIMENTAL_FINGERPRINTS
 | EXPLAIN
 | FILTER
@@ -5341,21 +5838,28 @@ unreserved_keyword:
 | HELP
 | HIGH
 | HOUR
+| IMPORT
+| INCREMENT
 | INCREMENTAL
 | INDEXES
 | INSERT
 | INT2VECTOR
 | INTERLEAVE
+| INVERTED
 | ISOLATION
+| JOB
 | KEY
 | KEYS
 | LC_COLLATE
 | LC_CTYPE
 | LEVEL
+| LIST
 | LOCAL
 | LOW
 | MATCH
+| MAXVALUE
 | MINUTE
+| MINVALUE
 | MONTH
 | NAMES
 | NAN
@@ -5374,10 +5878,13 @@ unreserved_keyword:
 | PARTIAL
 | PARTITION
 | PASSWORD
+| PAUSE
+| PHYSICAL
 | PRECEDING
 | PREPARE
 | PRIORITY
 | QUERIES
+| QUERY
 | RANGE
 | READ
 | RECURSIVE
@@ -5393,6 +5900,7 @@ unreserved_keyword:
 | RESET
 | RESTORE
 | RESTRICT
+| RESUME
 | REVOKE
 | ROLLBACK
 | ROLLUP
@@ -5402,8 +5910,10 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCRUB
 | SEARCH
 | SECOND
+| SEQUENCE
 | SERIALIZABLE
 | SESSION
 | SESSIONS
@@ -5414,6 +5924,7 @@ unreserved_keyword:
 | SQL
 | START
 | STDIN
+| STORED
 | STORING
 | STRICT
 | SPLIT
@@ -5482,6 +5993,8 @@ col_name_keyword:
 | INT64
 | INTEGER
 | INTERVAL
+| JSON
+| JSONB
 | LEAST
 | NAME
 | NULLIF
@@ -91,6 +91,8 @@ var (
 	TypeInterval Type = tInterval{}
 	// TypeUUID is the type of a DUuid. Can be compared with ==.
 	TypeUUID Type = tUUID{}
+	// TypeJSON is the type of a DJSON. Can be compared with ==.
+	TypeJSON Type = tJSON{}
 	// TypeTuple is the type family of a DTuple. CANNOT be compared with ==.
 	TypeTuple Type = TTuple(nil)
 	// TypeTable is the type family of a DTable. CANNOT be compared with ==.
@@ -146,6 +148,7 @@ var (
 		TypeTimestampTZ,
 		TypeInterval,
 		TypeUUID,
+		TypeJSON,
 		TypeOid,
 	}
 )
@@ -160,6 +163,24 @@ var (
 	typeInt4Array = TArray{typeInt4}
 )
 
+// The array types below can't be used as column types yet, but they can be
+// sent by clients as placeholder values and returned as results.
+var (
+	typeBoolArray        = TArray{TypeBool}
+	typeBytesArray       = TArray{TypeBytes}
+	typeDateArray        = TArray{TypeDate}
+	typeFloat4Array      = TArray{typeFloat4}
+	typeFloatArray       = TArray{TypeFloat}
+	typeIntervalArray    = TArray{TypeInterval}
+	typeJSONArray        = TArray{TypeJSON}
+	typeDecimalArray     = TArray{TypeDecimal}
+	typeOidArray         = TArray{TypeOid}
+	typeTimestampArray   = TArray{TypeTimestamp}
+	typeTimestampTZArray = TArray{TypeTimestampTZ}
+	typeUUIDArray        = TArray{TypeUUID}
+	typeVarCharArray     = TArray{typeVarChar}
+)
+
 // OidToType maps Postgres object IDs to CockroachDB types.
 var OidToType = map[oid.Oid]Type{
 	oid.T_anyelement:   TypeAny,
@@ -173,6 +194,7 @@ var OidToType = map[oid.Oid]Type{
 	oid.T_int8:         TypeInt,
 	oid.T_int2vector:   TypeIntVector,
 	oid.T_interval:     TypeInterval,
+	oid.T_jsonb:        TypeJSON,
 	oid.T_name:         TypeName,
 	oid.T_numeric:      TypeDecimal,
 	oid.T_oid:          TypeOid,
@@ -185,6 +207,19 @@ var OidToType = map[oid.Oid]Type{
 	oid.T__int2:        typeInt2Array,
 	oid.T__int4:        typeInt4Array,
 	oid.T__int8:        TypeIntArray,
+	oid.T__bool:        typeBoolArray,
+	oid.T__bytea:       typeBytesArray,
+	oid.T__date:        typeDateArray,
+	oid.T__float4:      typeFloat4Array,
+	oid.T__float8:      typeFloatArray,
+	oid.T__interval:    typeIntervalArray,
+	oid.T__jsonb:       typeJSONArray,
+	oid.T__numeric:     typeDecimalArray,
+	oid.T__oid:         typeOidArray,
+	oid.T__timestamp:   typeTimestampArray,
+	oid.T__timestamptz: typeTimestampTZArray,
+	oid.T__uuid:        typeUUIDArray,
+	oid.T__varchar:     typeVarCharArray,
 	oid.T_record:       TypeTuple,
 	oid.T_text:         TypeString,
 	oid.T_timestamp:    TypeTimestamp,
@@ -198,21 +233,34 @@ var OidToType = map[oid.Oid]Type{
 // for these OIDs will override the type name of the corresponding type when
 // looking up the display name for an OID.
 var aliasedOidToName = map[oid.Oid]string{
-	oid.T_float4:     "float4",
-	oid.T_float8:     "float8",
-	oid.T_int2:       "int2",
-	oid.T_int4:       "int4",
-	oid.T_int8:       "int8",
-	oid.T_int2vector: "int2vector",
-	oid.T_text:       "text",
-	oid.T_bytea:      "bytea",
-	oid.T_varchar:    "varchar",
-	oid.T_numeric:    "numeric",
-	oid.T_record:     "record",
-	oid.T__int2:      "_int2",
-	oid.T__int4:      "_int4",
-	oid.T__int8:      "_int8",
-	oid.T__text:      "_text",
+	oid.T_float4:       "float4",
+	oid.T_float8:       "float8",
+	oid.T_int2:         "int2",
+	oid.T_int4:         "int4",
+	oid.T_int8:         "int8",
+	oid.T_int2vector:   "int2vector",
+	oid.T_text:         "text",
+	oid.T_bytea:        "bytea",
+	oid.T_varchar:      "varchar",
+	oid.T_numeric:      "numeric",
+	oid.T_record:       "record",
+	oid.T__int2:        "_int2",
+	oid.T__int4:        "_int4",
+	oid.T__int8:        "_int8",
+	oid.T__text:        "_text",
+	oid.T__bool:        "_bool",
+	oid.T__bytea:       "_bytea",
+	oid.T__date:        "_date",
+	oid.T__float4:      "_float4",
+	oid.T__float8:      "_float8",
+	oid.T__interval:    "_interval",
+	oid.T__jsonb:       "_jsonb",
+	oid.T__numeric:     "_numeric",
+	oid.T__oid:         "_oid",
+	oid.T__timestamp:   "_timestamp",
+	oid.T__timestamptz: "_timestamptz",
+	oid.T__uuid:        "_uuid",
+	oid.T__varchar:     "_varchar",
 }
 
 // PGDisplayName returns the Postgres display name for a given type.
@@ -397,6 +445,16 @@ func (tUUID) Oid() oid.Oid                { return oid.T_uuid }
 func (tUUID) SQLName() string             { return "uuid" }
 func (tUUID) IsAmbiguous() bool           { return false }
 
+type tJSON struct{}
+
+func (tJSON) String() string              { return "jsonb" }
+func (tJSON) Equivalent(other Type) bool  { return UnwrapType(other) == TypeJSON || other == TypeAny }
+func (tJSON) FamilyEqual(other Type) bool { return UnwrapType(other) == TypeJSON }
+func (tJSON) Size() (uintptr, bool)       { return unsafe.Sizeof(DJSON{}), variableSize }
+func (tJSON) Oid() oid.Oid                { return oid.T_jsonb }
+func (tJSON) SQLName() string             { return "jsonb" }
+func (tJSON) IsAmbiguous() bool           { return false }
+
 // TTuple is the type of a DTuple.
 type TTuple []Type
 
@@ -532,11 +590,24 @@ func (TArray) Size() (uintptr, bool) {
 
 // oidToArrayOid maps scalar type Oids to their corresponding array type Oid.
 var oidToArrayOid = map[oid.Oid]oid.Oid{
-	oid.T_int2: oid.T__int2,
-	oid.T_int4: oid.T__int4,
-	oid.T_int8: oid.T__int8,
-	oid.T_text: oid.T__text,
-	oid.T_name: oid.T__name,
+	oid.T_bool:        oid.T__bool,
+	oid.T_bytea:       oid.T__bytea,
+	oid.T_date:        oid.T__date,
+	oid.T_float4:      oid.T__float4,
+	oid.T_float8:      oid.T__float8,
+	oid.T_int2:        oid.T__int2,
+	oid.T_int4:        oid.T__int4,
+	oid.T_int8:        oid.T__int8,
+	oid.T_interval:    oid.T__interval,
+	oid.T_jsonb:       oid.T__jsonb,
+	oid.T_name:        oid.T__name,
+	oid.T_numeric:     oid.T__numeric,
+	oid.T_oid:         oid.T__oid,
+	oid.T_text:        oid.T__text,
+	oid.T_timestamp:   oid.T__timestamp,
+	oid.T_timestamptz: oid.T__timestamptz,
+	oid.T_uuid:        oid.T__uuid,
+	oid.T_varchar:     oid.T__varchar,
 }
 
 // Oid implements the Type interface.
@@ -515,6 +515,9 @@ func (expr *DInterval) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DUuid) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DJSON) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr dNull) Walk(_ Visitor) Expr { return expr }
 
@@ -0,0 +1,231 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// createPartitioning constructs the partitioning descriptor of an index from
+// its PARTITION BY clause. The partitioning columns must be a prefix of the
+// index columns.
+func createPartitioning(
+	evalCtx *parser.EvalContext,
+	searchPath parser.SearchPath,
+	tableDesc *sqlbase.TableDescriptor,
+	indexDesc *sqlbase.IndexDescriptor,
+	partBy *parser.PartitionBy,
+) (sqlbase.PartitioningDescriptor, error) {
+	var partDesc sqlbase.PartitioningDescriptor
+	if len(indexDesc.Interleave.Ancestors) > 0 {
+		return partDesc, errors.New("interleaved tables cannot be partitioned")
+	}
+
+	if len(partBy.Fields) > len(indexDesc.ColumnNames) {
+		return partDesc, fmt.Errorf(
+			"declared partition columns (%s) exceed the columns in index being partitioned (%s)",
+			parser.AsString(partBy.Fields), quoteNames(indexDesc.ColumnNames...))
+	}
+	cols := make([]*sqlbase.ColumnDescriptor, len(partBy.Fields))
+	for i, field := range partBy.Fields {
+		if field.Normalize() != parser.ReNormalizeName(indexDesc.ColumnNames[i]) {
+			return partDesc, fmt.Errorf(
+				"declared partition columns (%s) do not match first %d columns in index being partitioned (%s)",
+				parser.AsString(partBy.Fields), len(partBy.Fields),
+				quoteNames(indexDesc.ColumnNames[:len(partBy.Fields)]...))
+		}
+		col, err := tableDesc.FindColumnByID(indexDesc.ColumnIDs[i])
+		if err != nil {
+			return partDesc, err
+		}
+		cols[i] = col
+	}
+	partDesc.NumColumns = uint32(len(partBy.Fields))
+
+	for _, l := range partBy.List {
+		p := sqlbase.PartitioningDescriptor_List{Name: l.Name.Normalize()}
+		for _, expr := range l.Exprs {
+			encoded, err := valueEncodePartitionTuple(
+				evalCtx, searchPath, parser.PartitionByList, expr, cols)
+			if err != nil {
+				return partDesc, errors.Wrapf(err, "partition %q", l.Name)
+			}
+			p.Values = append(p.Values, encoded)
+		}
+		partDesc.List = append(partDesc.List, p)
+	}
+	for _, r := range partBy.Range {
+		encoded, err := valueEncodePartitionTuple(
+			evalCtx, searchPath, parser.PartitionByRange, r.Expr, cols)
+		if err != nil {
+			return partDesc, errors.Wrapf(err, "partition %q", r.Name)
+		}
+		partDesc.Range = append(partDesc.Range, sqlbase.PartitioningDescriptor_Range{
+			Name:       r.Name.Normalize(),
+			UpperBound: encoded,
+		})
+	}
+	return partDesc, nil
+}
+
+// isMaxValue returns whether the expression is the MAXVALUE of a range
+// partition, which the grammar parses as a column name.
+func isMaxValue(expr parser.Expr) bool {
+	n, ok := expr.(parser.UnresolvedName)
+	if !ok || len(n) != 1 {
+		return false
+	}
+	name, ok := n[0].(parser.Name)
+	return ok && name.Normalize() == "maxvalue"
+}
+
+// valueEncodePartitionTuple type checks and evaluates one value of a
+// partition and encodes it with sqlbase.EncodePartitionTuple. The value is a
+// tuple with one expression per partitioning column, or a single expression
+// when there is only one column. A single DEFAULT or MAXVALUE applies to all
+// the columns.
+func valueEncodePartitionTuple(
+	evalCtx *parser.EvalContext,
+	searchPath parser.SearchPath,
+	typ parser.PartitionByType,
+	expr parser.Expr,
+	cols []*sqlbase.ColumnDescriptor,
+) ([]byte, error) {
+	exprs := parser.Exprs{expr}
+	if t, ok := parser.StripParens(expr).(*parser.Tuple); ok {
+		exprs = t.Exprs
+	}
+
+	var t sqlbase.PartitionTuple
+	if len(exprs) == 1 && len(cols) > 1 {
+		switch e := exprs[0]; {
+		case e == parser.DefaultVal{} && typ == parser.PartitionByList:
+			t.Special, t.SpecialCount = sqlbase.PartitionDefaultVal, len(cols)
+			return sqlbase.EncodePartitionTuple(&t)
+		case isMaxValue(e) && typ == parser.PartitionByRange:
+			t.Special, t.SpecialCount = sqlbase.PartitionMaxVal, len(cols)
+			return sqlbase.EncodePartitionTuple(&t)
+		}
+	}
+	if len(exprs) != len(cols) {
+		return nil, errors.Errorf("partition has %d columns but %d values were supplied",
+			len(cols), len(exprs))
+	}
+
+	for i, e := range exprs {
+		switch {
+		case e == parser.DefaultVal{}:
+			if typ != parser.PartitionByList {
+				return nil, errors.Errorf("DEFAULT cannot be used with PARTITION BY %s", typ)
+			}
+			t.Special = sqlbase.PartitionDefaultVal
+			t.SpecialCount++
+			continue
+		case isMaxValue(e):
+			if typ != parser.PartitionByRange {
+				return nil, errors.Errorf("MAXVALUE cannot be used with PARTITION BY %s", typ)
+			}
+			t.Special = sqlbase.PartitionMaxVal
+			t.SpecialCount++
+			continue
+		}
+		if t.SpecialCount > 0 {
+			return nil, errors.Errorf("%s cannot be followed by a value: %s",
+				t.Special, parser.AsString(e))
+		}
+		typedExpr, err := sqlbase.SanitizeVarFreeExpr(
+			e, cols[i].Type.ToDatumType(), "PARTITION BY", searchPath)
+		if err != nil {
+			return nil, err
+		}
+		datum, err := typedExpr.Eval(evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		t.Datums = append(t.Datums, datum)
+	}
+	return sqlbase.EncodePartitionTuple(&t)
+}
+
+// showCreatePartitioning returns a PARTITION BY clause for the specified
+// index, if applicable.
+func showCreatePartitioning(
+	tableDesc *sqlbase.TableDescriptor, idxDesc *sqlbase.IndexDescriptor,
+) (string, error) {
+	partDesc := &idxDesc.Partitioning
+	if partDesc.NumColumns == 0 {
+		return "", nil
+	}
+	var a sqlbase.DatumAlloc
+	formatTuple := func(encoded []byte) (string, error) {
+		t, _, err := sqlbase.DecodePartitionTuple(&a, tableDesc, idxDesc, partDesc, encoded)
+		if err != nil {
+			return "", err
+		}
+		if t.SpecialCount == int(partDesc.NumColumns) {
+			// A tuple of only DEFAULT or MAXVALUE is written as a single value.
+			return t.Special.String(), nil
+		}
+		s := t.String()
+		if partDesc.NumColumns == 1 {
+			// Strip the parentheses around single values.
+			s = s[1 : len(s)-1]
+		}
+		return s, nil
+	}
+
+	var buf bytes.Buffer
+	typ := parser.PartitionByRange
+	if len(partDesc.List) > 0 {
+		typ = parser.PartitionByList
+	}
+	fmt.Fprintf(&buf, " PARTITION BY %s (%s) (",
+		typ, quoteNames(idxDesc.ColumnNames[:partDesc.NumColumns]...))
+	for i, l := range partDesc.List {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, "\n\tPARTITION %s VALUES IN (", parser.Name(l.Name))
+		for j, encoded := range l.Values {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			s, err := formatTuple(encoded)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(s)
+		}
+		buf.WriteString(")")
+	}
+	for i, r := range partDesc.Range {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		s, err := formatTuple(r.UpperBound)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "\n\tPARTITION %s VALUES < %s", parser.Name(r.Name), s)
+	}
+	buf.WriteString("\n)")
+	return buf.String(), nil
+}
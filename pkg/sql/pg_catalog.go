@@ -220,9 +220,10 @@ CREATE TABLE pg_catalog.pg_attribute (
 }
 
 var (
-	relKindTable = parser.NewDString("r")
-	relKindIndex = parser.NewDString("i")
-	relKindView  = parser.NewDString("v")
+	relKindTable    = parser.NewDString("r")
+	relKindIndex    = parser.NewDString("i")
+	relKindView     = parser.NewDString("v")
+	relKindSequence = parser.NewDString("S")
 )
 
 // See: https://www.postgresql.org/docs/9.6/static/catalog-pg-class.html.
@@ -265,6 +266,8 @@ CREATE TABLE pg_catalog.pg_class (
 			if table.IsView() {
 				// The only difference between tables and views is the relkind column.
 				relKind = relKindView
+			} else if table.IsSequence() {
+				relKind = relKindSequence
 			}
 			if err := addRow(
 				h.TableOid(db, table),       // oid
@@ -1316,7 +1319,7 @@ CREATE TABLE pg_catalog.pg_tables (
 `,
 	populate: func(ctx context.Context, p *planner, addRow func(...parser.Datum) error) error {
 		return forEachTableDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
-			if table.IsView() {
+			if table.IsView() || table.IsSequence() {
 				return nil
 			}
 			return addRow(
@@ -1533,6 +1536,7 @@ var datumToTypeCategory = map[reflect.Type]*parser.DString{
 	reflect.TypeOf(parser.TypeTable):       typCategoryPseudo,
 	reflect.TypeOf(parser.TypeOid):         typCategoryNumeric,
 	reflect.TypeOf(parser.TypeUUID):        typCategoryUserDefined,
+	reflect.TypeOf(parser.TypeJSON):        typCategoryUserDefined,
 }
 
 func typCategory(typ parser.Type) parser.Datum {
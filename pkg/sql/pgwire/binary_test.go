@@ -32,6 +32,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
@@ -153,6 +154,115 @@ func TestBinaryIntArray(t *testing.T) {
 	}
 }
 
+func TestBinaryInterval(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	d := &parser.DInterval{Duration: duration.Duration{
+		Months: 14,
+		Days:   3,
+		Nanos:  (4*time.Hour + 5*time.Minute + 6789*time.Millisecond).Nanoseconds(),
+	}}
+	buf.writeBinaryDatum(d, time.UTC)
+	if buf.err != nil {
+		t.Fatal(buf.err)
+	}
+
+	b := buf.wrapped.Bytes()
+	// 14706789000 microseconds, 3 days and 14 months.
+	expected := []byte{
+		0, 0, 0, 16,
+		0, 0, 0, 3, 0x6c, 0x97, 0xca, 0x88,
+		0, 0, 0, 3,
+		0, 0, 0, 14,
+	}
+	if !bytes.Equal(b, expected) {
+		t.Fatalf("expected %v, got %v", expected, b)
+	}
+	got, err := decodeOidDatum(oid.T_interval, formatBinary, b[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	evalCtx := parser.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	if got.Compare(evalCtx, d) != 0 {
+		t.Fatalf("expected %s, got %s", d, got)
+	}
+}
+
+func TestBinaryArrays(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	evalCtx := parser.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+
+	dec, err := parser.ParseDDecimal("-1.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tstz, err := parser.ParseDTimestampTZ("2017-08-01 12:34:56.789+00", time.UTC, time.Microsecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		typ   parser.Type
+		elems []parser.Datum
+	}{
+		{parser.TypeInt, nil},
+		{parser.TypeBool, []parser.Datum{parser.DBoolTrue, parser.DNull, parser.DBoolFalse}},
+		{parser.TypeFloat, []parser.Datum{parser.NewDFloat(1.5), parser.NewDFloat(-2)}},
+		{parser.TypeDecimal, []parser.Datum{dec, parser.DNull}},
+		{parser.TypeString, []parser.Datum{parser.NewDString("a"), parser.NewDString("")}},
+		{parser.TypeBytes, []parser.Datum{parser.NewDBytes("\x00")}},
+		{parser.TypeDate, []parser.Datum{parser.NewDDate(17000)}},
+		{parser.TypeTimestampTZ, []parser.Datum{tstz}},
+		{parser.TypeInterval, []parser.Datum{&parser.DInterval{}}},
+	} {
+		d := parser.NewDArray(tc.typ)
+		for _, elem := range tc.elems {
+			if err := d.Append(elem); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Run(d.String(), func(t *testing.T) {
+			buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+			buf.writeBinaryDatum(d, time.UTC)
+			if buf.err != nil {
+				t.Fatal(buf.err)
+			}
+			b := buf.wrapped.Bytes()
+			got, err := decodeOidDatum(d.ResolvedType().Oid(), formatBinary, b[4:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Compare(evalCtx, d) != 0 {
+				t.Fatalf("expected %s, got %s", d, got)
+			}
+		})
+	}
+}
+
+func TestBinaryTuple(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	d := parser.NewDTuple(parser.NewDInt(1), parser.DNull)
+	buf.writeBinaryDatum(d, time.UTC)
+	if buf.err != nil {
+		t.Fatal(buf.err)
+	}
+
+	expected := []byte{
+		0, 0, 0, 28,
+		// Number of columns.
+		0, 0, 0, 2,
+		// int8 column.
+		0, 0, 0, 20, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 1,
+		// NULL column, of unknown type.
+		0, 0, 0x02, 0xc1, 0xff, 0xff, 0xff, 0xff,
+	}
+	if b := buf.wrapped.Bytes(); !bytes.Equal(b, expected) {
+		t.Fatalf("expected %v, got %v", expected, b)
+	}
+}
+
 var generateBinaryCmd = flag.String("generate-binary", "", "generate-binary command invocation")
 
 func TestRandomBinaryDecimal(t *testing.T) {
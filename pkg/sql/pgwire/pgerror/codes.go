@@ -99,6 +99,7 @@ const (
 	CodeNullValueNotAllowedError                   = "22004"
 	CodeNullValueNoIndicatorParameterError         = "22002"
 	CodeNumericValueOutOfRangeError                = "22003"
+	CodeSequenceGeneratorLimitExceeded             = "2200H"
 	CodeStringDataLengthMismatchError              = "22026"
 	CodeStringDataRightTruncationError             = "22001"
 	CodeSubstringError                             = "22011"
@@ -137,6 +138,7 @@ const (
 	CodeSchemaAndDataStatementMixingNotSupportedError        = "25007"
 	CodeNoActiveSQLTransactionError                          = "25P01"
 	CodeInFailedSQLTransactionError                          = "25P02"
+	CodeIdleInTransactionSessionTimeoutError                 = "25P03"
 	// Class 26 - Invalid SQL Statement Name
 	CodeInvalidSQLStatementNameError = "26000"
 	// Class 27 - Triggered Data Change Violation
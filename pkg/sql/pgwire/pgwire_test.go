@@ -1605,6 +1605,78 @@ func TestPGWireAuth(t *testing.T) {
 	})
 }
 
+func TestPGWireConnectionLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(fmt.Sprintf("CREATE USER %s", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+	testUserPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(server.TestUser))
+	defer cleanupFn()
+	rootPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupFn()
+
+	// Keep a connection of the test user open.
+	conn, err := gosql.Open("postgres", testUserPgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec("SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRejected := func(setting string, limit int, expected string) {
+		if _, err := db.Exec(fmt.Sprintf("SET CLUSTER SETTING %s = %d", setting, limit)); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if _, err := db.Exec(fmt.Sprintf("SET CLUSTER SETTING %s = 0", setting)); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		rejected := s.MustGetSQLNetworkCounter(pgwire.MetaConnsRejected.Name)
+		// The setting is propagated asynchronously.
+		testutils.SucceedsSoon(t, func() error {
+			err := trivialQuery(testUserPgURL)
+			if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "53300" {
+				return errors.Errorf("expected the connection to be rejected, got %v", err)
+			}
+			if !testutils.IsError(err, expected) {
+				t.Fatalf("expected error %q, got %v", expected, err)
+			}
+			return nil
+		})
+		if r := s.MustGetSQLNetworkCounter(pgwire.MetaConnsRejected.Name); r <= rejected {
+			t.Fatalf("expected the rejected connections metric to increase from %d, got %d", rejected, r)
+		}
+
+		// The limits don't apply to root.
+		if err := trivialQuery(rootPgURL); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("PerUser", func(t *testing.T) {
+		expectRejected("server.max_connections_per_user", 1, "too many connections for user testuser")
+	})
+	t.Run("PerNode", func(t *testing.T) {
+		// The connections of root count toward the limit.
+		expectRejected("server.max_connections", 1, "too many clients already")
+	})
+
+	// The connections are accepted again once the limits are lifted.
+	testutils.SucceedsSoon(t, func() error {
+		return trivialQuery(testUserPgURL)
+	})
+}
+
 func TestPGWireResultChange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
@@ -25,6 +25,8 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -57,6 +59,59 @@ var (
 	MetaBytesOut = metric.Metadata{
 		Name: "sql.bytesout",
 		Help: "Number of sql bytes sent"}
+	MetaConnsRejected = metric.Metadata{
+		Name: "sql.conns.rejected",
+		Help: "Number of sql connections rejected because of server.max_connections or server.max_connections_per_user"}
+)
+
+// The connection limits protect a node from misconfigured client pools. They
+// don't apply to the connections of root, so that an administrator can always
+// connect to a node.
+
+var maxConnections = settings.RegisterValidatedIntSetting(
+	"server.max_connections",
+	"maximum number of SQL connections open on a node, excluding the connections of root (0 for no limit)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set server.max_connections to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+var maxConnectionsPerUser = settings.RegisterValidatedIntSetting(
+	"server.max_connections_per_user",
+	"maximum number of SQL connections of a user open on a node, excluding root (0 for no limit)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set server.max_connections_per_user to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+var identityMap = settings.RegisterValidatedStringSetting(
+	"server.auth.identity_map",
+	"mapping of client certificate common names to the SQL users they can authenticate as, "+
+		"one '<common name regexp> <user>' rule per line or separated by ';'",
+	"",
+	func(v string) error {
+		_, err := security.ParseIdentityMap(v)
+		return err
+	},
+)
+
+var authRules = settings.RegisterValidatedStringSetting(
+	"server.auth.rules",
+	"authentication method required for SQL users, one '<user|all> <cert|password|any>' rule "+
+		"per line or separated by ';'; the first matching rule applies",
+	"",
+	func(v string) error {
+		_, err := security.ParseAuthRules(v)
+		return err
+	},
 )
 
 const (
@@ -64,10 +119,16 @@ const (
 	versionSSL = 80877103
 )
 
+// queryWait is the amount of time a draining server gives to sessions with
+// ongoing transactions to finish work before cancellation.
+var queryWait = settings.RegisterNonNegativeDurationSetting(
+	"server.shutdown.query_wait",
+	"the amount of time a draining node waits for the sessions with ongoing transactions "+
+		"to finish before canceling them",
+	10*time.Second,
+)
+
 const (
-	// drainMaxWait is the amount of time a draining server gives to sessions
-	// with ongoing transactions to finish work before cancellation.
-	drainMaxWait = 10 * time.Second
 	// cancelMaxWait is the amount of time a draining server gives to sessions
 	// to react to cancellation and return before a forceful shutdown.
 	cancelMaxWait = 1 * time.Second
@@ -106,6 +167,11 @@ type Server struct {
 		// that is closed when the connection is done.
 		connCancelMap cancelChanMap
 		draining      bool
+		// conns is the number of authenticated connections, and userConns the
+		// number of authenticated connections of each user. They are used to
+		// enforce the connection limits.
+		conns     int64
+		userConns map[string]int64
 	}
 
 	sqlMemoryPool mon.MemoryMonitor
@@ -117,6 +183,7 @@ type ServerMetrics struct {
 	BytesInCount   *metric.Counter
 	BytesOutCount  *metric.Counter
 	Conns          *metric.Counter
+	ConnsRejected  *metric.Counter
 	ConnMemMetrics sql.MemoryMetrics
 	SQLMemMetrics  sql.MemoryMetrics
 
@@ -128,6 +195,7 @@ func makeServerMetrics(
 ) ServerMetrics {
 	return ServerMetrics{
 		Conns:              metric.NewCounter(MetaConns),
+		ConnsRejected:      metric.NewCounter(MetaConnsRejected),
 		BytesInCount:       metric.NewCounter(MetaBytesIn),
 		BytesOutCount:      metric.NewCounter(MetaBytesOut),
 		ConnMemMetrics:     sql.MakeMemMetrics("conns", histogramWindow),
@@ -175,6 +243,7 @@ func MakeServer(
 
 	server.mu.Lock()
 	server.mu.connCancelMap = make(cancelChanMap)
+	server.mu.userConns = make(map[string]int64)
 	server.mu.Unlock()
 
 	return server
@@ -220,7 +289,7 @@ func (s *Server) Metrics() *ServerMetrics {
 // what will happen to connections in different states:
 // https://github.com/cockroachdb/cockroach/blob/master/docs/RFCS/drain_modes.md
 func (s *Server) SetDraining(drain bool) error {
-	return s.setDrainingImpl(drain, drainMaxWait, cancelMaxWait)
+	return s.setDrainingImpl(drain, queryWait.Get(), cancelMaxWait)
 }
 
 func (s *Server) setDrainingImpl(
@@ -307,6 +376,42 @@ func (s *Server) setDrainingImpl(
 	return nil
 }
 
+// acquireConnSlot registers an authenticated connection of the given user. It
+// returns an error if the connection would exceed server.max_connections or
+// server.max_connections_per_user, in which case the connection must be
+// rejected. Otherwise releaseConnSlot must be called once the connection is
+// closed.
+func (s *Server) acquireConnSlot(user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user != security.RootUser && user != security.NodeUser {
+		if limit := maxConnections.Get(); limit > 0 && s.mu.conns >= limit {
+			s.metrics.ConnsRejected.Inc(1)
+			return pgerror.NewError(pgerror.CodeTooManyConnectionsError,
+				"sorry, too many clients already (see server.max_connections)")
+		}
+		if limit := maxConnectionsPerUser.Get(); limit > 0 && s.mu.userConns[user] >= limit {
+			s.metrics.ConnsRejected.Inc(1)
+			return pgerror.NewErrorf(pgerror.CodeTooManyConnectionsError,
+				"too many connections for user %s (see server.max_connections_per_user)", user)
+		}
+	}
+	s.mu.conns++
+	s.mu.userConns[user]++
+	return nil
+}
+
+// releaseConnSlot unregisters a connection registered with acquireConnSlot.
+func (s *Server) releaseConnSlot(user string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.conns--
+	s.mu.userConns[user]--
+	if s.mu.userConns[user] == 0 {
+		delete(s.mu.userConns, user)
+	}
+}
+
 // ServeConn serves a single connection, driving the handshake process
 // and delegating to the appropriate connection type.
 func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
@@ -403,6 +508,14 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 			return v3conn.sendInternalError(err.Error())
 		}
 
+		// The connection limits are enforced once the user is authenticated,
+		// so that clients can't bypass them by claiming to be root.
+		user := v3conn.sessionArgs.User
+		if err := s.acquireConnSlot(user); err != nil {
+			return v3conn.sendError(err)
+		}
+		defer s.releaseConnSlot(user)
+
 		// Reserve some memory for this connection using the server's
 		// monitor. This reduces pressure on the shared pool because the
 		// server monitor allocates in chunks from the shared pool and
@@ -420,8 +533,11 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 
 		err := v3conn.serve(ctx, s.IsDraining, acc)
 		// If the error that closed the connection is related to an
-		// administrative shutdown, relay that information to the client.
-		if pgErr, ok := pgerror.GetPGCause(err); ok && pgErr.Code == pgerror.CodeAdminShutdownError {
+		// administrative shutdown or to an idle transaction, relay that
+		// information to the client.
+		if pgErr, ok := pgerror.GetPGCause(err); ok &&
+			(pgErr.Code == pgerror.CodeAdminShutdownError ||
+				pgErr.Code == pgerror.CodeIdleInTransactionSessionTimeoutError) {
 			return v3conn.sendError(err)
 		}
 		return err
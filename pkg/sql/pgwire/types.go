@@ -18,6 +18,7 @@ package pgwire
 
 import (
 	"bytes"
+	gosql "database/sql"
 	"encoding/binary"
 	"encoding/hex"
 	"math"
@@ -136,6 +137,9 @@ func (b *writeBuffer) writeTextDatum(d parser.Datum, sessionLoc *time.Location)
 	case *parser.DUuid:
 		b.writeLengthPrefixedString(v.UUID.String())
 
+	case *parser.DJSON:
+		b.writeLengthPrefixedString(v.JSON.String())
+
 	case *parser.DString:
 		b.writeLengthPrefixedString(string(*v))
 
@@ -309,6 +313,14 @@ func (b *writeBuffer) writeBinaryDatum(d parser.Datum, sessionLoc *time.Location
 		b.putInt32(16)
 		b.write(v.GetBytes())
 
+	case *parser.DJSON:
+		// The binary format of JSONB is a version number followed by the text
+		// format.
+		s := v.JSON.String()
+		b.putInt32(int32(1 + len(s)))
+		b.writeByte(1)
+		b.writeString(s)
+
 	case *parser.DString:
 		b.writeLengthPrefixedString(string(*v))
 
@@ -327,24 +339,52 @@ func (b *writeBuffer) writeBinaryDatum(d parser.Datum, sessionLoc *time.Location
 		b.putInt32(4)
 		b.putInt32(dateToPgBinary(v))
 
+	case *parser.DInterval:
+		// The binary format of an interval is its microseconds, days and
+		// months.
+		b.putInt32(16)
+		b.putInt64(v.Nanos / int64(time.Microsecond))
+		b.putInt32(int32(v.Days))
+		b.putInt32(int32(v.Months))
+
+	case *parser.DTuple:
+		// The binary format of a record is its number of columns followed by
+		// the type and the value of each column.
+		subWriter := &writeBuffer{wrapped: b.variablePutbuf}
+		subWriter.putInt32(int32(len(v.D)))
+		for _, elem := range v.D {
+			subWriter.putInt32(int32(elem.ResolvedType().Oid()))
+			subWriter.writeBinaryDatum(elem, sessionLoc)
+		}
+		b.variablePutbuf = subWriter.wrapped
+		b.writeLengthPrefixedVariablePutbuf()
+
 	case *parser.DArray:
 		if v.ParamTyp.FamilyEqual(parser.TypeAnyArray) {
 			b.setError(errors.New("unsupported binary serialization of multidimensional arrays"))
 			return
 		}
 		subWriter := &writeBuffer{wrapped: b.variablePutbuf}
-		// Put the number of dimensions. We currently support 1d arrays only.
-		subWriter.putInt32(1)
+		// Put the number of dimensions. We currently support 1d arrays only;
+		// like Postgres, empty arrays have no dimensions.
+		if v.Len() == 0 {
+			subWriter.putInt32(0)
+		} else {
+			subWriter.putInt32(1)
+		}
 		hasNulls := 0
 		if v.HasNulls {
 			hasNulls = 1
 		}
 		subWriter.putInt32(int32(hasNulls))
 		subWriter.putInt32(int32(v.ParamTyp.Oid()))
-		subWriter.putInt32(int32(v.Len()))
-		subWriter.putInt32(int32(v.Len()))
-		for _, elem := range v.Array {
-			subWriter.writeBinaryDatum(elem, sessionLoc)
+		if v.Len() > 0 {
+			subWriter.putInt32(int32(v.Len()))
+			// Lower bound, we only support a lower bound of 1.
+			subWriter.putInt32(1)
+			for _, elem := range v.Array {
+				subWriter.writeBinaryDatum(elem, sessionLoc)
+			}
 		}
 		b.variablePutbuf = subWriter.wrapped
 		b.writeLengthPrefixedVariablePutbuf()
@@ -515,6 +555,8 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 				return nil, errors.Errorf("could not parse string %q as uuid", b)
 			}
 			return d, nil
+		case oid.T_jsonb:
+			return parser.ParseDJSON(string(b))
 		case oid.T__int2, oid.T__int4, oid.T__int8:
 			var arr pq.Int64Array
 			if err := (&arr).Scan(b); err != nil {
@@ -546,6 +588,10 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 				}
 			}
 			return out, nil
+		case oid.T__bool, oid.T__bytea, oid.T__date, oid.T__float4, oid.T__float8,
+			oid.T__interval, oid.T__jsonb, oid.T__numeric, oid.T__oid, oid.T__timestamp,
+			oid.T__timestamptz, oid.T__uuid, oid.T__varchar:
+			return decodeTextArray(id, b)
 		}
 	case formatBinary:
 		switch id {
@@ -689,13 +735,33 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (parser.Datum, error)
 			}
 			i := int32(binary.BigEndian.Uint32(b))
 			return pgBinaryToDate(i), nil
+		case oid.T_interval:
+			if len(b) < 16 {
+				return nil, errors.Errorf("interval requires 16 bytes for binary format")
+			}
+			micros := int64(binary.BigEndian.Uint64(b))
+			days := int32(binary.BigEndian.Uint32(b[8:]))
+			months := int32(binary.BigEndian.Uint32(b[12:]))
+			return &parser.DInterval{Duration: duration.Duration{
+				Months: int64(months),
+				Days:   int64(days),
+				Nanos:  micros * int64(time.Microsecond),
+			}}, nil
 		case oid.T_uuid:
 			u, err := parser.ParseDUuidFromBytes(b)
 			if err != nil {
 				return nil, err
 			}
 			return u, nil
-		case oid.T__int2, oid.T__int4, oid.T__int8, oid.T__text, oid.T__name:
+		case oid.T_jsonb:
+			if len(b) < 1 || b[0] != 1 {
+				return nil, errors.Errorf("unsupported jsonb binary format version")
+			}
+			return parser.ParseDJSON(string(b[1:]))
+		case oid.T__int2, oid.T__int4, oid.T__int8, oid.T__text, oid.T__name,
+			oid.T__bool, oid.T__bytea, oid.T__date, oid.T__float4, oid.T__float8,
+			oid.T__interval, oid.T__jsonb, oid.T__numeric, oid.T__oid, oid.T__timestamp,
+			oid.T__timestamptz, oid.T__uuid, oid.T__varchar:
 			return decodeBinaryArray(b, code)
 		}
 	default:
@@ -719,9 +785,11 @@ func decodeBinaryArray(b []byte, code formatCode) (parser.Datum, error) {
 		// Nullflag
 		_       int32
 		ElemOid int32
-		// The next two fields should be arrays of size Ndims. However, since
-		// we only support 1-dimensional arrays for now, for convenience we can
-		// leave them in this struct as such for `binary.Read` to parse for us.
+	}{}
+	// The next two fields should be arrays of size Ndims. However, since we
+	// only support 1-dimensional arrays for now, for convenience we can read
+	// them in this struct as such for `binary.Read` to parse for us.
+	dim := struct {
 		DimSize int32
 		// Dim lower bound
 		_ int32
@@ -730,18 +798,39 @@ func decodeBinaryArray(b []byte, code formatCode) (parser.Datum, error) {
 	if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
 		return nil, err
 	}
+	elemOid := oid.Oid(hdr.ElemOid)
+	elemTyp, ok := parser.OidToType[elemOid]
+	if !ok {
+		return nil, errors.Errorf("unsupported array element type: %v", elemOid)
+	}
+	arr := parser.NewDArray(elemTyp)
+	// Empty arrays have no dimensions.
+	if hdr.Ndims == 0 {
+		return arr, nil
+	}
 	// Only 1-dimensional arrays are supported for now.
 	if hdr.Ndims != 1 {
 		return nil, errors.Errorf("unsupported number of array dimensions: %d", hdr.Ndims)
 	}
+	if err := binary.Read(r, binary.BigEndian, &dim); err != nil {
+		return nil, err
+	}
 
-	elemOid := oid.Oid(hdr.ElemOid)
-	arr := parser.NewDArray(parser.OidToType[elemOid])
 	var vlen int32
-	for i := int32(0); i < hdr.DimSize; i++ {
+	for i := int32(0); i < dim.DimSize; i++ {
 		if err := binary.Read(r, binary.BigEndian, &vlen); err != nil {
 			return nil, err
 		}
+		if vlen < 0 {
+			// A NULL element.
+			if err := arr.Append(parser.DNull); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if int(vlen) > r.Len() {
+			return nil, errors.Errorf("array element length %d exceeds the remaining %d bytes", vlen, r.Len())
+		}
 		buf := r.Next(int(vlen))
 		elem, err := decodeOidDatum(elemOid, code, buf)
 		if err != nil {
@@ -753,3 +842,29 @@ func decodeBinaryArray(b []byte, code formatCode) (parser.Datum, error) {
 	}
 	return arr, nil
 }
+
+// decodeTextArray decodes the text format of a 1-dimensional array, decoding
+// each element with the text format of the element type.
+func decodeTextArray(id oid.Oid, b []byte) (parser.Datum, error) {
+	elemTyp := parser.UnwrapType(parser.OidToType[id]).(parser.TArray).Typ
+	var elems pq.GenericArray
+	var strs []gosql.NullString
+	elems.A = &strs
+	if err := elems.Scan(b); err != nil {
+		return nil, err
+	}
+	arr := parser.NewDArray(elemTyp)
+	for _, s := range strs {
+		var elem parser.Datum = parser.DNull
+		if s.Valid {
+			var err error
+			if elem, err = decodeOidDatum(elemTyp.Oid(), formatText, []byte(s.String)); err != nil {
+				return nil, err
+			}
+		}
+		if err := arr.Append(elem); err != nil {
+			return nil, err
+		}
+	}
+	return arr, nil
+}
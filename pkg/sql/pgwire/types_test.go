@@ -110,6 +110,31 @@ func TestIntArrayRoundTrip(t *testing.T) {
 	}
 }
 
+func TestDecodeTextArrays(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		id       oid.Oid
+		in       string
+		expected string
+	}{
+		{oid.T__bool, `{t,NULL,false}`, `ARRAY[true,NULL,false]`},
+		{oid.T__float8, `{1.5,-2}`, `ARRAY[1.5,-2.0]`},
+		{oid.T__numeric, `{1.25,NULL}`, `ARRAY[1.25,NULL]`},
+		{oid.T__varchar, `{a,"b c",""}`, `ARRAY['a','b c','']`},
+		{oid.T__date, `{2017-08-01}`, `ARRAY['2017-08-01']`},
+		{oid.T__float8, `{}`, `ARRAY[]`},
+	} {
+		d, err := decodeOidDatum(tc.id, formatText, []byte(tc.in))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+		if s := d.String(); s != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.in, tc.expected, s)
+		}
+	}
+}
+
 func benchmarkWriteType(b *testing.B, d parser.Datum, format formatCode) {
 	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{Name: ""})}
 
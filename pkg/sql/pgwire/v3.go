@@ -186,6 +186,11 @@ type v3Conn struct {
 	// it gets extra data after an error happened during a COPY operation.
 	doNotSendReadyForQuery bool
 
+	// idleSince is the time at which the last ready for query message was
+	// sent, while the backend waits for the next message. It is zero
+	// otherwise.
+	idleSince time.Time
+
 	metrics *ServerMetrics
 
 	sqlMemoryPool *mon.MemoryMonitor
@@ -273,50 +278,75 @@ var statusReportParams = map[string]string{
 // name, if different from the one given initially. Note: at this
 // point the sql.Session does not exist yet! If need exists to access the
 // database to look up authentication data, use the internal executor.
+// The login attempt is recorded in the audit log.
 func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error {
-	if tlsConn, ok := c.conn.(*tls.Conn); ok {
-		var authenticationHook security.UserAuthHook
+	err := c.authenticate(ctx, insecure)
+	sql.AuditLogin(ctx, c.sessionArgs.User, c.conn.RemoteAddr().String(), err)
+	if err != nil {
+		return c.sendError(err)
+	}
 
-		// Check that the requested user exists and retrieve the hashed
-		// password in case password authentication is needed.
-		hashedPassword, err := sql.GetUserHashedPassword(
-			ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
-		)
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authOK)
+	return c.writeBuf.finishMsg(c.wr)
+}
+
+// authenticate checks the credentials of the client, and returns the
+// reason why they are rejected, if they are.
+func (c *v3Conn) authenticate(ctx context.Context, insecure bool) error {
+	tlsConn, ok := c.conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	var authenticationHook security.UserAuthHook
+
+	// Check that the requested user exists and retrieve the hashed
+	// password in case password authentication is needed.
+	hashedPassword, err := sql.GetUserHashedPassword(
+		ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
+	)
+	if err != nil {
+		return err
+	}
+
+	rules, err := security.ParseAuthRules(authRules.Get())
+	if err != nil {
+		return err
+	}
+	method := rules.Method(c.sessionArgs.User)
+
+	tlsState := tlsConn.ConnectionState()
+	// If no certificates are provided, or if the authentication rules
+	// require it, use password authentication.
+	if len(tlsState.PeerCertificates) == 0 || method == security.AuthPassword {
+		if method == security.AuthCert {
+			return errors.Errorf(
+				"user %s must use certificate authentication instead of password authentication",
+				c.sessionArgs.User)
+		}
+		password, err := c.sendAuthPasswordRequest()
 		if err != nil {
-			return c.sendError(err)
+			return err
 		}
-
-		tlsState := tlsConn.ConnectionState()
-		// If no certificates are provided, default to password
-		// authentication.
-		if len(tlsState.PeerCertificates) == 0 {
-			password, err := c.sendAuthPasswordRequest()
-			if err != nil {
-				return c.sendError(err)
-			}
-			authenticationHook = security.UserAuthPasswordHook(
-				insecure, password, hashedPassword,
-			)
-		} else {
-			// Normalize the username contained in the certificate.
-			tlsState.PeerCertificates[0].Subject.CommonName = parser.Name(
-				tlsState.PeerCertificates[0].Subject.CommonName,
-			).Normalize()
-			var err error
-			authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState)
-			if err != nil {
-				return c.sendError(err)
-			}
+		authenticationHook = security.UserAuthPasswordHook(
+			insecure, password, hashedPassword,
+		)
+	} else {
+		idMap, err := security.ParseIdentityMap(identityMap.Get())
+		if err != nil {
+			return err
 		}
-
-		if err := authenticationHook(c.sessionArgs.User, true /* public */); err != nil {
-			return c.sendError(err)
+		// Normalize the username contained in the certificate.
+		tlsState.PeerCertificates[0].Subject.CommonName = parser.Name(
+			tlsState.PeerCertificates[0].Subject.CommonName,
+		).Normalize()
+		authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState, idMap)
+		if err != nil {
+			return err
 		}
 	}
 
-	c.writeBuf.initMsg(serverMsgAuth)
-	c.writeBuf.putInt32(authOK)
-	return c.writeBuf.finishMsg(c.wr)
+	return authenticationHook(c.sessionArgs.User, true /* public */)
 }
 
 func (c *v3Conn) setupSession(ctx context.Context, reserved mon.BoundAccount) error {
@@ -374,7 +404,7 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 		}(); err != nil {
 			return newAdminShutdownErr(err)
 		}
-		return nil
+		return c.checkIdleInTransaction()
 	})
 	c.rd = bufio.NewReader(c.conn)
 
@@ -412,9 +442,11 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 			if err := c.wr.Flush(); err != nil {
 				return err
 			}
+			c.idleSince = timeutil.Now()
 		}
 		c.doNotSendReadyForQuery = false
 		typ, n, err := c.readBuf.readTypedMsg(c.rd)
+		c.idleSince = time.Time{}
 		c.metrics.BytesInCount.Inc(int64(n))
 		if err != nil {
 			return err
@@ -1156,6 +1188,22 @@ func (c *v3Conn) copyIn(ctx context.Context, columns []sqlbase.ResultColumn) (in
 	}
 }
 
+// checkIdleInTransaction returns an error if the session has waited for the
+// next statement of its transaction for longer than its
+// idle_in_transaction_session_timeout, which closes the connection and
+// thereby rolls back the transaction.
+func (c *v3Conn) checkIdleInTransaction() error {
+	timeout := c.session.IdleInTransactionSessionTimeout
+	if timeout == 0 || c.idleSince.IsZero() || c.session.TxnState.State == sql.NoTxn {
+		return nil
+	}
+	if timeutil.Since(c.idleSince) < timeout {
+		return nil
+	}
+	return pgerror.NewError(pgerror.CodeIdleInTransactionSessionTimeoutError,
+		"terminating connection due to idle-in-transaction timeout")
+}
+
 func newUnrecognizedMsgTypeErr(typ clientMessageType) error {
 	return pgerror.NewErrorf(
 		pgerror.CodeProtocolViolationError, "unrecognized client message type %v", typ)
@@ -171,10 +171,12 @@ type planNodeFastPath interface {
 	FastPathResults() (int, bool)
 }
 
+var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
 var _ planNode = &copyNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
+var _ planNode = &createSequenceNode{}
 var _ planNode = &createTableNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
@@ -182,6 +184,7 @@ var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
+var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
 var _ planNode = &dropViewNode{}
 var _ planNode = &emptyNode{}
@@ -194,6 +197,7 @@ var _ planNode = &filterNode{}
 var _ planNode = &groupNode{}
 var _ planNode = &hookFnNode{}
 var _ planNode = &indexJoinNode{}
+var _ planNode = &instrumentedNode{}
 var _ planNode = &insertNode{}
 var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
@@ -202,6 +206,7 @@ var _ planNode = &relocateNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &scanNode{}
 var _ planNode = &scatterNode{}
+var _ planNode = &scrubNode{}
 var _ planNode = &showRangesNode{}
 var _ planNode = &showFingerprintsNode{}
 var _ planNode = &sortNode{}
@@ -212,6 +217,9 @@ var _ planNode = &valueGenerator{}
 var _ planNode = &valuesNode{}
 var _ planNode = &windowNode{}
 var _ planNode = &createUserNode{}
+var _ planNode = &cancelQueryNode{}
+var _ planNode = &cancelSessionNode{}
+var _ planNode = &controlJobNode{}
 var _ planNode = &dropUserNode{}
 
 var _ planNodeFastPath = &deleteNode{}
@@ -298,10 +306,18 @@ func (p *planner) newPlan(
 	}
 
 	switch n := stmt.(type) {
+	case *parser.AlterSequence:
+		return p.AlterSequence(ctx, n)
 	case *parser.AlterTable:
 		return p.AlterTable(ctx, n)
 	case *parser.BeginTransaction:
 		return p.BeginTransaction(n)
+	case *parser.CancelJob:
+		return p.CancelJob(ctx, n)
+	case *parser.CancelQuery:
+		return p.CancelQuery(ctx, n)
+	case *parser.CancelSession:
+		return p.CancelSession(ctx, n)
 	case CopyDataBlock:
 		return p.CopyData(ctx, n)
 	case *parser.CopyFrom:
@@ -310,6 +326,8 @@ func (p *planner) newPlan(
 		return p.CreateDatabase(n)
 	case *parser.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *parser.CreateSequence:
+		return p.CreateSequence(ctx, n)
 	case *parser.CreateTable:
 		return p.CreateTable(ctx, n)
 	case *parser.CreateUser:
@@ -322,6 +340,8 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *parser.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *parser.DropSequence:
+		return p.DropSequence(ctx, n)
 	case *parser.DropTable:
 		return p.DropTable(ctx, n)
 	case *parser.DropView:
@@ -338,6 +358,8 @@ func (p *planner) newPlan(
 		return p.Insert(ctx, n, desiredTypes)
 	case *parser.ParenSelect:
 		return p.newPlan(ctx, n.Select, desiredTypes)
+	case *parser.PauseJob:
+		return p.PauseJob(ctx, n)
 	case *parser.Relocate:
 		return p.Relocate(ctx, n)
 	case *parser.RenameColumn:
@@ -348,10 +370,14 @@ func (p *planner) newPlan(
 		return p.RenameIndex(ctx, n)
 	case *parser.RenameTable:
 		return p.RenameTable(ctx, n)
+	case *parser.ResumeJob:
+		return p.ResumeJob(ctx, n)
 	case *parser.Revoke:
 		return p.Revoke(ctx, n)
 	case *parser.Scatter:
 		return p.Scatter(ctx, n)
+	case *parser.Scrub:
+		return p.Scrub(ctx, n)
 	case *parser.Select:
 		return p.Select(ctx, n, desiredTypes)
 	case *parser.SelectClause:
@@ -417,6 +443,12 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 	}
 
 	switch n := stmt.(type) {
+	case *parser.CancelJob:
+		return p.CancelJob(ctx, n)
+	case *parser.CancelQuery:
+		return p.CancelQuery(ctx, n)
+	case *parser.CancelSession:
+		return p.CancelSession(ctx, n)
 	case *parser.Delete:
 		return p.Delete(ctx, n, nil)
 	case *parser.Explain:
@@ -425,6 +457,10 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.Help(ctx, n)
 	case *parser.Insert:
 		return p.Insert(ctx, n, nil)
+	case *parser.PauseJob:
+		return p.PauseJob(ctx, n)
+	case *parser.ResumeJob:
+		return p.ResumeJob(ctx, n)
 	case *parser.Select:
 		return p.Select(ctx, n, nil)
 	case *parser.SelectClause:
@@ -59,6 +59,10 @@ type planner struct {
 	// initializing plans to read from a table. This should be used with care.
 	skipSelectPrivilegeChecks bool
 
+	// ctes is the environment of common table expressions in which the
+	// data sources of the statement being planned are resolved.
+	ctes *cteScope
+
 	// autoCommit indicates whether we're planning for a spontaneous transaction.
 	// If autoCommit is true, the plan is allowed (but not required) to
 	// commit the transaction along with other KV operations.
@@ -411,6 +411,22 @@ func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (pla
 			tableDesc.Checks[i].Expr = after
 		}
 	}
+	// Rename the column in the expressions of the computed columns.
+	for i := range tableDesc.Columns {
+		computedCol := &tableDesc.Columns[i]
+		if !computedCol.IsComputed() {
+			continue
+		}
+		expr, err := parser.ParseExpr(*computedCol.ComputedExpr)
+		if err != nil {
+			return nil, err
+		}
+		if expr, err = parser.SimpleVisit(expr, preFn); err != nil {
+			return nil, err
+		}
+		s := expr.String()
+		computedCol.ComputedExpr = &s
+	}
 	// Rename the column in the indexes.
 	tableDesc.RenameColumnDescriptor(col, normNewColName)
 
@@ -169,11 +169,21 @@ func (r *renderNode) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx
 func (p *planner) Select(
 	ctx context.Context, n *parser.Select, desiredTypes []parser.Type,
 ) (planNode, error) {
+	if n.With != nil {
+		popWith, err := p.pushWith(n.With)
+		if err != nil {
+			return nil, err
+		}
+		defer popWith()
+	}
+
 	wrapped := n.Select
 	limit := n.Limit
 	orderBy := n.OrderBy
 
-	for s, ok := wrapped.(*parser.ParenSelect); ok; s, ok = wrapped.(*parser.ParenSelect) {
+	// Parenthesized selects with their own WITH clause are not unwrapped, so
+	// that they are planned in the scope of their common table expressions.
+	for s, ok := wrapped.(*parser.ParenSelect); ok && s.Select.With == nil; s, ok = wrapped.(*parser.ParenSelect) {
 		wrapped = s.Select.Select
 		if s.Select.OrderBy != nil {
 			if orderBy != nil {
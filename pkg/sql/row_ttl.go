@@ -0,0 +1,351 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The storage parameters defining the row-level TTL of a table, as in
+// CREATE TABLE t (...) WITH (ttl_expire_after = '30 days', ttl_column = 'ts').
+const (
+	ttlExpireAfterParam = "ttl_expire_after"
+	ttlColumnParam      = "ttl_column"
+)
+
+var rowTTLJobInterval = settings.RegisterPositiveDurationSetting(
+	"sql.ttl.job_interval",
+	"interval at which the expired rows of the tables with a row-level TTL are deleted",
+	5*time.Minute,
+)
+
+var rowTTLDeleteBatchSize = settings.RegisterValidatedIntSetting(
+	"sql.ttl.delete_batch_size",
+	"number of expired rows deleted by each transaction of the row-level TTL job",
+	500,
+	func(v int64) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set sql.ttl.delete_batch_size to a non-positive value: %d", v)
+		}
+		return nil
+	},
+)
+
+var rowTTLDeleteRateLimit = settings.RegisterValidatedIntSetting(
+	"sql.ttl.delete_rate_limit",
+	"maximum number of expired rows deleted per second from each table by the row-level TTL job (0 for no limit)",
+	1000,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.ttl.delete_rate_limit to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// applyStorageParams sets the storage parameters of a CREATE TABLE or ALTER
+// TABLE ... SET statement on the table descriptor. The only parameters
+// currently supported are those of the row-level TTL, which must be set
+// together.
+func applyStorageParams(desc *sqlbase.TableDescriptor, params parser.StorageParams) error {
+	if len(params) == 0 {
+		return nil
+	}
+	var ttl sqlbase.RowLevelTTL
+	if desc.RowLevelTTL != nil {
+		ttl = *desc.RowLevelTTL
+	}
+	for _, param := range params {
+		key := param.Key.Normalize()
+		if key != ttlExpireAfterParam && key != ttlColumnParam {
+			return fmt.Errorf("unrecognized storage parameter %q", key)
+		}
+		typedExpr, err := parser.TypeCheck(param.Value, &parser.SemaContext{}, parser.TypeString)
+		if err != nil {
+			return err
+		}
+		value, ok := typedExpr.(*parser.DString)
+		if !ok {
+			return fmt.Errorf("storage parameter %q requires a string value", key)
+		}
+		switch key {
+		case ttlExpireAfterParam:
+			ttl.ExpireAfter = string(*value)
+			if _, err := ttl.ExpireAfterDuration(); err != nil {
+				return err
+			}
+		case ttlColumnParam:
+			col, err := desc.FindActiveColumnByName(parser.Name(*value))
+			if err != nil {
+				return err
+			}
+			if k := col.Type.Kind; k != sqlbase.ColumnType_TIMESTAMP && k != sqlbase.ColumnType_TIMESTAMPTZ {
+				return fmt.Errorf("row-level TTL column %q must be of type TIMESTAMP or TIMESTAMPTZ, not %s",
+					col.Name, col.Type.SQLString())
+			}
+			ttl.ColumnID = col.ID
+		}
+	}
+	if ttl.ExpireAfter == "" || ttl.ColumnID == 0 {
+		return fmt.Errorf("storage parameters %q and %q must be set together",
+			ttlExpireAfterParam, ttlColumnParam)
+	}
+	desc.RowLevelTTL = &ttl
+	return nil
+}
+
+// resetStorageParams resets the storage parameters of an ALTER TABLE ...
+// RESET statement. Resetting either parameter of the row-level TTL removes
+// it.
+func resetStorageParams(desc *sqlbase.TableDescriptor, params parser.NameList) error {
+	for _, param := range params {
+		key := param.Normalize()
+		if key != ttlExpireAfterParam && key != ttlColumnParam {
+			return fmt.Errorf("unrecognized storage parameter %q", key)
+		}
+		desc.RowLevelTTL = nil
+	}
+	return nil
+}
+
+// showCreateStorageParams returns the WITH clause of the SHOW CREATE TABLE
+// output for the storage parameters of the table, if any.
+func showCreateStorageParams(desc *sqlbase.TableDescriptor) (string, error) {
+	ttl := desc.RowLevelTTL
+	if ttl == nil {
+		return "", nil
+	}
+	col, err := desc.FindActiveColumnByID(ttl.ColumnID)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, " WITH (%s = ", ttlExpireAfterParam)
+	parser.FormatNode(&buf, parser.FmtSimple, parser.NewDString(ttl.ExpireAfter))
+	fmt.Fprintf(&buf, ", %s = ", ttlColumnParam)
+	parser.FormatNode(&buf, parser.FmtSimple, parser.NewDString(col.Name))
+	buf.WriteByte(')')
+	return buf.String(), nil
+}
+
+// RowTTLManager deletes the expired rows of the tables with a row-level TTL.
+// The tables are found in the system configuration received via gossip, and
+// to avoid contention only the live node with the lowest ID runs the
+// deletions. The rows are deleted in small batches, each in its own
+// transaction, at a rate limited by the sql.ttl.delete_rate_limit setting.
+//
+// The deleted rows leave MVCC tombstones behind, which are only removed by
+// the garbage collection of the ranges once the GC TTL of their zone has
+// passed.
+type RowTTLManager struct {
+	db       client.DB
+	gossip   *gossip.Gossip
+	leaseMgr *LeaseManager
+	liveness *storage.NodeLiveness
+	nodeID   roachpb.NodeID
+	clock    *hlc.Clock
+}
+
+// NewRowTTLManager returns a new RowTTLManager.
+func NewRowTTLManager(
+	db client.DB,
+	gossip *gossip.Gossip,
+	leaseMgr *LeaseManager,
+	liveness *storage.NodeLiveness,
+	nodeID roachpb.NodeID,
+	clock *hlc.Clock,
+) *RowTTLManager {
+	return &RowTTLManager{
+		db:       db,
+		gossip:   gossip,
+		leaseMgr: leaseMgr,
+		liveness: liveness,
+		nodeID:   nodeID,
+		clock:    clock,
+	}
+}
+
+// Start starts a goroutine that periodically deletes the expired rows of
+// the tables with a row-level TTL.
+func (m *RowTTLManager) Start(stopper *stop.Stopper) {
+	stopper.RunWorker(context.TODO(), func(ctx context.Context) {
+		gossipUpdateC := m.gossip.RegisterSystemConfigChannel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		lastRun := timeutil.Now()
+		timer.Reset(rowTTLJobInterval.Get())
+		for {
+			select {
+			case <-gossipUpdateC:
+				// The cluster settings are gossiped with the system
+				// configuration, so this picks up changes to the interval.
+				timer.Reset(rowTTLJobInterval.Get() - timeutil.Since(lastRun))
+
+			case <-timer.C:
+				timer.Read = true
+				lastRun = timeutil.Now()
+				if m.shouldRun() {
+					cfg, _ := m.gossip.GetSystemConfig()
+					m.deleteExpiredRows(ctx, stopper, cfg)
+				}
+				timer.Reset(rowTTLJobInterval.Get())
+
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// shouldRun returns whether this node is the live node with the lowest ID,
+// which is the one deleting the expired rows.
+func (m *RowTTLManager) shouldRun() bool {
+	if m.liveness == nil {
+		return true
+	}
+	for nodeID, live := range m.liveness.GetIsLiveMap() {
+		if live && nodeID < m.nodeID {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteExpiredRows deletes the expired rows of all the tables with a
+// row-level TTL in the system configuration.
+func (m *RowTTLManager) deleteExpiredRows(
+	ctx context.Context, stopper *stop.Stopper, cfg config.SystemConfig,
+) {
+	descKeyPrefix := keys.MakeTablePrefix(uint32(sqlbase.DescriptorTable.ID))
+	dbNames := make(map[sqlbase.ID]string)
+	var tables []*sqlbase.TableDescriptor
+	for _, kv := range cfg.Values {
+		if !bytes.HasPrefix(kv.Key, descKeyPrefix) {
+			continue
+		}
+		var descriptor sqlbase.Descriptor
+		if err := kv.Value.GetProto(&descriptor); err != nil {
+			log.Warningf(ctx, "%s: unable to unmarshal descriptor %v", kv.Key, kv.Value)
+			continue
+		}
+		switch union := descriptor.Union.(type) {
+		case *sqlbase.Descriptor_Table:
+			table := union.Table
+			if table.RowLevelTTL != nil && !table.Dropped() && !table.Adding() {
+				tables = append(tables, table)
+			}
+		case *sqlbase.Descriptor_Database:
+			dbNames[union.Database.ID] = union.Database.Name
+		}
+	}
+
+	for _, table := range tables {
+		dbName, ok := dbNames[table.ParentID]
+		if !ok {
+			continue
+		}
+		n, err := m.deleteExpiredTableRows(ctx, stopper, dbName, table)
+		if err != nil {
+			log.Warningf(ctx, "unable to delete the expired rows of table %q: %s", table.Name, err)
+		}
+		if n > 0 {
+			log.Infof(ctx, "deleted %d expired rows from table %q", n, table.Name)
+		}
+	}
+}
+
+// deleteExpiredTableRows deletes the rows of the table whose value in the TTL
+// column is older than the expiration of the row-level TTL, and returns the
+// number of rows deleted.
+func (m *RowTTLManager) deleteExpiredTableRows(
+	ctx context.Context, stopper *stop.Stopper, dbName string, table *sqlbase.TableDescriptor,
+) (int, error) {
+	if err := table.ValidateTable(); err != nil {
+		return 0, err
+	}
+	expireAfter, err := table.RowLevelTTL.ExpireAfterDuration()
+	if err != nil {
+		return 0, err
+	}
+	col, err := table.FindActiveColumnByID(table.RowLevelTTL.ColumnID)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := duration.Add(m.clock.PhysicalTime(), expireAfter.Mul(-1))
+	var cutoffDatum parser.Datum = parser.MakeDTimestamp(cutoff, time.Microsecond)
+	if col.Type.Kind == sqlbase.ColumnType_TIMESTAMPTZ {
+		cutoffDatum = parser.MakeDTimestampTZ(cutoff, time.Microsecond)
+	}
+
+	tn := parser.TableName{DatabaseName: parser.Name(dbName), TableName: parser.Name(table.Name)}
+	pk := quoteNames(table.PrimaryIndex.ColumnNames...)
+	batchSize := int(rowTTLDeleteBatchSize.Get())
+	stmt := fmt.Sprintf(
+		`DELETE FROM %[1]s WHERE (%[2]s) IN (SELECT %[2]s FROM %[1]s WHERE %[3]s < $1 LIMIT %[4]d)`,
+		&tn, pk, parser.Name(col.Name), batchSize,
+	)
+
+	limit := rate.Inf
+	if rowsPerSecond := rowTTLDeleteRateLimit.Get(); rowsPerSecond > 0 {
+		limit = rate.Limit(rowsPerSecond) / rate.Limit(batchSize)
+	}
+	limiter := rate.NewLimiter(limit, 1 /* burst size */)
+
+	ie := InternalExecutor{LeaseManager: m.leaseMgr}
+	deleted := 0
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			return deleted, err
+		}
+		select {
+		case <-stopper.ShouldQuiesce():
+			return deleted, nil
+		default:
+		}
+		var n int
+		if err := m.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			var err error
+			n, err = ie.ExecuteStatementInTransaction(ctx, "row-ttl-delete", txn, stmt, cutoffDatum)
+			return err
+		}); err != nil {
+			return deleted, err
+		}
+		deleted += n
+		if n < batchSize {
+			return deleted, nil
+		}
+	}
+}
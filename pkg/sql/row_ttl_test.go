@@ -0,0 +1,75 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRowTTLDeletesExpiredRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+	// A small batch size makes the job delete the expired rows over several
+	// transactions.
+	sqlDB.Exec(`SET CLUSTER SETTING sql.ttl.delete_batch_size = 2`)
+	sqlDB.Exec(`SET CLUSTER SETTING sql.ttl.delete_rate_limit = 0`)
+	sqlDB.Exec(`SET CLUSTER SETTING sql.ttl.job_interval = '10ms'`)
+	sqlDB.Exec(`CREATE DATABASE d`)
+	sqlDB.Exec(`CREATE TABLE d.t (
+		k INT PRIMARY KEY,
+		ts TIMESTAMPTZ
+	) WITH (ttl_expire_after = '1 hour', ttl_column = 'ts')`)
+	sqlDB.Exec(`CREATE TABLE d.u (k INT PRIMARY KEY, ts TIMESTAMPTZ)`)
+	for _, table := range []string{"d.t", "d.u"} {
+		sqlDB.Exec(fmt.Sprintf(`INSERT INTO %s VALUES
+			(1, now() - '2 hours'::INTERVAL),
+			(2, now() - '3 hours'::INTERVAL),
+			(3, now() - '4 hours'::INTERVAL),
+			(4, now() - '5 hours'::INTERVAL),
+			(5, now() - '6 hours'::INTERVAL),
+			(6, now()),
+			(7, NULL)`, table))
+	}
+
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		sqlDB.QueryRow(`SELECT COUNT(*) FROM d.t`).Scan(&count)
+		if count != 2 {
+			return fmt.Errorf("expected 2 rows left, found %d", count)
+		}
+		return nil
+	})
+	sqlDB.CheckQueryResults(`SELECT k FROM d.t ORDER BY k`, [][]string{{"6"}, {"7"}})
+
+	// The rows of the tables without a row-level TTL never expire.
+	var count int
+	sqlDB.QueryRow(`SELECT COUNT(*) FROM d.u`).Scan(&count)
+	if count != 7 {
+		t.Fatalf("expected 7 rows in the table without TTL, found %d", count)
+	}
+}
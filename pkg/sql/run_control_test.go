@@ -0,0 +1,129 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCancelQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, mainDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := mainDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v TEXT);
+INSERT INTO t.test VALUES (1, 'a');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Block writes to the row with the intent of an open transaction.
+	blocker, err := mainDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = blocker.Rollback() }()
+	if _, err := blocker.Exec(`UPDATE t.test SET v = 'b' WHERE k = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := mainDB.Exec(`UPDATE t.test SET v = 'c' WHERE k = 1`)
+		errCh <- err
+	}()
+
+	var queryID string
+	testutils.SucceedsSoon(t, func() error {
+		return mainDB.QueryRow(`
+SELECT query_id FROM crdb_internal.cluster_queries WHERE query LIKE 'UPDATE t.test SET v = ''c''%'
+`).Scan(&queryID)
+	})
+	if _, err := mainDB.Exec(`CANCEL QUERY $1`, queryID); err != nil {
+		t.Fatal(err)
+	}
+
+	err = <-errCh
+	if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != pgerror.CodeQueryCanceledError {
+		t.Fatalf("expected the query to be canceled, got %v", err)
+	}
+
+	// The query is gone once canceled.
+	if _, err := mainDB.Exec(`CANCEL QUERY $1`, queryID); !testutils.IsError(err, "not found") {
+		t.Fatalf("expected the query not to be found, got %v", err)
+	}
+}
+
+func TestCancelSession(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, mainDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	pgURL, cleanupDB := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestCancelSession", url.User(security.RootUser))
+	defer cleanupDB()
+	conn, err := pq.Open(pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Find the ID of the session of conn through its own active query.
+	rows, err := conn.(driver.Queryer).Query(`
+SELECT session_id FROM crdb_internal.node_sessions WHERE active_queries LIKE 'SELECT session_id%'
+`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals := make([]driver.Value, 1)
+	if err := rows.Next(vals); err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := fmt.Sprintf("%s", vals[0])
+
+	if _, err := mainDB.Exec(`CANCEL SESSION $1`, sessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The connection of the session is closed.
+	execer := conn.(driver.Execer)
+	testutils.SucceedsSoon(t, func() error {
+		if _, err := execer.Exec(`SELECT 1`, nil); err == nil {
+			return errors.New("expected the session to be closed")
+		}
+		return nil
+	})
+}
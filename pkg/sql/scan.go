@@ -411,6 +411,11 @@ func (n *scanNode) computeOrdering(
 	index *sqlbase.IndexDescriptor, exactPrefix int, reverse bool,
 ) orderingInfo {
 	var ordering orderingInfo
+	if index.Type == sqlbase.IndexDescriptor_INVERTED {
+		// The keys of an inverted index are not ordered by the values of
+		// its column.
+		return ordering
+	}
 
 	columnIDs, dirs := index.FullColumnIDs()
 
@@ -103,9 +103,12 @@ var errExistingSchemaChangeLease = errors.New(
 	"an outstanding schema change lease exists")
 var errSchemaChangeNotFirstInLine = errors.New(
 	"schema change not first in line")
+var errSchemaChangeNotScheduledYet = errors.New(
+	"schema change scheduled to start later")
 
 func shouldLogSchemaChangeError(err error) bool {
-	return err != errExistingSchemaChangeLease && err != errSchemaChangeNotFirstInLine
+	return err != errExistingSchemaChangeLease && err != errSchemaChangeNotFirstInLine &&
+		err != errSchemaChangeNotScheduledYet && !jobs.IsPausedError(err)
 }
 
 // AcquireLease acquires a schema change lease on the table if
@@ -369,11 +372,34 @@ func (sc *SchemaChanger) exec(ctx context.Context, evalCtx parser.EvalContext) e
 		return errDidntUpdateDescriptor
 	}
 
+	// Don't work on the schema change while its job is paused. If it was
+	// canceled, roll it back.
+	if err := sc.jobLogger.CheckStatus(ctx); err != nil {
+		if jobs.IsCanceledError(err) {
+			if err := sc.rollbackSchemaChange(ctx, err, &lease, evalCtx); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	// Don't start the schema change before its scheduled start time.
+	if details, ok := sc.jobLogger.Job.Details.(jobs.SchemaChangeJobDetails); ok &&
+		details.StartAfterMicros != 0 {
+		startAfter := time.Unix(0, details.StartAfterMicros*time.Microsecond.Nanoseconds())
+		if timeutil.Now().Before(startAfter) {
+			log.VEventf(ctx, 2, "schema change job %d scheduled to start at %s",
+				*sc.jobLogger.JobID(), startAfter)
+			return errSchemaChangeNotScheduledYet
+		}
+	}
+
 	if err := sc.jobLogger.Started(ctx); err != nil {
 		if log.V(2) {
 			log.Infof(ctx, "Failed to mark job %d as started: %v", *sc.jobLogger.JobID(), err)
 		}
 	}
+	defer log.StartActivity(fmt.Sprintf("schema change job %d", *sc.jobLogger.JobID()))()
 
 	// Another transaction might set the up_version bit again,
 	// but we're no longer responsible for taking care of that.
@@ -382,9 +408,10 @@ func (sc *SchemaChanger) exec(ctx context.Context, evalCtx parser.EvalContext) e
 	err = sc.runStateMachineAndBackfill(ctx, &lease, evalCtx)
 
 	// Purge the mutations if the application of the mutations failed due to
-	// a permanent error. All other errors are transient errors that are
-	// resolved by retrying the backfill.
-	if sqlbase.IsPermanentSchemaChangeError(err) {
+	// a permanent error or if the job was canceled. All other errors are
+	// transient errors that are resolved by retrying the backfill, which
+	// continues from the last checkpoint. This includes the job being paused.
+	if sqlbase.IsPermanentSchemaChangeError(err) || jobs.IsCanceledError(err) {
 		if err := sc.rollbackSchemaChange(ctx, err, &lease, evalCtx); err != nil {
 			return err
 		}
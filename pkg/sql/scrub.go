@@ -0,0 +1,882 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+var scrubBatchSize = settings.RegisterValidatedIntSetting(
+	"sql.scrub.batch_size",
+	"number of rows read by each transaction of a SCRUB",
+	1000,
+	func(v int64) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set sql.scrub.batch_size to a non-positive value: %d", v)
+		}
+		return nil
+	},
+)
+
+var scrubRateLimit = settings.RegisterValidatedIntSetting(
+	"sql.scrub.rate_limit",
+	"maximum number of rows read per second from each index by a SCRUB (0 for no limit)",
+	10000,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.scrub.rate_limit to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// The types of the errors found by SCRUB.
+const (
+	scrubErrorMissingIndexEntry      = "missing_index_entry"
+	scrubErrorDanglingIndexReference = "dangling_index_reference"
+	scrubErrorIndexValueMismatch     = "index_value_mismatch"
+	scrubErrorCheckViolation         = "check_constraint_violation"
+	scrubErrorForeignKeyViolation    = "foreign_key_violation"
+	scrubErrorInvalidEncoding        = "invalid_encoding"
+)
+
+var scrubColumns = sqlbase.ResultColumns{
+	{Name: "error_type", Typ: parser.TypeString},
+	{Name: "database", Typ: parser.TypeString},
+	{Name: "table", Typ: parser.TypeString},
+	{Name: "primary_key", Typ: parser.TypeString},
+	{Name: "timestamp", Typ: parser.TypeTimestamp},
+	{Name: "details", Typ: parser.TypeString},
+}
+
+// Scrub checks the integrity of the data of a table and returns one row per
+// error found:
+//
+//   - INDEX checks that every row of the primary index has the expected
+//     entries in the secondary indexes, and that every entry of the secondary
+//     indexes belongs to a row of the primary index.
+//   - CONSTRAINT checks that every row satisfies the CHECK and FOREIGN KEY
+//     constraints of the table, including the ones that were never validated.
+//   - PHYSICAL checks that every key and value of the indexes of the table can
+//     be decoded and matches its checksum.
+//
+// Every check is run when no options are given. The indexes are read in
+// batches of sql.scrub.batch_size rows, each in its own transaction at the
+// same timestamp (now, or the one of the AS OF SYSTEM TIME clause), at a rate
+// limited by the sql.scrub.rate_limit setting, so a SCRUB can run against a
+// production cluster without blocking its writes. The checks run on the
+// gateway node.
+func (p *planner) Scrub(ctx context.Context, n *parser.Scrub) (planNode, error) {
+	if err := p.RequireSuperUser("SCRUB"); err != nil {
+		return nil, err
+	}
+
+	ts := p.session.execCfg.Clock.Now()
+	if n.AsOf.Expr != nil {
+		evalCtx := p.session.evalCtx()
+		var err error
+		ts, err = EvalAsOfTimestamp(&evalCtx, n.AsOf, ts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	var tableDesc *sqlbase.TableDescriptor
+	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ts)
+
+		var err error
+		tableDesc, err = mustGetTableDesc(
+			ctx, txn, p.getVirtualTabler(), tn, false /*allowAdding*/)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if tableDesc.IsVirtualTable() {
+		return nil, errors.Errorf("cannot scrub virtual table %s", tn)
+	}
+
+	checks, err := makeScrubChecks(tableDesc, n.Options)
+	if err != nil {
+		return nil, err
+	}
+	return &scrubNode{
+		p:         p,
+		tn:        tn,
+		ts:        ts,
+		tableDesc: tableDesc,
+		checks:    checks,
+		colIdxMap: sqlbase.ColIDtoRowIndexFromCols(tableDesc.Columns),
+	}, nil
+}
+
+// scrubChecks are the checks run by a SCRUB.
+type scrubChecks struct {
+	// indexes are the secondary indexes whose entries are checked.
+	indexes []sqlbase.IndexDescriptor
+	// checks are the positions in the table descriptor of the CHECK
+	// constraints to check.
+	checks []int
+	// fks are the indexes with the FOREIGN KEY constraints to check.
+	fks []sqlbase.IndexDescriptor
+	// physical is set if the keys and values are checked to be decodable.
+	physical bool
+}
+
+func makeScrubChecks(
+	desc *sqlbase.TableDescriptor, options parser.ScrubOptions,
+) (scrubChecks, error) {
+	var c scrubChecks
+	if options == nil {
+		c.indexes = desc.Indexes
+		c.addAllConstraints(desc)
+		c.physical = true
+		return c, nil
+	}
+
+	var seenIndex, seenConstraint bool
+	for _, option := range options {
+		switch o := option.(type) {
+		case *parser.ScrubOptionIndex:
+			if seenIndex {
+				return c, errors.New("cannot specify the INDEX option more than once")
+			}
+			seenIndex = true
+			if o.IndexNames == nil {
+				c.indexes = desc.Indexes
+				continue
+			}
+			for _, name := range o.IndexNames {
+				index, err := findScrubIndex(desc, name)
+				if err != nil {
+					return c, err
+				}
+				c.indexes = append(c.indexes, index)
+			}
+
+		case *parser.ScrubOptionConstraint:
+			if seenConstraint {
+				return c, errors.New("cannot specify the CONSTRAINT option more than once")
+			}
+			seenConstraint = true
+			if o.ConstraintNames == nil {
+				c.addAllConstraints(desc)
+				continue
+			}
+			for _, name := range o.ConstraintNames {
+				if err := c.addConstraint(desc, name); err != nil {
+					return c, err
+				}
+			}
+
+		case *parser.ScrubOptionPhysical:
+			if c.physical {
+				return c, errors.New("cannot specify the PHYSICAL option more than once")
+			}
+			c.physical = true
+
+		default:
+			return c, errors.Errorf("unknown SCRUB option: %T", option)
+		}
+	}
+	return c, nil
+}
+
+// findScrubIndex returns the secondary index with the given name. Indexes
+// being added or dropped cannot be checked.
+func findScrubIndex(
+	desc *sqlbase.TableDescriptor, name parser.Name,
+) (sqlbase.IndexDescriptor, error) {
+	normName := name.Normalize()
+	for _, index := range desc.Indexes {
+		if parser.ReNormalizeName(index.Name) == normName {
+			return index, nil
+		}
+	}
+	if parser.ReNormalizeName(desc.PrimaryIndex.Name) == normName {
+		return sqlbase.IndexDescriptor{}, errors.Errorf(
+			"index %q is the primary index, which is checked by the PHYSICAL option", normName)
+	}
+	return sqlbase.IndexDescriptor{}, errors.Errorf("index %q does not exist", normName)
+}
+
+func (c *scrubChecks) addAllConstraints(desc *sqlbase.TableDescriptor) {
+	for i := range desc.Checks {
+		c.checks = append(c.checks, i)
+	}
+	for _, index := range append([]sqlbase.IndexDescriptor{desc.PrimaryIndex}, desc.Indexes...) {
+		if index.ForeignKey.IsSet() {
+			c.fks = append(c.fks, index)
+		}
+	}
+}
+
+func (c *scrubChecks) addConstraint(desc *sqlbase.TableDescriptor, name parser.Name) error {
+	normName := name.Normalize()
+	for i, check := range desc.Checks {
+		if parser.ReNormalizeName(check.Name) == normName {
+			c.checks = append(c.checks, i)
+			return nil
+		}
+	}
+	for _, index := range append([]sqlbase.IndexDescriptor{desc.PrimaryIndex}, desc.Indexes...) {
+		if index.ForeignKey.IsSet() && parser.ReNormalizeName(index.ForeignKey.Name) == normName {
+			c.fks = append(c.fks, index)
+			return nil
+		}
+	}
+	return errors.Errorf("constraint %q does not exist", normName)
+}
+
+// hasIndex returns whether the entries of the given index are checked.
+func (c *scrubChecks) hasIndex(id sqlbase.IndexID) bool {
+	for _, index := range c.indexes {
+		if index.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubFK is a FOREIGN KEY constraint checked by a SCRUB.
+type scrubFK struct {
+	// index is the index of the scrubbed table with the constraint.
+	index    *sqlbase.IndexDescriptor
+	refTable *sqlbase.TableDescriptor
+	refIndex *sqlbase.IndexDescriptor
+	// prefixLen is the number of columns of refIndex matched by the
+	// columns of index.
+	prefixLen int
+	// colMap maps the IDs of the columns of refIndex to the positions of the
+	// matching columns in the rows of the scrubbed table.
+	colMap    map[sqlbase.ColumnID]int
+	refPrefix []byte
+	// rf decodes the rows of refIndex.
+	rf sqlbase.RowFetcher
+}
+
+type scrubNode struct {
+	p         *planner
+	tn        *parser.TableName
+	ts        hlc.Timestamp
+	tableDesc *sqlbase.TableDescriptor
+	checks    scrubChecks
+
+	// colIdxMap maps the IDs of the columns of the table to their position in
+	// the rows read from its indexes.
+	colIdxMap     map[sqlbase.ColumnID]int
+	primaryPrefix []byte
+	// primaryFetcher decodes the rows of the primary index.
+	primaryFetcher sqlbase.RowFetcher
+	checkHelper    checkHelper
+	fks            []scrubFK
+
+	// batchRows holds the errors found in the batch being checked, which are
+	// moved to rows once its transaction has committed.
+	batchRows []parser.Datums
+	rows      []parser.Datums
+	rowIdx    int
+}
+
+func (n *scrubNode) Start(ctx context.Context) error {
+	n.primaryPrefix = sqlbase.MakeIndexKeyPrefix(n.tableDesc, n.tableDesc.PrimaryIndex.ID)
+	if err := n.initRowFetcher(&n.primaryFetcher, &n.tableDesc.PrimaryIndex); err != nil {
+		return err
+	}
+	if len(n.checks.checks) > 0 {
+		if err := n.checkHelper.init(ctx, n.p, n.tn, n.tableDesc); err != nil {
+			return err
+		}
+	}
+	if err := n.initForeignKeys(ctx); err != nil {
+		return err
+	}
+
+	if err := n.scanIndex(ctx, &n.tableDesc.PrimaryIndex, n.checkPrimaryRows); err != nil {
+		return err
+	}
+	for i := range n.tableDesc.Indexes {
+		index := &n.tableDesc.Indexes[i]
+		if index.Type == sqlbase.IndexDescriptor_INVERTED {
+			// The keys of inverted indexes cannot be decoded into the values
+			// of their column; their entries are only checked to be present.
+			continue
+		}
+		checkEntries := n.checks.hasIndex(index.ID)
+		if !checkEntries && !n.checks.physical {
+			continue
+		}
+		if err := n.scanIndex(ctx, index, func(
+			ctx context.Context, txn *client.Txn, rows []parser.Datums,
+		) error {
+			if !checkEntries {
+				return nil
+			}
+			return n.checkIndexRows(ctx, txn, index, rows)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *scrubNode) Next(ctx context.Context) (bool, error) {
+	if n.rowIdx >= len(n.rows) {
+		return false, nil
+	}
+	n.rowIdx++
+	return true, nil
+}
+
+func (n *scrubNode) Values() parser.Datums   { return n.rows[n.rowIdx-1] }
+func (n *scrubNode) Close(_ context.Context) {}
+
+func (*scrubNode) Columns() sqlbase.ResultColumns { return scrubColumns }
+func (*scrubNode) Ordering() orderingInfo         { return orderingInfo{} }
+func (*scrubNode) MarkDebug(_ explainMode)        {}
+func (*scrubNode) DebugValues() debugValues       { return debugValues{} }
+func (*scrubNode) Spans(context.Context) (_, _ roachpb.Spans, _ error) {
+	panic("unimplemented")
+}
+
+// initRowFetcher initializes a RowFetcher decoding the rows of an index of
+// the table. The rows of a secondary index only hold values for the columns of
+// the index; the other columns are NULL.
+func (n *scrubNode) initRowFetcher(rf *sqlbase.RowFetcher, index *sqlbase.IndexDescriptor) error {
+	desc := n.tableDesc
+	isSecondaryIndex := index.ID != desc.PrimaryIndex.ID
+	valNeededForCol := make([]bool, len(desc.Columns))
+	for i := range desc.Columns {
+		valNeededForCol[i] = !isSecondaryIndex || index.ContainsColumnID(desc.Columns[i].ID)
+	}
+	return rf.Init(desc, n.colIdxMap, index, false, /* reverse */
+		isSecondaryIndex, desc.Columns, valNeededForCol, false /* returnRangeInfo */)
+}
+
+func (n *scrubNode) initForeignKeys(ctx context.Context) error {
+	if len(n.checks.fks) == 0 {
+		return nil
+	}
+	return n.p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(n.ts)
+
+		n.fks = make([]scrubFK, len(n.checks.fks))
+		for i := range n.checks.fks {
+			fk := &n.fks[i]
+			fk.index = &n.checks.fks[i]
+			var err error
+			fk.refTable, err = sqlbase.GetTableDescFromID(ctx, txn, fk.index.ForeignKey.Table)
+			if err != nil {
+				return err
+			}
+			fk.refIndex, err = fk.refTable.FindIndexByID(fk.index.ForeignKey.Index)
+			if err != nil {
+				return err
+			}
+			fk.prefixLen = len(fk.refIndex.ColumnIDs)
+			if len(fk.index.ColumnIDs) < fk.prefixLen {
+				fk.prefixLen = len(fk.index.ColumnIDs)
+			}
+			fk.colMap = make(map[sqlbase.ColumnID]int, fk.prefixLen)
+			for j, colID := range fk.index.ColumnIDs[:fk.prefixLen] {
+				fk.colMap[fk.refIndex.ColumnIDs[j]] = n.colIdxMap[colID]
+			}
+			fk.refPrefix = sqlbase.MakeIndexKeyPrefix(fk.refTable, fk.refIndex.ID)
+
+			ids := sqlbase.ColIDtoRowIndexFromCols(fk.refTable.Columns)
+			needed := make([]bool, len(ids))
+			for _, colID := range fk.refIndex.ColumnIDs {
+				needed[ids[colID]] = true
+			}
+			isSecondaryIndex := fk.refTable.PrimaryIndex.ID != fk.refIndex.ID
+			if err := fk.rf.Init(fk.refTable, ids, fk.refIndex, false, /* reverse */
+				isSecondaryIndex, fk.refTable.Columns, needed, false /* returnRangeInfo */); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// scanIndex reads the rows of an index of the table and passes them to fn in
+// batches of sql.scrub.batch_size rows, each read by its own transaction at
+// the timestamp of the scrub and then checked by fn in that transaction. The
+// batches are read at a rate limited by the sql.scrub.rate_limit setting.
+func (n *scrubNode) scanIndex(
+	ctx context.Context,
+	index *sqlbase.IndexDescriptor,
+	fn func(ctx context.Context, txn *client.Txn, rows []parser.Datums) error,
+) error {
+	var rf sqlbase.RowFetcher
+	if err := n.initRowFetcher(&rf, index); err != nil {
+		return err
+	}
+
+	batchSize := scrubBatchSize.Get()
+	maxKeys := batchSize * int64(n.tableDesc.KeysPerRow(index.ID))
+	limit := rate.Inf
+	if rowsPerSecond := scrubRateLimit.Get(); rowsPerSecond > 0 {
+		limit = rate.Limit(rowsPerSecond) / rate.Limit(batchSize)
+	}
+	limiter := rate.NewLimiter(limit, 1 /* burst size */)
+
+	span := n.tableDesc.IndexSpan(index.ID)
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		var resume roachpb.Key
+		if err := n.p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			txn.SetFixedTimestamp(n.ts)
+			n.batchRows = n.batchRows[:0]
+
+			kvs, err := txn.Scan(ctx, span.Key, span.EndKey, maxKeys)
+			if err != nil {
+				return err
+			}
+			resume = nil
+			if int64(len(kvs)) == maxKeys {
+				// The keys of the last row may continue past the scanned ones, in
+				// which case the row is left for the next batch.
+				last := kvs[len(kvs)-1].Key
+				resume = last.Next()
+				if rowKey, err := keys.EnsureSafeSplitKey(last); err == nil {
+					i := len(kvs) - 1
+					for i > 0 && bytes.HasPrefix(kvs[i-1].Key, rowKey) {
+						i--
+					}
+					if i > 0 {
+						resume = kvs[i].Key
+						kvs = kvs[:i]
+					}
+				}
+			}
+
+			rows, err := n.decodeRows(ctx, &rf, index, kvs)
+			if err != nil {
+				return err
+			}
+			return fn(ctx, txn, rows)
+		}); err != nil {
+			return err
+		}
+		n.rows = append(n.rows, n.batchRows...)
+		if resume == nil {
+			return nil
+		}
+		span.Key = resume
+	}
+}
+
+// decodeRows decodes the rows of the given key-value pairs of an index, one
+// row at a time. With the physical check, the rows that cannot be decoded are
+// reported and skipped; otherwise they fail the scrub.
+func (n *scrubNode) decodeRows(
+	ctx context.Context,
+	rf *sqlbase.RowFetcher,
+	index *sqlbase.IndexDescriptor,
+	kvs []client.KeyValue,
+) ([]parser.Datums, error) {
+	var rows []parser.Datums
+	for len(kvs) > 0 {
+		end := 1
+		rowKey, err := keys.EnsureSafeSplitKey(kvs[0].Key)
+		if err == nil {
+			for end < len(kvs) && bytes.HasPrefix(kvs[end].Key, rowKey) {
+				end++
+			}
+		}
+		rowKVs := kvs[:end]
+		kvs = kvs[end:]
+
+		var row parser.Datums
+		if err == nil && n.checks.physical {
+			for _, kv := range rowKVs {
+				if err = kv.Value.Verify(kv.Key); err != nil {
+					break
+				}
+			}
+		}
+		if err == nil {
+			row, err = decodeScrubRow(ctx, rf, rowKVs)
+		}
+		if err != nil {
+			if !n.checks.physical {
+				return nil, errors.Wrapf(err, "decoding %s", keys.PrettyPrint(rowKVs[0].Key))
+			}
+			if err := n.report(scrubErrorInvalidEncoding, nil, nil, map[string]interface{}{
+				"index_name": index.Name,
+				"key":        keys.PrettyPrint(rowKVs[0].Key),
+				"error":      err.Error(),
+			}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if row != nil {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// decodeScrubRow decodes the row made of the given key-value pairs, or returns
+// nil if they don't belong to the index of the RowFetcher (e.g. if they are
+// the keys of an interleaved table).
+func decodeScrubRow(
+	ctx context.Context, rf *sqlbase.RowFetcher, kvs []client.KeyValue,
+) (parser.Datums, error) {
+	if err := rf.StartScanFrom(ctx, kvs); err != nil {
+		return nil, err
+	}
+	row, err := rf.NextRowDecoded(ctx)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return append(parser.Datums(nil), row...), nil
+}
+
+// checkPrimaryRows runs the checks of a batch of rows of the primary index.
+func (n *scrubNode) checkPrimaryRows(
+	ctx context.Context, txn *client.Txn, rows []parser.Datums,
+) error {
+	if err := n.checkIndexEntries(ctx, txn, rows); err != nil {
+		return err
+	}
+	if err := n.checkCheckConstraints(rows); err != nil {
+		return err
+	}
+	return n.checkForeignKeys(ctx, txn, rows)
+}
+
+// checkIndexEntries checks that the secondary indexes have the entries
+// expected for the given rows of the primary index.
+func (n *scrubNode) checkIndexEntries(
+	ctx context.Context, txn *client.Txn, rows []parser.Datums,
+) error {
+	type expectedEntry struct {
+		row   parser.Datums
+		index *sqlbase.IndexDescriptor
+		entry sqlbase.IndexEntry
+	}
+	var expected []expectedEntry
+	b := txn.NewBatch()
+	for _, row := range rows {
+		for i := range n.checks.indexes {
+			index := &n.checks.indexes[i]
+			entries, err := sqlbase.EncodeSecondaryIndex(n.tableDesc, index, n.colIdxMap, row)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				b.Get(entry.Key)
+				expected = append(expected, expectedEntry{row: row, index: index, entry: entry})
+			}
+		}
+	}
+	if len(expected) == 0 {
+		return nil
+	}
+	if err := txn.Run(ctx, b); err != nil {
+		return err
+	}
+
+	primary := &n.tableDesc.PrimaryIndex
+	for i, e := range expected {
+		details := map[string]interface{}{"index_name": e.index.Name}
+		value := b.Results[i].Rows[0].Value
+		if value == nil {
+			if err := n.report(scrubErrorMissingIndexEntry, e.row, primary, details); err != nil {
+				return err
+			}
+			continue
+		}
+		want, err := e.entry.Value.GetBytes()
+		if err != nil {
+			return err
+		}
+		if got, err := value.GetBytes(); err != nil || !bytes.Equal(got, want) {
+			if err := n.report(scrubErrorIndexValueMismatch, e.row, primary, details); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkCheckConstraints checks that the given rows of the primary index
+// satisfy the CHECK constraints.
+func (n *scrubNode) checkCheckConstraints(rows []parser.Datums) error {
+	if len(n.checks.checks) == 0 {
+		return nil
+	}
+	for _, row := range rows {
+		if err := n.checkHelper.loadRow(n.colIdxMap, row, false /* merge */); err != nil {
+			return err
+		}
+		for _, i := range n.checks.checks {
+			d, err := n.checkHelper.exprs[i].Eval(&n.p.evalCtx)
+			if err != nil {
+				return err
+			}
+			if res, err := parser.GetBool(d); err != nil {
+				return err
+			} else if res || d == parser.DNull {
+				continue
+			}
+			check := n.tableDesc.Checks[i]
+			if err := n.report(scrubErrorCheckViolation, row, &n.tableDesc.PrimaryIndex,
+				map[string]interface{}{
+					"constraint_name": check.Name,
+					"expr":            check.Expr,
+				}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkForeignKeys checks that the rows referenced by the given rows of the
+// primary index exist.
+func (n *scrubNode) checkForeignKeys(
+	ctx context.Context, txn *client.Txn, rows []parser.Datums,
+) error {
+	for i := range n.fks {
+		fk := &n.fks[i]
+
+		// As when rows are inserted, a reference is only skipped when all its
+		// columns are NULL. A reference to a full key of the referenced index
+		// only needs to look up the first key of the referenced row.
+		type reference struct {
+			row    parser.Datums
+			isScan bool
+		}
+		var refs []reference
+		b := txn.NewBatch()
+		for _, row := range rows {
+			allNulls := true
+			for _, colID := range fk.index.ColumnIDs[:fk.prefixLen] {
+				allNulls = allNulls && row[n.colIdxMap[colID]] == parser.DNull
+			}
+			if allNulls {
+				continue
+			}
+			key, containsNull, err := sqlbase.EncodePartialIndexKey(
+				fk.refTable, fk.refIndex, fk.prefixLen, fk.colMap, row, fk.refPrefix)
+			if err != nil {
+				return err
+			}
+			ref := reference{row: row, isScan: containsNull || fk.prefixLen < len(fk.refIndex.ColumnIDs)}
+			if ref.isScan {
+				b.Scan(roachpb.Key(key), roachpb.Key(key).PrefixEnd())
+			} else {
+				b.Get(keys.MakeRowSentinelKey(key))
+			}
+			refs = append(refs, ref)
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		if err := txn.Run(ctx, b); err != nil {
+			return err
+		}
+
+		for j, ref := range refs {
+			var found bool
+			if ref.isScan {
+				refRow, err := decodeScrubRow(ctx, &fk.rf, b.Results[j].Rows)
+				if err != nil {
+					return err
+				}
+				found = refRow != nil
+			} else {
+				found = b.Results[j].Rows[0].Value != nil
+			}
+			if found {
+				continue
+			}
+			if err := n.report(scrubErrorForeignKeyViolation, ref.row, &n.tableDesc.PrimaryIndex,
+				map[string]interface{}{
+					"constraint_name":  fk.index.ForeignKey.Name,
+					"referenced_table": fk.refTable.Name,
+				}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkIndexRows checks that the given entries of a secondary index belong to
+// rows of the primary index.
+func (n *scrubNode) checkIndexRows(
+	ctx context.Context, txn *client.Txn, index *sqlbase.IndexDescriptor, rows []parser.Datums,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// Look up every column family of the rows of the primary index.
+	families := n.tableDesc.Families
+	b := txn.NewBatch()
+	for _, row := range rows {
+		key, _, err := sqlbase.EncodeIndexKey(
+			n.tableDesc, &n.tableDesc.PrimaryIndex, n.colIdxMap, row, n.primaryPrefix)
+		if err != nil {
+			return err
+		}
+		for _, family := range families {
+			b.Get(keys.MakeFamilyKey(append([]byte(nil), key...), uint32(family.ID)))
+		}
+	}
+	if err := txn.Run(ctx, b); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		// The families are ordered by ID, so their keys are in the order of a
+		// scan.
+		var kvs []client.KeyValue
+		for _, result := range b.Results[i*len(families) : (i+1)*len(families)] {
+			if result.Rows[0].Value != nil {
+				kvs = append(kvs, result.Rows[0])
+			}
+		}
+		if len(kvs) > 0 {
+			primaryRow, err := decodeScrubRow(ctx, &n.primaryFetcher, kvs)
+			if err != nil {
+				// The primary index was read at the same timestamp, so this row
+				// has already been reported by the physical check.
+				continue
+			}
+			if matches, err := n.indexEntryMatches(index, row, primaryRow); err != nil {
+				return err
+			} else if matches {
+				continue
+			}
+		}
+		if err := n.report(scrubErrorDanglingIndexReference, row, index,
+			map[string]interface{}{"index_name": index.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexEntryMatches returns whether the entry of a secondary index decoded
+// into entryRow is one of the entries expected for the row of the primary
+// index.
+func (n *scrubNode) indexEntryMatches(
+	index *sqlbase.IndexDescriptor, entryRow, primaryRow parser.Datums,
+) (bool, error) {
+	if primaryRow == nil {
+		return false, nil
+	}
+	entries, err := sqlbase.EncodeSecondaryIndex(n.tableDesc, index, n.colIdxMap, entryRow)
+	if err != nil {
+		return false, err
+	}
+	expected, err := sqlbase.EncodeSecondaryIndex(n.tableDesc, index, n.colIdxMap, primaryRow)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		for _, e := range expected {
+			if entry.Key.Equal(e.Key) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// report records an error found in the batch being checked. row is the row
+// of the table involved, if any, as read from the given index.
+func (n *scrubNode) report(
+	errorType string,
+	row parser.Datums,
+	index *sqlbase.IndexDescriptor,
+	details map[string]interface{},
+) error {
+	var primaryKey parser.Datum = parser.DNull
+	if row != nil {
+		primaryKey = parser.NewDString(n.formatPrimaryKey(row))
+		details["row_data"] = n.formatRowData(row, index)
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	n.batchRows = append(n.batchRows, parser.Datums{
+		parser.NewDString(errorType),
+		parser.NewDString(string(n.tn.DatabaseName)),
+		parser.NewDString(n.tableDesc.Name),
+		primaryKey,
+		parser.MakeDTimestamp(n.ts.GoTime(), time.Microsecond),
+		parser.NewDString(string(detailsJSON)),
+	})
+	return nil
+}
+
+// formatPrimaryKey formats the values of the primary key of a row as a
+// tuple, e.g. (1, 'foo').
+func (n *scrubNode) formatPrimaryKey(row parser.Datums) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for i, colID := range n.tableDesc.PrimaryIndex.ColumnIDs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		parser.FormatNode(&buf, parser.FmtSimple, row[n.colIdxMap[colID]])
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// formatRowData formats the values of the columns of a row that are stored in
+// the index it was read from.
+func (n *scrubNode) formatRowData(
+	row parser.Datums, index *sqlbase.IndexDescriptor,
+) map[string]string {
+	isSecondaryIndex := index.ID != n.tableDesc.PrimaryIndex.ID
+	data := make(map[string]string)
+	for i, col := range n.tableDesc.Columns {
+		if isSecondaryIndex && !index.ContainsColumnID(col.ID) {
+			continue
+		}
+		data[col.Name] = parser.AsStringWithFlags(row[i], parser.FmtSimple)
+	}
+	return data
+}
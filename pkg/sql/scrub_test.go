@@ -0,0 +1,191 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	gosql "database/sql"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+type scrubResult struct {
+	errorType  string
+	database   string
+	table      string
+	primaryKey gosql.NullString
+	details    string
+}
+
+func runScrub(t *testing.T, db *gosql.DB, stmt string) []scrubResult {
+	rows, err := db.Query(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var results []scrubResult
+	for rows.Next() {
+		var r scrubResult
+		var ts interface{}
+		if err := rows.Scan(
+			&r.errorType, &r.database, &r.table, &r.primaryKey, &ts, &r.details,
+		); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func checkScrubResults(t *testing.T, results []scrubResult, errorType, primaryKey string) {
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %+v", results)
+	}
+	r := results[0]
+	if r.errorType != errorType {
+		t.Errorf("expected error type %s, got %s", errorType, r.errorType)
+	}
+	if r.database != "t" || r.table != "test" {
+		t.Errorf("expected table t.test, got %s.%s", r.database, r.table)
+	}
+	if primaryKey == "" {
+		if r.primaryKey.Valid {
+			t.Errorf("expected a NULL primary key, got %s", r.primaryKey.String)
+		}
+	} else if r.primaryKey.String != primaryKey {
+		t.Errorf("expected primary key %s, got %s", primaryKey, r.primaryKey.String)
+	}
+}
+
+// scrubIndexEntry returns the entry of the secondary index of t.test for
+// the row (k, v).
+func scrubIndexEntry(t *testing.T, tableDesc *sqlbase.TableDescriptor, k, v int) sqlbase.IndexEntry {
+	colMap := map[sqlbase.ColumnID]int{
+		tableDesc.Columns[0].ID: 0,
+		tableDesc.Columns[1].ID: 1,
+	}
+	values := []parser.Datum{parser.NewDInt(parser.DInt(k)), parser.NewDInt(parser.DInt(v))}
+	entries, err := sqlbase.EncodeSecondaryIndex(tableDesc, &tableDesc.Indexes[0], colMap, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries[0]
+}
+
+func setupScrubTest(t *testing.T, db *gosql.DB, kvDB *client.DB) *sqlbase.TableDescriptor {
+	if _, err := db.Exec(`
+		DROP DATABASE IF EXISTS t;
+		CREATE DATABASE t;
+		CREATE TABLE t.test (k INT PRIMARY KEY, v INT, INDEX secondary (v));
+		INSERT INTO t.test VALUES (10, 20), (30, 40);
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if results := runScrub(t, db, `EXPERIMENTAL SCRUB TABLE t.test`); len(results) != 0 {
+		t.Fatalf("expected no errors before corrupting the table, got %+v", results)
+	}
+	return sqlbase.GetTableDescriptor(kvDB, "t", "test")
+}
+
+func TestScrub(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ctx := context.TODO()
+
+	t.Run("missing index entry", func(t *testing.T) {
+		tableDesc := setupScrubTest(t, db, kvDB)
+		entry := scrubIndexEntry(t, tableDesc, 10, 20)
+		if err := kvDB.Del(ctx, entry.Key); err != nil {
+			t.Fatal(err)
+		}
+		results := runScrub(t, db, `EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS INDEX ALL`)
+		checkScrubResults(t, results, "missing_index_entry", "(10)")
+		if !strings.Contains(results[0].details, `"index_name":"secondary"`) {
+			t.Errorf("expected the index name in the details, got %s", results[0].details)
+		}
+	})
+
+	t.Run("dangling index reference", func(t *testing.T) {
+		tableDesc := setupScrubTest(t, db, kvDB)
+		entry := scrubIndexEntry(t, tableDesc, 50, 60)
+		if err := kvDB.Put(ctx, entry.Key, &entry.Value); err != nil {
+			t.Fatal(err)
+		}
+		results := runScrub(t, db, `EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS INDEX (secondary)`)
+		checkScrubResults(t, results, "dangling_index_reference", "(50)")
+		if !strings.Contains(results[0].details, `"row_data":{"k":"50","v":"60"}`) {
+			t.Errorf("expected the row of the index entry in the details, got %s", results[0].details)
+		}
+	})
+
+	t.Run("check constraint violation", func(t *testing.T) {
+		setupScrubTest(t, db, kvDB)
+		// Adding a CHECK constraint doesn't validate the existing rows.
+		if _, err := db.Exec(`ALTER TABLE t.test ADD CONSTRAINT v_big CHECK (v > 30)`); err != nil {
+			t.Fatal(err)
+		}
+		results := runScrub(t, db, `EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS CONSTRAINT (v_big)`)
+		checkScrubResults(t, results, "check_constraint_violation", "(10)")
+		if !strings.Contains(results[0].details, `"constraint_name":"v_big"`) {
+			t.Errorf("expected the constraint name in the details, got %s", results[0].details)
+		}
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		tableDesc := setupScrubTest(t, db, kvDB)
+		colMap := map[sqlbase.ColumnID]int{tableDesc.Columns[0].ID: 0}
+		key, _, err := sqlbase.EncodeIndexKey(
+			tableDesc, &tableDesc.PrimaryIndex, colMap,
+			[]parser.Datum{parser.NewDInt(70)},
+			sqlbase.MakeIndexKeyPrefix(tableDesc, tableDesc.PrimaryIndex.ID),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// v is stored in the value of the key of the first family; store bytes
+		// instead of an INT there.
+		var value roachpb.Value
+		value.SetString("garbage")
+		if err := kvDB.Put(ctx, keys.MakeFamilyKey(key, 0), &value); err != nil {
+			t.Fatal(err)
+		}
+
+		results := runScrub(t, db, `EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS PHYSICAL`)
+		checkScrubResults(t, results, "invalid_encoding", "")
+
+		// Without the PHYSICAL check, the row can't be decoded to be checked.
+		if _, err := db.Exec(
+			`EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS INDEX ALL`,
+		); err == nil {
+			t.Fatal("expected an error decoding the corrupted row")
+		}
+	})
+}
@@ -0,0 +1,365 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// sequenceState holds the values most recently obtained with nextval()
+// in a session, for use by currval().
+type sequenceState struct {
+	syncutil.Mutex
+	latestValues map[sqlbase.ID]int64
+}
+
+func (ss *sequenceState) recordValue(seqID sqlbase.ID, val int64) {
+	ss.Lock()
+	defer ss.Unlock()
+	if ss.latestValues == nil {
+		ss.latestValues = make(map[sqlbase.ID]int64)
+	}
+	ss.latestValues[seqID] = val
+}
+
+func (ss *sequenceState) getLastValue(seqID sqlbase.ID) (int64, bool) {
+	ss.Lock()
+	defer ss.Unlock()
+	val, ok := ss.latestValues[seqID]
+	return val, ok
+}
+
+// sequenceCache holds, for each sequence with a cache size greater than
+// one, a range of values reserved by the current node. The values are
+// handed out to the sessions of the node without going to the KV layer
+// until the range is exhausted, which amortizes the contention on the
+// key of the sequence.
+type sequenceCache struct {
+	syncutil.Mutex
+	seqs map[sqlbase.ID]*cachedSequence
+}
+
+// cachedSequence is the range of values of a sequence reserved by the
+// current node.
+type cachedSequence struct {
+	syncutil.Mutex
+	// version is the version of the sequence descriptor with which the
+	// range was reserved. A new range is reserved when the options of the
+	// sequence may have changed.
+	version sqlbase.DescriptorVersion
+	// next is the next value to hand out.
+	next int64
+	// remaining is the number of values left in the range.
+	remaining int64
+}
+
+func (sc *sequenceCache) get(seqID sqlbase.ID) *cachedSequence {
+	sc.Lock()
+	defer sc.Unlock()
+	if sc.seqs == nil {
+		sc.seqs = make(map[sqlbase.ID]*cachedSequence)
+	}
+	cs, ok := sc.seqs[seqID]
+	if !ok {
+		cs = &cachedSequence{}
+		sc.seqs[seqID] = cs
+	}
+	return cs
+}
+
+// IncrementSequence implements the parser.EvalPlanner interface.
+// Privileges: UPDATE on sequence.
+//   Notes: postgres requires USAGE or UPDATE on the sequence.
+func (p *planner) IncrementSequence(ctx context.Context, seqName *parser.TableName) (int64, error) {
+	descriptor, err := p.getSequenceLease(ctx, seqName)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.CheckPrivilege(descriptor, privilege.UPDATE); err != nil {
+		return 0, err
+	}
+
+	opts := descriptor.SequenceOpts
+	var val int64
+	if cache := p.session.sequenceCache; cache != nil && opts.CacheSize > 1 {
+		val, err = p.incrementCachedSequence(ctx, cache.get(descriptor.ID), descriptor)
+	} else {
+		val, err = p.incrementSequenceKey(ctx, descriptor, opts.Increment)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if val > opts.MaxValue || val < opts.MinValue {
+		return 0, sequenceLimitError(descriptor, val > opts.MaxValue)
+	}
+
+	p.session.sequenceState.recordValue(descriptor.ID, val)
+	return val, nil
+}
+
+// sequenceLimitError returns the error with which nextval() fails once the
+// sequence reached its maximum value, or its minimum value if max is false.
+func sequenceLimitError(descriptor *sqlbase.TableDescriptor, max bool) error {
+	bound, boundName := descriptor.SequenceOpts.MaxValue, "maximum"
+	if !max {
+		bound, boundName = descriptor.SequenceOpts.MinValue, "minimum"
+	}
+	return pgerror.NewErrorf(pgerror.CodeSequenceGeneratorLimitExceeded,
+		"reached %s value of sequence %q (%d)", boundName, descriptor.Name, bound)
+}
+
+// incrementSequenceKey advances the value of the sequence stored in the
+// KV layer by the given amount and returns the new value.
+//
+// The increment is performed outside of the current transaction, so
+// that the sessions using the sequence do not contend with each other
+// and that the values are never handed out twice, even if the
+// transaction is aborted.
+func (p *planner) incrementSequenceKey(
+	ctx context.Context, descriptor *sqlbase.TableDescriptor, delta int64,
+) (int64, error) {
+	seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
+	res, err := p.ExecCfg().DB.Inc(ctx, seqValueKey, delta)
+	if err != nil {
+		return 0, err
+	}
+	return res.ValueInt(), nil
+}
+
+// incrementCachedSequence hands out the next value of the range of
+// values of the sequence reserved by the current node, reserving a new
+// range first if needed.
+func (p *planner) incrementCachedSequence(
+	ctx context.Context, cs *cachedSequence, descriptor *sqlbase.TableDescriptor,
+) (int64, error) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	opts := descriptor.SequenceOpts
+	if cs.remaining == 0 || cs.version != descriptor.Version {
+		seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
+		kv, err := p.ExecCfg().DB.Get(ctx, seqValueKey)
+		if err != nil {
+			return 0, err
+		}
+		n := sequenceCacheSize(opts, kv.ValueInt())
+		if n == 0 {
+			return 0, sequenceLimitError(descriptor, opts.Increment > 0)
+		}
+		end, err := p.incrementSequenceKey(ctx, descriptor, opts.Increment*n)
+		if err != nil {
+			return 0, err
+		}
+		cs.version = descriptor.Version
+		cs.next = end - opts.Increment*(n-1)
+		cs.remaining = n
+	}
+	val := cs.next
+	cs.next += opts.Increment
+	cs.remaining--
+	return val, nil
+}
+
+// sequenceCacheSize returns the number of values of the sequence to reserve
+// at once, given its current value cur: the cache size, reduced so that the
+// reserved values neither overflow nor go past MINVALUE or MAXVALUE. It is 0
+// if the sequence is exhausted.
+func sequenceCacheSize(opts *sqlbase.SequenceOpts, cur int64) int64 {
+	// The differences are computed on unsigned integers, as they may exceed
+	// math.MaxInt64.
+	var remaining, step uint64
+	if opts.Increment > 0 {
+		step = uint64(opts.Increment)
+		if cur < opts.MaxValue {
+			remaining = (uint64(opts.MaxValue) - uint64(cur)) / step
+		}
+	} else {
+		step = -uint64(opts.Increment)
+		if cur > opts.MinValue {
+			remaining = (uint64(cur) - uint64(opts.MinValue)) / step
+		}
+	}
+	n := uint64(opts.CacheSize)
+	if remaining < n {
+		n = remaining
+	}
+	// The increment of the sequence key by Increment*n must not overflow.
+	maxDelta := uint64(math.MaxInt64)
+	if opts.Increment < 0 {
+		maxDelta++ // -math.MinInt64
+	}
+	if max := maxDelta / step; max < n {
+		n = max
+	}
+	return int64(n)
+}
+
+// GetLatestValueInSessionForSequence implements the parser.EvalPlanner
+// interface.
+// Privileges: SELECT on sequence.
+//   Notes: postgres requires USAGE or SELECT on the sequence.
+func (p *planner) GetLatestValueInSessionForSequence(
+	ctx context.Context, seqName *parser.TableName,
+) (int64, error) {
+	descriptor, err := p.getSequenceLease(ctx, seqName)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.CheckPrivilege(descriptor, privilege.SELECT); err != nil {
+		return 0, err
+	}
+
+	val, ok := p.session.sequenceState.getLastValue(descriptor.ID)
+	if !ok {
+		return 0, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"currval of sequence %q is not yet defined in this session", descriptor.Name)
+	}
+	return val, nil
+}
+
+// getSequenceLease acquires a lease on the descriptor of the given
+// sequence, and verifies that the descriptor is indeed a sequence.
+func (p *planner) getSequenceLease(
+	ctx context.Context, seqName *parser.TableName,
+) (*sqlbase.TableDescriptor, error) {
+	if err := seqName.QualifyWithDatabase(p.session.Database); err != nil {
+		return nil, err
+	}
+	descriptor, err := p.session.leases.getTableLease(ctx, p.txn, p.getVirtualTabler(), seqName)
+	if err != nil {
+		if sqlbase.IsUndefinedTableError(err) {
+			return nil, sqlbase.NewUndefinedSequenceError(seqName.String())
+		}
+		return nil, err
+	}
+	if !descriptor.IsSequence() {
+		return nil, sqlbase.NewWrongObjectTypeError(seqName.String(), "sequence")
+	}
+	return descriptor, nil
+}
+
+// assignSequenceOptions sets the options of the given sequence
+// descriptor from the options of a CREATE or ALTER SEQUENCE statement.
+// When setDefaults is set, the options which are not specified are set
+// to their default value, which depends on the direction of the
+// sequence, like in postgres.
+func assignSequenceOptions(
+	opts *sqlbase.SequenceOpts, optsNode parser.SequenceOptions, setDefaults bool,
+) error {
+	// Determine the direction of the sequence first, as the defaults of
+	// the other options depend on it.
+	if setDefaults {
+		opts.Increment = 1
+		opts.CacheSize = 1
+	}
+	for _, option := range optsNode {
+		if option.Name == parser.SeqOptIncrement {
+			opts.Increment = *option.IntVal
+		}
+	}
+	if opts.Increment == 0 {
+		return errors.New("INCREMENT must not be zero")
+	}
+	if setDefaults {
+		if opts.Increment > 0 {
+			opts.MinValue = 1
+			opts.MaxValue = math.MaxInt64
+		} else {
+			opts.MinValue = math.MinInt64
+			opts.MaxValue = -1
+		}
+	}
+
+	seen := make(map[string]struct{}, len(optsNode))
+	startSpecified := false
+	for _, option := range optsNode {
+		// NO MINVALUE and NO MAXVALUE conflict with MINVALUE and MAXVALUE,
+		// as they share their option name.
+		if _, ok := seen[option.Name]; ok {
+			return errors.New("conflicting or redundant options")
+		}
+		seen[option.Name] = struct{}{}
+
+		switch option.Name {
+		case parser.SeqOptCache:
+			if *option.IntVal < 1 {
+				return errors.Errorf("CACHE (%d) must be greater than zero", *option.IntVal)
+			}
+			opts.CacheSize = *option.IntVal
+		case parser.SeqOptIncrement:
+			// Handled above.
+		case parser.SeqOptMinValue:
+			if option.IntVal == nil {
+				if opts.Increment > 0 {
+					opts.MinValue = 1
+				} else {
+					opts.MinValue = math.MinInt64
+				}
+			} else {
+				opts.MinValue = *option.IntVal
+			}
+		case parser.SeqOptMaxValue:
+			if option.IntVal == nil {
+				if opts.Increment > 0 {
+					opts.MaxValue = math.MaxInt64
+				} else {
+					opts.MaxValue = -1
+				}
+			} else {
+				opts.MaxValue = *option.IntVal
+			}
+		case parser.SeqOptStart:
+			opts.Start = *option.IntVal
+			startSpecified = true
+		case parser.SeqOptNoCycle:
+			// Sequences never cycle.
+		default:
+			return errors.Errorf("unknown sequence option %q", option.Name)
+		}
+	}
+
+	if setDefaults && !startSpecified {
+		if opts.Increment > 0 {
+			opts.Start = opts.MinValue
+		} else {
+			opts.Start = opts.MaxValue
+		}
+	}
+
+	if opts.MinValue >= opts.MaxValue {
+		return errors.Errorf(
+			"MINVALUE (%d) must be less than MAXVALUE (%d)", opts.MinValue, opts.MaxValue)
+	}
+	if opts.Start < opts.MinValue {
+		return errors.Errorf(
+			"START value (%d) cannot be less than MINVALUE (%d)", opts.Start, opts.MinValue)
+	}
+	if opts.Start > opts.MaxValue {
+		return errors.Errorf(
+			"START value (%d) cannot be greater than MAXVALUE (%d)", opts.Start, opts.MaxValue)
+	}
+	return nil
+}
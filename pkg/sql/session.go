@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -159,6 +160,9 @@ const (
 // queryMeta stores metadata about a query. Stored as reference in
 // session.mu.ActiveQueries and planner.queryMeta.
 type queryMeta struct {
+	// The ID of the query, unique across the cluster.
+	id ClusterWideID
+
 	// The timestamp when this query began execution.
 	start time.Time
 
@@ -174,6 +178,22 @@ type queryMeta struct {
 
 	// Current phase of execution of query.
 	phase queryPhase
+
+	// canceled is set once the query is canceled with CANCEL QUERY.
+	canceled bool
+
+	// ctxCancel cancels the context of the query, once the query has started
+	// running with a cancelable context. See setQueryCancelFunc().
+	ctxCancel context.CancelFunc
+}
+
+// cancel marks the query as canceled and cancels its context, if the query
+// has one yet. The session's mu must be held.
+func (q *queryMeta) cancel() {
+	q.canceled = true
+	if q.ctxCancel != nil {
+		q.ctxCancel()
+	}
 }
 
 // queryHandle is a type for uniquely identifying queries in a session.
@@ -195,14 +215,28 @@ type Session struct {
 	// DistSQLMode indicates whether to run queries using the distributed
 	// execution engine.
 	DistSQLMode DistSQLExecMode
+	// IdleInTransactionSessionTimeout is the maximum time the session can
+	// wait for the next statement of its open transaction before it is
+	// closed, or 0 for no limit.
+	IdleInTransactionSessionTimeout time.Duration
 	// Location indicates the current time zone.
 	Location *time.Location
 	// SearchPath is a list of databases that will be searched for a table name
 	// before the database. Currently, this is used only for SELECTs.
 	// Names in the search path must have been normalized already.
 	SearchPath parser.SearchPath
+	// SchemaChangeStartTime is the time before which the schema changes
+	// started by the session don't begin, or the zero time to begin them
+	// immediately.
+	SchemaChangeStartTime time.Time
+	// StatementTimeout is the maximum duration of a statement before it is
+	// canceled, or 0 for no limit.
+	StatementTimeout time.Duration
 	// User is the name of the user logged into the session.
 	User string
+	// Vectorize indicates whether to run the simple aggregations using the
+	// vectorized execution engine (see vectorize.go).
+	Vectorize bool
 
 	//
 	// Session parameters, non-user-configurable.
@@ -232,6 +266,9 @@ type Session struct {
 	// TODO(knz): place this in an executionContext parameter-passing
 	// structure.
 	virtualSchemas virtualSchemaHolder
+	// sequenceState holds the values obtained with nextval() in this
+	// session, for use by currval().
+	sequenceState sequenceState
 
 	// planner is the "default planner" on a session, to save planner allocations
 	// during serial execution. Since planners are not threadsafe, this is only
@@ -243,12 +280,17 @@ type Session struct {
 	// Run-time state.
 	//
 
+	// id is the ID of the session, unique across the cluster.
+	id ClusterWideID
 	// execCfg is the configuration of the Executor that is executing this
 	// session.
 	execCfg *ExecutorConfig
 	// distSQLPlanner is in charge of distSQL physical planning and running
 	// logic.
 	distSQLPlanner *distSQLPlanner
+	// sequenceCache aliases Executor.sequenceCache. It is nil for the
+	// sessions which do not cache the values of sequences.
+	sequenceCache *sequenceCache
 	// context is the Session's base context, to be used for all
 	// SQL-related logging. See Ctx().
 	context context.Context
@@ -279,6 +321,13 @@ type Session struct {
 	// statistics for result sets (which escape transactions).
 	mon        mon.MemoryMonitor
 	sessionMon mon.MemoryMonitor
+	// userLimits aliases Executor.userLimits. It is nil for the sessions
+	// which are not subject to the per-user resource limits.
+	userLimits *userLimits
+	// userResources are the resources shared with the other sessions of
+	// the user on this node, if the per-user resource limits apply to the
+	// session. See StartMonitor().
+	userResources *userResources
 	// emergencyShutdown is set to true by EmergencyClose() to
 	// indicate to Finish() that the session is already closed.
 	emergencyShutdown bool
@@ -310,6 +359,10 @@ type Session struct {
 
 		// ActiveQueries contains all queries in flight.
 		ActiveQueries map[queryHandle]struct{}
+
+		// monitorsStarted is set once the memory monitors of the session
+		// have been set up, after which their usage can be reported.
+		monitorsStarted bool
 	}
 
 	//
@@ -334,6 +387,13 @@ type Session struct {
 	sqlStats *sqlStats
 	// appStats track per-application SQL usage statistics.
 	appStats *appStats
+	// verbosityScope is embedded in the session's context so that the
+	// verbosity of the application's sessions can be escalated. It is
+	// named after the application.
+	verbosityScope log.VerbosityScope
+	// logCapture is embedded in the session's context and collects the
+	// log entries of the session while capture_logs is set.
+	logCapture log.LogCapture
 	// phaseTimes tracks session-level phase times. It is copied-by-value
 	// to each planner in session.newPlanner.
 	phaseTimes phaseTimes
@@ -382,6 +442,63 @@ func (r *SessionRegistry) deregister(s *Session) {
 	r.Unlock()
 }
 
+// CancelQuery cancels the query with the given ID, if it is running in one of
+// the sessions of the registry. It returns whether the query was found. The
+// caller is responsible for checking that the query may be canceled.
+func (r *SessionRegistry) CancelQuery(queryIDStr string) (bool, error) {
+	queryID, err := StringToClusterWideID(queryIDStr)
+	if err != nil {
+		return false, errors.Errorf("query ID %s malformed: %s", queryIDStr, err)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	for session := range r.store {
+		session.mu.Lock()
+		for query := range session.mu.ActiveQueries {
+			if query.id == queryID {
+				(*queryMeta)(query).cancel()
+				session.mu.Unlock()
+				return true, nil
+			}
+		}
+		session.mu.Unlock()
+	}
+
+	return false, errors.Errorf("query ID %s not found", queryID)
+}
+
+// CancelSession cancels the session with the given ID, if it is in the
+// registry. The queries of the session are canceled and the connection of the
+// session is closed, which rolls back its transaction. It returns whether the
+// session was found. The caller is responsible for checking that the session
+// may be canceled.
+func (r *SessionRegistry) CancelSession(sessionIDStr string) (bool, error) {
+	sessionID, err := StringToClusterWideID(sessionIDStr)
+	if err != nil {
+		return false, errors.Errorf("session ID %s malformed: %s", sessionIDStr, err)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	for session := range r.store {
+		if session.id != sessionID {
+			continue
+		}
+		session.mu.Lock()
+		for query := range session.mu.ActiveQueries {
+			(*queryMeta)(query).cancel()
+		}
+		session.mu.Unlock()
+		session.cancel()
+		return true, nil
+	}
+
+	return false, errors.Errorf("session ID %s not found", sessionID)
+}
+
 // SerializeAll returns a slice of all sessions in the registry, converted to serverpb.Sessions.
 func (r *SessionRegistry) SerializeAll() []serverpb.Session {
 	r.Lock()
@@ -407,6 +524,7 @@ func NewSession(
 		distSQLMode = DistSQLExecModeFromInt(e.cfg.TestingKnobs.OverrideDistSQLMode.Get())
 	}
 	s := &Session{
+		id:               GenerateClusterWideID(e.cfg.Clock.Now(), e.cfg.NodeID.Get()),
 		Database:         args.Database,
 		DistSQLMode:      distSQLMode,
 		SearchPath:       sqlbase.DefaultSearchPath,
@@ -418,6 +536,8 @@ func NewSession(
 		parallelizeQueue: MakeParallelizeQueue(NewSpanBasedDependencyAnalyzer()),
 		memMetrics:       memMetrics,
 		sqlStats:         &e.sqlStats,
+		sequenceCache:    &e.sequenceCache,
+		userLimits:       &e.userLimits,
 		defaults: sessionDefaults{
 			applicationName: args.ApplicationName,
 			database:        args.Database,
@@ -443,6 +563,8 @@ func NewSession(
 	if traceSessionEventLogEnabled.Get() {
 		s.eventLog = trace.NewEventLog(fmt.Sprintf("sql [%s]", args.User), remoteStr)
 	}
+	ctx = log.WithVerbosityScope(ctx, &s.verbosityScope)
+	ctx = log.WithLogCapture(ctx, &s.logCapture)
 	s.context, s.cancel = context.WithCancel(ctx)
 
 	e.cfg.SessionRegistry.register(s)
@@ -502,6 +624,9 @@ func (s *Session) Finish(e *Executor) {
 	s.ClearStatementsAndPortals(s.context)
 	s.sessionMon.Stop(s.context)
 	s.mon.Stop(s.context)
+	if s.userResources != nil {
+		s.userLimits.release(s.context, s.userResources)
+	}
 
 	if s.eventLog != nil {
 		s.eventLog.Finish()
@@ -543,6 +668,9 @@ func (s *Session) EmergencyClose() {
 	// Shut the remaining monitors down.
 	s.sessionMon.EmergencyStop(s.context)
 	s.mon.EmergencyStop(s.context)
+	if s.userResources != nil {
+		s.userLimits.release(s.context, s.userResources)
+	}
 
 	// Finalize the event log.
 	if s.eventLog != nil {
@@ -676,6 +804,7 @@ func (s *Session) addActiveQuery(stmt Statement) queryHandle {
 
 	s.mu.Lock()
 	query := &queryMeta{
+		id:    GenerateClusterWideID(s.execCfg.Clock.Now(), s.execCfg.NodeID.Get()),
 		start: timeutil.Now(),
 		sql:   sql,
 		phase: preparing,
@@ -693,6 +822,26 @@ func (s *Session) removeActiveQuery(query queryHandle) {
 	s.mu.Unlock()
 }
 
+// setQueryCancelFunc makes a query cancelable through the given function,
+// which cancels the context used to run the query. The function is called
+// right away if the query was canceled already.
+func (s *Session) setQueryCancelFunc(query queryHandle, cancel context.CancelFunc) {
+	s.mu.Lock()
+	queryMeta := (*queryMeta)(query)
+	queryMeta.ctxCancel = cancel
+	if queryMeta.canceled {
+		cancel()
+	}
+	s.mu.Unlock()
+}
+
+// isQueryCanceled returns whether a query was canceled with CANCEL QUERY.
+func (s *Session) isQueryCanceled(query queryHandle) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return (*queryMeta)(query).canceled
+}
+
 // setQueryExecutionMode is called upon start of execution of a query, and sets
 // the query's metadata to indicate whether it's distributed or not.
 func (s *Session) setQueryExecutionMode(query queryHandle, isDistributed bool) {
@@ -717,14 +866,25 @@ func (s *Session) serialize() serverpb.Session {
 		kvTxnID = txn.ID()
 	}
 
+	// The memory used by the queries is accounted to the monitor of the
+	// transaction running them, which is shared by the parallelized ones.
+	var allocBytes, maxAllocBytes, txnAllocBytes int64
+	if s.mu.monitorsStarted {
+		allocBytes = s.mon.AllocBytes()
+		maxAllocBytes = s.mon.MaximumBytes()
+		txnAllocBytes = s.TxnState.mon.AllocBytes()
+	}
+
 	activeQueries := make([]serverpb.ActiveQuery, 0, len(s.mu.ActiveQueries))
 
 	for query := range s.mu.ActiveQueries {
 		activeQueries = append(activeQueries, serverpb.ActiveQuery{
+			ID:            query.id.String(),
 			Start:         query.start.UTC(),
 			Sql:           query.sql,
 			IsDistributed: query.isDistributed,
 			Phase:         (serverpb.ActiveQuery_Phase)(query.phase),
+			AllocBytes:    txnAllocBytes,
 		})
 	}
 
@@ -735,6 +895,9 @@ func (s *Session) serialize() serverpb.Session {
 		Start:           s.phaseTimes[sessionInit].UTC(),
 		ActiveQueries:   activeQueries,
 		KvTxnID:         kvTxnID,
+		ID:              s.id.String(),
+		AllocBytes:      allocBytes,
+		MaxAllocBytes:   maxAllocBytes,
 	}
 }
 
@@ -1060,7 +1223,10 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 					log.Warningf(ctx, "Error executing schema change: %s", err)
 				}
 				if err == sqlbase.ErrDescriptorNotFound {
-				} else if sqlbase.IsPermanentSchemaChangeError(err) {
+				} else if jobs.IsPausedError(err) || err == errSchemaChangeNotScheduledYet {
+					// The schema change is picked up by the asynchronous schema
+					// changer once its job is resumed or its start time is reached.
+				} else if sqlbase.IsPermanentSchemaChangeError(err) || jobs.IsCanceledError(err) {
 					// All constraint violations can be reported; we report it as the result
 					// corresponding to the statement that enqueued this changer.
 					// There's some sketchiness here: we assume there's a single result
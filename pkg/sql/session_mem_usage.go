@@ -108,7 +108,17 @@ func (s *Session) StartMonitor(pool *mon.MemoryMonitor, reserved mon.BoundAccoun
 	// ask their "parent" for memory as soon as the first
 	// allocation. This is acceptable because the session is single
 	// threaded, and the point of buffering is just to avoid contention.
-	s.mon = mon.MakeMonitor("root",
+	//
+	// If the per-user limits apply, the pool of s.mon is the monitor shared
+	// by the sessions of the user, which draws from the given pool.
+	var limit int64
+	if s.userLimits != nil && hasUserLimits(s.User) {
+		s.userResources = s.userLimits.acquire(s.context, s.User, pool)
+		pool = &s.userResources.mon
+		limit = sessionMaxMemory.Get()
+	}
+	s.mon = mon.MakeMonitorWithLimit("root",
+		limit,
 		s.memMetrics.CurBytesCount,
 		s.memMetrics.MaxBytesHist,
 		-1, math.MaxInt64)
@@ -140,6 +150,10 @@ func (s *Session) deriveAndStartMonitors() {
 		s.memMetrics.TxnCurBytesCount,
 		s.memMetrics.TxnMaxBytesHist,
 		-1, noteworthyMemoryUsageBytes)
+
+	s.mu.Lock()
+	s.mu.monitorsStarted = true
+	s.mu.Unlock()
 }
 
 func (s *Session) makeBoundAccount() mon.BoundAccount {
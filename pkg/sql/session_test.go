@@ -178,3 +178,108 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v TEXT);
 		})
 	}
 }
+
+// Test that a statement running for longer than statement_timeout is
+// canceled and aborts its transaction.
+func TestStatementTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, mainDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := mainDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v TEXT);
+INSERT INTO t.test VALUES (1, 'a');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Block writes to the row with the intent of an open transaction.
+	blocker, err := mainDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = blocker.Rollback() }()
+	if _, err := blocker.Exec(`UPDATE t.test SET v = 'b' WHERE k = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	pgURL, cleanupDB := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestStatementTimeout", url.User(security.RootUser))
+	defer cleanupDB()
+	conn, err := pq.Open(pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	execer := conn.(driver.Execer)
+	if _, err := execer.Exec(`SET statement_timeout = '100ms'`, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = execer.Exec(`UPDATE t.test SET v = 'c' WHERE k = 1`, nil)
+	if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "57014" {
+		t.Fatalf("expected the statement to time out, got %v", err)
+	}
+
+	// The session is usable once the blocking transaction is done.
+	if err := blocker.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := execer.Exec(`UPDATE t.test SET v = 'c' WHERE k = 1`, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test that a session waiting in an open transaction for longer than
+// idle_in_transaction_session_timeout is closed and its transaction rolled
+// back.
+func TestIdleInTransactionSessionTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, mainDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := mainDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v TEXT);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	pgURL, cleanupDB := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestIdleInTransactionSessionTimeout", url.User(security.RootUser))
+	defer cleanupDB()
+	conn, err := pq.Open(pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	execer := conn.(driver.Execer)
+	if _, err := execer.Exec(`SET idle_in_transaction_session_timeout = '100ms'`, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Idle sessions outside of a transaction are not closed.
+	time.Sleep(300 * time.Millisecond)
+	if _, err := execer.Exec(`BEGIN`, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := execer.Exec(`INSERT INTO t.test VALUES (1, 'a')`, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if _, err := execer.Exec(`COMMIT`, nil); err == nil {
+		t.Fatal("expected the idle session to be closed")
+	}
+
+	var count int
+	if err := mainDB.QueryRow(`SELECT COUNT(*) FROM t.test`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the transaction to be rolled back, found %d rows", count)
+	}
+}
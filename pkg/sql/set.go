@@ -18,6 +18,7 @@ package sql
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -104,6 +105,18 @@ func (p *planner) setClusterSetting(
 	name = strings.ToLower(name)
 	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
 
+	row, err := ie.QueryRowInTransaction(
+		ctx, "read-setting", p.txn, "SELECT value FROM system.settings WHERE name = $1", name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var oldValue, newValue *string
+	if row != nil {
+		s := string(parser.MustBeDString(row[0]))
+		oldValue = &s
+	}
+
 	switch len(v) {
 	case 0:
 		if _, err := ie.ExecuteStatementInTransaction(
@@ -123,12 +136,42 @@ func (p *planner) setClusterSetting(
 		); err != nil {
 			return nil, err
 		}
+		newValue = &encoded
 	default:
 		return nil, errors.Errorf("SET %q requires a single value", name)
 	}
+
+	// Record the change in the event log, in the same transaction as the
+	// change itself, so that crdb_internal.cluster_setting_changes tells who
+	// changed a setting and when.
+	if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogSetClusterSetting,
+		0, /* no target */
+		int32(p.evalCtx.NodeID),
+		clusterSettingChange{
+			SettingName: name,
+			OldValue:    oldValue,
+			Value:       newValue,
+			User:        p.session.User,
+		},
+	); err != nil {
+		return nil, err
+	}
 	return &emptyNode{}, nil
 }
 
+// clusterSettingChange is the info of the EventLogSetClusterSetting events.
+// The values are encoded as in system.settings, and nil when the setting
+// has its default value.
+type clusterSettingChange struct {
+	SettingName string
+	OldValue    *string
+	Value       *string
+	User        string
+}
+
 func (p *planner) toSettingString(
 	name string, setting settings.Setting, raw parser.Expr,
 ) (string, error) {
@@ -143,13 +186,18 @@ func (p *planner) toSettingString(
 		}
 		return f(d)
 	}
+	// invalid decorates the errors of the validation functions of settings,
+	// which are not required to mention the setting.
+	invalid := func(err error, val interface{}) error {
+		return errors.Wrapf(err, "invalid value %v for cluster setting '%s'", val, name)
+	}
 
 	switch setting := setting.(type) {
 	case *settings.StringSetting:
 		return typeCheckAndParse(parser.TypeString, func(d parser.Datum) (string, error) {
 			if s, ok := d.(*parser.DString); ok {
 				if err := setting.Validate(string(*s)); err != nil {
-					return "", err
+					return "", invalid(err, d)
 				}
 				return string(*s), nil
 			}
@@ -166,7 +214,7 @@ func (p *planner) toSettingString(
 		return typeCheckAndParse(parser.TypeInt, func(d parser.Datum) (string, error) {
 			if i, ok := d.(*parser.DInt); ok {
 				if err := setting.Validate(int64(*i)); err != nil {
-					return "", err
+					return "", invalid(err, d)
 				}
 				return settings.EncodeInt(int64(*i)), nil
 			}
@@ -176,7 +224,7 @@ func (p *planner) toSettingString(
 		return typeCheckAndParse(parser.TypeFloat, func(d parser.Datum) (string, error) {
 			if f, ok := d.(*parser.DFloat); ok {
 				if err := setting.Validate(float64(*f)); err != nil {
-					return "", err
+					return "", invalid(err, d)
 				}
 				return settings.EncodeFloat(float64(*f)), nil
 			}
@@ -201,18 +249,25 @@ func (p *planner) toSettingString(
 			return "", errors.Errorf("cannot use %s %T value for enum setting, must be int or string", d.ResolvedType(), d)
 		})
 	case *settings.ByteSizeSetting:
-		return typeCheckAndParse(parser.TypeString, func(d parser.Datum) (string, error) {
-			if s, ok := d.(*parser.DString); ok {
-				bytes, err := humanizeutil.ParseBytes(string(*s))
-				if err != nil {
-					return "", err
-				}
-				if err := setting.Validate(bytes); err != nil {
-					return "", err
+		// Byte sizes are either a number of bytes or a string with a size
+		// suffix, e.g. '64MiB'.
+		return typeCheckAndParse(parser.TypeAny, func(d parser.Datum) (string, error) {
+			var bytes int64
+			switch v := d.(type) {
+			case *parser.DInt:
+				bytes = int64(*v)
+			case *parser.DString:
+				var err error
+				if bytes, err = humanizeutil.ParseBytes(string(*v)); err != nil {
+					return "", errors.Wrapf(err, "invalid byte size %s for cluster setting '%s'", d, name)
 				}
-				return settings.EncodeInt(bytes), nil
+			default:
+				return "", errors.Errorf("cannot use %s %T value for byte size setting", d.ResolvedType(), d)
 			}
-			return "", errors.Errorf("cannot use %s %T value for byte size setting", d.ResolvedType(), d)
+			if err := setting.Validate(bytes); err != nil {
+				return "", invalid(err, d)
+			}
+			return settings.EncodeInt(bytes), nil
 		})
 	case *settings.DurationSetting:
 		return typeCheckAndParse(parser.TypeInterval, func(d parser.Datum) (string, error) {
@@ -220,11 +275,11 @@ func (p *planner) toSettingString(
 				if f.Duration.Months > 0 || f.Duration.Days > 0 {
 					return "", errors.Errorf("cannot use day or month specifiers: %s", d.String())
 				}
-				d := time.Duration(f.Duration.Nanos) * time.Nanosecond
-				if err := setting.Validate(d); err != nil {
-					return "", err
+				dur := time.Duration(f.Duration.Nanos) * time.Nanosecond
+				if err := setting.Validate(dur); err != nil {
+					return "", invalid(err, d)
 				}
-				return settings.EncodeDuration(d), nil
+				return settings.EncodeDuration(dur), nil
 			}
 			return "", errors.Errorf("cannot use %s %T value for duration setting", d.ResolvedType(), d)
 		})
@@ -249,6 +304,51 @@ func (p *planner) getStringVal(name string, values []parser.TypedExpr) (string,
 	return string(s), nil
 }
 
+// getTimeoutVal returns the value of a timeout variable, given either as
+// an integer number of milliseconds or as an interval, as in Postgres.
+func (p *planner) getTimeoutVal(name string, values []parser.TypedExpr) (time.Duration, error) {
+	if len(values) != 1 {
+		return 0, fmt.Errorf("set %s: requires a single value", name)
+	}
+	val, err := values[0].Eval(&p.evalCtx)
+	if err != nil {
+		return 0, err
+	}
+	var timeout time.Duration
+	switch v := parser.UnwrapDatum(val).(type) {
+	case *parser.DInt:
+		timeout = time.Duration(*v) * time.Millisecond
+	case *parser.DString:
+		if ms, err := strconv.ParseInt(string(*v), 10, 64); err == nil {
+			timeout = time.Duration(ms) * time.Millisecond
+			break
+		}
+		d, err := parser.ParseDInterval(string(*v))
+		if err != nil {
+			return 0, fmt.Errorf("set %s: invalid timeout %q: %v", name, string(*v), err)
+		}
+		if timeout, err = intervalToDuration(d); err != nil {
+			return 0, err
+		}
+	case *parser.DInterval:
+		if timeout, err = intervalToDuration(v); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("set %s: requires an integer or interval value: %s is a %s",
+			name, values[0], val.ResolvedType())
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("set %s: timeout cannot be negative: %s", name, timeout)
+	}
+	return timeout, nil
+}
+
+func intervalToDuration(d *parser.DInterval) (time.Duration, error) {
+	nanos, _, _, err := d.Duration.Encode()
+	return time.Duration(nanos), err
+}
+
 func (p *planner) SetDefaultIsolation(n *parser.SetDefaultIsolation) (planNode, error) {
 	// Note: We also support SET DEFAULT_TRANSACTION_ISOLATION TO ' .... ' above.
 	// Ensure both versions stay in sync.
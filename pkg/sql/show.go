@@ -20,18 +20,15 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
-	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
-	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
 const (
@@ -153,6 +150,10 @@ func (p *planner) showClusterSetting(name string) (planNode, error) {
 			{Name: "current_value", Typ: parser.TypeString},
 			{Name: "type", Typ: parser.TypeString},
 			{Name: "description", Typ: parser.TypeString},
+			// local_override is true if the current value is the one set on
+			// this node by --settings-override, rather than the cluster-wide
+			// value.
+			{Name: "local_override", Typ: parser.TypeBool},
 		}
 		populate = func(ctx context.Context, v *valuesNode) error {
 			for _, k := range settings.Keys() {
@@ -162,6 +163,7 @@ func (p *planner) showClusterSetting(name string) (planNode, error) {
 					parser.NewDString(setting.String()),
 					parser.NewDString(setting.Typ()),
 					parser.NewDString(setting.Description()),
+					parser.MakeDBool(parser.DBool(settings.IsLocallyOverridden(k))),
 				}); err != nil {
 					return err
 				}
@@ -481,6 +483,18 @@ func (p *planner) showCreateTable(
 	}
 	buf.WriteString(interleave)
 
+	partitioning, err := showCreatePartitioning(desc, &desc.PrimaryIndex)
+	if err != nil {
+		return "", err
+	}
+	buf.WriteString(partitioning)
+
+	storageParams, err := showCreateStorageParams(desc)
+	if err != nil {
+		return "", err
+	}
+	buf.WriteString(storageParams)
+
 	return buf.String(), nil
 }
 
@@ -860,189 +874,40 @@ func (p *planner) ShowConstraints(
 	}, nil
 }
 
+// ShowQueries returns the queries running on the current node, or on all
+// the nodes of the cluster with SHOW CLUSTER QUERIES.
+// Privileges: None.
+//   Notes: only root can see the queries of the other users.
 func (p *planner) ShowQueries(ctx context.Context, n *parser.ShowQueries) (planNode, error) {
-	columns := sqlbase.ResultColumns{
-		{Name: "node_id", Typ: parser.TypeInt},
-		{Name: "username", Typ: parser.TypeString},
-		{Name: "start", Typ: parser.TypeTimestamp},
-		{Name: "query", Typ: parser.TypeString},
-		{Name: "client_address", Typ: parser.TypeString},
-		{Name: "application_name", Typ: parser.TypeString},
-		{Name: "distributed", Typ: parser.TypeBool},
-		{Name: "phase", Typ: parser.TypeString},
+	const query = `SELECT node_id, username, start, query, client_address, application_name,
+		distributed, phase, query_id FROM crdb_internal.%s`
+	table := `node_queries`
+	if n.Cluster {
+		table = `cluster_queries`
 	}
-
-	return &delayedNode{
-		name:    n.String(),
-		columns: columns,
-		constructor: func(ctx context.Context, p *planner) (planNode, error) {
-			statusServer := p.session.execCfg.StatusServer
-
-			var response *serverpb.ListSessionsResponse
-			var err error
-			sessionsRequest := &serverpb.ListSessionsRequest{Username: p.session.User}
-			if n.Cluster {
-				response, err = statusServer.ListSessions(ctx, sessionsRequest)
-			} else {
-				response, err = statusServer.ListLocalSessions(ctx, sessionsRequest)
-			}
-
-			if err != nil {
-				return nil, err
-			}
-
-			v := p.newContainerValuesNode(columns, 0)
-			for _, session := range response.Sessions {
-				for _, query := range session.ActiveQueries {
-					isDistributedDatum := parser.DNull
-					if query.Phase.String() == "EXECUTING" {
-						isDistributedDatum = parser.DBoolFalse
-						if query.IsDistributed {
-							isDistributedDatum = parser.DBoolTrue
-						}
-					}
-					row := parser.Datums{
-						parser.NewDInt(parser.DInt(session.NodeID)),
-						parser.NewDString(session.Username),
-						parser.MakeDTimestamp(query.Start, time.Microsecond),
-						parser.NewDString(query.Sql),
-						parser.NewDString(session.ClientAddress),
-						parser.NewDString(session.ApplicationName),
-						isDistributedDatum,
-						parser.NewDString(strings.ToLower(query.Phase.String())),
-					}
-					if _, err := v.rows.AddRow(ctx, row); err != nil {
-						v.Close(ctx)
-						return nil, err
-					}
-				}
-			}
-
-			for _, rpcErr := range response.Errors {
-				if rpcErr.NodeID != 0 {
-					// Add a row with this node ID, and nulls for all other columns
-					_, err := v.rows.AddRow(ctx, parser.Datums{
-						parser.NewDInt(parser.DInt(rpcErr.NodeID)),
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-					})
-					if err != nil {
-						v.Close(ctx)
-						return nil, err
-					}
-				}
-				log.Warning(ctx, rpcErr.Message)
-			}
-
-			return v, nil
-		},
-	}, nil
-
+	stmt, err := parser.ParseOne(fmt.Sprintf(query, table))
+	if err != nil {
+		return nil, err
+	}
+	return p.newPlan(ctx, stmt, nil)
 }
 
+// ShowSessions returns the sessions open on the current node, or on all the
+// nodes of the cluster with SHOW CLUSTER SESSIONS.
+// Privileges: None.
+//   Notes: only root can see the sessions of the other users.
 func (p *planner) ShowSessions(ctx context.Context, n *parser.ShowSessions) (planNode, error) {
-	columns := sqlbase.ResultColumns{
-		{Name: "node_id", Typ: parser.TypeInt},
-		{Name: "username", Typ: parser.TypeString},
-		{Name: "client_address", Typ: parser.TypeString},
-		{Name: "application_name", Typ: parser.TypeString},
-		{Name: "active_queries", Typ: parser.TypeString},
-		{Name: "session_start", Typ: parser.TypeTimestamp},
-		{Name: "oldest_query_start", Typ: parser.TypeTimestamp},
-		{Name: "kv_txn", Typ: parser.TypeString},
+	const query = `SELECT node_id, username, client_address, application_name, active_queries,
+		session_start, oldest_query_start, kv_txn, session_id FROM crdb_internal.%s`
+	table := `node_sessions`
+	if n.Cluster {
+		table = `cluster_sessions`
 	}
-	return &delayedNode{
-		name:    n.String(),
-		columns: columns,
-		constructor: func(ctx context.Context, p *planner) (planNode, error) {
-			statusServer := p.session.execCfg.StatusServer
-
-			var response *serverpb.ListSessionsResponse
-			var err error
-			sessionsRequest := &serverpb.ListSessionsRequest{Username: p.session.User}
-			if n.Cluster {
-				response, err = statusServer.ListSessions(ctx, sessionsRequest)
-			} else {
-				response, err = statusServer.ListLocalSessions(ctx, sessionsRequest)
-			}
-
-			if err != nil {
-				return nil, err
-			}
-
-			v := p.newContainerValuesNode(columns, len(response.Sessions))
-			for _, session := range response.Sessions {
-
-				// Generate active_queries and oldest_query_start
-				var activeQueries bytes.Buffer
-				var oldestStart time.Time
-				var oldestStartDatum parser.Datum
-
-				for _, query := range session.ActiveQueries {
-					activeQueries.WriteString(query.Sql)
-					activeQueries.WriteString("; ")
-
-					if oldestStart.IsZero() || query.Start.Before(oldestStart) {
-						oldestStart = query.Start
-					}
-				}
-
-				if oldestStart.IsZero() {
-					oldestStartDatum = parser.DNull
-				} else {
-					oldestStartDatum = parser.MakeDTimestamp(oldestStart, time.Microsecond)
-				}
-
-				kvTxnIDDatum := parser.DNull
-				if session.KvTxnID != nil {
-					kvTxnIDDatum = parser.NewDString(session.KvTxnID.String())
-				}
-
-				row := parser.Datums{
-					parser.NewDInt(parser.DInt(session.NodeID)),
-					parser.NewDString(session.Username),
-					parser.NewDString(session.ClientAddress),
-					parser.NewDString(session.ApplicationName),
-					parser.NewDString(activeQueries.String()),
-					parser.MakeDTimestamp(session.Start, time.Microsecond),
-					oldestStartDatum,
-					kvTxnIDDatum,
-				}
-				if _, err := v.rows.AddRow(ctx, row); err != nil {
-					v.Close(ctx)
-					return nil, err
-				}
-			}
-
-			for _, rpcErr := range response.Errors {
-				if rpcErr.NodeID != 0 {
-					// Add a row with this node ID, and nulls for all other columns
-					_, err := v.rows.AddRow(ctx, parser.Datums{
-						parser.NewDInt(parser.DInt(rpcErr.NodeID)),
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-						parser.DNull,
-					})
-					if err != nil {
-						v.Close(ctx)
-						return nil, err
-					}
-				}
-				log.Warning(ctx, rpcErr.Message)
-			}
-
-			return v, nil
-		},
-	}, nil
+	stmt, err := parser.ParseOne(fmt.Sprintf(query, table))
+	if err != nil {
+		return nil, err
+	}
+	return p.newPlan(ctx, stmt, nil)
 }
 
 // ShowTables returns all the tables.
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -107,6 +108,7 @@ type splitNode struct {
 }
 
 func (n *splitNode) Start(ctx context.Context) error {
+	telemetry.Inc("sql.split_at")
 	return n.rows.Start(ctx)
 }
 
@@ -447,6 +449,7 @@ type scatterNode struct {
 }
 
 func (n *scatterNode) Start(ctx context.Context) error {
+	telemetry.Inc("sql.scatter")
 	db := n.p.ExecCfg().DB
 	req := &roachpb.AdminScatterRequest{
 		Span: roachpb.Span{Key: n.span.Key, EndKey: n.span.EndKey},
@@ -0,0 +1,226 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+// RowIndexedVarContainer is used to evaluate the expressions of computed
+// columns over a row. The IndexedVars of the expressions refer to the
+// ordinals of the columns in Cols; Mapping maps the IDs of these columns to
+// their position in CurSourceRow. Columns which are not present in the row
+// evaluate to NULL.
+type RowIndexedVarContainer struct {
+	CurSourceRow parser.Datums
+	Cols         []ColumnDescriptor
+	Mapping      map[ColumnID]int
+}
+
+var _ parser.IndexedVarContainer = &RowIndexedVarContainer{}
+
+// IndexedVarEval implements the parser.IndexedVarContainer interface.
+func (r *RowIndexedVarContainer) IndexedVarEval(
+	idx int, ctx *parser.EvalContext,
+) (parser.Datum, error) {
+	rowIdx, ok := r.Mapping[r.Cols[idx].ID]
+	if !ok {
+		return parser.DNull, nil
+	}
+	return r.CurSourceRow[rowIdx], nil
+}
+
+// IndexedVarResolvedType implements the parser.IndexedVarContainer interface.
+func (r *RowIndexedVarContainer) IndexedVarResolvedType(idx int) parser.Type {
+	return r.Cols[idx].Type.ToDatumType()
+}
+
+// IndexedVarFormat implements the parser.IndexedVarContainer interface.
+func (r *RowIndexedVarContainer) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx int) {
+	parser.Name(r.Cols[idx].Name).Format(buf, f)
+}
+
+// CannotWriteToComputedColError returns the error reported when a value is
+// assigned directly to a computed column.
+func CannotWriteToComputedColError(col ColumnDescriptor) error {
+	return fmt.Errorf("cannot write directly to computed column %q", col.Name)
+}
+
+// HasComputedColumns returns true if the table has computed columns,
+// including columns being added by a schema change.
+func (desc *TableDescriptor) HasComputedColumns() bool {
+	for _, col := range desc.Columns {
+		if col.IsComputed() {
+			return true
+		}
+	}
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil && col.IsComputed() {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveComputedExpr replaces the references to the columns of the table in
+// the expression of a computed column with IndexedVars, whose indexes are the
+// ordinals of the columns in desc.Columns. Computed columns can only refer to
+// public, non-computed columns of the same table.
+func resolveComputedExpr(
+	desc *TableDescriptor, expr parser.Expr, ivarHelper *parser.IndexedVarHelper,
+) (parser.Expr, error) {
+	preFn := func(expr parser.Expr) (err error, recurse bool, newExpr parser.Expr) {
+		vBase, ok := expr.(parser.VarName)
+		if !ok {
+			// Not a VarName, don't do anything to this node.
+			return nil, true, expr
+		}
+
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return err, false, nil
+		}
+
+		c, ok := v.(*parser.ColumnItem)
+		if !ok {
+			return errors.Errorf("%q is not allowed in computed column expressions", v), false, nil
+		}
+		if c.TableName.TableName != "" &&
+			c.TableName.TableName.Normalize() != parser.ReNormalizeName(desc.Name) {
+			return errors.Errorf("computed column expressions cannot reference other tables: %q", c), false, nil
+		}
+
+		normName := c.ColumnName.Normalize()
+		for i := range desc.Columns {
+			col := &desc.Columns[i]
+			if parser.ReNormalizeName(col.Name) != normName {
+				continue
+			}
+			if col.IsComputed() {
+				return errors.Errorf(
+					"computed column expressions cannot reference computed columns: %q", col.Name), false, nil
+			}
+			return nil, false, ivarHelper.IndexedVar(i)
+		}
+		return fmt.Errorf("column %q does not exist", c.ColumnName), false, nil
+	}
+	return parser.SimpleVisit(expr, preFn)
+}
+
+// MakeComputedExpr returns the typed expression of the given computed
+// column, resolved against the columns of the table and bound to container.
+func MakeComputedExpr(
+	col *ColumnDescriptor,
+	desc *TableDescriptor,
+	container parser.IndexedVarContainer,
+	searchPath parser.SearchPath,
+) (parser.TypedExpr, error) {
+	expr, err := parser.ParseExpr(*col.ComputedExpr)
+	if err != nil {
+		return nil, err
+	}
+	ivarHelper := parser.MakeIndexedVarHelper(container, len(desc.Columns))
+	if expr, err = resolveComputedExpr(desc, expr, &ivarHelper); err != nil {
+		return nil, err
+	}
+
+	var p parser.Parser
+	if err := p.AssertNoAggregationOrWindowing(
+		expr, "computed column expressions", searchPath,
+	); err != nil {
+		return nil, err
+	}
+
+	colType := col.Type.ToDatumType()
+	ctx := parser.SemaContext{SearchPath: searchPath}
+	typedExpr, err := parser.TypeCheck(expr, &ctx, colType)
+	if err != nil {
+		return nil, err
+	}
+	if typ := typedExpr.ResolvedType(); typ != parser.TypeNull && !colType.Equivalent(typ) {
+		return nil, errors.Errorf(
+			"expected computed column expression to have type %s, but '%s' has type %s",
+			colType, expr, typ)
+	}
+
+	// Computed values are stored, so they must only depend on the values of
+	// the other columns of the row.
+	if _, err := parser.SimpleVisit(typedExpr, func(
+		expr parser.Expr,
+	) (err error, recurse bool, newExpr parser.Expr) {
+		if f, ok := expr.(*parser.FuncExpr); ok && f.IsImpure() {
+			return errors.Errorf(
+				"impure functions are not allowed in computed column expressions: %s", f), false, expr
+		}
+		return nil, true, expr
+	}); err != nil {
+		return nil, err
+	}
+	return typedExpr, nil
+}
+
+// ValidateComputedColumn checks that the expression of the given computed
+// column is valid for the table: it must only reference existing,
+// non-computed columns of the table, and have the type of the column.
+func ValidateComputedColumn(
+	col *ColumnDescriptor, desc *TableDescriptor, searchPath parser.SearchPath,
+) error {
+	_, err := MakeComputedExpr(col, desc, &RowIndexedVarContainer{Cols: desc.Columns}, searchPath)
+	return err
+}
+
+// ProcessComputedColumns adds the computed columns of the table to cols,
+// including the computed columns being added which are
+// DELETE_AND_WRITE_ONLY, and returns them along with their expressions.
+// The expressions are bound to container, which must evaluate the
+// IndexedVars as the ordinals of the columns in tableDesc.Columns.
+func ProcessComputedColumns(
+	cols []ColumnDescriptor,
+	tableDesc *TableDescriptor,
+	container parser.IndexedVarContainer,
+	searchPath parser.SearchPath,
+) ([]ColumnDescriptor, []ColumnDescriptor, []parser.TypedExpr, error) {
+	var computedCols []ColumnDescriptor
+	for _, col := range tableDesc.Columns {
+		if col.IsComputed() {
+			computedCols = append(computedCols, col)
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if col := m.GetColumn(); col != nil && col.IsComputed() &&
+			m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			computedCols = append(computedCols, *col)
+		}
+	}
+	if len(computedCols) == 0 {
+		return cols, nil, nil, nil
+	}
+
+	computeExprs := make([]parser.TypedExpr, len(computedCols))
+	for i := range computedCols {
+		expr, err := MakeComputedExpr(&computedCols[i], tableDesc, container, searchPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		computeExprs[i] = expr
+	}
+	cols = append(cols[:len(cols):len(cols)], computedCols...)
+	return cols, computedCols, computeExprs, nil
+}
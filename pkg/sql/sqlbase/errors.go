@@ -136,6 +136,11 @@ func NewUndefinedViewError(name string) error {
 	return pgerror.NewErrorf(pgerror.CodeUndefinedTableError, "view %q does not exist", name)
 }
 
+// NewUndefinedSequenceError creates an error that represents a missing sequence.
+func NewUndefinedSequenceError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeUndefinedTableError, "sequence %q does not exist", name)
+}
+
 // IsUndefinedTableError returns true if the error is for an undefined table.
 func IsUndefinedTableError(err error) bool {
 	return errHasCode(err, pgerror.CodeUndefinedTableError)
@@ -0,0 +1,359 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// PartitionSpecialValCode identifies a special value in a partition tuple.
+type PartitionSpecialValCode uint64
+
+const (
+	// PartitionDefaultVal represents the special DEFAULT value, which matches
+	// all the values not matched by another partition.
+	PartitionDefaultVal PartitionSpecialValCode = 0
+	// PartitionMaxVal represents the special MAXVALUE value, which sorts after
+	// all other values.
+	PartitionMaxVal PartitionSpecialValCode = 1
+)
+
+func (c PartitionSpecialValCode) String() string {
+	switch c {
+	case PartitionDefaultVal:
+		return "DEFAULT"
+	case PartitionMaxVal:
+		return "MAXVALUE"
+	}
+	panic("unreachable")
+}
+
+// PartitionTuple is a tuple of values of the partitioning columns. The first
+// len(Datums) columns hold regular values and the remaining SpecialCount
+// columns all hold the special value Special.
+type PartitionTuple struct {
+	Datums       parser.Datums
+	Special      PartitionSpecialValCode
+	SpecialCount int
+}
+
+func (t *PartitionTuple) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for i, d := range t.Datums {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		d.Format(&buf, parser.FmtParsable)
+	}
+	for i := 0; i < t.SpecialCount; i++ {
+		if i > 0 || len(t.Datums) > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(t.Special.String())
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// EncodePartitionTuple encodes a partition tuple with the value encoding, so
+// that it does not depend on the direction of the index columns. Special
+// values are encoded as a NotNull tag followed by their code.
+func EncodePartitionTuple(t *PartitionTuple) ([]byte, error) {
+	var b []byte
+	for _, d := range t.Datums {
+		var err error
+		if b, err = EncodeTableValue(b, ColumnID(encoding.NoColumnID), d); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < t.SpecialCount; i++ {
+		b = encoding.EncodeNotNullValue(b, encoding.NoColumnID)
+		b = encoding.EncodeNonsortingUvarint(b, uint64(t.Special))
+	}
+	return b, nil
+}
+
+// DecodePartitionTuple decodes a tuple encoded by EncodePartitionTuple for the
+// partitioning of the given index. It also returns the index key prefix
+// shared by all the keys matching the tuple.
+func DecodePartitionTuple(
+	a *DatumAlloc,
+	tableDesc *TableDescriptor,
+	idxDesc *IndexDescriptor,
+	partDesc *PartitioningDescriptor,
+	valueEncBuf []byte,
+) (*PartitionTuple, []byte, error) {
+	if len(idxDesc.ColumnIDs) < int(partDesc.NumColumns) {
+		return nil, nil, errors.Errorf("not enough columns in index %q for partitioning", idxDesc.Name)
+	}
+	colIDs := idxDesc.ColumnIDs[:partDesc.NumColumns]
+
+	t := &PartitionTuple{Datums: make(parser.Datums, 0, len(colIDs))}
+	for _, colID := range colIDs {
+		col, err := tableDesc.FindColumnByID(colID)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, dataOffset, _, typ, err := encoding.DecodeValueTag(valueEncBuf)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "decoding partition tuple")
+		}
+		if typ == encoding.NotNull {
+			var code uint64
+			valueEncBuf, _, code, err = encoding.DecodeNonsortingUvarint(valueEncBuf[dataOffset:])
+			if err != nil {
+				return nil, nil, err
+			}
+			special := PartitionSpecialValCode(code)
+			if t.SpecialCount > 0 && t.Special != special {
+				return nil, nil, errors.Errorf("cannot mix %s and %s in a partition tuple",
+					t.Special, special)
+			}
+			t.Special = special
+			t.SpecialCount++
+			continue
+		}
+		if t.SpecialCount > 0 {
+			return nil, nil, errors.Errorf("%s cannot be followed by a value in a partition tuple",
+				t.Special)
+		}
+		var datum parser.Datum
+		datum, valueEncBuf, err = DecodeTableValue(a, col.Type.ToDatumType(), valueEncBuf)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "decoding partition tuple")
+		}
+		t.Datums = append(t.Datums, datum)
+	}
+	if len(valueEncBuf) > 0 {
+		return nil, nil, errors.New("superfluous data in encoded partition tuple")
+	}
+
+	colMap := make(map[ColumnID]int, len(t.Datums))
+	for i := range t.Datums {
+		colMap[colIDs[i]] = i
+	}
+	key, _, err := EncodePartialIndexKey(
+		tableDesc, idxDesc, len(t.Datums), colMap, t.Datums,
+		MakeIndexKeyPrefix(tableDesc, idxDesc.ID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, key, nil
+}
+
+// PartitionNames returns the names of all the partitions of the index.
+func (desc *PartitioningDescriptor) PartitionNames() []string {
+	var names []string
+	for _, l := range desc.List {
+		names = append(names, l.Name)
+	}
+	for _, r := range desc.Range {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// FindPartitionByName returns whether the index has a partition with the given
+// name.
+func (desc *PartitioningDescriptor) FindPartitionByName(name string) bool {
+	for _, n := range desc.PartitionNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePartitioning validates that the partitioning of each index refers to
+// its leading columns, has unique partition names and decodes to valid spans.
+func (desc *TableDescriptor) validatePartitioning() error {
+	return desc.ForeachNonDropIndex(func(idxDesc *IndexDescriptor) error {
+		partDesc := &idxDesc.Partitioning
+		if partDesc.NumColumns == 0 {
+			if len(partDesc.List) > 0 || len(partDesc.Range) > 0 {
+				return errors.Errorf("index %q has partitions but no partitioning columns", idxDesc.Name)
+			}
+			return nil
+		}
+		if len(partDesc.List) > 0 && len(partDesc.Range) > 0 {
+			return errors.Errorf("index %q cannot be partitioned by both LIST and RANGE", idxDesc.Name)
+		}
+		if int(partDesc.NumColumns) > len(idxDesc.ColumnIDs) {
+			return errors.Errorf("index %q is partitioned on %d columns but only has %d",
+				idxDesc.Name, partDesc.NumColumns, len(idxDesc.ColumnIDs))
+		}
+		names := make(map[string]struct{})
+		for _, name := range partDesc.PartitionNames() {
+			if name == "" {
+				return errors.Errorf("index %q has a partition with an empty name", idxDesc.Name)
+			}
+			if _, ok := names[name]; ok {
+				return errors.Errorf("partition %q is defined more than once", name)
+			}
+			names[name] = struct{}{}
+		}
+		_, err := desc.PartitionSpans(idxDesc)
+		return err
+	})
+}
+
+// PartitionSpans returns the key spans of each partition of the given index,
+// keyed by partition name. A list partition covers the keys matching its
+// values, except those matching a more specific value of another partition;
+// DEFAULT matches everything. A range partition covers the keys from the
+// upper bound of the previous partition up to its own.
+func (desc *TableDescriptor) PartitionSpans(
+	idxDesc *IndexDescriptor,
+) (map[string][]roachpb.Span, error) {
+	partDesc := &idxDesc.Partitioning
+	spans := make(map[string][]roachpb.Span)
+	if partDesc.NumColumns == 0 {
+		return spans, nil
+	}
+	var a DatumAlloc
+
+	if len(partDesc.List) > 0 {
+		// Assign the spans of the most specific tuples first, so that a tuple
+		// using DEFAULT only claims what no more specific tuple has.
+		type listSpan struct {
+			name        string
+			span        roachpb.Span
+			specificity int
+		}
+		var listSpans []listSpan
+		seen := make(map[string]string)
+		for _, p := range partDesc.List {
+			for _, encoded := range p.Values {
+				t, key, err := DecodePartitionTuple(&a, desc, idxDesc, partDesc, encoded)
+				if err != nil {
+					return nil, err
+				}
+				if t.SpecialCount > 0 && t.Special != PartitionDefaultVal {
+					return nil, errors.Errorf("%s is not allowed in a list partition", t.Special)
+				}
+				if other, ok := seen[string(encoded)]; ok {
+					return nil, errors.Errorf("%s cannot be present in more than one partition: %q and %q",
+						t, other, p.Name)
+				}
+				seen[string(encoded)] = p.Name
+				listSpans = append(listSpans, listSpan{
+					name:        p.Name,
+					span:        roachpb.Span{Key: key, EndKey: roachpb.Key(key).PrefixEnd()},
+					specificity: len(t.Datums),
+				})
+			}
+		}
+		sort.SliceStable(listSpans, func(i, j int) bool {
+			return listSpans[i].specificity > listSpans[j].specificity
+		})
+		var covered []roachpb.Span
+		for _, ls := range listSpans {
+			remaining := subtractSpans(ls.span, covered)
+			spans[ls.name] = append(spans[ls.name], remaining...)
+			covered = append(covered, remaining...)
+			sort.Slice(covered, func(i, j int) bool {
+				return covered[i].Key.Compare(covered[j].Key) < 0
+			})
+		}
+		return spans, nil
+	}
+
+	lowerBound := desc.IndexSpan(idxDesc.ID).Key
+	for _, p := range partDesc.Range {
+		t, upperBound, err := DecodePartitionTuple(&a, desc, idxDesc, partDesc, p.UpperBound)
+		if err != nil {
+			return nil, err
+		}
+		if t.SpecialCount > 0 {
+			if t.Special != PartitionMaxVal {
+				return nil, errors.Errorf("%s is not allowed in a range partition", t.Special)
+			}
+			upperBound = roachpb.Key(upperBound).PrefixEnd()
+		}
+		if lowerBound.Compare(upperBound) >= 0 {
+			return nil, errors.Errorf(
+				"partitions must be listed in ascending order: %q is empty", p.Name)
+		}
+		spans[p.Name] = []roachpb.Span{{Key: lowerBound, EndKey: upperBound}}
+		lowerBound = upperBound
+	}
+	return spans, nil
+}
+
+// subtractSpans returns the parts of span not covered by any of the sorted,
+// non-overlapping spans in covered.
+func subtractSpans(span roachpb.Span, covered []roachpb.Span) []roachpb.Span {
+	var result []roachpb.Span
+	start := span.Key
+	for _, c := range covered {
+		if c.EndKey.Compare(start) <= 0 {
+			continue
+		}
+		if c.Key.Compare(span.EndKey) >= 0 {
+			break
+		}
+		if start.Compare(c.Key) < 0 {
+			result = append(result, roachpb.Span{Key: start, EndKey: c.Key})
+		}
+		start = c.EndKey
+	}
+	if start.Compare(span.EndKey) < 0 {
+		result = append(result, roachpb.Span{Key: start, EndKey: span.EndKey})
+	}
+	return result
+}
+
+// GenerateSubzoneSpans computes the sorted key spans of the given subzones of
+// the table, for use as the SubzoneSpans of the table's zone config.
+func GenerateSubzoneSpans(
+	tableDesc *TableDescriptor, subzones []config.Subzone,
+) ([]config.SubzoneSpan, error) {
+	var subzoneSpans []config.SubzoneSpan
+	for i, subzone := range subzones {
+		idxDesc, err := tableDesc.FindIndexByID(IndexID(subzone.IndexID))
+		if err != nil {
+			return nil, err
+		}
+		spans, err := tableDesc.PartitionSpans(idxDesc)
+		if err != nil {
+			return nil, err
+		}
+		partitionSpans, ok := spans[subzone.PartitionName]
+		if !ok {
+			return nil, errors.Errorf("partition %q does not exist on index %q",
+				subzone.PartitionName, idxDesc.Name)
+		}
+		for _, span := range partitionSpans {
+			subzoneSpans = append(subzoneSpans, config.SubzoneSpan{
+				Key:          span.Key,
+				EndKey:       span.EndKey,
+				SubzoneIndex: int32(i),
+			})
+		}
+	}
+	sort.Slice(subzoneSpans, func(i, j int) bool {
+		return subzoneSpans[i].Key.Compare(subzoneSpans[j].Key) < 0
+	})
+	return subzoneSpans, nil
+}
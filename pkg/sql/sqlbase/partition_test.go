@@ -0,0 +1,182 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func makePartitionedTableDescForTest(partDesc PartitioningDescriptor) TableDescriptor {
+	return TableDescriptor{
+		ID: 50,
+		Columns: []ColumnDescriptor{
+			{ID: 1, Name: "a", Type: ColumnType{Kind: ColumnType_INT}},
+			{ID: 2, Name: "b", Type: ColumnType{Kind: ColumnType_INT}},
+		},
+		PrimaryIndex: IndexDescriptor{
+			ID:               1,
+			Name:             "primary",
+			ColumnIDs:        []ColumnID{1, 2},
+			ColumnNames:      []string{"a", "b"},
+			ColumnDirections: make([]IndexDescriptor_Direction, 2),
+			Partitioning:     partDesc,
+		},
+	}
+}
+
+func encodePartitionTupleForTest(t *testing.T, tuple PartitionTuple) []byte {
+	b, err := EncodePartitionTuple(&tuple)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPartitionTupleRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	partDesc := PartitioningDescriptor{NumColumns: 2}
+	desc := makePartitionedTableDescForTest(partDesc)
+	prefix := MakeIndexKeyPrefix(&desc, desc.PrimaryIndex.ID)
+
+	testCases := []struct {
+		tuple  PartitionTuple
+		str    string
+		prefix roachpb.Key
+	}{
+		{
+			PartitionTuple{Datums: parser.Datums{parser.NewDInt(1), parser.NewDInt(2)}},
+			"(1, 2)",
+			encoding.EncodeVarintAscending(encoding.EncodeVarintAscending(prefix, 1), 2),
+		},
+		{
+			PartitionTuple{
+				Datums: parser.Datums{parser.NewDInt(1)}, Special: PartitionDefaultVal, SpecialCount: 1,
+			},
+			"(1, DEFAULT)",
+			encoding.EncodeVarintAscending(prefix, 1),
+		},
+		{
+			PartitionTuple{Special: PartitionMaxVal, SpecialCount: 2},
+			"(MAXVALUE, MAXVALUE)",
+			prefix,
+		},
+	}
+	for _, tc := range testCases {
+		encoded := encodePartitionTupleForTest(t, tc.tuple)
+		var a DatumAlloc
+		tuple, key, err := DecodePartitionTuple(&a, &desc, &desc.PrimaryIndex, &partDesc, encoded)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.str, err)
+		}
+		if s := tuple.String(); s != tc.str {
+			t.Errorf("expected %s, got %s", tc.str, s)
+		}
+		if !roachpb.Key(key).Equal(tc.prefix) {
+			t.Errorf("%s: expected key %s, got %s", tc.str, tc.prefix, roachpb.Key(key))
+		}
+	}
+}
+
+func TestPartitionSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	one := parser.NewDInt(1)
+	two := parser.NewDInt(2)
+	desc := makePartitionedTableDescForTest(PartitioningDescriptor{})
+	prefix := roachpb.Key(MakeIndexKeyPrefix(&desc, desc.PrimaryIndex.ID))
+	key1 := roachpb.Key(encoding.EncodeVarintAscending(prefix, 1))
+	key12 := roachpb.Key(encoding.EncodeVarintAscending(key1, 2))
+
+	t.Run("list", func(t *testing.T) {
+		desc.PrimaryIndex.Partitioning = PartitioningDescriptor{
+			NumColumns: 2,
+			List: []PartitioningDescriptor_List{
+				{Name: "p12", Values: [][]byte{
+					encodePartitionTupleForTest(t, PartitionTuple{Datums: parser.Datums{one, two}}),
+				}},
+				{Name: "p1", Values: [][]byte{
+					encodePartitionTupleForTest(t, PartitionTuple{
+						Datums: parser.Datums{one}, Special: PartitionDefaultVal, SpecialCount: 1,
+					}),
+				}},
+				{Name: "rest", Values: [][]byte{
+					encodePartitionTupleForTest(t, PartitionTuple{
+						Special: PartitionDefaultVal, SpecialCount: 2,
+					}),
+				}},
+			},
+		}
+		spans, err := desc.PartitionSpans(&desc.PrimaryIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string][]roachpb.Span{
+			"p12": {{Key: key12, EndKey: key12.PrefixEnd()}},
+			"p1": {
+				{Key: key1, EndKey: key12},
+				{Key: key12.PrefixEnd(), EndKey: key1.PrefixEnd()},
+			},
+			"rest": {
+				{Key: prefix, EndKey: key1},
+				{Key: key1.PrefixEnd(), EndKey: prefix.PrefixEnd()},
+			},
+		}
+		if !reflect.DeepEqual(expected, spans) {
+			t.Errorf("expected\n%v\ngot\n%v", expected, spans)
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		desc.PrimaryIndex.Partitioning = PartitioningDescriptor{
+			NumColumns: 1,
+			Range: []PartitioningDescriptor_Range{
+				{Name: "lo", UpperBound: encodePartitionTupleForTest(t, PartitionTuple{
+					Datums: parser.Datums{one},
+				})},
+				{Name: "hi", UpperBound: encodePartitionTupleForTest(t, PartitionTuple{
+					Special: PartitionMaxVal, SpecialCount: 1,
+				})},
+			},
+		}
+		spans, err := desc.PartitionSpans(&desc.PrimaryIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string][]roachpb.Span{
+			"lo": {{Key: prefix, EndKey: key1}},
+			"hi": {{Key: key1, EndKey: prefix.PrefixEnd()}},
+		}
+		if !reflect.DeepEqual(expected, spans) {
+			t.Errorf("expected\n%v\ngot\n%v", expected, spans)
+		}
+
+		// Out of order partitions are rejected.
+		r := desc.PrimaryIndex.Partitioning.Range
+		r[0], r[1] = r[1], r[0]
+		if _, err := desc.PartitionSpans(&desc.PrimaryIndex); !testutils.IsError(
+			err, "partitions must be listed in ascending order",
+		) {
+			t.Errorf("expected ascending order error, got %v", err)
+		}
+	})
+}
@@ -210,6 +210,19 @@ func (rf *RowFetcher) StartScan(
 	return err
 }
 
+// StartScanFrom initializes a scan over the given key-value pairs, which are
+// decoded without issuing any KV requests. The key-value pairs must be ordered
+// as they would be by a scan in the direction of the RowFetcher. Can be used
+// multiple times.
+func (rf *RowFetcher) StartScanFrom(ctx context.Context, kvs []client.KeyValue) error {
+	rf.indexKey = nil
+	rf.kvFetcher = kvFetcher{kvs: kvs, fetchEnd: true, returnRangeInfo: rf.returnRangeInfo}
+
+	// Retrieve the first key.
+	_, err := rf.NextKey(ctx)
+	return err
+}
+
 // NextKey retrieves the next key/value and sets kv/kvEnd. Returns whether a row
 // has been completed.
 // TODO(andrei): change to return error
@@ -532,7 +545,9 @@ func (rf *RowFetcher) NextRow(ctx context.Context) (EncDatumRow, error) {
 			return nil, err
 		}
 		if rowDone {
-			rf.finalizeRow()
+			if err := rf.finalizeRow(); err != nil {
+				return nil, err
+			}
 			return rf.row, nil
 		}
 	}
@@ -574,18 +589,23 @@ func (rf *RowFetcher) NextKeyDebug(
 		return "", "", nil, err
 	}
 	if rowDone {
-		rf.finalizeRow()
+		if err := rf.finalizeRow(); err != nil {
+			return "", "", nil, err
+		}
 		row = rf.row
 	}
 	return prettyKey, prettyValue, row, nil
 }
 
-func (rf *RowFetcher) finalizeRow() {
+func (rf *RowFetcher) finalizeRow() error {
 	// Fill in any missing values with NULLs
 	for i := range rf.cols {
 		if rf.neededCols.Contains(uint32(rf.cols[i].ID)) && rf.row[i].IsUnset() {
 			if !rf.cols[i].Nullable {
-				panic("Non-nullable column with no value!")
+				// This can only happen if the data is corrupted, e.g. if the
+				// key-value pair of a column family is missing.
+				return errors.Errorf("non-nullable column %q of table %q with no value",
+					rf.cols[i].Name, rf.desc.Name)
 			}
 			rf.row[i] = EncDatum{
 				Type:  rf.cols[i].Type,
@@ -593,6 +613,7 @@ func (rf *RowFetcher) finalizeRow() {
 			}
 		}
 	}
+	return nil
 }
 
 // Key returns the next key (the key that follows the last returned row).
@@ -42,9 +42,10 @@ const (
 
 // rowHelper has the common methods for table row manipulations.
 type rowHelper struct {
-	TableDesc    *TableDescriptor
-	Indexes      []IndexDescriptor
-	indexEntries []IndexEntry
+	TableDesc *TableDescriptor
+	Indexes   []IndexDescriptor
+	// indexEntries holds the entries of each of the Indexes.
+	indexEntries [][]IndexEntry
 
 	// Computed and cached.
 	primaryIndexKeyPrefix []byte
@@ -57,7 +58,7 @@ type rowHelper struct {
 // encodeSecondaryIndexes.
 func (rh *rowHelper) encodeIndexes(
 	colIDtoRowIndex map[ColumnID]int, values []parser.Datum,
-) (primaryIndexKey []byte, secondaryIndexEntries []IndexEntry, err error) {
+) (primaryIndexKey []byte, secondaryIndexEntries [][]IndexEntry, err error) {
 	if rh.primaryIndexKeyPrefix == nil {
 		rh.primaryIndexKeyPrefix = MakeIndexKeyPrefix(rh.TableDesc,
 			rh.TableDesc.PrimaryIndex.ID)
@@ -74,19 +75,33 @@ func (rh *rowHelper) encodeIndexes(
 	return primaryIndexKey, secondaryIndexEntries, nil
 }
 
-// encodeSecondaryIndexes encodes the secondary index keys. The
+// encodeSecondaryIndexes encodes the secondary index keys. The i-th element
+// of secondaryIndexEntries holds the entries of rh.Indexes[i]. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
 func (rh *rowHelper) encodeSecondaryIndexes(
 	colIDtoRowIndex map[ColumnID]int, values []parser.Datum,
-) (secondaryIndexEntries []IndexEntry, err error) {
+) (secondaryIndexEntries [][]IndexEntry, err error) {
 	if len(rh.indexEntries) != len(rh.Indexes) {
-		rh.indexEntries = make([]IndexEntry, len(rh.Indexes))
+		rh.indexEntries = make([][]IndexEntry, len(rh.Indexes))
 	}
-	err = EncodeSecondaryIndexes(
-		rh.TableDesc, rh.Indexes, colIDtoRowIndex, values, rh.indexEntries)
-	if err != nil {
-		return nil, err
+	for i := range rh.Indexes {
+		index := &rh.Indexes[i]
+		if index.Type == IndexDescriptor_INVERTED {
+			rh.indexEntries[i], err = encodeInvertedIndexEntries(
+				rh.TableDesc, index, colIDtoRowIndex, values)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Forward indexes have exactly one entry, whose slot is reused between
+		// rows.
+		entry, err := encodeForwardIndexEntry(rh.TableDesc, index, colIDtoRowIndex, values)
+		if err != nil {
+			return nil, err
+		}
+		rh.indexEntries[i] = append(rh.indexEntries[i][:0], entry)
 	}
 	return rh.indexEntries, nil
 }
@@ -331,9 +346,11 @@ func (ri *RowInserter) InsertRow(
 		ri.key = nil
 	}
 
-	for i := range secondaryIndexEntries {
-		e := &secondaryIndexEntries[i]
-		putFn(ctx, b, &e.Key, &e.Value)
+	for _, entries := range secondaryIndexEntries {
+		for i := range entries {
+			e := &entries[i]
+			putFn(ctx, b, &e.Key, &e.Value)
+		}
 	}
 
 	return nil
@@ -358,7 +375,7 @@ type RowUpdater struct {
 	marshalled      []roachpb.Value
 	newValues       []parser.Datum
 	key             roachpb.Key
-	indexEntriesBuf []IndexEntry
+	indexEntriesBuf [][]IndexEntry
 	valueBuf        []byte
 	value           roachpb.Value
 }
@@ -548,8 +565,13 @@ func (ru *RowUpdater) UpdateRow(
 	// The secondary index entries returned by rowHelper.encodeIndexes are only
 	// valid until the next call to encodeIndexes. We need to copy them so that
 	// we can compare against the new secondary index entries.
-	secondaryIndexEntries = append(ru.indexEntriesBuf[:0], secondaryIndexEntries...)
-	ru.indexEntriesBuf = secondaryIndexEntries
+	if len(ru.indexEntriesBuf) != len(secondaryIndexEntries) {
+		ru.indexEntriesBuf = make([][]IndexEntry, len(secondaryIndexEntries))
+	}
+	for i, entries := range secondaryIndexEntries {
+		ru.indexEntriesBuf[i] = append(ru.indexEntriesBuf[i][:0], entries...)
+	}
+	secondaryIndexEntries = ru.indexEntriesBuf
 
 	// Check that the new value types match the column types. This needs to
 	// happen before index encoding because certain datum types (i.e. tuple)
@@ -567,7 +589,7 @@ func (ru *RowUpdater) UpdateRow(
 	}
 
 	rowPrimaryKeyChanged := false
-	var newSecondaryIndexEntries []IndexEntry
+	var newSecondaryIndexEntries [][]IndexEntry
 	if ru.primaryKeyColChange {
 		var newPrimaryIndexKey []byte
 		newPrimaryIndexKey, newSecondaryIndexEntries, err =
@@ -589,7 +611,11 @@ func (ru *RowUpdater) UpdateRow(
 			return nil, err
 		}
 		for i := range newSecondaryIndexEntries {
-			if !bytes.Equal(newSecondaryIndexEntries[i].Key, secondaryIndexEntries[i].Key) {
+			// Inverted indexes are never referenced by foreign keys.
+			if ru.Helper.Indexes[i].Type == IndexDescriptor_INVERTED {
+				continue
+			}
+			if !bytes.Equal(newSecondaryIndexEntries[i][0].Key, secondaryIndexEntries[i][0].Key) {
 				if err := ru.Fks.checkIdx(ctx, ru.Helper.Indexes[i].ID, oldValues, ru.newValues); err != nil {
 					return nil, err
 				}
@@ -703,8 +729,12 @@ func (ru *RowUpdater) UpdateRow(
 	}
 
 	// Update secondary indexes.
-	for i, newSecondaryIndexEntry := range newSecondaryIndexEntries {
-		secondaryIndexEntry := secondaryIndexEntries[i]
+	for i, newEntries := range newSecondaryIndexEntries {
+		if ru.Helper.Indexes[i].Type == IndexDescriptor_INVERTED {
+			ru.updateInvertedIndex(ctx, b, i, secondaryIndexEntries[i], newEntries)
+			continue
+		}
+		secondaryIndexEntry, newSecondaryIndexEntry := secondaryIndexEntries[i][0], newEntries[0]
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, secondaryIndexEntry.Key) {
 			if err := ru.Fks.checkIdx(ctx, ru.Helper.Indexes[i].ID, oldValues, ru.newValues); err != nil {
@@ -732,6 +762,44 @@ func (ru *RowUpdater) UpdateRow(
 	return ru.newValues, nil
 }
 
+// updateInvertedIndex deletes the entries of the i-th index which are not
+// present anymore in the updated row and puts its new entries. The entries of
+// inverted indexes have empty values, so the entries present in both the old
+// and the updated row are left alone.
+func (ru *RowUpdater) updateInvertedIndex(
+	ctx context.Context, b *client.Batch, i int, oldEntries, newEntries []IndexEntry,
+) {
+	oldKeys := make(map[string]struct{}, len(oldEntries))
+	for _, e := range oldEntries {
+		oldKeys[string(e.Key)] = struct{}{}
+	}
+	newKeys := make(map[string]struct{}, len(newEntries))
+	for _, e := range newEntries {
+		newKeys[string(e.Key)] = struct{}{}
+	}
+	for _, e := range oldEntries {
+		if _, ok := newKeys[string(e.Key)]; !ok {
+			if log.V(2) {
+				log.Infof(ctx, "Del %s", e.Key)
+			}
+			b.Del(e.Key)
+		}
+	}
+	// Do not update Indexes in the DELETE_ONLY state.
+	if _, ok := ru.deleteOnlyIndex[i]; ok {
+		return
+	}
+	for j := range newEntries {
+		e := &newEntries[j]
+		if _, ok := oldKeys[string(e.Key)]; !ok {
+			if log.V(2) {
+				log.Infof(ctx, "CPut %s -> %v", e.Key, e.Value.PrettyPrint())
+			}
+			b.CPut(e.Key, &e.Value, nil)
+		}
+	}
+}
+
 // IsColumnOnlyUpdate returns true if this RowUpdater is only updating column
 // data (in contrast to updating the primary key or other indexes).
 func (ru *RowUpdater) IsColumnOnlyUpdate() bool {
@@ -834,11 +902,13 @@ func (rd *RowDeleter) DeleteRow(ctx context.Context, b *client.Batch, values []p
 		return err
 	}
 
-	for _, secondaryIndexEntry := range secondaryIndexEntries {
-		if log.V(2) {
-			log.Infof(ctx, "Del %s", secondaryIndexEntry.Key)
+	for _, entries := range secondaryIndexEntries {
+		for _, secondaryIndexEntry := range entries {
+			if log.V(2) {
+				log.Infof(ctx, "Del %s", secondaryIndexEntry.Key)
+			}
+			b.Del(secondaryIndexEntry.Key)
 		}
-		b.Del(secondaryIndexEntry.Key)
 	}
 
 	// Delete the row.
@@ -861,15 +931,17 @@ func (rd *RowDeleter) DeleteIndexRow(
 	if err := rd.Fks.checkAll(ctx, values); err != nil {
 		return err
 	}
-	secondaryIndexEntry, err := EncodeSecondaryIndex(
+	secondaryIndexEntries, err := EncodeSecondaryIndex(
 		rd.Helper.TableDesc, idx, rd.FetchColIDtoRowIndex, values)
 	if err != nil {
 		return err
 	}
-	if log.V(2) {
-		log.Infof(ctx, "Del %s", secondaryIndexEntry.Key)
+	for _, secondaryIndexEntry := range secondaryIndexEntries {
+		if log.V(2) {
+			log.Infof(ctx, "Del %s", secondaryIndexEntry.Key)
+		}
+		b.Del(secondaryIndexEntry.Key)
 	}
-	b.Del(secondaryIndexEntry.Key)
 	return nil
 }
 
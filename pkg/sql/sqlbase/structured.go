@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
 
@@ -293,6 +294,18 @@ func (desc *IndexDescriptor) SQLString(tableName string) string {
 	if tableName != "" {
 		onTable = fmt.Sprintf("ON %s ", tableName)
 	}
+	if desc.Type == IndexDescriptor_INVERTED {
+		// The columns of inverted indexes have no direction.
+		colNames := make(parser.NameList, len(desc.ColumnNames))
+		for i, n := range desc.ColumnNames {
+			colNames[i] = parser.Name(n)
+		}
+		return fmt.Sprintf("INVERTED INDEX %s%s (%s)",
+			onTable,
+			parser.AsString(parser.Name(desc.Name)),
+			parser.AsString(colNames),
+		)
+	}
 	return fmt.Sprintf("%sINDEX %s%s (%s)%s",
 		isUnique[desc.Unique],
 		onTable,
@@ -312,6 +325,9 @@ func (desc *TableDescriptor) TypeName() string {
 	if desc.IsView() {
 		return "view"
 	}
+	if desc.IsSequence() {
+		return "sequence"
+	}
 	return "table"
 }
 
@@ -329,7 +345,7 @@ func (desc *TableDescriptor) IsEmpty() bool {
 // IsTable returns true if the TableDescriptor actually describes a
 // Table resource, as opposed to a different resource (like a View).
 func (desc *TableDescriptor) IsTable() bool {
-	return !desc.IsView()
+	return !desc.IsView() && !desc.IsSequence()
 }
 
 // IsView returns true if the TableDescriptor actually describes a
@@ -338,6 +354,12 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// IsSequence returns true if the TableDescriptor actually describes a
+// Sequence resource rather than a Table.
+func (desc *TableDescriptor) IsSequence() bool {
+	return desc.SequenceOpts != nil
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -1058,6 +1080,12 @@ func (desc *TableDescriptor) ValidateTable() error {
 		if err := desc.validateTableIndexes(columnNames, colIDToFamilyID); err != nil {
 			return err
 		}
+		if err := desc.validateRowLevelTTL(); err != nil {
+			return err
+		}
+		if err := desc.validatePartitioning(); err != nil {
+			return err
+		}
 	}
 
 	// Validate the privilege descriptor.
@@ -1189,6 +1217,10 @@ func (desc *TableDescriptor) validateTableIndexes(
 					index.Name, name, colID, index.ColumnIDs[i])
 			}
 		}
+
+		if err := desc.validateIndexType(index); err != nil {
+			return err
+		}
 	}
 
 	for _, colID := range desc.PrimaryIndex.ColumnIDs {
@@ -1201,6 +1233,80 @@ func (desc *TableDescriptor) validateTableIndexes(
 	return nil
 }
 
+// validateIndexType checks that the columns of the index can be encoded by
+// it: JSON columns can only be indexed by inverted indexes, which index a
+// single JSON column.
+func (desc *TableDescriptor) validateIndexType(index IndexDescriptor) error {
+	if index.Type == IndexDescriptor_INVERTED {
+		if index.ID == desc.PrimaryIndex.ID {
+			return fmt.Errorf("primary index \"%s\" cannot be inverted", index.Name)
+		}
+		if index.Unique {
+			return fmt.Errorf("inverted index \"%s\" cannot be unique", index.Name)
+		}
+		if len(index.StoreColumnNames) > 0 {
+			return fmt.Errorf("inverted index \"%s\" cannot store columns", index.Name)
+		}
+		if len(index.Interleave.Ancestors) > 0 {
+			return fmt.Errorf("inverted index \"%s\" cannot be interleaved", index.Name)
+		}
+		if len(index.ColumnIDs) != 1 {
+			return fmt.Errorf("inverted index \"%s\" must contain exactly one column", index.Name)
+		}
+		col, err := desc.FindColumnByID(index.ColumnIDs[0])
+		if err != nil {
+			return err
+		}
+		if col.Type.Kind != ColumnType_JSON {
+			return fmt.Errorf("column \"%s\" of type %s cannot be indexed by inverted index \"%s\"",
+				col.Name, col.Type.SQLString(), index.Name)
+		}
+		return nil
+	}
+	for _, colID := range index.ColumnIDs {
+		col, err := desc.FindColumnByID(colID)
+		if err != nil {
+			return err
+		}
+		if col.Type.Kind == ColumnType_JSON {
+			return fmt.Errorf("column \"%s\" of type %s can only be indexed by an inverted index",
+				col.Name, col.Type.SQLString())
+		}
+	}
+	return nil
+}
+
+// validateRowLevelTTL checks that the row-level TTL of the table, if any, is
+// keyed on a timestamp column and has a positive expiration.
+func (desc *TableDescriptor) validateRowLevelTTL() error {
+	ttl := desc.RowLevelTTL
+	if ttl == nil {
+		return nil
+	}
+	col, err := desc.FindActiveColumnByID(ttl.ColumnID)
+	if err != nil {
+		return errors.Wrap(err, "invalid row-level TTL column")
+	}
+	if k := col.Type.Kind; k != ColumnType_TIMESTAMP && k != ColumnType_TIMESTAMPTZ {
+		return fmt.Errorf("row-level TTL column \"%s\" of type %s is not a timestamp",
+			col.Name, col.Type.SQLString())
+	}
+	_, err = ttl.ExpireAfterDuration()
+	return err
+}
+
+// ExpireAfterDuration returns the interval after which the rows expire.
+func (ttl *RowLevelTTL) ExpireAfterDuration() (duration.Duration, error) {
+	d, err := parser.ParseDInterval(ttl.ExpireAfter)
+	if err != nil {
+		return duration.Duration{}, err
+	}
+	if d.Duration.Compare(duration.Duration{}) <= 0 {
+		return duration.Duration{}, fmt.Errorf("row-level TTL expiration %q must be positive", ttl.ExpireAfter)
+	}
+	return d.Duration, nil
+}
+
 // FamilyHeuristicTargetBytes is the target total byte size of columns that the
 // current heuristic will assign to a family.
 const FamilyHeuristicTargetBytes = 256
@@ -1220,7 +1326,8 @@ func upperBoundColumnValueEncodedSize(col ColumnDescriptor) (int, bool) {
 		typ = encoding.Float
 	case ColumnType_INTERVAL:
 		typ = encoding.Duration
-	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID:
+	case ColumnType_STRING, ColumnType_BYTES, ColumnType_COLLATEDSTRING, ColumnType_NAME, ColumnType_UUID,
+		ColumnType_JSON:
 		// STRINGs are counted as runes, so this isn't totally correct, but this
 		// seems better than always assuming the maximum rune width.
 		typ, size = encoding.Bytes, int(col.Type.Width)
@@ -1741,6 +1848,8 @@ func (c *ColumnType) SQLString() string {
 		return fmt.Sprintf("%s COLLATE %s", ColumnType_STRING.String(), *c.Locale)
 	case ColumnType_INT_ARRAY:
 		return "INT[]"
+	case ColumnType_JSON:
+		return "JSONB"
 	}
 	return c.Kind.String()
 }
@@ -1834,6 +1943,8 @@ func DatumTypeToColumnType(ptyp parser.Type) ColumnType {
 		ctyp.Kind = ColumnType_INTERVAL
 	case parser.TypeUUID:
 		ctyp.Kind = ColumnType_UUID
+	case parser.TypeJSON:
+		ctyp.Kind = ColumnType_JSON
 	case parser.TypeOid:
 		ctyp.Kind = ColumnType_OID
 	case parser.TypeNull:
@@ -1879,6 +1990,8 @@ func (c *ColumnType) ToDatumType() parser.Type {
 		return parser.TypeInterval
 	case ColumnType_UUID:
 		return parser.TypeUUID
+	case ColumnType_JSON:
+		return parser.TypeJSON
 	case ColumnType_COLLATEDSTRING:
 		if c.Locale == nil {
 			panic("locale is required for COLLATEDSTRING")
@@ -2024,5 +2137,13 @@ func (desc *ColumnDescriptor) SQLString() string {
 	if desc.DefaultExpr != nil {
 		fmt.Fprintf(&buf, " DEFAULT %s", *desc.DefaultExpr)
 	}
+	if desc.IsComputed() {
+		fmt.Fprintf(&buf, " AS (%s) STORED", *desc.ComputedExpr)
+	}
 	return buf.String()
 }
+
+// IsComputed returns whether this column is computed.
+func (desc *ColumnDescriptor) IsComputed() bool {
+	return desc.ComputedExpr != nil
+}
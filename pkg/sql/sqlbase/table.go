@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -129,6 +130,7 @@ func MakeColumnDefDescs(
 	case *parser.TimestampTZColType:
 	case *parser.IntervalColType:
 	case *parser.UUIDColType:
+	case *parser.JSONColType:
 	case *parser.StringColType:
 		col.Type.Width = int32(t.N)
 	case *parser.NameColType:
@@ -205,6 +207,17 @@ func MakeColumnDefDescs(
 		col.DefaultExpr = &s
 	}
 
+	if d.IsComputed() {
+		if col.DefaultExpr != nil {
+			return nil, nil, fmt.Errorf(
+				"computed column %q cannot also have a DEFAULT expression", col.Name)
+		}
+		// The expression can only be resolved and type checked once all the
+		// columns of the table are known, see ValidateComputedColumn.
+		s := parser.Serialize(d.Computed.Expr)
+		col.ComputedExpr = &s
+	}
+
 	var idx *IndexDescriptor
 	if d.PrimaryKey || d.Unique {
 		idx = &IndexDescriptor{
@@ -599,6 +612,8 @@ func EncodeTableValue(appendTo []byte, colID ColumnID, val parser.Datum) ([]byte
 		return encoding.EncodeDurationValue(appendTo, uint32(colID), t.Duration), nil
 	case *parser.DUuid:
 		return encoding.EncodeUUIDValue(appendTo, uint32(colID), t.UUID), nil
+	case *parser.DJSON:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(t.JSON.String())), nil
 	case *parser.DCollatedString:
 		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(t.Contents)), nil
 	case *parser.DOid:
@@ -1240,6 +1255,14 @@ func DecodeTableValue(a *DatumAlloc, valType parser.Type, b []byte) (parser.Datu
 		var u uuid.UUID
 		b, u, err = encoding.DecodeUUIDValue(b)
 		return a.NewDUuid(parser.DUuid{UUID: u}), b, err
+	case parser.TypeJSON:
+		var data []byte
+		b, data, err = encoding.DecodeBytesValue(b)
+		if err != nil {
+			return nil, b, err
+		}
+		d, err := parser.ParseDJSON(string(data))
+		return d, b, err
 
 	case parser.TypeOid:
 		var i int64
@@ -1277,12 +1300,32 @@ func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byID) Less(i, j int) bool { return a[i].id < a[j].id }
 
 // EncodeSecondaryIndex encodes key/values for a secondary index. colMap maps
-// ColumnIDs to indices in `values`.
+// ColumnIDs to indices in `values`. A forward index has exactly one entry per
+// row; an inverted index has one entry per path of the indexed JSON document,
+// and none if the document is NULL.
 func EncodeSecondaryIndex(
 	tableDesc *TableDescriptor,
 	secondaryIndex *IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []parser.Datum,
+) ([]IndexEntry, error) {
+	if secondaryIndex.Type == IndexDescriptor_INVERTED {
+		return encodeInvertedIndexEntries(tableDesc, secondaryIndex, colMap, values)
+	}
+	entry, err := encodeForwardIndexEntry(tableDesc, secondaryIndex, colMap, values)
+	if err != nil {
+		return nil, err
+	}
+	return []IndexEntry{entry}, nil
+}
+
+// encodeForwardIndexEntry encodes the key/value of a forward secondary index,
+// which has exactly one entry per row.
+func encodeForwardIndexEntry(
+	tableDesc *TableDescriptor,
+	secondaryIndex *IndexDescriptor,
+	colMap map[ColumnID]int,
+	values []parser.Datum,
 ) (IndexEntry, error) {
 	secondaryIndexKeyPrefix := MakeIndexKeyPrefix(tableDesc, secondaryIndex.ID)
 	secondaryIndexKey, containsNull, err := EncodeIndexKey(
@@ -1356,25 +1399,62 @@ func EncodeSecondaryIndex(
 	return entry, nil
 }
 
+// encodeInvertedIndexEntries encodes the key/values of an inverted index: the
+// key of each entry is made of one of the paths of the indexed JSON document
+// followed by the extra columns, and its value is empty.
+func encodeInvertedIndexEntries(
+	tableDesc *TableDescriptor,
+	index *IndexDescriptor,
+	colMap map[ColumnID]int,
+	values []parser.Datum,
+) ([]IndexEntry, error) {
+	var val parser.Datum = parser.DNull
+	if i, ok := colMap[index.ColumnIDs[0]]; ok {
+		val = values[i]
+	}
+	if val == parser.DNull {
+		return nil, nil
+	}
+	d, ok := parser.UnwrapDatum(val).(*parser.DJSON)
+	if !ok {
+		return nil, errors.Errorf("unable to encode inverted index key: %T", val)
+	}
+
+	extraKey, _, err := EncodeColumns(index.ExtraColumnIDs, nil, colMap, values, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPrefix := MakeIndexKeyPrefix(tableDesc, index.ID)
+	invertedKeys := json.EncodeInvertedIndexKeys(keyPrefix, d.JSON)
+	entries := make([]IndexEntry, len(invertedKeys))
+	for i, key := range invertedKeys {
+		entries[i].Key = keys.MakeRowSentinelKey(append(key, extraKey...))
+		// The zero value for an index-key is a 0-length bytes value.
+		entries[i].Value.SetBytes([]byte{})
+	}
+	return entries, nil
+}
+
 // EncodeSecondaryIndexes encodes key/values for the secondary indexes. colMap
-// maps ColumnIDs to indices in `values`. secondaryIndexEntries is the return
-// value (passed as a parameter so the caller can reuse between rows) and is
-// expected to be the same length as indexes.
+// maps ColumnIDs to indices in `values`. The entries are appended to
+// secondaryIndexEntries (passed as a parameter so the caller can reuse it
+// between rows), which is returned.
 func EncodeSecondaryIndexes(
 	tableDesc *TableDescriptor,
 	indexes []IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []parser.Datum,
 	secondaryIndexEntries []IndexEntry,
-) error {
+) ([]IndexEntry, error) {
 	for i := range indexes {
-		var err error
-		secondaryIndexEntries[i], err = EncodeSecondaryIndex(tableDesc, &indexes[i], colMap, values)
+		entries, err := EncodeSecondaryIndex(tableDesc, &indexes[i], colMap, values)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		secondaryIndexEntries = append(secondaryIndexEntries, entries...)
 	}
-	return nil
+	return secondaryIndexEntries, nil
 }
 
 // CheckColumnType verifies that a given value is compatible
@@ -1467,6 +1547,11 @@ func MarshalColumnValue(col ColumnDescriptor, val parser.Datum) (roachpb.Value,
 			r.SetBytes(v.GetBytes())
 			return r, nil
 		}
+	case ColumnType_JSON:
+		if v, ok := val.(*parser.DJSON); ok {
+			r.SetString(v.JSON.String())
+			return r, nil
+		}
 	case ColumnType_COLLATEDSTRING:
 		if col.Type.Locale == nil {
 			panic("locale is required for COLLATEDSTRING")
@@ -1579,6 +1664,12 @@ func UnmarshalColumnValue(
 			return nil, err
 		}
 		return a.NewDUuid(parser.DUuid{UUID: u}), nil
+	case ColumnType_JSON:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		return parser.ParseDJSON(string(v))
 	case ColumnType_NAME:
 		v, err := value.GetBytes()
 		if err != nil {
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -98,6 +99,13 @@ func RandDatum(rng *rand.Rand, typ ColumnType, null bool) parser.Datum {
 		}}
 	case ColumnType_UUID:
 		return parser.NewDUuid(parser.DUuid{UUID: uuid.MakeV4()})
+	case ColumnType_JSON:
+		j, err := json.ParseJSON(fmt.Sprintf(`{"a": %d, "b": [%t, "%d"]}`,
+			rng.Intn(100), rng.Intn(2) == 1, rng.Intn(100)))
+		if err != nil {
+			panic(err)
+		}
+		return parser.NewDJSON(j)
 	case ColumnType_STRING:
 		// Generate a random ASCII string.
 		p := make([]byte, rng.Intn(10))
@@ -155,6 +163,11 @@ var (
 
 func init() {
 	for k := range ColumnType_Kind_name {
+		// JSON values have no key encoding, so they can't be used as random
+		// EncDatums.
+		if ColumnType_Kind(k) == ColumnType_JSON {
+			continue
+		}
 		columnKinds = append(columnKinds, ColumnType_Kind(k))
 	}
 }
@@ -258,8 +258,9 @@ func (v *subqueryPlanVisitor) subqueryNode(ctx context.Context, sq *subquery) er
 }
 
 func (v *subqueryPlanVisitor) enterNode(_ context.Context, _ string, n planNode) bool {
-	if _, ok := n.(*explainPlanNode); ok {
-		// EXPLAIN doesn't start/substitute sub-queries.
+	if e, ok := n.(*explainPlanNode); ok && !e.explainer.analyze {
+		// EXPLAIN doesn't start/substitute sub-queries, unless it executes the
+		// plan for ANALYZE.
 		return false
 	}
 	return true
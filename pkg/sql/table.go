@@ -20,6 +20,7 @@ package sql
 import (
 	"bytes"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -158,6 +159,24 @@ func getViewDesc(
 	return desc, nil
 }
 
+// getSequenceDesc returns a table descriptor for a sequence, or nil if
+// the descriptor is not found.
+//
+// Returns an error if the underlying table descriptor actually
+// represents a table or view rather than a sequence.
+func getSequenceDesc(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *parser.TableName,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := getTableOrViewDesc(ctx, txn, vt, tn)
+	if err != nil {
+		return desc, err
+	}
+	if desc != nil && !desc.IsSequence() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn.String(), "sequence")
+	}
+	return desc, nil
+}
+
 // mustGetTableOrViewDesc returns a table descriptor for either a table or
 // view, or an error if the descriptor is not found. allowAdding when set allows
 // a table descriptor in the ADD state to also be returned.
@@ -218,6 +237,24 @@ func mustGetViewDesc(
 	return desc, nil
 }
 
+// mustGetSequenceDesc returns a table descriptor for a sequence, or an
+// error if the descriptor is not found or descriptor.Dropped().
+func mustGetSequenceDesc(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *parser.TableName,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := getSequenceDesc(ctx, txn, vt, tn)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, sqlbase.NewUndefinedSequenceError(tn.String())
+	}
+	if err := filterTableState(desc); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
 var errTableDropped = errors.New("table is being dropped")
 var errTableAdding = errors.New("table is being added")
 
@@ -446,11 +483,16 @@ func (p *planner) createSchemaChangeJob(
 	if err != nil {
 		return sqlbase.InvalidMutationID, err
 	}
+	var details jobs.SchemaChangeJobDetails
+	if startTime := p.session.SchemaChangeStartTime; !startTime.IsZero() {
+		// The schema change is scheduled to start later.
+		details.StartAfterMicros = startTime.UnixNano() / time.Microsecond.Nanoseconds()
+	}
 	jobRecord := jobs.JobRecord{
 		Description:   stmt,
 		Username:      p.User(),
 		DescriptorIDs: sqlbase.IDs{tableDesc.GetID()},
-		Details:       jobs.SchemaChangeJobDetails{},
+		Details:       details,
 	}
 	jobLogger := jobs.NewJobLogger(p.ExecCfg().DB, InternalExecutor{LeaseManager: p.session.leases.leaseMgr}, jobRecord)
 	if err := jobLogger.WithTxn(p.txn).Created(ctx); err != nil {
@@ -183,6 +183,10 @@ type tableUpsertEvaler interface {
 	// eval returns the values for the update case of an upsert, given the row
 	// that would have been inserted and the existing (conflicting) values.
 	eval(insertRow parser.Datums, existingRow parser.Datums) (parser.Datums, error)
+
+	// shouldUpdate returns true if the existing (conflicting) row must be
+	// updated, given the row that would have been inserted.
+	shouldUpdate(insertRow parser.Datums, existingRow parser.Datums) (bool, error)
 }
 
 // tableUpserter handles writing kvs and forming table rows for upserts.
@@ -352,6 +356,13 @@ func (tu *tableUpserter) flush(ctx context.Context, finalize bool) error {
 			// If len(tu.updateCols) == 0, then we're in the DO NOTHING case.
 			if len(tu.updateCols) > 0 {
 				existingValues := existingRow[:len(tu.ru.FetchCols)]
+				update, err := tu.evaler.shouldUpdate(insertRow, existingValues)
+				if err != nil {
+					return err
+				}
+				if !update {
+					continue
+				}
 				updateValues, err := tu.evaler.eval(insertRow, existingValues)
 				if err != nil {
 					return err
@@ -404,11 +415,14 @@ func (tu *tableUpserter) upsertRowPKs(ctx context.Context) ([]roachpb.Key, error
 	// others will be conflicting rows.
 	b := tu.txn.NewBatch()
 	for _, insertRow := range tu.insertRows {
-		entry, err := sqlbase.EncodeSecondaryIndex(
+		entries, err := sqlbase.EncodeSecondaryIndex(
 			tu.tableDesc, &tu.conflictIndex, tu.ri.InsertColIDtoRowIndex, insertRow)
 		if err != nil {
 			return nil, err
 		}
+		// The conflict index is unique, hence a forward index with a single
+		// entry per row.
+		entry := entries[0]
 		if log.V(2) {
 			log.Infof(ctx, "Get %s\n", entry.Key)
 		}
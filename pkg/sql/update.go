@@ -47,10 +47,11 @@ func (p *planner) makeEditNode(
 	if err != nil {
 		return editNodeBase{}, err
 	}
-	// We don't support update on views, only real tables.
+	// We don't support update on views or sequences, only real tables.
 	if !tableDesc.IsTable() {
 		return editNodeBase{},
-			errors.Errorf("cannot run %s on view %q - views are not updateable", priv, tn)
+			errors.Errorf("cannot run %s on %s %q - %ss are not updateable",
+				priv, tableDesc.TypeName(), tn, tableDesc.TypeName())
 	}
 
 	if err := p.CheckPrivilege(tableDesc, priv); err != nil {
@@ -135,6 +136,15 @@ type updateNode struct {
 	checkHelper   checkHelper
 	sourceSlots   []sourceSlot
 
+	// computedCols are the computed columns of the table, which are
+	// appended to updateCols and recomputed for every updated row with
+	// computeExprs. The expressions are evaluated over computedValues, whose
+	// current row is computedRow.
+	computedCols   []sqlbase.ColumnDescriptor
+	computeExprs   []parser.TypedExpr
+	computedValues *sqlbase.RowIndexedVarContainer
+	computedRow    parser.Datums
+
 	run struct {
 		// The following fields are populated during Start().
 		editNodeRun
@@ -255,14 +265,29 @@ func (p *planner) Update(
 	if err != nil {
 		return nil, err
 	}
+	for _, col := range updateCols {
+		if col.IsComputed() {
+			return nil, sqlbase.CannotWriteToComputedColError(col)
+		}
+	}
 
 	defaultExprs, err := sqlbase.MakeDefaultExprs(updateCols, &p.parser, &p.evalCtx)
 	if err != nil {
 		return nil, err
 	}
 
+	// The computed columns are recomputed for every updated row, as they may
+	// depend on the updated columns.
+	computedValues := &sqlbase.RowIndexedVarContainer{Cols: en.tableDesc.Columns}
+	updateCols, computedCols, computeExprs, err := sqlbase.ProcessComputedColumns(
+		updateCols, en.tableDesc, computedValues, p.session.SearchPath)
+	if err != nil {
+		return nil, err
+	}
+
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs || len(en.tableDesc.Checks) > 0 {
+	if _, retExprs := n.Returning.(*parser.ReturningExprs); retExprs ||
+		len(en.tableDesc.Checks) > 0 || len(computedCols) > 0 {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = en.tableDesc.Columns
@@ -277,6 +302,7 @@ func (p *planner) Update(
 		return nil, err
 	}
 	tw := tableUpdater{ru: ru, autoCommit: p.autoCommit}
+	computedValues.Mapping = ru.FetchColIDtoRowIndex
 
 	tracing.AnnotateTrace()
 
@@ -381,6 +407,14 @@ func (p *planner) Update(
 		updateColsIdx: updateColsIdx,
 		tw:            tw,
 		sourceSlots:   sourceSlots,
+
+		computedCols:   computedCols,
+		computeExprs:   computeExprs,
+		computedValues: computedValues,
+	}
+	if len(computedCols) > 0 {
+		un.computedRow = make(parser.Datums, len(ru.FetchCols))
+		computedValues.CurSourceRow = un.computedRow
 	}
 	if err := un.checkHelper.init(ctx, p, tn, en.tableDesc); err != nil {
 		return nil, err
@@ -435,6 +469,25 @@ func (u *updateNode) Next(ctx context.Context) (bool, error) {
 		}
 	}
 
+	if len(u.computeExprs) > 0 {
+		// Compute the values of the computed columns over the updated row.
+		// The computed columns come last in updateValues; as they cannot
+		// reference each other, they are not needed to build the updated row.
+		copy(u.computedRow, oldValues)
+		for i := 0; i < valueIdx; i++ {
+			if rowIdx, ok := u.tw.ru.FetchColIDtoRowIndex[u.tw.ru.UpdateCols[i].ID]; ok {
+				u.computedRow[rowIdx] = updateValues[i]
+			}
+		}
+		for i := range u.computedCols {
+			d, err := u.computeExprs[i].Eval(&u.p.evalCtx)
+			if err != nil {
+				return false, errors.Wrapf(err, "computed column %q", u.computedCols[i].Name)
+			}
+			updateValues[valueIdx+i] = d
+		}
+	}
+
 	if err := u.checkHelper.loadRow(u.tw.ru.FetchColIDtoRowIndex, oldValues, false); err != nil {
 		return false, err
 	}
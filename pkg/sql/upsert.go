@@ -23,6 +23,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
@@ -36,11 +37,19 @@ var upsertExcludedTable = parser.TableName{TableName: "excluded"}
 type upsertHelper struct {
 	p                  *planner
 	evalExprs          []parser.TypedExpr
+	whereExpr          parser.TypedExpr
 	sourceInfo         *dataSourceInfo
 	excludedSourceInfo *dataSourceInfo
 	curSourceRow       parser.Datums
 	curExcludedRow     parser.Datums
 
+	// excludedCols are the columns of the excluded table, which are all the
+	// columns of the table; the columns which are not in the rows being
+	// inserted are NULL. insertColIDtoRowIndex maps the IDs of the columns to
+	// their index in the rows being inserted.
+	excludedCols          []sqlbase.ColumnDescriptor
+	insertColIDtoRowIndex map[sqlbase.ColumnID]int
+
 	// This struct must be allocated on the heap and its location stay
 	// stable after construction because it implements
 	// IndexedVarContainer and the IndexedVar objects in sub-expressions
@@ -55,7 +64,11 @@ var _ tableUpsertEvaler = (*upsertHelper)(nil)
 func (uh *upsertHelper) IndexedVarEval(idx int, ctx *parser.EvalContext) (parser.Datum, error) {
 	numSourceColumns := len(uh.sourceInfo.sourceColumns)
 	if idx >= numSourceColumns {
-		return uh.curExcludedRow[idx-numSourceColumns].Eval(ctx)
+		rowIdx, ok := uh.insertColIDtoRowIndex[uh.excludedCols[idx-numSourceColumns].ID]
+		if !ok {
+			return parser.DNull, nil
+		}
+		return uh.curExcludedRow[rowIdx].Eval(ctx)
 	}
 	return uh.curSourceRow[idx].Eval(ctx)
 }
@@ -86,6 +99,7 @@ func (p *planner) makeUpsertHelper(
 	insertCols []sqlbase.ColumnDescriptor,
 	updateCols []sqlbase.ColumnDescriptor,
 	updateExprs parser.UpdateExprs,
+	where *parser.Where,
 	upsertConflictIndex *sqlbase.IndexDescriptor,
 ) (*upsertHelper, error) {
 	defaultExprs, err := sqlbase.MakeDefaultExprs(updateCols, &p.parser, &p.evalCtx)
@@ -115,13 +129,15 @@ func (p *planner) makeUpsertHelper(
 		*tn, sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
 	)
 	excludedSourceInfo := newSourceInfoForSingleTable(
-		upsertExcludedTable, sqlbase.ResultColumnsFromColDescs(insertCols),
+		upsertExcludedTable, sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
 	)
 
 	helper := &upsertHelper{
-		p:                  p,
-		sourceInfo:         sourceInfo,
-		excludedSourceInfo: excludedSourceInfo,
+		p:                     p,
+		sourceInfo:            sourceInfo,
+		excludedSourceInfo:    excludedSourceInfo,
+		excludedCols:          tableDesc.Columns,
+		insertColIDtoRowIndex: sqlbase.ColIDtoRowIndexFromCols(insertCols),
 	}
 
 	var evalExprs []parser.TypedExpr
@@ -136,6 +152,16 @@ func (p *planner) makeUpsertHelper(
 	}
 	helper.evalExprs = evalExprs
 
+	if where != nil {
+		whereExpr, err := p.analyzeExpr(
+			ctx, where.Expr, sources, ivarHelper, parser.TypeBool, true, "WHERE",
+		)
+		if err != nil {
+			return nil, err
+		}
+		helper.whereExpr = whereExpr
+	}
+
 	return helper, nil
 }
 
@@ -143,6 +169,9 @@ func (uh *upsertHelper) walkExprs(walk func(desc string, index int, expr parser.
 	for i, evalExpr := range uh.evalExprs {
 		walk("eval", i, evalExpr)
 	}
+	if uh.whereExpr != nil {
+		walk("where", 0, uh.whereExpr)
+	}
 }
 
 // eval returns the values for the update case of an upsert, given the row
@@ -164,6 +193,20 @@ func (uh *upsertHelper) eval(
 	return ret, nil
 }
 
+// shouldUpdate returns true if the conflicting existing row must be updated,
+// that is if the WHERE clause of the ON CONFLICT DO UPDATE, if any, is
+// satisfied.
+func (uh *upsertHelper) shouldUpdate(
+	insertRow parser.Datums, existingRow parser.Datums,
+) (bool, error) {
+	if uh.whereExpr == nil {
+		return true, nil
+	}
+	uh.curSourceRow = existingRow
+	uh.curExcludedRow = insertRow
+	return sqlbase.RunFilter(uh.whereExpr, &uh.p.evalCtx)
+}
+
 // upsertExprsAndIndex returns the upsert conflict index and the (possibly
 // synthetic) SET expressions used when a row conflicts.
 func upsertExprsAndIndex(
@@ -198,15 +241,40 @@ func upsertExprsAndIndex(
 		return updateExprs, conflictIndex, nil
 	}
 
+	if onConflict.Constraint != "" {
+		// ON CONFLICT ON CONSTRAINT uses the unique index with the given name
+		// as the conflict index.
+		normName := onConflict.Constraint.Normalize()
+		if parser.ReNormalizeName(tableDesc.PrimaryIndex.Name) == normName {
+			return onConflict.Exprs, &tableDesc.PrimaryIndex, nil
+		}
+		for _, index := range tableDesc.Indexes {
+			if parser.ReNormalizeName(index.Name) == normName {
+				if !index.Unique {
+					return nil, nil, fmt.Errorf("constraint in ON CONFLICT clause has no associated index")
+				}
+				return onConflict.Exprs, &index, nil
+			}
+		}
+		return nil, nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"constraint %q for table %q does not exist", string(onConflict.Constraint), tableDesc.Name)
+	}
+
+	// Like in postgres, the conflict index is any unique index whose columns
+	// are exactly the columns of the conflict target, in any order.
+	targetCols := make(map[string]struct{}, len(onConflict.Columns))
+	for _, name := range onConflict.Columns {
+		targetCols[name.Normalize()] = struct{}{}
+	}
 	indexMatch := func(index sqlbase.IndexDescriptor) bool {
 		if !index.Unique {
 			return false
 		}
-		if len(index.ColumnNames) != len(onConflict.Columns) {
+		if len(index.ColumnNames) != len(targetCols) {
 			return false
 		}
-		for i, colName := range index.ColumnNames {
-			if parser.ReNormalizeName(colName) != onConflict.Columns[i].Normalize() {
+		for _, colName := range index.ColumnNames {
+			if _, ok := targetCols[parser.ReNormalizeName(colName)]; !ok {
 				return false
 			}
 		}
@@ -0,0 +1,200 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/mon"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// The limits below protect a node from the sessions of a single user, e.g.
+// from a runaway join exhausting the SQL memory of the node. They apply
+// to each node separately and don't apply to the root user, so that an
+// administrator can always connect to investigate and cancel queries.
+
+var sessionMaxMemory = settings.RegisterByteSizeSetting(
+	"sql.session.max_memory",
+	"maximum memory used by a SQL session, excluding the sessions of root (0 for no limit)",
+	0,
+)
+
+var userMaxMemory = settings.RegisterByteSizeSetting(
+	"sql.user.max_memory",
+	"maximum memory used by all the SQL sessions of a user on a node, excluding root (0 for no limit)",
+	0,
+)
+
+var userMaxConcurrentStatements = settings.RegisterValidatedIntSetting(
+	"sql.user.max_concurrent_statements",
+	"maximum number of statements of a user running concurrently on a node, excluding root (0 for no limit)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.user.max_concurrent_statements to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+var userStatementQueueTimeout = settings.RegisterNonNegativeDurationSetting(
+	"sql.user.statement_queue_timeout",
+	"maximum time a statement waits when sql.user.max_concurrent_statements is reached "+
+		"before being rejected (0 to reject it right away)",
+	0,
+)
+
+// hasUserLimits returns whether the resource limits apply to the sessions of
+// a user.
+func hasUserLimits(user string) bool {
+	return user != security.RootUser && user != security.NodeUser
+}
+
+// userLimits holds, for each user with open sessions on the current node, the
+// resources shared by these sessions.
+type userLimits struct {
+	syncutil.Mutex
+	users map[string]*userResources
+}
+
+// userResources tracks the memory and the statements of the sessions of a
+// user, to enforce sql.user.max_memory and sql.user.max_concurrent_statements.
+type userResources struct {
+	user string
+	// sessions is the number of open sessions of the user. It is protected by
+	// the mutex of userLimits.
+	sessions int
+	// mon is the parent of the root monitors of the sessions of the user.
+	mon mon.MemoryMonitor
+
+	mu struct {
+		syncutil.Mutex
+		// running is the number of statements holding a slot.
+		running int64
+		// waiters are the statements waiting for a slot, in order of
+		// arrival. A slot is handed to a waiter by closing its channel.
+		waiters []chan struct{}
+	}
+}
+
+// acquire registers a new session of the user. The first session of a user
+// starts the memory monitor of the user, using pool as its pool.
+func (ul *userLimits) acquire(
+	ctx context.Context, user string, pool *mon.MemoryMonitor,
+) *userResources {
+	ul.Lock()
+	defer ul.Unlock()
+	if ul.users == nil {
+		ul.users = make(map[string]*userResources)
+	}
+	ur, ok := ul.users[user]
+	if !ok {
+		ur = &userResources{user: user}
+		ur.mon = mon.MakeMonitorWithLimit(fmt.Sprintf("user %s", user),
+			0 /* limit */, nil, nil, -1, math.MaxInt64)
+		ur.mon.Start(ctx, pool, mon.BoundAccount{})
+		ul.users[user] = ur
+	}
+	// The setting may have changed since the monitor was started.
+	ur.mon.SetLimit(userMaxMemory.Get())
+	ur.sessions++
+	return ur
+}
+
+// release unregisters a session of the user, whose memory monitors must have
+// been stopped.
+func (ul *userLimits) release(ctx context.Context, ur *userResources) {
+	ul.Lock()
+	defer ul.Unlock()
+	ur.sessions--
+	if ur.sessions == 0 {
+		ur.mon.Stop(ctx)
+		delete(ul.users, ur.user)
+	}
+}
+
+// acquireStatementSlot waits until the user has less than
+// sql.user.max_concurrent_statements statements running, for at most
+// sql.user.statement_queue_timeout. The slot must be released with
+// releaseStatementSlot once the statement is done.
+func (ur *userResources) acquireStatementSlot(ctx context.Context) error {
+	limit := userMaxConcurrentStatements.Get()
+	ur.mu.Lock()
+	if limit == 0 || ur.mu.running < limit {
+		ur.mu.running++
+		ur.mu.Unlock()
+		return nil
+	}
+	timeout := userStatementQueueTimeout.Get()
+	if timeout == 0 {
+		ur.mu.Unlock()
+		return ur.tooManyStatementsError(limit)
+	}
+	granted := make(chan struct{})
+	ur.mu.waiters = append(ur.mu.waiters, granted)
+	ur.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-granted:
+		return nil
+	case <-timer.C:
+		err = ur.tooManyStatementsError(limit)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	for i, w := range ur.mu.waiters {
+		if w == granted {
+			ur.mu.waiters = append(ur.mu.waiters[:i], ur.mu.waiters[i+1:]...)
+			return err
+		}
+	}
+	// The slot was handed to us after we stopped waiting; keep it.
+	return nil
+}
+
+// releaseStatementSlot releases a slot obtained with acquireStatementSlot,
+// handing it to the first waiting statement if any.
+func (ur *userResources) releaseStatementSlot() {
+	limit := userMaxConcurrentStatements.Get()
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if len(ur.mu.waiters) > 0 && (limit == 0 || ur.mu.running <= limit) {
+		close(ur.mu.waiters[0])
+		ur.mu.waiters = ur.mu.waiters[1:]
+		return
+	}
+	ur.mu.running--
+}
+
+func (ur *userResources) tooManyStatementsError(limit int64) error {
+	return pgerror.NewErrorf(pgerror.CodeConfigurationLimitExceededError,
+		"user %s already has %d statements running on this node "+
+			"(see sql.user.max_concurrent_statements)", ur.user, limit)
+}
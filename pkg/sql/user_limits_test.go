@@ -0,0 +1,141 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	gosql "database/sql"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestUserStatementSlots(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer settings.TestingSetInt(&userMaxConcurrentStatements, 1)()
+
+	ctx := context.Background()
+	ur := &userResources{user: "testuser"}
+	if err := ur.acquireStatementSlot(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a queue timeout, the second statement is rejected right away.
+	err := ur.acquireStatementSlot(ctx)
+	if pgErr, ok := pgerror.GetPGCause(err); !ok ||
+		pgErr.Code != pgerror.CodeConfigurationLimitExceededError {
+		t.Fatalf("expected the statement to be rejected, got %v", err)
+	}
+
+	// With a short timeout, it is rejected once the timeout expires.
+	restoreTimeout := settings.TestingSetDuration(&userStatementQueueTimeout, time.Millisecond)
+	if err := ur.acquireStatementSlot(ctx); err == nil {
+		t.Fatal("expected the statement to be rejected after waiting")
+	}
+	restoreTimeout()
+	if len(ur.mu.waiters) != 0 {
+		t.Fatalf("expected the rejected statement to stop waiting, got %d waiters", len(ur.mu.waiters))
+	}
+
+	// With a long timeout, it gets the slot of the first statement once it is
+	// released.
+	defer settings.TestingSetDuration(&userStatementQueueTimeout, time.Minute)()
+	errCh := make(chan error)
+	go func() {
+		errCh <- ur.acquireStatementSlot(ctx)
+	}()
+	for {
+		ur.mu.Lock()
+		waiting := len(ur.mu.waiters)
+		ur.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	ur.releaseStatementSlot()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if ur.mu.running != 1 {
+		t.Fatalf("expected the slot to be handed over, got %d running statements", ur.mu.running)
+	}
+	ur.releaseStatementSlot()
+	if ur.mu.running != 0 {
+		t.Fatalf("expected no running statements, got %d", ur.mu.running)
+	}
+}
+
+func TestUserMemoryLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, rootDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if err := createTableWithLongStrings(rootDB); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rootDB.Exec(`
+CREATE USER testuser;
+GRANT SELECT ON TABLE d.t TO testuser
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingAddr(), "TestUserMemoryLimit", url.User("testuser"))
+	defer cleanup()
+	userDB, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer userDB.Close()
+
+	const statement = `SELECT LENGTH(CONCAT_AGG(a)) FROM d.t`
+	if _, err := userDB.Exec(statement); err != nil {
+		t.Fatalf("expected the statement to succeed without limits, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		setting **settings.ByteSizeSetting
+	}{
+		{"sql.session.max_memory", &sessionMaxMemory},
+		{"sql.user.max_memory", &userMaxMemory},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer settings.TestingSetByteSize(tc.setting, lowMemoryBudget)()
+			// The limits are read when the sessions start.
+			userDB.SetMaxIdleConns(0)
+
+			if _, err := userDB.Exec(statement); err == nil ||
+				err.(*pq.Error).Code != pgerror.CodeOutOfMemoryError {
+				t.Fatalf("expected %q to exceed the memory limit, got %v", statement, err)
+			}
+			// The limits don't apply to root.
+			if _, err := rootDB.Exec(statement); err != nil {
+				t.Fatalf("expected the statement of root to succeed, got %v", err)
+			}
+		})
+	}
+}
@@ -145,6 +145,22 @@ var varGen = map[string]sessionVar{
 			return nil
 		},
 	},
+	`idle_in_transaction_session_timeout`: {
+		// See https://www.postgresql.org/docs/9.6/static/runtime-config-client.html#GUC-IDLE-IN-TRANSACTION-SESSION-TIMEOUT
+		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
+			timeout, err := p.getTimeoutVal(`idle_in_transaction_session_timeout`, values)
+			if err != nil {
+				return err
+			}
+			p.session.IdleInTransactionSessionTimeout = timeout
+			return nil
+		},
+		Get: func(p *planner) string { return p.session.IdleInTransactionSessionTimeout.String() },
+		Reset: func(p *planner) error {
+			p.session.IdleInTransactionSessionTimeout = 0
+			return nil
+		},
+	},
 	`search_path`: {
 		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
 			// https://www.postgresql.org/docs/9.6/static/runtime-config-client.html
@@ -182,6 +198,36 @@ var varGen = map[string]sessionVar{
 			return nil
 		},
 	},
+	`schema_change_start_time`: {
+		// The schema changes of the session are scheduled to begin at this
+		// time, e.g. to run their backfills outside of peak hours.
+		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
+			s, err := p.getStringVal(`schema_change_start_time`, values)
+			if err != nil {
+				return err
+			}
+			if s == "" {
+				p.session.SchemaChangeStartTime = time.Time{}
+				return nil
+			}
+			d, err := parser.ParseDTimestampTZ(s, p.session.Location, time.Microsecond)
+			if err != nil {
+				return errors.Wrap(err, "set schema_change_start_time")
+			}
+			p.session.SchemaChangeStartTime = d.Time
+			return nil
+		},
+		Get: func(p *planner) string {
+			if p.session.SchemaChangeStartTime.IsZero() {
+				return ""
+			}
+			return p.session.SchemaChangeStartTime.UTC().Format(parser.TimestampOutputFormat)
+		},
+		Reset: func(p *planner) error {
+			p.session.SchemaChangeStartTime = time.Time{}
+			return nil
+		},
+	},
 	`standard_conforming_strings`: {
 		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
 			// If true, escape backslash literals in strings. We do this by default,
@@ -200,6 +246,50 @@ var varGen = map[string]sessionVar{
 		Get:   func(*planner) string { return "on" },
 		Reset: func(*planner) error { return nil },
 	},
+	`statement_timeout`: {
+		// See https://www.postgresql.org/docs/9.6/static/runtime-config-client.html#GUC-STATEMENT-TIMEOUT
+		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
+			timeout, err := p.getTimeoutVal(`statement_timeout`, values)
+			if err != nil {
+				return err
+			}
+			p.session.StatementTimeout = timeout
+			return nil
+		},
+		Get: func(p *planner) string { return p.session.StatementTimeout.String() },
+		Reset: func(p *planner) error {
+			p.session.StatementTimeout = 0
+			return nil
+		},
+	},
+	`vectorize`: {
+		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
+			s, err := p.getStringVal(`vectorize`, values)
+			if err != nil {
+				return err
+			}
+			switch parser.Name(s).Normalize() {
+			case parser.ReNormalizeName("off"):
+				p.session.Vectorize = false
+			case parser.ReNormalizeName("on"):
+				p.session.Vectorize = true
+			default:
+				return fmt.Errorf("set vectorize: \"%s\" not supported", s)
+			}
+
+			return nil
+		},
+		Get: func(p *planner) string {
+			if p.session.Vectorize {
+				return "on"
+			}
+			return "off"
+		},
+		Reset: func(p *planner) error {
+			p.session.Vectorize = false
+			return nil
+		},
+	},
 	`application_name`: {
 		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
 			// Set by clients to improve query logging.
@@ -285,6 +375,34 @@ var varGen = map[string]sessionVar{
 		Reset: func(*planner) error { return nil },
 	},
 
+	`capture_logs`: {
+		Get: func(p *planner) string {
+			if p.session.logCapture.Started() {
+				return "on"
+			}
+			return "off"
+		},
+		Reset: func(p *planner) error {
+			p.session.logCapture.Stop()
+			return nil
+		},
+		Set: func(_ context.Context, p *planner, values []parser.TypedExpr) error {
+			s, err := p.getStringVal("capture_logs", values)
+			if err != nil {
+				return err
+			}
+			switch parser.Name(s).Normalize() {
+			case parser.ReNormalizeName("on"):
+				p.session.logCapture.Start()
+			case parser.ReNormalizeName("off"):
+				p.session.logCapture.Stop()
+			default:
+				return fmt.Errorf("set capture_logs: \"%s\" not supported", s)
+			}
+			return nil
+		},
+	},
+
 	`trace`: {
 		Get: func(p *planner) string {
 			if p.session.Tracing.Enabled() {
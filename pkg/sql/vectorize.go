@@ -0,0 +1,665 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cockroachdb/apd"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+// The vectorized execution engine is an alternative to the row-at-a-time
+// execution of planNodes for simple aggregations, enabled with the vectorize
+// session variable:
+//
+//   SELECT <aggregations> FROM <table> [WHERE <conditions>]
+//
+// Instead of pulling the rows one at a time through a renderNode and having
+// the filter and the aggregate functions process Datums through interface
+// calls, the rows decoded by the scan are copied into batches of typed
+// columns. The filter and the aggregations then process a whole batch at a
+// time using loops over the columns.
+//
+// Only the aggregations without GROUP BY, DISTINCT or FILTER are supported:
+// COUNT of any column or of all the rows, and SUM, AVG, MIN and MAX of INT and
+// FLOAT columns. The filter must be a conjunction of comparisons between INT
+// or FLOAT columns and constants of the same type, or of IS NOT NULL
+// conditions. The other plans run as usual.
+
+// vecBatchSize is the maximum number of rows in a batch.
+const vecBatchSize = 1024
+
+// vecKind is the type of the values stored in a vecColumn.
+type vecKind int
+
+const (
+	// vecNullsOnly columns only record which values are NULL; this suffices
+	// for COUNT and IS NOT NULL.
+	vecNullsOnly vecKind = iota
+	vecInt
+	vecFloat
+)
+
+// vecColumn is a column of a batch of rows.
+type vecColumn struct {
+	kind   vecKind
+	ints   []int64
+	floats []float64
+	nulls  []bool
+}
+
+func newVecColumn(typ parser.Type) *vecColumn {
+	c := &vecColumn{nulls: make([]bool, vecBatchSize)}
+	switch typ {
+	case parser.TypeInt:
+		c.kind = vecInt
+		c.ints = make([]int64, vecBatchSize)
+	case parser.TypeFloat:
+		c.kind = vecFloat
+		c.floats = make([]float64, vecBatchSize)
+	}
+	return c
+}
+
+// set stores a value at the given position of the column.
+func (c *vecColumn) set(i int, d parser.Datum) {
+	if d == parser.DNull {
+		c.nulls[i] = true
+		return
+	}
+	c.nulls[i] = false
+	switch c.kind {
+	case vecInt:
+		c.ints[i] = int64(parser.MustBeDInt(d))
+	case vecFloat:
+		c.floats[i] = float64(*parser.UnwrapDatum(d).(*parser.DFloat))
+	}
+}
+
+// vecBatch is a batch of rows, stored by column.
+type vecBatch struct {
+	// cols contains a column for each column of the scan, or nil if the
+	// column isn't used.
+	cols []*vecColumn
+	// length is the number of rows in the batch.
+	length int
+	// sel lists the positions of the rows which passed the filter.
+	sel []int
+}
+
+// vecFilter removes the rows of a batch which don't satisfy a condition from
+// its selection.
+type vecFilter interface {
+	filter(b *vecBatch)
+}
+
+// vecNotNull is the IS NOT NULL condition on a column.
+type vecNotNull struct {
+	col int
+}
+
+func (f *vecNotNull) filter(b *vecBatch) {
+	nulls := b.cols[f.col].nulls
+	sel := b.sel[:0]
+	for _, i := range b.sel {
+		if !nulls[i] {
+			sel = append(sel, i)
+		}
+	}
+	b.sel = sel
+}
+
+// vecIntCmp compares an INT column to a constant.
+type vecIntCmp struct {
+	col int
+	op  parser.ComparisonOperator
+	val int64
+}
+
+func (f *vecIntCmp) filter(b *vecBatch) {
+	c := b.cols[f.col]
+	sel := b.sel[:0]
+	for _, i := range b.sel {
+		if !c.nulls[i] && cmpSatisfies(f.op, compareInts(c.ints[i], f.val)) {
+			sel = append(sel, i)
+		}
+	}
+	b.sel = sel
+}
+
+// vecFloatCmp compares a FLOAT column to a constant.
+type vecFloatCmp struct {
+	col int
+	op  parser.ComparisonOperator
+	val float64
+}
+
+func (f *vecFloatCmp) filter(b *vecBatch) {
+	c := b.cols[f.col]
+	sel := b.sel[:0]
+	for _, i := range b.sel {
+		if !c.nulls[i] && cmpSatisfies(f.op, compareFloats(c.floats[i], f.val)) {
+			sel = append(sel, i)
+		}
+	}
+	b.sel = sel
+}
+
+func compareInts(a, b int64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareFloats compares two floats like parser.DFloat.Compare, which sorts
+// NaN before the other values.
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	if a == b {
+		return 0
+	}
+	if math.IsNaN(a) {
+		if math.IsNaN(b) {
+			return 0
+		}
+		return -1
+	}
+	return 1
+}
+
+// cmpSatisfies returns whether the result of a comparison satisfies the
+// operator.
+func cmpSatisfies(op parser.ComparisonOperator, cmp int) bool {
+	switch op {
+	case parser.EQ:
+		return cmp == 0
+	case parser.NE:
+		return cmp != 0
+	case parser.LT:
+		return cmp < 0
+	case parser.LE:
+		return cmp <= 0
+	case parser.GT:
+		return cmp > 0
+	case parser.GE:
+		return cmp >= 0
+	default:
+		panic("unsupported comparison operator " + op.String())
+	}
+}
+
+// vecAggregate accumulates the selected values of each batch. Its result
+// matches the one of the corresponding parser.AggregateFunc.
+type vecAggregate interface {
+	add(b *vecBatch) error
+	result() (parser.Datum, error)
+}
+
+// vecCountRows is COUNT(*).
+type vecCountRows struct {
+	count int64
+}
+
+func (a *vecCountRows) add(b *vecBatch) error {
+	a.count += int64(len(b.sel))
+	return nil
+}
+
+func (a *vecCountRows) result() (parser.Datum, error) {
+	return parser.NewDInt(parser.DInt(a.count)), nil
+}
+
+// vecCount is COUNT of a column.
+type vecCount struct {
+	col   int
+	count int64
+}
+
+func (a *vecCount) add(b *vecBatch) error {
+	nulls := b.cols[a.col].nulls
+	for _, i := range b.sel {
+		if !nulls[i] {
+			a.count++
+		}
+	}
+	return nil
+}
+
+func (a *vecCount) result() (parser.Datum, error) {
+	return parser.NewDInt(parser.DInt(a.count)), nil
+}
+
+// vecIntSum is SUM of an INT column. Like the row-at-a-time aggregate, it
+// switches to a decimal sum if the sum overflows.
+type vecIntSum struct {
+	col         int
+	intSum      int64
+	decSum      apd.Decimal
+	tmpDec      apd.Decimal
+	large       bool
+	seenNonNull bool
+	// count is the number of values added, for AVG.
+	count int64
+}
+
+func (a *vecIntSum) add(b *vecBatch) error {
+	c := b.cols[a.col]
+	for _, i := range b.sel {
+		if c.nulls[i] {
+			continue
+		}
+		a.seenNonNull = true
+		a.count++
+		t := c.ints[i]
+		if !a.large &&
+			((t < 0 && a.intSum < math.MinInt64-t) ||
+				(t > 0 && a.intSum > math.MaxInt64-t)) {
+			a.large = true
+			a.decSum.SetCoefficient(a.intSum)
+		}
+		if a.large {
+			a.tmpDec.SetCoefficient(t)
+			if _, err := parser.ExactCtx.Add(&a.decSum, &a.decSum, &a.tmpDec); err != nil {
+				return err
+			}
+		} else {
+			a.intSum += t
+		}
+	}
+	return nil
+}
+
+func (a *vecIntSum) result() (parser.Datum, error) {
+	if !a.seenNonNull {
+		return parser.DNull, nil
+	}
+	dd := &parser.DDecimal{}
+	if a.large {
+		dd.Set(&a.decSum)
+	} else {
+		dd.SetCoefficient(a.intSum)
+	}
+	return dd, nil
+}
+
+// vecFloatSum is SUM of a FLOAT column.
+type vecFloatSum struct {
+	col         int
+	sum         float64
+	seenNonNull bool
+	// count is the number of values added, for AVG.
+	count int64
+}
+
+func (a *vecFloatSum) add(b *vecBatch) error {
+	c := b.cols[a.col]
+	for _, i := range b.sel {
+		if !c.nulls[i] {
+			a.sum += c.floats[i]
+			a.seenNonNull = true
+			a.count++
+		}
+	}
+	return nil
+}
+
+func (a *vecFloatSum) result() (parser.Datum, error) {
+	if !a.seenNonNull {
+		return parser.DNull, nil
+	}
+	return parser.NewDFloat(parser.DFloat(a.sum)), nil
+}
+
+// vecIntAvg is AVG of an INT column.
+type vecIntAvg struct {
+	vecIntSum
+}
+
+func (a *vecIntAvg) result() (parser.Datum, error) {
+	sum, err := a.vecIntSum.result()
+	if err != nil || sum == parser.DNull {
+		return sum, err
+	}
+	dd := sum.(*parser.DDecimal)
+	_, err = parser.DecimalCtx.Quo(&dd.Decimal, &dd.Decimal, apd.New(a.count, 0))
+	return dd, err
+}
+
+// vecFloatAvg is AVG of a FLOAT column.
+type vecFloatAvg struct {
+	vecFloatSum
+}
+
+func (a *vecFloatAvg) result() (parser.Datum, error) {
+	if !a.seenNonNull {
+		return parser.DNull, nil
+	}
+	return parser.NewDFloat(parser.DFloat(a.sum) / parser.DFloat(a.count)), nil
+}
+
+// vecIntMinMax is MIN or MAX of an INT column.
+type vecIntMinMax struct {
+	col  int
+	max  bool
+	val  int64
+	seen bool
+}
+
+func (a *vecIntMinMax) add(b *vecBatch) error {
+	c := b.cols[a.col]
+	for _, i := range b.sel {
+		if c.nulls[i] {
+			continue
+		}
+		if v := c.ints[i]; !a.seen || (a.max && v > a.val) || (!a.max && v < a.val) {
+			a.val = v
+			a.seen = true
+		}
+	}
+	return nil
+}
+
+func (a *vecIntMinMax) result() (parser.Datum, error) {
+	if !a.seen {
+		return parser.DNull, nil
+	}
+	return parser.NewDInt(parser.DInt(a.val)), nil
+}
+
+// vecFloatMinMax is MIN or MAX of a FLOAT column.
+type vecFloatMinMax struct {
+	col  int
+	max  bool
+	val  float64
+	seen bool
+}
+
+func (a *vecFloatMinMax) add(b *vecBatch) error {
+	c := b.cols[a.col]
+	for _, i := range b.sel {
+		if c.nulls[i] {
+			continue
+		}
+		v := c.floats[i]
+		if !a.seen {
+			a.val = v
+			a.seen = true
+			continue
+		}
+		if cmp := compareFloats(v, a.val); (a.max && cmp > 0) || (!a.max && cmp < 0) {
+			a.val = v
+		}
+	}
+	return nil
+}
+
+func (a *vecFloatMinMax) result() (parser.Datum, error) {
+	if !a.seen {
+		return parser.DNull, nil
+	}
+	return parser.NewDFloat(parser.DFloat(a.val)), nil
+}
+
+// vecAggregation is the vectorized execution of a groupNode, which reads the
+// rows directly from the scanNode underneath.
+type vecAggregation struct {
+	scan    *scanNode
+	filters []vecFilter
+	aggs    []vecAggregate
+	batch   vecBatch
+}
+
+// vectorizeGroup returns the vectorized execution of a groupNode, or nil if
+// the aggregation or its source are not supported.
+func vectorizeGroup(n *groupNode) *vecAggregation {
+	if n.numGroupCols > 0 || n.needOnlyOneRow {
+		// The aggregation already only needs to read a single row.
+		return nil
+	}
+	// The arguments of the aggregate functions are either rendered by a
+	// renderNode over the scan, or are the columns of the scan itself.
+	var scan *scanNode
+	var renders []parser.TypedExpr
+	switch t := n.plan.(type) {
+	case *renderNode:
+		s, ok := t.source.plan.(*scanNode)
+		if !ok {
+			return nil
+		}
+		scan, renders = s, t.render
+	case *scanNode:
+		scan = t
+	default:
+		return nil
+	}
+
+	v := &vecAggregation{
+		scan:  scan,
+		batch: vecBatch{cols: make([]*vecColumn, len(scan.resultColumns))},
+	}
+	for _, f := range n.funcs {
+		fn, ok := f.expr.(*parser.FuncExpr)
+		if !ok || f.identAggregate || f.hasFilter || f.seen != nil {
+			return nil
+		}
+		col := f.argRenderIdx
+		if renders != nil {
+			switch r := renders[f.argRenderIdx].(type) {
+			case *parser.IndexedVar:
+				col = r.Idx
+			case *parser.StarDatum:
+				col = -1
+			default:
+				return nil
+			}
+		}
+		agg := v.makeAggregate(strings.ToLower(fn.Func.FunctionReference.String()), col)
+		if agg == nil {
+			return nil
+		}
+		v.aggs = append(v.aggs, agg)
+	}
+	if !isFilterTrue(scan.filter) && !v.addFilters(scan.filter) {
+		return nil
+	}
+	return v
+}
+
+// column returns the batch column of a column of the scan.
+func (v *vecAggregation) column(col int) *vecColumn {
+	if v.batch.cols[col] == nil {
+		v.batch.cols[col] = newVecColumn(v.scan.resultColumns[col].Typ)
+	}
+	return v.batch.cols[col]
+}
+
+// makeAggregate returns the vectorized aggregate function with the given
+// name over a column of the scan (-1 for COUNT(*)), or nil if it is not
+// supported.
+func (v *vecAggregation) makeAggregate(name string, col int) vecAggregate {
+	if col < 0 {
+		if name == "count" {
+			return &vecCountRows{}
+		}
+		return nil
+	}
+	kind := v.column(col).kind
+	switch {
+	case name == "count":
+		return &vecCount{col: col}
+	case name == "sum" && kind == vecInt:
+		return &vecIntSum{col: col}
+	case name == "sum" && kind == vecFloat:
+		return &vecFloatSum{col: col}
+	case name == "avg" && kind == vecInt:
+		return &vecIntAvg{vecIntSum{col: col}}
+	case name == "avg" && kind == vecFloat:
+		return &vecFloatAvg{vecFloatSum{col: col}}
+	case (name == "min" || name == "max") && kind == vecInt:
+		return &vecIntMinMax{col: col, max: name == "max"}
+	case (name == "min" || name == "max") && kind == vecFloat:
+		return &vecFloatMinMax{col: col, max: name == "max"}
+	}
+	return nil
+}
+
+// flippedCmpOps contains the comparison operators which can be flipped to
+// put the column on the left hand side.
+var flippedCmpOps = map[parser.ComparisonOperator]parser.ComparisonOperator{
+	parser.EQ: parser.EQ,
+	parser.NE: parser.NE,
+	parser.LT: parser.GT,
+	parser.LE: parser.GE,
+	parser.GT: parser.LT,
+	parser.GE: parser.LE,
+}
+
+// addFilters adds the filters for the conditions of the scan's filter, and
+// returns false if they are not supported.
+func (v *vecAggregation) addFilters(expr parser.TypedExpr) bool {
+	switch t := expr.(type) {
+	case *parser.AndExpr:
+		return v.addFilters(t.TypedLeft()) && v.addFilters(t.TypedRight())
+
+	case *parser.ComparisonExpr:
+		op := t.Operator
+		ivar, isVar := t.Left.(*parser.IndexedVar)
+		d, isDatum := t.Right.(parser.Datum)
+		if !isVar {
+			// Put the column on the left hand side.
+			flipped, ok := flippedCmpOps[op]
+			if !ok {
+				return false
+			}
+			op = flipped
+			ivar, isVar = t.Right.(*parser.IndexedVar)
+			d, isDatum = t.Left.(parser.Datum)
+		}
+		if !isVar || !isDatum {
+			return false
+		}
+		if op == parser.IsNot && d == parser.DNull {
+			v.column(ivar.Idx)
+			v.filters = append(v.filters, &vecNotNull{col: ivar.Idx})
+			return true
+		}
+		if _, ok := flippedCmpOps[op]; !ok {
+			return false
+		}
+		kind := v.column(ivar.Idx).kind
+		switch c := d.(type) {
+		case *parser.DInt:
+			if kind == vecInt {
+				v.filters = append(v.filters, &vecIntCmp{col: ivar.Idx, op: op, val: int64(*c)})
+				return true
+			}
+		case *parser.DFloat:
+			if kind == vecFloat {
+				v.filters = append(v.filters, &vecFloatCmp{col: ivar.Idx, op: op, val: float64(*c)})
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextBatch reads the next batch of rows from the scan, and selects all of
+// them. It returns false once there are no more rows.
+func (v *vecAggregation) nextBatch(ctx context.Context) (bool, error) {
+	b := &v.batch
+	b.length = 0
+	for b.length < vecBatchSize {
+		row, err := v.scan.fetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return false, err
+		}
+		if row == nil {
+			break
+		}
+		for i, c := range b.cols {
+			if c != nil {
+				c.set(b.length, row[i])
+			}
+		}
+		b.length++
+	}
+	b.sel = b.sel[:0]
+	for i := 0; i < b.length; i++ {
+		b.sel = append(b.sel, i)
+	}
+	return b.length > 0, nil
+}
+
+// run reads all the rows of the scan and stores the results of the
+// aggregations in values.
+func (v *vecAggregation) run(ctx context.Context, values parser.Datums) error {
+	if !v.scan.scanInitialized {
+		if err := v.scan.initScan(ctx); err != nil {
+			return err
+		}
+	}
+	for {
+		more, err := v.nextBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		for _, f := range v.filters {
+			f.filter(&v.batch)
+		}
+		for _, a := range v.aggs {
+			if err := a.add(&v.batch); err != nil {
+				return err
+			}
+		}
+	}
+	for i, a := range v.aggs {
+		var err error
+		if values[i], err = a.result(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isVectorized returns whether the plan contains an aggregation which runs on
+// the vectorized execution engine.
+func isVectorized(ctx context.Context, plan planNode) bool {
+	found := false
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) bool {
+			if n, ok := plan.(*groupNode); ok && n.vec != nil {
+				found = true
+			}
+			return !found
+		},
+	})
+	return found
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestVecCompareFloats verifies that the vectorized filters and aggregations
+// compare floats like the row-at-a-time execution.
+func TestVecCompareFloats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	values := []float64{math.NaN(), math.Inf(-1), -1.5, math.Copysign(0, -1), 0, 2, math.Inf(1)}
+	evalCtx := parser.MakeTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	for _, a := range values {
+		for _, b := range values {
+			expected := parser.NewDFloat(parser.DFloat(a)).Compare(&evalCtx, parser.NewDFloat(parser.DFloat(b)))
+			if cmp := compareFloats(a, b); cmp != expected {
+				t.Errorf("%f vs %f: expected %d, got %d", a, b, expected, cmp)
+			}
+		}
+	}
+}
+
+func TestVecFilters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ints := newVecColumn(parser.TypeInt)
+	floats := newVecColumn(parser.TypeFloat)
+	rows := []parser.Datums{
+		{parser.NewDInt(1), parser.NewDFloat(0.5)},
+		{parser.DNull, parser.NewDFloat(1.5)},
+		{parser.NewDInt(3), parser.DNull},
+		{parser.NewDInt(4), parser.NewDFloat(parser.DFloat(math.NaN()))},
+		{parser.NewDInt(5), parser.NewDFloat(2.5)},
+	}
+	b := vecBatch{cols: []*vecColumn{ints, floats}, length: len(rows)}
+	for i, row := range rows {
+		ints.set(i, row[0])
+		floats.set(i, row[1])
+	}
+
+	testCases := []struct {
+		filters  []vecFilter
+		expected []int
+	}{
+		{nil, []int{0, 1, 2, 3, 4}},
+		{[]vecFilter{&vecNotNull{col: 0}}, []int{0, 2, 3, 4}},
+		{[]vecFilter{&vecIntCmp{col: 0, op: parser.GT, val: 1}}, []int{2, 3, 4}},
+		{[]vecFilter{&vecIntCmp{col: 0, op: parser.NE, val: 3}}, []int{0, 3, 4}},
+		{[]vecFilter{&vecFloatCmp{col: 1, op: parser.LT, val: 1}}, []int{0, 3}},
+		{
+			[]vecFilter{
+				&vecIntCmp{col: 0, op: parser.GE, val: 3},
+				&vecFloatCmp{col: 1, op: parser.LE, val: 2.5},
+			},
+			[]int{3, 4},
+		},
+	}
+	for _, tc := range testCases {
+		b.sel = []int{0, 1, 2, 3, 4}
+		for _, f := range tc.filters {
+			f.filter(&b)
+		}
+		if !reflect.DeepEqual(b.sel, tc.expected) {
+			t.Errorf("%+v: expected %v, got %v", tc.filters, tc.expected, b.sel)
+		}
+	}
+}
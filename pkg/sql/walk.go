@@ -85,6 +85,12 @@ func (v *planVisitor) visit(plan planNode) {
 		return
 	}
 
+	if n, ok := plan.(*instrumentedNode); ok {
+		// The nodes instrumented for EXPLAIN ANALYZE are visited as if they
+		// were not wrapped.
+		plan = n.plan
+	}
+
 	name := nodeName(plan)
 	recurse := true
 	if v.observer.enterNode != nil {
@@ -256,6 +262,9 @@ func (v *planVisitor) visit(plan planNode) {
 		for i, agg := range n.funcs {
 			subplans = v.expr(name, "aggregate", i, agg.expr, subplans)
 		}
+		if n.vec != nil && v.observer.attr != nil {
+			v.observer.attr(name, "vectorized", "true")
+		}
 		v.visit(n.plan)
 
 	case *windowNode:
@@ -491,10 +500,15 @@ func nodeName(plan planNode) string {
 // strings are constant and not precomptued so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
+	reflect.TypeOf(&alterSequenceNode{}):    "alter sequence",
 	reflect.TypeOf(&alterTableNode{}):       "alter table",
+	reflect.TypeOf(&cancelQueryNode{}):      "cancel query",
+	reflect.TypeOf(&cancelSessionNode{}):    "cancel session",
+	reflect.TypeOf(&controlJobNode{}):       "control job",
 	reflect.TypeOf(&copyNode{}):             "copy",
 	reflect.TypeOf(&createDatabaseNode{}):   "create database",
 	reflect.TypeOf(&createIndexNode{}):      "create index",
+	reflect.TypeOf(&createSequenceNode{}):   "create sequence",
 	reflect.TypeOf(&createTableNode{}):      "create table",
 	reflect.TypeOf(&createUserNode{}):       "create user",
 	reflect.TypeOf(&createViewNode{}):       "create view",
@@ -503,6 +517,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&distinctNode{}):         "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):     "drop database",
 	reflect.TypeOf(&dropIndexNode{}):        "drop index",
+	reflect.TypeOf(&dropSequenceNode{}):     "drop sequence",
 	reflect.TypeOf(&dropTableNode{}):        "drop table",
 	reflect.TypeOf(&dropViewNode{}):         "drop view",
 	reflect.TypeOf(&dropUserNode{}):         "drop user",
@@ -523,6 +538,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&renderNode{}):           "render",
 	reflect.TypeOf(&scanNode{}):             "scan",
 	reflect.TypeOf(&scatterNode{}):          "scatter",
+	reflect.TypeOf(&scrubNode{}):            "scrub",
 	reflect.TypeOf(&showRangesNode{}):       "showRanges",
 	reflect.TypeOf(&showFingerprintsNode{}): "showFingerprints",
 	reflect.TypeOf(&sortNode{}):             "sort",
@@ -0,0 +1,118 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// cteScope is the environment of common table expressions (CTEs) in
+// which the data sources of a query are resolved. It is a linked list
+// with the innermost CTE at its head: a CTE shadows the CTEs with the
+// same name defined by enclosing queries.
+//
+// CTEs are not materialized; instead, each reference to a CTE is
+// planned as a subquery. The statement of the CTE is planned in the
+// environment in which the CTE was defined, so that it can refer to the
+// CTEs defined before it but not to itself.
+type cteScope struct {
+	cte *parser.CTE
+
+	// outer is the environment in which the CTE was defined.
+	outer *cteScope
+}
+
+// pushWith adds the CTEs of the given WITH clause to the environment of
+// the planner, and returns a function restoring the previous
+// environment.
+func (p *planner) pushWith(with *parser.With) (func(), error) {
+	saved := p.ctes
+	names := make(map[string]struct{}, len(with.CTEList))
+	for _, cte := range with.CTEList {
+		name := cte.Name.Alias.Normalize()
+		if _, ok := names[name]; ok {
+			p.ctes = saved
+			return nil, errors.Errorf("WITH query name %q specified more than once", name)
+		}
+		names[name] = struct{}{}
+		p.ctes = &cteScope{cte: cte, outer: p.ctes}
+	}
+	return func() { p.ctes = saved }, nil
+}
+
+// getCTEDataSource builds a planDataSource for the CTE designated by
+// the given table name, if any.
+func (p *planner) getCTEDataSource(
+	ctx context.Context, tn *parser.TableName,
+) (planDataSource, bool, error) {
+	if tn.DatabaseName != "" {
+		// CTEs can only be referred to by unqualified names.
+		return planDataSource{}, false, nil
+	}
+	name := tn.TableName.Normalize()
+	scope := p.ctes
+	for ; scope != nil; scope = scope.outer {
+		if scope.cte.Name.Alias.Normalize() == name {
+			break
+		}
+	}
+	if scope == nil {
+		return planDataSource{}, false, nil
+	}
+
+	cte := scope.cte
+	sel, ok := cte.Stmt.(*parser.Select)
+	if !ok {
+		return planDataSource{}, false, pgerror.Unimplemented(
+			"cte non-select", "WITH queries other than SELECT are not supported")
+	}
+
+	saved := p.ctes
+	p.ctes = scope.outer
+	plan, err := p.newPlan(ctx, sel, nil)
+	p.ctes = saved
+	if err != nil {
+		return planDataSource{}, false, err
+	}
+
+	cols := plan.Columns()
+	if colAlias := cte.Name.Cols; len(colAlias) > 0 {
+		// Make a copy of the columns since we are about to rename them.
+		cols = append(sqlbase.ResultColumns(nil), cols...)
+		// The column aliases can only refer to explicit columns.
+		for colIdx, aliasIdx := 0, 0; aliasIdx < len(colAlias); colIdx++ {
+			if colIdx >= len(cols) {
+				return planDataSource{}, false, errors.Errorf(
+					"WITH query %q has %d columns available but %d columns specified",
+					name, aliasIdx, len(colAlias))
+			}
+			if cols[colIdx].Hidden {
+				continue
+			}
+			cols[colIdx].Name = string(colAlias[aliasIdx])
+			aliasIdx++
+		}
+	}
+
+	return planDataSource{
+		info: newSourceInfoForSingleTable(parser.TableName{TableName: parser.Name(name)}, cols),
+		plan: plan,
+	}, true, nil
+}
@@ -54,9 +54,10 @@ const (
 	minReplicaWeight = 0.001
 
 	// priorities for various repair operations.
-	addMissingReplicaPriority  float64 = 10000
-	removeDeadReplicaPriority  float64 = 1000
-	removeExtraReplicaPriority float64 = 100
+	addMissingReplicaPriority            float64 = 10000
+	removeDeadReplicaPriority            float64 = 1000
+	removeDecommissioningReplicaPriority float64 = 200
+	removeExtraReplicaPriority           float64 = 100
 )
 
 var (
@@ -88,6 +89,22 @@ var (
 		"set greater than 1.0 to rebalance leases toward load more aggressively, "+
 			"or between 0 and 1.0 to be more conservative about rebalancing leases",
 		1.0)
+
+	// EnableQPSBasedLeaseRebalancing controls whether leases are moved off of
+	// stores serving considerably more requests per second than the mean when
+	// the locality-based heuristic has no opinion, rather than only balancing
+	// the number of leases per store.
+	EnableQPSBasedLeaseRebalancing = settings.RegisterBoolSetting(
+		"kv.allocator.qps_based_lease_rebalancing.enabled",
+		"set to enable rebalancing of range leases based on the queries per second served by each store",
+		false)
+
+	// QPSRebalanceThreshold is the fraction above the mean queries per second
+	// that a store must serve before leases are moved away from it.
+	QPSRebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
+		"kv.allocator.qps_rebalance_threshold",
+		"minimum fraction away from the mean a store's QPS must be to trigger lease rebalancing",
+		0.25)
 )
 
 // AllocatorAction enumerates the various replication adjustments that may be
@@ -101,13 +118,15 @@ const (
 	AllocatorRemove
 	AllocatorAdd
 	AllocatorRemoveDead
+	AllocatorRemoveDecommissioning
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
-	AllocatorNoop:       "noop",
-	AllocatorRemove:     "remove",
-	AllocatorAdd:        "add",
-	AllocatorRemoveDead: "remove dead",
+	AllocatorNoop:                  "noop",
+	AllocatorRemove:                "remove",
+	AllocatorAdd:                   "add",
+	AllocatorRemoveDead:            "remove dead",
+	AllocatorRemoveDecommissioning: "remove decommissioning",
 }
 
 func (a AllocatorAction) String() string {
@@ -250,6 +269,25 @@ func (a *Allocator) ComputeAction(
 			}
 		}
 	}
+	if decommissioningReplicas := a.storePool.decommissioningReplicas(
+		desc.RangeID, desc.Replicas); len(decommissioningReplicas) > 0 {
+		// The range has replicas on decommissioning nodes, which are removed
+		// once a replacement target exists, the same way as dead replicas.
+		if _, err := a.AllocateTarget(
+			ctx,
+			zone.Constraints,
+			desc.Replicas,
+			desc.RangeID,
+			true, /* relaxConstraints */
+		); err == nil {
+			priority := removeDecommissioningReplicaPriority
+			if log.V(3) {
+				log.Infof(ctx, "AllocatorRemoveDecommissioning - decommissioning=%d, priority=%.2f",
+					len(decommissioningReplicas), priority)
+			}
+			return AllocatorRemoveDecommissioning, priority
+		}
+	}
 	if have > need {
 		// Range is over-replicated, and should remove a replica.
 		// Ranges with an even number of replicas get extra priority because
@@ -468,6 +506,9 @@ func (a *Allocator) TransferLeaseTarget(
 	// whether we actually should be transferring the lease. The transfer
 	// decision is only needed if we've been asked to check the source.
 	transferDec, repl := a.shouldTransferLeaseUsingStats(ctx, sl, source, existing, stats)
+	if transferDec == decideWithoutStats {
+		transferDec, repl = a.shouldTransferLeaseUsingQPS(ctx, sl, source, existing, stats)
+	}
 	if checkTransferLeaseSource {
 		switch transferDec {
 		case shouldNotTransfer:
@@ -497,6 +538,9 @@ func (a *Allocator) TransferLeaseTarget(
 		if !ok {
 			continue
 		}
+		if checkCandidateFullness && isOverfullQPS(sl, storeDesc) {
+			continue
+		}
 		if !checkCandidateFullness || float64(storeDesc.Capacity.LeaseCount) < sl.candidateLeases.mean-0.5 {
 			candidates = append(candidates, repl)
 		}
@@ -531,6 +575,9 @@ func (a *Allocator) ShouldTransferLease(
 	}
 
 	transferDec, _ := a.shouldTransferLeaseUsingStats(ctx, sl, source, existing, stats)
+	if transferDec == decideWithoutStats {
+		transferDec, _ = a.shouldTransferLeaseUsingQPS(ctx, sl, source, existing, stats)
+	}
 	var result bool
 	switch transferDec {
 	case shouldNotTransfer:
@@ -648,6 +695,74 @@ func (a Allocator) shouldTransferLeaseUsingStats(
 	return shouldNotTransfer, bestRepl
 }
 
+// isOverfullQPS returns whether QPS-based lease rebalancing is enabled and the
+// store serves more queries per second than the threshold above the mean of
+// the store list allows.
+func isOverfullQPS(sl StoreList, store roachpb.StoreDescriptor) bool {
+	if !EnableQPSBasedLeaseRebalancing.Get() {
+		return false
+	}
+	overfullThreshold := sl.candidateQueriesPerSecond.mean * (1 + QPSRebalanceThreshold.Get())
+	return store.Capacity.QueriesPerSecond > overfullThreshold
+}
+
+// shouldTransferLeaseUsingQPS decides whether the lease should be moved off of
+// the source store because it serves more queries per second than the other
+// stores, and if so picks the replica on the least loaded store. It only picks
+// a target if moving the range's load there leaves the target less loaded
+// than the source, which keeps leases from bouncing between two hot stores.
+func (a Allocator) shouldTransferLeaseUsingQPS(
+	ctx context.Context,
+	sl StoreList,
+	source roachpb.StoreDescriptor,
+	existing []roachpb.ReplicaDescriptor,
+	stats *replicaStats,
+) (transferDecision, roachpb.ReplicaDescriptor) {
+	if stats == nil || !EnableQPSBasedLeaseRebalancing.Get() {
+		return decideWithoutStats, roachpb.ReplicaDescriptor{}
+	}
+	rangeQPS, rangeQPSDur := stats.avgQPS()
+	// As with the locality-based heuristic, wait for stats to accumulate after
+	// the lease was acquired in order to avoid thrashing.
+	if rangeQPSDur < MinLeaseTransferStatsDuration {
+		return shouldNotTransfer, roachpb.ReplicaDescriptor{}
+	}
+	if rangeQPS == 0 || !isOverfullQPS(sl, source) {
+		return decideWithoutStats, roachpb.ReplicaDescriptor{}
+	}
+
+	sourceQPS := source.Capacity.QueriesPerSecond
+	var bestRepl roachpb.ReplicaDescriptor
+	bestQPS := math.MaxFloat64
+	for _, repl := range existing {
+		if repl.NodeID == source.Node.NodeID {
+			continue
+		}
+		storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
+		if !ok {
+			continue
+		}
+		targetQPS := storeDesc.Capacity.QueriesPerSecond
+		if targetQPS+rangeQPS >= sourceQPS-rangeQPS {
+			continue
+		}
+		if targetQPS < bestQPS {
+			bestQPS = targetQPS
+			bestRepl = repl
+		}
+	}
+	if bestRepl == (roachpb.ReplicaDescriptor{}) {
+		return decideWithoutStats, roachpb.ReplicaDescriptor{}
+	}
+
+	log.Infof(ctx,
+		"QPS-based lease rebalancing: s%d (%.2f qps) is overfull (mean %.2f qps), "+
+			"considering s%d (%.2f qps) as the target for this range (%.2f qps)",
+		source.StoreID, sourceQPS, sl.candidateQueriesPerSecond.mean,
+		bestRepl.StoreID, bestQPS, rangeQPS)
+	return shouldTransfer, bestRepl
+}
+
 // loadBasedLeaseRebalanceScore attempts to give a score to how desirable it
 // would be to transfer a range lease from the local store to a remote store.
 // It does so using a formula based on the latency between the stores and
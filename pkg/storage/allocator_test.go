@@ -1266,6 +1266,86 @@ func TestAllocatorTransferLeaseTargetLoadBased(t *testing.T) {
 	}
 }
 
+// Test that leases are moved off of stores serving more than their share of
+// queries per second when QPS-based lease rebalancing is enabled.
+func TestAllocatorTransferLeaseTargetQPSBased(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+
+	// 3 stores with the same number of leases but very different loads.
+	qps := []float64{300, 100, 50}
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 3; i++ {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			Capacity: roachpb.StoreCapacity{
+				LeaseCount:       10,
+				QueriesPerSecond: qps[i-1],
+			},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	existing := []roachpb.ReplicaDescriptor{
+		{NodeID: 1, StoreID: 1},
+		{NodeID: 2, StoreID: 2},
+		{NodeID: 3, StoreID: 3},
+	}
+
+	// Give the range roughly 10 QPS.
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+	stats := newReplicaStats(clock, func(roachpb.NodeID) string { return "" })
+	for i := 0; i < 10*int(MinLeaseTransferStatsDuration.Seconds()); i++ {
+		stats.record(1)
+	}
+	manual.Increment(int64(MinLeaseTransferStatsDuration))
+
+	testCases := []struct {
+		enabled     bool
+		leaseholder roachpb.StoreID
+		expected    roachpb.StoreID
+	}{
+		{enabled: false, leaseholder: 1, expected: 0},
+		{enabled: true, leaseholder: 1, expected: 3},
+		{enabled: true, leaseholder: 2, expected: 0},
+		{enabled: true, leaseholder: 3, expected: 0},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			defer settings.TestingSetBool(&EnableQPSBasedLeaseRebalancing, c.enabled)()
+			shouldTransfer := a.ShouldTransferLease(
+				context.Background(),
+				config.Constraints{},
+				existing,
+				c.leaseholder,
+				0,
+				stats,
+			)
+			if expected := c.expected != 0; shouldTransfer != expected {
+				t.Errorf("expected ShouldTransferLease to return %t, got %t", expected, shouldTransfer)
+			}
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				config.Constraints{},
+				existing,
+				c.leaseholder,
+				0,
+				stats,
+				true, /* checkTransferLeaseSource */
+				true, /* checkCandidateFullness */
+			)
+			if c.expected != target.StoreID {
+				t.Errorf("expected %d, got %d", c.expected, target.StoreID)
+			}
+		})
+	}
+}
+
 func TestLoadBasedLeaseRebalanceScore(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	remoteStore := roachpb.StoreDescriptor{
@@ -1932,6 +2012,60 @@ func TestAllocatorComputeActionRemoveDead(t *testing.T) {
 	}
 }
 
+// TestAllocatorComputeActionDecommission verifies that replicas on
+// decommissioning nodes are removed once a replacement target is available.
+func TestAllocatorComputeActionDecommission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	zone := config.ZoneConfig{NumReplicas: 3}
+	desc := roachpb.RangeDescriptor{
+		Replicas: []roachpb.ReplicaDescriptor{
+			{StoreID: 1, NodeID: 1, ReplicaID: 1},
+			{StoreID: 2, NodeID: 2, ReplicaID: 2},
+			{StoreID: 3, NodeID: 3, ReplicaID: 3},
+		},
+	}
+
+	testCases := []struct {
+		expectedAction  AllocatorAction
+		live            []roachpb.StoreID
+		decommissioning []roachpb.StoreID
+	}{
+		// One replica is on a decommissioning node, but there is no replacement.
+		{
+			expectedAction:  AllocatorNoop,
+			live:            []roachpb.StoreID{1, 2},
+			decommissioning: []roachpb.StoreID{3},
+		},
+		// One replica is on a decommissioning node and there is a replacement.
+		{
+			expectedAction:  AllocatorRemoveDecommissioning,
+			live:            []roachpb.StoreID{1, 2, 4},
+			decommissioning: []roachpb.StoreID{3},
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator( /* deterministic */ false)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, append(tcase.live, tcase.decommissioning...), nil, nil)
+		mnl := newMockNodeLiveness(nodeStatusLive)
+		for _, storeID := range tcase.decommissioning {
+			mnl.setNodeStatus(roachpb.NodeID(storeID), nodeStatusDecommissioning)
+		}
+		sp.detailsMu.Lock()
+		sp.nodeLivenessFn = mnl.nodeLivenessFunc
+		sp.detailsMu.Unlock()
+
+		action, _ := a.ComputeAction(ctx, zone, &desc)
+		if tcase.expectedAction != action {
+			t.Errorf("Test case %d expected action %s, got action %s", i, tcase.expectedAction, action)
+		}
+	}
+}
+
 // TestAllocatorComputeActionNoStorePool verifies that
 // ComputeAction returns AllocatorNoop when storePool is nil.
 func TestAllocatorComputeActionNoStorePool(t *testing.T) {
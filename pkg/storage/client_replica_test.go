@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -1498,3 +1499,53 @@ func TestDrainRangeRejection(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// TestFollowerReadOnIdleRange verifies that the followers of a range
+// without writes can serve follower reads, the lease holder refreshing the
+// closed timestamp of the range.
+func TestFollowerReadOnIdleRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const safeDuration = 50 * time.Millisecond
+	defer settings.TestingSetDuration(&storagebase.FollowerReadsSafeDuration, safeDuration)()
+
+	mtc := &multiTestContext{}
+	defer mtc.Stop()
+	mtc.Start(t, 2)
+	mtc.replicateRange(mtc.stores[0].LookupReplica(roachpb.RKeyMin, nil).RangeID, 1)
+
+	// Split off a range which receives no writes.
+	key := roachpb.Key("a")
+	if _, pErr := client.SendWrapped(
+		context.Background(), rg1(mtc.stores[0]), adminSplitArgs(key, key),
+	); pErr != nil {
+		t.Fatal(pErr)
+	}
+	repl := mtc.stores[1].LookupReplica(roachpb.RKey(key), nil)
+	if repl == nil {
+		t.Fatalf("no replica of %s on store %d", key, mtc.stores[1].StoreID())
+	}
+
+	// Read at a timestamp which gets closed once the clock moves past the safe
+	// duration.
+	ts := mtc.clock.Now()
+	mtc.manualClock.Increment(4 * safeDuration.Nanoseconds())
+	testutils.SucceedsSoon(t, func() error {
+		reply, pErr := client.SendWrappedWith(context.Background(), mtc.stores[1], roachpb.Header{
+			RangeID:      repl.RangeID,
+			Timestamp:    ts,
+			FollowerRead: true,
+		}, getArgs(key))
+		if pErr != nil {
+			return pErr.GoError()
+		}
+		if v := reply.(*roachpb.GetResponse).Value; v != nil {
+			return errors.Errorf("expected no value, got %s", v)
+		}
+		return nil
+	})
+
+	// The read was served by the follower, not by acquiring the lease.
+	if lease, _ := repl.GetLease(); !lease.OwnedBy(mtc.stores[0].StoreID()) {
+		t.Fatalf("expected the lease to stay on store %d, got %s", mtc.stores[0].StoreID(), lease)
+	}
+}
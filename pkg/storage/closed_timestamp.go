@@ -0,0 +1,166 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// closedTimestampPollInterval is the interval at which the store checks
+// whether follower reads were enabled, to start refreshing the closed
+// timestamps of its idle ranges.
+const closedTimestampPollInterval = time.Second
+
+// closedTimestampRefreshInterval returns the interval at which the closed
+// timestamps of idle ranges are refreshed, which is also the lag of their
+// closed timestamps which triggers a refresh. It is zero if follower reads
+// are disabled.
+func closedTimestampRefreshInterval() time.Duration {
+	return storagebase.FollowerReadsSafeDuration.Get() / 4
+}
+
+// closedTimestampTracker tracks the closed timestamps under which the write
+// batches evaluated by a replica were admitted, until they are proposed. The
+// closed timestamp attached to a proposal must not exceed any of them, as
+// these batches may write right above them at higher lease indexes.
+type closedTimestampTracker struct {
+	syncutil.Mutex
+	evaluating map[hlc.Timestamp]int
+}
+
+// track returns the closed timestamp at time now, at or below which the
+// batch about to be evaluated must not write, and a function to call once
+// the batch was proposed or failed.
+func (t *closedTimestampTracker) track(now hlc.Timestamp) (hlc.Timestamp, func()) {
+	// Batches are tracked even if follower reads are disabled, as they may be
+	// enabled while the batch is evaluated.
+	closedTS := storagebase.ClosedTimestamp(now)
+	t.Lock()
+	if t.evaluating == nil {
+		t.evaluating = make(map[hlc.Timestamp]int)
+	}
+	t.evaluating[closedTS]++
+	t.Unlock()
+	return closedTS, func() {
+		t.Lock()
+		if t.evaluating[closedTS]--; t.evaluating[closedTS] == 0 {
+			delete(t.evaluating, closedTS)
+		}
+		t.Unlock()
+	}
+}
+
+// closed returns the closed timestamp to attach to a proposal at time now.
+func (t *closedTimestampTracker) closed(now hlc.Timestamp) hlc.Timestamp {
+	closedTS := storagebase.ClosedTimestamp(now)
+	t.Lock()
+	defer t.Unlock()
+	for ts := range t.evaluating {
+		if ts.Less(closedTS) {
+			closedTS = ts
+		}
+	}
+	return closedTS
+}
+
+// canServeFollowerRead returns true if the batch may be served by the replica
+// without the range lease: it must be eligible for a follower read, and the
+// replica must have applied the writes up to its timestamp.
+func (r *Replica) canServeFollowerRead(ba *roachpb.BatchRequest) bool {
+	if !storagebase.CanServeFollowerRead(ba, r.store.Clock().Now(), r.store.Clock().MaxOffset()) {
+		return false
+	}
+	r.mu.RLock()
+	closedTS := r.mu.closedTimestamp
+	r.mu.RUnlock()
+	return !closedTS.Less(storagebase.FollowerReadTimestamp(ba))
+}
+
+// startClosedTimestampLoop starts the loop which refreshes the closed
+// timestamps of the ranges of which the store holds the lease. The closed
+// timestamps are carried by the proposals of the lease holder: without
+// this loop, the followers of a range without writes could never serve
+// follower reads.
+func (s *Store) startClosedTimestampLoop() {
+	ctx := s.AnnotateCtx(context.Background())
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			interval := closedTimestampRefreshInterval()
+			wait := interval
+			if wait == 0 {
+				wait = closedTimestampPollInterval
+			}
+			select {
+			case <-time.After(wait):
+			case <-s.stopper.ShouldStop():
+				return
+			}
+			if interval != 0 {
+				s.refreshClosedTimestamps(ctx, interval)
+			}
+		}
+	})
+}
+
+// refreshClosedTimestamps proposes an empty GC request, which is a no-op
+// but carries a closed timestamp like any other proposal, on the replicas
+// of which the store holds the lease and whose closed timestamp lags by
+// more than lag. Ranges with recent writes are thus left alone.
+func (s *Store) refreshClosedTimestamps(ctx context.Context, lag time.Duration) {
+	// Limit the number of concurrent refreshes.
+	sem := make(chan struct{}, 32)
+	now := s.Clock().Now()
+	target := storagebase.ClosedTimestamp(now).Add(-lag.Nanoseconds(), 0)
+	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
+		r.mu.RLock()
+		closedTS := r.mu.closedTimestamp
+		r.mu.RUnlock()
+		if !closedTS.Less(target) || !r.ownsValidLease(now) {
+			return true
+		}
+		if err := s.stopper.RunLimitedAsyncTask(
+			r.AnnotateCtx(ctx), "storage.Store: refresh closed timestamp", sem, true, /* wait */
+			func(ctx context.Context) {
+				if err := r.refreshClosedTimestamp(ctx); err != nil {
+					log.VEventf(ctx, 1, "unable to refresh the closed timestamp: %s", err)
+				}
+			}); err != nil {
+			return false
+		}
+		return true
+	})
+}
+
+// refreshClosedTimestamp proposes an empty GC request on the replica, to
+// advance its closed timestamp on all the replicas of the range.
+func (r *Replica) refreshClosedTimestamp(ctx context.Context) error {
+	desc := r.Desc()
+	var ba roachpb.BatchRequest
+	ba.RangeID = desc.RangeID
+	ba.Timestamp = r.store.Clock().Now()
+	ba.Add(&roachpb.GCRequest{
+		Span: roachpb.Span{Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey()},
+	})
+	_, pErr := r.Send(ctx, ba)
+	return pErr.GoError()
+}
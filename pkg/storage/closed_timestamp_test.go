@@ -0,0 +1,65 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestClosedTimestampTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer settings.TestingSetDuration(&storagebase.FollowerReadsSafeDuration, 10*time.Second)()
+
+	at := func(d time.Duration) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: int64(d)}
+	}
+	var tracker closedTimestampTracker
+	if e, a := at(90*time.Second), tracker.closed(at(100*time.Second)); e != a {
+		t.Fatalf("expected %s without writes being evaluated, got %s", e, a)
+	}
+
+	// The writes being evaluated hold back the closed timestamp.
+	closedTS, release1 := tracker.track(at(100 * time.Second))
+	if e := at(90 * time.Second); closedTS != e {
+		t.Fatalf("expected %s, got %s", e, closedTS)
+	}
+	_, release2 := tracker.track(at(105 * time.Second))
+	if e, a := at(90*time.Second), tracker.closed(at(110*time.Second)); e != a {
+		t.Fatalf("expected %s, got %s", e, a)
+	}
+	release1()
+	if e, a := at(95*time.Second), tracker.closed(at(110*time.Second)); e != a {
+		t.Fatalf("expected %s, got %s", e, a)
+	}
+	release2()
+	if e, a := at(100*time.Second), tracker.closed(at(110*time.Second)); e != a {
+		t.Fatalf("expected %s, got %s", e, a)
+	}
+
+	// Writes evaluated while follower reads were disabled may be anywhere.
+	defer settings.TestingSetDuration(&storagebase.FollowerReadsSafeDuration, 0)()
+	_, release := tracker.track(at(110 * time.Second))
+	defer settings.TestingSetDuration(&storagebase.FollowerReadsSafeDuration, 10*time.Second)()
+	if a := tracker.closed(at(120 * time.Second)); a != (hlc.Timestamp{}) {
+		t.Fatalf("expected an empty closed timestamp, got %s", a)
+	}
+	release()
+}
@@ -0,0 +1,141 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// contentionEventLogSize is the number of contention events kept in memory
+// by each store. Older events are discarded.
+const contentionEventLogSize = 1000
+
+// The outcomes of the push of a transaction in a contention event.
+const (
+	// ContentionOutcomePushed means that the timestamp of the pushee was
+	// moved forward.
+	ContentionOutcomePushed = "pushed"
+	// ContentionOutcomeAborted means that the pushee was aborted.
+	ContentionOutcomeAborted = "aborted"
+	// ContentionOutcomeCommitted means that the pushee had committed by the
+	// time the push completed.
+	ContentionOutcomeCommitted = "committed"
+	// ContentionOutcomeFailed means that the push failed, usually because
+	// the pushee had a higher priority. The pusher is then retried or
+	// aborted.
+	ContentionOutcomeFailed = "failed"
+)
+
+// ContentionEvent describes a request which found the intent of another
+// transaction and had to push that transaction before proceeding.
+type ContentionEvent struct {
+	// Key is the key of the conflicting intent.
+	Key roachpb.Key
+	// TxnID is the ID of the pushing transaction, nil for non-transactional
+	// requests.
+	TxnID *uuid.UUID
+	// PusheeTxnID is the ID of the transaction which wrote the intent.
+	PusheeTxnID uuid.UUID
+	PushType    roachpb.PushTxnType
+	Outcome     string
+	// Time is when the push started.
+	Time time.Time
+	// WaitDuration is how long the request waited for the push, including
+	// the time spent in the push txn queue.
+	WaitDuration time.Duration
+}
+
+// contentionEventLog is a bounded, in-memory log of contention events.
+type contentionEventLog struct {
+	mu struct {
+		syncutil.Mutex
+		// events is used as a ring buffer once it reaches
+		// contentionEventLogSize, with next the index of the oldest event.
+		events []ContentionEvent
+		next   int
+	}
+}
+
+// recordPushes records a contention event for each of the intents whose
+// transactions were pushed by a request. pushed are the intents returned by
+// the push, holding the updated status of the pushees, and are only used if
+// the push succeeded.
+func (l *contentionEventLog) recordPushes(
+	start time.Time,
+	wait time.Duration,
+	pusherTxn *roachpb.Transaction,
+	pushType roachpb.PushTxnType,
+	intents []roachpb.Intent,
+	pushed []roachpb.Intent,
+	pErr *roachpb.Error,
+) {
+	var txnID *uuid.UUID
+	if pusherTxn != nil {
+		txnID = pusherTxn.ID
+	}
+	if pErr == nil {
+		intents = pushed
+	}
+	for _, intent := range intents {
+		outcome := ContentionOutcomeFailed
+		if pErr == nil {
+			switch intent.Status {
+			case roachpb.ABORTED:
+				outcome = ContentionOutcomeAborted
+			case roachpb.COMMITTED:
+				outcome = ContentionOutcomeCommitted
+			default:
+				outcome = ContentionOutcomePushed
+			}
+		}
+		var pusheeTxnID uuid.UUID
+		if intent.Txn.ID != nil {
+			pusheeTxnID = *intent.Txn.ID
+		}
+		l.add(ContentionEvent{
+			Key:          intent.Key,
+			TxnID:        txnID,
+			PusheeTxnID:  pusheeTxnID,
+			PushType:     pushType,
+			Outcome:      outcome,
+			Time:         start,
+			WaitDuration: wait,
+		})
+	}
+}
+
+func (l *contentionEventLog) add(event ContentionEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.mu.events) < contentionEventLogSize {
+		l.mu.events = append(l.mu.events, event)
+		return
+	}
+	l.mu.events[l.mu.next] = event
+	l.mu.next = (l.mu.next + 1) % len(l.mu.events)
+}
+
+// get returns a copy of the logged events, oldest first.
+func (l *contentionEventLog) get() []ContentionEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := make([]ContentionEvent, 0, len(l.mu.events))
+	events = append(events, l.mu.events[l.mu.next:]...)
+	return append(events, l.mu.events[:l.mu.next]...)
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestContentionEventLogRecordPushes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	pusher := newTransaction("pusher", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, nil)
+	pushee := newTransaction("pushee", roachpb.Key("b"), 1, enginepb.SERIALIZABLE, nil)
+	intent := roachpb.Intent{
+		Span:   roachpb.Span{Key: roachpb.Key("b")},
+		Txn:    pushee.TxnMeta,
+		Status: roachpb.PENDING,
+	}
+	start := time.Unix(1, 0)
+
+	testCases := []struct {
+		status  roachpb.TransactionStatus
+		pErr    *roachpb.Error
+		outcome string
+	}{
+		{roachpb.PENDING, nil, ContentionOutcomePushed},
+		{roachpb.ABORTED, nil, ContentionOutcomeAborted},
+		{roachpb.COMMITTED, nil, ContentionOutcomeCommitted},
+		{roachpb.PENDING, roachpb.NewError(&roachpb.TransactionPushError{}), ContentionOutcomeFailed},
+	}
+	for i, tc := range testCases {
+		var l contentionEventLog
+		pushed := intent
+		pushed.Status = tc.status
+		var pushedIntents []roachpb.Intent
+		if tc.pErr == nil {
+			pushedIntents = []roachpb.Intent{pushed}
+		}
+		l.recordPushes(start, time.Second, pusher, roachpb.PUSH_ABORT,
+			[]roachpb.Intent{intent}, pushedIntents, tc.pErr)
+
+		events := l.get()
+		if len(events) != 1 {
+			t.Fatalf("%d: expected 1 event, got %d", i, len(events))
+		}
+		e := events[0]
+		if e.Outcome != tc.outcome {
+			t.Errorf("%d: expected outcome %s, got %s", i, tc.outcome, e.Outcome)
+		}
+		if !e.Key.Equal(intent.Key) || *e.TxnID != *pusher.ID || e.PusheeTxnID != *pushee.ID ||
+			e.PushType != roachpb.PUSH_ABORT || e.Time != start || e.WaitDuration != time.Second {
+			t.Errorf("%d: unexpected event %+v", i, e)
+		}
+	}
+}
+
+func TestContentionEventLogBounded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var l contentionEventLog
+	n := contentionEventLogSize + 10
+	for i := 0; i < n; i++ {
+		l.add(ContentionEvent{Key: roachpb.Key(fmt.Sprintf("%05d", i)), PusheeTxnID: uuid.MakeV4()})
+	}
+	events := l.get()
+	if len(events) != contentionEventLogSize {
+		t.Fatalf("expected %d events, got %d", contentionEventLogSize, len(events))
+	}
+	// The oldest events are discarded and the rest returned in order.
+	for i, e := range events {
+		if expected := roachpb.Key(fmt.Sprintf("%05d", i+10)); !e.Key.Equal(expected) {
+			t.Fatalf("expected event %d to have key %s, got %s", i, expected, e.Key)
+		}
+	}
+}
@@ -188,6 +188,9 @@ type Engine interface {
 	Flush() error
 	// GetStats retrieves stats from the engine.
 	GetStats() (*Stats, error)
+	// Dir returns the directory in which the engine stores its data, or an
+	// empty string for in-memory engines.
+	Dir() string
 	// GetTempDir returns a path under which tempdirs or tempfiles can be created.
 	GetTempDir() string
 	// NewBatch returns a new instance of a batched engine which wraps
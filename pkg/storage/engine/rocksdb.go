@@ -1953,6 +1953,12 @@ func RunLDB(args []string) {
 	C.DBRunLDB(C.int(len(argv)), &argv[0])
 }
 
+// Dir returns the data directory of the engine, which is empty for
+// in-memory engines.
+func (r *RocksDB) Dir() string {
+	return r.dir
+}
+
 // GetTempDir returns a temp path (usually under the store directory).
 func (r *RocksDB) GetTempDir() string {
 	return r.tempDir
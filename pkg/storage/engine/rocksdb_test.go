@@ -611,3 +611,24 @@ func TestRocksDBTimeBound(t *testing.T) {
 		t.Fatalf("got max %v expected %v", sst.TsMax, maxTimestamp)
 	}
 }
+
+func TestRocksDBDir(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, dirCleanup := testutils.TempDir(t)
+	defer dirCleanup()
+
+	rocksdb, err := NewRocksDB(roachpb.Attributes{}, dir, RocksDBCache{}, 0, DefaultMaxOpenFiles)
+	if err != nil {
+		t.Fatalf("could not create new rocksdb db instance at %s: %v", dir, err)
+	}
+	defer rocksdb.Close()
+	if d := rocksdb.Dir(); d != dir {
+		t.Errorf("expected %s, got %s", dir, d)
+	}
+
+	inMem := NewInMem(roachpb.Attributes{}, 1<<20)
+	defer inMem.Close()
+	if d := inMem.Dir(); d != "" {
+		t.Errorf("expected no directory for an in-memory engine, got %s", d)
+	}
+}
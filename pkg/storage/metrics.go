@@ -149,6 +149,12 @@ var (
 	metaReserved = metric.Metadata{
 		Name: "capacity.reserved",
 		Help: "Capacity reserved for snapshots"}
+	metaDiskSpaceState = metric.Metadata{
+		Name: "capacity.disk_space_state",
+		Help: "Disk space state of the store: 0 if ok, 1 if declining rebalancing snapshots, 2 if rejecting writes"}
+	metaDiskSpaceStateChanges = metric.Metadata{
+		Name: "capacity.disk_space_state_changes",
+		Help: "Number of changes of the disk space state of the store"}
 	metaSysBytes = metric.Metadata{
 		Name: "sysbytes",
 		Help: "Number of bytes in system KV pairs"}
@@ -510,6 +516,10 @@ type StoreMetrics struct {
 	SysBytes        *metric.Gauge
 	SysCount        *metric.Gauge
 
+	// Disk space metrics.
+	DiskSpaceState        *metric.Gauge
+	DiskSpaceStateChanges *metric.Counter
+
 	// RocksDB metrics.
 	RdbBlockCacheHits           *metric.Gauge
 	RdbBlockCacheMisses         *metric.Gauge
@@ -693,6 +703,10 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		SysBytes:        metric.NewGauge(metaSysBytes),
 		SysCount:        metric.NewGauge(metaSysCount),
 
+		// Disk space metrics.
+		DiskSpaceState:        metric.NewGauge(metaDiskSpaceState),
+		DiskSpaceStateChanges: metric.NewCounter(metaDiskSpaceStateChanges),
+
 		// RocksDB metrics.
 		RdbBlockCacheHits:           metric.NewGauge(metaRdbBlockCacheHits),
 		RdbBlockCacheMisses:         metric.NewGauge(metaRdbBlockCacheMisses),
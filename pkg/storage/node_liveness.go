@@ -63,7 +63,9 @@ var (
 		Help: "Number of times this node has incremented its liveness epoch"}
 )
 
-func (l *Liveness) isLive(now hlc.Timestamp, maxOffset time.Duration) bool {
+// IsLive returns whether the liveness record is live at the given time,
+// accounting for the maximum clock offset.
+func (l *Liveness) IsLive(now hlc.Timestamp, maxOffset time.Duration) bool {
 	expiration := l.Expiration.Add(-maxOffset.Nanoseconds(), 0)
 	return now.Less(expiration)
 }
@@ -199,6 +201,62 @@ func (nl *NodeLiveness) setDrainingInternal(
 	return nil
 }
 
+var errNodeDecommissioningSet = errors.New("node already has given decommissioning value")
+
+// SetDecommissioning attempts to update the decommissioning field of the
+// liveness record of the specified node. Unlike SetDraining, it can be
+// called from any node.
+func (nl *NodeLiveness) SetDecommissioning(
+	ctx context.Context, nodeID roachpb.NodeID, decommission bool,
+) error {
+	ctx = nl.ambientCtx.AnnotateCtx(ctx)
+	var err error
+	for r := retry.StartWithCtx(ctx, base.DefaultRetryOptions()); r.Next(); {
+		var liveness *Liveness
+		liveness, err = nl.GetLiveness(nodeID)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get liveness for node %d", nodeID)
+		}
+		if err = nl.setDecommissioningInternal(ctx, liveness, decommission); err == nil {
+			return nil
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+func (nl *NodeLiveness) setDecommissioningInternal(
+	ctx context.Context, liveness *Liveness, decommission bool,
+) error {
+	newLiveness := *liveness
+	newLiveness.Decommissioning = decommission
+	update := func(l Liveness) {
+		nl.mu.Lock()
+		defer nl.mu.Unlock()
+		if nodeID := nl.gossip.NodeID.Get(); nodeID == l.NodeID {
+			nl.mu.self = l
+		} else {
+			nl.mu.nodes[l.NodeID] = l
+		}
+	}
+	if err := nl.updateLiveness(ctx, &newLiveness, liveness, func(actual Liveness) error {
+		update(actual)
+		if actual.Decommissioning == newLiveness.Decommissioning {
+			return errNodeDecommissioningSet
+		}
+		return errors.New("failed to update liveness record")
+	}); err != nil {
+		if err == errNodeDecommissioningSet {
+			return nil
+		}
+		return err
+	}
+	update(newLiveness)
+	return nil
+}
+
 // GetLivenessThreshold returns the maximum duration between heartbeats
 // before a node is considered not-live.
 func (nl *NodeLiveness) GetLivenessThreshold() time.Duration {
@@ -213,7 +271,7 @@ func (nl *NodeLiveness) IsLive(nodeID roachpb.NodeID) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return liveness.isLive(nl.clock.Now(), nl.clock.MaxOffset()), nil
+	return liveness.IsLive(nl.clock.Now(), nl.clock.MaxOffset()), nil
 }
 
 // StartHeartbeat starts a periodic heartbeat to refresh this node's
@@ -325,7 +383,7 @@ func (nl *NodeLiveness) heartbeatInternal(
 			// considered live, treat the heartbeat as a success. This can
 			// happen when the periodic heartbeater races with a concurrent
 			// lease acquisition.
-			if actual.isLive(nl.clock.Now(), nl.clock.MaxOffset()) && !incrementEpoch {
+			if actual.IsLive(nl.clock.Now(), nl.clock.MaxOffset()) && !incrementEpoch {
 				return errNodeAlreadyLive
 			}
 			// Otherwise, return error.
@@ -382,9 +440,9 @@ func (nl *NodeLiveness) GetIsLiveMap() map[roachpb.NodeID]bool {
 	maxOffset := nl.clock.MaxOffset()
 	for nID, l := range nl.mu.nodes {
 		if nID == nl.mu.self.NodeID {
-			lMap[nID] = nl.mu.self.isLive(now, maxOffset)
+			lMap[nID] = nl.mu.self.IsLive(now, maxOffset)
 		} else {
-			lMap[nID] = l.isLive(now, maxOffset)
+			lMap[nID] = l.IsLive(now, maxOffset)
 		}
 	}
 	return lMap
@@ -446,7 +504,7 @@ func (nl *NodeLiveness) IncrementEpoch(ctx context.Context, liveness *Liveness)
 		<-nl.sem
 	}()
 
-	if liveness.isLive(nl.clock.Now(), nl.clock.MaxOffset()) {
+	if liveness.IsLive(nl.clock.Now(), nl.clock.MaxOffset()) {
 		return errors.Errorf("cannot increment epoch on live node: %+v", liveness)
 	}
 	newLiveness := *liveness
@@ -577,16 +635,18 @@ func (nl *NodeLiveness) livenessGossipUpdate(key string, content roachpb.Value)
 
 	// If there's an existing liveness record, only update the received
 	// timestamp if this is our first receipt of this node's liveness, the
-	// expiration or epoch was advanced, or the draining state changed.
+	// expiration or epoch was advanced, or the draining or decommissioning
+	// state changed.
 	var callbacks []IsLiveCallback
 	nl.mu.Lock()
 	exLiveness, ok := nl.mu.nodes[liveness.NodeID]
-	if !ok || exLiveness.Expiration.Less(liveness.Expiration) || exLiveness.Epoch < liveness.Epoch || exLiveness.Draining != liveness.Draining {
+	if !ok || exLiveness.Expiration.Less(liveness.Expiration) || exLiveness.Epoch < liveness.Epoch ||
+		exLiveness.Draining != liveness.Draining || exLiveness.Decommissioning != liveness.Decommissioning {
 		nl.mu.nodes[liveness.NodeID] = liveness
 
 		// If isLive status is now true, but previously false, invoke any registered callbacks.
 		now, offset := nl.clock.Now(), nl.clock.MaxOffset()
-		if !exLiveness.isLive(now, offset) && liveness.isLive(now, offset) {
+		if !exLiveness.IsLive(now, offset) && liveness.IsLive(now, offset) {
 			callbacks = append(callbacks, nl.mu.callbacks...)
 		}
 	}
@@ -619,13 +679,13 @@ func (nl *NodeLiveness) numLiveNodes() int64 {
 	// because it's more likely to be inaccurate than the view of a live node.
 	now := nl.clock.Now()
 	maxOffset := nl.clock.MaxOffset()
-	if !nl.mu.self.isLive(now, maxOffset) {
+	if !nl.mu.self.IsLive(now, maxOffset) {
 		return 0
 	}
 
 	var liveNodes int64
 	for _, l := range nl.mu.nodes {
-		if l.isLive(now, maxOffset) {
+		if l.IsLive(now, maxOffset) {
 			liveNodes++
 		}
 	}
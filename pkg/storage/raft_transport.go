@@ -622,6 +622,7 @@ func (t *RaftTransport) SendAsync(req *RaftMessageRequest) bool {
 func (t *RaftTransport) SendSnapshot(
 	ctx context.Context,
 	storePool *StorePool,
+	bandwidth *snapshotBandwidth,
 	header SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
 	newBatch func() engine.Batch,
@@ -656,5 +657,5 @@ func (t *RaftTransport) SendSnapshot(
 			log.Warningf(ctx, "failed to close snapshot stream: %s", err)
 		}
 	}()
-	return sendSnapshot(ctx, stream, storePool, header, snap, newBatch, sent)
+	return sendSnapshot(ctx, stream, storePool, bandwidth, header, snap, newBatch, sent)
 }
@@ -109,7 +109,7 @@ var syncRaftLog = settings.RegisterBoolSetting(
 	"set to true to synchronize on Raft log writes to persistent storage",
 	true)
 
-var maxCommandSize = settings.RegisterByteSizeSetting(
+var maxCommandSize = settings.RegisterPositiveByteSizeSetting(
 	"kv.raft.command.max_size",
 	"maximum size of a raft command",
 	64<<20)
@@ -222,6 +222,9 @@ type Replica struct {
 	pushTxnQueue *pushTxnQueue // Queues push txn attempts by txn ID
 
 	stats *replicaStats
+	// loadSplitter proposes splitting the range when it receives a sustained
+	// high rate of requests.
+	loadSplitter *loadSplitter
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -253,6 +256,10 @@ type Replica struct {
 	// Contains the lease history when enabled.
 	leaseHistory *leaseHistory
 
+	// closedTS tracks the closed timestamps of the write batches being
+	// evaluated, which bound the closed timestamp of the proposals.
+	closedTS closedTimestampTracker
+
 	cmdQMu struct {
 		// Protects all fields in the cmdQMu struct.
 		//
@@ -320,6 +327,9 @@ type Replica struct {
 		// lease extension that were in flight at the time of the transfer cannot be
 		// used, if they eventually apply.
 		minLeaseProposedTS hlc.Timestamp
+		// closedTimestamp is the highest closed timestamp of the commands
+		// applied by the replica. It may serve follower reads at or below it.
+		closedTimestamp hlc.Timestamp
 		// Max bytes before split.
 		maxBytes int64
 		// proposals stores the Raft in-flight commands which
@@ -414,6 +424,13 @@ type Replica struct {
 		// Counts calls to Replica.tick()
 		ticks int
 
+		// The applied index seen by the last tick and the tick at which the
+		// replica last made progress, i.e. either had no pending proposals or
+		// saw its applied index advance. Used to report Raft groups which are
+		// stuck with pending proposals.
+		lastTickAppliedIndex uint64
+		lastProgressTicks    int
+
 		// Counts Raft messages refused due to queue congestion.
 		droppedMessages int
 
@@ -555,6 +572,7 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 		store:          store,
 		abortCache:     NewAbortCache(rangeID),
 		pushTxnQueue:   newPushTxnQueue(store),
+		loadSplitter:   newLoadSplitter(),
 	}
 	r.mu.stateLoader = makeReplicaStateLoader(rangeID)
 	if leaseHistoryMaxEntries > 0 {
@@ -1635,6 +1653,12 @@ func (r *Replica) Send(
 	if r.stats != nil && ba.Header.GatewayNodeID != 0 {
 		r.stats.record(ba.Header.GatewayNodeID)
 	}
+	if r.loadSplitter.record(time.Unix(0, r.store.Clock().PhysicalNow()), func() (roachpb.RSpan, bool) {
+		span, err := keys.Range(ba)
+		return span, err == nil
+	}) && r.store.splitQueue != nil {
+		r.store.splitQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
 
 	if err := r.checkBatchRequest(ba); err != nil {
 		return nil, roachpb.NewError(err)
@@ -2057,8 +2081,11 @@ func (r *Replica) beginCmds(
 // update its timestamp to be greater than more recent values in the
 // timestamp cache. When the write returns, the updated timestamp
 // will inform the batch response timestamp or batch response txn
-// timestamp.
-func (r *Replica) applyTimestampCache(ba *roachpb.BatchRequest) (bool, *roachpb.Error) {
+// timestamp. Writes are also moved above closedTS, as they could otherwise
+// be missed by follower reads.
+func (r *Replica) applyTimestampCache(
+	ba *roachpb.BatchRequest, closedTS hlc.Timestamp,
+) (bool, *roachpb.Error) {
 	span, err := keys.Range(*ba)
 	if err != nil {
 		return false, roachpb.NewError(err)
@@ -2105,6 +2132,9 @@ func (r *Replica) applyTimestampCache(ba *roachpb.BatchRequest) (bool, *roachpb.
 
 			// Forward the timestamp if there's been a more recent read (by someone else).
 			rTS, rTxnID, _ := r.store.tsCacheMu.cache.GetMaxRead(header.Key, header.EndKey)
+			if rTS.Forward(closedTS) {
+				rTxnID = nil
+			}
 			if ba.Txn != nil {
 				if rTxnID == nil || *ba.Txn.ID != *rTxnID {
 					nextTS := rTS.Next()
@@ -2238,8 +2268,9 @@ func (r *Replica) executeAdminBatch(
 func (r *Replica) executeReadOnlyBatch(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
-	// If the read is consistent, the read requires the range lease.
-	if ba.ReadConsistency != roachpb.INCONSISTENT {
+	// If the read is consistent, the read requires the range lease, unless it
+	// can be served by a follower.
+	if ba.ReadConsistency != roachpb.INCONSISTENT && !r.canServeFollowerRead(&ba) {
 		if _, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
 			return nil, pErr
 		}
@@ -2442,7 +2473,9 @@ func (r *Replica) tryExecuteWriteBatch(
 	// commands which require this command to move its timestamp
 	// forward. Or, in the case of a transactional write, the txn
 	// timestamp and possible write-too-old bool.
-	if bumped, pErr := r.applyTimestampCache(&ba); pErr != nil {
+	closedTS, releaseClosedTS := r.closedTS.track(r.store.Clock().Now())
+	if bumped, pErr := r.applyTimestampCache(&ba, closedTS); pErr != nil {
+		releaseClosedTS()
 		return nil, pErr, proposalNoRetry
 	} else if bumped {
 		// If we bump the transaction's timestamp, we must absolutely
@@ -2466,6 +2499,9 @@ func (r *Replica) tryExecuteWriteBatch(
 	log.Event(ctx, "applied timestamp cache")
 
 	ch, tryAbandon, undoQuotaAcquistion, err := r.propose(ctx, lease, ba, endCmds, spans)
+	// Once proposed, the command is ordered by its lease index and no longer
+	// holds back the closed timestamp of later proposals.
+	releaseClosedTS()
 	if err != nil {
 		return nil, roachpb.NewError(err), proposalNoRetry
 	}
@@ -2656,6 +2692,9 @@ func (r *Replica) insertProposalLocked(
 	proposal.command.MaxLeaseIndex = r.mu.lastAssignedLeaseIndex
 	proposal.command.ProposerReplica = proposerReplica
 	proposal.command.ProposerLease = proposerLease
+	if !proposal.Request.IsLeaseRequest() {
+		proposal.command.ClosedTimestamp = r.closedTS.closed(r.store.Clock().Now())
+	}
 	if log.V(4) {
 		log.Infof(proposal.ctx, "submitting proposal %x: maxLeaseIndex=%d",
 			proposal.idKey, proposal.command.MaxLeaseIndex)
@@ -3314,6 +3353,10 @@ func (r *Replica) tickRaftMuLocked() (bool, error) {
 	}
 
 	r.mu.ticks++
+	if len(r.mu.proposals) == 0 || r.mu.state.RaftAppliedIndex != r.mu.lastTickAppliedIndex {
+		r.mu.lastTickAppliedIndex = r.mu.state.RaftAppliedIndex
+		r.mu.lastProgressTicks = r.mu.ticks
+	}
 	r.mu.internalRaftGroup.Tick()
 	if !r.store.TestingKnobs().DisableRefreshReasonTicks &&
 		r.mu.ticks%r.store.cfg.RaftElectionTimeoutTicks == 0 {
@@ -4025,6 +4068,11 @@ func (r *Replica) processRaftCommand(
 		if pErr == nil {
 			pErr = forcedErr
 		}
+		if pErr == nil && raftCmd.ClosedTimestamp != (hlc.Timestamp{}) {
+			r.mu.Lock()
+			r.mu.closedTimestamp.Forward(raftCmd.ClosedTimestamp)
+			r.mu.Unlock()
+		}
 
 		var lResult *LocalEvalResult
 		if proposedLocally {
@@ -5015,8 +5063,21 @@ type ReplicaMetrics struct {
 	Underreplicated bool
 	BehindCount     int64
 	SelfBehindCount int64
+	// Is the range larger than twice its maximum size, i.e. is it failing to
+	// split? Only set on the replica which collects per-range metrics.
+	TooLarge bool
+	// Has the replica had pending proposals without its applied index
+	// advancing for raftStalledElectionTimeouts election timeouts?
+	RaftStalled bool
+	// Has the replica been found corrupt and stalled?
+	Corrupt bool
 }
 
+// raftStalledElectionTimeouts is the number of election timeouts a replica
+// with pending proposals can go without applying a command before its Raft
+// group is reported as stalled.
+const raftStalledElectionTimeouts = 3
+
 // Metrics returns the current metrics for the replica.
 func (r *Replica) Metrics(
 	ctx context.Context,
@@ -5030,10 +5091,18 @@ func (r *Replica) Metrics(
 	quiescent := r.mu.quiescent || r.mu.internalRaftGroup == nil
 	desc := r.mu.state.Desc
 	selfBehindCount := r.getEstimatedBehindCountRLocked(raftStatus)
+	tooLarge := r.exceedsDoubleSplitSizeRLocked()
+	raftStalled := r.mu.ticks-r.mu.lastProgressTicks >
+		raftStalledElectionTimeouts*r.store.cfg.RaftElectionTimeoutTicks
+	corrupt := r.mu.corrupted
 	r.mu.RUnlock()
 
-	return calcReplicaMetrics(ctx, now, cfg, livenessMap, desc,
+	m := calcReplicaMetrics(ctx, now, cfg, livenessMap, desc,
 		raftStatus, status, r.store.StoreID(), quiescent, selfBehindCount)
+	m.TooLarge = m.RangeCounter && tooLarge
+	m.RaftStalled = raftStalled
+	m.Corrupt = corrupt
+	return m
 }
 
 func isRaftLeader(raftStatus *raft.Status) bool {
@@ -3718,7 +3718,8 @@ func (r *Replica) sendSnapshot(
 		r.store.metrics.RangeSnapshotsGenerated.Inc(1)
 	}
 	if err := r.store.cfg.Transport.SendSnapshot(
-		ctx, r.store.allocator.storePool, req, snap, r.store.Engine().NewBatch, sent); err != nil {
+		ctx, r.store.allocator.storePool, &r.store.snapshotBandwidth, req, snap,
+		r.store.Engine().NewBatch, sent); err != nil {
 		return &snapshotError{err}
 	}
 	return nil
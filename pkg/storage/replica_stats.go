@@ -135,6 +135,18 @@ func (rs *replicaStats) perLocalityDecayingQPS() (perLocalityCounts, time.Durati
 	return counts, now.Sub(rs.mu.lastReset)
 }
 
+// avgQPS returns the decaying average of requests per second received by the
+// replica from all localities, and the amount of time over which the stats
+// were accumulated.
+func (rs *replicaStats) avgQPS() (float64, time.Duration) {
+	counts, duration := rs.perLocalityDecayingQPS()
+	var qps float64
+	for _, v := range counts {
+		qps += v
+	}
+	return qps, duration
+}
+
 func (rs *replicaStats) resetRequestCounts() {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
@@ -7689,6 +7689,40 @@ func TestReplicaMetrics(t *testing.T) {
 	}
 }
 
+// TestReplicaMetricsRaftStalled verifies that a replica is reported as
+// stalled once it has gone raftStalledElectionTimeouts election timeouts
+// without making progress.
+func TestReplicaMetricsRaftStalled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var tc testContext
+	cfg := TestStoreConfig(nil)
+	// Disable ticks which would modify the tick counters concurrently.
+	cfg.RaftTickInterval = math.MaxInt32
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.StartWithStoreConfig(t, stopper, cfg)
+
+	r := tc.repl
+	stalledTicks := raftStalledElectionTimeouts * tc.store.cfg.RaftElectionTimeoutTicks
+	testCases := []struct {
+		ticksSinceProgress int
+		expected           bool
+	}{
+		{0, false},
+		{stalledTicks, false},
+		{stalledTicks + 1, true},
+	}
+	for i, c := range testCases {
+		r.mu.Lock()
+		r.mu.lastProgressTicks = r.mu.ticks - c.ticksSinceProgress
+		r.mu.Unlock()
+		metrics := r.Metrics(context.Background(), tc.Clock().Now(), config.SystemConfig{}, nil)
+		if metrics.RaftStalled != c.expected {
+			t.Errorf("%d: expected stalled %t, got %t", i, c.expected, metrics.RaftStalled)
+		}
+	}
+}
+
 // TestCancelPendingCommands verifies that cancelPendingCommands sends
 // an error to each command awaiting execution.
 func TestCancelPendingCommands(t *testing.T) {
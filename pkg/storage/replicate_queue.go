@@ -57,6 +57,9 @@ var (
 	metaReplicateQueueRemoveDeadReplicaCount = metric.Metadata{
 		Name: "queue.replicate.removedeadreplica",
 		Help: "Number of dead replica removals attempted by the replicate queue (typically in response to a node outage)"}
+	metaReplicateQueueRemoveDecommissioningReplicaCount = metric.Metadata{
+		Name: "queue.replicate.removedecommissioningreplica",
+		Help: "Number of decommissioning replica removals attempted by the replicate queue (typically in response to a node decommission)"}
 	metaReplicateQueueRebalanceReplicaCount = metric.Metadata{
 		Name: "queue.replicate.rebalancereplica",
 		Help: "Number of replica rebalancer-initiated additions attempted by the replicate queue"}
@@ -67,20 +70,22 @@ var (
 
 // ReplicateQueueMetrics is the set of metrics for the replicate queue.
 type ReplicateQueueMetrics struct {
-	AddReplicaCount        *metric.Counter
-	RemoveReplicaCount     *metric.Counter
-	RemoveDeadReplicaCount *metric.Counter
-	RebalanceReplicaCount  *metric.Counter
-	TransferLeaseCount     *metric.Counter
+	AddReplicaCount                   *metric.Counter
+	RemoveReplicaCount                *metric.Counter
+	RemoveDeadReplicaCount            *metric.Counter
+	RemoveDecommissioningReplicaCount *metric.Counter
+	RebalanceReplicaCount             *metric.Counter
+	TransferLeaseCount                *metric.Counter
 }
 
 func makeReplicateQueueMetrics() ReplicateQueueMetrics {
 	return ReplicateQueueMetrics{
-		AddReplicaCount:        metric.NewCounter(metaReplicateQueueAddReplicaCount),
-		RemoveReplicaCount:     metric.NewCounter(metaReplicateQueueRemoveReplicaCount),
-		RemoveDeadReplicaCount: metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
-		RebalanceReplicaCount:  metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
-		TransferLeaseCount:     metric.NewCounter(metaReplicateQueueTransferLeaseCount),
+		AddReplicaCount:                   metric.NewCounter(metaReplicateQueueAddReplicaCount),
+		RemoveReplicaCount:                metric.NewCounter(metaReplicateQueueRemoveReplicaCount),
+		RemoveDeadReplicaCount:            metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
+		RemoveDecommissioningReplicaCount: metric.NewCounter(metaReplicateQueueRemoveDecommissioningReplicaCount),
+		RebalanceReplicaCount:             metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
+		TransferLeaseCount:                metric.NewCounter(metaReplicateQueueTransferLeaseCount),
 	}
 }
 
@@ -392,6 +397,51 @@ func (rq *replicateQueue) processOneChange(
 		if err := rq.removeReplica(ctx, repl, target, desc); err != nil {
 			return false, err
 		}
+	case AllocatorRemoveDecommissioning:
+		if log.V(1) {
+			log.Infof(ctx, "removing a decommissioning replica")
+		}
+		decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(
+			desc.RangeID, desc.Replicas)
+		if len(decommissioningReplicas) == 0 {
+			if log.V(1) {
+				log.Warningf(ctx, "range of replica %s was identified as having decommissioning replicas, but no decommissioning replicas were found", repl)
+			}
+			break
+		}
+		decommissioningReplica := decommissioningReplicas[0]
+		if decommissioningReplica.StoreID == repl.store.StoreID() {
+			// The local replica is the leaseholder, so transfer the lease away
+			// before the replica can be removed.
+			transferred, err := rq.transferLease(
+				ctx,
+				repl,
+				desc,
+				zone,
+				false, /* checkTransferLeaseSource */
+				false, /* checkCandidateFullness */
+			)
+			if err != nil {
+				return false, err
+			}
+			if !transferred {
+				return false, errors.Errorf("unable to transfer lease away from decommissioning replica %+v",
+					decommissioningReplica)
+			}
+			// Do not requeue as we transferred our lease away.
+			return false, nil
+		}
+		rq.metrics.RemoveDecommissioningReplicaCount.Inc(1)
+		if log.V(1) {
+			log.Infof(ctx, "removing decommissioning replica %+v from store", decommissioningReplica)
+		}
+		target := roachpb.ReplicationTarget{
+			NodeID:  decommissioningReplica.NodeID,
+			StoreID: decommissioningReplica.StoreID,
+		}
+		if err := rq.removeReplica(ctx, repl, target, desc); err != nil {
+			return false, err
+		}
 	case AllocatorNoop:
 		// The Noop case will result if this replica was queued in order to
 		// rebalance. Attempt to find a rebalancing target.
@@ -0,0 +1,162 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var totalSnapshotRate = settings.RegisterPositiveByteSizeSetting(
+	"kv.snapshot.max_rate",
+	"the rate limit (bytes/sec) shared by all the snapshots sent by a store; "+
+		"recovery snapshots are sent before rebalance snapshots",
+	envutil.EnvOrDefaultBytes("COCKROACH_SNAPSHOT_RATE", 8<<20))
+
+// snapshotBatchSize is the size of the batches of a snapshot, which are the
+// granularity of rate limiting.
+const snapshotBatchSize = 256 << 10 // 256 KB
+
+// tokenBucket is a token bucket whose tokens are bytes. Its tokens can go
+// negative so that requests larger than the burst are served; the following
+// requests wait for the debt to be paid back.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accumulated at the given rate since the last refill,
+// up to one batch.
+func (tb *tokenBucket) refill(now time.Time, rate float64) {
+	if tb.last.IsZero() {
+		tb.tokens = snapshotBatchSize
+	} else {
+		tb.tokens += now.Sub(tb.last).Seconds() * rate
+		if tb.tokens > snapshotBatchSize {
+			tb.tokens = snapshotBatchSize
+		}
+	}
+	tb.last = now
+}
+
+// wait returns how long it takes for the tokens to become non-negative at the
+// given rate.
+func (tb *tokenBucket) wait(rate float64) time.Duration {
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / rate * float64(time.Second))
+}
+
+// snapshotBandwidth shares the bandwidth of the snapshots sent by a store
+// between the recovery and rebalance snapshots. The snapshots of each priority
+// share a token bucket limited by kv.snapshot_recovery.max_rate or
+// kv.snapshot_rebalance.max_rate, and all the snapshots share a token bucket
+// limited by kv.snapshot.max_rate. Rebalance snapshots don't take tokens from
+// the latter while recovery snapshots wait for it, so that rebalancing doesn't
+// slow down up-replication after a node failure. The settings apply to the
+// snapshots in progress as soon as they change.
+type snapshotBandwidth struct {
+	mu struct {
+		syncutil.Mutex
+		total     tokenBucket
+		recovery  tokenBucket
+		rebalance tokenBucket
+		// recoveryWaiters is the number of recovery snapshots waiting for the
+		// tokens of the total bucket.
+		recoveryWaiters int
+	}
+}
+
+// acquire waits until a snapshot of the given priority can send n bytes.
+func (sb *snapshotBandwidth) acquire(
+	ctx context.Context, priority SnapshotRequest_Priority, n int64,
+) error {
+	classRate, err := snapshotRateLimit(priority)
+	if err != nil {
+		return err
+	}
+	var waiting bool
+	defer func() {
+		if waiting {
+			sb.mu.Lock()
+			sb.mu.recoveryWaiters--
+			sb.mu.Unlock()
+		}
+	}()
+	for {
+		wait := sb.tryAcquire(priority, float64(classRate), n, &waiting)
+		if wait == 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// tryAcquire takes n bytes from the buckets of a snapshot of the given
+// priority if they have tokens left, and otherwise returns how long to wait
+// before trying again. waiting tracks whether the snapshot is counted in
+// recoveryWaiters.
+func (sb *snapshotBandwidth) tryAcquire(
+	priority SnapshotRequest_Priority, classRate float64, n int64, waiting *bool,
+) time.Duration {
+	now := timeutil.Now()
+	totalRate := float64(totalSnapshotRate.Get())
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	class := &sb.mu.rebalance
+	if priority == SnapshotRequest_RECOVERY {
+		class = &sb.mu.recovery
+	}
+	sb.mu.total.refill(now, totalRate)
+	class.refill(now, classRate)
+
+	totalWait := sb.mu.total.wait(totalRate)
+	if priority == SnapshotRequest_RECOVERY {
+		if totalWait > 0 && !*waiting {
+			sb.mu.recoveryWaiters++
+			*waiting = true
+		} else if totalWait == 0 && *waiting {
+			sb.mu.recoveryWaiters--
+			*waiting = false
+		}
+	} else if sb.mu.recoveryWaiters > 0 {
+		// Let the waiting recovery snapshots go first, and check again once
+		// they had the time to send a batch.
+		return totalWait + time.Duration(float64(n)/totalRate*float64(time.Second))
+	}
+
+	if wait := class.wait(classRate); wait > totalWait {
+		return wait
+	} else if totalWait > 0 {
+		return totalWait
+	}
+	sb.mu.total.tokens -= float64(n)
+	class.tokens -= float64(n)
+	return 0
+}
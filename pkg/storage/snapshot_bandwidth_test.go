@@ -0,0 +1,87 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestTokenBucket(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const rate = snapshotBatchSize // one batch per second
+	start := time.Unix(0, 0)
+
+	var tb tokenBucket
+	tb.refill(start, rate)
+	if tb.tokens != snapshotBatchSize {
+		t.Fatalf("expected a full bucket, found %f tokens", tb.tokens)
+	}
+	if wait := tb.wait(rate); wait != 0 {
+		t.Fatalf("expected no wait, found %s", wait)
+	}
+
+	// Going into debt requires waiting for the debt to be paid back.
+	tb.tokens -= 3 * snapshotBatchSize
+	if wait, expected := tb.wait(rate), 2*time.Second; wait != expected {
+		t.Fatalf("expected to wait %s, found %s", expected, wait)
+	}
+	tb.refill(start.Add(time.Second), rate)
+	if wait, expected := tb.wait(rate), time.Second; wait != expected {
+		t.Fatalf("expected to wait %s, found %s", expected, wait)
+	}
+
+	// The bucket never holds more than one batch.
+	tb.refill(start.Add(time.Minute), rate)
+	if tb.tokens != snapshotBatchSize {
+		t.Fatalf("expected a full bucket, found %f tokens", tb.tokens)
+	}
+}
+
+func TestSnapshotBandwidthPrefersRecovery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var sb snapshotBandwidth
+	const classRate = snapshotBatchSize
+	var recoveryWaiting, rebalanceWaiting bool
+
+	// Empty the total bucket so that the recovery snapshot has to wait.
+	if wait := sb.tryAcquire(
+		SnapshotRequest_RECOVERY, classRate, 2*snapshotBatchSize, &recoveryWaiting,
+	); wait != 0 {
+		t.Fatalf("expected the first batch to be sent right away, waited %s", wait)
+	}
+	if wait := sb.tryAcquire(
+		SnapshotRequest_RECOVERY, classRate, snapshotBatchSize, &recoveryWaiting,
+	); wait == 0 {
+		t.Fatal("expected the recovery snapshot to wait")
+	}
+	if !recoveryWaiting {
+		t.Fatal("expected the recovery snapshot to be counted as waiting")
+	}
+
+	// A rebalance snapshot must not take tokens while recovery is waiting.
+	if wait := sb.tryAcquire(
+		SnapshotRequest_REBALANCE, classRate, snapshotBatchSize, &rebalanceWaiting,
+	); wait == 0 {
+		t.Fatal("expected the rebalance snapshot to wait for the recovery snapshot")
+	}
+	if sb.mu.rebalance.tokens != snapshotBatchSize {
+		t.Fatalf("expected the rebalance bucket to be full, found %f tokens", sb.mu.rebalance.tokens)
+	}
+}
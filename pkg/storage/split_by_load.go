@@ -0,0 +1,220 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var (
+	// SplitByLoadEnabled controls whether ranges are split automatically when
+	// they receive more requests than SplitByLoadQPSThreshold.
+	SplitByLoadEnabled = settings.RegisterBoolSetting(
+		"kv.range_split.by_load_enabled",
+		"allow automatic splits of ranges based on where load is concentrated",
+		true)
+
+	// SplitByLoadQPSThreshold is the number of queries per second a range must
+	// sustain for splitByLoadDuration before it is split by load.
+	SplitByLoadQPSThreshold = settings.RegisterIntSetting(
+		"kv.range_split.load_qps_threshold",
+		"the QPS over which, for a sustained period, a range becomes a candidate for load-based splitting",
+		250)
+)
+
+const (
+	// splitByLoadDuration is how long a range has to receive more than the
+	// QPS threshold before a split key is chosen. Waiting avoids splitting
+	// ranges because of short bursts of load.
+	splitByLoadDuration = 10 * time.Second
+
+	// splitKeySampleSize is the number of request keys sampled as candidate
+	// split keys.
+	splitKeySampleSize = 20
+
+	// splitKeyMinCounter is the number of requests which must have been counted
+	// against a candidate split key before it can be picked.
+	splitKeyMinCounter = 100
+
+	// splitKeyThreshold is the maximum imbalance, as a fraction of the counted
+	// requests, between the requests to the left and to the right of a split
+	// key for the key to be picked.
+	splitKeyThreshold = 0.25
+
+	// splitKeyContainedThreshold is the maximum fraction of the counted
+	// requests that may span a candidate split key for the key to be picked.
+	splitKeyContainedThreshold = 0.5
+)
+
+// splitKeySample is a candidate split key along with the number of requests
+// seen since it was sampled which fell to its left, to its right, or which
+// spanned it.
+type splitKeySample struct {
+	key                    roachpb.RKey
+	left, right, contained int
+}
+
+// splitFinder picks a key which divides the requests to a range roughly in
+// half, using reservoir sampling to choose candidate keys among the start
+// keys of the requests.
+type splitFinder struct {
+	startTime time.Time
+	count     int
+	samples   [splitKeySampleSize]splitKeySample
+}
+
+func (f *splitFinder) record(span roachpb.RSpan, intn func(int) int) {
+	idx := f.count
+	if idx >= splitKeySampleSize {
+		idx = intn(f.count + 1)
+	}
+	f.count++
+
+	n := f.count - 1
+	if n > splitKeySampleSize {
+		n = splitKeySampleSize
+	}
+	for i := 0; i < n; i++ {
+		s := &f.samples[i]
+		switch {
+		case !s.key.Less(span.EndKey):
+			s.left++
+		case !span.Key.Less(s.key):
+			s.right++
+		default:
+			s.contained++
+		}
+	}
+	if idx < splitKeySampleSize {
+		f.samples[idx] = splitKeySample{key: span.Key}
+	}
+}
+
+// key returns the most balanced of the candidate split keys, or nil if none is
+// balanced enough.
+func (f *splitFinder) key() roachpb.RKey {
+	var best roachpb.RKey
+	bestImbalance := math.Inf(1)
+	for i := range f.samples {
+		s := &f.samples[i]
+		total := s.left + s.right + s.contained
+		if s.key == nil || total < splitKeyMinCounter {
+			continue
+		}
+		if float64(s.contained)/float64(total) > splitKeyContainedThreshold {
+			continue
+		}
+		imbalance := math.Abs(float64(s.left-s.right)) / float64(total)
+		if imbalance < splitKeyThreshold && imbalance < bestImbalance {
+			best, bestImbalance = s.key, imbalance
+		}
+	}
+	return best
+}
+
+// loadSplitter tracks the queries per second received by a replica and, once
+// the replica has received more than SplitByLoadQPSThreshold for
+// splitByLoadDuration, proposes a key to split the range at which divides its
+// load.
+type loadSplitter struct {
+	intn func(int) int
+
+	mu struct {
+		syncutil.Mutex
+		lastQPSRollover time.Time
+		count           int64
+		lastQPS         float64
+		// lastQueued is when record last asked for the replica to be queued
+		// for splitting, in order to only do so once per splitByLoadDuration.
+		lastQueued time.Time
+		// finder is set while the replica receives more than the threshold.
+		finder *splitFinder
+	}
+}
+
+func newLoadSplitter() *loadSplitter {
+	return &loadSplitter{intn: rand.Intn}
+}
+
+// record records a request to the replica. The span of the request is only
+// computed when it is needed to sample split keys. It returns true when a
+// split key has been found and the replica should be queued for splitting.
+func (ls *loadSplitter) record(now time.Time, spanFn func() (roachpb.RSpan, bool)) bool {
+	if !SplitByLoadEnabled.Get() {
+		return false
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.mu.count++
+	if ls.mu.lastQPSRollover.IsZero() {
+		ls.mu.lastQPSRollover = now
+	}
+	if elapsed := now.Sub(ls.mu.lastQPSRollover); elapsed >= time.Second {
+		ls.mu.lastQPS = float64(ls.mu.count) / elapsed.Seconds()
+		ls.mu.lastQPSRollover = now
+		ls.mu.count = 0
+
+		if ls.mu.lastQPS >= float64(SplitByLoadQPSThreshold.Get()) {
+			if ls.mu.finder == nil {
+				ls.mu.finder = &splitFinder{startTime: now}
+			}
+		} else {
+			ls.mu.finder = nil
+		}
+	}
+
+	if ls.mu.finder == nil {
+		return false
+	}
+	if span, ok := spanFn(); ok {
+		ls.mu.finder.record(span, ls.intn)
+	}
+	if now.Sub(ls.mu.finder.startTime) < splitByLoadDuration ||
+		now.Sub(ls.mu.lastQueued) < splitByLoadDuration || ls.mu.finder.key() == nil {
+		return false
+	}
+	ls.mu.lastQueued = now
+	return true
+}
+
+// splitKey returns the key to split the range at to divide its load, or nil
+// if the range should not be split by load.
+func (ls *loadSplitter) splitKey(now time.Time) roachpb.RKey {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.mu.finder == nil || now.Sub(ls.mu.finder.startTime) < splitByLoadDuration {
+		return nil
+	}
+	return ls.mu.finder.key()
+}
+
+// reset discards the load statistics, which no longer apply after the range
+// has been split.
+func (ls *loadSplitter) reset() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.mu.finder = nil
+	ls.mu.count = 0
+	ls.mu.lastQPS = 0
+	ls.mu.lastQPSRollover = time.Time{}
+}
@@ -0,0 +1,122 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func pointSpan(i int) roachpb.RSpan {
+	key := roachpb.RKey(fmt.Sprintf("%04d", i))
+	return roachpb.RSpan{Key: key, EndKey: key.Next()}
+}
+
+func TestSplitFinder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng := rand.New(rand.NewSource(1))
+
+	// Uniform requests over [0, 1000) are split close to the middle.
+	var f splitFinder
+	for i := 0; i < 10000; i++ {
+		f.record(pointSpan(rng.Intn(1000)), rng.Intn)
+	}
+	key := f.key()
+	if key == nil {
+		t.Fatal("expected a split key")
+	}
+	if key.Less(roachpb.RKey("0300")) || !key.Less(roachpb.RKey("0700")) {
+		t.Errorf("expected a split key close to the middle, got %s", key)
+	}
+
+	// Requests to a single key cannot be divided.
+	f = splitFinder{}
+	for i := 0; i < 10000; i++ {
+		f.record(pointSpan(5), rng.Intn)
+	}
+	if key := f.key(); key != nil {
+		t.Errorf("expected no split key, got %s", key)
+	}
+
+	// Requests spanning all the keys cannot be divided either.
+	f = splitFinder{}
+	for i := 0; i < 10000; i++ {
+		start := rng.Intn(10)
+		f.record(roachpb.RSpan{
+			Key:    pointSpan(start).Key,
+			EndKey: pointSpan(1000 + start).Key,
+		}, rng.Intn)
+	}
+	if key := f.key(); key != nil {
+		t.Errorf("expected no split key, got %s", key)
+	}
+}
+
+func TestLoadSplitter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng := rand.New(rand.NewSource(1))
+	spanFn := func() (roachpb.RSpan, bool) {
+		return pointSpan(rng.Intn(1000)), true
+	}
+	start := time.Unix(0, 0)
+
+	// Record requests at the given rate for the given duration, returning
+	// whether the splitter asked for the replica to be queued.
+	run := func(ls *loadSplitter, qps int, dur time.Duration) bool {
+		var queued bool
+		n := int(dur.Seconds()) * qps
+		for i := 0; i < n; i++ {
+			now := start.Add(time.Duration(i) * time.Second / time.Duration(qps))
+			if ls.record(now, spanFn) {
+				queued = true
+			}
+		}
+		return queued
+	}
+
+	threshold := int(SplitByLoadQPSThreshold.Get())
+
+	ls := newLoadSplitter()
+	if run(ls, threshold/2, 2*splitByLoadDuration) {
+		t.Error("expected a range below the QPS threshold not to be split")
+	}
+	if key := ls.splitKey(start.Add(2 * splitByLoadDuration)); key != nil {
+		t.Errorf("expected no split key, got %s", key)
+	}
+
+	ls = newLoadSplitter()
+	if run(ls, 2*threshold, splitByLoadDuration/2) {
+		t.Error("expected a short burst of load not to split the range")
+	}
+
+	ls = newLoadSplitter()
+	if !run(ls, 2*threshold, 2*splitByLoadDuration) {
+		t.Error("expected a range above the QPS threshold to be split")
+	}
+	if key := ls.splitKey(start.Add(2 * splitByLoadDuration)); key == nil {
+		t.Error("expected a split key")
+	}
+	ls.reset()
+	if key := ls.splitKey(start.Add(2 * splitByLoadDuration)); key != nil {
+		t.Errorf("expected no split key after reset, got %s", key)
+	}
+}
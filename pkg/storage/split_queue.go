@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
 const (
@@ -64,7 +65,8 @@ func newSplitQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *splitQue
 
 // shouldQueue determines whether a range should be queued for
 // splitting. This is true if the range is intersected by a zone config
-// prefix or if the range's size in bytes exceeds the limit for the zone.
+// prefix, if the range's size in bytes exceeds the limit for the zone or if
+// the range has been receiving enough load to be split by load.
 func (sq *splitQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
 ) (shouldQ bool, priority float64) {
@@ -81,6 +83,11 @@ func (sq *splitQueue) shouldQueue(
 		priority += ratio
 		shouldQ = true
 	}
+
+	if repl.loadSplitter.splitKey(now.GoTime()) != nil {
+		priority++
+		shouldQ = true
+	}
 	return
 }
 
@@ -104,6 +111,31 @@ func (sq *splitQueue) process(ctx context.Context, r *Replica, sysCfg config.Sys
 		return nil
 	}
 
+	// Next handle case of splitting due to load.
+	now := time.Unix(0, r.store.Clock().PhysicalNow())
+	if splitKey := r.loadSplitter.splitKey(now); splitKey != nil {
+		// The load statistics no longer apply once the range is split, and
+		// should not be used again if the key cannot be split at.
+		defer r.loadSplitter.reset()
+		if !splitKey.Equal(desc.StartKey) && desc.ContainsKey(splitKey) {
+			if _, validSplitKey, pErr := r.adminSplitWithDescriptor(
+				ctx,
+				roachpb.AdminSplitRequest{
+					Span: roachpb.Span{
+						Key: splitKey.AsRawKey(),
+					},
+					SplitKey: splitKey.AsRawKey(),
+				},
+				desc,
+			); pErr != nil {
+				return errors.Wrapf(pErr.GoError(), "unable to split %s by load at key %q", r, splitKey)
+			} else if validSplitKey {
+				log.Infof(ctx, "split %s by load at key %s", r, splitKey)
+				return nil
+			}
+		}
+	}
+
 	// Next handle case of splitting due to size. Note that we don't perform
 	// size-based splitting if maxBytes is 0 (happens in certain test
 	// situations).
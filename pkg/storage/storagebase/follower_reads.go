@@ -0,0 +1,84 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storagebase
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// FollowerReadsSafeDuration controls follower reads. When non-zero, the lease
+// holder of a range pushes the writes older than this duration above it, so
+// that AS OF SYSTEM TIME queries older than this duration (plus the maximum
+// clock offset) can be served by any replica of the range which applied the
+// writes up to their timestamp.
+var FollowerReadsSafeDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.follower_reads.safe_duration",
+	"if non-zero, writes older than this duration are pushed forward and AS OF SYSTEM TIME queries older than this duration "+
+		"plus the maximum clock offset may be served by the nearest up-to-date replica instead of the lease holder",
+	0)
+
+// ClosedTimestamp returns the timestamp at or below which the lease holder of
+// a range does not accept writes, given the current time. It is empty if
+// follower reads are disabled.
+func ClosedTimestamp(now hlc.Timestamp) hlc.Timestamp {
+	safeDuration := FollowerReadsSafeDuration.Get()
+	if safeDuration == 0 {
+		return hlc.Timestamp{}
+	}
+	return hlc.Timestamp{WallTime: now.WallTime - safeDuration.Nanoseconds()}
+}
+
+// CanServeFollowerRead returns true if the batch is an explicit historical
+// read (see roachpb.Header.FollowerRead) old enough to be served by a replica
+// which does not hold the range lease, provided that replica applied the
+// writes up to FollowerReadTimestamp(ba). The offset of the lease holder's
+// clock is accounted for with maxOffset. Only point and range reads qualify:
+// other read-only requests, such as exports for backups, and the reads of
+// schema changes are served by the lease holder.
+func CanServeFollowerRead(ba *roachpb.BatchRequest, now hlc.Timestamp, maxOffset time.Duration) bool {
+	if !ba.FollowerRead || ba.ReadConsistency == roachpb.INCONSISTENT {
+		return false
+	}
+	for _, union := range ba.Requests {
+		switch union.GetInner().(type) {
+		case *roachpb.GetRequest, *roachpb.ScanRequest, *roachpb.ReverseScanRequest:
+		default:
+			return false
+		}
+	}
+	closedTS := ClosedTimestamp(now)
+	if closedTS == (hlc.Timestamp{}) {
+		return false
+	}
+	readTS := FollowerReadTimestamp(ba)
+	if readTS == (hlc.Timestamp{}) {
+		return false
+	}
+	return !closedTS.Add(-maxOffset.Nanoseconds(), 0).Less(readTS)
+}
+
+// FollowerReadTimestamp returns the timestamp up to which a replica serving
+// the batch as a follower read must have applied the writes of the range.
+// Transactional reads must also take their uncertainty interval into account.
+func FollowerReadTimestamp(ba *roachpb.BatchRequest) hlc.Timestamp {
+	if ba.Txn != nil {
+		return ba.Txn.MaxTimestamp
+	}
+	return ba.Timestamp
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storagebase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCanServeFollowerRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	now := hlc.Timestamp{WallTime: int64(100 * time.Second)}
+	maxOffset := time.Second
+	ts := func(d time.Duration) hlc.Timestamp {
+		return now.Add(-d.Nanoseconds(), 0)
+	}
+	get := roachpb.NewGet(roachpb.Key("a"))
+	put := roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromString("b"))
+	export := &roachpb.ExportRequest{Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}}
+
+	testCases := []struct {
+		safeDuration time.Duration
+		readTS       hlc.Timestamp
+		txnMaxTS     hlc.Timestamp
+		req          roachpb.Request
+		implicit     bool
+		inconsistent bool
+		expected     bool
+	}{
+		// Follower reads are disabled by default.
+		{0, ts(time.Hour), hlc.Timestamp{}, get, false, false, false},
+		{10 * time.Second, ts(time.Hour), hlc.Timestamp{}, get, false, false, true},
+		{10 * time.Second, ts(11 * time.Second), hlc.Timestamp{}, get, false, false, true},
+		// The read must account for the clock offset.
+		{10 * time.Second, ts(10 * time.Second), hlc.Timestamp{}, get, false, false, false},
+		{10 * time.Second, ts(time.Second), hlc.Timestamp{}, get, false, false, false},
+		{10 * time.Second, hlc.Timestamp{}, hlc.Timestamp{}, get, false, false, false},
+		// Transactional reads use their uncertainty interval.
+		{10 * time.Second, ts(time.Hour), ts(time.Hour), get, false, false, true},
+		{10 * time.Second, ts(time.Hour), ts(time.Second), get, false, false, false},
+		// Only the point and range reads of explicit historical queries are
+		// follower reads.
+		{10 * time.Second, ts(time.Hour), hlc.Timestamp{}, put, false, false, false},
+		{10 * time.Second, ts(time.Hour), hlc.Timestamp{}, get, false, true, false},
+		{10 * time.Second, ts(time.Hour), hlc.Timestamp{}, export, false, false, false},
+		{10 * time.Second, ts(time.Hour), hlc.Timestamp{}, get, true, false, false},
+	}
+	for i, tc := range testCases {
+		func() {
+			defer settings.TestingSetDuration(&FollowerReadsSafeDuration, tc.safeDuration)()
+
+			var ba roachpb.BatchRequest
+			ba.Timestamp = tc.readTS
+			ba.FollowerRead = !tc.implicit
+			if tc.txnMaxTS != (hlc.Timestamp{}) {
+				ba.Txn = &roachpb.Transaction{MaxTimestamp: tc.txnMaxTS}
+			}
+			if tc.inconsistent {
+				ba.ReadConsistency = roachpb.INCONSISTENT
+			}
+			ba.Add(tc.req)
+			if actual := CanServeFollowerRead(&ba, now, maxOffset); actual != tc.expected {
+				t.Errorf("%d: expected %t, but got %t", i, tc.expected, actual)
+			}
+		}()
+	}
+}
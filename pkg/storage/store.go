@@ -449,6 +449,26 @@ type Store struct {
 	// being restarted.
 	rebalancesDisabled int32
 
+	// snapshotBandwidth rate limits the snapshots sent by the store.
+	snapshotBandwidth snapshotBandwidth
+
+	// diskSpaceState holds the DiskSpaceState of the store, updated along with
+	// the capacity metrics.
+	diskSpaceState int32
+
+	// problemRangeIDs holds the IDs of the unavailable ranges and of the
+	// corrupt replicas found by the last computation of the replication
+	// metrics.
+	problemRangeIDs struct {
+		syncutil.Mutex
+		unavailable []roachpb.RangeID
+		corrupt     []roachpb.RangeID
+	}
+
+	// contentionEvents logs the requests which had to push the transactions
+	// of conflicting intents.
+	contentionEvents contentionEventLog
+
 	// draining holds a bool which indicates whether this store is draining. See
 	// SetDraining() for a more detailed explanation of behavior changes.
 	//
@@ -1148,8 +1168,12 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 		s.cfg.Gossip.NodeID.Set(ctx, s.Ident.NodeID)
 	}
 
-	// Set the store ID for logging.
+	// Set the store ID for logging, as well as the directory of the store
+	// so that the logs of multi-store nodes can be filtered by device.
 	s.cfg.AmbientCtx.AddLogTagInt("s", int(s.StoreID()))
+	if dir := s.engine.Dir(); dir != "" {
+		s.cfg.AmbientCtx.AddLogTagStr("path", dir)
+	}
 
 	// Create ID allocators.
 	idAlloc, err := newIDAllocator(
@@ -2278,15 +2302,37 @@ func (s *Store) Attrs() roachpb.Attributes {
 	return s.engine.Attrs()
 }
 
+// ContentionEvents returns the most recent contention events recorded by the
+// store, oldest first.
+func (s *Store) ContentionEvents() []ContentionEvent {
+	return s.contentionEvents.get()
+}
+
 // Capacity returns the capacity of the underlying storage engine. Note that
 // this does not include reservations.
 func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	capacity, err := s.engine.Capacity()
-	if err == nil {
-		capacity.RangeCount = int32(s.ReplicaCount())
-		capacity.LeaseCount = int32(s.LeaseCount())
+	if err != nil {
+		return capacity, err
 	}
-	return capacity, err
+
+	now := s.cfg.Clock.Now()
+	var leaseCount int32
+	var totalQPS float64
+	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
+		if r.ownsValidLease(now) {
+			leaseCount++
+			if r.stats != nil {
+				qps, _ := r.stats.avgQPS()
+				totalQPS += qps
+			}
+		}
+		return true
+	})
+	capacity.RangeCount = int32(s.ReplicaCount())
+	capacity.LeaseCount = leaseCount
+	capacity.QueriesPerSecond = totalQPS
+	return capacity, nil
 }
 
 // Registry returns the store registry.
@@ -2413,6 +2459,9 @@ func (s *Store) Send(
 			return nil, roachpb.NewError(err)
 		}
 	}
+	if err := s.checkDiskSpaceForBatch(ba); err != nil {
+		return nil, roachpb.NewError(err)
+	}
 
 	if err := ba.SetActiveTimestamp(s.Clock().Now); err != nil {
 		return nil, roachpb.NewError(err)
@@ -2684,6 +2733,9 @@ func (s *Store) reserveSnapshot(
 		if atomic.LoadInt32(&s.rebalancesDisabled) == 1 {
 			return nil, nil
 		}
+		if s.DiskSpaceState() != DiskSpaceOK {
+			return nil, nil
+		}
 		select {
 		case sem <- struct{}{}:
 		case <-ctx.Done():
@@ -3222,13 +3274,13 @@ type SnapshotStorePool interface {
 	throttle(reason throttleReason, toStoreID roachpb.StoreID)
 }
 
-var rebalanceSnapshotRate = settings.RegisterByteSizeSetting(
+var rebalanceSnapshotRate = settings.RegisterPositiveByteSizeSetting(
 	"kv.snapshot_rebalance.max_rate",
-	"the rate limit (bytes/sec) to use for rebalance snapshots",
+	"the rate limit (bytes/sec) shared by the rebalance snapshots sent by a store",
 	envutil.EnvOrDefaultBytes("COCKROACH_PREEMPTIVE_SNAPSHOT_RATE", 2<<20))
-var recoverySnapshotRate = settings.RegisterByteSizeSetting(
+var recoverySnapshotRate = settings.RegisterPositiveByteSizeSetting(
 	"kv.snapshot_recovery.max_rate",
-	"the rate limit (bytes/sec) to use for recovery snapshots",
+	"the rate limit (bytes/sec) shared by the recovery snapshots sent by a store",
 	envutil.EnvOrDefaultBytes("COCKROACH_RAFT_SNAPSHOT_RATE", 8<<20))
 
 func snapshotRateLimit(priority SnapshotRequest_Priority) (rate.Limit, error) {
@@ -3242,11 +3294,13 @@ func snapshotRateLimit(priority SnapshotRequest_Priority) (rate.Limit, error) {
 	}
 }
 
-// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream.
+// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream, at
+// the rate allowed by the bandwidth of the sending store.
 func sendSnapshot(
 	ctx context.Context,
 	stream OutgoingSnapshotStream,
 	storePool SnapshotStorePool,
+	bandwidth *snapshotBandwidth,
 	header SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
 	newBatch func() engine.Batch,
@@ -3288,22 +3342,11 @@ func sendSnapshot(
 			to, resp.Status)
 	}
 
-	// The size of batches to send. This is the granularity of rate limiting.
-	const batchSize = 256 << 10 // 256 KB
 	targetRate, err := snapshotRateLimit(header.Priority)
 	if err != nil {
 		return errors.Wrapf(err, "%s", to)
 	}
 
-	// Convert the bytes/sec rate limit to batches/sec.
-	//
-	// TODO(peter): Using bytes/sec for rate limiting seems more natural but has
-	// practical difficulties. We either need to use a very large burst size
-	// which seems to disable the rate limiting, or call WaitN in smaller than
-	// burst size chunks which caused excessive slowness in testing. Would be
-	// nice to figure this out, but the batches/sec rate limit works for now.
-	limiter := rate.NewLimiter(targetRate/batchSize, 1 /* burst size */)
-
 	// Determine the unreplicated key prefix so we can drop any
 	// unreplicated keys from the snapshot.
 	unreplicatedPrefix := keys.MakeRangeIDUnreplicatedPrefix(header.State.Desc.RangeID)
@@ -3335,8 +3378,8 @@ func sendSnapshot(
 			return err
 		}
 
-		if len(b.Repr()) >= batchSize {
-			if err := limiter.WaitN(ctx, 1); err != nil {
+		if size := len(b.Repr()); size >= snapshotBatchSize {
+			if err := bandwidth.acquire(ctx, header.Priority, int64(size)); err != nil {
 				return err
 			}
 			if err := sendBatch(stream, b); err != nil {
@@ -3349,7 +3392,7 @@ func sendSnapshot(
 		}
 	}
 	if b != nil {
-		if err := limiter.WaitN(ctx, 1); err != nil {
+		if err := bandwidth.acquire(ctx, header.Priority, int64(len(b.Repr()))); err != nil {
 			return err
 		}
 		if err := sendBatch(stream, b); err != nil {
@@ -3514,6 +3557,7 @@ func (s *Store) processRaft() {
 
 	s.raftTickLoop()
 	s.startCoalescedHeartbeatsLoop()
+	s.startClosedTimestampLoop()
 }
 
 func (s *Store) raftTickLoop() {
@@ -3841,13 +3885,14 @@ func (s *Store) canApplySnapshotLocked(
 	return placeholder, nil
 }
 
-func (s *Store) updateCapacityGauges() error {
+func (s *Store) updateCapacityGauges(ctx context.Context) error {
 	desc, err := s.Descriptor()
 	if err != nil {
 		return err
 	}
 	s.metrics.Capacity.Update(desc.Capacity.Capacity)
 	s.metrics.Available.Update(desc.Capacity.Available)
+	s.updateDiskSpaceState(ctx, desc.Capacity)
 
 	return nil
 }
@@ -3877,6 +3922,9 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		underreplicatedRangeCount int64
 		behindCount               int64
 		selfBehindCount           int64
+
+		unavailableRangeIDs []roachpb.RangeID
+		corruptRangeIDs     []roachpb.RangeID
 	)
 
 	timestamp := s.cfg.Clock.Now()
@@ -3910,16 +3958,25 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 			rangeCount++
 			if metrics.Unavailable {
 				unavailableRangeCount++
+				unavailableRangeIDs = append(unavailableRangeIDs, rep.RangeID)
 			}
 			if metrics.Underreplicated {
 				underreplicatedRangeCount++
 			}
 		}
+		if metrics.Corrupt {
+			corruptRangeIDs = append(corruptRangeIDs, rep.RangeID)
+		}
 		behindCount += metrics.BehindCount
 		selfBehindCount += metrics.SelfBehindCount
 		return true // more
 	})
 
+	s.problemRangeIDs.Lock()
+	s.problemRangeIDs.unavailable = unavailableRangeIDs
+	s.problemRangeIDs.corrupt = corruptRangeIDs
+	s.problemRangeIDs.Unlock()
+
 	s.metrics.RaftLeaderCount.Update(raftLeaderCount)
 	s.metrics.RaftLeaderNotLeaseHolderCount.Update(raftLeaderNotLeaseHolderCount)
 	s.metrics.LeaseHolderCount.Update(leaseHolderCount)
@@ -4009,12 +4066,20 @@ func (s *Store) updateCommandQueueGauges() error {
 	return nil
 }
 
+// ProblemRangeIDs returns the IDs of the unavailable ranges and of the corrupt
+// replicas of the store as of the last computation of its metrics.
+func (s *Store) ProblemRangeIDs() (unavailable, corrupt []roachpb.RangeID) {
+	s.problemRangeIDs.Lock()
+	defer s.problemRangeIDs.Unlock()
+	return s.problemRangeIDs.unavailable, s.problemRangeIDs.corrupt
+}
+
 // ComputeMetrics immediately computes the current value of store metrics which
 // cannot be computed incrementally. This method should be invoked periodically
 // by a higher-level system which records store metrics.
 func (s *Store) ComputeMetrics(ctx context.Context, tick int) error {
 	ctx = s.AnnotateCtx(ctx)
-	if err := s.updateCapacityGauges(); err != nil {
+	if err := s.updateCapacityGauges(ctx); err != nil {
 		return err
 	}
 	if err := s.updateReplicationGauges(ctx); err != nil {
@@ -4065,6 +4130,48 @@ func (s *Store) ComputeStatsForKeySpan(startKey, endKey roachpb.RKey) (enginepb.
 	return output, count
 }
 
+// TableStats holds the statistics of the ranges of a table whose lease is
+// held by a store.
+type TableStats struct {
+	enginepb.MVCCStats
+	// QPS is the decayed number of requests per second received by the
+	// ranges.
+	QPS float64
+}
+
+// ComputeTableStats aggregates, by table ID, the statistics of the ranges
+// whose lease is held by this store, so that the ranges of a table are
+// accounted for once across the cluster. Ranges are attributed to the
+// table containing their start key; ranges outside of the table key space
+// are ignored.
+func (s *Store) ComputeTableStats() map[uint32]*TableStats {
+	tables := make(map[uint32]*TableStats)
+	now := s.cfg.Clock.Now()
+	newStoreReplicaVisitor(s).Visit(func(repl *Replica) bool {
+		if !repl.ownsValidLease(now) {
+			return true // continue
+		}
+		_, tableID, err := keys.DecodeTablePrefix(repl.Desc().StartKey.AsRawKey())
+		if err != nil {
+			return true // continue
+		}
+		stats, ok := tables[uint32(tableID)]
+		if !ok {
+			stats = &TableStats{}
+			tables[uint32(tableID)] = stats
+		}
+		stats.Add(repl.GetMVCCStats())
+		if repl.stats != nil {
+			qps, _ := repl.stats.perLocalityDecayingQPS()
+			for _, q := range qps {
+				stats.QPS += q
+			}
+		}
+		return true
+	})
+	return tables
+}
+
 // GetTempPrefix returns a path where temporary files and directories can be
 // allocated.
 func (s *Store) GetTempPrefix() string {
@@ -0,0 +1,158 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func validateFreeSpaceFraction(v float64) error {
+	if v < 0 || v >= 1 {
+		return errors.Errorf("cannot set free space fraction to %f, must be in [0, 1)", v)
+	}
+	return nil
+}
+
+// The free space thresholds keep a store from filling its disk, which RocksDB
+// doesn't recover from gracefully. Below the first threshold, the store stops
+// accepting the snapshots of rebalancing; below the second one, it rejects
+// the writes to user data of the ranges it holds the lease of. The writes to
+// system ranges and the deletions are still accepted so that the cluster keeps
+// working and the data can be deleted.
+
+var minFreeSpaceForSnapshots = settings.RegisterValidatedFloatSetting(
+	"kv.store.min_free_space_fraction_for_snapshots",
+	"fraction of free disk space below which a store declines rebalancing snapshots (0 to disable)",
+	0.05,
+	validateFreeSpaceFraction,
+)
+
+var minFreeSpaceForWrites = settings.RegisterValidatedFloatSetting(
+	"kv.store.min_free_space_fraction_for_writes",
+	"fraction of free disk space below which a store rejects writes to user data (0 to disable)",
+	0.01,
+	validateFreeSpaceFraction,
+)
+
+// DiskSpaceState describes the restrictions put on a store because of the
+// free space left on its disk.
+type DiskSpaceState int32
+
+const (
+	// DiskSpaceOK is the state of stores with enough free space.
+	DiskSpaceOK DiskSpaceState = iota
+	// DiskSpaceLow is the state of stores declining rebalancing snapshots, see
+	// kv.store.min_free_space_fraction_for_snapshots.
+	DiskSpaceLow
+	// DiskSpaceFull is the state of stores rejecting writes, see
+	// kv.store.min_free_space_fraction_for_writes.
+	DiskSpaceFull
+)
+
+func (s DiskSpaceState) String() string {
+	switch s {
+	case DiskSpaceOK:
+		return "ok"
+	case DiskSpaceLow:
+		return "low"
+	case DiskSpaceFull:
+		return "full"
+	}
+	return "unknown"
+}
+
+// computeDiskSpaceState returns the state of a store with the given capacity.
+func computeDiskSpaceState(capacity roachpb.StoreCapacity) DiskSpaceState {
+	if capacity.Capacity <= 0 {
+		// The capacity of some in-memory engines is unknown.
+		return DiskSpaceOK
+	}
+	free := float64(capacity.Available) / float64(capacity.Capacity)
+	if free < minFreeSpaceForWrites.Get() {
+		return DiskSpaceFull
+	}
+	if free < minFreeSpaceForSnapshots.Get() {
+		return DiskSpaceLow
+	}
+	return DiskSpaceOK
+}
+
+// DiskSpaceState returns the state of the store as of the last computation of
+// its metrics.
+func (s *Store) DiskSpaceState() DiskSpaceState {
+	return DiskSpaceState(atomic.LoadInt32(&s.diskSpaceState))
+}
+
+// updateDiskSpaceState updates the state of the store from its capacity,
+// logging the transitions.
+func (s *Store) updateDiskSpaceState(ctx context.Context, capacity roachpb.StoreCapacity) {
+	state := computeDiskSpaceState(capacity)
+	old := DiskSpaceState(atomic.SwapInt32(&s.diskSpaceState, int32(state)))
+	s.metrics.DiskSpaceState.Update(int64(state))
+	if state == old {
+		return
+	}
+	s.metrics.DiskSpaceStateChanges.Inc(1)
+	if state == DiskSpaceOK {
+		log.Infof(ctx, "disk space state changed from %s to %s; %s available of %s",
+			old, state, humanizeutil.IBytes(capacity.Available), humanizeutil.IBytes(capacity.Capacity))
+	} else {
+		log.Warningf(ctx, "disk space state changed from %s to %s; %s available of %s",
+			old, state, humanizeutil.IBytes(capacity.Available), humanizeutil.IBytes(capacity.Capacity))
+	}
+}
+
+// checkDiskSpaceForBatch returns an error if the batch writes user data while
+// the store is full. Batches which only delete data are accepted, as deleting
+// data is the way out of the full state.
+func (s *Store) checkDiskSpaceForBatch(ba roachpb.BatchRequest) error {
+	if s.DiskSpaceState() != DiskSpaceFull || !ba.IsTransactionWrite() || onlyDeletes(ba) {
+		return nil
+	}
+	rs, err := keys.Range(ba)
+	if err != nil {
+		return err
+	}
+	if bytes.Compare(rs.Key, keys.UserTableDataMin) < 0 {
+		return nil
+	}
+	return errors.Errorf("%s is out of disk space and rejects writes "+
+		"(see kv.store.min_free_space_fraction_for_writes)", s)
+}
+
+// onlyDeletes returns true if all the transactional writes of the batch are
+// deletions.
+func onlyDeletes(ba roachpb.BatchRequest) bool {
+	for _, union := range ba.Requests {
+		switch args := union.GetInner(); args.(type) {
+		case *roachpb.DeleteRequest, *roachpb.DeleteRangeRequest:
+		default:
+			if roachpb.IsTransactionWrite(args) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+func TestComputeDiskSpaceState(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		capacity, available int64
+		expected            DiskSpaceState
+	}{
+		{0, 0, DiskSpaceOK},
+		{100, 100, DiskSpaceOK},
+		{100, 5, DiskSpaceOK},
+		{100, 4, DiskSpaceLow},
+		{100, 1, DiskSpaceLow},
+		{100, 0, DiskSpaceFull},
+		{1000, 9, DiskSpaceFull},
+	}
+	for _, tc := range testCases {
+		capacity := roachpb.StoreCapacity{Capacity: tc.capacity, Available: tc.available}
+		if state := computeDiskSpaceState(capacity); state != tc.expected {
+			t.Errorf("%d/%d available: expected state %s, got %s",
+				tc.available, tc.capacity, tc.expected, state)
+		}
+	}
+}
+
+func TestStoreDiskSpaceState(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, _ := createTestStore(t, stopper)
+	ctx := context.Background()
+
+	userKey := append(roachpb.Key(nil), keys.UserTableDataMin...)
+	userKey = append(userKey, 'a')
+	put := func(key roachpb.Key) *roachpb.Error {
+		pArgs := putArgs(key, []byte("value"))
+		_, pErr := client.SendWrapped(ctx, store.testSender(), &pArgs)
+		return pErr
+	}
+	reserve := func() bool {
+		cleanup, err := store.reserveSnapshot(ctx, &SnapshotRequest_Header{CanDecline: true, RangeSize: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cleanup == nil {
+			return false
+		}
+		cleanup()
+		return true
+	}
+
+	// A store with enough space accepts writes and snapshots.
+	store.updateDiskSpaceState(ctx, roachpb.StoreCapacity{Capacity: 100, Available: 50})
+	if pErr := put(userKey); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if !reserve() {
+		t.Fatal("expected the snapshot to be accepted")
+	}
+
+	// A store low on space declines snapshots.
+	store.updateDiskSpaceState(ctx, roachpb.StoreCapacity{Capacity: 100, Available: 2})
+	if state := store.DiskSpaceState(); state != DiskSpaceLow {
+		t.Fatalf("expected state %s, got %s", DiskSpaceLow, state)
+	}
+	if reserve() {
+		t.Fatal("expected the snapshot to be declined")
+	}
+	if pErr := put(userKey); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// A full store rejects writes to user data only.
+	store.updateDiskSpaceState(ctx, roachpb.StoreCapacity{Capacity: 100, Available: 0})
+	if pErr := put(userKey); !testutils.IsPError(pErr, "out of disk space") {
+		t.Fatalf("expected the write to be rejected, got %v", pErr)
+	}
+	if pErr := put(roachpb.Key("a")); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// Deletions are accepted, so that space can be reclaimed.
+	dArgs := deleteArgs(userKey)
+	if _, pErr := client.SendWrapped(ctx, store.testSender(), &dArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	drArgs := roachpb.DeleteRangeRequest{
+		Span: roachpb.Span{Key: userKey, EndKey: userKey.PrefixEnd()},
+	}
+	if _, pErr := client.SendWrapped(ctx, store.testSender(), &drArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// Batches mixing deletions and other writes are not.
+	var ba roachpb.BatchRequest
+	ba.Add(&dArgs)
+	pArgs := putArgs(userKey, []byte("value"))
+	ba.Add(&pArgs)
+	if _, pErr := store.testSender().Send(ctx, ba); !testutils.IsPError(pErr, "out of disk space") {
+		t.Fatalf("expected the batch to be rejected, got %v", pErr)
+	}
+	gArgs := getArgs(userKey)
+	if _, pErr := client.SendWrapped(ctx, store.testSender(), &gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	if changes := store.metrics.DiskSpaceStateChanges.Count(); changes != 2 {
+		t.Errorf("expected 2 state changes, got %d", changes)
+	}
+}
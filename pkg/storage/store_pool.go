@@ -69,6 +69,8 @@ const (
 	nodeStatusUnknown
 	// The node is considered live.
 	nodeStatusLive
+	// The node is live but is being decommissioned.
+	nodeStatusDecommissioning
 )
 
 // A NodeLivenessFunc accepts a node ID, current time and threshold before
@@ -82,7 +84,10 @@ func MakeStorePoolNodeLivenessFunc(nodeLiveness *NodeLiveness) NodeLivenessFunc
 	return func(nodeID roachpb.NodeID, now time.Time, threshold time.Duration) nodeStatus {
 		liveness, err := nodeLiveness.GetLiveness(nodeID)
 		if err == nil && !liveness.Draining {
-			if liveness.isLive(hlc.Timestamp{WallTime: now.UnixNano()}, nodeLiveness.clock.MaxOffset()) {
+			if liveness.IsLive(hlc.Timestamp{WallTime: now.UnixNano()}, nodeLiveness.clock.MaxOffset()) {
+				if liveness.Decommissioning {
+					return nodeStatusDecommissioning
+				}
 				return nodeStatusLive
 			}
 			deadAsOf := liveness.Expiration.GoTime().Add(threshold)
@@ -131,6 +136,10 @@ const (
 	storeStatusReplicaCorrupted
 	// The store is alive and available.
 	storeStatusAvailable
+	// The store is alive but its node is being decommissioned. Its replicas
+	// count towards quorum but are moved to other stores, and no new replicas
+	// are placed on it.
+	storeStatusDecommissioning
 )
 
 // status returns the current status of the store, including whether
@@ -159,6 +168,8 @@ func (sd *storeDetail) status(
 		return storeStatusDead
 	case nodeStatusUnknown:
 		return storeStatusUnknown
+	case nodeStatusDecommissioning:
+		return storeStatusDecommissioning
 	}
 
 	if sd.isThrottled(now) {
@@ -378,15 +389,34 @@ func (sp *StorePool) liveAndDeadReplicas(
 				// Otherwise, consider the store live.
 				liveReplicas = append(liveReplicas, repl)
 			}
-		case storeStatusAvailable, storeStatusThrottled:
-			// We count both available and throttled stores to be live for the
-			// purpose of computing quorum.
+		case storeStatusAvailable, storeStatusThrottled, storeStatusDecommissioning:
+			// We count available, throttled and decommissioning stores to be live
+			// for the purpose of computing quorum.
 			liveReplicas = append(liveReplicas, repl)
 		}
 	}
 	return
 }
 
+// decommissioningReplicas filters out replicas on stores whose nodes are
+// being decommissioned, which need to be moved elsewhere.
+func (sp *StorePool) decommissioningReplicas(
+	rangeID roachpb.RangeID, repls []roachpb.ReplicaDescriptor,
+) (decommissioningReplicas []roachpb.ReplicaDescriptor) {
+	sp.detailsMu.Lock()
+	defer sp.detailsMu.Unlock()
+
+	now := sp.clock.PhysicalTime()
+	for _, repl := range repls {
+		detail := sp.getStoreDetailLocked(repl.StoreID)
+		switch detail.status(now, sp.timeUntilStoreDead.Get(), rangeID, sp.nodeLivenessFn) {
+		case storeStatusDecommissioning:
+			decommissioningReplicas = append(decommissioningReplicas, repl)
+		}
+	}
+	return
+}
+
 // stat provides a running sample size and running stats.
 type stat struct {
 	n, mean, s float64
@@ -416,6 +446,10 @@ type StoreList struct {
 	// candidateLeases tracks range lease stats for stores that are eligible to
 	// be rebalance targets.
 	candidateLeases stat
+
+	// candidateQueriesPerSecond tracks queries-per-second stats for stores that
+	// are eligible to be rebalance targets.
+	candidateQueriesPerSecond stat
 }
 
 // Generates a new store list based on the passed in descriptors. It will
@@ -427,18 +461,19 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 			sl.candidateCount.update(float64(desc.Capacity.RangeCount))
 		}
 		sl.candidateLeases.update(float64(desc.Capacity.LeaseCount))
+		sl.candidateQueriesPerSecond.update(desc.Capacity.QueriesPerSecond)
 	}
 	return sl
 }
 
 func (sl StoreList) String() string {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "  candidate: avg-ranges=%v avg-leases=%v\n",
-		sl.candidateCount.mean, sl.candidateLeases.mean)
+	fmt.Fprintf(&buf, "  candidate: avg-ranges=%v avg-leases=%v avg-qps=%.2f\n",
+		sl.candidateCount.mean, sl.candidateLeases.mean, sl.candidateQueriesPerSecond.mean)
 	for _, desc := range sl.stores {
-		fmt.Fprintf(&buf, "  %d: ranges=%d leases=%d fraction-used=%.2f\n",
-			desc.StoreID, desc.Capacity.RangeCount,
-			desc.Capacity.LeaseCount, desc.Capacity.FractionUsed())
+		fmt.Fprintf(&buf, "  %d: ranges=%d leases=%d qps=%.2f fraction-used=%.2f\n",
+			desc.StoreID, desc.Capacity.RangeCount, desc.Capacity.LeaseCount,
+			desc.Capacity.QueriesPerSecond, desc.Capacity.FractionUsed())
 	}
 	return buf.String()
 }
@@ -540,7 +575,7 @@ func (sp *StorePool) getStoreListFromIDsRLocked(
 		case storeStatusAvailable:
 			aliveStoreCount++
 			storeDescriptors = append(storeDescriptors, *detail.desc)
-		case storeStatusDead, storeStatusUnknown:
+		case storeStatusDead, storeStatusUnknown, storeStatusDecommissioning:
 			// Do nothing; this node cannot be used.
 		default:
 			panic(fmt.Sprintf("unknown store status: %d", s))
@@ -29,6 +29,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 
@@ -77,6 +78,20 @@ type TestServerInterface interface {
 	// Clock returns the clock used by the TestServer.
 	Clock() *hlc.Clock
 
+	// ManualClock returns the manual clock set in TestServerArgs.Clock, or nil
+	// if the server uses the wall clock.
+	ManualClock() *hlc.ManualClock
+
+	// AdvanceClock moves the manual clock of the server forward by the given
+	// duration. It returns an error if the server doesn't use a manual clock.
+	AdvanceClock(d time.Duration) error
+
+	// ExpireLeases moves the manual clock of the server past the expiration of
+	// all the range leases and node liveness records, so that tests can
+	// exercise lease expiry without sleeping. It returns an error if the server
+	// doesn't use a manual clock.
+	ExpireLeases() error
+
 	// DistSender returns the DistSender used by the TestServer.
 	DistSender() *kv.DistSender
 
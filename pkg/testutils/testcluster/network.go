@@ -0,0 +1,199 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testcluster
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// link identifies the traffic sent from one server of the cluster to another,
+// by their indexes.
+type link struct {
+	from, to int
+}
+
+// linkFault describes the fault injected on a link.
+type linkFault struct {
+	// drop is set if no traffic flows through the link.
+	drop bool
+	// latency is added to every read and write of the traffic on the link.
+	latency time.Duration
+}
+
+// network keeps track of the faults injected between the servers of a
+// TestCluster. It wraps the RPC connections dialed by the servers so that the
+// traffic between two servers is held back while their link is dropped and is
+// delayed by the latency of their link.
+//
+// Every connection is wrapped by the server which dialed it: its writes are
+// the traffic from that server to the remote one and its reads the traffic
+// in the other direction, which allows for one-way faults.
+type network struct {
+	mu struct {
+		syncutil.Mutex
+		// addrs maps the serving addresses of the servers to their indexes.
+		addrs  map[string]int
+		faults map[link]linkFault
+		// changed is closed and replaced whenever the faults change, to wake
+		// up the connections waiting for a link to be restored.
+		changed chan struct{}
+	}
+}
+
+func newNetwork() *network {
+	n := &network{}
+	n.mu.addrs = make(map[string]int)
+	n.mu.faults = make(map[link]linkFault)
+	n.mu.changed = make(chan struct{})
+	return n
+}
+
+// addServer records the serving address of the server with the given index.
+// Faults only apply to connections dialed to registered servers.
+func (n *network) addServer(idx int, addr string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.mu.addrs[addr] = idx
+}
+
+// update applies fn to the fault of the link and wakes up the waiting
+// connections.
+func (n *network) update(l link, fn func(*linkFault)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f := n.mu.faults[l]
+	fn(&f)
+	if f == (linkFault{}) {
+		delete(n.mu.faults, l)
+	} else {
+		n.mu.faults[l] = f
+	}
+	n.notifyLocked()
+}
+
+// heal removes all the faults.
+func (n *network) heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.mu.faults = make(map[link]linkFault)
+	n.notifyLocked()
+}
+
+func (n *network) notifyLocked() {
+	close(n.mu.changed)
+	n.mu.changed = make(chan struct{})
+}
+
+// fault returns the fault of the link along with a channel which is closed
+// the next time the faults change.
+func (n *network) fault(l link) (linkFault, <-chan struct{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.mu.faults[l], n.mu.changed
+}
+
+// wrapConn returns a function suitable for the rpc.ContextTestingKnobs of the
+// server with the given index.
+func (n *network) wrapConn(idx int) func(string, net.Conn) (net.Conn, error) {
+	return func(target string, conn net.Conn) (net.Conn, error) {
+		n.mu.Lock()
+		remote, ok := n.mu.addrs[target]
+		n.mu.Unlock()
+		if !ok || remote == idx {
+			return conn, nil
+		}
+		c := &faultyConn{
+			Conn:    conn,
+			network: n,
+			out:     link{from: idx, to: remote},
+			in:      link{from: remote, to: idx},
+			closed:  make(chan struct{}),
+		}
+		// Connecting requires traffic in both directions.
+		for _, l := range []link{c.out, c.in} {
+			if f, _ := n.fault(l); f.drop {
+				return nil, errors.Errorf("injected network partition between servers %d and %d",
+					l.from, l.to)
+			}
+		}
+		return c, nil
+	}
+}
+
+// faultyConn is a connection subject to the faults of the network.
+type faultyConn struct {
+	net.Conn
+	network *network
+	// out is the link of the writes, in the link of the reads.
+	out, in link
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// wait blocks until the link isn't dropped anymore and then sleeps for its
+// latency. It returns an error if the connection is closed in the meantime.
+func (c *faultyConn) wait(l link) error {
+	for {
+		f, changed := c.network.fault(l)
+		if !f.drop {
+			if f.latency > 0 {
+				select {
+				case <-time.After(f.latency):
+				case <-c.closed:
+					return errors.New("connection closed")
+				}
+			}
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-c.closed:
+			return errors.New("connection closed")
+		}
+	}
+}
+
+// Write is part of the net.Conn interface.
+func (c *faultyConn) Write(b []byte) (int, error) {
+	if err := c.wait(c.out); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
+
+// Read is part of the net.Conn interface. The data read is held back until
+// the link of the reads allows it through.
+func (c *faultyConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		if waitErr := c.wait(c.in); waitErr != nil {
+			return 0, waitErr
+		}
+	}
+	return n, err
+}
+
+// Close is part of the net.Conn interface.
+func (c *faultyConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testcluster
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestNetworkFaults(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	n := newNetwork()
+	n.addServer(0, "a")
+	n.addServer(1, "b")
+
+	// Connections to unknown servers and to self are left alone.
+	local, _ := net.Pipe()
+	if conn, err := n.wrapConn(0)("c", local); err != nil || conn != local {
+		t.Fatalf("expected the connection to be left alone, got %v, %v", conn, err)
+	}
+	if conn, err := n.wrapConn(0)("a", local); err != nil || conn != local {
+		t.Fatalf("expected the connection to be left alone, got %v, %v", conn, err)
+	}
+
+	local, remote := net.Pipe()
+	conn, err := n.wrapConn(0)("b", local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	write := func() <-chan error {
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := conn.Write([]byte("x"))
+			errCh <- err
+		}()
+		return errCh
+	}
+	read := func() {
+		if _, err := remote.Read(make([]byte, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The writes are held back while the link from 0 to 1 is dropped.
+	n.update(link{from: 0, to: 1}, func(f *linkFault) { f.drop = true })
+	errCh := write()
+	select {
+	case err := <-errCh:
+		t.Fatalf("expected the write to block, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if _, err := n.wrapConn(1)("a", local); err == nil {
+		t.Fatal("expected dialing through a dropped link to fail")
+	}
+	n.heal()
+	read()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	// Dropping the link in the other direction doesn't affect the writes.
+	n.update(link{from: 1, to: 0}, func(f *linkFault) { f.drop = true })
+	errCh = write()
+	read()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	n.heal()
+
+	// The latency of the link delays the writes.
+	const latency = 20 * time.Millisecond
+	n.update(link{from: 0, to: 1}, func(f *linkFault) { f.latency = latency })
+	start := timeutil.Now()
+	errCh = write()
+	read()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := timeutil.Since(start); elapsed < latency {
+		t.Fatalf("expected the write to be delayed by %s, took %s", latency, elapsed)
+	}
+
+	// Closing the connection unblocks the held back writes.
+	n.update(link{from: 0, to: 1}, func(f *linkFault) { f.drop = true })
+	errCh = write()
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err == nil {
+		t.Fatal("expected the write to fail after the connection was closed")
+	}
+}
@@ -18,6 +18,7 @@ package testcluster
 
 import (
 	gosql "database/sql"
+	"net"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -49,6 +51,7 @@ type TestCluster struct {
 	Conns           []*gosql.DB
 	stopper         *stop.Stopper
 	replicationMode base.TestClusterReplicationMode
+	network         *network
 	mu              struct {
 		syncutil.Mutex
 		serverStoppers []*stop.Stopper
@@ -142,6 +145,7 @@ func StartTestCluster(t testing.TB, nodes int, args base.TestClusterArgs) *TestC
 	tc := &TestCluster{
 		stopper:         stop.NewStopper(),
 		replicationMode: args.ReplicationMode,
+		network:         newNetwork(),
 	}
 	tc.stopper = stop.NewStopper()
 
@@ -245,7 +249,29 @@ func (tc *TestCluster) doAddServer(t testing.TB, serverArgs base.TestServerArgs)
 		serverArgs.Knobs.Store = &stkCopy
 	}
 
+	// Route the connections dialed by the server through the cluster's network
+	// so that faults can be injected between servers.
+	idx := len(tc.Servers)
+	var rpcKnobs rpc.ContextTestingKnobs
+	if knobs := serverArgs.Knobs.RPC; knobs != nil {
+		rpcKnobs = *knobs.(*rpc.ContextTestingKnobs)
+	}
+	wrapConn := tc.network.wrapConn(idx)
+	if userWrapConn := rpcKnobs.WrapConn; userWrapConn != nil {
+		rpcKnobs.WrapConn = func(target string, conn net.Conn) (net.Conn, error) {
+			conn, err := userWrapConn(target, conn)
+			if err != nil {
+				return nil, err
+			}
+			return wrapConn(target, conn)
+		}
+	} else {
+		rpcKnobs.WrapConn = wrapConn
+	}
+	serverArgs.Knobs.RPC = &rpcKnobs
+
 	s, conn, _ := serverutils.StartServer(t, serverArgs)
+	tc.network.addServer(idx, s.ServingAddr())
 	tc.Servers = append(tc.Servers, s.(*server.TestServer))
 	tc.Conns = append(tc.Conns, conn)
 	tc.mu.Lock()
@@ -254,6 +280,42 @@ func (tc *TestCluster) doAddServer(t testing.TB, serverArgs base.TestServerArgs)
 	return nil
 }
 
+// DropTraffic holds back all the traffic sent from the server with index
+// from to the server with index to, until it is restored by RestoreTraffic or
+// HealNetwork. The traffic in the other direction is unaffected.
+func (tc *TestCluster) DropTraffic(from, to int) {
+	tc.network.update(link{from: from, to: to}, func(f *linkFault) { f.drop = true })
+}
+
+// RestoreTraffic lets the traffic from the server with index from to the
+// server with index to flow again.
+func (tc *TestCluster) RestoreTraffic(from, to int) {
+	tc.network.update(link{from: from, to: to}, func(f *linkFault) { f.drop = false })
+}
+
+// PartitionServers drops the traffic between the two groups of servers, in
+// both directions. The servers are identified by their indexes.
+func (tc *TestCluster) PartitionServers(group1, group2 []int) {
+	for _, i := range group1 {
+		for _, j := range group2 {
+			tc.DropTraffic(i, j)
+			tc.DropTraffic(j, i)
+		}
+	}
+}
+
+// InjectLatency delays the traffic sent from the server with index from to
+// the server with index to by the given latency. A zero latency removes the
+// injected latency.
+func (tc *TestCluster) InjectLatency(from, to int, latency time.Duration) {
+	tc.network.update(link{from: from, to: to}, func(f *linkFault) { f.latency = latency })
+}
+
+// HealNetwork removes all the faults injected between the servers.
+func (tc *TestCluster) HealNetwork() {
+	tc.network.heal()
+}
+
 // WaitForStores waits for all of the store descriptors to be gossiped. Servers
 // other than the first "bootstrap" their stores asynchronously, but we'd like
 // to wait for all of the stores to be initialized before returning the
@@ -24,22 +24,42 @@ export const DROP_INDEX = "drop_index";
 export const CREATE_VIEW = "create_view";
 // Recorded when a view is dropped.
 export const DROP_VIEW = "drop_view";
+// Recorded when a sequence is created.
+export const CREATE_SEQUENCE = "create_sequence";
+// Recorded when a sequence is altered.
+export const ALTER_SEQUENCE = "alter_sequence";
+// Recorded when a sequence is dropped.
+export const DROP_SEQUENCE = "drop_sequence";
 // Recorded when an in-progress schema change encounters a problem and is
 // reversed.
 export const REVERSE_SCHEMA_CHANGE = "reverse_schema_change";
 // Recorded when a previously initiated schema change has completed.
 export const FINISH_SCHEMA_CHANGE = "finish_schema_change";
+// Recorded when a cluster setting is changed.
+export const SET_CLUSTER_SETTING = "set_cluster_setting";
 // Recorded when a node joins the cluster.
 export const NODE_JOIN = "node_join";
 // Recorded when an existing node rejoins the cluster after being offline.
 export const NODE_RESTART = "node_restart";
+// Recorded when a store starts or stops declining snapshots or rejecting
+// writes because of its free disk space.
+export const STORE_DISK_SPACE = "store_disk_space";
+// Recorded when ranges of a store become unavailable.
+export const RANGE_UNAVAILABLE = "range_unavailable";
+// Recorded when replicas of a store are found corrupt.
+export const REPLICA_CORRUPTION = "replica_corruption";
+// Recorded when a certificate of a node is about to expire.
+export const CERTIFICATE_EXPIRY = "certificate_expiry";
 
 // Node Event Types
-export const nodeEvents = [NODE_JOIN, NODE_RESTART];
+export const nodeEvents = [NODE_JOIN, NODE_RESTART, STORE_DISK_SPACE, RANGE_UNAVAILABLE,
+  REPLICA_CORRUPTION, CERTIFICATE_EXPIRY];
 export const databaseEvents = [CREATE_DATABASE, DROP_DATABASE];
 export const tableEvents = [CREATE_TABLE, DROP_TABLE, ALTER_TABLE, CREATE_INDEX,
-  DROP_INDEX, CREATE_VIEW, DROP_VIEW, REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE];
-export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents];
+  DROP_INDEX, CREATE_VIEW, DROP_VIEW, CREATE_SEQUENCE, ALTER_SEQUENCE, DROP_SEQUENCE,
+  REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE];
+export const settingsEvents = [SET_CLUSTER_SETTING];
+export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents];
 
 interface EventSet {
   [key: string]: number;
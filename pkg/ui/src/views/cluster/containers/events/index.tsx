@@ -47,12 +47,21 @@ const s = (v: {}) => JSON.stringify(v, undefined, 2);
 
 export function getEventInfo(e: Event$Properties): SimplifiedEvent {
   const info: {
+    CertificateType: string,
     DatabaseName: string,
     DroppedTables: string[],
+    ExpirationTime: string,
     IndexName: string,
     MutationID: string,
+    NewState: string,
+    OldState: string,
+    RangeIDs: number[],
+    SequenceName: string,
+    SettingName: string,
+    StoreID: string,
     TableName: string,
     User: string,
+    Value: string,
     ViewName: string,
   } = protobuf.util.isset(e, "info") ? JSON.parse(e.info) : {};
   const targetId: number = e.target_id ? e.target_id.toNumber() : null;
@@ -93,18 +102,46 @@ export function getEventInfo(e: Event$Properties): SimplifiedEvent {
     case eventTypes.DROP_VIEW:
       content = <span>View Dropped: User {info.User} dropped view {info.ViewName}</span>;
       break;
+    case eventTypes.CREATE_SEQUENCE:
+      content = <span>Sequence Created: User {info.User} created sequence {info.SequenceName}</span>;
+      break;
+    case eventTypes.ALTER_SEQUENCE:
+      content = <span>Sequence Altered: User {info.User} altered sequence {info.SequenceName}</span>;
+      break;
+    case eventTypes.DROP_SEQUENCE:
+      content = <span>Sequence Dropped: User {info.User} dropped sequence {info.SequenceName}</span>;
+      break;
     case eventTypes.REVERSE_SCHEMA_CHANGE:
       content = <span>Schema Change Reversed: Schema change with ID {info.MutationID} was reversed.</span>;
       break;
     case eventTypes.FINISH_SCHEMA_CHANGE:
       content = <span>Schema Change Finished: Schema Change Completed: Schema change with ID {info.MutationID} was completed.</span>;
       break;
+    case eventTypes.SET_CLUSTER_SETTING:
+      if (info.Value) {
+        content = <span>Cluster Setting Changed: User {info.User} set {info.SettingName} to {info.Value}</span>;
+      } else {
+        content = <span>Cluster Setting Reset: User {info.User} reset {info.SettingName} to its default value</span>;
+      }
+      break;
     case eventTypes.NODE_JOIN:
       content = <span>Node Joined: Node {targetId} joined the cluster</span>;
       break;
     case eventTypes.NODE_RESTART:
       content = <span>Node Rejoined: Node {targetId} rejoined the cluster</span>;
       break;
+    case eventTypes.STORE_DISK_SPACE:
+      content = <span>Store Disk Space Changed: Disk space state of store {info.StoreID} changed from {info.OldState} to {info.NewState}</span>;
+      break;
+    case eventTypes.RANGE_UNAVAILABLE:
+      content = <span>Ranges Unavailable: Ranges {(info.RangeIDs || []).join(", ")} of store {info.StoreID} became unavailable</span>;
+      break;
+    case eventTypes.REPLICA_CORRUPTION:
+      content = <span>Replicas Corrupt: Replicas of ranges {(info.RangeIDs || []).join(", ")} on store {info.StoreID} were found corrupt</span>;
+      break;
+    case eventTypes.CERTIFICATE_EXPIRY:
+      content = <span>Certificate Expiring: The {info.CertificateType} certificate of node {targetId} expires at {info.ExpirationTime}</span>;
+      break;
     default:
       content = <span>Unknown Event Type: {e.event_type}, content: {s(info)}</span>;
   }
@@ -1173,6 +1173,13 @@ func EncodeNullValue(appendTo []byte, colID uint32) []byte {
 	return encodeValueTag(appendTo, colID, Null)
 }
 
+// EncodeNotNullValue encodes a not-null marker with no data, appends it to
+// the supplied buffer, and returns the final buffer. It is used by callers
+// that encode their own data after the tag.
+func EncodeNotNullValue(appendTo []byte, colID uint32) []byte {
+	return encodeValueTag(appendTo, colID, NotNull)
+}
+
 // EncodeBoolValue encodes a bool value, appends it to the supplied buffer, and
 // returns the final buffer.
 func EncodeBoolValue(appendTo []byte, colID uint32, b bool) []byte {
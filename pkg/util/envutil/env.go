@@ -131,6 +131,57 @@ func GetEnvVarsUsed() []string {
 	return vars
 }
 
+// reportablePrefixes are the prefixes of the names of the environment
+// variables included in crash reports.
+var reportablePrefixes = []string{"COCKROACH_", "GO"}
+
+// sensitiveNameParts identify environment variables whose values must
+// not leave the machine even though their names are reportable.
+var sensitiveNameParts = []string{"PASSWORD", "SECRET", "TOKEN", "KEY", "CRED"}
+
+// GetReportableEnv returns the environment variables that are safe to
+// include in crash reports: those whose name starts with COCKROACH_ or
+// GO. The values of variables which look like they hold credentials,
+// either by name or because they are URLs with user information, are
+// replaced by "<redacted>".
+func GetReportableEnv() map[string]string {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name, value := parts[0], parts[1]
+		reportable := false
+		for _, prefix := range reportablePrefixes {
+			if strings.HasPrefix(name, prefix) {
+				reportable = true
+				break
+			}
+		}
+		if !reportable {
+			continue
+		}
+		if isSensitiveEnvVar(name, value) {
+			value = "<redacted>"
+		}
+		vars[name] = value
+	}
+	return vars
+}
+
+func isSensitiveEnvVar(name, value string) bool {
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	if i := strings.Index(value, "://"); i >= 0 && strings.Contains(value[i:], "@") {
+		return true
+	}
+	return false
+}
+
 // GetShellCommand returns a complete command to run with a prefix of the command line.
 func GetShellCommand(cmd string) []string {
 	if runtime.GOOS == "windows" {
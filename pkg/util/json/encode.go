@@ -0,0 +1,114 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package json
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/apd"
+
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// The tags used in the encoding of the paths of a JSON document in inverted
+// indexes. A path is the sequence of the object keys and arrays leading to one
+// of the leaves of the document, followed by the leaf. Array positions are not
+// part of the path, since they do not matter for containment.
+const (
+	objectKeyTag byte = iota + 1
+	arrayTag
+	nullTag
+	falseTag
+	trueTag
+	numberTag
+	stringTag
+	emptyArrayTag
+	emptyObjectTag
+)
+
+// EncodeInvertedIndexKeys returns the inverted index keys of the document:
+// one key for each distinct path of the document, made of b followed by the
+// encoding of the path as a byte string. The keys are sorted.
+func EncodeInvertedIndexKeys(b []byte, j JSON) [][]byte {
+	paths := encodePaths(j, nil, nil)
+	seen := make(map[string]struct{}, len(paths))
+	keys := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		if _, ok := seen[string(path)]; ok {
+			continue
+		}
+		seen[string(path)] = struct{}{}
+		keys = append(keys, encoding.EncodeBytesAscending(append([]byte(nil), b...), path))
+	}
+	sort.Slice(keys, func(i, k int) bool { return string(keys[i]) < string(keys[k]) })
+	return keys
+}
+
+// EncodeContainingInvertedIndexKey returns an inverted index key, made of b
+// followed by the encoding of a path, which is present in the inverted index
+// keys of any document containing j. It returns false if there is no such key,
+// which is the case of the scalars (contained in the arrays they are an
+// element of) and of the documents whose leaves are all empty arrays or
+// objects (contained in any array or object).
+func EncodeContainingInvertedIndexKey(b []byte, j JSON) ([]byte, bool) {
+	if j.Type() != ArrayJSONType && j.Type() != ObjectJSONType {
+		return nil, false
+	}
+	for _, path := range encodePaths(j, nil, nil) {
+		if tag := path[len(path)-1]; tag == emptyArrayTag || tag == emptyObjectTag {
+			continue
+		}
+		return encoding.EncodeBytesAscending(append([]byte(nil), b...), path), true
+	}
+	return nil, false
+}
+
+// encodePaths appends the encodings of the paths of j, prefixed by
+// prefix, to paths.
+func encodePaths(j JSON, prefix []byte, paths [][]byte) [][]byte {
+	// Force the appends below to copy prefix, which is shared between paths.
+	prefix = prefix[:len(prefix):len(prefix)]
+	switch t := j.(type) {
+	case jsonNull:
+		return append(paths, append(prefix, nullTag))
+	case jsonFalse:
+		return append(paths, append(prefix, falseTag))
+	case jsonTrue:
+		return append(paths, append(prefix, trueTag))
+	case *jsonNumber:
+		return append(paths, encoding.EncodeDecimalAscending(append(prefix, numberTag), (*apd.Decimal)(t)))
+	case jsonString:
+		return append(paths, encoding.EncodeStringAscending(append(prefix, stringTag), string(t)))
+	case jsonArray:
+		if len(t) == 0 {
+			return append(paths, append(prefix, emptyArrayTag))
+		}
+		prefix = append(prefix, arrayTag)
+		for _, e := range t {
+			paths = encodePaths(e, prefix, paths)
+		}
+		return paths
+	case jsonObject:
+		if len(t) == 0 {
+			return append(paths, append(prefix, emptyObjectTag))
+		}
+		for _, kv := range t {
+			keyPrefix := encoding.EncodeStringAscending(append(prefix, objectKeyTag), string(kv.k))
+			paths = encodePaths(kv.v, keyPrefix, paths)
+		}
+		return paths
+	}
+	panic("unknown JSON type")
+}
@@ -0,0 +1,459 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package json implements the JSON documents stored in JSONB columns.
+package json
+
+import (
+	"bytes"
+	gojson "encoding/json"
+	"io"
+	"math/big"
+	"sort"
+	"unsafe"
+
+	"github.com/cockroachdb/apd"
+	"github.com/pkg/errors"
+)
+
+// Type represents the type of a JSON document. The types are listed in their
+// sort order, which is the one used by PostgreSQL.
+type Type int
+
+const (
+	// NullJSONType is the type of the JSON null literal.
+	NullJSONType Type = iota
+	// StringJSONType is the type of a JSON string.
+	StringJSONType
+	// NumberJSONType is the type of a JSON number.
+	NumberJSONType
+	// FalseJSONType is the type of the JSON false literal.
+	FalseJSONType
+	// TrueJSONType is the type of the JSON true literal.
+	TrueJSONType
+	// ArrayJSONType is the type of a JSON array.
+	ArrayJSONType
+	// ObjectJSONType is the type of a JSON object.
+	ObjectJSONType
+)
+
+// JSON is a JSON document. JSON values are immutable.
+type JSON interface {
+	// Type returns the type of the document.
+	Type() Type
+
+	// Format writes the canonical text representation of the document to buf.
+	Format(buf *bytes.Buffer)
+
+	// String returns the canonical text representation of the document.
+	String() string
+
+	// Compare returns -1, 0 or 1 if the document is respectively smaller than,
+	// equal to or greater than other.
+	Compare(other JSON) int
+
+	// FetchValKey returns the value of the given key if the document is an
+	// object containing it, and nil otherwise.
+	FetchValKey(key string) JSON
+
+	// FetchValIdx returns the element at the given position if the document is
+	// an array containing it, and nil otherwise. Negative positions count from
+	// the end of the array.
+	FetchValIdx(idx int) JSON
+
+	// AsText returns the document as text: strings are unquoted and the JSON
+	// null literal is returned as nil.
+	AsText() *string
+
+	// Exists returns true if the given string is a key of the document (if it
+	// is an object), one of its string elements (if it is an array), or the
+	// document itself (if it is a string).
+	Exists(s string) bool
+
+	// Size returns the approximate size of the document in memory.
+	Size() uintptr
+}
+
+type jsonNull struct{}
+type jsonFalse struct{}
+type jsonTrue struct{}
+type jsonNumber apd.Decimal
+type jsonString string
+type jsonArray []JSON
+type jsonObject []jsonKeyValuePair
+
+type jsonKeyValuePair struct {
+	k jsonString
+	v JSON
+}
+
+var (
+	// NullJSONValue is the JSON null literal.
+	NullJSONValue = JSON(jsonNull{})
+	// TrueJSONValue is the JSON true literal.
+	TrueJSONValue = JSON(jsonTrue{})
+	// FalseJSONValue is the JSON false literal.
+	FalseJSONValue = JSON(jsonFalse{})
+)
+
+// FromString returns the JSON string with the given contents.
+func FromString(s string) JSON {
+	return jsonString(s)
+}
+
+// ParseJSON parses the text representation of a JSON document. Duplicate
+// keys in objects keep the last value, as in PostgreSQL.
+func ParseJSON(s string) (JSON, error) {
+	decoder := gojson.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: trailing characters after document")
+	}
+	return fromGo(v)
+}
+
+func fromGo(v interface{}) (JSON, error) {
+	switch t := v.(type) {
+	case nil:
+		return NullJSONValue, nil
+	case bool:
+		if t {
+			return TrueJSONValue, nil
+		}
+		return FalseJSONValue, nil
+	case gojson.Number:
+		d, _, err := apd.NewFromString(string(t))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON number %s", t)
+		}
+		return (*jsonNumber)(d), nil
+	case string:
+		return jsonString(t), nil
+	case []interface{}:
+		arr := make(jsonArray, len(t))
+		for i, e := range t {
+			var err error
+			if arr[i], err = fromGo(e); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case map[string]interface{}:
+		obj := make(jsonObject, 0, len(t))
+		for k, e := range t {
+			j, err := fromGo(e)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonKeyValuePair{k: jsonString(k), v: j})
+		}
+		sort.Slice(obj, func(i, j int) bool { return obj[i].k < obj[j].k })
+		return obj, nil
+	}
+	return nil, errors.Errorf("unexpected JSON value of type %T", v)
+}
+
+func (jsonNull) Type() Type    { return NullJSONType }
+func (jsonFalse) Type() Type   { return FalseJSONType }
+func (jsonTrue) Type() Type    { return TrueJSONType }
+func (*jsonNumber) Type() Type { return NumberJSONType }
+func (jsonString) Type() Type  { return StringJSONType }
+func (jsonArray) Type() Type   { return ArrayJSONType }
+func (jsonObject) Type() Type  { return ObjectJSONType }
+
+func (jsonNull) Format(buf *bytes.Buffer)  { buf.WriteString("null") }
+func (jsonFalse) Format(buf *bytes.Buffer) { buf.WriteString("false") }
+func (jsonTrue) Format(buf *bytes.Buffer)  { buf.WriteString("true") }
+
+func (j *jsonNumber) Format(buf *bytes.Buffer) {
+	buf.WriteString((*apd.Decimal)(j).ToStandard())
+}
+
+func (j jsonString) Format(buf *bytes.Buffer) {
+	// Marshaling a string cannot fail.
+	b, _ := gojson.Marshal(string(j))
+	buf.Write(b)
+}
+
+func (j jsonArray) Format(buf *bytes.Buffer) {
+	buf.WriteByte('[')
+	for i, e := range j {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		e.Format(buf)
+	}
+	buf.WriteByte(']')
+}
+
+func (j jsonObject) Format(buf *bytes.Buffer) {
+	buf.WriteByte('{')
+	for i, kv := range j {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		kv.k.Format(buf)
+		buf.WriteString(": ")
+		kv.v.Format(buf)
+	}
+	buf.WriteByte('}')
+}
+
+func asString(j JSON) string {
+	var buf bytes.Buffer
+	j.Format(&buf)
+	return buf.String()
+}
+
+func (j jsonNull) String() string    { return asString(j) }
+func (j jsonFalse) String() string   { return asString(j) }
+func (j jsonTrue) String() string    { return asString(j) }
+func (j *jsonNumber) String() string { return asString(j) }
+func (j jsonString) String() string  { return asString(j) }
+func (j jsonArray) String() string   { return asString(j) }
+func (j jsonObject) String() string  { return asString(j) }
+
+func cmpTypes(a, b JSON) int {
+	if a.Type() < b.Type() {
+		return -1
+	}
+	if a.Type() > b.Type() {
+		return 1
+	}
+	return 0
+}
+
+func (j jsonNull) Compare(other JSON) int  { return cmpTypes(j, other) }
+func (j jsonFalse) Compare(other JSON) int { return cmpTypes(j, other) }
+func (j jsonTrue) Compare(other JSON) int  { return cmpTypes(j, other) }
+
+func (j *jsonNumber) Compare(other JSON) int {
+	if c := cmpTypes(j, other); c != 0 {
+		return c
+	}
+	return (*apd.Decimal)(j).Cmp((*apd.Decimal)(other.(*jsonNumber)))
+}
+
+func (j jsonString) Compare(other JSON) int {
+	if c := cmpTypes(j, other); c != 0 {
+		return c
+	}
+	o := other.(jsonString)
+	if j < o {
+		return -1
+	}
+	if j > o {
+		return 1
+	}
+	return 0
+}
+
+// Compare implements the JSON interface. As in PostgreSQL, longer arrays sort
+// after shorter ones, and arrays of the same length are compared element by
+// element.
+func (j jsonArray) Compare(other JSON) int {
+	if c := cmpTypes(j, other); c != 0 {
+		return c
+	}
+	o := other.(jsonArray)
+	if len(j) != len(o) {
+		if len(j) < len(o) {
+			return -1
+		}
+		return 1
+	}
+	for i := range j {
+		if c := j[i].Compare(o[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Compare implements the JSON interface. As in PostgreSQL, objects with more
+// keys sort after objects with fewer keys, and objects with the same number
+// of keys are compared key by key, then value by value.
+func (j jsonObject) Compare(other JSON) int {
+	if c := cmpTypes(j, other); c != 0 {
+		return c
+	}
+	o := other.(jsonObject)
+	if len(j) != len(o) {
+		if len(j) < len(o) {
+			return -1
+		}
+		return 1
+	}
+	for i := range j {
+		if c := j[i].k.Compare(o[i].k); c != 0 {
+			return c
+		}
+	}
+	for i := range j {
+		if c := j[i].v.Compare(o[i].v); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (jsonNull) FetchValKey(string) JSON    { return nil }
+func (jsonFalse) FetchValKey(string) JSON   { return nil }
+func (jsonTrue) FetchValKey(string) JSON    { return nil }
+func (*jsonNumber) FetchValKey(string) JSON { return nil }
+func (jsonString) FetchValKey(string) JSON  { return nil }
+func (jsonArray) FetchValKey(string) JSON   { return nil }
+
+func (j jsonObject) FetchValKey(key string) JSON {
+	i := sort.Search(len(j), func(i int) bool { return string(j[i].k) >= key })
+	if i < len(j) && string(j[i].k) == key {
+		return j[i].v
+	}
+	return nil
+}
+
+func (jsonNull) FetchValIdx(int) JSON    { return nil }
+func (jsonFalse) FetchValIdx(int) JSON   { return nil }
+func (jsonTrue) FetchValIdx(int) JSON    { return nil }
+func (*jsonNumber) FetchValIdx(int) JSON { return nil }
+func (jsonString) FetchValIdx(int) JSON  { return nil }
+func (jsonObject) FetchValIdx(int) JSON  { return nil }
+
+func (j jsonArray) FetchValIdx(idx int) JSON {
+	if idx < 0 {
+		idx += len(j)
+	}
+	if idx < 0 || idx >= len(j) {
+		return nil
+	}
+	return j[idx]
+}
+
+func textOf(j JSON) *string {
+	s := j.String()
+	return &s
+}
+
+func (jsonNull) AsText() *string      { return nil }
+func (j jsonFalse) AsText() *string   { return textOf(j) }
+func (j jsonTrue) AsText() *string    { return textOf(j) }
+func (j *jsonNumber) AsText() *string { return textOf(j) }
+func (j jsonArray) AsText() *string   { return textOf(j) }
+func (j jsonObject) AsText() *string  { return textOf(j) }
+
+func (j jsonString) AsText() *string {
+	s := string(j)
+	return &s
+}
+
+func (jsonNull) Exists(string) bool    { return false }
+func (jsonFalse) Exists(string) bool   { return false }
+func (jsonTrue) Exists(string) bool    { return false }
+func (*jsonNumber) Exists(string) bool { return false }
+
+func (j jsonString) Exists(s string) bool { return string(j) == s }
+
+func (j jsonArray) Exists(s string) bool {
+	for _, e := range j {
+		if str, ok := e.(jsonString); ok && string(str) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (j jsonObject) Exists(s string) bool { return j.FetchValKey(s) != nil }
+
+// Contains returns true if a contains b, following the rules of the PostgreSQL
+// @> operator: scalars contain equal scalars, an object contains another
+// object if it contains all its keys with values containing theirs, and an
+// array contains another array if each element of the latter is contained in
+// some element of the former. As a special exception, a top-level array
+// contains the scalars it contains as elements.
+func Contains(a, b JSON) bool {
+	if arr, ok := a.(jsonArray); ok && b.Type() != ArrayJSONType && b.Type() != ObjectJSONType {
+		for _, e := range arr {
+			if e.Compare(b) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return contains(a, b)
+}
+
+func contains(a, b JSON) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch t := a.(type) {
+	case jsonArray:
+		for _, be := range b.(jsonArray) {
+			found := false
+			for _, ae := range t {
+				if contains(ae, be) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case jsonObject:
+		for _, kv := range b.(jsonObject) {
+			v := t.FetchValKey(string(kv.k))
+			if v == nil || !contains(v, kv.v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Compare(b) == 0
+	}
+}
+
+func (jsonNull) Size() uintptr  { return 0 }
+func (jsonFalse) Size() uintptr { return 0 }
+func (jsonTrue) Size() uintptr  { return 0 }
+
+func (j *jsonNumber) Size() uintptr {
+	intVal := (*apd.Decimal)(j).Coeff
+	return unsafe.Sizeof(*j) + uintptr(cap(intVal.Bits()))*unsafe.Sizeof(big.Word(0))
+}
+
+func (j jsonString) Size() uintptr {
+	return unsafe.Sizeof(j) + uintptr(len(j))
+}
+
+func (j jsonArray) Size() uintptr {
+	sz := unsafe.Sizeof(j)
+	for _, e := range j {
+		sz += e.Size()
+	}
+	return sz
+}
+
+func (j jsonObject) Size() uintptr {
+	sz := unsafe.Sizeof(j)
+	for _, kv := range j {
+		sz += kv.k.Size() + kv.v.Size()
+	}
+	return sz
+}
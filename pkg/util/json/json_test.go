@@ -0,0 +1,262 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"testing"
+)
+
+func parse(t *testing.T, s string) JSON {
+	j, err := ParseJSON(s)
+	if err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	return j
+}
+
+func TestParseJSON(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`null`, `null`},
+		{` true `, `true`},
+		{`false`, `false`},
+		{`1`, `1`},
+		{`-1.50`, `-1.50`},
+		{`1e2`, `100`},
+		{`"a\"bé"`, `"a\"bé"`},
+		{`[]`, `[]`},
+		{`[1,  "a",[null]]`, `[1, "a", [null]]`},
+		{`{}`, `{}`},
+		{`{"b": 1, "a": {"c": [true]}}`, `{"a": {"c": [true]}, "b": 1}`},
+		// The last value of duplicate keys is kept.
+		{`{"a": 1, "a": 2}`, `{"a": 2}`},
+	}
+	for _, tc := range testCases {
+		if actual := parse(t, tc.input).String(); actual != tc.expected {
+			t.Errorf("%s: expected %s, but got %s", tc.input, tc.expected, actual)
+		}
+	}
+
+	for _, input := range []string{``, `{`, `[1,]`, `{"a"}`, `nul`, `1 2`, `{} x`, `'a'`} {
+		if _, err := ParseJSON(input); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestJSONCompare(t *testing.T) {
+	// The values are in increasing order.
+	values := []string{
+		`null`,
+		`""`,
+		`"a"`,
+		`"b"`,
+		`-1`,
+		`1`,
+		`1.5`,
+		`false`,
+		`true`,
+		`[]`,
+		`[2]`,
+		`[1, 2]`,
+		`[1, 3]`,
+		`{}`,
+		`{"a": 2}`,
+		`{"b": 1}`,
+		`{"a": 1, "b": 1}`,
+	}
+	for i := range values {
+		for k := range values {
+			expected := 0
+			if i < k {
+				expected = -1
+			} else if i > k {
+				expected = 1
+			}
+			if actual := parse(t, values[i]).Compare(parse(t, values[k])); actual != expected {
+				t.Errorf("%s vs %s: expected %d, but got %d", values[i], values[k], expected, actual)
+			}
+		}
+	}
+	if c := parse(t, `1.0`).Compare(parse(t, `1`)); c != 0 {
+		t.Errorf("expected 1.0 to be equal to 1, but got %d", c)
+	}
+}
+
+func TestJSONFetch(t *testing.T) {
+	j := parse(t, `{"a": [1, "b", null], "c": {"d": "e"}}`)
+	str := func(j JSON) string {
+		if j == nil {
+			return "<nil>"
+		}
+		return j.String()
+	}
+	text := func(s *string) string {
+		if s == nil {
+			return "<nil>"
+		}
+		return *s
+	}
+
+	testCases := []struct {
+		actual   string
+		expected string
+	}{
+		{str(j.FetchValKey("a")), `[1, "b", null]`},
+		{str(j.FetchValKey("c").FetchValKey("d")), `"e"`},
+		{str(j.FetchValKey("z")), `<nil>`},
+		{str(j.FetchValIdx(0)), `<nil>`},
+		{str(j.FetchValKey("a").FetchValIdx(1)), `"b"`},
+		{str(j.FetchValKey("a").FetchValIdx(-1)), `null`},
+		{str(j.FetchValKey("a").FetchValIdx(3)), `<nil>`},
+		{str(j.FetchValKey("a").FetchValIdx(-4)), `<nil>`},
+		{text(j.FetchValKey("c").FetchValKey("d").AsText()), `e`},
+		{text(j.FetchValKey("c").AsText()), `{"d": "e"}`},
+		{text(j.FetchValKey("a").FetchValIdx(2).AsText()), `<nil>`},
+	}
+	for i, tc := range testCases {
+		if tc.actual != tc.expected {
+			t.Errorf("%d: expected %s, but got %s", i, tc.expected, tc.actual)
+		}
+	}
+}
+
+func TestJSONExists(t *testing.T) {
+	testCases := []struct {
+		j        string
+		s        string
+		expected bool
+	}{
+		{`{"a": 1}`, "a", true},
+		{`{"a": 1}`, "b", false},
+		{`{"b": {"a": 1}}`, "a", false},
+		{`["a", 1]`, "a", true},
+		{`["b", ["a"]]`, "a", false},
+		{`"a"`, "a", true},
+		{`1`, "1", false},
+		{`null`, "a", false},
+	}
+	for _, tc := range testCases {
+		if actual := parse(t, tc.j).Exists(tc.s); actual != tc.expected {
+			t.Errorf("%s ? %s: expected %t, but got %t", tc.j, tc.s, tc.expected, actual)
+		}
+	}
+}
+
+func TestJSONContains(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{`1`, `1`, true},
+		{`1`, `1.0`, true},
+		{`1`, `2`, false},
+		{`"a"`, `"a"`, true},
+		{`null`, `null`, true},
+		{`[1, 2, 3]`, `[3, 1]`, true},
+		{`[1, 2, 3]`, `[1, 1]`, true},
+		{`[1, 2, 3]`, `[]`, true},
+		{`[1, 2, 3]`, `[4]`, false},
+		{`[1, 2, 3]`, `2`, true},
+		{`[1, 2, 3]`, `4`, false},
+		{`[[1, 2]]`, `[1]`, false},
+		{`[[1, 2]]`, `[[1]]`, true},
+		{`[{"a": 1, "b": 2}]`, `[{"a": 1}]`, true},
+		{`{"a": 1, "b": 2}`, `{"a": 1}`, true},
+		{`{"a": 1, "b": 2}`, `{}`, true},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{`{"a": {"b": [1, 2]}}`, `{"a": {"b": [2]}}`, true},
+		{`{"a": {"b": [1, 2]}}`, `{"a": {"b": 2}}`, false},
+		{`{"a": 1}`, `[]`, false},
+		{`[]`, `{}`, false},
+		{`{"a": 1}`, `1`, false},
+	}
+	for _, tc := range testCases {
+		if actual := Contains(parse(t, tc.a), parse(t, tc.b)); actual != tc.expected {
+			t.Errorf("%s @> %s: expected %t, but got %t", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestEncodeInvertedIndexKeys(t *testing.T) {
+	prefix := []byte("prefix")
+	contains := func(keys [][]byte, key []byte) bool {
+		for _, k := range keys {
+			if bytes.Equal(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	docs := []string{
+		`null`,
+		`1`,
+		`"a"`,
+		`[]`,
+		`{}`,
+		`[1, 1, 2]`,
+		`[[1], {"a": "b"}]`,
+		`{"a": 1, "b": [true, false], "c": {"d": null, "e": {}}}`,
+		`{"a": {"b": 1}}`,
+		`{"a": [{"b": 1}]}`,
+	}
+	for _, doc := range docs {
+		keys := EncodeInvertedIndexKeys(prefix, parse(t, doc))
+		if len(keys) == 0 {
+			t.Errorf("%s: expected keys", doc)
+		}
+		for i := range keys {
+			if !bytes.HasPrefix(keys[i], prefix) {
+				t.Errorf("%s: key %q does not have prefix %q", doc, keys[i], prefix)
+			}
+			if i > 0 && bytes.Compare(keys[i-1], keys[i]) >= 0 {
+				t.Errorf("%s: keys are not sorted and unique: %q", doc, keys)
+			}
+		}
+	}
+	if keys := EncodeInvertedIndexKeys(prefix, parse(t, `[1, 1, 2]`)); len(keys) != 2 {
+		t.Errorf("expected 2 keys, but got %d", len(keys))
+	}
+
+	// The containing key of a document must be one of the keys of all the
+	// documents which contain it.
+	for _, a := range docs {
+		ja := parse(t, a)
+		keys := EncodeInvertedIndexKeys(prefix, ja)
+		for _, b := range docs {
+			jb := parse(t, b)
+			key, ok := EncodeContainingInvertedIndexKey(prefix, jb)
+			if !ok {
+				continue
+			}
+			if Contains(ja, jb) && !contains(keys, key) {
+				t.Errorf("%s contains %s but does not have its containing key", a, b)
+			}
+		}
+	}
+
+	for _, doc := range []string{`1`, `"a"`, `null`, `[]`, `{}`, `{"a": []}`, `[{}]`} {
+		if _, ok := EncodeContainingInvertedIndexKey(prefix, parse(t, doc)); ok {
+			t.Errorf("%s: expected no containing key", doc)
+		}
+	}
+	if _, ok := EncodeContainingInvertedIndexKey(prefix, parse(t, `{"a": [], "b": 1}`)); !ok {
+		t.Errorf("expected a containing key")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxActivityTagLen bounds the length of the "activity" tag of crash
+// reports, which is truncated beyond it by the crash reporting server.
+const maxActivityTagLen = 200
+
+var activities struct {
+	syncutil.Mutex
+	nextID int64
+	m      map[int64]string
+}
+
+// StartActivity registers a short description of a long-running activity
+// of the process, for instance "backup job 1234", which is attached to
+// the crash reports generated until the returned function is called, so
+// that they tell what the node was doing. The description is reported
+// verbatim, so it must not contain user data.
+func StartActivity(description string) (done func()) {
+	activities.Lock()
+	defer activities.Unlock()
+	if activities.m == nil {
+		activities.m = make(map[int64]string)
+	}
+	id := activities.nextID
+	activities.nextID++
+	activities.m[id] = description
+	return func() {
+		activities.Lock()
+		defer activities.Unlock()
+		delete(activities.m, id)
+	}
+}
+
+// currentActivities returns the descriptions of the activities in
+// progress, in the order in which they were started.
+func currentActivities() []string {
+	activities.Lock()
+	defer activities.Unlock()
+	ids := make([]int64, 0, len(activities.m))
+	for id := range activities.m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[i] = activities.m[id]
+	}
+	return res
+}
+
+// activityTag returns the value of the "activity" tag of crash reports,
+// or an empty string if no activity is in progress.
+func activityTag() string {
+	tag := strings.Join(currentActivities(), "; ")
+	if len(tag) > maxActivityTagLen {
+		tag = tag[:maxActivityTagLen-3] + "..."
+	}
+	return tag
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestActivityTag(t *testing.T) {
+	if tag := activityTag(); tag != "" {
+		t.Fatalf("expected no activity, got %q", tag)
+	}
+
+	doneBackup := StartActivity("backup job 1")
+	doneSchemaChange := StartActivity("schema change job 2")
+	doneRestore := StartActivity("restore job 3")
+	doneSchemaChange()
+	if tag, expected := activityTag(), "backup job 1; restore job 3"; tag != expected {
+		t.Errorf("expected %q, got %q", expected, tag)
+	}
+
+	packet := makeReportPacket(context.Background(), errors.New("boom"), nil, nil)
+	var found bool
+	for _, tag := range packet.Tags {
+		if tag.Key == "activity" {
+			found = tag.Value == "backup job 1; restore job 3"
+		}
+	}
+	if !found {
+		t.Errorf("expected the crash report to be tagged with the activities, got %v", packet.Tags)
+	}
+
+	doneBackup()
+	doneRestore()
+	if tag := activityTag(); tag != "" {
+		t.Errorf("expected no activity, got %q", tag)
+	}
+
+	defer StartActivity(strings.Repeat("x", 2*maxActivityTagLen))()
+	if tag := activityTag(); len(tag) != maxActivityTagLen {
+		t.Errorf("expected the tag to be truncated to %d bytes, got %d", maxActivityTagLen, len(tag))
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// An Anonymizer transforms a value which may contain user data before it
+// is included in a crash report or a diagnostics report. Anonymizers can
+// be called concurrently, and while the process is crashing: they must
+// not log, and must bound the time they spend, for instance in the calls
+// to an external tokenization service.
+type Anonymizer func(value string) string
+
+// The built-in anonymization strategies. "redact" replaces values by the
+// placeholder of the report, "<redacted>" in crash reports; "hash"
+// replaces them by a fingerprint, which allows correlating reports
+// without revealing the values, though short values can be guessed from
+// their fingerprint; "drop" leaves them out of the reports altogether.
+const (
+	anonymizeRedact = "redact"
+	anonymizeHash   = "hash"
+	anonymizeDrop   = "drop"
+)
+
+var anonymizers = struct {
+	syncutil.Mutex
+	// m maps the names of the strategies to their anonymizers. The redact
+	// strategy, which depends on the report, has none.
+	m map[string]Anonymizer
+}{
+	m: map[string]Anonymizer{
+		anonymizeRedact: nil,
+		anonymizeHash:   func(v string) string { return "hash#" + stringFingerprint(v) },
+		anonymizeDrop:   func(string) string { return "" },
+	},
+}
+
+// RegisterAnonymizer adds an anonymization strategy, which can then be
+// selected with the ReportAnonymization setting. It is meant to be
+// called during initialization, and panics if the strategy is already
+// registered.
+func RegisterAnonymizer(name string, fn Anonymizer) {
+	anonymizers.Lock()
+	defer anonymizers.Unlock()
+	if _, ok := anonymizers.m[name]; ok {
+		panic(fmt.Sprintf("anonymization strategy %q already registered", name))
+	}
+	anonymizers.m[name] = fn
+}
+
+func validateAnonymization(name string) error {
+	anonymizers.Lock()
+	defer anonymizers.Unlock()
+	if _, ok := anonymizers.m[name]; ok {
+		return nil
+	}
+	names := make([]string, 0, len(anonymizers.m))
+	for n := range anonymizers.m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown anonymization strategy %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// ReportAnonymization selects the strategy applied to the values which
+// may contain user data in crash reports and diagnostics reports.
+var ReportAnonymization = settings.RegisterValidatedStringSetting(
+	"diagnostics.reporting.anonymization",
+	"strategy applied to the values which may contain user data in crash and diagnostics reports "+
+		"(redact, hash, drop, or one registered by an extension)",
+	anonymizeRedact,
+	validateAnonymization,
+)
+
+// AnonymizeForReport returns the rendition of value, which may contain
+// user data, to include in a report, according to the ReportAnonymization
+// setting. It returns placeholder with the redact strategy, and with the
+// strategies which are not registered in this process, and the empty
+// string if the value is to be left out of the report.
+func AnonymizeForReport(value, placeholder string) string {
+	anonymizers.Lock()
+	fn := anonymizers.m[ReportAnonymization.Get()]
+	anonymizers.Unlock()
+	if fn == nil {
+		return placeholder
+	}
+	return fn(value)
+}
+
+// anonymizedValue returns v if it is safe to report, and its anonymized
+// rendition otherwise.
+func anonymizedValue(v interface{}) interface{} {
+	if r := reportableValue(v); r != redactedMarker {
+		return r
+	}
+	return AnonymizeForReport(fmt.Sprint(v), redactedMarker)
+}
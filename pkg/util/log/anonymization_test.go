@@ -0,0 +1,86 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestAnonymizeForReport(t *testing.T) {
+	RegisterAnonymizer("test-token", func(v string) string { return "token:" + v })
+	defer func() {
+		anonymizers.Lock()
+		delete(anonymizers.m, "test-token")
+		anonymizers.Unlock()
+	}()
+
+	testCases := []struct {
+		strategy string
+		expected string
+	}{
+		{anonymizeRedact, "<placeholder>"},
+		{anonymizeHash, "hash#" + stringFingerprint("secret")},
+		{anonymizeDrop, ""},
+		{"test-token", "token:secret"},
+		// Strategies registered on other nodes fall back to redaction.
+		{"unknown", "<placeholder>"},
+	}
+	for _, tc := range testCases {
+		func() {
+			defer settings.TestingSetString(&ReportAnonymization, tc.strategy)()
+			if a := AnonymizeForReport("secret", "<placeholder>"); a != tc.expected {
+				t.Errorf("%s: expected %q, got %q", tc.strategy, tc.expected, a)
+			}
+		}()
+	}
+
+	if err := validateAnonymization("test-token"); err != nil {
+		t.Error(err)
+	}
+	if err := validateAnonymization("unknown"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestAnonymizedReportContext(t *testing.T) {
+	ctx := WithLogTag(context.Background(), "n", 1)
+	ctx = WithLogTag(ctx, "user", "alice")
+	ctx = WithPanicHint(ctx, "serving %s", "alice")
+
+	func() {
+		defer settings.TestingSetString(&ReportAnonymization, anonymizeHash)()
+		expected := map[string]interface{}{"n": 1, "user": "hash#" + stringFingerprint("alice")}
+		if tags := contextReportableTags(ctx); !reflect.DeepEqual(tags, expected) {
+			t.Errorf("expected %v, got %v", expected, tags)
+		}
+		if hints := panicHints(ctx, true /* redact */); !reflect.DeepEqual(hints,
+			[]string{"serving hash#" + stringFingerprint("alice")}) {
+			t.Errorf("unexpected hints %q", hints)
+		}
+	}()
+
+	func() {
+		defer settings.TestingSetString(&ReportAnonymization, anonymizeDrop)()
+		expected := map[string]interface{}{"n": 1}
+		if tags := contextReportableTags(ctx); !reflect.DeepEqual(tags, expected) {
+			t.Errorf("expected the dropped tag to be left out, got %v", tags)
+		}
+	}()
+}
@@ -0,0 +1,266 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// auditTag is the channel of the events recorded in the audit log.
+const auditTag = "audit"
+
+// auditEventPrefix prefixes the message of the entries of the audit log
+// files, which is followed by the JSON encoding of the AuditEvent.
+const auditEventPrefix = "audit: "
+
+// auditEventType is the name of the event type of AuditEvent in the
+// catalog of the structured log surface.
+const auditEventType = "audit_event"
+
+func init() {
+	RegisterChannel(auditTag, "privileged operations and logins, recorded in the audit log files")
+	RegisterEventType(auditEventType, auditTag,
+		"a privileged operation or a login attempt, in the audit log files",
+		auditEventPrefix, AuditEvent{})
+}
+
+// AuditLogFileMaxSize is the maximum size in bytes of an audit log file.
+var AuditLogFileMaxSize int64 = 10 << 20 // 10MiB
+
+// AuditLogFilesCombinedMaxSize is the maximum total size in bytes of the
+// audit log files, past which the oldest ones are removed. Zero, the
+// default, means that audit log files are never removed, and are left
+// for the operators to archive.
+var AuditLogFilesCombinedMaxSize int64
+
+var auditLogEnabled = settings.RegisterBoolSetting(
+	"log.audit.enabled",
+	"record DDL, privilege changes, logins and cluster setting changes in the audit log files",
+	false,
+)
+
+// AuditLogEnabled returns true if the audit events are recorded, so that
+// callers can skip preparing the events otherwise.
+func AuditLogEnabled() bool {
+	return auditLogEnabled.Get()
+}
+
+// maxAuditStatementLen bounds the length of the statements recorded in
+// the audit log, so that their entries are not truncated when decoded.
+const maxAuditStatementLen = 16 << 10
+
+// AuditEvent is an event recorded in the audit log. The events of the
+// log of a node are numbered in sequence, and each records the hash of
+// the previous one, so that VerifyAuditLog detects the events which were
+// removed from the log files or altered.
+type AuditEvent struct {
+	// Seq is the sequence number of the event in the audit log of the node.
+	Seq uint64 `json:"seq"`
+	// Prev is the hex encoding of the SHA-256 hash of the JSON encoding of
+	// the previous event, or empty for the first event of the log.
+	Prev string    `json:"prev"`
+	Time time.Time `json:"time"`
+	// Kind is the kind of operation, such as "ddl" or "login".
+	Kind       string `json:"kind"`
+	User       string `json:"user"`
+	ClientAddr string `json:"client_addr"`
+	// Statement is the text of the SQL statement, if any, which requested
+	// the operation.
+	Statement string `json:"statement,omitempty"`
+	// Error is the reason why the operation failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// auditLogger writes the audit log files, which are separate from the
+// other log files and rotated according to a policy of their own.
+type auditLogger struct {
+	syncutil.Mutex
+	file secondaryLogFile
+	// seq and prev are the sequence number and the hash of the last event
+	// recorded.
+	seq  uint64
+	prev string
+}
+
+var auditLog = auditLogger{
+	file: secondaryLogFile{
+		name:            auditTag,
+		maxSize:         &AuditLogFileMaxSize,
+		combinedMaxSize: &AuditLogFilesCombinedMaxSize,
+	},
+}
+
+// Audit records ev in the audit log, if it is enabled. Its sequence
+// number and the hash of the previous event are filled in, and so is its
+// time if it is zero. The event is durable once Audit returns; failures
+// to record it are logged as warnings.
+func Audit(ctx context.Context, ev AuditEvent) {
+	if !AuditLogEnabled() {
+		return
+	}
+	file, line, _ := caller.Lookup(1)
+	if err := auditLog.record(ev, file, line); err != nil {
+		Warningf(ctx, "unable to record %s event in the audit log: %s", ev.Kind, err)
+	}
+}
+
+// auditHash returns the hash of the JSON encoding of an event, recorded
+// in the next event.
+func auditHash(payload []byte) string {
+	h := sha256.Sum256(payload)
+	return hex.EncodeToString(h[:])
+}
+
+func (a *auditLogger) record(ev AuditEvent, file string, line int) error {
+	a.Lock()
+	defer a.Unlock()
+	changed, err := a.file.setDir()
+	if err == errDirectoryNotSet {
+		// Without a log directory, there are no audit log files.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if changed {
+		// Resume the sequence of events where the last event recorded in the
+		// directory, possibly by a previous run of the process, left it.
+		if a.seq, a.prev, err = lastAuditEvent(a.file.dir); err != nil {
+			a.file.dir = ""
+			return err
+		}
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	if len(ev.Statement) > maxAuditStatementLen {
+		ev.Statement = ev.Statement[:maxAuditStatementLen] + "..."
+	}
+	ev.Seq = a.seq + 1
+	ev.Prev = a.prev
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if err := a.file.writeEntry(ev.Time, file, line, auditEventPrefix+string(payload)); err != nil {
+		return err
+	}
+	// Unlike the other log files, the audit log files are synced after
+	// every event, which are rare enough.
+	if err := a.file.sync(); err != nil {
+		return err
+	}
+	a.seq, a.prev = ev.Seq, auditHash(payload)
+	return nil
+}
+
+// lastAuditEvent returns the sequence number and the hash of the last
+// event recorded in the audit log files in dir, or zero values if there
+// is none.
+func lastAuditEvent(dir string) (seq uint64, hash string, err error) {
+	files, err := listLogFiles(dir)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, f := range selectFiles(files, math.MaxInt64) {
+		r, err := os.Open(filepath.Join(dir, f.Name))
+		if err != nil {
+			return 0, "", err
+		}
+		var payload string
+		d := NewEntryDecoder(r)
+		for {
+			var e Entry
+			if err := d.Decode(&e); err != nil {
+				if err != io.EOF {
+					_ = r.Close()
+					return 0, "", err
+				}
+				break
+			}
+			if strings.HasPrefix(e.Message, auditEventPrefix) {
+				payload = e.Message[len(auditEventPrefix):]
+			}
+		}
+		if err := r.Close(); err != nil {
+			return 0, "", err
+		}
+		if payload == "" {
+			// The file is empty, for instance because the process crashed
+			// after creating it.
+			continue
+		}
+		var ev AuditEvent
+		if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+			return 0, "", fmt.Errorf("malformed audit event in %s: %s", f.Name, err)
+		}
+		return ev.Seq, auditHash([]byte(payload)), nil
+	}
+	return 0, "", nil
+}
+
+// VerifyAuditLog checks the sequence of the events of the audit log read
+// from r, the concatenation of consecutive audit log files of a node in
+// the order in which they were written, and returns the number of events
+// it contains. An error is returned if events are missing or were
+// altered. Only the first event may follow events which are not part of
+// r, such as those of files removed by the rotation policy.
+func VerifyAuditLog(r io.Reader) (int, error) {
+	d := NewEntryDecoder(r)
+	var n int
+	var prevSeq uint64
+	var prevHash string
+	for {
+		var e Entry
+		if err := d.Decode(&e); err != nil {
+			if err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		if !strings.HasPrefix(e.Message, auditEventPrefix) {
+			continue
+		}
+		payload := e.Message[len(auditEventPrefix):]
+		var ev AuditEvent
+		if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+			return n, fmt.Errorf("malformed audit event after event %d: %s", prevSeq, err)
+		}
+		if n > 0 {
+			if ev.Seq != prevSeq+1 {
+				return n, fmt.Errorf("audit event %d follows event %d: events are missing", ev.Seq, prevSeq)
+			}
+			if ev.Prev != prevHash {
+				return n, fmt.Errorf("audit event %d does not match the hash of event %d: events were altered", ev.Seq, prevSeq)
+			}
+		}
+		n++
+		prevSeq, prevHash = ev.Seq, auditHash([]byte(payload))
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// readAuditLog returns the concatenation of the audit log files, in the
+// order in which they were written, and the number of files.
+func readAuditLog(t *testing.T) ([]byte, int) {
+	dir, err := secondaryLogDir(auditTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := listLogFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = selectFiles(files, math.MaxInt64)
+	sort.Sort(sortableFileInfoSlice(files))
+	var buf bytes.Buffer
+	for _, f := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), len(files)
+}
+
+// auditEvents decodes the events of an audit log.
+func auditEvents(t *testing.T, data []byte) []AuditEvent {
+	var res []AuditEvent
+	d := NewEntryDecoder(bytes.NewReader(data))
+	for {
+		var e Entry
+		if err := d.Decode(&e); err != nil {
+			break
+		}
+		var ev AuditEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(e.Message, auditEventPrefix)), &ev); err != nil {
+			t.Fatalf("%q: %s", e.Message, err)
+		}
+		res = append(res, ev)
+	}
+	return res
+}
+
+func TestAuditLog(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	ctx := context.Background()
+
+	// Nothing is recorded while the audit log is disabled.
+	Audit(ctx, AuditEvent{Kind: "ddl", User: "root", Statement: "CREATE DATABASE d"})
+	if _, n := readAuditLog(t); n != 0 {
+		t.Fatalf("expected no audit log file, found %d", n)
+	}
+
+	defer settings.TestingSetBool(&auditLogEnabled, true)()
+	for i := 0; i < 3; i++ {
+		Audit(ctx, AuditEvent{
+			Kind:       "ddl",
+			User:       "root",
+			ClientAddr: "127.0.0.1:1234",
+			Statement:  fmt.Sprintf("CREATE TABLE t%d ()", i),
+		})
+	}
+	Audit(ctx, AuditEvent{Kind: "ddl", Statement: strings.Repeat("x", 2*maxAuditStatementLen)})
+
+	data, _ := readAuditLog(t)
+	if n, err := VerifyAuditLog(bytes.NewReader(data)); err != nil || n != 4 {
+		t.Fatalf("expected 4 verified events, got %d, %v", n, err)
+	}
+	events := auditEvents(t, data)
+	for i, ev := range events {
+		if ev.Seq != uint64(i+1) {
+			t.Errorf("%d: expected sequence number %d, got %d", i, i+1, ev.Seq)
+		}
+		if (i == 0) != (ev.Prev == "") {
+			t.Errorf("%d: unexpected hash of the previous event %q", i, ev.Prev)
+		}
+		if ev.Time.IsZero() {
+			t.Errorf("%d: expected the time to be set", i)
+		}
+	}
+	if a, e := events[1].Statement, "CREATE TABLE t1 ()"; a != e {
+		t.Errorf("expected statement %q, got %q", e, a)
+	}
+	if a, e := len(events[3].Statement), maxAuditStatementLen+len("..."); a != e {
+		t.Errorf("expected the statement to be truncated to %d bytes, got %d", e, a)
+	}
+
+	// A restart resumes the sequence of events.
+	auditLog.Lock()
+	auditLog.file.dir = ""
+	auditLog.Unlock()
+	Audit(ctx, AuditEvent{Kind: "login", User: "root"})
+	data, _ = readAuditLog(t)
+	if n, err := VerifyAuditLog(bytes.NewReader(data)); err != nil || n != 5 {
+		t.Fatalf("expected 5 verified events, got %d, %v", n, err)
+	}
+
+	// Altered and missing events are detected.
+	altered := bytes.Replace(data, []byte("CREATE TABLE t1"), []byte("CREATE TABLE t9"), 1)
+	if _, err := VerifyAuditLog(bytes.NewReader(altered)); err == nil || !strings.Contains(err.Error(), "events were altered") {
+		t.Errorf("expected the alteration to be detected, got %v", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	removed := bytes.Join(append(lines[:1:1], lines[2:]...), nil)
+	if _, err := VerifyAuditLog(bytes.NewReader(removed)); err == nil || !strings.Contains(err.Error(), "events are missing") {
+		t.Errorf("expected the removal to be detected, got %v", err)
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	ctx := context.Background()
+	defer settings.TestingSetBool(&auditLogEnabled, true)()
+
+	defer func(previous int64) { atomic.StoreInt64(&AuditLogFileMaxSize, previous) }(AuditLogFileMaxSize)
+	atomic.StoreInt64(&AuditLogFileMaxSize, 1) // ensure rotation on every event
+
+	const numEvents = 5
+	for i := 0; i < numEvents; i++ {
+		Audit(ctx, AuditEvent{Kind: "ddl", Statement: "DROP TABLE t"})
+	}
+	_, files := readAuditLog(t)
+	if files != numEvents {
+		t.Fatalf("expected %d audit log files, found %d", numEvents, files)
+	}
+
+	// Keep room for the new file and the previous one.
+	defer func(previous int64) {
+		atomic.StoreInt64(&AuditLogFilesCombinedMaxSize, previous)
+	}(AuditLogFilesCombinedMaxSize)
+	data, _ := readAuditLog(t)
+	atomic.StoreInt64(&AuditLogFilesCombinedMaxSize, int64(len(data)/numEvents*3/2))
+	Audit(ctx, AuditEvent{Kind: "ddl", Statement: "DROP TABLE t"})
+
+	data, files = readAuditLog(t)
+	if files != 2 {
+		t.Fatalf("expected 2 audit log files, found %d", files)
+	}
+	// The remaining events still form a valid sequence.
+	if n, err := VerifyAuditLog(bytes.NewReader(data)); err != nil || n != 2 {
+		t.Fatalf("expected 2 verified events, got %d, %v", n, err)
+	}
+	if events := auditEvents(t, data); events[1].Seq != numEvents+1 {
+		t.Errorf("expected the last event to be number %d, got %d", numEvents+1, events[1].Seq)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// sinkChaosSpec configures the chaos mode of the logging pipeline, which
+// is used to test its robustness. It is a comma-separated list of
+// parameters, for instance
+// "seed=42,delay=0.1:50ms,partial=0.05,error=0.05,flush=0.1,rotate=0.1":
+// entries are delayed by up to 50ms with probability 0.1, partially
+// written to the sinks with probability 0.05 and fail to be written to
+// them with probability 0.05, while the flushes and the rotations of the
+// log file fail with probability 0.1. The chaos mode is never enabled in
+// release builds.
+var sinkChaosSpec = envutil.EnvOrDefaultString("COCKROACH_LOG_SINK_CHAOS", "")
+
+// errChaos is returned by the operations which the chaos mode fails.
+var errChaos = errors.New("log sink chaos: injected error")
+
+// chaosOptions are the parameters of a chaos sink.
+type chaosOptions struct {
+	seed        int64
+	delayProb   float64
+	maxDelay    time.Duration
+	partialProb float64
+	errProb     float64
+	flushProb   float64
+	rotateProb  float64
+}
+
+// parseChaosOptions parses the specification of the chaos mode (see
+// sinkChaosSpec).
+func parseChaosOptions(spec string) (chaosOptions, error) {
+	var opts chaosOptions
+	for _, param := range strings.Split(spec, ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 {
+			return opts, fmt.Errorf("invalid chaos parameter %q", param)
+		}
+		var err error
+		switch kv[0] {
+		case "seed":
+			opts.seed, err = strconv.ParseInt(kv[1], 10, 64)
+		case "delay":
+			parts := strings.SplitN(kv[1], ":", 2)
+			if len(parts) != 2 {
+				return opts, fmt.Errorf("invalid chaos delay %q, expected PROBABILITY:DURATION", kv[1])
+			}
+			if opts.delayProb, err = strconv.ParseFloat(parts[0], 64); err == nil {
+				opts.maxDelay, err = time.ParseDuration(parts[1])
+			}
+		case "partial":
+			opts.partialProb, err = strconv.ParseFloat(kv[1], 64)
+		case "error":
+			opts.errProb, err = strconv.ParseFloat(kv[1], 64)
+		case "flush":
+			opts.flushProb, err = strconv.ParseFloat(kv[1], 64)
+		case "rotate":
+			opts.rotateProb, err = strconv.ParseFloat(kv[1], 64)
+		default:
+			return opts, fmt.Errorf("unknown chaos parameter %q", kv[0])
+		}
+		if err != nil {
+			return opts, errors.Wrapf(err, "invalid chaos parameter %q", param)
+		}
+	}
+	return opts, nil
+}
+
+// chaosSink wraps a sink and injects partial writes and errors into the
+// writes to it, according to a pseudo-random sequence which is determined
+// by the seed so that failures can be reproduced.
+type chaosSink struct {
+	sink logSink
+	opts chaosOptions
+	// rng is only used in write, which is called with logging.mu held.
+	rng *rand.Rand
+}
+
+func newChaosSink(sink logSink, opts chaosOptions) *chaosSink {
+	return &chaosSink{sink: sink, opts: opts, rng: rand.New(rand.NewSource(opts.seed))}
+}
+
+// maybeChaos wraps sink in a chaos sink if the chaos mode is enabled.
+func maybeChaos(sink logSink) logSink {
+	if sinkChaosSpec == "" || build.IsRelease() {
+		return sink
+	}
+	opts, err := parseChaosOptions(sinkChaosSpec)
+	if err != nil {
+		fmt.Fprintf(OrigStderr, "log: ignoring sink chaos mode: %s\n", err)
+		return sink
+	}
+	return newChaosSink(sink, opts)
+}
+
+func (c *chaosSink) write(data []byte) error {
+	if c.rng.Float64() < c.opts.errProb {
+		return errChaos
+	}
+	if c.rng.Float64() < c.opts.partialProb && len(data) > 1 {
+		if err := c.sink.write(data[:c.rng.Intn(len(data)-1)+1]); err != nil {
+			return err
+		}
+		return io.ErrShortWrite
+	}
+	return c.sink.write(data)
+}
+
+func (c *chaosSink) String() string {
+	return c.sink.String()
+}
+
+// backpressure is part of the backpressureReporter interface.
+func (c *chaosSink) backpressure() float64 {
+	if b, ok := c.sink.(backpressureReporter); ok {
+		return b.backpressure()
+	}
+	return 0
+}
+
+// recordDropped is part of the backpressureReporter interface.
+func (c *chaosSink) recordDropped() {
+	if b, ok := c.sink.(backpressureReporter); ok {
+		b.recordDropped()
+	}
+}
+
+// processChaos is the part of the chaos mode which is not specific to a
+// sink: the delays injected before entries are output, and the failures
+// of the flushes and rotations of the log file.
+var processChaos struct {
+	syncutil.Mutex
+	opts chaosOptions
+	rng  *rand.Rand
+}
+
+func init() {
+	if sinkChaosSpec == "" || build.IsRelease() {
+		return
+	}
+	// Invalid specifications are reported by maybeChaos.
+	if opts, err := parseChaosOptions(sinkChaosSpec); err == nil {
+		setProcessChaos(opts)
+	}
+}
+
+// setProcessChaos configures the part of the chaos mode which is not
+// specific to a sink.
+func setProcessChaos(opts chaosOptions) {
+	processChaos.Lock()
+	defer processChaos.Unlock()
+	processChaos.opts = opts
+	processChaos.rng = rand.New(rand.NewSource(opts.seed))
+}
+
+// injectChaosDelay sleeps for the delay injected before an entry is
+// output, if any. It is called without logging.mu held, so that only the
+// goroutine logging the entry is slowed down.
+func injectChaosDelay() {
+	processChaos.Lock()
+	var d time.Duration
+	if processChaos.rng != nil && processChaos.opts.maxDelay > 0 &&
+		processChaos.rng.Float64() < processChaos.opts.delayProb {
+		d = time.Duration(processChaos.rng.Int63n(int64(processChaos.opts.maxDelay)))
+	}
+	processChaos.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// injectChaosFault returns true if the operation whose probability of
+// failure is returned by prob must fail.
+func injectChaosFault(prob func(chaosOptions) float64) bool {
+	processChaos.Lock()
+	defer processChaos.Unlock()
+	return processChaos.rng != nil && processChaos.rng.Float64() < prob(processChaos.opts)
+}
+
+func chaosFlushProb(opts chaosOptions) float64  { return opts.flushProb }
+func chaosRotateProb(opts chaosOptions) float64 { return opts.rotateProb }
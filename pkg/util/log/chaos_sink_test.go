@@ -0,0 +1,150 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestParseChaosOptions(t *testing.T) {
+	opts, err := parseChaosOptions("seed=42, delay=0.1:50ms,partial=0.05,error=0.2,flush=0.3,rotate=0.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := chaosOptions{
+		seed: 42, delayProb: 0.1, maxDelay: 50 * time.Millisecond, partialProb: 0.05, errProb: 0.2,
+		flushProb: 0.3, rotateProb: 0.4,
+	}
+	if opts != expected {
+		t.Errorf("expected %+v, got %+v", expected, opts)
+	}
+	for _, spec := range []string{"seed", "delay=0.1", "error=x", "unknown=1"} {
+		if _, err := parseChaosOptions(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestChaosSink(t *testing.T) {
+	run := func(seed int64) ([]error, []string) {
+		sink := &testSink{name: "test"}
+		chaos := newChaosSink(sink, chaosOptions{seed: seed, partialProb: 0.3, errProb: 0.3})
+		var errs []error
+		for i := 0; i < 50; i++ {
+			errs = append(errs, chaos.write([]byte(fmt.Sprintf("entry %d", i))))
+		}
+		return errs, sink.entries
+	}
+
+	errs, entries := run(1)
+	var failed, partial int
+	for _, err := range errs {
+		switch err {
+		case errChaos:
+			failed++
+		case io.ErrShortWrite:
+			partial++
+		}
+	}
+	if failed == 0 || partial == 0 || failed+partial == len(errs) {
+		t.Errorf("expected a mix of failures, partial and full writes, got %v", errs)
+	}
+	if len(entries) != len(errs)-failed {
+		t.Errorf("expected %d entries to reach the sink, got %d", len(errs)-failed, len(entries))
+	}
+
+	// The injected failures are reproducible.
+	if errs2, entries2 := run(1); !reflect.DeepEqual(errs, errs2) || !reflect.DeepEqual(entries, entries2) {
+		t.Error("expected the same seed to inject the same failures")
+	}
+
+	// Failover chains route around the injected errors.
+	fallback := &testSink{name: "fallback"}
+	chain := newFailoverSink([]logSink{
+		newChaosSink(&testSink{name: "primary"}, chaosOptions{errProb: 1}), fallback,
+	}, time.Minute)
+	if err := chain.write([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if len(fallback.entries) != 1 {
+		t.Errorf("expected the entry to fail over, got %v", fallback.entries)
+	}
+}
+
+func TestChaosLogFile(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	var exitErr error
+	defer func(previous func(error)) { logExitFunc = previous }(logExitFunc)
+	logExitFunc = func(e error) {
+		exitErr = e
+	}
+	defer func(previous int64) { LogFileMaxSize = previous }(LogFileMaxSize)
+	LogFileMaxSize = 2048
+	defer setProcessChaos(chaosOptions{})
+
+	Info(context.Background(), "x") // Be sure we have a file.
+	sb, ok := logging.file.(*syncBuffer)
+	if !ok {
+		t.Fatal("log file wasn't created")
+	}
+	fname := sb.file.Name()
+
+	// Failed flushes are reported, and the entries remain buffered.
+	setProcessChaos(chaosOptions{flushProb: 1})
+	logging.mu.Lock()
+	err := logging.flushFile()
+	logging.mu.Unlock()
+	if err != errChaos {
+		t.Errorf("expected %v, got %v", errChaos, err)
+	}
+
+	// Failed rotations leave the current file in use.
+	setProcessChaos(chaosOptions{rotateProb: 1})
+	Info(context.Background(), strings.Repeat("x", int(LogFileMaxSize)))
+	Info(context.Background(), "x")
+	if exitErr != nil {
+		t.Fatalf("unexpected exit: %v", exitErr)
+	}
+	if sb.file.Name() != fname {
+		t.Errorf("expected the rotation to fail, got a new file %s", sb.file.Name())
+	}
+
+	// Delays do not fail anything, and once the faults stop, the rotation
+	// succeeds.
+	setProcessChaos(chaosOptions{delayProb: 1, maxDelay: time.Millisecond})
+	Info(context.Background(), "x")
+	if exitErr != nil {
+		t.Fatalf("unexpected exit: %v", exitErr)
+	}
+	if sb.file.Name() == fname {
+		t.Error("expected the file to be rotated once the faults stopped")
+	}
+	logging.mu.Lock()
+	err = logging.flushFile()
+	logging.mu.Unlock()
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/petermattis/goid"
+	"golang.org/x/net/context"
 )
 
 const severityChar = "IWEF"
@@ -203,8 +204,21 @@ type modulePat struct {
 }
 
 // match reports whether the file matches the pattern. It uses a string
-// comparison if the pattern contains no metacharacters.
+// comparison if the pattern contains no metacharacters. The file is its
+// path stripped of the .go suffix; patterns containing slashes, such as
+// "storage/*", match its last as many path components, and the other
+// patterns its base name, so that the verbosity can be raised for a whole
+// package.
 func (m *modulePat) match(file string) bool {
+	n := strings.Count(m.pattern, "/") + 1
+	for i := len(file) - 1; i >= 0; i-- {
+		if file[i] == '/' {
+			if n--; n == 0 {
+				file = file[i+1:]
+				break
+			}
+		}
+	}
 	if m.literal {
 		return file == m.pattern
 	}
@@ -228,7 +242,7 @@ func (m *moduleSpec) String() string {
 
 var errVmoduleSyntax = errors.New("syntax error: expect comma-separated list of filename=N")
 
-// Syntax: --vmodule=recordio=2,file=1,gfs*=3
+// Syntax: --vmodule=recordio=2,file=1,gfs*=3,storage/*=2
 func (m *moduleSpec) Set(value string) error {
 	var filter []modulePat
 	for _, pat := range strings.Split(value, ",") {
@@ -339,12 +353,55 @@ func (t *traceLocation) Set(value string) error {
 var entryRE = regexp.MustCompile(
 	`(?m)^([IWEF])(\d{6} \d{2}:\d{2}:\d{2}.\d{6}) (?:(\d+) )?([^:]+):(\d+)`)
 
+// FormatVersion is the version of the format of the entries in log
+// files, which is recorded in the header of every log file. It is
+// incremented on every incompatible change of the format, and
+// EntryDecoder keeps decoding the entries of all the previous versions,
+// so that archived log files remain readable. Entries of version 1 do
+// not include a goroutine ID; entries of version 2 include the ID of the
+// logging goroutine. Files written before the version was recorded use
+// either of them.
+const FormatVersion = 2
+
+// formatVersionPrefix prefixes the message of the log file header entry
+// which records the format version.
+const formatVersionPrefix = "[config] line format version: "
+
 // EntryDecoder reads successive encoded log entries from the input
 // buffer. Each entry is preceded by a single big-ending uint32
 // describing the next entry's length.
 type EntryDecoder struct {
 	scanner            *bufio.Scanner
 	truncatedLastEntry bool
+	// json is set once an entry in the json format was found, after which
+	// the lines starting with '{' are entries of their own, and
+	// truncatedJSONLine is set while the rest of a line too long to be
+	// decoded is skipped.
+	json              bool
+	truncatedJSONLine bool
+	// truncated is set by split when it returns a truncated entry, and
+	// pendingGap is the entry annotating the truncation, returned by the
+	// next call to Decode.
+	truncated  bool
+	pendingGap *Entry
+	// lastTime is the time of the last entry decoded.
+	lastTime int64
+	// firstSeq and hasSeq are the sequence number recorded in the header
+	// of the log file, if any, and count is the number of entries decoded
+	// so far, besides the header.
+	firstSeq uint64
+	hasSeq   bool
+	count    uint64
+	version  int
+	// templates are the message templates defined so far in the log
+	// file, by ID.
+	templates map[int]messageTemplate
+}
+
+// Version returns the format version recorded in the header of the log
+// file being decoded, or 0 if none was found so far.
+func (d *EntryDecoder) Version() int {
+	return d.version
 }
 
 // NewEntryDecoder creates a new instance of EntryDecoder.
@@ -355,7 +412,16 @@ func NewEntryDecoder(in io.Reader) *EntryDecoder {
 }
 
 // Decode decodes the next log entry into the provided protobuf message.
+//
+// Where entries are missing because they were truncated, an entry
+// annotating the gap, for which IsGap returns true, is returned after the
+// truncated entry.
 func (d *EntryDecoder) Decode(entry *Entry) error {
+	if d.pendingGap != nil {
+		*entry = *d.pendingGap
+		d.pendingGap = nil
+		return nil
+	}
 	for {
 		if !d.scanner.Scan() {
 			if err := d.scanner.Err(); err != nil {
@@ -364,38 +430,117 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 			return io.EOF
 		}
 		b := d.scanner.Bytes()
-		m := entryRE.FindSubmatch(b)
-		if m == nil {
-			continue
-		}
-		entry.Severity = Severity(strings.IndexByte(severityChar, m[1][0]) + 1)
-		t, err := time.ParseInLocation("060102 15:04:05.999999", string(m[2]), time.Local)
-		if err != nil {
+		truncated := d.truncated
+		d.truncated = false
+		if len(b) > 0 && b[0] == '{' {
+			if err := decodeJSONEntry(b, entry); err != nil {
+				if truncated {
+					*entry = makeGap(d.lastTime, "an entry longer than %d bytes was skipped", len(b))
+					return nil
+				}
+				// Not an entry, such as the output of a panic.
+				continue
+			}
+		} else if err := decodeEntry(b, entry); err != nil {
+			if err == errNotAnEntry {
+				continue
+			}
 			return err
 		}
-		entry.Time = t.UnixNano()
-		if len(m[3]) > 0 {
-			goroutine, err := strconv.Atoi(string(m[3]))
+		if strings.HasPrefix(entry.Message, formatVersionPrefix) {
+			v, err := strconv.Atoi(entry.Message[len(formatVersionPrefix):])
 			if err != nil {
+				return fmt.Errorf("malformed log format version: %s", err)
+			}
+			if v > FormatVersion {
+				return fmt.Errorf("log format version %d is newer than the supported version %d",
+					v, FormatVersion)
+			}
+			d.version = v
+		} else if strings.HasPrefix(entry.Message, entrySequencePrefix) {
+			if err := d.parseEntrySequence(entry.Message); err != nil {
 				return err
 			}
-			entry.Goroutine = int64(goroutine)
+		} else if strings.HasPrefix(entry.Message, templateDefPrefix) {
+			if err := d.defineTemplate(entry.Message[len(templateDefPrefix):]); err != nil {
+				return err
+			}
+		} else if msg, ok := d.expandInterned(entry.Message); ok {
+			entry.Message = strings.TrimSpace(msg)
+		}
+		if !isHeaderEntry(entry.Message) {
+			d.count++
+		}
+		d.lastTime = entry.Time
+		if truncated {
+			gap := makeGap(entry.Time, "entry at %s:%d truncated to %d bytes", entry.File, entry.Line, len(b))
+			d.pendingGap = &gap
 		}
-		entry.File = string(m[4])
-		line, err := strconv.Atoi(string(m[5]))
+		return nil
+	}
+}
+
+// errNotAnEntry is returned by decodeEntry for the input which is not a
+// log entry.
+var errNotAnEntry = errors.New("not a log entry")
+
+// decodeEntry decodes an entry in the crdb-v1 format.
+func decodeEntry(b []byte, entry *Entry) error {
+	m := entryRE.FindSubmatch(b)
+	if m == nil {
+		return errNotAnEntry
+	}
+	entry.Severity = Severity(strings.IndexByte(severityChar, m[1][0]) + 1)
+	t, err := time.ParseInLocation("060102 15:04:05.999999", string(m[2]), time.Local)
+	if err != nil {
+		return err
+	}
+	entry.Time = t.UnixNano()
+	if len(m[3]) > 0 {
+		goroutine, err := strconv.Atoi(string(m[3]))
 		if err != nil {
 			return err
 		}
-		entry.Line = int64(line)
-		entry.Message = strings.TrimSpace(string(b[len(m[0]):]))
-		return nil
+		entry.Goroutine = int64(goroutine)
+	}
+	entry.File = string(m[4])
+	line, err := strconv.Atoi(string(m[5]))
+	if err != nil {
+		return err
 	}
+	entry.Line = int64(line)
+	entry.Message = strings.TrimSpace(string(b[len(m[0]):]))
+	return nil
 }
 
 func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
 	}
+	if d.truncatedJSONLine {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return len(data), nil, nil
+		}
+		d.truncatedJSONLine = false
+		return i + 1, nil, nil
+	}
+	if data[0] == '{' {
+		// An entry in the json format spans a single line.
+		d.json = true
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			return i + 1, data[:i+1], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		if len(data) >= bufio.MaxScanTokenSize {
+			d.truncatedJSONLine = true
+			d.truncated = true
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
 	if d.truncatedLastEntry {
 		i := entryRE.FindIndex(data)
 		if i == nil {
@@ -416,6 +561,13 @@ func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte
 	// From this point on, we assume we're currently positioned at a log entry.
 	// We want to find the next one so we start our search at data[1].
 	i := entryRE.FindIndex(data[1:])
+	if d.json {
+		// Lines which are not entries, such as the output of a panic, end
+		// at the next entry in the json format.
+		if j := bytes.Index(data[1:], []byte("\n{")); j >= 0 && (i == nil || j+1 < i[0]) {
+			i = []int{j + 1, j + 2}
+		}
+	}
 	if i == nil {
 		if atEOF {
 			return len(data), data, nil
@@ -424,6 +576,7 @@ func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte
 			// If there's no room left in the buffer, return the current truncated
 			// entry.
 			d.truncatedLastEntry = true
+			d.truncated = true
 			return len(data), data, nil
 		}
 		// If there is still room to read more, ask for more before deciding whether
@@ -595,6 +748,9 @@ func init() {
 	// commands set their default separately in cli/flags.go
 	logging.stderrThreshold = Severity_INFO
 	logging.fileThreshold = Severity_INFO
+	logging.fileFlushThreshold = Severity_WARNING
+	logging.stderrFormat = formatCrdbV1TTY
+	logging.fileFormat = formatCrdbV1
 
 	logging.setVState(0, nil, false)
 	logging.exitFunc = os.Exit
@@ -642,6 +798,14 @@ type loggingT struct {
 	stderrThreshold Severity
 	// Level flag for output to files.
 	fileThreshold Severity
+	// Entries at or above this severity are flushed to the log file
+	// immediately instead of remaining buffered. Handled atomically.
+	fileFlushThreshold Severity
+	// Formats of the entries written to stderr and to files.
+	stderrFormat, fileFormat outputFormat
+	// Whether the log tags and message arguments not marked as Safe are
+	// redacted from the entries written to stderr and to files.
+	stderrRedact, fileRedact bool
 
 	// freeList is a list of byte buffers, maintained under freeListMu.
 	freeList *buffer
@@ -655,8 +819,40 @@ type loggingT struct {
 	mu syncutil.Mutex
 	// file holds the log file writer.
 	file flushSyncWriter
+	// fileSeq is the number of entries written to the log files so far,
+	// recorded in the header of every log file.
+	fileSeq uint64
 	// syncWrites if true calls file.Flush on every log write.
 	syncWrites bool
+	// fileFallback, if set, is the reason why the log files were disabled
+	// in favor of stderr at startup.
+	fileFallback string
+	// sinks are the destinations of log entries besides stderr and files.
+	sinks []sinkConfig
+	// sinksInitialized is set once the sinks configured by flags have
+	// been created.
+	sinksInitialized bool
+	// degradeSinks if true causes entries below WARNING to be dropped
+	// from the sinks under backpressure, as if the cluster setting was
+	// enabled.
+	degradeSinks bool
+	// sinkSampling, if greater than 1, causes only one in sinkSampling
+	// of the entries below WARNING from each call site to be sent to the
+	// sinks, and sinkSamples counts the entries of each call site.
+	sinkSampling int
+	sinkSamples  map[callSite]int
+	// recent retains the latest entries for GetRecentEntries.
+	recent recentEntries
+	// breadcrumbs retains the latest entries for crash reports.
+	breadcrumbs breadcrumbs
+	// templates are the message templates interned for the log files
+	// written in the crdb-v1-interned format, by format string, and
+	// templateFormats their format strings, by ID minus one.
+	templates       map[string]*internedTemplate
+	templateFormats []string
+	// interceptors is the chain of interceptors applied to entries before
+	// they are output.
+	interceptors []namedInterceptor
 	// pcs is used in V to avoid an allocation when computing the caller's PC.
 	pcs [1]uintptr
 	// vmap is a cache of the V Level for each V() call site, identified by PC.
@@ -738,24 +934,51 @@ func (l *loggingT) putBuffer(b *buffer) {
 
 // outputLogEntry marshals a log entry proto into bytes, and writes
 // the data to the log files. If a trace location is set, stack traces
-// are added to the entry before marshaling.
-func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string) {
+// are added to the entry before marshaling. redactedMsg is the variant
+// of msg written to the outputs configured to redact entries, and
+// markedMsg, if set, the variant written to the log files otherwise,
+// where the values wrapped in Unsafe are delimited. format
+// and args, from which msg was formatted, are used to intern the message
+// template in the log files; format is empty if they are unknown.
+// sensitivity is the class of the entry, see WithSensitivity.
+func (l *loggingT) outputLogEntry(
+	s Severity,
+	sensitivity Sensitivity,
+	file string,
+	line int,
+	msg, redactedMsg, markedMsg string,
+	format string,
+	args []interface{},
+) {
+	injectChaosDelay()
+
 	// TODO(tschottdorf): this is a pretty horrible critical section.
 	l.mu.Lock()
 
 	// Set additional details in log entry.
 	now := time.Now()
 	entry := Entry{
-		Severity:  s,
-		Time:      now.UnixNano(),
-		Goroutine: goid.Get(),
-		File:      file,
-		Line:      int64(line),
-		Message:   msg,
+		Severity:    s,
+		Time:        now.UnixNano(),
+		Goroutine:   goid.Get(),
+		File:        file,
+		Line:        int64(line),
+		Message:     msg,
+		Sensitivity: sensitivity,
 	}
 	// On fatal log, set all stacks.
 	var stacks []byte
 	if s == Severity_FATAL {
+		// Save the stacks of all goroutines alongside the log files so that
+		// the postmortem does not depend on the GOTRACEBACK setting or on
+		// stderr having been captured.
+		if logDir.isSet() {
+			if path, err := writeGoroutineDump(now); err != nil {
+				entry.Message += fmt.Sprintf("\n(unable to write stacks of all goroutines: %s)", err)
+			} else {
+				entry.Message += "\nstacks of all goroutines written to: " + path
+			}
+		}
 		switch traceback {
 		case tracebackSingle:
 			stacks = getStacks(false)
@@ -769,9 +992,42 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 		}
 	}
 
+	redactedEntry := entry
+	redactedEntry.Message = redactedMsg
+	unintercepted := entry.Message
+	if !l.intercept(&entry, &redactedEntry) {
+		l.mu.Unlock()
+		return
+	}
+	// The marked message is only used if the interceptors left the message
+	// alone. It is followed by the notes appended to msg above.
+	fileEntry, fileArgs := entry, args
+	if markedMsg != "" && entry.Message == unintercepted {
+		fileEntry.Message = markedMsg + entry.Message[len(msg):]
+		fileArgs = make([]interface{}, len(args))
+		for i, arg := range args {
+			fileArgs[i] = markedValue(arg)
+		}
+	}
+
 	if s >= l.stderrThreshold.get() {
-		l.outputToStderr(entry, stacks)
+		if l.stderrRedact {
+			l.outputToStderr(redactedEntry, stacks)
+		} else {
+			l.outputToStderr(entry, stacks)
+		}
 	}
+	l.outputToSinks(entry, stacks)
+	if s >= recentEntriesThreshold {
+		l.recent.add(entry)
+	}
+	l.breadcrumbs.add(breadcrumb{
+		severity: s,
+		time:     entry.Time,
+		file:     file,
+		line:     line,
+		template: reportableTemplate(format, args),
+	})
 	if logDir.isSet() && s >= l.fileThreshold.get() {
 		if l.file == nil {
 			if err := l.createFile(); err != nil {
@@ -783,15 +1039,27 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 			}
 		}
 
-		buf := l.processForFile(entry, stacks)
+		var buf *buffer
+		if l.fileRedact {
+			buf = l.processForFile(redactedEntry, stacks)
+		} else if b, ok := l.processInternedForFile(fileEntry, stacks, format, fileArgs); ok {
+			buf = b
+		} else {
+			buf = l.processForFile(fileEntry, stacks)
+		}
 		data := buf.Bytes()
 
 		if _, err := l.file.Write(data); err != nil {
 			panic(err)
 		}
+		// The entry was written after the header of the file, if it caused
+		// a rotation.
+		l.fileSeq++
 		if l.syncWrites {
 			_ = l.file.Flush()
 			_ = l.file.Sync()
+		} else if s >= l.fileFlushThreshold.get() {
+			_ = l.file.Flush()
 		}
 
 		l.putBuffer(buf)
@@ -801,7 +1069,9 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 	// Flush and exit on fatal logging.
 	if s == Severity_FATAL {
 		// If we got here via Exit rather than Fatal, print no stacks.
-		timeoutFlush(10 * time.Second)
+		ctx, cancel := context.WithDeadline(context.Background(), fatalFlushDeadline())
+		FlushAll(ctx)
+		cancel()
 		exitFunc(255) // C++ uses -1, which is silly because it's anded with 255 anyway.
 	}
 }
@@ -816,12 +1086,23 @@ func (l *loggingT) outputToStderr(entry Entry, stacks []byte) {
 
 // processForStderr formats a log entry for output to standard error.
 func (l *loggingT) processForStderr(entry Entry, stacks []byte) *buffer {
-	return formatLogEntry(entry, stacks, l.getTermColorProfile())
+	return l.stderrFormat.formatEntry(entry, stacks, l.getTermColorProfile())
 }
 
 // processForFile formats a log entry for output to a file.
 func (l *loggingT) processForFile(entry Entry, stacks []byte) *buffer {
-	return formatLogEntry(entry, stacks, nil)
+	return l.fileFormat.formatEntry(entry, stacks, colorProfile256)
+}
+
+// processInternedForFile formats an entry for the log file if it is
+// written in the crdb-v1-interned format and the entry can be interned.
+func (l *loggingT) processInternedForFile(
+	entry Entry, stacks []byte, format string, args []interface{},
+) (*buffer, bool) {
+	if l.fileFormat != formatCrdbV1Interned {
+		return nil, false
+	}
+	return l.formatInterned(entry, stacks, format, args)
 }
 
 // checkForColorTerm attempts to verify that stderr is a character
@@ -861,23 +1142,6 @@ func (l *loggingT) getTermColorProfile() *colorProfile {
 	return l.colorProfile
 }
 
-// timeoutFlush calls Flush and returns when it completes or after timeout
-// elapses, whichever happens first.  This is needed because the hooks invoked
-// by Flush may deadlock when clog.Fatal is called from a hook that holds
-// a lock.
-func timeoutFlush(timeout time.Duration) {
-	done := make(chan bool, 1)
-	go func() {
-		Flush() // calls logging.lockAndFlushAll()
-		done <- true
-	}()
-	select {
-	case <-done:
-	case <-time.After(timeout):
-		fmt.Fprintln(OrigStderr, "clog: Flush took longer than", timeout)
-	}
-}
-
 // getStacks is a wrapper for runtime.Stack that attempts to recover the data for all goroutines.
 func getStacks(all bool) []byte {
 	// We don't know how big the traces are, so grow a few times if they don't fit. Start large, though.
@@ -938,9 +1202,11 @@ func (sb *syncBuffer) Sync() error {
 
 func (sb *syncBuffer) Write(p []byte) (n int, err error) {
 	if sb.nbytes+int64(len(p)) >= atomic.LoadInt64(&LogFileMaxSize) {
-		if err := sb.rotateFile(time.Now()); err != nil {
+		if err := sb.rotateFile(time.Now()); err != nil && sb.file == nil {
 			sb.logger.exit(err)
 		}
+		// Otherwise the current file is still usable, and the rotation is
+		// retried with the next entry.
 	}
 	n, err = sb.Writer.Write(p)
 	sb.nbytes += int64(n)
@@ -950,13 +1216,18 @@ func (sb *syncBuffer) Write(p []byte) (n int, err error) {
 	return
 }
 
-// rotateFile closes the syncBuffer's file and starts a new one.
+// rotateFile closes the syncBuffer's file and starts a new one. If it
+// fails, sb.file is only nil if the current file is no longer usable.
 func (sb *syncBuffer) rotateFile(now time.Time) error {
 	if sb.file != nil {
+		if injectChaosFault(chaosRotateProb) {
+			return errChaos
+		}
 		if err := sb.Flush(); err != nil {
 			return err
 		}
 		if err := sb.file.Close(); err != nil {
+			sb.file = nil
 			return err
 		}
 	}
@@ -983,7 +1254,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	sb.Writer = bufio.NewWriterSize(sb.file, bufferSize)
 
 	f, l, _ := caller.Lookup(1)
-	for _, msg := range []string{
+	msgs := []string{
 		fmt.Sprintf("[config] file created at: %s\n", now.Format("2006/01/02 15:04:05")),
 		fmt.Sprintf("[config] running on machine: %s\n", host),
 		fmt.Sprintf("[config] binary: %s\n", build.GetInfo().Short()),
@@ -991,8 +1262,21 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 		// Including a non-ascii character in the first 1024 bytes of the log helps
 		// viewers that attempt to guess the character encoding.
 		fmt.Sprintf("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713\n"),
-	} {
-		buf := formatLogEntry(Entry{
+		fmt.Sprintf("%s%d\n", formatVersionPrefix, FormatVersion),
+		fmt.Sprintf("%s%d\n", entrySequencePrefix, logging.fileSeq),
+	}
+	if logging.fileRedact {
+		msgs = append(msgs, redactedFileHeader)
+	} else {
+		msgs = append(msgs, markedFileHeader)
+	}
+	if logging.fileFormat == formatCrdbV1Interned {
+		for i, format := range logging.templateFormats {
+			msgs = append(msgs, formatTemplateDef(i+1, format))
+		}
+	}
+	for _, msg := range msgs {
+		buf := logging.fileFormat.formatEntry(Entry{
 			Severity:  Severity_INFO,
 			Time:      now.UnixNano(),
 			Goroutine: goid.Get(),
@@ -1009,10 +1293,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 		logging.putBuffer(buf)
 	}
 
-	select {
-	case logging.gcNotify <- struct{}{}:
-	default:
-	}
+	notifyGC()
 	return nil
 }
 
@@ -1053,6 +1334,7 @@ const flushInterval = 30 * time.Second
 
 // flushDaemon periodically flushes the log file buffers.
 func (l *loggingT) flushDaemon() {
+	setProfilerLabels(profilerLabelFlush)
 	// doesn't need to be Stop()'d as the loop never escapes
 	for range time.Tick(flushInterval) {
 		l.mu.Lock()
@@ -1080,20 +1362,46 @@ func (l *loggingT) lockAndSetSync(sync bool) {
 // flushAll flushes all the logs and attempts to "sync" their data to disk.
 // l.mu is held.
 func (l *loggingT) flushAll() {
-	if l.file != nil {
-		_ = l.file.Flush() // ignore error
-		_ = l.file.Sync()  // ignore error
+	_ = l.flushFile() // ignore error
+}
+
+// flushFile flushes the log file and syncs its data to disk, and returns
+// the first error encountered.
+// l.mu is held.
+func (l *loggingT) flushFile() error {
+	if l.file == nil {
+		return nil
+	}
+	if injectChaosFault(chaosFlushProb) {
+		return errChaos
 	}
+	err := l.file.Flush()
+	if syncErr := l.file.Sync(); err == nil {
+		err = syncErr
+	}
+	return err
 }
 
 func (l *loggingT) gcDaemon() {
+	setProfilerLabels(profilerLabelGC)
 	l.gcOldFiles()
-	for range l.gcNotify {
+	l.compressOldFiles()
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.gcNotify:
+		case <-ticker.C:
+		}
 		l.mu.Lock()
-		if !l.disableDaemons {
+		disabled := l.disableDaemons
+		if !disabled {
 			l.gcOldFiles()
 		}
 		l.mu.Unlock()
+		if !disabled {
+			l.compressOldFiles()
+		}
 	}
 }
 
@@ -1106,11 +1414,13 @@ func (l *loggingT) gcOldFiles() {
 
 	allFiles, err := ListLogFiles()
 	if err != nil {
-		fmt.Fprintf(OrigStderr, "unable to GC log files: %s\n", err)
+		metaLogf("gc", "unable to GC log files: %s", err)
 		return
 	}
 
 	logFilesCombinedMaxSize := atomic.LoadInt64(&LogFilesCombinedMaxSize)
+	maxAge := effectiveFileMaxAge()
+	now := time.Now().UnixNano()
 	files := selectFiles(allFiles, math.MaxInt64)
 	if len(files) == 0 {
 		return
@@ -1120,12 +1430,12 @@ func (l *loggingT) gcOldFiles() {
 	sum := files[0].SizeBytes
 	for _, f := range files[1:] {
 		sum += f.SizeBytes
-		if sum < logFilesCombinedMaxSize {
+		if sum < logFilesCombinedMaxSize && (maxAge == 0 || now-f.ModTimeNanos <= int64(maxAge)) {
 			continue
 		}
 		path := filepath.Join(dir, f.Name)
 		if err := os.Remove(path); err != nil {
-			fmt.Fprintln(OrigStderr, err)
+			metaLogf("gc", "unable to GC log file: %s", err)
 		}
 	}
 }
@@ -1172,7 +1482,9 @@ func (lb logBridge) Write(b []byte) (n int, err error) {
 			line = 1
 		}
 	}
-	logging.outputLogEntry(Severity(lb), file, line, text)
+	// The arguments of messages logged through the standard library
+	// logger are unknown, so the message is redacted entirely.
+	logging.outputLogEntry(Severity(lb), Sensitivity_UNCLASSIFIED, file, line, text, redactedMarker, "", "", nil)
 	return len(b), nil
 }
 
@@ -1184,20 +1496,16 @@ func NewStdLogger(severity Severity) *stdLog.Logger {
 
 // setV computes and remembers the V level for a given PC
 // when vmodule is enabled.
-// File pattern matching takes the basename of the file, stripped
-// of its .go suffix, and uses filepath.Match, which is a little more
-// general than the *? matching used in C++.
+// File pattern matching takes the path of the file, stripped of its .go
+// suffix, and uses filepath.Match, which is a little more general than
+// the *? matching used in C++.
 // l.mu is held.
 func (l *loggingT) setV(pc uintptr) level {
 	fn := runtime.FuncForPC(pc)
 	file, _ := fn.FileLine(pc)
-	// The file is something like /a/b/c/d.go. We want just the d.
-	if strings.HasSuffix(file, ".go") {
-		file = file[:len(file)-3]
-	}
-	if slash := strings.LastIndex(file, "/"); slash >= 0 {
-		file = file[slash+1:]
-	}
+	// The file is something like /a/b/c/d.go. We want /a/b/c/d, whose base
+	// name or parent directories are matched by the patterns.
+	file = strings.TrimSuffix(file, ".go")
 	for _, filter := range l.vmodule.filter {
 		if filter.match(file) {
 			l.vmap[pc] = filter.level
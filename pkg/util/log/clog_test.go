@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/ioutil"
 	stdLog "log"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -141,6 +142,51 @@ func TestStandardLog(t *testing.T) {
 	}
 }
 
+// Verify that log files written in all the format versions can be
+// decoded.
+func TestEntryDecoderFormatVersions(t *testing.T) {
+	testCases := []struct {
+		version  int
+		contents string
+		expected Entry
+	}{
+		// Version 1 entries have no goroutine ID, and the files did not
+		// record the version.
+		{0, "I170614 15:04:05.123456 server.go:12 [n1] started\n",
+			Entry{Severity: Severity_INFO, File: "server.go", Line: 12, Message: "[n1] started"}},
+		{2, "I170614 15:04:05.123456 1 clog.go:1 " + formatVersionPrefix + "2\n" +
+			"W170614 15:04:05.123456 42 server.go:12 [n1] started\n",
+			Entry{Severity: Severity_WARNING, Goroutine: 42, File: "server.go", Line: 12, Message: "[n1] started"}},
+	}
+	for _, tc := range testCases {
+		decoder := NewEntryDecoder(strings.NewReader(tc.contents))
+		var entry Entry
+		for {
+			if err := decoder.Decode(&entry); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(entry.Message, formatVersionPrefix) {
+				break
+			}
+		}
+		entry.Time = 0
+		if !reflect.DeepEqual(entry, tc.expected) {
+			t.Errorf("version %d: expected %+v, got %+v", tc.version, tc.expected, entry)
+		}
+		if v := decoder.Version(); v != tc.version {
+			t.Errorf("expected version %d, got %d", tc.version, v)
+		}
+	}
+
+	// Entries written in a newer format are not misinterpreted.
+	decoder := NewEntryDecoder(strings.NewReader(
+		"I170614 15:04:05.123456 1 clog.go:1 " + formatVersionPrefix + "3\n"))
+	var entry Entry
+	if err := decoder.Decode(&entry); err == nil || !strings.Contains(err.Error(), "newer than the supported version") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 // Verify that a log can be fetched in JSON format.
 func TestEntryDecoder(t *testing.T) {
 	formatEntry := func(s Severity, now time.Time, gid int, file string, line int, msg string) string {
@@ -388,6 +434,14 @@ var vGlobs = map[string]bool{
 	"m*=2":         false,
 	"??_*=2":       false,
 	"?[abc]?_*t=2": false,
+	// Patterns with slashes match the trailing components of the path.
+	"log/clog_test=2": true,
+	"util/log/*=2":    true,
+	"log/*=2":         true,
+	"util/*=2":        false,
+	"sql/clog_test=2": false,
+	"*/log/clog_t*=2": true,
+	"util/log/clog=2": false,
 }
 
 // Test that vmodule globbing works as advertised.
@@ -408,6 +462,44 @@ func TestVmoduleGlob(t *testing.T) {
 	}
 }
 
+func TestSetVModule(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+	defer func() { _ = logging.vmodule.Set("") }()
+
+	ctx := context.Background()
+	if err := SetVModule(ctx, "util/log/*=2,raft=3"); err != nil {
+		t.Fatal(err)
+	}
+	if !v(2) {
+		t.Error("expected the verbosity of the package to be raised")
+	}
+	if spec := GetVModule(); spec != "util/log/*=2,raft=3" {
+		t.Errorf("unexpected spec %q", spec)
+	}
+	w := httptest.NewRecorder()
+	handleGetVModule(w, httptest.NewRequest("GET", httpLogLevelPath, nil))
+	if body := w.Body.String(); body != "util/log/*=2,raft=3\n" {
+		t.Errorf("unexpected response %q", body)
+	}
+
+	if err := SetVModule(ctx, "raft=x"); err == nil {
+		t.Error("expected an error for a malformed spec")
+	}
+	if spec := GetVModule(); spec != "util/log/*=2,raft=3" {
+		t.Errorf("expected a malformed spec to be ignored, got %q", spec)
+	}
+
+	if err := SetVModule(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if v(2) {
+		t.Error("expected the verbosity to be restored")
+	}
+}
+
 func TestListLogFiles(t *testing.T) {
 	s := ScopeWithoutShowLogs(t)
 	defer s.Close(t)
@@ -774,6 +866,32 @@ func TestFileSeverityFilter(t *testing.T) {
 	}
 }
 
+func TestFileFlushThreshold(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	logging.fileFlushThreshold = Severity_ERROR
+	defer func() { logging.fileFlushThreshold = Severity_WARNING }()
+
+	readLog := func() string {
+		contents, err := ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(contents)
+	}
+
+	Warningf(context.Background(), "test1")
+	if contents := readLog(); strings.Contains(contents, "test1") {
+		t.Errorf("warning text was flushed below the flush threshold\n%s", contents)
+	}
+	Errorf(context.Background(), "test2")
+	if contents := readLog(); !strings.Contains(contents, "test1") || !strings.Contains(contents, "test2") {
+		t.Errorf("log was not flushed at the flush threshold\n%s", contents)
+	}
+}
+
 func BenchmarkHeader(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		buf := formatHeader(Severity_INFO, time.Now(), 200, "file.go", 100, nil)
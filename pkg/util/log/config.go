@@ -0,0 +1,85 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"sync/atomic"
+
+	raven "github.com/getsentry/raven-go"
+)
+
+// EffectiveConfig describes the logging and telemetry configuration in
+// effect in the process. It is attached to crash reports so that it is
+// possible to tell whether missing context is due to the configuration.
+type EffectiveConfig struct {
+	Profile              string `json:"profile"`
+	LogDir               string `json:"log_dir"`
+	StderrThreshold      string `json:"stderr_threshold"`
+	FileThreshold        string `json:"file_threshold"`
+	FileFlushThreshold   string `json:"file_flush_threshold"`
+	StderrFormat         string `json:"stderr_format"`
+	FileFormat           string `json:"file_format"`
+	StderrRedact         bool   `json:"stderr_redact"`
+	FileRedact           bool   `json:"file_redact"`
+	StderrRedirect       bool   `json:"stderr_redirect"`
+	SyncWrites           bool   `json:"sync_writes"`
+	Verbosity            int32  `json:"verbosity"`
+	VModule              string `json:"vmodule"`
+	FileMaxSize          int64  `json:"file_max_size"`
+	FilesCombinedMaxSize int64  `json:"files_combined_max_size"`
+	FileMaxAge           string `json:"file_max_age"`
+	FileCompression      bool   `json:"file_compression"`
+	FatalDrainTimeout    string `json:"fatal_drain_timeout"`
+	// FileFallback, if set, is the reason why the log files were disabled
+	// in favor of stderr, such as a read-only log directory.
+	FileFallback         string `json:"file_fallback,omitempty"`
+	DiagnosticsReporting bool   `json:"diagnostics_reporting"`
+	CrashReports         bool   `json:"crash_reports"`
+	CrashReporterEnabled bool   `json:"crash_reporter_enabled"`
+}
+
+// GetEffectiveConfig returns the logging and telemetry configuration
+// currently in effect.
+func GetEffectiveConfig() EffectiveConfig {
+	// The vmodule spec acquires logging.mu itself.
+	vmodule := logging.vmodule.String()
+
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	return EffectiveConfig{
+		Profile:              effectiveProfileLocked(),
+		LogDir:               logDir.String(),
+		StderrThreshold:      logging.stderrThreshold.get().String(),
+		FileThreshold:        logging.fileThreshold.get().String(),
+		FileFlushThreshold:   logging.fileFlushThreshold.get().String(),
+		StderrFormat:         logging.stderrFormat.String(),
+		FileFormat:           logging.fileFormat.String(),
+		StderrRedact:         logging.stderrRedact,
+		FileRedact:           logging.fileRedact,
+		StderrRedirect:       !logging.noStderrRedirect,
+		SyncWrites:           logging.syncWrites,
+		Verbosity:            int32(logging.verbosity.get()),
+		VModule:              vmodule,
+		FileMaxSize:          atomic.LoadInt64(&LogFileMaxSize),
+		FilesCombinedMaxSize: atomic.LoadInt64(&LogFilesCombinedMaxSize),
+		FileMaxAge:           effectiveFileMaxAge().String(),
+		FileCompression:      fileCompressionEnabled(),
+		FatalDrainTimeout:    FatalDrainTimeout.String(),
+		FileFallback:         logging.fileFallback,
+		DiagnosticsReporting: DiagnosticsReportingEnabled.Get(),
+		CrashReports:         crashReports.Get(),
+		CrashReporterEnabled: raven.DefaultClient != nil,
+	}
+}
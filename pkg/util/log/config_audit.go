@@ -0,0 +1,88 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// opsTag tags the entries recording notable events, which are of
+// interest to the operators of a cluster, such as changes to the
+// configuration.
+const opsTag = "ops"
+
+// configChangePrefix prefixes the message of the entries recording
+// changes to the logging configuration.
+const configChangePrefix = "logging configuration changed: "
+
+// configChangeEvent is the name of the event type of ConfigChange in the
+// catalog of the structured log surface.
+const configChangeEvent = "logging_config_change"
+
+func init() {
+	RegisterChannel(opsTag, "notable events of interest to the operators of a cluster")
+	RegisterEventType(configChangeEvent, opsTag,
+		"a change to the logging configuration made while the process is running",
+		configChangePrefix, ConfigChange{})
+}
+
+// ConfigChange describes a change to the logging configuration made
+// while the process is running. Every change is recorded as an entry
+// tagged with "ops", whose message is configChangePrefix followed by the
+// JSON encoding of the change, so that incident timelines can show when
+// the verbosity or the sinks were changed.
+type ConfigChange struct {
+	Time time.Time `json:"time"`
+	// Who is the origin of the change, as described by the log tags of the
+	// context in which it was requested, for instance the client and user
+	// of a SQL session.
+	Who string `json:"who"`
+	// What is the part of the configuration which was changed.
+	What string `json:"what"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// changeOrigin returns the description of the origin of a change
+// requested within ctx.
+func changeOrigin(ctx context.Context) string {
+	var buf msgBuf
+	if !formatTags(ctx, &buf) {
+		return "unknown"
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(buf.String(), "["), "] ")
+}
+
+// recordConfigChange records a change to the logging configuration
+// requested within ctx.
+func recordConfigChange(ctx context.Context, what, oldValue, newValue string) {
+	c := ConfigChange{
+		Time: time.Now().UTC(),
+		Who:  changeOrigin(ctx),
+		What: what,
+		Old:  oldValue,
+		New:  newValue,
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		Warningf(ctx, "unable to record change of %s from %q to %q: %s", what, oldValue, newValue, err)
+		return
+	}
+	Infof(WithLogTag(ctx, opsTag, nil), configChangePrefix+"%s", b)
+}
@@ -0,0 +1,71 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestRecordConfigChange(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	var c LogCapture
+	ctx := WithLogTagStr(WithLogCapture(context.Background(), &c), "user", "root")
+	c.Start()
+	EscalateVerbosity(ctx, "app", 2, time.Minute)
+	EscalateVerbosity(ctx, "app", 0, 0)
+	c.Stop()
+
+	entries, _ := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	var changes []ConfigChange
+	for _, e := range entries {
+		prefix := "[user=root," + opsTag + "] " + configChangePrefix
+		if !strings.HasPrefix(e.Message, prefix) {
+			t.Fatalf("expected %q to start with %q", e.Message, prefix)
+		}
+		var change ConfigChange
+		if err := json.Unmarshal([]byte(e.Message[len(prefix):]), &change); err != nil {
+			t.Fatal(err)
+		}
+		if change.Who != "user=root" || change.What != `verbosity of application "app"` {
+			t.Errorf("unexpected change %+v", change)
+		}
+		changes = append(changes, change)
+	}
+	if changes[0].Old != "none" || !strings.HasPrefix(changes[0].New, "2 until ") {
+		t.Errorf("unexpected escalation %+v", changes[0])
+	}
+	if changes[1].Old != changes[0].New || changes[1].New != "none" {
+		t.Errorf("unexpected cancellation %+v", changes[1])
+	}
+
+	defer func() { _ = logging.vmodule.Set("") }()
+	handleVModule(httptest.NewRecorder(), httptest.NewRequest("GET", httpLogLevelPrefix+"foo=2", nil))
+	if !contains(`"what":"vmodule","old":"","new":"foo=2"`, t) {
+		t.Errorf("expected the change of vmodule to be recorded, got %q", contents())
+	}
+}
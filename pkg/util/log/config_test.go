@@ -0,0 +1,41 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import "testing"
+
+func TestEffectiveConfig(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	defer setFlags()
+	logging.fileThreshold = Severity_WARNING
+	defer func() { logging.fileThreshold = Severity_INFO }()
+
+	cfg := GetEffectiveConfig()
+	if cfg.LogDir != s.logDir {
+		t.Errorf("expected log dir %s, got %s", s.logDir, cfg.LogDir)
+	}
+	if e := Severity_ERROR.String(); cfg.StderrThreshold != e {
+		t.Errorf("expected stderr threshold %s, got %s", e, cfg.StderrThreshold)
+	}
+	if e := Severity_WARNING.String(); cfg.FileThreshold != e {
+		t.Errorf("expected file threshold %s, got %s", e, cfg.FileThreshold)
+	}
+	if cfg.FileMaxSize != LogFileMaxSize {
+		t.Errorf("expected file max size %d, got %d", LogFileMaxSize, cfg.FileMaxSize)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// breadcrumbsSize is the number of log entries included in the
+// "breadcrumbs" extra of crash reports.
+const breadcrumbsSize = 100
+
+// maxReportGoroutinesSize bounds the size of the goroutine dump included
+// in the "goroutines" extra of crash reports, so that the dump of a busy
+// process leaves room in the report for the other extras.
+const maxReportGoroutinesSize = 50 << 10
+
+// breadcrumb is the reportable rendition of a log entry: its message is
+// the format string of the entry rather than the formatted message,
+// since the arguments may contain user data.
+type breadcrumb struct {
+	severity Severity
+	time     int64
+	file     string
+	line     int
+	template string
+}
+
+// String formats the breadcrumb as the header of a log entry followed by
+// the template of its message.
+func (b breadcrumb) String() string {
+	return fmt.Sprintf("%c%s %s:%d %s", severityChar[b.severity-1],
+		time.Unix(0, b.time).UTC().Format("060102 15:04:05.000000"),
+		filepath.Base(b.file), b.line, b.template)
+}
+
+// breadcrumbs is a ring buffer of the latest entries of all severities,
+// retained for inclusion in crash reports.
+type breadcrumbs struct {
+	buf [breadcrumbsSize]breadcrumb
+	// next is the index in buf of the next entry to be recorded, and n the
+	// number of entries recorded, up to breadcrumbsSize.
+	next, n int
+}
+
+func (b *breadcrumbs) add(c breadcrumb) {
+	b.buf[b.next] = c
+	b.next = (b.next + 1) % breadcrumbsSize
+	if b.n < breadcrumbsSize {
+		b.n++
+	}
+}
+
+// strings returns the recorded entries in the order in which they were
+// logged.
+func (b *breadcrumbs) strings() []string {
+	res := make([]string, 0, b.n)
+	for i := 0; i < b.n; i++ {
+		res = append(res, b.buf[(b.next-b.n+i+breadcrumbsSize)%breadcrumbsSize].String())
+	}
+	return res
+}
+
+// reportableTemplate returns the rendition of a message formatted from
+// format and args which is safe to report: the format string, or the
+// type of the first argument if there is no format string.
+func reportableTemplate(format string, args []interface{}) string {
+	if format == "" && len(args) > 0 {
+		return fmt.Sprintf("%T", args[0])
+	}
+	return format
+}
+
+// getBreadcrumbs returns the reportable renditions of the latest log
+// entries, in the order in which they were logged.
+func getBreadcrumbs() []string {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	return logging.breadcrumbs.strings()
+}
+
+// reportableGoroutines returns the stacks of all goroutines, truncated to
+// maxReportGoroutinesSize at the boundary between two goroutines. The
+// stacks only contain the functions and the addresses of their
+// arguments, which are safe to report.
+func reportableGoroutines() string {
+	stacks := getStacks(true)
+	if len(stacks) <= maxReportGoroutinesSize {
+		return string(stacks)
+	}
+	stacks = stacks[:maxReportGoroutinesSize]
+	if i := bytes.LastIndex(stacks, []byte("\n\n")); i >= 0 {
+		stacks = stacks[:i+1]
+	}
+	return string(stacks) + "...\n"
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestBreadcrumbsRing(t *testing.T) {
+	var b breadcrumbs
+	for i := 1; i <= breadcrumbsSize+10; i++ {
+		b.add(breadcrumb{severity: Severity_INFO, file: "f.go", line: i})
+	}
+	s := b.strings()
+	if len(s) != breadcrumbsSize {
+		t.Fatalf("expected %d breadcrumbs, got %d", breadcrumbsSize, len(s))
+	}
+	if !strings.Contains(s[0], "f.go:11 ") || !strings.Contains(s[len(s)-1], fmt.Sprintf("f.go:%d ", breadcrumbsSize+10)) {
+		t.Errorf("expected the latest breadcrumbs, got %s to %s", s[0], s[len(s)-1])
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	ctx := context.Background()
+	Infof(ctx, "user %s logged in", "hunter2")
+	Warning(ctx, 42, Unsafe{V: "secret"})
+
+	crumbs := getBreadcrumbs()
+	if len(crumbs) < 2 {
+		t.Fatalf("expected at least 2 breadcrumbs, got %q", crumbs)
+	}
+	info, warning := crumbs[len(crumbs)-2], crumbs[len(crumbs)-1]
+	if !strings.HasPrefix(info, "I") || !strings.HasSuffix(info, " user %s logged in") {
+		t.Errorf("expected the template of the entry, got %q", info)
+	}
+	if !strings.HasPrefix(warning, "W") || !strings.HasSuffix(warning, " int") {
+		t.Errorf("expected the type of the first argument, got %q", warning)
+	}
+	for _, c := range crumbs {
+		if strings.Contains(c, "hunter2") || strings.Contains(c, "secret") {
+			t.Errorf("breadcrumb %q leaks data", c)
+		}
+	}
+	if e := time.Now().UTC().Format("060102"); !strings.Contains(warning, e) {
+		t.Errorf("expected the date %s in %q", e, warning)
+	}
+}
+
+func TestReportableGoroutines(t *testing.T) {
+	stacks := reportableGoroutines()
+	if !strings.HasPrefix(stacks, "goroutine ") {
+		t.Errorf("expected a goroutine dump, got %q", stacks)
+	}
+	if len(stacks) > maxReportGoroutinesSize+len("...\n") {
+		t.Errorf("expected the dump to be truncated to %d bytes, got %d", maxReportGoroutinesSize, len(stacks))
+	}
+}
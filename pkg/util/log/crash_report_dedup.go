@@ -0,0 +1,213 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxIdenticalCrashReports bounds the number of crash reports with the
+// same stack sent per crashReportDedupWindow, so that a node stuck in a
+// crash loop does not exhaust the quota of the crash reporting server.
+var maxIdenticalCrashReports = settings.RegisterIntSetting(
+	"diagnostics.reporting.max_identical_crash_reports",
+	"maximum number of crash reports with the same stack sent per hour, across restarts of the node "+
+		"(0 for no limit)",
+	5,
+)
+
+// crashReportDedupWindow is the window over which identical crash
+// reports are counted.
+const crashReportDedupWindow = time.Hour
+
+// crashReportWindow counts the reports with a given stack sent since the
+// start of a window.
+type crashReportWindow struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// crashReportDedup tracks the reports sent per stack fingerprint. The
+// windows are stored in the log directory, so that the reports sent by
+// the previous processes of a crash loop are accounted for.
+var crashReportDedup struct {
+	syncutil.Mutex
+	loaded  bool
+	windows map[string]*crashReportWindow
+	// dirty is set when the windows changed since they were last stored.
+	dirty bool
+}
+
+// crashReportDedupSaves wakes up the goroutine storing the windows. The
+// reports admitted while it is storing them are coalesced into its next
+// write, so that a burst of reports does not result in a burst of
+// writes in the goroutines reporting the crashes.
+var crashReportDedupSaves = make(chan struct{}, 1)
+
+// crashReportDedupSaveMu serializes the writes of the windows by the
+// background goroutine and by FlushAll.
+var crashReportDedupSaveMu syncutil.Mutex
+
+func init() {
+	go func() {
+		for range crashReportDedupSaves {
+			_ = saveCrashReportDedup()
+		}
+	}()
+}
+
+// stackFingerprint derives the key under which crash reports with the
+// stack pcs are deduplicated from the functions and lines of its frames,
+// which are stable across restarts of the same binary.
+func stackFingerprint(pcs []uintptr) string {
+	var parts []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		parts = append(parts, fmt.Sprintf("%s:%d", f.Function, f.Line))
+		if !more {
+			break
+		}
+	}
+	return stringFingerprint(strings.Join(parts, "\n"))
+}
+
+// crashReportDedupPath returns the path of the file holding the windows
+// of the reports sent, in the log directory.
+func crashReportDedupPath() (string, error) {
+	dir, err := logDir.get()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, removePeriods(program)+".crash-reports.json"), nil
+}
+
+// admitCrashReport calls enqueue if a crash report with the stack pcs
+// may be sent at time now, and accounts for the report if enqueue
+// succeeds. It returns the error of enqueue, or an error describing why
+// the report is suppressed. The windows are stored asynchronously.
+func admitCrashReport(pcs []uintptr, now time.Time, enqueue func() error) error {
+	max := maxIdenticalCrashReports.Get()
+	if max <= 0 {
+		return enqueue()
+	}
+	fp := stackFingerprint(pcs)
+	d := &crashReportDedup
+	d.Lock()
+	defer d.Unlock()
+	if !d.loaded {
+		d.windows = loadCrashReportWindows()
+		d.loaded = true
+	}
+	w := d.windows[fp]
+	if w == nil || now.Sub(w.Start) >= crashReportDedupWindow {
+		w = &crashReportWindow{Start: now}
+	}
+	if w.Count >= max {
+		return fmt.Errorf("%d identical crash reports sent since %s",
+			w.Count, w.Start.UTC().Format(time.RFC3339))
+	}
+	// enqueue does not block, so it is called with the lock held to keep
+	// concurrent reports with the same stack from exceeding the limit.
+	if err := enqueue(); err != nil {
+		return err
+	}
+	w.Count++
+	d.windows[fp] = w
+	for k, w := range d.windows {
+		if now.Sub(w.Start) >= crashReportDedupWindow {
+			delete(d.windows, k)
+		}
+	}
+	d.dirty = true
+	select {
+	case crashReportDedupSaves <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// saveCrashReportDedup stores the windows in the log directory if they
+// changed since they were last stored.
+func saveCrashReportDedup() error {
+	crashReportDedupSaveMu.Lock()
+	defer crashReportDedupSaveMu.Unlock()
+	d := &crashReportDedup
+	d.Lock()
+	if !d.dirty {
+		d.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(d.windows)
+	d.dirty = false
+	d.Unlock()
+	if err != nil {
+		return err
+	}
+	return saveCrashReportWindows(data)
+}
+
+// loadCrashReportWindows reads the windows stored in the log directory.
+// A missing or malformed file is equivalent to no report having been
+// sent.
+func loadCrashReportWindows() map[string]*crashReportWindow {
+	windows := make(map[string]*crashReportWindow)
+	path, err := crashReportDedupPath()
+	if err != nil {
+		return windows
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			metaLogf("crash-report-dedup", "unable to read %s: %s", path, err)
+		}
+		return windows
+	}
+	if err := json.Unmarshal(data, &windows); err != nil {
+		metaLogf("crash-report-dedup", "malformed %s: %s", path, err)
+		return make(map[string]*crashReportWindow)
+	}
+	return windows
+}
+
+// saveCrashReportWindows stores the encoded windows in the log
+// directory, if there is one. It is best-effort, as reports are sent
+// while the process is crashing.
+func saveCrashReportWindows(data []byte) error {
+	path, err := crashReportDedupPath()
+	if err != nil {
+		return nil
+	}
+	// Write to a temporary file first so that a crash never leaves a
+	// partially written file behind.
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		metaLogf("crash-report-dedup", "unable to write %s: %s", path, err)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestAdmitCrashReport(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	defer settings.TestingSetInt(&maxIdenticalCrashReports, 2)()
+	reset := func() {
+		crashReportDedup.Lock()
+		crashReportDedup.loaded = false
+		crashReportDedup.windows = nil
+		crashReportDedup.dirty = false
+		crashReportDedup.Unlock()
+	}
+	reset()
+	defer reset()
+
+	enqueued := func() error { return nil }
+	dropped := func() error { return errCrashReportQueueFull }
+
+	pcs := capturePCs(0)
+	other := capturePCs(0)
+	now := time.Now()
+	// Reports which are not enqueued do not count towards the limit.
+	for i := 0; i < 3; i++ {
+		if err := admitCrashReport(pcs, now, dropped); err != errCrashReportQueueFull {
+			t.Fatalf("expected %v, got %v", errCrashReportQueueFull, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := admitCrashReport(pcs, now, enqueued); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := admitCrashReport(pcs, now, enqueued); err == nil {
+		t.Error("expected the third identical report to be suppressed")
+	}
+	if err := admitCrashReport(other, now, enqueued); err != nil {
+		t.Errorf("expected a report with another stack to be sent, got %v", err)
+	}
+
+	// The reports sent are accounted for across restarts, once the
+	// windows are stored.
+	if err := saveCrashReportDedup(); err != nil {
+		t.Fatal(err)
+	}
+	reset()
+	if err := admitCrashReport(pcs, now.Add(time.Minute), enqueued); err == nil {
+		t.Error("expected the reports of the previous process to be accounted for")
+	}
+	if err := admitCrashReport(pcs, now.Add(crashReportDedupWindow), enqueued); err != nil {
+		t.Errorf("expected reports to be sent again after the window, got %v", err)
+	}
+
+	defer settings.TestingSetInt(&maxIdenticalCrashReports, 0)()
+	for i := 0; i < 5; i++ {
+		if err := admitCrashReport(pcs, now.Add(crashReportDedupWindow), enqueued); err != nil {
+			t.Fatalf("expected no limit, got %v", err)
+		}
+	}
+}
+
+func TestStackFingerprint(t *testing.T) {
+	var fps []string
+	for i := 0; i < 2; i++ {
+		fps = append(fps, stackFingerprint(capturePCs(0)))
+	}
+	if fps[0] != fps[1] {
+		t.Errorf("expected identical fingerprints for the same stack, got %s and %s", fps[0], fps[1])
+	}
+	if other := stackFingerprint(capturePCs(0)); other == fps[0] {
+		t.Errorf("expected a different fingerprint for another stack, got %s", other)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	raven "github.com/getsentry/raven-go"
+	"golang.org/x/net/context"
+)
+
+// Crash reports are built and sent by a small pool of workers, so that
+// the goroutine reporting a crash only captures its stack before
+// proceeding. The workers are started in init so that they are not
+// mistaken for goroutines leaked by tests.
+const (
+	crashReportWorkers   = 2
+	crashReportQueueSize = 16
+	// maxReportFrames bounds the depth of the stacks of crash reports.
+	maxReportFrames = 100
+	// reportContextLines is the number of source lines included around
+	// each frame of the stacks of crash reports.
+	reportContextLines = 3
+)
+
+// pendingReport holds the data of a crash report captured by the
+// reporting goroutine, which is enriched into a packet by a worker.
+type pendingReport struct {
+	ctx context.Context
+	err error
+	pcs []uintptr
+	// level and fingerprint, if set, override those of the packet.
+	level       raven.Severity
+	fingerprint []string
+	// breadcrumbs and goroutines, if set, are the latest log entries and
+	// the stacks of all goroutines at the time of the crash.
+	breadcrumbs []string
+	goroutines  string
+	// captured is called by the worker once the packet was handed over to
+	// the crash reporter. ch receives the result of the upload.
+	captured func(eventID string, ch chan error)
+}
+
+var crashReportQueue = make(chan *pendingReport, crashReportQueueSize)
+
+func init() {
+	for i := 0; i < crashReportWorkers; i++ {
+		go func() {
+			setProfilerLabels(profilerLabelCrashReport)
+			for r := range crashReportQueue {
+				r.process()
+			}
+		}()
+	}
+}
+
+// errCrashReportQueueFull is returned when a report is dropped because
+// the workers do not keep up.
+var errCrashReportQueueFull = errors.New("too many pending crash reports")
+
+// enqueueReport hands r over to the workers. It returns an error if the
+// report is dropped, because too many identical reports were sent
+// recently or because the queue is full. Dropped reports do not count
+// towards the limit of identical reports.
+func enqueueReport(r *pendingReport) error {
+	return admitCrashReport(r.pcs, time.Now(), func() error {
+		select {
+		case crashReportQueue <- r:
+			return nil
+		default:
+			return errCrashReportQueueFull
+		}
+	})
+}
+
+func (r *pendingReport) process() {
+	extra := make(map[string]interface{})
+	if r.breadcrumbs != nil {
+		extra["breadcrumbs"] = r.breadcrumbs
+	}
+	if r.goroutines != "" {
+		extra["goroutines"] = r.goroutines
+	}
+	packet := makeReportPacket(r.ctx, r.err, makeStacktrace(r.pcs), extra)
+	if r.level != "" {
+		packet.Level = r.level
+	}
+	if r.fingerprint != nil {
+		packet.Fingerprint = r.fingerprint
+	}
+	recordCrashPacket(packet)
+	eventID, ch, ok := capture(packet)
+	if ok && r.captured != nil {
+		r.captured(eventID, ch)
+	}
+}
+
+// capturePCs returns the program counters of the stack of the calling
+// goroutine, starting depth+1 frames up the stack. Unlike building the
+// stack trace of a report, which reads the source files, it is cheap.
+func capturePCs(depth int) []uintptr {
+	pcs := make([]uintptr, maxReportFrames)
+	return pcs[:runtime.Callers(depth+2, pcs)]
+}
+
+// makeStacktrace builds the stack trace of a crash report from the
+// program counters captured by capturePCs.
+func makeStacktrace(pcs []uintptr) *raven.Stacktrace {
+	var frames []*raven.StacktraceFrame
+	callers := runtime.CallersFrames(pcs)
+	for {
+		f, more := callers.Next()
+		if f.PC != 0 {
+			if frame := raven.NewStacktraceFrame(
+				f.PC, f.File, f.Line, reportContextLines, crdbPaths,
+			); frame != nil {
+				frames = append(frames, frame)
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+	// Sentry expects the innermost frame last.
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &raven.Stacktrace{Frames: frames}
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// CrashReportsPausedUntil is the Unix time, in seconds, until which no
+// node of the cluster sends crash reports. It is set when the crash
+// reporting server asks a node to back off, so that the whole cluster
+// backs off rather than only the node which was told to.
+var CrashReportsPausedUntil = func() *settings.IntSetting {
+	s := settings.RegisterIntSetting(
+		"diagnostics.reporting.crash_reports_paused_until",
+		"unix time in seconds until which crash reports are not sent, set when the reporting server is over quota",
+		0,
+	)
+	s.Hide()
+	return s
+}()
+
+const (
+	// defaultCrashReportBackoff is the backoff used when the crash
+	// reporting server does not say for how long to back off.
+	defaultCrashReportBackoff = time.Minute
+	// maxCrashReportBackoff bounds the backoff requested by the crash
+	// reporting server.
+	maxCrashReportBackoff = 24 * time.Hour
+	// crashReportSendTimeout bounds the duration of the upload of a report.
+	crashReportSendTimeout = 10 * time.Second
+)
+
+// crashReportsPausedUntilNanos is the time, in nanoseconds since the
+// epoch, until which this node does not send crash reports. It takes
+// effect before the shared setting is propagated to the node.
+var crashReportsPausedUntilNanos int64
+
+var crashReportBackoffHook struct {
+	syncutil.Mutex
+	fn func(until time.Time)
+}
+
+// SetCrashReportBackoffHook installs a function called when the crash
+// reporting server asks for a backoff, which is expected to set
+// CrashReportsPausedUntil to propagate the backoff to the cluster. The
+// function is called on the goroutine uploading reports, and should not
+// block.
+func SetCrashReportBackoffHook(fn func(until time.Time)) {
+	crashReportBackoffHook.Lock()
+	defer crashReportBackoffHook.Unlock()
+	crashReportBackoffHook.fn = fn
+}
+
+// crashReportsPaused returns true if no crash report is to be sent at
+// time now.
+func crashReportsPaused(now time.Time) bool {
+	if now.UnixNano() < atomic.LoadInt64(&crashReportsPausedUntilNanos) {
+		return true
+	}
+	return now.Unix() < CrashReportsPausedUntil.Get()
+}
+
+// pauseCrashReports stops sending crash reports until the given time,
+// on this node at once and on the other nodes through the backoff hook.
+func pauseCrashReports(until time.Time) {
+	for {
+		cur := atomic.LoadInt64(&crashReportsPausedUntilNanos)
+		if until.UnixNano() <= cur {
+			// A longer backoff is already in effect.
+			return
+		}
+		if atomic.CompareAndSwapInt64(&crashReportsPausedUntilNanos, cur, until.UnixNano()) {
+			break
+		}
+	}
+	crashReportBackoffHook.Lock()
+	fn := crashReportBackoffHook.fn
+	crashReportBackoffHook.Unlock()
+	if fn != nil {
+		fn(until)
+	}
+}
+
+// parseRetryAfter returns the backoff requested by the value of a
+// Retry-After header received at time now, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	d := defaultCrashReportBackoff
+	if secs, err := strconv.Atoi(header); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(header); err == nil {
+		d = t.Sub(now)
+	}
+	if d <= 0 {
+		return defaultCrashReportBackoff
+	}
+	if d > maxCrashReportBackoff {
+		return maxCrashReportBackoff
+	}
+	return d
+}
@@ -0,0 +1,43 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", defaultCrashReportBackoff},
+		{"garbage", defaultCrashReportBackoff},
+		{"120", 2 * time.Minute},
+		{"0", defaultCrashReportBackoff},
+		{"-5", defaultCrashReportBackoff},
+		{"10000000", maxCrashReportBackoff},
+		{now.Add(time.Hour).Format(http.TimeFormat), time.Hour},
+		{now.Add(-time.Hour).Format(http.TimeFormat), defaultCrashReportBackoff},
+	}
+	for _, tc := range testCases {
+		if d := parseRetryAfter(tc.header, now); d != tc.expected {
+			t.Errorf("%q: expected %s, got %s", tc.header, tc.expected, d)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Crash reports are written to a spool directory in the log directory
+// before they are uploaded, and removed once the crash reporting server
+// accepted them. The reports which could not be uploaded, for instance
+// because the network was down when the process crashed, are uploaded
+// by UploadSpooledCrashReports on the next start.
+const (
+	// crashReportSpoolMaxAge is the age past which spooled reports are
+	// discarded instead of uploaded.
+	crashReportSpoolMaxAge = 7 * 24 * time.Hour
+	// crashReportSpoolMaxSize bounds the total size of the spooled
+	// reports. The oldest reports are discarded to make room for new ones.
+	crashReportSpoolMaxSize = 10 << 20
+	// spooledReportSuffix is the extension of the files of the spool.
+	spooledReportSuffix = ".json"
+)
+
+// crashReportSpool serializes the changes to the spool directory.
+var crashReportSpool syncutil.Mutex
+
+// crashReportSpoolDir returns the path of the spool directory, in the
+// log directory.
+func crashReportSpoolDir() (string, error) {
+	dir, err := logDir.get()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, removePeriods(program)+".crash-spool"), nil
+}
+
+// spooledReports returns the reports in the spool directory, oldest
+// first, after removing those older than crashReportSpoolMaxAge at time
+// now. The caller must hold crashReportSpool.
+func spooledReports(dir string, now time.Time) ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	reports := infos[:0]
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), spooledReportSuffix) {
+			continue
+		}
+		if now.Sub(info.ModTime()) > crashReportSpoolMaxAge {
+			removeSpooledReport(filepath.Join(dir, info.Name()))
+			continue
+		}
+		reports = append(reports, info)
+	}
+	// The names of the files start with the time at which the reports were
+	// spooled.
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name() < reports[j].Name() })
+	return reports, nil
+}
+
+// spoolCrashReport writes body, the JSON encoding of the packet of the
+// event eventID, to the spool directory, and returns the path of the
+// file to remove once the report is uploaded. The oldest reports are
+// discarded if the spool would otherwise exceed crashReportSpoolMaxSize.
+// Spooling the same event again, as done when spooled reports are
+// uploaded, replaces the file. Without a log directory, reports are not
+// spooled and the returned path is empty.
+func spoolCrashReport(eventID string, body []byte, now time.Time) (string, error) {
+	if len(body) > crashReportSpoolMaxSize {
+		return "", fmt.Errorf("crash report of %d bytes exceeds the size of the spool", len(body))
+	}
+	dir, err := crashReportSpoolDir()
+	if err != nil {
+		return "", nil
+	}
+	crashReportSpool.Lock()
+	defer crashReportSpool.Unlock()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	reports, err := spooledReports(dir, now)
+	if err != nil {
+		return "", err
+	}
+	path := ""
+	total := int64(len(body))
+	for _, info := range reports {
+		if strings.HasSuffix(info.Name(), "-"+eventID+spooledReportSuffix) {
+			path = filepath.Join(dir, info.Name())
+			continue
+		}
+		total += info.Size()
+	}
+	for _, info := range reports {
+		if total <= crashReportSpoolMaxSize {
+			break
+		}
+		if p := filepath.Join(dir, info.Name()); p != path {
+			removeSpooledReport(p)
+			total -= info.Size()
+		}
+	}
+	if path == "" {
+		path = filepath.Join(dir, fmt.Sprintf("%019d-%s%s", now.UnixNano(), eventID, spooledReportSuffix))
+	}
+	// Write to a temporary file first so that a crash never leaves a
+	// partially written report behind.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, body, 0644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// removeSpooledReport removes a report from the spool directory.
+func removeSpooledReport(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		metaLogf("crash-report-spool", "unable to remove %s: %s", path, err)
+	}
+}
+
+// uploadSpooledReport uploads body, the JSON encoding of a spooled packet,
+// to the crash reporting server. The boolean return value is true if the
+// report is to be kept for a later attempt. It is a variable so that
+// tests can intercept the uploads.
+var uploadSpooledReport = uploadCrashReport
+
+// UploadSpooledCrashReports uploads the crash reports spooled by previous
+// runs of the process which could not be uploaded when they were
+// captured, and returns the number of reports left in the spool. Reports
+// are not uploaded while reporting is disabled, and are discarded once
+// they are older than a week.
+func UploadSpooledCrashReports(ctx context.Context) (int, error) {
+	dir, err := crashReportSpoolDir()
+	if err != nil {
+		// Without a log directory, nothing was spooled.
+		return 0, nil
+	}
+	crashReportSpool.Lock()
+	reports, err := spooledReports(dir, time.Now())
+	crashReportSpool.Unlock()
+	if err != nil || !reportingEnabled() {
+		return len(reports), err
+	}
+	remaining := 0
+	for i, info := range reports {
+		if err := ctx.Err(); err != nil {
+			return remaining + len(reports) - i, err
+		}
+		path := filepath.Join(dir, info.Name())
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				Warningf(ctx, "unable to read spooled crash report: %s", err)
+				remaining++
+			}
+			continue
+		}
+		keep, err := uploadSpooledReport(body)
+		if err != nil {
+			Warningf(ctx, "unable to upload spooled crash report %s: %s", info.Name(), err)
+		}
+		if keep {
+			remaining++
+			continue
+		}
+		crashReportSpool.Lock()
+		removeSpooledReport(path)
+		crashReportSpool.Unlock()
+	}
+	return remaining, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestSpoolCrashReport(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	dir, err := crashReportSpoolDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	body := bytes.Repeat([]byte("x"), crashReportSpoolMaxSize/3)
+	var paths []string
+	for i, id := range []string{"a", "b", "c"} {
+		path, err := spoolCrashReport(id, body, now.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	// Spooling the same event again replaces its file.
+	if path, err := spoolCrashReport("b", body, now.Add(time.Hour)); err != nil || path != paths[1] {
+		t.Errorf("expected %s to be replaced, got %s, %v", paths[1], path, err)
+	}
+	if _, err := os.Stat(paths[0]); err != nil {
+		t.Errorf("expected the reports within the size of the spool to be kept, got %v", err)
+	}
+
+	// The oldest reports make room for new ones.
+	if _, err := spoolCrashReport("d", body, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest report to be discarded, got %v", err)
+	}
+	if _, err := spoolCrashReport("e", make([]byte, crashReportSpoolMaxSize+1), now); err == nil {
+		t.Error("expected a report larger than the spool to be refused")
+	}
+
+	crashReportSpool.Lock()
+	reports, err := spooledReports(dir, now.Add(time.Hour))
+	crashReportSpool.Unlock()
+	if err != nil || len(reports) != 3 {
+		t.Fatalf("expected 3 spooled reports, got %d, %v", len(reports), err)
+	}
+	crashReportSpool.Lock()
+	reports, err = spooledReports(dir, time.Now().Add(crashReportSpoolMaxAge+time.Hour))
+	crashReportSpool.Unlock()
+	if err != nil || len(reports) != 0 {
+		t.Errorf("expected the expired reports to be discarded, got %d, %v", len(reports), err)
+	}
+}
+
+func TestUploadSpooledCrashReports(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	ctx := context.Background()
+
+	var uploaded []string
+	defer func(f func([]byte) (bool, error)) { uploadSpooledReport = f }(uploadSpooledReport)
+	uploadSpooledReport = func(body []byte) (bool, error) {
+		uploaded = append(uploaded, string(body))
+		// The server is down for the second report.
+		return string(body) == "second", nil
+	}
+
+	now := time.Now()
+	for i, body := range []string{"first", "second"} {
+		if _, err := spoolCrashReport(body, []byte(body), now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reports are not uploaded while reporting is disabled.
+	func() {
+		defer settings.TestingSetBool(&DiagnosticsReportingEnabled, false)()
+		if n, err := UploadSpooledCrashReports(ctx); err != nil || n != 2 {
+			t.Errorf("expected 2 reports to be left, got %d, %v", n, err)
+		}
+		if len(uploaded) != 0 {
+			t.Errorf("unexpected uploads %q", uploaded)
+		}
+	}()
+
+	defer settings.TestingSetBool(&DiagnosticsReportingEnabled, true)()
+	if n, err := UploadSpooledCrashReports(ctx); err != nil || n != 1 {
+		t.Errorf("expected 1 report to be left, got %d, %v", n, err)
+	}
+	if len(uploaded) != 2 || uploaded[0] != "first" {
+		t.Errorf("expected the reports to be uploaded oldest first, got %q", uploaded)
+	}
+	uploaded = nil
+	if n, err := UploadSpooledCrashReports(ctx); err != nil || n != 1 {
+		t.Errorf("expected 1 report to be left, got %d, %v", n, err)
+	}
+	if len(uploaded) != 1 || uploaded[0] != "second" {
+		t.Errorf("expected the uploaded report to be removed, got %q", uploaded)
+	}
+
+	dir, err := crashReportSpoolDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*-second"+spooledReportSuffix)); len(matches) != 1 {
+		t.Errorf("expected the report which failed to upload to be kept, got %q", matches)
+	}
+}
@@ -15,10 +15,17 @@
 package log
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	raven "github.com/getsentry/raven-go"
 	"github.com/pkg/errors"
@@ -28,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
 // DiagnosticsReportingEnabled wraps "diagnostics.reporting.enabled".
@@ -67,6 +75,40 @@ type Safe struct {
 	V interface{}
 }
 
+// Unsafe marks a value as containing user data, such as an SQL statement
+// or the contents of a key. It is formatted as its value in log messages,
+// but is never reported verbatim, not even if the value is numeric:
+// redacted log outputs replace it by "<redacted>", the log files delimit
+// it so that it can be stripped from the support bundles, and crash
+// reports anonymize it according to the ReportAnonymization setting.
+type Unsafe struct {
+	V interface{}
+}
+
+// Format implements fmt.Formatter, formatting u as its value with the
+// same verb and flags.
+func (u Unsafe) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, formatDirective(s, verb), u.V)
+}
+
+// formatDirective returns the formatting directive, such as "%-5.1f",
+// with which s was formatted with the given verb.
+func formatDirective(s fmt.State, verb rune) string {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := s.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+	if prec, ok := s.Precision(); ok {
+		directive += "." + strconv.Itoa(prec)
+	}
+	return directive + string(verb)
+}
+
 func format(r interface{}) string {
 	switch wrapped := r.(type) {
 	case *Safe:
@@ -77,9 +119,50 @@ func format(r interface{}) string {
 	return fmt.Sprintf("%T", r)
 }
 
+// causer is implemented by errors wrapping another error, as done by
+// github.com/pkg/errors.
+type causer interface {
+	Cause() error
+}
+
+// formatPanicValue returns a description of a panic payload which is
+// safe to report. Safe values are reported verbatim, and Unsafe ones as
+// the values they wrap. Errors are reported
+// as the chain of the types of the wrapped errors, outermost first.
+// Strings are reported as a fingerprint, which allows grouping reports of
+// the same panic without revealing its message. For anything else, only
+// the Go type is reported.
+func formatPanicValue(r interface{}) string {
+	switch v := r.(type) {
+	case Safe, *Safe:
+		return format(v)
+	case Unsafe:
+		return formatPanicValue(v.V)
+	case *Unsafe:
+		return formatPanicValue(v.V)
+	case error:
+		var types []string
+		for err := v; err != nil; {
+			types = append(types, fmt.Sprintf("%T", err))
+			c, ok := err.(causer)
+			if !ok {
+				break
+			}
+			err = c.Cause()
+		}
+		return strings.Join(types, ": ")
+	case string:
+		return "string#" + stringFingerprint(v)
+	}
+	return fmt.Sprintf("%T", r)
+}
+
 // ReportPanic reports a panic has occurred on the real stderr.
 func ReportPanic(ctx context.Context, r interface{}, depth int) {
 	Shout(ctx, Severity_ERROR, "a panic has occurred!")
+	for _, hint := range panicHints(ctx, false /* redact */) {
+		Shout(ctx, Severity_ERROR, "while "+hint)
+	}
 
 	// TODO(dt,knz,sql-team): we need to audit all sprintf'ing of values into the
 	// errors and strings passed to panic, to ensure raw user data is kept
@@ -90,14 +173,8 @@ func ReportPanic(ctx context.Context, r interface{}, depth int) {
 	// line here help uniquely identify the error.
 	// Some exceptions, like a runtime.Error, are assumed to be fine as-is.
 
-	var reportable interface{}
-	switch r.(type) {
-	case runtime.Error:
-		reportable = r
-	default:
-		file, line, _ := caller.Lookup(depth + 3)
-		reportable = fmt.Sprintf("%s %s:%d", format(r), filepath.Base(file), line)
-	}
+	reportable := reportablePanic(r, depth+3)
+	recordExitReason(ExitClassPanic, fmt.Sprint(reportable))
 	sendCrashReport(ctx, reportable, depth+3)
 
 	// Ensure that the logs are flushed before letting a panic
@@ -116,6 +193,40 @@ func ReportPanic(ctx context.Context, r interface{}, depth int) {
 	}
 }
 
+// reportablePanic returns the description of the panic value r which is
+// safe to report, and which mentions the location of the panic, depth+1
+// frames up the stack.
+func reportablePanic(r interface{}, depth int) interface{} {
+	if _, ok := r.(runtime.Error); ok {
+		return r
+	}
+	file, line, _ := caller.Lookup(depth + 1)
+	return fmt.Sprintf("%s %s:%d", formatPanicValue(r), filepath.Base(file), line)
+}
+
+// ReportRecoveredPanic logs and reports a panic from which the caller,
+// typically the handler of a request, recovered. Unlike ReportPanic, it
+// does not assume that the process terminates, and does not wait for the
+// report to be sent.
+func ReportRecoveredPanic(ctx context.Context, r interface{}, depth int) {
+	Errorf(ctx, "recovered from panic: %v\n%s", r, debug.Stack())
+	if !reportingEnabled() {
+		return
+	}
+	if err := enqueueReport(&pendingReport{
+		ctx:         ctx,
+		err:         fmt.Errorf("%v", reportablePanic(r, depth+3)),
+		pcs:         capturePCs(depth + 1),
+		breadcrumbs: getBreadcrumbs(),
+		goroutines:  reportableGoroutines(),
+		captured: func(eventID string, _ chan error) {
+			Warningf(ctx, "reported recovered panic as error %s", eventID)
+		},
+	}); err != nil {
+		Warningf(ctx, "%s; dropped recovered panic report", err)
+	}
+}
+
 var crashReports = settings.RegisterBoolSetting(
 	"diagnostics.reporting.send_crash_reports",
 	"send crash and panic reports",
@@ -135,6 +246,9 @@ func SetupCrashReporter(ctx context.Context, cmd string) {
 	if err := raven.SetDSN(crashReportURL); err != nil {
 		panic(errors.Wrap(err, "failed to setup crash reporting"))
 	}
+	if raven.DefaultClient != nil {
+		raven.DefaultClient.Transport = newCrashReportTransport()
+	}
 
 	if cmd == "start" {
 		cmd = "server"
@@ -151,14 +265,40 @@ func SetupCrashReporter(ctx context.Context, cmd string) {
 	})
 }
 
+// crashReporterCheckTimeout bounds the duration of CheckCrashReporter.
+var crashReporterCheckTimeout = 10 * time.Second
+
+// CheckCrashReporter verifies that the crash reporting server can be
+// reached if reporting is enabled, and logs a warning if it cannot, so
+// that operators learn about broken crash reporting before they need it.
+func CheckCrashReporter(ctx context.Context) {
+	if !phoneHome || !reportingEnabled() {
+		return
+	}
+	url := raven.DefaultClient.URL()
+	if err := checkReachable(ctx, url, crashReporterCheckTimeout); err != nil {
+		Warningf(ctx, "crash reports cannot be sent to %s: %s", url, err)
+	}
+}
+
 var crdbPaths = []string{"github.com/cockroachdb/cockroach"}
 
-func sendCrashReport(ctx context.Context, r interface{}, depth int) {
+// reportingEnabled returns true if reports are to be sent to the crash
+// reporting server.
+func reportingEnabled() bool {
 	if !DiagnosticsReportingEnabled.Get() || !crashReports.Get() {
-		return // disabled via settings.
+		return false // disabled via settings.
 	}
-	if raven.DefaultClient == nil {
-		return // disabled via empty URL env var.
+	if crashReportsPaused(time.Now()) {
+		return false // the reporting server asked for a backoff.
+	}
+	// An empty URL env var disables reporting.
+	return raven.DefaultClient != nil
+}
+
+func sendCrashReport(ctx context.Context, r interface{}, depth int) {
+	if !reportingEnabled() {
+		return
 	}
 
 	var err error
@@ -168,17 +308,246 @@ func sendCrashReport(ctx context.Context, r interface{}, depth int) {
 		err = fmt.Errorf("%v", r)
 	}
 
+	type result struct {
+		eventID string
+		ch      chan error
+	}
+	captured := make(chan result, 1)
+	if err := enqueueReport(&pendingReport{
+		ctx:         ctx,
+		err:         err,
+		pcs:         capturePCs(depth + 1),
+		breadcrumbs: getBreadcrumbs(),
+		goroutines:  reportableGoroutines(),
+		captured: func(eventID string, ch chan error) {
+			captured <- result{eventID, ch}
+		},
+	}); err != nil {
+		Shout(ctx, Severity_ERROR, err.Error()+"; dropped report")
+		return
+	}
+	// A crash report is always followed by process termination, so do
+	// not wait for the report to be built and uploaded for longer than its
+	// share of the fatal drain window, the rest being left to the flush of
+	// the logs.
+	start := time.Now()
+	timeout := crashReportDrainTimeout()
+	var res result
+	select {
+	case res = <-captured:
+	case <-time.After(timeout):
+		Shout(ctx, Severity_ERROR, "timed out building crash report")
+		return
+	}
+	if !waitWithin(res.ch, timeout-time.Since(start)) {
+		Shout(ctx, Severity_ERROR, "timed out reporting error "+res.eventID)
+		return
+	}
+	Shout(ctx, Severity_ERROR, "Reported as error "+res.eventID)
+}
+
+// ReportError reports a "should never happen" condition from which the
+// caller was able to recover. The report has the level error and the
+// same tags and extras as crash reports. As for panics, the message of
+// err is not reported: only the types of the chain of errors it wraps
+// and the location of the call are.
+//
+// Reports are grouped by call site and build version rather than by
+// message, so that a fleet running the same binary produces a single
+// issue per bug. ReportError neither waits for the report to be built
+// nor for it to be sent.
+func ReportError(ctx context.Context, err error) {
+	if !reportingEnabled() {
+		return
+	}
+	file, line, _ := caller.Lookup(1)
+	if err := enqueueReport(&pendingReport{
+		ctx:         ctx,
+		err:         fmt.Errorf("%s %s:%d", formatPanicValue(err), filepath.Base(file), line),
+		pcs:         capturePCs(1),
+		level:       raven.ERROR,
+		fingerprint: callSiteFingerprint(1),
+		captured: func(eventID string, _ chan error) {
+			Warningf(ctx, "reported assertion failure as error %s", eventID)
+		},
+	}); err != nil {
+		Warningf(ctx, "%s; dropped assertion failure report", err)
+	}
+}
+
+// CrashReportingEnabled returns true if crash reports are sent.
+func CrashReportingEnabled() bool {
+	return reportingEnabled()
+}
+
+// PreviewCrashReport returns the JSON encoding of the report that a panic
+// at the call site would send, with the same tags and extras, so that
+// operators can audit what leaves the process before enabling reporting.
+// The report is neither sent, nor recorded for support bundles.
+func PreviewCrashReport(ctx context.Context) ([]byte, error) {
+	err := fmt.Errorf("%s", reportablePanic(Safe{V: "crash report preview"}, 0))
+	packet := makeReportPacket(ctx, err, makeStacktrace(capturePCs(1)), map[string]interface{}{
+		"breadcrumbs": getBreadcrumbs(),
+		"goroutines":  reportableGoroutines(),
+	})
+	if raven.DefaultClient != nil {
+		packet.AddTags(raven.DefaultClient.Tags)
+	}
+	return packet.JSON()
+}
+
+// ciEnvVars are environment variables set by continuous integration
+// systems.
+var ciEnvVars = []string{"CI", "CONTINUOUS_INTEGRATION", "BUILD_NUMBER", "TEAMCITY_VERSION"}
+
+// isTestOrCI returns true if the process is a test binary or runs in a
+// continuous integration environment.
+func isTestOrCI() bool {
+	if flag.Lookup("test.v") != nil || strings.HasSuffix(os.Args[0], ".test") {
+		return true
+	}
+	for _, name := range ciEnvVars {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// crashReportInterceptor receives the packets in place of the crash
+// reporter while it is set (see TestingInterceptCrashReports).
+var crashReportInterceptor struct {
+	syncutil.Mutex
+	fn func(*raven.Packet)
+}
+
+// capture hands packet over to the crash reporter. Test binaries,
+// processes running in CI and the builds tagged nophonehome never send
+// reports to an actual server, even when the reporting settings say
+// otherwise, but they still hand packets over to crashReportInterceptor
+// and to any other transport. The boolean return value is false if the
+// packet was dropped.
+func capture(packet *raven.Packet) (eventID string, ch chan error, ok bool) {
+	crashReportInterceptor.Lock()
+	intercept := crashReportInterceptor.fn
+	crashReportInterceptor.Unlock()
+	if intercept != nil {
+		// Tag the packet like the client does before handing it to the
+		// transport.
+		packet.AddTags(raven.DefaultClient.Tags)
+		intercept(packet)
+		ch = make(chan error, 1)
+		ch <- nil
+		return packet.EventID, ch, true
+	}
+	if isEgressTransport(raven.DefaultClient.Transport) && (!phoneHome || isTestOrCI()) {
+		return "", nil, false
+	}
+	eventID, ch = raven.DefaultClient.Capture(packet, nil /* tags */)
+	return eventID, ch, true
+}
+
+// callSiteFingerprint returns the grouping key of reports generated at
+// the call site depth+1 frames up the stack: the function, file and line,
+// as well as the build version since line numbers shift across versions.
+func callSiteFingerprint(depth int) []string {
+	file, line, fun := caller.Lookup(depth + 1)
+	return []string{fun, fmt.Sprintf("%s:%d", filepath.Base(file), line), build.GetInfo().Tag}
+}
+
+// processLifetimeTags returns the report tags describing for how long
+// the process has been running at time now, and how many times it was
+// restarted after exiting abnormally.
+func processLifetimeTags(now time.Time) map[string]string {
+	uptime := now.Sub(processStart)
+	return map[string]string{
+		"uptime":     (uptime / time.Second * time.Second).String(),
+		"start_time": processStart.UTC().Format(time.RFC3339),
+		"restarts":   strconv.Itoa(int(atomic.LoadInt32(&restartCount))),
+	}
+}
+
+// makeReportPacket builds the packet reporting err, with the given stack
+// trace and extras.
+func makeReportPacket(
+	ctx context.Context, err error, stack *raven.Stacktrace, extra map[string]interface{},
+) *raven.Packet {
 	// This is close to inlining raven.CaptureErrorAndWait(), except it lets us
 	// control the stack depth of the collected trace.
-	const contextLines = 3
-
-	ex := raven.NewException(err, raven.NewStacktrace(depth+1, contextLines, crdbPaths))
+	ex := raven.NewException(err, stack)
 	packet := raven.NewPacket(err.Error(), ex)
 	// Avoid leaking the machine's hostname by injecting the literal "<redacted>".
 	// Otherwise, raven.Client.Capture will see an empty ServerName field and
 	// automatically fill in the machine's hostname.
 	packet.ServerName = "<redacted>"
-	eventID, ch := raven.DefaultClient.Capture(packet, nil /* tags */)
-	<-ch
-	Shout(ctx, Severity_ERROR, "Reported as error "+eventID)
+	if packet.Extra == nil {
+		packet.Extra = make(map[string]interface{})
+	}
+	// Misconfigured environment variables are a common root cause of
+	// crashes; include those that are relevant and safe to report.
+	packet.Extra["environment"] = envutil.GetReportableEnv()
+	// Likewise, include the logging configuration, so that it is possible
+	// to tell whether missing context is due to the configuration.
+	packet.Extra["log_config"] = GetEffectiveConfig()
+	if tags := contextReportableTags(ctx); tags != nil {
+		packet.Extra["log_tags"] = tags
+	}
+	if hints := panicHints(ctx, true /* redact */); hints != nil {
+		packet.Extra["panic_context"] = hints
+	}
+	for k, v := range extra {
+		packet.Extra[k] = v
+	}
+	// Distinguish crashes right after startup, possibly in a crash loop,
+	// from crashes of long-running processes.
+	packet.AddTags(processLifetimeTags(time.Now()))
+	if tags := reportTags(ctx); tags != nil {
+		packet.AddTags(tags)
+	}
+	if activity := activityTag(); activity != "" {
+		packet.AddTags(map[string]string{"activity": activity})
+	}
+	if trimmed := trimReportPacket(packet, maxReportSize); len(trimmed) > 0 {
+		Warningf(ctx, "crash report exceeds %d bytes; dropped: %s",
+			maxReportSize, strings.Join(trimmed, ", "))
+	}
+	return packet
+}
+
+// maxReportSize is the maximum size of the JSON encoding of a crash
+// report. Larger reports are rejected by the crash reporting server.
+var maxReportSize = 100 << 10
+
+// reportTrimOrder lists the extras of a crash report in the order in
+// which they are dropped when the report is too large, from the least to
+// the most useful for the diagnosis of a crash. Extras not listed here
+// are dropped last, in lexical order.
+var reportTrimOrder = []string{"breadcrumbs", "goroutines", "log_tags", "log_config", "environment"}
+
+// trimReportPacket drops extras from packet until its encoding fits in
+// maxSize bytes and returns the names of the extras it dropped. The names
+// are recorded in the "trimmed" extra so that the report shows which
+// context is missing.
+func trimReportPacket(packet *raven.Packet, maxSize int) []string {
+	order := append([]string(nil), reportTrimOrder...)
+	var others []string
+	for k := range packet.Extra {
+		others = append(others, k)
+	}
+	sort.Strings(others)
+	order = append(order, others...)
+
+	var trimmed []string
+	for _, k := range order {
+		if data, err := packet.JSON(); err == nil && len(data) <= maxSize {
+			break
+		}
+		if _, ok := packet.Extra[k]; !ok {
+			continue
+		}
+		delete(packet.Extra, k)
+		trimmed = append(trimmed, k)
+		packet.Extra["trimmed"] = trimmed
+	}
+	return trimmed
 }
@@ -14,7 +14,20 @@
 
 package log
 
-import "testing"
+import (
+	"encoding/json"
+	goErrors "errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	raven "github.com/getsentry/raven-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
 
 func TestCrashReportingFormatSave(t *testing.T) {
 	r1 := "i am hidden"
@@ -39,3 +52,201 @@ func TestingSetCrashReportingURL(url string) func() {
 	crashReportURL = url
 	return func() { crashReportURL = oldCrashReportURL }
 }
+
+// TestingInterceptCrashReports hands the crash reports to fn rather than to
+// the crash reporter, until the returned function is called.
+func TestingInterceptCrashReports(fn func(*raven.Packet)) func() {
+	crashReportInterceptor.Lock()
+	defer crashReportInterceptor.Unlock()
+	oldFn := crashReportInterceptor.fn
+	crashReportInterceptor.fn = fn
+	return func() {
+		crashReportInterceptor.Lock()
+		defer crashReportInterceptor.Unlock()
+		crashReportInterceptor.fn = oldFn
+	}
+}
+
+func TestCallSiteFingerprint(t *testing.T) {
+	fingerprint := func() []string { return callSiteFingerprint(1) }
+	var fps [][]string
+	for i := 0; i < 2; i++ {
+		fps = append(fps, fingerprint())
+	}
+	other := fingerprint()
+
+	if !reflect.DeepEqual(fps[0], fps[1]) {
+		t.Errorf("expected identical fingerprints for the same call site, got %v and %v", fps[0], fps[1])
+	}
+	if reflect.DeepEqual(fps[0], other) {
+		t.Errorf("expected different fingerprints for different call sites, got %v", other)
+	}
+	if e := "TestCallSiteFingerprint"; !strings.Contains(other[0], e) {
+		t.Errorf("expected fingerprint %v to mention %s", other, e)
+	}
+}
+
+func TestUnsafe(t *testing.T) {
+	ctx := WithLogTag(context.Background(), "key", Unsafe{V: "/Table/51/1/\"secret\""})
+	format := "stmt %s, %03d rows, %5.1f%%, %s"
+	args := []interface{}{Unsafe{V: "SELECT 'secret'"}, &Unsafe{V: 7}, Unsafe{V: 42.0}, Safe{V: "public"}}
+
+	if msg, expected := makeMessage(ctx, format, args, spanIDs{}),
+		`[key=/Table/51/1/"secret"] stmt SELECT 'secret', 007 rows,  42.0%, {public}`; msg != expected {
+		t.Errorf("expected %q, got %q", expected, msg)
+	}
+	if msg, expected := makeRedactedMessage(ctx, format, args, spanIDs{}),
+		"[key=<redacted>] stmt <redacted>, <redacted> rows, <redacted>%, public"; msg != expected {
+		t.Errorf("expected %q, got %q", expected, msg)
+	}
+	func() {
+		defer settings.TestingSetString(&ReportAnonymization, anonymizeHash)()
+		msg := makeAnonymizedMessage(ctx, format, args, spanIDs{})
+		if expected := "stmt hash#" + stringFingerprint("SELECT 'secret'"); !strings.Contains(msg, expected) {
+			t.Errorf("expected %q in %q", expected, msg)
+		}
+		if strings.Contains(msg, "secret'") || strings.Contains(msg, "007") {
+			t.Errorf("unsafe data was not anonymized in %q", msg)
+		}
+	}()
+
+	if actual, expected := formatPanicValue(Unsafe{V: "secret"}),
+		"string#"+stringFingerprint("secret"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+type unknownPanicValue struct {
+	secret string
+}
+
+func TestFormatPanicValue(t *testing.T) {
+	base := goErrors.New("secret")
+	wrapped := errors.Wrap(base, "more secret")
+
+	testCases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{Safe{V: "i am public"}, "i am public"},
+		{base, "*errors.errorString"},
+		{wrapped, "*errors.withStack: *errors.withMessage: *errors.errorString"},
+		{"i am hidden", "string#" + stringFingerprint("i am hidden")},
+		{unknownPanicValue{secret: "i am hidden"}, "log.unknownPanicValue"},
+		{42, "int"},
+	}
+	for _, tc := range testCases {
+		if actual := formatPanicValue(tc.value); actual != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.value, tc.expected, actual)
+		}
+	}
+	if formatPanicValue("a") == formatPanicValue("b") {
+		t.Error("expected different strings to have different fingerprints")
+	}
+}
+
+func TestProcessLifetimeTags(t *testing.T) {
+	defer SetRestartCount(0)
+	SetRestartCount(3)
+
+	tags := processLifetimeTags(processStart.Add(90*time.Second + time.Millisecond))
+	expected := map[string]string{
+		"uptime":     "1m30s",
+		"start_time": processStart.UTC().Format(time.RFC3339),
+		"restarts":   "3",
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+}
+
+func TestIsTestOrCI(t *testing.T) {
+	if !isTestOrCI() {
+		t.Error("expected a test binary to be detected")
+	}
+}
+
+func TestTrimReportPacket(t *testing.T) {
+	makePacket := func() *raven.Packet {
+		packet := raven.NewPacket("boom")
+		packet.Extra = map[string]interface{}{
+			"environment": strings.Repeat("e", 1000),
+			"goroutines":  strings.Repeat("g", 1000),
+			"log_config":  strings.Repeat("c", 1000),
+			"zzz":         strings.Repeat("z", 1000),
+		}
+		return packet
+	}
+	size := func(packet *raven.Packet) int {
+		data, err := packet.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(data)
+	}
+	full := size(makePacket())
+
+	testCases := []struct {
+		maxSize  int
+		expected []string
+	}{
+		{full, nil},
+		{full - 500, []string{"goroutines"}},
+		{full - 1500, []string{"goroutines", "log_config"}},
+		{full - 2500, []string{"goroutines", "log_config", "environment"}},
+		{0, []string{"goroutines", "log_config", "environment", "zzz"}},
+	}
+	for _, tc := range testCases {
+		packet := makePacket()
+		trimmed := trimReportPacket(packet, tc.maxSize)
+		if !reflect.DeepEqual(trimmed, tc.expected) {
+			t.Errorf("%d: expected %v to be trimmed, got %v", tc.maxSize, tc.expected, trimmed)
+		}
+		if tc.expected != nil && !reflect.DeepEqual(packet.Extra["trimmed"], tc.expected) {
+			t.Errorf("%d: expected trimmed extras to be recorded, got %v", tc.maxSize, packet.Extra["trimmed"])
+		}
+		if len(tc.expected) < 4 && size(packet) > tc.maxSize {
+			t.Errorf("%d: packet still too large: %d bytes", tc.maxSize, size(packet))
+		}
+	}
+}
+
+func TestMakeStacktrace(t *testing.T) {
+	stack := makeStacktrace(capturePCs(0))
+	if stack == nil {
+		t.Fatal("expected a stack trace")
+	}
+	// The innermost frame comes last.
+	if f := stack.Frames[len(stack.Frames)-1]; f.Function != "TestMakeStacktrace" {
+		t.Errorf("expected the innermost frame to be the caller, got %+v", f)
+	}
+	if makeStacktrace(nil) != nil {
+		t.Error("expected no stack trace without frames")
+	}
+}
+
+func TestPreviewCrashReport(t *testing.T) {
+	recorded := len(getRecentCrashPackets())
+	data, err := PreviewCrashReport(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packet struct {
+		Message string                 `json:"message"`
+		Extra   map[string]interface{} `json:"extra"`
+	}
+	if err := json.Unmarshal(data, &packet); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(packet.Message, "crash report preview crash_reporting.go:") {
+		t.Errorf("expected the message to report the location of the preview, got %q", packet.Message)
+	}
+	for _, extra := range []string{"breadcrumbs", "goroutines", "environment", "log_config"} {
+		if _, ok := packet.Extra[extra]; !ok {
+			t.Errorf("expected the %q extra in %s", extra, data)
+		}
+	}
+	if n := len(getRecentCrashPackets()); n != recorded {
+		t.Errorf("expected the preview not to be recorded, found %d packets instead of %d", n, recorded)
+	}
+}
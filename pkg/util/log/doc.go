@@ -52,13 +52,111 @@
 //	--log-dir="..."
 //		Log files will be written to this directory instead of the
 //		default target directory.
+//  --log-profile=PROFILE
+//    Configure the thresholds, verbosity, flushing and sampling of all
+//    outputs at once for a common scenario: "production", "development",
+//    "debug-incident" or "minimal". Flags given after it override the
+//    corresponding parameters of the profile. On the nodes started
+//    without it, the profile can be selected with the log.profile
+//    cluster setting.
 //  --log-file-verbosity=LEVEL
 //    Entries with severity below LEVEL are not written to the log file.
 //    "true" and "false" are also supported (everything / nothing).
+//  --log-file-flush-threshold=LEVEL
+//    Entries with severity at or above LEVEL are flushed to the log file
+//    immediately; others are buffered and flushed periodically.
+//    Defaults to WARNING.
+//  --log-stderr-format=FORMAT, --log-file-format=FORMAT
+//    Format of the entries written to stderr and to the log file:
+//    "crdb-v1" (plain text), "crdb-v1-tty" (text with colors) or "json"
+//    (one JSON object per line, with the severity, time, goroutine,
+//    file, line, log tags and message of the entry). Stderr defaults to
+//    "crdb-v1-tty", the log file to "crdb-v1".
+//  --log-format=FORMAT
+//    Sets the format of both stderr and the log file.
+//  --log-stderr-redact, --log-file-redact
+//    Replace the log tags and message arguments which may contain user
+//    data by "<redacted>" in the entries written to stderr or to the log
+//    file, as done in crash reports. Arguments wrapped in log.Safe are
+//    kept, and those wrapped in log.Unsafe are redacted even if numeric.
+//  --log-fifo=PATH
+//    Log entries are also written to the named pipe at PATH. Writes
+//    never block: while the reader is stalled or absent, entries are
+//    buffered up to a limit, then dropped, and the number of dropped
+//    entries is written to the pipe once the reader catches up.
+//  --log-fifo-verbosity=LEVEL
+//    Entries with severity below LEVEL are not written to the pipe.
+//  --log-fifo-failover=SINK[,SINK...]
+//    Entries which cannot be written to the pipe are written to the
+//    first of these sinks which is healthy: "spill", a file in the log
+//    directory, or "stderr". Failed sinks are retried periodically, so
+//    delivery resumes automatically when they recover.
+//  --log-net-addr=HOST:PORT
+//    Log entries are also sent to the TCP collector at this address.
+//    Entries are queued while the collector is unavailable, and the
+//    connection is re-established automatically, backing off
+//    exponentially while the collector keeps failing.
+//  --log-net-verbosity=LEVEL
+//    Entries with severity below LEVEL are not sent to the collector.
+//  --log-net-spool-max-size=N
+//    If non-zero, entries for the collector are spooled in the log
+//    directory, up to N bytes, instead of being queued in memory. Spooled
+//    entries are delivered at least once, including across restarts.
+//  --log-net-compression=none|gzip
+//    Compression of the stream sent to the collector. Defaults to none.
+//  --log-net-tls
+//    Use TLS to connect to the collector.
+//  --log-net-tls-ca=FILE, --log-net-tls-cert=FILE, --log-net-tls-key=FILE,
+//  --log-net-tls-server-name=NAME, --log-net-tls-min-version=VERSION
+//    The TLS options of all the sinks connecting over TLS: a custom
+//    certificate authority, a client certificate, the name to verify in
+//    the collector's certificate and the minimum TLS version (default
+//    1.2). Certificates are read again on every connection, so rotated
+//    certificates are picked up without a restart.
+//  --log-syslog=local|udp://HOST:PORT|tcp://HOST:PORT|tls://HOST:PORT
+//    Log entries are also written to syslog, with a priority reflecting
+//    their severity. Over TLS, messages follow RFC 5424 and are framed
+//    as per RFC 5425. Not supported on Windows.
+//  --log-syslog-tag=TAG, --log-syslog-verbosity=LEVEL
+//    The tag of the syslog messages, the name of the program by default,
+//    and the minimum severity of the entries written to syslog.
+//  --log-fluent-addr=[tcp://|tls://|udp://]HOST:PORT
+//    Log entries are also sent to a fluentd collector: over TCP, with or
+//    without TLS, with the forward protocol, queued like for
+//    --log-net-addr; over UDP as one MessagePack record per datagram,
+//    for the udp input of fluentd.
+//  --log-fluent-tag=TAG, --log-fluent-verbosity=LEVEL
+//    The fluentd tag of the entries, the name of the program by default,
+//    and the minimum severity of the entries sent to the collector.
 //  --log-file-max-size=N
 //    Log files are rotated after reaching that size.
 //  --log-dir-max-size=N
 //    Log files are removed after log directory reaches that size.
+//  --log-file-max-age=DURATION
+//    Log files are removed once older than that, unless overridden by
+//    the log.file.max_age cluster setting.
+//  --log-file-compress
+//    Log files are gzipped once rotated, which is also done when the
+//    log.file.compression.enabled cluster setting is set.
+//  --log-audit-file-max-size=N
+//    Audit log files are rotated after reaching that size. When the
+//    log.audit.enabled cluster setting is set, DDL, privilege changes,
+//    logins and cluster setting changes are recorded in the audit log
+//    files, in a subdirectory of the log directory. Their events are
+//    numbered and chained by hash, so that VerifyAuditLog detects the
+//    events which were removed or altered.
+//  --log-audit-dir-max-size=N
+//    The oldest audit log files are removed once their combined size
+//    reaches that size. By default, they are never removed.
+//  --log-slow-query-file-max-size=N, --log-slow-query-dir-max-size=N
+//    The statements slower than the sql.log.slow_query.latency_threshold
+//    cluster setting are recorded, anonymized, in the slow query log
+//    files, in a subdirectory of the log directory. These files are
+//    rotated after reaching the first size, and the oldest are removed
+//    once their combined size reaches the second.
+//  --log-fatal-drain-timeout=DURATION
+//    Maximum time spent flushing logs and crash reports on a fatal error
+//    before the process exits.
 //
 //	Other flags provide aids to debugging.
 //
@@ -77,5 +175,12 @@
 //		"glob" pattern and N is a V level. For instance,
 //			-vmodule=gopher*=3
 //		sets the V level to 3 in all Go files whose names begin "gopher".
+//		Patterns containing slashes match the trailing components of
+//		the paths of the files instead, for instance
+//			-vmodule=storage/*=2
+//		sets the V level to 2 in the files of the storage package. The
+//		setting can be changed while the process is running with
+//		SetVModule or at /debug/vmodule/SPEC, and is served at
+//		/debug/vmodule.
 //
 package log
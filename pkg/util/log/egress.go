@@ -0,0 +1,183 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !nophonehome
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	raven "github.com/getsentry/raven-go"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// phoneHome is false in the builds tagged nophonehome, from which the
+// transports sending data out of the process are compiled out, so that
+// distributions can guarantee that the log package never connects to
+// the network.
+const phoneHome = true
+
+// newCrashReportTransport returns the transport uploading crash reports.
+func newCrashReportTransport() raven.Transport {
+	return newQuotaTransport()
+}
+
+// isEgressTransport returns true if t sends reports to the crash
+// reporting server.
+func isEgressTransport(t raven.Transport) bool {
+	switch t.(type) {
+	case *raven.HTTPTransport, *quotaTransport:
+		return true
+	}
+	return false
+}
+
+// dialTCP connects to a log collector.
+func dialTCP(addr string) (net.Conn, error) {
+	return net.DialTimeout("tcp", addr, netSinkDialTimeout)
+}
+
+// dialUDP sets up the sending of datagrams to a log collector.
+func dialUDP(addr string) (net.Conn, error) {
+	return net.DialTimeout("udp", addr, netSinkDialTimeout)
+}
+
+// checkReachable issues a HEAD request to url. Any response, including
+// an error status, means that the server is reachable.
+func checkReachable(ctx context.Context, url string, timeout time.Duration) error {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// quotaTransport is a raven.Transport which uploads reports like the
+// default one, and honors the requests of the server to back off.
+type quotaTransport struct {
+	client *http.Client
+}
+
+var _ raven.Transport = (*quotaTransport)(nil)
+
+func newQuotaTransport() *quotaTransport {
+	return &quotaTransport{client: &http.Client{Timeout: crashReportSendTimeout}}
+}
+
+// Send is part of the raven.Transport interface. The report is spooled
+// before it is uploaded, and left in the spool if the upload fails in a
+// way which may be retried later.
+func (t *quotaTransport) Send(url, authHeader string, packet *raven.Packet) error {
+	if url == "" {
+		return nil
+	}
+	body, err := packet.JSON()
+	if err != nil {
+		return errors.Wrap(err, "error marshaling packet")
+	}
+	path, spoolErr := spoolCrashReport(packet.EventID, body, time.Now())
+	if spoolErr != nil {
+		metaLogf("crash-report-spool", "unable to spool crash report %s: %s", packet.EventID, spoolErr)
+	}
+	keep, err := t.upload(url, authHeader, body)
+	if !keep && path != "" {
+		crashReportSpool.Lock()
+		removeSpooledReport(path)
+		crashReportSpool.Unlock()
+	}
+	return err
+}
+
+// upload posts body, the JSON encoding of a packet, to url. The boolean
+// return value is true if the upload failed in a way which may be
+// retried later, such as a network error or the server asking to back
+// off.
+func (t *quotaTransport) upload(url, authHeader string, body []byte) (bool, error) {
+	if crashReportsPaused(time.Now()) {
+		return true, errors.New("crash reports are paused at the request of the server")
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Sentry-Auth", authHeader)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		d := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		pauseCrashReports(time.Now().Add(d))
+		return true, errors.Errorf("crash reporting server is over quota, backing off for %s", d)
+	default:
+		// Server errors may be transient, whereas the server will reject
+		// the report again in response to any other status.
+		return resp.StatusCode >= 500, errors.Errorf("crash reporting server returned status %d", resp.StatusCode)
+	}
+}
+
+// crashReportAuthHeader returns the authentication header of the uploads
+// to the crash reporting server of the given DSN, as computed by the
+// raven client.
+func crashReportAuthHeader(dsn string) (string, error) {
+	uri, err := neturl.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	if uri.User == nil {
+		return "", raven.ErrMissingUser
+	}
+	if secret, ok := uri.User.Password(); ok {
+		return fmt.Sprintf("Sentry sentry_version=4, sentry_key=%s, sentry_secret=%s",
+			uri.User.Username(), secret), nil
+	}
+	return fmt.Sprintf("Sentry sentry_version=4, sentry_key=%s", uri.User.Username()), nil
+}
+
+// uploadCrashReport uploads body, the JSON encoding of a spooled packet,
+// with the transport installed by SetupCrashReporter. As for the reports
+// captured by the process, it never sends reports from test binaries or
+// from processes running in CI. The boolean return value is true if the
+// report is to be kept for a later attempt.
+func uploadCrashReport(body []byte) (bool, error) {
+	t, ok := raven.DefaultClient.Transport.(*quotaTransport)
+	if !ok || isTestOrCI() {
+		return true, errors.New("crash reports are not sent by this process")
+	}
+	authHeader, err := crashReportAuthHeader(crashReportURL)
+	if err != nil {
+		return false, err
+	}
+	return t.upload(raven.DefaultClient.URL(), authHeader, body)
+}
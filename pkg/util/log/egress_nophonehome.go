@@ -0,0 +1,73 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build nophonehome
+
+package log
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	raven "github.com/getsentry/raven-go"
+	"golang.org/x/net/context"
+)
+
+// phoneHome is false in the builds tagged nophonehome: the transports
+// which would send crash reports and log entries out of the process are
+// replaced by ones which refuse to, so that distributions can guarantee
+// that the log package never connects to the network. Reports are still
+// constructed and handed over to the transports installed by tests.
+const phoneHome = false
+
+var errNoPhoneHome = errors.New("outbound connections are disabled in this build")
+
+// discardTransport is a raven.Transport which drops all reports.
+type discardTransport struct{}
+
+var _ raven.Transport = discardTransport{}
+
+// Send is part of the raven.Transport interface.
+func (discardTransport) Send(url, authHeader string, packet *raven.Packet) error {
+	return errNoPhoneHome
+}
+
+func newCrashReportTransport() raven.Transport {
+	return discardTransport{}
+}
+
+func isEgressTransport(t raven.Transport) bool {
+	switch t.(type) {
+	case *raven.HTTPTransport, discardTransport:
+		return true
+	}
+	return false
+}
+
+func dialTCP(addr string) (net.Conn, error) {
+	return nil, errNoPhoneHome
+}
+
+func dialUDP(addr string) (net.Conn, error) {
+	return nil, errNoPhoneHome
+}
+
+func checkReachable(ctx context.Context, url string, timeout time.Duration) error {
+	return errNoPhoneHome
+}
+
+func uploadCrashReport(body []byte) (bool, error) {
+	return true, errNoPhoneHome
+}
@@ -0,0 +1,162 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sink is a destination for log entries provided by another package,
+// registered with RegisterSink.
+type Sink interface {
+	// Output delivers an entry. It is called from a goroutine dedicated to
+	// the sink, one entry at a time, so it may block: entries are buffered
+	// in the meantime, and dropped once the buffer is full. An entry for
+	// which an error is returned is not delivered again.
+	Output(entry Entry) error
+}
+
+// entrySinkBufferSize is the number of entries buffered by a sink taking
+// entries while its Output method is blocked.
+const entrySinkBufferSize = 1000
+
+// entryWriter is implemented by the sinks which take entries rather than
+// formatted data, to which outputToSinks hands entries as they are. They
+// are configured with the json format, which their write method decodes
+// for when they are wrapped in another sink.
+type entryWriter interface {
+	writeEntry(entry Entry) error
+}
+
+// bufferedSink delivers entries to a Sink from a dedicated goroutine, so
+// that a slow Sink never blocks logging.
+type bufferedSink struct {
+	health  sinkHealth
+	name    string
+	sink    Sink
+	entries chan Entry
+	stopper chan struct{}
+	done    chan struct{}
+}
+
+func newBufferedSink(name string, sink Sink, bufferSize int) *bufferedSink {
+	s := &bufferedSink{
+		name:    name,
+		sink:    sink,
+		entries: make(chan Entry, bufferSize),
+		stopper: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.health.name = name
+	go s.run()
+	return s
+}
+
+func (s *bufferedSink) String() string {
+	return s.name
+}
+
+// errEntrySinkFull is returned when an entry is dropped because a sink
+// does not keep up.
+var errEntrySinkFull = errors.New("log: sink buffer full, entry dropped")
+
+func (s *bufferedSink) writeEntry(entry Entry) error {
+	select {
+	case s.entries <- entry:
+		return nil
+	default:
+		s.health.recordDropped()
+		return errEntrySinkFull
+	}
+}
+
+func (s *bufferedSink) write(data []byte) error {
+	var entry Entry
+	if err := decodeJSONEntry(data, &entry); err != nil {
+		return err
+	}
+	return s.writeEntry(entry)
+}
+
+func (s *bufferedSink) backpressure() float64 {
+	return float64(len(s.entries)) / float64(cap(s.entries))
+}
+
+func (s *bufferedSink) recordDropped() {
+	s.health.recordDropped()
+}
+
+func (s *bufferedSink) status() SinkStatus {
+	return s.health.status(s.name, len(s.entries))
+}
+
+// close stops the sink. Buffered entries are discarded.
+func (s *bufferedSink) close() {
+	close(s.stopper)
+	<-s.done
+}
+
+func (s *bufferedSink) run() {
+	defer close(s.done)
+	setProfilerLabels(profilerLabelSink)
+	for {
+		select {
+		case entry := <-s.entries:
+			if err := s.sink.Output(entry); err != nil {
+				s.health.recordError(err)
+				continue
+			}
+			s.health.setConnected(true)
+			s.health.recordSent(len(entry.Message))
+		case <-s.stopper:
+			return
+		}
+	}
+}
+
+// RegisterSink adds a sink to which the entries at or above threshold are
+// delivered, in addition to stderr, the log files and the sinks
+// configured by flags. It panics if a sink of the same name is already
+// registered. The returned function removes the sink, discarding the
+// entries it has not delivered yet.
+func RegisterSink(name string, sink Sink, threshold Severity) (remove func()) {
+	s := newBufferedSink(name, sink, entrySinkBufferSize)
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	for _, c := range logging.sinks {
+		if c.sink.String() == name {
+			s.close()
+			panic(fmt.Sprintf("log sink %q already registered", name))
+		}
+	}
+	logging.sinks = append(logging.sinks, sinkConfig{
+		sink:      s,
+		threshold: threshold,
+		format:    formatJSON,
+	})
+	return func() {
+		logging.mu.Lock()
+		sinks := make([]sinkConfig, 0, len(logging.sinks))
+		for _, c := range logging.sinks {
+			if c.sink != logSink(s) {
+				sinks = append(sinks, c)
+			}
+		}
+		logging.sinks = sinks
+		logging.mu.Unlock()
+		s.close()
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// entryChanSink is a Sink sending the entries it is given on a channel.
+type entryChanSink chan Entry
+
+func (c entryChanSink) Output(entry Entry) error {
+	c <- entry
+	return nil
+}
+
+func TestRegisterSink(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	entries := make(entryChanSink, 10)
+	remove := RegisterSink("test", entries, Severity_WARNING)
+	ctx := context.Background()
+	Info(ctx, "below the threshold")
+	Warning(ctx, "delivered")
+	select {
+	case e := <-entries:
+		if e.Severity != Severity_WARNING || !strings.Contains(e.Message, "delivered") {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("entry not delivered")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected registering a sink twice to panic")
+			}
+		}()
+		RegisterSink("test", entries, Severity_INFO)
+	}()
+
+	remove()
+	Warning(ctx, "after removal")
+	select {
+	case e := <-entries:
+		t.Errorf("unexpected entry %+v", e)
+	default:
+	}
+}
+
+func TestBufferedSinkWrite(t *testing.T) {
+	entries := make(entryChanSink, 1)
+	s := newBufferedSink("test", entries, 1)
+	defer s.close()
+
+	// Written to through another sink, a buffered sink decodes entries in
+	// the json format.
+	entry := Entry{Severity: Severity_ERROR, Time: 1e9 + 5, Goroutine: 3, File: "f.go", Line: 7, Message: "[n1] hello"}
+	buf := formatJSONEntry(entry, nil)
+	defer logging.putBuffer(buf)
+	if err := s.write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-entries:
+		if e != entry {
+			t.Errorf("expected %+v, got %+v", entry, e)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("entry not delivered")
+	}
+	if err := s.write([]byte("not json\n")); err == nil {
+		t.Error("expected an error writing a malformed entry")
+	}
+}
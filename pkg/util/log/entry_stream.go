@@ -0,0 +1,225 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// entryStreamFlushInterval is the number of entries written between two
+// flushes of the HTTP response, so that clients start receiving entries
+// before the log files have been read entirely.
+const entryStreamFlushInterval = 100
+
+// entryFilter selects the entries served by ServeLogEntries.
+type entryFilter struct {
+	// severity is the minimum severity of the entries.
+	severity Severity
+	// startTime and endTime, in nanoseconds since the epoch, bound the
+	// time of the entries, inclusively.
+	startTime, endTime int64
+	// pattern, if set, must match either the message or the file of the
+	// entries.
+	pattern *regexp.Regexp
+	// max, if positive, is the maximum number of entries.
+	max int
+}
+
+// match returns true if the entry passes the filter. Gaps in the sequence
+// of entries are always reported if they fall in the time range.
+func (f *entryFilter) match(entry Entry) bool {
+	if entry.Time < f.startTime || entry.Time > f.endTime {
+		return false
+	}
+	if IsGap(entry) {
+		return true
+	}
+	if entry.Severity < f.severity {
+		return false
+	}
+	return f.pattern == nil || f.pattern.MatchString(entry.Message) ||
+		f.pattern.MatchString(entry.File)
+}
+
+// parseEntryFilter parses the parameters of a request served by
+// ServeLogEntries.
+func parseEntryFilter(r *http.Request) (entryFilter, error) {
+	q := r.URL.Query()
+	f := entryFilter{severity: Severity_INFO, endTime: math.MaxInt64}
+	if s := q.Get("severity"); s != "" {
+		var ok bool
+		if f.severity, ok = SeverityByName(s); !ok {
+			return f, errors.Errorf("unknown severity %q", s)
+		}
+	}
+	var err error
+	if s := q.Get("start_time"); s != "" {
+		if f.startTime, err = parseEntryTime(s); err != nil {
+			return f, errors.Wrap(err, "invalid start_time parameter")
+		}
+	}
+	if s := q.Get("end_time"); s != "" {
+		if f.endTime, err = parseEntryTime(s); err != nil {
+			return f, errors.Wrap(err, "invalid end_time parameter")
+		}
+	}
+	if f.startTime > f.endTime {
+		return f, errors.New("start_time is after end_time")
+	}
+	if s := q.Get("pattern"); s != "" {
+		if f.pattern, err = regexp.Compile(s); err != nil {
+			return f, errors.Wrap(err, "invalid pattern parameter")
+		}
+	}
+	if s := q.Get("max"); s != "" {
+		if f.max, err = strconv.Atoi(s); err != nil {
+			return f, errors.Wrap(err, "invalid max parameter")
+		}
+	}
+	return f, nil
+}
+
+// parseEntryTime parses a time given either in nanoseconds since the
+// epoch, as in the entries, or in RFC 3339 format.
+func parseEntryTime(s string) (int64, error) {
+	if nanos, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return nanos, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano(), nil
+}
+
+// streamEntries writes the entries of files which pass the filter to w as
+// a JSON array, in chronological order. flush, if not nil, is called
+// periodically. If an error occurs after the first byte is written, the
+// array is left unterminated so that the truncation is not mistaken for
+// the end of the entries.
+func streamEntries(w io.Writer, flush func(), files []FileInfo, f entryFilter) error {
+	// Go through the files in chronological order. Files which were last
+	// written to before the start of the time range are skipped.
+	sort.Sort(sortableFileInfoSlice(files))
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	n := 0
+	for _, file := range files {
+		if file.Details.Time > f.endTime || file.ModTimeNanos < f.startTime {
+			continue
+		}
+		done, err := streamEntriesFromFile(w, flush, file, f, &n)
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
+
+// streamEntriesFromFile writes the entries of file which pass the filter
+// to w, following the n entries already written. The entries are redacted
+// as in the support bundles before they are filtered, so that the pattern
+// can't probe the stripped values. It returns true once the maximum
+// number of entries is reached.
+func streamEntriesFromFile(
+	w io.Writer, flush func(), file FileInfo, f entryFilter, n *int,
+) (bool, error) {
+	reader, err := GetLogReader(file.Name, true /* restricted */)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	decoder := NewEntryDecoder(reader)
+	redactor := makeEntryRedactor()
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, err
+		}
+		redactor.redact(&entry)
+		if !f.match(entry) {
+			continue
+		}
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return false, err
+		}
+		sep := ",\n"
+		if *n == 0 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return false, err
+		}
+		if _, err := w.Write(b); err != nil {
+			return false, err
+		}
+		*n++
+		if f.max > 0 && *n >= f.max {
+			return true, nil
+		}
+		if flush != nil && *n%entryStreamFlushInterval == 0 {
+			flush()
+		}
+	}
+}
+
+// ServeLogEntries streams, as a JSON array, the entries of the log files
+// of this node which are of at least the "severity" parameter (INFO by
+// default), fall between the "start_time" and "end_time" parameters, given
+// in nanoseconds since the epoch or in RFC 3339 format, and whose message
+// or file match the "pattern" regexp. The "max" parameter limits the
+// number of entries. Unlike the admin UI, which downloads whole files,
+// this only transfers the entries of interest. The values wrapped in
+// Unsafe are stripped from the entries, as in the support bundles. The
+// caller is responsible for authorizing the request.
+func ServeLogEntries(w http.ResponseWriter, r *http.Request) {
+	f, err := parseEntryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	Flush()
+	files, err := ListLogFiles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var flush func()
+	if flusher, ok := w.(http.Flusher); ok {
+		flush = flusher.Flush
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := streamEntries(w, flush, files, f); err != nil {
+		metaLogf("logs", "unable to stream log entries: %s", err)
+	}
+}
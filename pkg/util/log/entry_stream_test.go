@@ -0,0 +1,138 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestLogEntriesEndpoint(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	ctx := context.Background()
+	// The log files record times to the microsecond: keep the boundaries of
+	// the time range clear of the entries.
+	start := time.Now().Add(-time.Millisecond).UnixNano()
+	for i := 0; i < 3; i++ {
+		Infof(ctx, "streamed info %d", i)
+		Warningf(ctx, "streamed warning %d", i)
+	}
+	Info(ctx, "unrelated")
+	end := time.Now().UnixNano()
+	time.Sleep(time.Millisecond)
+	Warning(ctx, "streamed too late")
+
+	get := func(params url.Values) ([]string, int) {
+		w := httptest.NewRecorder()
+		ServeLogEntries(w, httptest.NewRequest("GET", "/?"+params.Encode(), nil))
+		if w.Code != http.StatusOK {
+			return nil, w.Code
+		}
+		var entries []Entry
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("%s: %s", err, w.Body.String())
+		}
+		var messages []string
+		for _, e := range entries {
+			messages = append(messages, e.Message)
+		}
+		return messages, w.Code
+	}
+
+	timeRange := url.Values{
+		"start_time": {fmt.Sprint(start)},
+		"end_time":   {time.Unix(0, end).UTC().Format(time.RFC3339Nano)},
+	}
+	testCases := []struct {
+		params   map[string]string
+		expected []string
+	}{
+		{map[string]string{"pattern": "^streamed"}, []string{
+			"streamed info 0", "streamed warning 0", "streamed info 1", "streamed warning 1",
+			"streamed info 2", "streamed warning 2",
+		}},
+		{map[string]string{"severity": "warning"}, []string{
+			"streamed warning 0", "streamed warning 1", "streamed warning 2",
+		}},
+		{map[string]string{"pattern": "info [12]"}, []string{"streamed info 1", "streamed info 2"}},
+		{map[string]string{"severity": "WARNING", "max": "2"}, []string{
+			"streamed warning 0", "streamed warning 1",
+		}},
+		{map[string]string{"pattern": "nothing"}, nil},
+	}
+	for _, tc := range testCases {
+		params := url.Values{}
+		for k, v := range timeRange {
+			params[k] = v
+		}
+		for k, v := range tc.params {
+			params.Set(k, v)
+		}
+		messages, _ := get(params)
+		if fmt.Sprint(messages) != fmt.Sprint(tc.expected) {
+			t.Errorf("%v: expected %q, got %q", tc.params, tc.expected, messages)
+		}
+	}
+
+	for _, params := range []url.Values{
+		{"severity": {"loud"}},
+		{"start_time": {"yesterday"}},
+		{"start_time": {"2"}, "end_time": {"1"}},
+		{"pattern": {"("}},
+		{"max": {"many"}},
+	} {
+		if _, code := get(params); code != http.StatusBadRequest {
+			t.Errorf("%v: expected status %d, got %d", params, http.StatusBadRequest, code)
+		}
+	}
+}
+
+func TestLogEntriesRedacted(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	Infof(context.Background(), "the key of %s is %s", "alice", Unsafe{V: "hunter2"})
+
+	for pattern, expected := range map[string]string{
+		"the key":  "[the key of alice is <redacted>]",
+		"hunter2":  "[]",
+		"redacted": "[the key of alice is <redacted>]",
+	} {
+		w := httptest.NewRecorder()
+		ServeLogEntries(w, httptest.NewRequest("GET", "/?"+url.Values{"pattern": {pattern}}.Encode(), nil))
+		var entries []Entry
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("%s: %s", err, w.Body.String())
+		}
+		messages := []string{}
+		for _, e := range entries {
+			messages = append(messages, e.Message)
+		}
+		if fmt.Sprint(messages) != expected {
+			t.Errorf("%s: expected %s, got %q", pattern, expected, messages)
+		}
+	}
+}
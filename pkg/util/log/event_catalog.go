@@ -0,0 +1,186 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// ChannelInfo describes a channel of the structured log surface: the
+// tag which marks the entries of a category, such as "ops" for the
+// notable events of interest to operators.
+type ChannelInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// EventFieldInfo describes a field of the payload of an event type.
+type EventFieldInfo struct {
+	Name string `json:"name"`
+	// Type is the JSON type of the field: "string", "integer", "number",
+	// "boolean", "timestamp", "array" or "object".
+	Type string `json:"type"`
+}
+
+// EventTypeInfo describes a type of structured event logged on a
+// channel. The message of its entries is MessagePrefix followed by the
+// JSON encoding of the payload, whose fields are listed in Fields.
+type EventTypeInfo struct {
+	Name          string           `json:"name"`
+	Channel       string           `json:"channel"`
+	Description   string           `json:"description"`
+	MessagePrefix string           `json:"message_prefix"`
+	Fields        []EventFieldInfo `json:"fields"`
+}
+
+// Catalog describes the structured log surface of the process, so that
+// external tooling can stay in sync with it.
+type Catalog struct {
+	// FormatVersion is the version of the format of the log files.
+	FormatVersion int             `json:"format_version"`
+	Channels      []ChannelInfo   `json:"channels"`
+	Events        []EventTypeInfo `json:"events"`
+}
+
+var catalog struct {
+	syncutil.Mutex
+	channels map[string]ChannelInfo
+	events   map[string]EventTypeInfo
+}
+
+// RegisterChannel adds a channel to the catalog. It is meant to be called
+// during initialization, and panics if the channel is already registered.
+func RegisterChannel(name, description string) {
+	catalog.Lock()
+	defer catalog.Unlock()
+	if catalog.channels == nil {
+		catalog.channels = make(map[string]ChannelInfo)
+	}
+	if _, ok := catalog.channels[name]; ok {
+		panic(fmt.Sprintf("log channel %q already registered", name))
+	}
+	catalog.channels[name] = ChannelInfo{Name: name, Description: description}
+}
+
+// RegisterEventType adds a type of event to the catalog, whose schema is
+// derived from the exported fields of payload, a struct, and their JSON
+// names. It is meant to be called during initialization, and panics if
+// the channel is not registered or the event type already is.
+func RegisterEventType(name, channel, description, messagePrefix string, payload interface{}) {
+	t := reflect.TypeOf(payload)
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("payload of log event type %q is a %s, not a struct", name, t.Kind()))
+	}
+	catalog.Lock()
+	defer catalog.Unlock()
+	if _, ok := catalog.channels[channel]; !ok {
+		panic(fmt.Sprintf("log event type %q registered on unknown channel %q", name, channel))
+	}
+	if catalog.events == nil {
+		catalog.events = make(map[string]EventTypeInfo)
+	}
+	if _, ok := catalog.events[name]; ok {
+		panic(fmt.Sprintf("log event type %q already registered", name))
+	}
+	info := EventTypeInfo{
+		Name:          name,
+		Channel:       channel,
+		Description:   description,
+		MessagePrefix: messagePrefix,
+		Fields:        []EventFieldInfo{},
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		fieldName := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				fieldName = n
+			}
+		}
+		info.Fields = append(info.Fields, EventFieldInfo{Name: fieldName, Type: jsonType(f.Type)})
+	}
+	catalog.events[name] = info
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonType returns the JSON type of the encoding of the values of type t.
+func jsonType(t reflect.Type) string {
+	if t == timeType {
+		return "timestamp"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Ptr:
+		return jsonType(t.Elem())
+	default:
+		return "object"
+	}
+}
+
+// GetCatalog returns the catalog of the channels and event types,
+// sorted by name.
+func GetCatalog() Catalog {
+	catalog.Lock()
+	defer catalog.Unlock()
+	c := Catalog{
+		FormatVersion: FormatVersion,
+		Channels:      make([]ChannelInfo, 0, len(catalog.channels)),
+		Events:        make([]EventTypeInfo, 0, len(catalog.events)),
+	}
+	for _, ch := range catalog.channels {
+		c.Channels = append(c.Channels, ch)
+	}
+	for _, e := range catalog.events {
+		c.Events = append(c.Events, e)
+	}
+	sort.Slice(c.Channels, func(i, j int) bool { return c.Channels[i].Name < c.Channels[j].Name })
+	sort.Slice(c.Events, func(i, j int) bool { return c.Events[i].Name < c.Events[j].Name })
+	return c
+}
+
+const httpCatalogPath = "/debug/logs/catalog"
+
+// handleCatalog serves the catalog as JSON.
+func handleCatalog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(GetCatalog()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
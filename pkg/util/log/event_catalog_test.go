@@ -0,0 +1,84 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCatalog(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleCatalog(w, httptest.NewRequest("GET", httpCatalogPath, nil))
+	var c Catalog
+	if err := json.NewDecoder(w.Body).Decode(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.FormatVersion != FormatVersion {
+		t.Errorf("expected format version %d, got %d", FormatVersion, c.FormatVersion)
+	}
+
+	var found bool
+	for _, ch := range c.Channels {
+		found = found || ch.Name == opsTag
+	}
+	if !found {
+		t.Errorf("expected the %s channel in %+v", opsTag, c.Channels)
+	}
+
+	expected := EventTypeInfo{
+		Name:          configChangeEvent,
+		Channel:       opsTag,
+		Description:   "a change to the logging configuration made while the process is running",
+		MessagePrefix: configChangePrefix,
+		Fields: []EventFieldInfo{
+			{"time", "timestamp"}, {"who", "string"}, {"what", "string"}, {"old", "string"}, {"new", "string"},
+		},
+	}
+	for _, e := range c.Events {
+		if e.Name == configChangeEvent && !reflect.DeepEqual(e, expected) {
+			t.Errorf("expected %+v, got %+v", expected, e)
+		}
+	}
+}
+
+func TestRegisterEventType(t *testing.T) {
+	expectPanic := func(name string, fn func()) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("%s: expected a panic", name)
+			}
+		}()
+		fn()
+	}
+	expectPanic("unknown channel", func() {
+		RegisterEventType("test_event", "unknown", "", "", struct{}{})
+	})
+	expectPanic("duplicate", func() {
+		RegisterEventType(configChangeEvent, opsTag, "", "", ConfigChange{})
+	})
+	expectPanic("not a struct", func() {
+		RegisterEventType("test_event", opsTag, "", "", 1)
+	})
+
+	if typ := jsonType(reflect.TypeOf(&struct {
+		T []time.Time
+	}{})); typ != "object" {
+		t.Errorf("expected object, got %s", typ)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// EveryN rate-limits log messages which may recur often, such as the
+// errors of a remote service while it is unreachable. It tracks when the
+// message was last logged.
+type EveryN struct {
+	// N is the minimum duration between two messages.
+	N time.Duration
+
+	syncutil.Mutex
+	lastLog time.Time
+}
+
+// Every returns an EveryN allowing a message every n.
+func Every(n time.Duration) EveryN {
+	return EveryN{N: n}
+}
+
+// ShouldLog returns whether at least N has elapsed since the last time it
+// returned true, in which case the message is to be logged.
+func (e *EveryN) ShouldLog() bool {
+	return e.shouldLogAt(time.Now())
+}
+
+func (e *EveryN) shouldLogAt(now time.Time) bool {
+	e.Lock()
+	defer e.Unlock()
+	if now.Sub(e.lastLog) < e.N {
+		return false
+	}
+	e.lastLog = now
+	return true
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEveryN(t *testing.T) {
+	start := time.Now()
+	e := Every(time.Minute)
+	testCases := []struct {
+		offset   time.Duration
+		expected bool
+	}{
+		{0, true},
+		{time.Second, false},
+		{time.Minute - time.Second, false},
+		{time.Minute, true},
+		{time.Minute + time.Second, false},
+		{3 * time.Minute, true},
+	}
+	for _, tc := range testCases {
+		if actual := e.shouldLogAt(start.Add(tc.offset)); actual != tc.expected {
+			t.Errorf("%s: expected %t, got %t", tc.offset, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+)
+
+// ExitClass categorizes the reason for which the process exited.
+type ExitClass string
+
+const (
+	// ExitClassFatal indicates that the process exited because of a
+	// Fatal log entry.
+	ExitClassFatal ExitClass = "fatal"
+	// ExitClassPanic indicates that the process exited because of an
+	// unrecovered panic.
+	ExitClassPanic ExitClass = "panic"
+)
+
+// ExitReason is the machine-readable description of why a process
+// exited. It is stored in the log directory so that the next process
+// using the same directory, as well as external orchestrators, can
+// find out why the previous process went away.
+type ExitReason struct {
+	Class ExitClass `json:"class"`
+	// Fingerprint identifies the location and format of the fatal
+	// message without revealing its (possibly sensitive) arguments.
+	Fingerprint string    `json:"fingerprint"`
+	Time        time.Time `json:"timestamp"`
+	PID         int       `json:"pid"`
+	Version     string    `json:"version"`
+	// Restarts is the number of consecutive abnormal exits, including this
+	// one, of the processes using the same log directory. A high value
+	// indicates a crash loop.
+	Restarts int `json:"restarts"`
+}
+
+// processStart is the time at which the process started.
+var processStart = time.Now()
+
+// restartCount is the number of consecutive abnormal exits of the
+// processes which used the same log directory before this one.
+var restartCount int32
+
+// SetRestartCount records the number of consecutive abnormal exits of
+// the processes which used the same log directory before this one, as
+// found in the exit reason of the previous process. It is reported
+// alongside crash reports and carried over in the exit reason of the
+// current process should it also exit abnormally.
+func SetRestartCount(n int) {
+	atomic.StoreInt32(&restartCount, int32(n))
+}
+
+func (r ExitReason) String() string {
+	return fmt.Sprintf("%s (fingerprint %s) at %s, pid %d, version %s, %d consecutive abnormal exit(s)",
+		r.Class, r.Fingerprint, r.Time.Format(time.RFC3339), r.PID, r.Version, r.Restarts)
+}
+
+// exitReasonPath returns the path of the exit reason file in the log
+// directory.
+func exitReasonPath() (string, error) {
+	dir, err := logDir.get()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, removePeriods(program)+".last-exit.json"), nil
+}
+
+// stringFingerprint derives a short, stable identifier from s which
+// does not reveal its contents.
+func stringFingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// recordExitReason writes the exit reason file for an exit of the given
+// class. It is best-effort: the process is going away and there is
+// nobody left to handle an error, so errors are only printed to stderr.
+func recordExitReason(class ExitClass, reportable string) {
+	if !logDir.isSet() {
+		return
+	}
+	if err := writeExitReason(ExitReason{
+		Class:       class,
+		Fingerprint: stringFingerprint(reportable),
+		Time:        time.Now().UTC(),
+		PID:         pid,
+		Version:     build.GetInfo().Tag,
+		Restarts:    int(atomic.LoadInt32(&restartCount)) + 1,
+	}); err != nil {
+		fmt.Fprintf(OrigStderr, "log: unable to record exit reason: %s\n", err)
+	}
+}
+
+func writeExitReason(reason ExitReason) error {
+	path, err := exitReasonPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(reason)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that readers never observe a
+	// partially written file.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadLastExitReason returns the exit reason recorded by a previous
+// process using the same log directory. The boolean return value is
+// false if there is no such record, for instance because the previous
+// process shut down cleanly.
+func ReadLastExitReason() (ExitReason, bool, error) {
+	var reason ExitReason
+	path, err := exitReasonPath()
+	if err != nil {
+		// No log directory configured, so nothing was recorded.
+		return reason, false, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reason, false, nil
+		}
+		return reason, false, err
+	}
+	if err := json.Unmarshal(data, &reason); err != nil {
+		return reason, false, errors.Wrapf(err, "malformed exit reason file %s", path)
+	}
+	return reason, true, nil
+}
+
+// previousExitReasonPath returns the path to which the exit reason file of
+// the previous process is rotated.
+func previousExitReasonPath(path string) string {
+	return path + ".previous"
+}
+
+// RotateLastExitReason renames the exit reason recorded by a previous
+// process to "<file>.previous", so that it is not reported again after a
+// clean shutdown of the current one, while remaining available to the
+// orchestrators which check why the node restarted. The rotated record is
+// replaced by the next rotation.
+func RotateLastExitReason() error {
+	path, err := exitReasonPath()
+	if err != nil {
+		return nil
+	}
+	if err := os.Rename(path, previousExitReasonPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExitReason(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	if _, ok, err := ReadLastExitReason(); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("unexpected exit reason in empty log directory")
+	}
+
+	before := time.Now().Add(-time.Second)
+	recordExitReason(ExitClassFatal, "foo.go:12 unexpected %s")
+	reason, ok, err := ReadLastExitReason()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected an exit reason to be recorded")
+	}
+	if reason.Class != ExitClassFatal {
+		t.Errorf("expected class %s, got %s", ExitClassFatal, reason.Class)
+	}
+	if e := stringFingerprint("foo.go:12 unexpected %s"); reason.Fingerprint != e {
+		t.Errorf("expected fingerprint %s, got %s", e, reason.Fingerprint)
+	}
+	if stringFingerprint("foo.go:13 unexpected %s") == reason.Fingerprint {
+		t.Error("expected different call sites to have different fingerprints")
+	}
+	if reason.Time.Before(before) {
+		t.Errorf("unexpected timestamp %s", reason.Time)
+	}
+	if reason.PID != pid {
+		t.Errorf("expected pid %d, got %d", pid, reason.PID)
+	}
+	if reason.Restarts != 1 {
+		t.Errorf("expected 1 restart, got %d", reason.Restarts)
+	}
+
+	// A process restarted after an abnormal exit carries the count over.
+	defer SetRestartCount(0)
+	SetRestartCount(reason.Restarts)
+	recordExitReason(ExitClassPanic, "bar")
+	if reason, _, err = ReadLastExitReason(); err != nil {
+		t.Fatal(err)
+	} else if reason.Restarts != 2 {
+		t.Errorf("expected 2 restarts, got %d", reason.Restarts)
+	}
+
+	if err := RotateLastExitReason(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := ReadLastExitReason(); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("exit reason still present after being rotated")
+	}
+	// The rotated record remains available to orchestrators.
+	path, err := exitReasonPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(previousExitReasonPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var previous ExitReason
+	if err := json.Unmarshal(data, &previous); err != nil {
+		t.Fatal(err)
+	}
+	if previous.Fingerprint != reason.Fingerprint || previous.Restarts != reason.Restarts {
+		t.Errorf("expected the rotated exit reason %s, got %s", reason, previous)
+	}
+	// Rotating without a new record keeps the previous one.
+	if err := RotateLastExitReason(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(previousExitReasonPath(path)); err != nil {
+		t.Errorf("expected the rotated exit reason to be kept, got %v", err)
+	}
+}
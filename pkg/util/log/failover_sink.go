@@ -0,0 +1,134 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// failoverRetryInterval is the interval after which a failover sink
+// tries again to write to a sink which failed.
+const failoverRetryInterval = 10 * time.Second
+
+// failoverSink writes each entry to the first of an ordered chain of
+// sinks which accepts it. A sink which fails is skipped until the retry
+// interval elapses, after which it is tried again so that the chain
+// recovers automatically. The last sink of the chain is never skipped.
+type failoverSink struct {
+	sinks         []logSink
+	retryInterval time.Duration
+	// failedAt records when each sink last failed, or the zero time if it
+	// is healthy. Protected by logging.mu, as is write.
+	failedAt []time.Time
+	lastErr  []error
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+func newFailoverSink(sinks []logSink, retryInterval time.Duration) *failoverSink {
+	return &failoverSink{
+		sinks:         sinks,
+		retryInterval: retryInterval,
+		failedAt:      make([]time.Time, len(sinks)),
+		lastErr:       make([]error, len(sinks)),
+		now:           time.Now,
+	}
+}
+
+func (s *failoverSink) String() string {
+	names := make([]string, len(s.sinks))
+	for i, sink := range s.sinks {
+		names[i] = sink.String()
+	}
+	return "failover:" + strings.Join(names, ">")
+}
+
+func (s *failoverSink) write(data []byte) error {
+	now := s.now()
+	var err error
+	for i, sink := range s.sinks {
+		last := i == len(s.sinks)-1
+		if !last && !s.failedAt[i].IsZero() && now.Sub(s.failedAt[i]) < s.retryInterval {
+			continue
+		}
+		if err = sink.write(data); err == nil {
+			s.failedAt[i] = time.Time{}
+			s.lastErr[i] = nil
+			return nil
+		}
+		if s.failedAt[i].IsZero() && !last {
+			metaLogf("failover:"+sink.String(), "failing over from %s: %s", sink, err)
+		}
+		s.failedAt[i] = now
+		s.lastErr[i] = err
+	}
+	return err
+}
+
+// healthy reports whether the i-th sink of the chain is considered
+// healthy.
+func (s *failoverSink) healthy(i int) bool {
+	return s.failedAt[i].IsZero()
+}
+
+// stderrSink writes entries to the process' original stderr.
+type stderrSink struct{}
+
+func (stderrSink) String() string { return "stderr" }
+
+func (stderrSink) write(data []byte) error {
+	_, err := OrigStderr.Write(data)
+	return err
+}
+
+// spillFileName returns the name of the file, in the log directory, in
+// which entries are spilled by failover chains. It deliberately does not
+// match logFileRE.
+func spillFileName() string {
+	return removePeriods(program) + ".spill.log"
+}
+
+// spillFileSink appends entries to a local file.
+type spillFileSink struct {
+	path string
+	f    *os.File
+}
+
+func newSpillFileSink(path string) *spillFileSink {
+	return &spillFileSink{path: path}
+}
+
+func (s *spillFileSink) String() string {
+	return "spill:" + s.path
+}
+
+func (s *spillFileSink) write(data []byte) error {
+	if s.f == nil {
+		f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	if _, err := s.f.Write(data); err != nil {
+		// Reopen the file on the next attempt.
+		_ = s.f.Close()
+		s.f = nil
+		return err
+	}
+	return nil
+}
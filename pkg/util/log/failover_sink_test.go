@@ -0,0 +1,117 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testSink records the entries written to it and fails while err is set.
+type testSink struct {
+	name    string
+	err     error
+	entries []string
+}
+
+func (s *testSink) String() string { return s.name }
+
+func (s *testSink) write(data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, string(data))
+	return nil
+}
+
+func TestFailoverSink(t *testing.T) {
+	primary := &testSink{name: "primary"}
+	secondary := &testSink{name: "secondary"}
+	sink := newFailoverSink([]logSink{primary, secondary}, time.Minute)
+	now := time.Unix(0, 0)
+	sink.now = func() time.Time { return now }
+
+	if e, a := "failover:primary>secondary", sink.String(); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+
+	if err := sink.write([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	// The primary fails: entries go to the secondary.
+	primary.err = errors.New("boom")
+	if err := sink.write([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if sink.healthy(0) || !sink.healthy(1) {
+		t.Error("expected only the primary to be unhealthy")
+	}
+	// The primary recovers, but is not retried before the interval elapses.
+	primary.err = nil
+	if err := sink.write([]byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if err := sink.write([]byte("d")); err != nil {
+		t.Fatal(err)
+	}
+	if !sink.healthy(0) {
+		t.Error("expected the primary to have recovered")
+	}
+
+	if e := []string{"a", "d"}; !reflect.DeepEqual(primary.entries, e) {
+		t.Errorf("expected primary entries %v, got %v", e, primary.entries)
+	}
+	if e := []string{"b", "c"}; !reflect.DeepEqual(secondary.entries, e) {
+		t.Errorf("expected secondary entries %v, got %v", e, secondary.entries)
+	}
+
+	// When all sinks fail, the last sink is still tried and its error is
+	// returned.
+	primary.err = errors.New("boom")
+	secondary.err = errors.New("bang")
+	for i := 0; i < 2; i++ {
+		if err := sink.write([]byte("e")); err != secondary.err {
+			t.Errorf("expected error %v, got %v", secondary.err, err)
+		}
+	}
+}
+
+func TestSpillFileSink(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	path := filepath.Join(s.logDir, spillFileName())
+	if logFileRE.MatchString(path) {
+		t.Errorf("spill file name %s must not match the log file pattern", path)
+	}
+	sink := newSpillFileSink(path)
+	for _, e := range []string{"a\n", "b\n"} {
+		if err := sink.write([]byte(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "a\nb\n", string(contents); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+}
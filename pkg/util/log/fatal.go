@@ -0,0 +1,114 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FatalDrainTimeout bounds the time given to the log sinks and the crash
+// reporter to flush their data when a Fatal entry is logged. The crash
+// report, which is sent first, is given at most half of it, so that the
+// flush of the log outputs completes even if the crash reporter hangs. Once
+// it elapses, the process exits regardless of whether draining completed.
+var FatalDrainTimeout = 5 * time.Second
+
+// fatalDrainGrace is the time after the end of the drain window of a Fatal
+// entry at which the hard timer terminates the process, should the regular
+// exit path hang. It leaves the regular path the time to exit once the
+// outputs were flushed.
+var fatalDrainGrace = time.Second
+
+// fatalDrainDeadline is the end of the drain window of the Fatal entry
+// being logged, in nanoseconds since the epoch, or 0. It is accessed
+// atomically.
+var fatalDrainDeadline int64
+
+// startFatalDrain starts the drain window of a Fatal entry, and arms a hard
+// timer which terminates the process if the regular exit path is not
+// reached within fatalDrainGrace after the window expired. The returned
+// function disarms the timer; it only matters when the exit function
+// returns, as is the case in tests.
+func startFatalDrain() (stop func()) {
+	timeout := FatalDrainTimeout
+	atomic.StoreInt64(&fatalDrainDeadline, time.Now().Add(timeout).UnixNano())
+	logging.mu.Lock()
+	exitFunc := logging.exitFunc
+	logging.mu.Unlock()
+	timer := time.AfterFunc(timeout+fatalDrainGrace, func() {
+		fmt.Fprintf(OrigStderr, "log: draining after fatal error did not complete within %s; exiting\n",
+			timeout)
+		exitFunc(255)
+	})
+	return func() {
+		timer.Stop()
+		atomic.StoreInt64(&fatalDrainDeadline, 0)
+	}
+}
+
+// crashReportDrainTimeout returns the time given to the crash report of a
+// fatal error: half of the drain window, the rest being left to the flush
+// of the log outputs.
+func crashReportDrainTimeout() time.Duration {
+	return FatalDrainTimeout / 2
+}
+
+// fatalFlushDeadline returns the deadline of the flush of the log outputs
+// after a Fatal entry: the end of its drain window, or FatalDrainTimeout
+// from now if none was started, as is the case of Exit.
+func fatalFlushDeadline() time.Time {
+	if d := atomic.LoadInt64(&fatalDrainDeadline); d != 0 {
+		return time.Unix(0, d)
+	}
+	return time.Now().Add(FatalDrainTimeout)
+}
+
+// waitWithin waits for ch to be signaled for at most timeout and
+// reports whether it was.
+func waitWithin(ch <-chan error, timeout time.Duration) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// goroutineDumpName returns the name of the goroutine dump file created
+// at time t. It is derived from the log file name but deliberately does
+// not match logFileRE, so that dumps are not mistaken for log files.
+func goroutineDumpName(t time.Time) string {
+	name, _ := logName(t)
+	return strings.TrimSuffix(name, ".log") + ".goroutines.txt"
+}
+
+// writeGoroutineDump writes the stacks of all running goroutines to a
+// dedicated file in the log directory and returns the file's path.
+func writeGoroutineDump(now time.Time) (string, error) {
+	dir, err := logDir.get()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, goroutineDumpName(now))
+	if err := ioutil.WriteFile(path, getStacks(true), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	raven "github.com/getsentry/raven-go"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestFatalDrainHardExit(t *testing.T) {
+	defer func(d time.Duration) { FatalDrainTimeout = d }(FatalDrainTimeout)
+	defer func(d time.Duration) { fatalDrainGrace = d }(fatalDrainGrace)
+	defer SetExitFunc(os.Exit)
+	fatalDrainGrace = 0
+
+	exited := make(chan int, 1)
+	SetExitFunc(func(code int) { exited <- code })
+
+	// An expired drain window must terminate the process.
+	FatalDrainTimeout = time.Millisecond
+	stop := startFatalDrain()
+	select {
+	case code := <-exited:
+		if code != 255 {
+			t.Errorf("expected exit code 255, got %d", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("hard exit timer did not fire")
+	}
+	stop()
+
+	// A stopped drain must not.
+	FatalDrainTimeout = 50 * time.Millisecond
+	startFatalDrain()()
+	select {
+	case code := <-exited:
+		t.Fatalf("unexpected exit with code %d", code)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// flushRecorder is a log file writer which records whether it was flushed.
+type flushRecorder struct {
+	flushBuffer
+	flushed int32
+}
+
+func (f *flushRecorder) Flush() error {
+	atomic.StoreInt32(&f.flushed, 1)
+	return nil
+}
+
+// Test that the log outputs are flushed before the process exits on a
+// fatal error, even if the crash report hangs.
+func TestFatalFlushWithHangingCrashReport(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	logging.stderrThreshold = Severity_NONE
+	defer setFlags()
+	w := &flushRecorder{}
+	defer logging.swap(logging.swap(w))
+
+	defer settings.TestingSetBool(&DiagnosticsReportingEnabled, true)()
+	unblock := make(chan struct{})
+	defer close(unblock)
+	defer TestingInterceptCrashReports(func(*raven.Packet) { <-unblock })()
+
+	defer func(d time.Duration) { FatalDrainTimeout = d }(FatalDrainTimeout)
+	FatalDrainTimeout = 200 * time.Millisecond
+	exits := make(chan bool, 2)
+	SetExitFunc(func(int) { exits <- atomic.LoadInt32(&w.flushed) == 1 })
+
+	Fatalf(context.Background(), "hanging crash report")
+	// The first exit is the regular one, once the fatal entry was written
+	// and flushed, rather than the hard exit.
+	if flushed := <-exits; !flushed {
+		t.Fatal("expected the log file to be flushed before exiting")
+	}
+	if !strings.Contains(w.String(), "hanging crash report") {
+		t.Errorf("expected the fatal entry to be logged, got %s", w.String())
+	}
+}
+
+func TestWaitWithin(t *testing.T) {
+	ch := make(chan error, 1)
+	if waitWithin(ch, time.Millisecond) {
+		t.Error("expected wait on unsignaled channel to time out")
+	}
+	ch <- nil
+	if !waitWithin(ch, time.Second) {
+		t.Error("expected wait on signaled channel to succeed")
+	}
+}
+
+func TestFatalGoroutineDump(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	logging.stderrThreshold = Severity_NONE
+	SetExitFunc(func(int) {})
+
+	defer setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	Fatalf(context.Background(), "cinap")
+	cont := contents()
+	re := regexp.MustCompile(`stacks of all goroutines written to: (\S+)`)
+	m := re.FindStringSubmatch(cont)
+	if m == nil {
+		t.Fatalf("fatal entry does not reference a goroutine dump:\n%s", cont)
+	}
+	if filepath.Dir(m[1]) != s.logDir {
+		t.Errorf("expected goroutine dump in %s, found %s", s.logDir, m[1])
+	}
+	dump, err := ioutil.ReadFile(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dump), "goroutine ") {
+		t.Errorf("goroutine dump does not contain stacks:\n%s", dump)
+	}
+}
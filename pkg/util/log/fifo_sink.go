@@ -0,0 +1,156 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// fifoSinkBufferSize is the number of entries buffered by a FIFO sink
+// while its reader is stalled or absent.
+const fifoSinkBufferSize = 1000
+
+// fifoSinkRetryInterval is the interval at which a FIFO sink retries
+// opening the FIFO when there is no reader or writing to it failed.
+const fifoSinkRetryInterval = time.Second
+
+// fifoSink writes entries to a named pipe. The pipe is written to by a
+// dedicated goroutine, so that a stalled or absent reader never blocks
+// logging: entries are buffered up to a limit, then dropped. The number
+// of entries dropped is reported to the reader once it catches up.
+type fifoSink struct {
+	health  sinkHealth
+	path    string
+	entries chan []byte
+	stopper chan struct{}
+	done    chan struct{}
+	// dropped is the number of entries dropped since the last report to
+	// the reader. Accessed atomically.
+	dropped uint64
+}
+
+func newFIFOSink(path string, bufferSize int) *fifoSink {
+	s := &fifoSink{
+		path:    path,
+		entries: make(chan []byte, bufferSize),
+		stopper: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.health.name = s.String()
+	go s.run()
+	return s
+}
+
+func (s *fifoSink) String() string {
+	return "fifo:" + s.path
+}
+
+// errFIFOSinkFull is returned when an entry is dropped because the
+// reader of the FIFO does not keep up.
+var errFIFOSinkFull = errors.New("log: FIFO buffer full, entry dropped")
+
+func (s *fifoSink) write(data []byte) error {
+	select {
+	case s.entries <- append([]byte(nil), data...):
+		return nil
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+		s.health.recordDropped()
+		return errFIFOSinkFull
+	}
+}
+
+// Dropped returns the number of entries dropped since the sink was
+// created.
+func (s *fifoSink) Dropped() uint64 {
+	return s.health.status(s.String(), 0).Dropped
+}
+
+func (s *fifoSink) backpressure() float64 {
+	return float64(len(s.entries)) / float64(cap(s.entries))
+}
+
+func (s *fifoSink) recordDropped() {
+	s.health.recordDropped()
+}
+
+func (s *fifoSink) status() SinkStatus {
+	return s.health.status(s.String(), len(s.entries))
+}
+
+// close stops the sink. Buffered entries are discarded.
+func (s *fifoSink) close() {
+	close(s.stopper)
+	<-s.done
+}
+
+func (s *fifoSink) run() {
+	defer close(s.done)
+	setProfilerLabels(profilerLabelSink)
+	var f *os.File
+	defer func() {
+		if f != nil {
+			_ = f.Close()
+		}
+	}()
+	var pending []byte
+	for {
+		if pending == nil {
+			select {
+			case pending = <-s.entries:
+			case <-s.stopper:
+				return
+			}
+		}
+		if f == nil {
+			var err error
+			if f, err = openFIFO(s.path); err != nil {
+				// No reader yet, or the path is not usable: keep buffering.
+				s.health.recordError(err)
+				f = nil
+				select {
+				case <-time.After(fifoSinkRetryInterval):
+					continue
+				case <-s.stopper:
+					return
+				}
+			}
+			s.health.setConnected(true)
+		}
+		if n := atomic.SwapUint64(&s.dropped, 0); n > 0 {
+			msg := fmt.Sprintf("log: %d entries dropped while the reader was stalled\n", n)
+			if _, err := f.WriteString(msg); err != nil {
+				atomic.AddUint64(&s.dropped, n)
+				s.health.recordError(err)
+				_ = f.Close()
+				f = nil
+				continue
+			}
+		}
+		if _, err := f.Write(pending); err != nil {
+			// The reader went away; reopen the FIFO and retry the entry.
+			s.health.recordError(err)
+			_ = f.Close()
+			f = nil
+			continue
+		}
+		s.health.recordSent(len(pending))
+		pending = nil
+	}
+}
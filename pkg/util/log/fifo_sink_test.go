@@ -0,0 +1,91 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !windows
+
+package log
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestFIFOSink(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	path := filepath.Join(s.logDir, "fifo")
+	if err := unix.Mkfifo(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	sink := newFIFOSink(path, 2)
+	defer sink.close()
+
+	// Without a reader, writes neither block nor fail, and overflowing
+	// entries are dropped.
+	for i := 0; i < 10; i++ {
+		sink.write([]byte("stalled\n"))
+	}
+	// The writer goroutine may hold one entry, and two are buffered.
+	if d := sink.Dropped(); d < 7 {
+		t.Errorf("expected at least 7 dropped entries, got %d", d)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		r := bufio.NewReader(f)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+	// Once the reader is there, it is told about the dropped entries
+	// and receives new ones. New entries may be dropped until the
+	// buffered ones are consumed, so keep writing.
+	var sawDropped bool
+	timeout := time.After(10 * time.Second)
+	for {
+		if sawDropped {
+			sink.write([]byte("resumed\n"))
+		}
+		select {
+		case line := <-lines:
+			if strings.Contains(line, "entries dropped while the reader was stalled") {
+				sawDropped = true
+			}
+			if line == "resumed\n" {
+				return
+			}
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("reader did not catch up (dropped entries reported: %t)", sawDropped)
+		}
+	}
+}
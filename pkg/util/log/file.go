@@ -115,11 +115,12 @@ func DirSet() bool { return logDir.isSet() }
 // and it splits the details of the filename into groups for easy parsing.
 // The log file format is {process}.{host}.{username}.{timestamp}.{pid}.log
 // cockroach.Brams-MacBook-Pro.bram.2015-06-09T16-10-48Z.30209.log
+// with an additional .gz extension once compressed.
 // All underscore in process, host and username are escaped to double
 // underscores and all periods are escaped to an underscore.
 // For compatibility with Windows filenames, all colons from the timestamp
 // (RFC3339) are converted from underscores.
-var logFileRE = regexp.MustCompile(`^(?:.*/)?([^/.]+)\.([^/\.]+)\.([^/\.]+)\.([^/\.]+)\.(\d+)\.log$`)
+var logFileRE = regexp.MustCompile(`^(?:.*/)?([^/.]+)\.([^/\.]+)\.([^/\.]+)\.([^/\.]+)\.(\d+)\.log(?:\.gz)?$`)
 
 var (
 	pid      = os.Getpid()
@@ -239,14 +240,14 @@ func create(
 		// Symlinks are best-effort.
 
 		if err := os.Remove(symlink); err != nil && !os.IsNotExist(err) {
-			fmt.Fprintf(OrigStderr, "log: failed to remove symlink %s: %s", symlink, err)
+			metaLogf("symlink", "failed to remove symlink %s: %s", symlink, err)
 		}
 		if err := os.Symlink(filepath.Base(fname), symlink); err != nil {
 			// On Windows, this will be the common case, as symlink creation
 			// requires special privileges.
 			// See: https://docs.microsoft.com/en-us/windows/device-security/security-policy-settings/create-symbolic-links
 			if runtime.GOOS != "windows" {
-				fmt.Fprintf(OrigStderr, "log: failed to create symlink %s: %s", symlink, err)
+				metaLogf("symlink", "failed to create symlink %s: %s", symlink, err)
 			}
 		}
 	}
@@ -256,13 +257,19 @@ func create(
 // ListLogFiles returns a slice of FileInfo structs for each log file
 // on the local node, in any of the configured log directories.
 func ListLogFiles() ([]FileInfo, error) {
-	var results []FileInfo
 	dir, err := logDir.get()
 	if err != nil {
 		// No log directory configured: simply indicate that there are no
 		// log files.
 		return nil, nil
 	}
+	return listLogFiles(dir)
+}
+
+// listLogFiles returns a slice of FileInfo structs for each log file in
+// dir.
+func listLogFiles(dir string) ([]FileInfo, error) {
+	var results []FileInfo
 	infos, err := ioutil.ReadDir(dir)
 	if err != nil {
 		return results, err
@@ -283,7 +290,8 @@ func ListLogFiles() ([]FileInfo, error) {
 	return results, nil
 }
 
-// GetLogReader returns a reader for the specified filename. In
+// GetLogReader returns a reader for the specified filename, which
+// decompresses the compressed log files. In
 // restricted mode, the filename must be the base name of a file in
 // this process's log directory (this is safe for cases when the
 // filename comes from external sources, such as the admin UI via
@@ -354,7 +362,7 @@ func GetLogReader(filename string, restricted bool) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	return os.Open(filename)
+	return openLogFile(filename)
 }
 
 // TODO(bram): remove when Go1.9 is required.
@@ -410,8 +418,9 @@ func FetchEntriesFromFiles(
 	selectedFiles := selectFiles(logFiles, endTimestamp)
 
 	entries := []Entry{}
+	var newer *fileSequence
 	for _, file := range selectedFiles {
-		newEntries, entryBeforeStart, err := readAllEntriesFromFile(
+		newEntries, entryBeforeStart, seq, err := readAllEntriesFromFile(
 			file,
 			startTimestamp,
 			endTimestamp,
@@ -420,6 +429,13 @@ func FetchEntriesFromFiles(
 		if err != nil {
 			return nil, err
 		}
+		if seq != nil && newer != nil {
+			if gap, ok := sequenceGap(*seq, *newer); ok &&
+				gap.Time >= startTimestamp && gap.Time <= endTimestamp {
+				entries = append(entries, gap)
+			}
+		}
+		newer = seq
 		entries = append(entries, newEntries...)
 		if len(entries) >= maxEntries {
 			break
@@ -439,28 +455,32 @@ func FetchEntriesFromFiles(
 // returns a flag that denotes if any timestamp occurred before the
 // 'startTimestamp' to inform the caller that no more log files need to be
 // processed. If the number of entries returned exceeds 'maxEntries' then
-// processing of new entries is stopped immediately.
+// processing of new entries is stopped immediately. Finally, it returns
+// the range of the sequence of entries of the process found in the file,
+// if the file records it and was read entirely.
 func readAllEntriesFromFile(
 	file FileInfo, startTimestamp, endTimestamp int64, maxEntries int, pattern *regexp.Regexp,
-) ([]Entry, bool, error) {
+) ([]Entry, bool, *fileSequence, error) {
 	reader, err := GetLogReader(file.Name, true /* restricted */)
 	if reader == nil || err != nil {
-		return nil, false, err
+		return nil, false, nil, err
 	}
 	defer reader.Close()
 	entries := []Entry{}
 	decoder := NewEntryDecoder(reader)
 	entryBeforeStart := false
+	complete := false
 	for {
 		entry := Entry{}
 		if err := decoder.Decode(&entry); err != nil {
 			if err == io.EOF {
+				complete = true
 				break
 			}
-			return nil, false, err
+			return nil, false, nil, err
 		}
 		var match bool
-		if pattern == nil {
+		if pattern == nil || IsGap(entry) {
 			match = true
 		} else {
 			match = pattern.MatchString(entry.Message) ||
@@ -477,5 +497,9 @@ func readAllEntriesFromFile(
 		}
 
 	}
-	return entries, entryBeforeStart, nil
+	var seq *fileSequence
+	if first, count, ok := decoder.sequence(); ok && complete {
+		seq = &fileSequence{pid: file.Details.PID, first: first, count: count, created: file.Details.Time}
+	}
+	return entries, entryBeforeStart, seq, nil
 }
@@ -0,0 +1,198 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// LogFileMaxAge is the age past which log files are deleted, unless
+// overridden by the log.file.max_age cluster setting. Zero means no age
+// limit. The most recent log file is always kept.
+var LogFileMaxAge time.Duration
+
+// logFileCompression, set by --log-file-compress, causes the log files
+// to be compressed once rotated, as the log.file.compression.enabled
+// cluster setting does.
+var logFileCompression bool
+
+// The retention settings trigger a GC when they change, so that a new
+// policy takes effect on the whole cluster without waiting for the next
+// rotation of the log files.
+var (
+	fileMaxAge = settings.RegisterDurationSetting(
+		"log.file.max_age",
+		"if non-zero, delete log files older than this, overriding --log-file-max-age",
+		0,
+	).OnChange(notifyGC)
+	fileCompression = settings.RegisterBoolSetting(
+		"log.file.compression.enabled",
+		"gzip log files once rotated, as done when --log-file-compress is set",
+		false,
+	).OnChange(notifyGC)
+)
+
+// gcInterval is the interval at which the log files are garbage
+// collected when they are not rotated, so that the age limit is enforced
+// on idle processes.
+const gcInterval = time.Hour
+
+// compressedSuffix is the extension appended to the name of compressed
+// log files.
+const compressedSuffix = ".gz"
+
+// effectiveFileMaxAge returns the age past which log files are deleted,
+// or zero if there is no age limit.
+func effectiveFileMaxAge() time.Duration {
+	if d := fileMaxAge.Get(); d > 0 {
+		return d
+	}
+	return LogFileMaxAge
+}
+
+// fileCompressionEnabled returns true if the rotated log files are to be
+// compressed.
+func fileCompressionEnabled() bool {
+	return logFileCompression || fileCompression.Get()
+}
+
+// notifyGC wakes up the GC daemon.
+func notifyGC() {
+	select {
+	case logging.gcNotify <- struct{}{}:
+	default:
+	}
+}
+
+// compressOldFiles compresses the rotated log files if compression is
+// enabled. The most recent file of each process is left alone, since the
+// process may still be writing to it. Unlike gcOldFiles, it does not
+// require l.mu to be held, so logging is not blocked during compression.
+func (l *loggingT) compressOldFiles() {
+	if !fileCompressionEnabled() {
+		return
+	}
+	dir, err := logDir.get()
+	if err != nil {
+		// No log directory configured. Nothing to do.
+		return
+	}
+	allFiles, err := ListLogFiles()
+	if err != nil {
+		metaLogf("gc", "unable to compress log files: %s", err)
+		return
+	}
+	newest := make(map[int64]int64)
+	for _, f := range allFiles {
+		if f.Details.Time > newest[f.Details.PID] {
+			newest[f.Details.PID] = f.Details.Time
+		}
+	}
+	for _, f := range allFiles {
+		if strings.HasSuffix(f.Name, compressedSuffix) || f.Details.Time == newest[f.Details.PID] {
+			continue
+		}
+		if err := compressLogFile(filepath.Join(dir, f.Name)); err != nil {
+			metaLogf("gc", "unable to compress log file: %s", err)
+		}
+	}
+}
+
+// compressLogFile replaces the log file at path by its gzipped version,
+// which retains its modification time so that the age limit applies to
+// it as if it were not compressed.
+func compressLogFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a crash never leaves a
+	// truncated file behind.
+	tmp := path + compressedSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+	w := gzip.NewWriter(dst)
+	_, err = io.Copy(w, src)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if syncErr := dst.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path+compressedSuffix); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// gzipFileReader reads a compressed log file.
+type gzipFileReader struct {
+	*gzip.Reader
+	f *os.File
+}
+
+// Close closes the decompressor and the underlying file.
+func (r gzipFileReader) Close() error {
+	err := r.Reader.Close()
+	if closeErr := r.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// openLogFile opens the log file at path, decompressing it if needed.
+func openLogFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, compressedSuffix) {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return gzipFileReader{Reader: gz, f: f}, nil
+}
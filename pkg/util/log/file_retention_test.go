@@ -0,0 +1,185 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// createRotatedLogFiles writes n entries to the log files, rotating them
+// on every write, and returns the resulting log files.
+func createRotatedLogFiles(t *testing.T, n int) []FileInfo {
+	logging.mu.Lock()
+	logging.disableDaemons = true
+	logging.mu.Unlock()
+	setFlags()
+	logging.noStderrRedirect = true
+
+	defer func(previous int64) { LogFileMaxSize = previous }(LogFileMaxSize)
+	LogFileMaxSize = 1 // ensure rotation on every log write
+	for i := 0; i < n; i++ {
+		Infof(context.Background(), "retention entry %d", i)
+		Flush()
+	}
+	files, err := ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != n {
+		t.Fatalf("expected %d files, but found %d", n, len(files))
+	}
+	return files
+}
+
+func TestCompressOldFiles(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	defer func(previous bool) {
+		logging.mu.Lock()
+		logging.disableDaemons = previous
+		logging.mu.Unlock()
+	}(logging.disableDaemons)
+
+	const numFiles = 4
+	before := createRotatedLogFiles(t, numFiles)
+
+	// Compression is disabled by default.
+	logging.compressOldFiles()
+	for _, f := range mustListLogFiles(t) {
+		if strings.HasSuffix(f.Name, compressedSuffix) {
+			t.Fatalf("unexpected compressed file %s", f.Name)
+		}
+	}
+
+	defer settings.TestingSetBool(&fileCompression, true)()
+	logging.compressOldFiles()
+
+	after := mustListLogFiles(t)
+	if len(after) != numFiles {
+		t.Fatalf("expected %d files, but found %d", numFiles, len(after))
+	}
+	compressed := 0
+	for _, f := range after {
+		if !strings.HasSuffix(f.Name, compressedSuffix) {
+			continue
+		}
+		compressed++
+		// Compressed files retain the modification time of the originals,
+		// and read back the same through GetLogReader.
+		var orig FileInfo
+		for _, b := range before {
+			if b.Name+compressedSuffix == f.Name {
+				orig = b
+			}
+		}
+		if orig.Name == "" {
+			t.Fatalf("compressed file %s has no original", f.Name)
+		}
+		if f.ModTimeNanos != orig.ModTimeNanos {
+			t.Errorf("%s: expected modification time %d, got %d", f.Name, orig.ModTimeNanos, f.ModTimeNanos)
+		}
+		reader, err := GetLogReader(f.Name, true /* restricted */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found bool
+		d := NewEntryDecoder(reader)
+		for {
+			var e Entry
+			if err := d.Decode(&e); err != nil {
+				break
+			}
+			found = found || strings.HasPrefix(e.Message, "retention entry")
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !found {
+			t.Errorf("%s: entry not found after decompression", f.Name)
+		}
+	}
+	// The file being written to is never compressed.
+	if e := numFiles - 1; compressed != e {
+		t.Fatalf("expected %d compressed files, but found %d", e, compressed)
+	}
+}
+
+func TestGCMaxAge(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	defer func(previous bool) {
+		logging.mu.Lock()
+		logging.disableDaemons = previous
+		logging.mu.Unlock()
+	}(logging.disableDaemons)
+
+	const numFiles = 4
+	files := createRotatedLogFiles(t, numFiles)
+	dir, err := logDir.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Age all the files but the second newest, including the newest one,
+	// which is kept regardless of its age.
+	sorted := selectFiles(files, math.MaxInt64)
+	old := time.Now().Add(-2 * time.Hour)
+	for _, f := range append(sorted[:1:1], sorted[2:]...) {
+		if err := os.Chtimes(filepath.Join(dir, f.Name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without an age limit, nothing is removed.
+	logging.gcOldFiles()
+	if a := len(mustListLogFiles(t)); a != numFiles {
+		t.Fatalf("expected %d files, but found %d", numFiles, a)
+	}
+
+	defer func(previous time.Duration) { LogFileMaxAge = previous }(LogFileMaxAge)
+	LogFileMaxAge = time.Hour
+	logging.gcOldFiles()
+	after := mustListLogFiles(t)
+	if len(after) != 2 {
+		t.Fatalf("expected 2 files, but found %+v", after)
+	}
+	for _, f := range after {
+		if f.Name != sorted[0].Name && f.Name != sorted[1].Name {
+			t.Errorf("unexpected file %s left after GC", f.Name)
+		}
+	}
+
+	// The cluster setting overrides the flag.
+	defer settings.TestingSetDuration(&fileMaxAge, 24*time.Hour)()
+	if a := effectiveFileMaxAge(); a != 24*time.Hour {
+		t.Errorf("expected the setting to take precedence, got %s", a)
+	}
+}
+
+func mustListLogFiles(t *testing.T) []FileInfo {
+	files, err := ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
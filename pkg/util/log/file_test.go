@@ -34,12 +34,14 @@ func TestLogFilenameParsing(t *testing.T) {
 
 	for i, testCase := range testCases {
 		filename, _ := logName(testCase)
-		details, err := parseLogFilename(filename)
-		if err != nil {
-			t.Fatal(err)
-		}
-		if a, e := time.Unix(0, details.Time).Format(time.RFC3339), testCase.Format(time.RFC3339); a != e {
-			t.Errorf("%d: Times do not match, expected:%s - actual:%s", i, e, a)
+		for _, name := range []string{filename, filename + compressedSuffix} {
+			details, err := parseLogFilename(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a, e := time.Unix(0, details.Time).Format(time.RFC3339), testCase.Format(time.RFC3339); a != e {
+				t.Errorf("%d: Times do not match, expected:%s - actual:%s", i, e, a)
+			}
 		}
 	}
 }
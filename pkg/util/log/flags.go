@@ -19,6 +19,7 @@ package log
 import (
 	"flag"
 
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
 )
 
@@ -29,10 +30,80 @@ func init() {
 		&logging.vmodule, &logging.traceLocation,
 		&LogFileMaxSize, &LogFilesCombinedMaxSize,
 	)
+	flag.DurationVar(&LogFileMaxAge,
+		logflags.LogFileMaxAgeName, 0, "if non-zero, remove log files older than this")
+	flag.BoolVar(&logFileCompression,
+		logflags.LogFileCompressName, false, "gzip log files once rotated")
+	flag.Var(humanizeutil.NewBytesValue(&AuditLogFileMaxSize),
+		logflags.LogAuditFileMaxSizeName, "maximum size of each audit log file")
+	flag.Var(humanizeutil.NewBytesValue(&AuditLogFilesCombinedMaxSize),
+		logflags.LogAuditDirMaxSizeName, "if non-zero, maximum combined size of the audit log files, past which the oldest are removed")
+	flag.Var(humanizeutil.NewBytesValue(&SlowQueryLogFileMaxSize),
+		logflags.LogSlowQueryFileMaxSizeName, "maximum size of each slow query log file")
+	flag.Var(humanizeutil.NewBytesValue(&SlowQueryLogFilesCombinedMaxSize),
+		logflags.LogSlowQueryDirMaxSizeName, "maximum combined size of the slow query log files")
+	flag.Var(&logProfile,
+		logflags.LogProfileName, "preset logging configuration (production, development, debug-incident, minimal); flags given after it override its parameters")
 	// We define these flags here because they have the type Severity
 	// which we can't pass to logflags without creating an import cycle.
 	flag.Var(&logging.stderrThreshold,
 		logflags.LogToStderrName, "logs at or above this threshold go to stderr")
 	flag.Var(&logging.fileThreshold,
 		logflags.LogFileVerbosityThresholdName, "minimum verbosity of messages written to the log file")
+	flag.Var(&logging.fileFlushThreshold,
+		logflags.LogFileFlushThresholdName, "messages at or above this threshold are flushed to the log file immediately")
+	flag.Var(&logging.stderrFormat,
+		logflags.LogStderrFormatName, "format of the messages written to stderr (crdb-v1, crdb-v1-tty, json)")
+	flag.Var(&logging.fileFormat,
+		logflags.LogFileFormatName, "format of the messages written to the log file (crdb-v1, crdb-v1-tty, crdb-v1-interned, json)")
+	flag.Var(formatsFlag{},
+		logflags.LogFormatName, "format of the messages written to both stderr and the log file (crdb-v1, crdb-v1-tty, json); flags given after it override it")
+	flag.BoolVar(&logging.stderrRedact,
+		logflags.LogStderrRedactName, false, "redact potentially sensitive data from the messages written to stderr")
+	flag.BoolVar(&logging.fileRedact,
+		logflags.LogFileRedactName, false, "redact potentially sensitive data from the messages written to the log file")
+	flag.StringVar(&fifoSinkPath,
+		logflags.LogFIFOName, "", "if non-empty, also write log messages to the named pipe at this path")
+	flag.Var(&fifoSinkThreshold,
+		logflags.LogFIFOVerbosityThresholdName, "minimum verbosity of messages written to the named pipe")
+	flag.StringVar(&fifoSinkFailover,
+		logflags.LogFIFOFailoverName, "", "comma-separated list of sinks (spill, stderr) to write to, in order, when the named pipe fails")
+	flag.StringVar(&teeSinkSpec,
+		logflags.LogTeeName, "", "comma-separated list of destinations (stderr, spill, file:PATH, fifo:PATH) to each of which log messages are also written, independently of the others")
+	flag.Var(&teeSinkThreshold,
+		logflags.LogTeeVerbosityThresholdName, "minimum verbosity of messages written to the tee destinations")
+	flag.StringVar(&netSinkAddr,
+		logflags.LogNetAddrName, "", "if non-empty, also send log messages to the TCP collector at this address")
+	flag.Var(&netSinkThreshold,
+		logflags.LogNetVerbosityThresholdName, "minimum verbosity of messages sent to the TCP collector")
+	flag.Var(humanizeutil.NewBytesValue(&netSinkSpoolMaxSize),
+		logflags.LogNetSpoolMaxSizeName, "if non-zero, spool messages for the TCP collector in the log directory, up to this size")
+	flag.Var(&netSinkOpts.compression,
+		logflags.LogNetCompressionName, "compression of the stream of messages sent to the TCP collector (none, gzip)")
+	flag.BoolVar(&netSinkTLS,
+		logflags.LogNetTLSName, false, "use TLS to connect to the TCP collector")
+	flag.StringVar(&sinkTLS.caFile,
+		logflags.LogNetTLSCAName, "", "certificate authority used to verify the log collectors reached over TLS (system roots if empty)")
+	flag.StringVar(&sinkTLS.certFile,
+		logflags.LogNetTLSCertName, "", "client certificate presented to the log collectors reached over TLS")
+	flag.StringVar(&sinkTLS.keyFile,
+		logflags.LogNetTLSKeyName, "", "key of the client certificate presented to the log collectors reached over TLS")
+	flag.StringVar(&sinkTLS.serverName,
+		logflags.LogNetTLSServerNameName, "", "name verified in the certificates of the log collectors reached over TLS (host of their address if empty)")
+	flag.StringVar(&sinkTLS.minVersion,
+		logflags.LogNetTLSMinVersionName, "", "minimum TLS version used to connect to the log collectors (1.0, 1.1 or 1.2; default 1.2)")
+	flag.StringVar(&syslogSinkSpec,
+		logflags.LogSyslogName, "", "if non-empty, also write log messages to syslog: local for the local daemon, or udp://ADDR, tcp://ADDR or tls://ADDR")
+	flag.StringVar(&syslogSinkTag,
+		logflags.LogSyslogTagName, "", "tag of the messages written to syslog (the name of the program if empty)")
+	flag.Var(&syslogSinkThreshold,
+		logflags.LogSyslogVerbosityName, "minimum verbosity of messages written to syslog")
+	flag.StringVar(&fluentSinkAddr,
+		logflags.LogFluentAddrName, "", "if non-empty, also send log messages to the fluentd collector at this address, tcp://ADDR or tls://ADDR for the forward protocol or udp://ADDR for one datagram per message")
+	flag.StringVar(&fluentSinkTag,
+		logflags.LogFluentTagName, "", "tag of the messages sent to the fluentd collector (the name of the program if empty)")
+	flag.Var(&fluentSinkThreshold,
+		logflags.LogFluentVerbosityName, "minimum verbosity of messages sent to the fluentd collector")
+	flag.DurationVar(&FatalDrainTimeout, logflags.LogFatalDrainTimeoutName, FatalDrainTimeout,
+		"maximum time spent flushing logs and crash reports before exiting on a fatal error")
 }
@@ -0,0 +1,203 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+)
+
+// makeFluentSink returns a sink sending entries to the fluentd collector
+// at addr, tagged with tag. Over TCP, the default, entries are sent with
+// the forward protocol, through a network sink which queues them and
+// reconnects as needed; over tls, the connection uses TLS with tlsOpts.
+// The forward protocol only uses UDP for heartbeats: over UDP, each entry
+// is sent as a datagram holding its record, for the udp input of fluentd
+// with the msgpack parser.
+func makeFluentSink(addr, tag string, tlsOpts *sinkTLSOptions) (logSink, error) {
+	network, hostPort, err := splitSinkAddr(addr, "tcp")
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		return nil, err
+	}
+	if network == "udp" {
+		return newBufferedSink("fluent:udp:"+hostPort, &fluentUDPSink{addr: hostPort}, entrySinkBufferSize), nil
+	}
+	var opts netSinkOptions
+	if network == "tls" {
+		opts.tls = tlsOpts
+	}
+	return &fluentSink{
+		tag: tag,
+		net: newNetSink(hostPort, opts, newMemQueue(netSinkBufferSize)),
+	}, nil
+}
+
+// fluentSink sends entries to a fluentd collector with the forward
+// protocol, in message mode: each entry is a MessagePack array of the
+// tag, the time and the record of the entry.
+type fluentSink struct {
+	tag string
+	net *netSink
+}
+
+func (s *fluentSink) String() string {
+	return "fluent:" + s.net.String()
+}
+
+func (s *fluentSink) writeEntry(entry Entry) error {
+	b := appendMsgpackArrayHeader(nil, 3)
+	b = appendMsgpackString(b, s.tag)
+	b = appendMsgpackEventTime(b, entry.Time)
+	return s.net.write(appendFluentRecord(b, entry))
+}
+
+func (s *fluentSink) write(data []byte) error {
+	var entry Entry
+	if err := decodeJSONEntry(data, &entry); err != nil {
+		return err
+	}
+	return s.writeEntry(entry)
+}
+
+func (s *fluentSink) backpressure() float64 {
+	return s.net.backpressure()
+}
+
+func (s *fluentSink) recordDropped() {
+	s.net.recordDropped()
+}
+
+func (s *fluentSink) status() SinkStatus {
+	st := s.net.status()
+	st.Name = s.String()
+	return st
+}
+
+// close stops the sink. Entries not yet delivered are discarded.
+func (s *fluentSink) close() {
+	s.net.close()
+}
+
+// fluentUDPSink sends the record of each entry in a datagram to a
+// fluentd collector. It is wrapped in a buffered sink.
+type fluentUDPSink struct {
+	addr string
+	conn net.Conn
+}
+
+// Output is part of the Sink interface.
+func (s *fluentUDPSink) Output(entry Entry) error {
+	if s.conn == nil {
+		conn, err := dialUDP(s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	_, err := s.conn.Write(appendFluentRecord(nil, entry))
+	return err
+}
+
+// appendFluentRecord appends the MessagePack encoding of the record of an
+// entry, a map whose fields are those of the json format.
+func appendFluentRecord(b []byte, entry Entry) []byte {
+	tags, msg := splitTags(strings.TrimSuffix(entry.Message, "\n"))
+	n := 5
+	if tags != "" {
+		n++
+	}
+	b = appendMsgpackMapHeader(b, n)
+	b = appendMsgpackString(b, "severity")
+	b = appendMsgpackString(b, entry.Severity.String())
+	b = appendMsgpackString(b, "goroutine")
+	b = appendMsgpackInt(b, entry.Goroutine)
+	b = appendMsgpackString(b, "file")
+	b = appendMsgpackString(b, entry.File)
+	b = appendMsgpackString(b, "line")
+	b = appendMsgpackInt(b, entry.Line)
+	if tags != "" {
+		b = appendMsgpackString(b, "tags")
+		b = appendMsgpackString(b, tags)
+	}
+	b = appendMsgpackString(b, "message")
+	return appendMsgpackString(b, msg)
+}
+
+// The functions below append the MessagePack encodings of the few types
+// of values the fluentd sink sends.
+
+func appendMsgpackArrayHeader(b []byte, n int) []byte {
+	return appendMsgpackHeader(b, n, 0x90, 0xdc, 0xdd)
+}
+
+func appendMsgpackMapHeader(b []byte, n int) []byte {
+	return appendMsgpackHeader(b, n, 0x80, 0xde, 0xdf)
+}
+
+// appendMsgpackHeader appends the header of an array or map of n
+// elements, whose fixed, 16-bit and 32-bit forms start with the given
+// bytes.
+func appendMsgpackHeader(b []byte, n int, fix, b16, b32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n < 1<<16:
+		return append(b, b16, byte(n>>8), byte(n))
+	default:
+		return appendUint32(append(b, b32), uint32(n))
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n < 1<<8:
+		b = append(b, 0xd9, byte(n))
+	case n < 1<<16:
+		b = append(b, 0xda, byte(n>>8), byte(n))
+	default:
+		b = appendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	if i >= -32 && i < 128 {
+		return append(b, byte(i))
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(i))
+	return append(append(b, 0xd3), buf[:]...)
+}
+
+// appendMsgpackEventTime appends the EventTime of the forward protocol,
+// an extension of type 0 holding the seconds and nanoseconds of the
+// time, given in nanoseconds since the epoch.
+func appendMsgpackEventTime(b []byte, nanos int64) []byte {
+	b = append(b, 0xd7, 0x00)
+	b = appendUint32(b, uint32(nanos/1e9))
+	return appendUint32(b, uint32(nanos%1e9))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
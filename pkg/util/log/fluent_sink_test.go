@@ -0,0 +1,126 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !nophonehome
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgpackEncoding(t *testing.T) {
+	testCases := []struct {
+		b        []byte
+		expected []byte
+	}{
+		{appendMsgpackString(nil, "a"), []byte{0xa1, 'a'}},
+		{appendMsgpackString(nil, strings.Repeat("x", 40))[:2], []byte{0xd9, 40}},
+		{appendMsgpackString(nil, strings.Repeat("x", 300))[:3], []byte{0xda, 0x01, 0x2c}},
+		{appendMsgpackInt(nil, 7), []byte{0x07}},
+		{appendMsgpackInt(nil, -1), []byte{0xff}},
+		{appendMsgpackInt(nil, 300), []byte{0xd3, 0, 0, 0, 0, 0, 0, 0x01, 0x2c}},
+		{appendMsgpackArrayHeader(nil, 3), []byte{0x93}},
+		{appendMsgpackArrayHeader(nil, 20), []byte{0xdc, 0, 20}},
+		{appendMsgpackMapHeader(nil, 5), []byte{0x85}},
+		{appendMsgpackEventTime(nil, 2e9+3), []byte{0xd7, 0, 0, 0, 0, 2, 0, 0, 0, 3}},
+	}
+	for i, tc := range testCases {
+		if !bytes.Equal(tc.b, tc.expected) {
+			t.Errorf("%d: expected %x, got %x", i, tc.expected, tc.b)
+		}
+	}
+}
+
+var fluentTestEntry = Entry{Severity: Severity_WARNING, Time: 5e9 + 1, Goroutine: 2, File: "f.go", Line: 9, Message: "[n1] hello"}
+
+func TestFluentSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	sink, err := makeFluentSink("tcp://"+ln.Addr().String(), "crdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.(*fluentSink).close()
+	if err := sink.(entryWriter).writeEntry(fluentTestEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ln.(*net.TCPListener).SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{0x93, 0xa4, 'c', 'r', 'd', 'b', 0xd7, 0, 0, 0, 0, 5, 0, 0, 0, 1}
+	expected = appendFluentRecord(expected, fluentTestEntry)
+	b := make([]byte, len(expected))
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+}
+
+func TestFluentSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	sink, err := makeFluentSink("udp://"+pc.LocalAddr().String(), "crdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.(*bufferedSink).close()
+	if err := sink.(entryWriter).writeEntry(fluentTestEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pc.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The datagram holds the record alone: the tag and the time are up to
+	// the udp input of the collector.
+	if expected := appendFluentRecord(nil, fluentTestEntry); !bytes.Equal(b[:n], expected) {
+		t.Errorf("expected %x, got %x", expected, b[:n])
+	}
+}
+
+func TestMakeFluentSinkErrors(t *testing.T) {
+	for _, addr := range []string{"sctp://127.0.0.1:1", "tcp://", "udp://nohostport"} {
+		if _, err := makeFluentSink(addr, "crdb", nil); err == nil {
+			t.Errorf("%s: expected an error", addr)
+		}
+	}
+}
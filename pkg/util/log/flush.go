@@ -0,0 +1,171 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// sinkFlushTimeout bounds the time given by FlushAll to each destination
+// of log entries, unless the context of the flush has an earlier
+// deadline.
+var sinkFlushTimeout = 5 * time.Second
+
+// flushPollInterval is the interval at which FlushAll checks whether the
+// sinks have delivered their backlog.
+const flushPollInterval = 10 * time.Millisecond
+
+// FlushResult describes the flush of one of the destinations of log
+// entries.
+type FlushResult struct {
+	Name string `json:"name"`
+	// Pending is the number of entries, or crash reports, which were not
+	// delivered yet when the flush ended.
+	Pending  int           `json:"pending"`
+	Duration time.Duration `json:"duration"`
+	// Error is set if the destination could not be flushed before its
+	// deadline.
+	Error string `json:"error,omitempty"`
+}
+
+// flushTask flushes a destination, giving up at the deadline. It returns
+// the number of entries not delivered yet.
+type flushTask struct {
+	name  string
+	flush func(deadline time.Time) (pending int, err error)
+}
+
+// FlushAll flushes and syncs the log file, and waits for the sinks to
+// deliver the entries they buffer and for the queued crash reports to be
+// handed over to the crash reporter. The destinations are flushed
+// concurrently, each until the deadline of ctx and for at most
+// sinkFlushTimeout. It returns the result for the log file, the sinks in
+// the order in which they were configured, and the crash reports; the
+// destinations which could not be flushed are also reported on stderr.
+// Unlike Flush, it may block for as long as the deadlines allow.
+func FlushAll(ctx context.Context) []FlushResult {
+	start := time.Now()
+	deadline := start.Add(sinkFlushTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	tasks := []flushTask{{name: "file", flush: func(time.Time) (int, error) {
+		logging.mu.Lock()
+		defer logging.mu.Unlock()
+		return 0, logging.flushFile()
+	}}}
+	logging.mu.Lock()
+	for _, c := range logging.sinks {
+		sink := c.sink
+		tasks = append(tasks, flushTask{name: sink.String(), flush: func(deadline time.Time) (int, error) {
+			return waitForBacklog(deadline, func() int {
+				logging.mu.Lock()
+				defer logging.mu.Unlock()
+				var n int
+				for _, st := range sinkStatuses(sink) {
+					n += st.Backlog
+				}
+				return n
+			})
+		}})
+	}
+	logging.mu.Unlock()
+	tasks = append(tasks, flushTask{name: "crash-reports", flush: func(deadline time.Time) (int, error) {
+		// The windows of the reports admitted so far are stored first, in
+		// case the process is about to exit.
+		saveErr := saveCrashReportDedup()
+		pending, err := waitForBacklog(deadline, func() int { return len(crashReportQueue) })
+		if err == nil {
+			err = saveErr
+		}
+		return pending, err
+	}})
+
+	type indexedResult struct {
+		i int
+		r FlushResult
+	}
+	ch := make(chan indexedResult, len(tasks))
+	for i, t := range tasks {
+		go func(i int, t flushTask) {
+			setProfilerLabels(profilerLabelFlush)
+			pending, err := t.flush(deadline)
+			r := FlushResult{Name: t.name, Pending: pending, Duration: time.Since(start)}
+			if err != nil {
+				r.Error = err.Error()
+			}
+			ch <- indexedResult{i, r}
+		}(i, t)
+	}
+
+	results := make([]FlushResult, len(tasks))
+	done := make([]bool, len(tasks))
+	// The tasks give up at the deadline on their own, except the flush of
+	// the log file, which may be stuck in a write.
+	timer := time.NewTimer(time.Until(deadline) + flushPollInterval)
+	defer timer.Stop()
+wait:
+	for n := 0; n < len(tasks); n++ {
+		select {
+		case ir := <-ch:
+			results[ir.i], done[ir.i] = ir.r, true
+		case <-timer.C:
+			break wait
+		case <-ctx.Done():
+			break wait
+		}
+	}
+	for i, t := range tasks {
+		if !done[i] {
+			results[i] = FlushResult{Name: t.name, Duration: time.Since(start), Error: "flush did not complete in time"}
+		}
+		if r := results[i]; r.Error != "" {
+			metaLogf("flush:"+r.Name, "unable to flush %s: %s", r.Name, r.Error)
+		}
+	}
+	return results
+}
+
+// waitForBacklog waits until backlog returns zero, giving up at the
+// deadline. It returns the last backlog.
+func waitForBacklog(deadline time.Time, backlog func() int) (int, error) {
+	for {
+		n := backlog()
+		if n == 0 {
+			return 0, nil
+		}
+		if !time.Now().Before(deadline) {
+			return n, fmt.Errorf("%d entries not delivered in time", n)
+		}
+		time.Sleep(flushPollInterval)
+	}
+}
+
+const httpFlushPath = "/debug/logs/flush"
+
+// handleFlush flushes all the destinations of log entries and serves the
+// results as JSON.
+func handleFlush(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(FlushAll(r.Context())); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestFlushAll(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	var health sinkHealth
+	stuck := &testStatusSink{testSink: testSink{name: "stuck"}, health: &health}
+	logging.mu.Lock()
+	oldSinks := logging.sinks
+	logging.sinks = []sinkConfig{{sink: &testSink{name: "idle"}}, {sink: stuck}}
+	logging.mu.Unlock()
+	defer func() {
+		logging.mu.Lock()
+		logging.sinks = oldSinks
+		logging.mu.Unlock()
+	}()
+
+	Info(context.Background(), "flushed")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results := FlushAll(ctx)
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	if expected := []string{"file", "idle", "stuck", "crash-reports"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected results for %v, got %+v", expected, results)
+	}
+	for _, r := range []FlushResult{results[0], results[1], results[3]} {
+		if r.Error != "" || r.Pending != 0 {
+			t.Errorf("expected %s to be flushed, got %+v", r.Name, r)
+		}
+	}
+	// The stuck sink always reports a backlog of 3 entries.
+	if r := results[2]; r.Error == "" || r.Pending != 3 {
+		t.Errorf("expected the stuck sink to time out, got %+v", r)
+	}
+	if d := results[2].Duration; d < 50*time.Millisecond || d > sinkFlushTimeout {
+		t.Errorf("expected the deadline of the context to apply, took %s", d)
+	}
+}
+
+func TestHandleFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleFlush(w, httptest.NewRequest("GET", httpFlushPath, nil))
+	var results []FlushResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("%s: %s", err, w.Body.String())
+	}
+	if len(results) == 0 || results[0].Name != "file" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// outputFormat identifies the format in which log entries are written to
+// one of the outputs (standard error or the log file).
+type outputFormat int32
+
+const (
+	// formatCrdbV1 is the human-readable format where each entry is
+	// prefixed by a header indicating its severity, time, goroutine and
+	// origin.
+	formatCrdbV1 outputFormat = iota
+	// formatCrdbV1TTY is formatCrdbV1 with terminal colors. On standard
+	// error, colors are only used if the terminal supports them.
+	formatCrdbV1TTY
+	// formatCrdbV1Interned is formatCrdbV1 where, in the log files, the
+	// messages whose format strings are repeated are written as the ID of
+	// their template followed by their arguments encoded in JSON, and the
+	// templates are defined in the header of the files, which shrinks
+	// high-volume logs. On the other outputs, it is formatCrdbV1.
+	formatCrdbV1Interned
+	// formatJSON writes each entry as a JSON object on a single line, for
+	// ingestion by log processing pipelines.
+	formatJSON
+)
+
+var outputFormatNames = map[outputFormat]string{
+	formatCrdbV1:         "crdb-v1",
+	formatCrdbV1TTY:      "crdb-v1-tty",
+	formatCrdbV1Interned: "crdb-v1-interned",
+	formatJSON:           "json",
+}
+
+// String is part of the flag.Value interface.
+func (f *outputFormat) String() string {
+	return outputFormatNames[*f]
+}
+
+// Set is part of the flag.Value interface.
+func (f *outputFormat) Set(value string) error {
+	for k, name := range outputFormatNames {
+		if name == value {
+			*f = k
+			return nil
+		}
+	}
+	var names []string
+	for _, name := range outputFormatNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown log format %q, expected one of: %s", value, strings.Join(names, ", "))
+}
+
+// colors returns the color profile to use when writing entries in format
+// f to an output, given the color profile supported by the output.
+func (f outputFormat) colors(supported *colorProfile) *colorProfile {
+	if f == formatCrdbV1TTY {
+		return supported
+	}
+	return nil
+}
+
+// formatEntry formats an entry in format f for an output supporting the
+// given color profile.
+func (f outputFormat) formatEntry(entry Entry, stacks []byte, supported *colorProfile) *buffer {
+	if f == formatJSON {
+		return formatJSONEntry(entry, stacks)
+	}
+	return formatLogEntry(entry, stacks, f.colors(supported))
+}
+
+// formatsFlag is the flag.Value which sets the format of both stderr and
+// the log files.
+type formatsFlag struct{}
+
+// String is part of the flag.Value interface.
+func (formatsFlag) String() string {
+	return ""
+}
+
+// Set is part of the flag.Value interface.
+func (formatsFlag) Set(value string) error {
+	var f outputFormat
+	if err := f.Set(value); err != nil {
+		return err
+	}
+	logging.stderrFormat, logging.fileFormat = f, f
+	return nil
+}
+
+// SetFormat changes the format of the entries written to stderr and to
+// the log files, for instance to "json". The change is recorded on the
+// ops channel as originating from ctx.
+func SetFormat(ctx context.Context, name string) error {
+	var f outputFormat
+	if err := f.Set(name); err != nil {
+		return err
+	}
+	logging.mu.Lock()
+	oldValue := fmt.Sprintf("stderr: %s, file: %s", logging.stderrFormat.String(), logging.fileFormat.String())
+	logging.stderrFormat, logging.fileFormat = f, f
+	logging.mu.Unlock()
+	recordConfigChange(ctx, "format", oldValue, fmt.Sprintf("stderr: %s, file: %s", name, name))
+	return nil
+}
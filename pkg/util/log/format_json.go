@@ -0,0 +1,99 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// jsonEntry is the encoding of an entry in the json format. The log tags
+// which prefix the message of the entry are split from it.
+type jsonEntry struct {
+	Severity    string `json:"severity"`
+	Time        string `json:"time"`
+	Goroutine   int64  `json:"goroutine,omitempty"`
+	File        string `json:"file"`
+	Line        int64  `json:"line"`
+	Tags        string `json:"tags,omitempty"`
+	Message     string `json:"message"`
+	Sensitivity string `json:"sensitivity,omitempty"`
+	Stacks      string `json:"stacks,omitempty"`
+}
+
+// splitTags splits the log tags which prefix msg, as formatted by
+// formatTags, from the rest of the message. It returns the tags without
+// their brackets.
+func splitTags(msg string) (tags, rest string) {
+	if !strings.HasPrefix(msg, "[") {
+		return "", msg
+	}
+	i := strings.Index(msg, "] ")
+	if i < 0 {
+		return "", msg
+	}
+	return msg[1:i], msg[i+2:]
+}
+
+// formatJSONEntry formats an entry in the json format: a JSON object on
+// a single line.
+func formatJSONEntry(entry Entry, stacks []byte) *buffer {
+	e := jsonEntry{
+		Severity:  entry.Severity.String(),
+		Time:      time.Unix(0, entry.Time).UTC().Format(time.RFC3339Nano),
+		Goroutine: entry.Goroutine,
+		File:      entry.File,
+		Line:      entry.Line,
+		Stacks:    string(stacks),
+	}
+	// As in the crdb-v1 format, the entry ends with the line.
+	e.Tags, e.Message = splitTags(strings.TrimSuffix(entry.Message, "\n"))
+	if entry.Sensitivity != Sensitivity_UNCLASSIFIED {
+		e.Sensitivity = entry.Sensitivity.String()
+	}
+	buf := logging.getBuffer()
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// The encoding of strings can't fail, and terminates the object with a
+	// newline.
+	_ = enc.Encode(e)
+	return buf
+}
+
+// decodeJSONEntry decodes an entry formatted by formatJSONEntry.
+func decodeJSONEntry(data []byte, entry *Entry) error {
+	var e jsonEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	t, err := time.Parse(time.RFC3339Nano, e.Time)
+	if err != nil {
+		return err
+	}
+	*entry = Entry{
+		Severity:    Severity(Severity_value[e.Severity]),
+		Time:        t.UnixNano(),
+		Goroutine:   e.Goroutine,
+		File:        e.File,
+		Line:        e.Line,
+		Message:     e.Message,
+		Sensitivity: Sensitivity(Sensitivity_value[e.Sensitivity]),
+	}
+	if e.Tags != "" {
+		entry.Message = "[" + e.Tags + "] " + e.Message
+	}
+	return nil
+}
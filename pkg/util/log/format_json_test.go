@@ -0,0 +1,127 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestJSONEntryRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Severity: Severity_INFO, Time: 1500000000123456789, Goroutine: 7, File: "a.go", Line: 12, Message: "plain"},
+		{Severity: Severity_WARNING, Time: 1, File: "b.go", Line: 1, Message: "[n1,client=1.2.3.4] tagged"},
+		{Severity: Severity_ERROR, Time: 2, File: "c.go", Line: 2, Message: "multi\nline \"quoted\" <html>",
+			Sensitivity: Sensitivity_RESTRICTED},
+	}
+	for _, entry := range entries {
+		buf := formatJSONEntry(entry, nil)
+		data := append([]byte(nil), buf.Bytes()...)
+		logging.putBuffer(buf)
+		if bytes.Count(data, []byte("\n")) != 1 || data[len(data)-1] != '\n' {
+			t.Errorf("expected a single line, got %q", data)
+		}
+		var decoded Entry
+		if err := decodeJSONEntry(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded != entry {
+			t.Errorf("expected %+v, got %+v", entry, decoded)
+		}
+	}
+
+	buf := formatJSONEntry(entries[1], []byte("goroutine 1 [running]:\n"))
+	defer logging.putBuffer(buf)
+	var e map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e["tags"] != "n1,client=1.2.3.4" || e["message"] != "tagged" ||
+		e["severity"] != "WARNING" || e["stacks"] != "goroutine 1 [running]:\n" {
+		t.Errorf("unexpected encoding %s", buf.Bytes())
+	}
+}
+
+func TestJSONLogFile(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer func(stderrFormat, fileFormat outputFormat) {
+		logging.stderrFormat, logging.fileFormat = stderrFormat, fileFormat
+	}(logging.stderrFormat, logging.fileFormat)
+
+	if err := SetFormat(context.Background(), "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	ctx := context.Background()
+	if err := SetFormat(ctx, "json"); err != nil {
+		t.Fatal(err)
+	}
+	Infof(WithLogTagInt(ctx, "n", 1), "first line\nsecond line")
+	Warning(ctx, "the end")
+	Flush()
+
+	contents, err := ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n") {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Errorf("expected a JSON object per line, got %q: %s", line, err)
+		}
+	}
+
+	d := NewEntryDecoder(bytes.NewReader(contents))
+	var messages []string
+	for {
+		var e Entry
+		if err := d.Decode(&e); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(e.Message, "[config]") && !strings.HasPrefix(e.Message, "line format") {
+			messages = append(messages, e.Message)
+		}
+	}
+	if d.Version() != FormatVersion {
+		t.Errorf("expected the header to be decoded, got version %d", d.Version())
+	}
+	if len(messages) != 3 || !strings.Contains(messages[0], `"what":"format"`) ||
+		messages[1] != "[n1] first line\nsecond line" || messages[2] != "the end" {
+		t.Errorf("unexpected entries %q", messages)
+	}
+}
+
+func TestFormatsFlag(t *testing.T) {
+	defer func(stderrFormat, fileFormat outputFormat) {
+		logging.stderrFormat, logging.fileFormat = stderrFormat, fileFormat
+	}(logging.stderrFormat, logging.fileFormat)
+
+	if err := (formatsFlag{}).Set("json"); err != nil {
+		t.Fatal(err)
+	}
+	if logging.stderrFormat != formatJSON || logging.fileFormat != formatJSON {
+		t.Errorf("expected both formats to be json, got %s and %s",
+			logging.stderrFormat.String(), logging.fileFormat.String())
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestOutputFormatFlag(t *testing.T) {
+	var f outputFormat
+	for _, name := range []string{"crdb-v1", "crdb-v1-tty", "json"} {
+		if err := f.Set(name); err != nil {
+			t.Fatal(err)
+		}
+		if s := f.String(); s != name {
+			t.Errorf("expected %s, got %s", name, s)
+		}
+	}
+	if err := f.Set("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestOutputFormatColors(t *testing.T) {
+	if c := formatCrdbV1.colors(colorProfile256); c != nil {
+		t.Errorf("expected no colors, got %v", c)
+	}
+	if c := formatCrdbV1TTY.colors(colorProfile256); c != colorProfile256 {
+		t.Errorf("expected colors, got %v", c)
+	}
+}
+
+func TestFileRedact(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	logging.fileRedact = true
+	defer func() { logging.fileRedact = false }()
+
+	ctx := WithLogTagInt(context.Background(), "n", 1)
+	ctx = WithLogTagStr(ctx, "client", "1.2.3.4")
+	Infof(ctx, "user %s has %d rows, %s", "hunter2", 3, Safe{V: "public"})
+	Flush()
+
+	contents, err := ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[n1,client=<redacted>] user <redacted> has 3 rows, public"
+	if !strings.Contains(string(contents), expected) {
+		t.Errorf("expected redacted entry %q in log\n%s", expected, contents)
+	}
+	if strings.Contains(string(contents), "hunter2") || strings.Contains(string(contents), "1.2.3.4") {
+		t.Errorf("sensitive data was not redacted from log\n%s", contents)
+	}
+}
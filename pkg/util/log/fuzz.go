@@ -0,0 +1,34 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build gofuzz
+
+package log
+
+// Fuzz is the go-fuzz target of the formatting and redaction of log
+// entries. It panics if an entry does not round-trip through the machine
+// formats, or if redaction leaks unsafe data. See fuzzEntry for the
+// interpretation of its input; an initial corpus is written by:
+//
+//	go test ./pkg/util/log -run TestFuzzCorpus -fuzz-corpus <dir>
+func Fuzz(data []byte) int {
+	e, ok := fuzzEntry(data)
+	if !ok {
+		return -1
+	}
+	if err := e.check(); err != nil {
+		panic(err)
+	}
+	return 1
+}
@@ -0,0 +1,258 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// corpusUnsafeMarker is included in every value which the corpus
+// generator passes without marking it as safe, and in no other value, so
+// that its presence in a redacted message reveals a leak.
+const corpusUnsafeMarker = "☢"
+
+// corpusTag is a log tag of a corpusEntry.
+type corpusTag struct {
+	name  string
+	value interface{}
+}
+
+// corpusEntry is a synthetic log entry produced by generateCorpusEntry.
+type corpusEntry struct {
+	severity  Severity
+	time      time.Time
+	goroutine int
+	file      string
+	line      int
+	tags      []corpusTag
+	ids       spanIDs
+	format    string
+	args      []interface{}
+}
+
+// corpusStringer is an unsafe value implementing fmt.Stringer.
+type corpusStringer struct{ s string }
+
+func (c corpusStringer) String() string { return c.s }
+
+var corpusFormats = []string{
+	"",
+	"plain message",
+	"%s",
+	"%v and %v",
+	"%d items in %s",
+	"%q: %+v",
+	"%x %5.2f",
+	"%%s is not a verb",
+	"trailing whitespace %s \t\n",
+	"multi\nline\n\tindented %v",
+	"unicode é世 %s",
+}
+
+var corpusWords = []string{"", "a", "key", "/some/path", "x=y", "été", "with space", "]"}
+
+// generateCorpusWord returns a random string which never contains
+// corpusUnsafeMarker.
+func generateCorpusWord(rng *rand.Rand) string {
+	return corpusWords[rng.Intn(len(corpusWords))]
+}
+
+// generateCorpusValue returns a random argument or tag value. Values not
+// wrapped in Safe and not numeric or boolean, including those wrapped in
+// Unsafe, contain corpusUnsafeMarker.
+func generateCorpusValue(rng *rand.Rand) interface{} {
+	unsafe := corpusUnsafeMarker + generateCorpusWord(rng)
+	switch rng.Intn(13) {
+	case 0:
+		return nil
+	case 1:
+		return rng.Intn(1000) - 500
+	case 2:
+		return rng.Int63()
+	case 3:
+		return uint8(rng.Intn(256))
+	case 4:
+		return rng.Float64()
+	case 5:
+		return rng.Intn(2) == 0
+	case 6:
+		return unsafe
+	case 7:
+		return []byte(unsafe)
+	case 8:
+		return errors.New(unsafe)
+	case 9:
+		return corpusStringer{unsafe}
+	case 10:
+		return Unsafe{V: unsafe}
+	case 11:
+		return Safe{V: generateCorpusWord(rng)}
+	default:
+		return &Safe{V: rng.Intn(100)}
+	}
+}
+
+// generateCorpusEntry returns a random entry exercising the formatting
+// and redaction paths of log messages.
+func generateCorpusEntry(rng *rand.Rand) corpusEntry {
+	e := corpusEntry{
+		severity: Severity(rng.Intn(int(Severity_FATAL)) + 1),
+		// The header of entries only records two digits of the year, and
+		// local times, which are ambiguous around the changes of daylight
+		// saving time in the early hours.
+		time: time.Date(2000+rng.Intn(100), time.Month(rng.Intn(12)+1), rng.Intn(28)+1,
+			4+rng.Intn(20), rng.Intn(60), rng.Intn(60), rng.Intn(1e9), time.Local),
+		// Zero goroutine IDs are elided from the header.
+		goroutine: rng.Intn(3) * rng.Intn(1e6),
+		file:      fmt.Sprintf("pkg/%s/file%d.go", []string{"kv", "sql", "util/log"}[rng.Intn(3)], rng.Intn(10)),
+		line:      rng.Intn(5000),
+		format:    corpusFormats[rng.Intn(len(corpusFormats))],
+	}
+	for i, n := 0, rng.Intn(4); i < n; i++ {
+		e.tags = append(e.tags, corpusTag{
+			name:  []string{"n", "s", "r", "client", "user"}[rng.Intn(5)],
+			value: generateCorpusValue(rng),
+		})
+	}
+	if rng.Intn(3) == 0 {
+		e.ids = spanIDs{traceID: uint64(rng.Int63()) + 1, spanID: uint64(rng.Int63()) + 1}
+	}
+	// Mismatched numbers of arguments exercise the error paths of fmt.
+	for i, n := 0, rng.Intn(4); i < n; i++ {
+		e.args = append(e.args, generateCorpusValue(rng))
+	}
+	return e
+}
+
+// maxFuzzPayload bounds the size of the payload of fuzz inputs, which
+// is well below the size of the entries that EntryDecoder truncates.
+const maxFuzzPayload = 4096
+
+// fuzzEntry interprets the input of the fuzz target: the first 8 bytes
+// seed the generation of an entry, and the rest is a payload appended to
+// its arguments as an unsafe value. The boolean return value is false if
+// the input can't be interpreted, or if the payload contains the header
+// of an entry, in which case the entry is split on decoding by design.
+func fuzzEntry(data []byte) (corpusEntry, bool) {
+	if len(data) < 8 || len(data) > 8+maxFuzzPayload {
+		return corpusEntry{}, false
+	}
+	payload := data[8:]
+	if entryRE.Match(payload) {
+		return corpusEntry{}, false
+	}
+	rng := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(data))))
+	e := generateCorpusEntry(rng)
+	e.format += " %s"
+	e.args = append(e.args, corpusUnsafeMarker+string(payload))
+	return e, true
+}
+
+func (e corpusEntry) context() context.Context {
+	ctx := context.Background()
+	for _, t := range e.tags {
+		ctx = WithLogTag(ctx, t.name, t.value)
+	}
+	return ctx
+}
+
+// check verifies that the entry round-trips through the log file format
+// and its protobuf encoding, and that none of its unsafe values leak into
+// the redacted renditions of the entry.
+func (e corpusEntry) check() error {
+	ctx := e.context()
+	msg := makeMessage(ctx, e.format, e.args, e.ids)
+	entry := Entry{
+		Severity:  e.severity,
+		Time:      e.time.UnixNano(),
+		Goroutine: int64(e.goroutine),
+		File:      e.file,
+		Line:      int64(e.line),
+		Message:   msg,
+	}
+
+	// The header of log files only records microseconds, and the decoder
+	// trims the whitespace around messages.
+	expected := entry
+	expected.Time = e.time.Truncate(time.Microsecond).UnixNano()
+	expected.Message = strings.TrimSpace(msg)
+	buf := formatLogEntry(entry, nil, nil)
+	defer logging.putBuffer(buf)
+	d := NewEntryDecoder(bytes.NewReader(buf.Bytes()))
+	var decoded Entry
+	if err := d.Decode(&decoded); err != nil {
+		return fmt.Errorf("decoding %+v: %v", entry, err)
+	}
+	if decoded != expected {
+		return fmt.Errorf("expected %+v to decode as %+v, got %+v", entry, expected, decoded)
+	}
+	if err := d.Decode(&decoded); err != io.EOF {
+		return fmt.Errorf("expected %+v to decode as a single entry, got %+v, %v", entry, decoded, err)
+	}
+
+	// Interned, the entry decodes the same, after the definition of its
+	// template.
+	var l loggingT
+	if buf, ok := l.formatInterned(entry, nil, e.format, e.args); ok {
+		defer logging.putBuffer(buf)
+		d := NewEntryDecoder(bytes.NewReader(buf.Bytes()))
+		var def Entry
+		if err := d.Decode(&def); err != nil || !strings.HasPrefix(def.Message, templateDefPrefix) {
+			return fmt.Errorf("expected the definition of the template of %+v, got %+v, %v", entry, def, err)
+		}
+		decoded = Entry{}
+		if err := d.Decode(&decoded); err != nil {
+			return fmt.Errorf("decoding interned %+v: %v", entry, err)
+		}
+		if decoded != expected {
+			return fmt.Errorf("expected interned %+v to decode as %+v, got %+v", entry, expected, decoded)
+		}
+	}
+
+	data, err := entry.Marshal()
+	if err != nil {
+		return err
+	}
+	decoded = Entry{}
+	if err := decoded.Unmarshal(data); err != nil {
+		return err
+	}
+	if decoded != entry {
+		return fmt.Errorf("expected %+v to unmarshal as itself, got %+v", entry, decoded)
+	}
+
+	redacted := []string{makeRedactedMessage(ctx, e.format, e.args, e.ids)}
+	for _, v := range contextReportableTags(ctx) {
+		redacted = append(redacted, fmt.Sprint(v))
+	}
+	for _, arg := range e.args {
+		redacted = append(redacted, formatPanicValue(arg))
+	}
+	for _, s := range redacted {
+		if strings.Contains(s, corpusUnsafeMarker) {
+			return fmt.Errorf("redaction of %q leaked unsafe data: %q", msg, s)
+		}
+	}
+	return nil
+}
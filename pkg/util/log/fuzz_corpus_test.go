@@ -0,0 +1,76 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var fuzzCorpusDir = flag.String("fuzz-corpus", "",
+	"if set, the directory in which TestFuzzCorpus writes the initial corpus of the fuzz target")
+
+func TestCorpusEntries(t *testing.T) {
+	rng := rand.New(rand.NewSource(rand.Int63()))
+	for i := 0; i < 1000; i++ {
+		if err := generateCorpusEntry(rng).check(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCorpusEntryCheck(t *testing.T) {
+	e := corpusEntry{severity: Severity_INFO, file: "f.go", format: "%s", args: []interface{}{Safe{V: corpusUnsafeMarker}}}
+	if err := e.check(); err == nil {
+		t.Error("expected a leak of unsafe data to be detected")
+	}
+}
+
+func TestFuzzCorpus(t *testing.T) {
+	rng := rand.New(rand.NewSource(rand.Int63()))
+	if *fuzzCorpusDir != "" {
+		if err := os.MkdirAll(*fuzzCorpusDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		input := make([]byte, 8, 8+16)
+		binary.BigEndian.PutUint64(input, uint64(rng.Int63()))
+		input = append(input, generateCorpusWord(rng)...)
+		e, ok := fuzzEntry(input)
+		if !ok {
+			t.Fatalf("unexpected invalid input %q", input)
+		}
+		if err := e.check(); err != nil {
+			t.Fatal(err)
+		}
+		if *fuzzCorpusDir != "" {
+			path := filepath.Join(*fuzzCorpusDir, fmt.Sprintf("entry-%d", i))
+			if err := ioutil.WriteFile(path, input, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if _, ok := fuzzEntry(append(make([]byte, 8), "x\nI170101 00:00:00.000000 1 f.go:1  forged"...)); ok {
+		t.Error("expected inputs containing entry headers to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// entrySequencePrefix prefixes the message of the log file header entry
+// which records the sequence number of the first entry of the file: the
+// number of entries previously written to the log files by the process.
+// Comparing it across the files of a process reveals the entries which
+// are missing, for instance because a file was removed or is incomplete.
+const entrySequencePrefix = "[config] entry sequence: "
+
+// gapPrefix prefixes the message of the entries synthesized on the read
+// path to annotate the places where entries are missing.
+const gapPrefix = "[gap] "
+
+// isHeaderEntry returns true if msg is the message of an entry of the
+// header of a log file, or of a definition of a message template, which
+// are not counted in the sequence of entries.
+func isHeaderEntry(msg string) bool {
+	return strings.HasPrefix(msg, "[config] ") || strings.HasPrefix(msg, "line format: ")
+}
+
+// IsGap returns true if entry was synthesized by EntryDecoder or
+// FetchEntriesFromFiles to annotate missing entries.
+func IsGap(entry Entry) bool {
+	return strings.HasPrefix(entry.Message, gapPrefix)
+}
+
+// makeGap returns an entry annotating missing entries at time t.
+func makeGap(t int64, format string, args ...interface{}) Entry {
+	return Entry{
+		Severity: Severity_WARNING,
+		Time:     t,
+		Message:  gapPrefix + fmt.Sprintf(format, args...),
+	}
+}
+
+// parseEntrySequence records the sequence number in msg, if it is the
+// header entry which records it.
+func (d *EntryDecoder) parseEntrySequence(msg string) error {
+	if !strings.HasPrefix(msg, entrySequencePrefix) {
+		return nil
+	}
+	seq, err := strconv.ParseUint(strings.TrimSpace(msg[len(entrySequencePrefix):]), 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed entry sequence: %s", err)
+	}
+	d.firstSeq, d.hasSeq = seq, true
+	return nil
+}
+
+// fileSequence describes the range of entries of the sequence of a
+// process decoded from one of its log files.
+type fileSequence struct {
+	pid   int64
+	first uint64
+	count uint64
+	// created is the time at which the file was created.
+	created int64
+}
+
+// sequence returns the range of entries of the sequence decoded so far.
+// The boolean return value is false if the file does not record it.
+func (d *EntryDecoder) sequence() (first, count uint64, ok bool) {
+	return d.firstSeq, d.count, d.hasSeq
+}
+
+// sequenceGap returns an entry annotating the entries missing between
+// two log files of the same process, older preceding newer, which were
+// fully decoded.
+func sequenceGap(older, newer fileSequence) (Entry, bool) {
+	if older.pid != newer.pid || older.first+older.count >= newer.first {
+		return Entry{}, false
+	}
+	return makeGap(newer.created, "%d entries of process %d missing before this point",
+		newer.first-(older.first+older.count), newer.pid), true
+}
@@ -0,0 +1,115 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestDecodeTruncatedEntry(t *testing.T) {
+	var buf bytes.Buffer
+	for _, msg := range []string{"first", strings.Repeat("x", bufio.MaxScanTokenSize), "last"} {
+		b := formatLogEntry(Entry{Severity: Severity_INFO, Time: 1, File: "a.go", Line: 1, Message: msg}, nil, nil)
+		buf.Write(b.Bytes())
+		logging.putBuffer(b)
+	}
+
+	d := NewEntryDecoder(&buf)
+	var gaps, messages []string
+	for {
+		var e Entry
+		if err := d.Decode(&e); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if IsGap(e) {
+			gaps = append(gaps, e.Message)
+		} else {
+			messages = append(messages, e.Message[:4])
+		}
+	}
+	if strings.Join(messages, ",") != "firs,xxxx,last" {
+		t.Errorf("unexpected entries %q", messages)
+	}
+	if len(gaps) != 1 || !strings.HasPrefix(gaps[0], gapPrefix+"entry at a.go:1 truncated to ") {
+		t.Errorf("expected the truncation to be annotated, got %q", gaps)
+	}
+}
+
+func TestFetchEntriesAnnotatesSequenceGaps(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer func(previous int64) { LogFileMaxSize = previous }(LogFileMaxSize)
+	LogFileMaxSize = 2048
+
+	ctx := context.Background()
+	for i := 0; i < 60; i++ {
+		Infof(ctx, "entry %d %s", i, strings.Repeat("x", 100))
+	}
+	Flush()
+
+	files, err := ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = selectFiles(files, math.MaxInt64)
+	if len(files) < 3 {
+		t.Fatalf("expected several log files, got %d", len(files))
+	}
+	// Remove a file in the middle of the sequence.
+	removed := files[len(files)/2]
+	_, _, seq, err := readAllEntriesFromFile(removed, 0, math.MaxInt64, math.MaxInt32, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq == nil || seq.count == 0 {
+		t.Fatalf("expected the entry sequence of %s, got %+v", removed.Name, seq)
+	}
+	dir, err := logDir.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, removed.Name)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := FetchEntriesFromFiles(0, math.MaxInt64, math.MaxInt32, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gaps []string
+	for _, e := range entries {
+		if IsGap(e) {
+			gaps = append(gaps, e.Message)
+		}
+	}
+	expected := fmt.Sprintf("%s%d entries of process %d missing before this point",
+		gapPrefix, seq.count, removed.Details.PID)
+	if len(gaps) != 1 || gaps[0] != expected {
+		t.Errorf("expected %q, got %q", expected, gaps)
+	}
+}
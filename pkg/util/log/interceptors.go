@@ -0,0 +1,111 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import "fmt"
+
+// EntryInterceptor transforms a log entry before it is written to stderr,
+// the log files and the sinks. It is passed the entry and its redacted
+// rendition, which is output in place of the entry where redaction is
+// enabled; interceptors which rewrite the message must rewrite both. It
+// returns false to drop the entry, except that fatal entries are never
+// dropped.
+//
+// Interceptors are called with the logging lock held: they must be fast,
+// and must neither log nor panic.
+type EntryInterceptor func(entry, redactedEntry *Entry) bool
+
+// namedInterceptor is an interceptor in the chain.
+type namedInterceptor struct {
+	name string
+	fn   EntryInterceptor
+}
+
+// RegisterEntryInterceptor appends an interceptor to the chain applied to
+// every entry, so that enrichers, scrubbers and counters compose in the
+// order in which they are registered. It panics if an interceptor of the
+// same name is already registered. The returned function removes the
+// interceptor from the chain.
+func RegisterEntryInterceptor(name string, fn EntryInterceptor) (remove func()) {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	for _, i := range logging.interceptors {
+		if i.name == name {
+			panic(fmt.Sprintf("log entry interceptor %q already registered", name))
+		}
+	}
+	logging.interceptors = append(logging.interceptors, namedInterceptor{name: name, fn: fn})
+	return func() {
+		logging.mu.Lock()
+		defer logging.mu.Unlock()
+		logging.removeInterceptorLocked(name)
+	}
+}
+
+// removeInterceptorLocked removes the interceptor of the given name from the
+// chain, if any. l.mu is held.
+func (l *loggingT) removeInterceptorLocked(name string) {
+	chain := make([]namedInterceptor, 0, len(l.interceptors))
+	for _, i := range l.interceptors {
+		if i.name != name {
+			chain = append(chain, i)
+		}
+	}
+	l.interceptors = chain
+}
+
+// intercept applies the chain of interceptors to the entry and its
+// redacted rendition, and returns false if the entry is to be dropped.
+// l.mu is held.
+func (l *loggingT) intercept(entry, redactedEntry *Entry) bool {
+	for _, i := range l.interceptors {
+		if !i.fn(entry, redactedEntry) && entry.Severity != Severity_FATAL {
+			return false
+		}
+	}
+	return true
+}
+
+// interceptWithName is the name of the interceptor installed by
+// InterceptWith.
+const interceptWithName = "intercept-with"
+
+// InterceptWith installs fn to receive every entry logged by the process,
+// regardless of the thresholds of the outputs. It lets tests and programs
+// embedding the log package assert on or forward the log output without
+// scraping the log files. fn is appended to the chain of interceptors
+// under the name "intercept-with", so that it receives the entries as
+// transformed by the interceptors registered before it. Installing a
+// function replaces the previous one, and passing nil stops the
+// interception.
+//
+// Like the other interceptors, fn is called with the logging lock held,
+// including on fatal entries before the process exits: it must be fast,
+// and must neither log nor panic.
+func InterceptWith(fn func(Entry)) {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	logging.removeInterceptorLocked(interceptWithName)
+	if fn == nil {
+		return
+	}
+	logging.interceptors = append(logging.interceptors, namedInterceptor{
+		name: interceptWithName,
+		fn: func(entry, _ *Entry) bool {
+			fn(*entry)
+			return true
+		},
+	})
+}
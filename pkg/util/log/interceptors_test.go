@@ -0,0 +1,120 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestEntryInterceptors(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	var counted int
+	defer RegisterEntryInterceptor("tenant", func(e, r *Entry) bool {
+		e.Message = "[tenant=7] " + e.Message
+		r.Message = "[tenant=7] " + r.Message
+		return true
+	})()
+	defer RegisterEntryInterceptor("scrub", func(e, r *Entry) bool {
+		if strings.Contains(e.Message, "secret") {
+			return false
+		}
+		e.Message = strings.Replace(e.Message, "tenant", "t", 1)
+		return true
+	})()
+	defer RegisterEntryInterceptor("count", func(e, r *Entry) bool {
+		counted++
+		return true
+	})()
+
+	ctx := context.Background()
+	Info(ctx, "visible")
+	Info(ctx, "the secret")
+	if !contains("[t=7] visible", t) {
+		t.Errorf("expected the interceptors to apply in order, got %s", contents())
+	}
+	if contains("secret", t) {
+		t.Errorf("expected the entry to be dropped, got %s", contents())
+	}
+	if counted != 1 {
+		t.Errorf("expected the dropped entry to skip the rest of the chain, counted %d", counted)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected duplicate registrations to panic")
+		}
+	}()
+	RegisterEntryInterceptor("count", func(e, r *Entry) bool { return true })
+}
+
+func TestInterceptWith(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	defer RegisterEntryInterceptor("scrub", func(e, r *Entry) bool {
+		return !strings.Contains(e.Message, "secret")
+	})()
+
+	var entries []Entry
+	InterceptWith(func(e Entry) {
+		entries = append(entries, e)
+	})
+	defer InterceptWith(nil)
+
+	ctx := context.Background()
+	// Entries below the thresholds of the outputs are intercepted too.
+	Info(ctx, "first")
+	Info(ctx, "the secret")
+	Warning(ctx, "second")
+	// Installing a function replaces the previous one.
+	var replaced int
+	InterceptWith(func(e Entry) {
+		replaced++
+	})
+	Info(ctx, "third")
+	InterceptWith(nil)
+	Info(ctx, "fourth")
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 intercepted entries, got %+v", entries)
+	}
+	if replaced != 1 {
+		t.Errorf("expected 1 entry intercepted by the replacement, got %d", replaced)
+	}
+	for i, e := range []struct {
+		severity Severity
+		message  string
+	}{
+		{Severity_INFO, "first"},
+		{Severity_WARNING, "second"},
+	} {
+		if entries[i].Severity != e.severity || entries[i].Message != e.message {
+			t.Errorf("%d: expected %s %q, got %s %q",
+				i, e.severity, e.message, entries[i].Severity, entries[i].Message)
+		}
+		if !strings.HasSuffix(entries[i].File, "interceptors_test.go") {
+			t.Errorf("%d: expected the entry to point to the test file, got %s", i, entries[i].File)
+		}
+	}
+}
@@ -27,18 +27,55 @@ import (
 
 const httpLogLevelPrefix = "/debug/vmodule/"
 
+// httpLogLevelPath serves the current vmodule specification.
+const httpLogLevelPath = "/debug/vmodule"
+
+// SetVModule replaces the per-file verbosity of the running process, in
+// the syntax of the --vmodule flag: a comma-separated list of pattern=N,
+// where the patterns match file names or, if they contain slashes, the
+// trailing components of file paths, such as "storage/*" for a package.
+// An empty spec restores the global verbosity everywhere. The change is
+// recorded on the ops channel as originating from ctx.
+func SetVModule(ctx context.Context, spec string) error {
+	old := logging.vmodule.String()
+	if err := logging.vmodule.Set(spec); err != nil {
+		return err
+	}
+	recordConfigChange(ctx, "vmodule", old, logging.vmodule.String())
+	return nil
+}
+
+// GetVModule returns the current per-file verbosity, in the syntax of
+// the --vmodule flag.
+func GetVModule() string {
+	return logging.vmodule.String()
+}
+
 func handleVModule(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	spec := r.RequestURI[len(httpLogLevelPrefix):]
-	if err := logging.vmodule.Set(spec); err != nil {
+	ctx := WithLogTagStr(r.Context(), "client", r.RemoteAddr)
+	if err := SetVModule(ctx, spec); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	Infof(context.Background(), "vmodule changed to: %s", spec)
 	fmt.Fprint(w, "ok: "+spec)
 }
 
+func handleGetVModule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, GetVModule())
+}
+
 func init() {
 	http.Handle(httpLogLevelPrefix, http.HandlerFunc(handleVModule))
+	http.Handle(httpLogLevelPath, http.HandlerFunc(handleGetVModule))
+	http.Handle(httpLogSinksPath, http.HandlerFunc(handleLogSinks))
+	http.Handle(httpRecentEntriesPath, http.HandlerFunc(handleRecentEntries))
+	http.Handle(httpCatalogPath, http.HandlerFunc(handleCatalog))
+	http.Handle(httpSupportBundlePath, http.HandlerFunc(handleSupportBundle))
+	http.Handle(httpStartupBannerPath, http.HandlerFunc(handleStartupBanner))
+	http.Handle(httpFlushPath, http.HandlerFunc(handleFlush))
 	copyStandardLogTo("INFO")
 }
 
@@ -182,9 +219,10 @@ func FatalfDepth(ctx context.Context, depth int, format string, args ...interfac
 }
 
 // V returns true if the logging verbosity is set to the specified level or
-// higher.
+// higher, or if the verbosity of a scope is escalated to it (see
+// EscalateVerbosity).
 func V(level level) bool {
-	return VDepth(level, 1)
+	return VDepth(level, 1) || verbosityEscalatedTo(level)
 }
 
 // Format writes the log entry to the specified writer.
@@ -0,0 +1,109 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxCapturedEntries bounds the number of entries retained by a
+// LogCapture. Entries logged past the limit are counted but not kept.
+const maxCapturedEntries = 10000
+
+// LogCapture collects the log entries logged with a context in which it
+// is embedded (see WithLogCapture), regardless of the thresholds of the
+// log outputs, while it is started. It is used to return to a client the
+// log entries generated while serving its requests. The zero value is a
+// stopped capture.
+type LogCapture struct {
+	// started is accessed atomically, so that logging with a context
+	// embedding a stopped capture remains cheap.
+	started int32
+
+	mu struct {
+		syncutil.Mutex
+		entries []Entry
+		dropped int
+	}
+}
+
+// Start discards the entries collected so far and starts collecting.
+func (c *LogCapture) Start() {
+	c.mu.Lock()
+	c.mu.entries = nil
+	c.mu.dropped = 0
+	c.mu.Unlock()
+	atomic.StoreInt32(&c.started, 1)
+}
+
+// Stop stops collecting entries. The entries collected so far remain
+// available.
+func (c *LogCapture) Stop() {
+	atomic.StoreInt32(&c.started, 0)
+}
+
+// Started returns true if the capture is collecting entries.
+func (c *LogCapture) Started() bool {
+	return atomic.LoadInt32(&c.started) == 1
+}
+
+// Entries returns the entries collected so far, and the number of
+// entries that were not retained because the capture was full.
+func (c *LogCapture) Entries() ([]Entry, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Entry(nil), c.mu.entries...), c.mu.dropped
+}
+
+func (c *LogCapture) add(entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.mu.entries) >= maxCapturedEntries {
+		c.mu.dropped++
+		return
+	}
+	c.mu.entries = append(c.mu.entries, entry)
+}
+
+// ctxLogCaptureKey is an empty type for the handle associated with the
+// LogCapture value (see context.Value).
+type ctxLogCaptureKey struct{}
+
+// WithLogCapture returns a context whose log entries are collected by c
+// while it is started.
+func WithLogCapture(ctx context.Context, c *LogCapture) context.Context {
+	return context.WithValue(ctx, ctxLogCaptureKey{}, c)
+}
+
+// captureEntry hands the entry to the capture embedded in ctx, if any.
+func captureEntry(ctx context.Context, s Severity, file string, line int, msg string) {
+	c, ok := ctx.Value(ctxLogCaptureKey{}).(*LogCapture)
+	if !ok || !c.Started() {
+		return
+	}
+	c.add(Entry{
+		Severity:    s,
+		Time:        time.Now().UnixNano(),
+		File:        file,
+		Line:        int64(line),
+		Message:     msg,
+		Sensitivity: SensitivityFromContext(ctx),
+	})
+}
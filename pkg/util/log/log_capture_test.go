@@ -0,0 +1,67 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestLogCapture(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	var c LogCapture
+	ctx := WithLogCapture(context.Background(), &c)
+
+	Info(ctx, "not started")
+	c.Start()
+	Infof(ctx, "captured %d", 1)
+	Warning(context.Background(), "other request")
+	Error(ctx, "captured 2")
+	c.Stop()
+	Info(ctx, "stopped")
+
+	entries, dropped := c.Entries()
+	if dropped != 0 {
+		t.Errorf("expected no dropped entries, got %d", dropped)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	for i, e := range []struct {
+		s   Severity
+		msg string
+	}{{Severity_INFO, "captured 1"}, {Severity_ERROR, "captured 2"}} {
+		if entries[i].Severity != e.s || entries[i].Message != e.msg ||
+			filepath.Base(entries[i].File) != "log_capture_test.go" {
+			t.Errorf("%d: expected %s %q, got %+v", i, e.s, e.msg, entries[i])
+		}
+	}
+
+	// The capture is bounded.
+	c.Start()
+	for i := 0; i < maxCapturedEntries+3; i++ {
+		captureEntry(ctx, Severity_INFO, "f.go", 1, "x")
+	}
+	if entries, dropped := c.Entries(); len(entries) != maxCapturedEntries || dropped != 3 {
+		t.Errorf("expected %d entries and 3 dropped, got %d and %d",
+			maxCapturedEntries, len(entries), dropped)
+	}
+}
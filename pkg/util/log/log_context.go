@@ -17,6 +17,8 @@
 package log
 
 import (
+	"reflect"
+
 	otlog "github.com/opentracing/opentracing-go/log"
 
 	"golang.org/x/net/context"
@@ -220,3 +222,52 @@ func chainContains(vals *logTag, key string) bool {
 	}
 	return false
 }
+
+// contextReportableTags returns the log tags in the context, keyed by
+// name, in a form suitable for inclusion in crash reports. The values of
+// tags which may contain user data are anonymized according to the
+// ReportAnonymization setting, and by default replaced by "<redacted>":
+// only numeric and boolean values not wrapped in Unsafe, and those
+// wrapped in Safe, are reported verbatim. Tags whose values are dropped are left out.
+func contextReportableTags(ctx context.Context) map[string]interface{} {
+	tags := contextLogTags(ctx, nil)
+	if len(tags) == 0 {
+		return nil
+	}
+	res := make(map[string]interface{}, len(tags))
+	for _, t := range tags {
+		v := anonymizedValue(t.Value())
+		if v == "" {
+			continue
+		}
+		res[t.Key()] = v
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+// redactedMarker replaces the data which may not be reported.
+const redactedMarker = "<redacted>"
+
+// reportableValue returns v if it is safe to report, and redactedMarker
+// otherwise.
+func reportableValue(v interface{}) interface{} {
+	switch v.(type) {
+	case nil:
+		return nil
+	case Safe, *Safe:
+		return format(v)
+	case Unsafe, *Unsafe:
+		return redactedMarker
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v
+	}
+	return redactedMarker
+}
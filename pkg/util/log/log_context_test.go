@@ -17,6 +17,7 @@
 package log
 
 import (
+	"reflect"
 	"testing"
 
 	otlog "github.com/opentracing/opentracing-go/log"
@@ -207,3 +208,31 @@ func TestMergeChains(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestContextReportableTags(t *testing.T) {
+	type nodeID int32
+
+	ctx := context.Background()
+	if tags := contextReportableTags(ctx); tags != nil {
+		t.Fatalf("expected no tags, got %v", tags)
+	}
+
+	ctx = WithLogTagInt(ctx, "n", 1)
+	ctx = WithLogTag(ctx, "node", nodeID(2))
+	ctx = WithLogTagStr(ctx, "key", "/Table/51/1/\"secret\"")
+	ctx = WithLogTag(ctx, "phase", Safe{V: "apply"})
+	ctx = WithLogTag(ctx, "rows", Unsafe{V: 42})
+	ctx = WithLogTag(ctx, "aborted", nil)
+
+	expected := map[string]interface{}{
+		"n":       1,
+		"node":    nodeID(2),
+		"key":     "<redacted>",
+		"phase":   "apply",
+		"rows":    "<redacted>",
+		"aborted": nil,
+	}
+	if tags := contextReportableTags(ctx); !reflect.DeepEqual(expected, tags) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+}
@@ -71,7 +71,42 @@ const (
 	ShowLogsName                  = "show-logs"
 	LogFileMaxSizeName            = "log-file-max-size"
 	LogFilesCombinedMaxSizeName   = "log-dir-max-size"
+	LogFileMaxAgeName             = "log-file-max-age"
+	LogFileCompressName           = "log-file-compress"
+	LogAuditFileMaxSizeName       = "log-audit-file-max-size"
+	LogAuditDirMaxSizeName        = "log-audit-dir-max-size"
+	LogSlowQueryFileMaxSizeName   = "log-slow-query-file-max-size"
+	LogSlowQueryDirMaxSizeName    = "log-slow-query-dir-max-size"
 	LogFileVerbosityThresholdName = "log-file-verbosity"
+	LogFatalDrainTimeoutName      = "log-fatal-drain-timeout"
+	LogFileFlushThresholdName     = "log-file-flush-threshold"
+	LogStderrFormatName           = "log-stderr-format"
+	LogFileFormatName             = "log-file-format"
+	LogFormatName                 = "log-format"
+	LogStderrRedactName           = "log-stderr-redact"
+	LogFileRedactName             = "log-file-redact"
+	LogFIFOName                   = "log-fifo"
+	LogFIFOVerbosityThresholdName = "log-fifo-verbosity"
+	LogFIFOFailoverName           = "log-fifo-failover"
+	LogTeeName                    = "log-tee"
+	LogTeeVerbosityThresholdName  = "log-tee-verbosity"
+	LogNetAddrName                = "log-net-addr"
+	LogNetVerbosityThresholdName  = "log-net-verbosity"
+	LogNetSpoolMaxSizeName        = "log-net-spool-max-size"
+	LogNetCompressionName         = "log-net-compression"
+	LogNetTLSName                 = "log-net-tls"
+	LogNetTLSCAName               = "log-net-tls-ca"
+	LogNetTLSCertName             = "log-net-tls-cert"
+	LogNetTLSKeyName              = "log-net-tls-key"
+	LogNetTLSServerNameName       = "log-net-tls-server-name"
+	LogNetTLSMinVersionName       = "log-net-tls-min-version"
+	LogSyslogName                 = "log-syslog"
+	LogSyslogTagName              = "log-syslog-tag"
+	LogSyslogVerbosityName        = "log-syslog-verbosity"
+	LogFluentAddrName             = "log-fluent-addr"
+	LogFluentTagName              = "log-fluent-tag"
+	LogFluentVerbosityName        = "log-fluent-verbosity"
+	LogProfileName                = "log-profile"
 )
 
 // InitFlags creates logging flags which update the given variables. The passed mutex is
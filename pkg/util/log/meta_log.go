@@ -0,0 +1,88 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// metaLogInterval is the minimum interval between two messages of the
+// same kind emitted by the log package about its own operation.
+const metaLogInterval = 10 * time.Second
+
+// metaLogger emits the messages of the log package about its own
+// operation, such as the failures of sinks, dropped entries or errors
+// while rotating files. They are written to the original stderr
+// directly rather than logged, so that a failing sink can't cause a
+// feedback loop of entries about failing to log, and each kind of
+// message is emitted at most once per interval, with a count of the
+// messages it suppressed in the meantime.
+type metaLogger struct {
+	syncutil.Mutex
+	interval time.Duration
+	kinds    map[string]*metaLogKind
+	// out and now are overridden in tests; out defaults to OrigStderr.
+	out io.Writer
+	now func() time.Time
+}
+
+type metaLogKind struct {
+	last       time.Time
+	suppressed int
+}
+
+var metaLog = &metaLogger{interval: metaLogInterval, now: time.Now}
+
+// metaLogf emits a message of the given kind about the operation of the
+// log package, unless one of the same kind was emitted less than
+// metaLogInterval ago.
+func metaLogf(kind, format string, args ...interface{}) {
+	metaLog.logf(kind, format, args...)
+}
+
+func (m *metaLogger) logf(kind, format string, args ...interface{}) {
+	now := m.now()
+	m.Lock()
+	defer m.Unlock()
+	if m.kinds == nil {
+		m.kinds = make(map[string]*metaLogKind)
+	}
+	k, ok := m.kinds[kind]
+	if !ok {
+		k = &metaLogKind{}
+		m.kinds[kind] = k
+	}
+	if !k.last.IsZero() && now.Sub(k.last) < m.interval {
+		k.suppressed++
+		return
+	}
+	suppressed := k.suppressed
+	k.last, k.suppressed = now, 0
+
+	out := m.out
+	if out == nil {
+		out = OrigStderr
+	}
+	msg := fmt.Sprintf(format, args...)
+	if suppressed > 0 {
+		msg += fmt.Sprintf(" (%d similar messages suppressed)", suppressed)
+	}
+	// There is nowhere left to report a failure to write.
+	_, _ = fmt.Fprintf(out, "log: %s\n", msg)
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetaLogger(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(0, 0)
+	m := &metaLogger{interval: time.Second, out: &buf, now: func() time.Time { return now }}
+
+	m.logf("a", "first %d", 1)
+	m.logf("a", "second")
+	m.logf("a", "third")
+	m.logf("b", "other kind")
+	now = now.Add(time.Second)
+	m.logf("a", "fourth")
+	m.logf("a", "fifth")
+
+	expected := []string{
+		"log: first 1",
+		"log: other kind",
+		"log: fourth (2 similar messages suppressed)",
+	}
+	if lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); strings.Join(lines, "|") !=
+		strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
+
+func TestSinkFailuresAreThrottled(t *testing.T) {
+	var buf bytes.Buffer
+	metaLog.Lock()
+	oldInterval, oldKinds := metaLog.interval, metaLog.kinds
+	metaLog.interval, metaLog.kinds, metaLog.out = time.Hour, nil, &buf
+	metaLog.Unlock()
+	defer func() {
+		metaLog.Lock()
+		metaLog.interval, metaLog.kinds, metaLog.out = oldInterval, oldKinds, nil
+		metaLog.Unlock()
+	}()
+
+	h := sinkHealth{name: "tcp:example:1234"}
+	for i := 0; i < 100; i++ {
+		h.recordError(errors.New("connection refused"))
+		h.recordDropped()
+	}
+	expected := "log: unable to deliver entries to tcp:example:1234: connection refused\n" +
+		"log: 1 entries bound for tcp:example:1234 dropped so far\n"
+	metaLog.Lock()
+	s := buf.String()
+	metaLog.Unlock()
+	if s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+	if st := h.status("", 0); st.Dropped != 100 {
+		t.Errorf("expected all the drops to be accounted for, got %d", st.Dropped)
+	}
+}
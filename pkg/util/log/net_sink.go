@@ -0,0 +1,258 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// netSinkBufferSize is the number of entries buffered in memory by a
+// network sink without a spool while its destination is unavailable.
+const netSinkBufferSize = 10000
+
+// netSinkRetryInterval is the interval after which a network sink retries
+// connecting to its destination after a failure. It doubles with every
+// consecutive failure, up to netSinkMaxRetryInterval.
+const netSinkRetryInterval = time.Second
+
+// netSinkMaxRetryInterval bounds the interval between the attempts of a
+// network sink to connect to its destination.
+const netSinkMaxRetryInterval = 30 * time.Second
+
+// netSinkDialTimeout bounds the time spent connecting to the destination
+// of a network sink.
+const netSinkDialTimeout = 5 * time.Second
+
+// netCompression identifies the compression applied by a network sink to
+// the stream of entries it sends.
+type netCompression int
+
+const (
+	netCompressionNone netCompression = iota
+	netCompressionGzip
+)
+
+var netCompressionNames = map[netCompression]string{
+	netCompressionNone: "none",
+	netCompressionGzip: "gzip",
+}
+
+// String is part of the flag.Value interface.
+func (c *netCompression) String() string {
+	return netCompressionNames[*c]
+}
+
+// Set is part of the flag.Value interface.
+func (c *netCompression) Set(value string) error {
+	for k, name := range netCompressionNames {
+		if name == value {
+			*c = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown compression %q, expected none or gzip", value)
+}
+
+// netSinkOptions configures a network sink.
+type netSinkOptions struct {
+	compression netCompression
+	// tls is set if the connection uses TLS.
+	tls *sinkTLSOptions
+}
+
+// netConn is the connection of a network sink to its destination.
+type netConn struct {
+	conn net.Conn
+	w    io.Writer
+	// gz is set when the stream is compressed.
+	gz *gzip.Writer
+}
+
+// write sends data on the connection. Compressed data is flushed after
+// every entry, so that entries are not held back in the compressor; the
+// compression window is preserved across flushes, so the stream still
+// compresses the redundancy between entries.
+func (c *netConn) write(data []byte) error {
+	if _, err := c.w.Write(data); err != nil {
+		return err
+	}
+	if c.gz != nil {
+		return c.gz.Flush()
+	}
+	return nil
+}
+
+func (c *netConn) close() {
+	if c.gz != nil {
+		_ = c.gz.Close()
+	}
+	_ = c.conn.Close()
+}
+
+// netSink writes entries to a TCP collector. Entries are queued and
+// delivered by a dedicated goroutine which reconnects as needed. An entry
+// is only removed from the queue once written to the connection, so
+// entries are delivered at least once as long as they fit in the queue;
+// with a disk queue, this also holds across process restarts.
+type netSink struct {
+	health  sinkHealth
+	addr    string
+	opts    netSinkOptions
+	queue   sinkQueue
+	stopper chan struct{}
+	done    chan struct{}
+	// backoff is the interval before the next retry. It is only accessed
+	// by the goroutine delivering the entries.
+	backoff time.Duration
+}
+
+func newNetSink(addr string, opts netSinkOptions, queue sinkQueue) *netSink {
+	s := &netSink{
+		addr:    addr,
+		opts:    opts,
+		queue:   queue,
+		stopper: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.health.name = s.String()
+	go s.run()
+	return s
+}
+
+func (s *netSink) String() string {
+	return "tcp:" + s.addr
+}
+
+func (s *netSink) write(data []byte) error {
+	err := s.queue.push(data)
+	if err != nil {
+		s.health.recordDropped()
+	}
+	return err
+}
+
+func (s *netSink) backpressure() float64 {
+	return s.queue.fill()
+}
+
+func (s *netSink) recordDropped() {
+	s.health.recordDropped()
+}
+
+func (s *netSink) status() SinkStatus {
+	return s.health.status(s.String(), s.queue.length())
+}
+
+// close stops the sink. Entries not yet delivered are discarded, unless
+// the queue is on disk.
+func (s *netSink) close() {
+	close(s.stopper)
+	<-s.done
+	if q, ok := s.queue.(*diskQueue); ok {
+		_ = q.close()
+	}
+}
+
+// retry waits before the next attempt to deliver an entry, for an
+// interval which backs off exponentially while the attempts fail. It
+// returns false if the sink was stopped in the meantime.
+func (s *netSink) retry() bool {
+	if s.backoff == 0 {
+		s.backoff = netSinkRetryInterval
+	}
+	wait := s.backoff
+	if s.backoff *= 2; s.backoff > netSinkMaxRetryInterval {
+		s.backoff = netSinkMaxRetryInterval
+	}
+	select {
+	case <-time.After(wait):
+		return true
+	case <-s.stopper:
+		return false
+	}
+}
+
+// dial connects to the destination of the sink.
+func (s *netSink) dial() (*netConn, error) {
+	var conn net.Conn
+	var err error
+	if s.opts.tls != nil {
+		conn, err = dialTLS(s.addr, s.opts.tls)
+	} else {
+		conn, err = dialTCP(s.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &netConn{conn: conn, w: conn}
+	if s.opts.compression == netCompressionGzip {
+		c.gz = gzip.NewWriter(conn)
+		c.w = c.gz
+	}
+	return c, nil
+}
+
+func (s *netSink) run() {
+	defer close(s.done)
+	setProfilerLabels(profilerLabelSink)
+	var conn *netConn
+	defer func() {
+		if conn != nil {
+			conn.close()
+		}
+	}()
+	for {
+		data, ok, err := s.queue.peek(s.stopper)
+		if !ok {
+			if err != nil {
+				s.health.recordError(err)
+			}
+			if err != nil && s.retry() {
+				continue
+			}
+			return
+		}
+		if conn == nil {
+			if conn, err = s.dial(); err != nil {
+				s.health.recordError(err)
+				conn = nil
+				if !s.retry() {
+					return
+				}
+				continue
+			}
+			s.health.setConnected(true)
+		}
+		if err := conn.write(data); err != nil {
+			// Reconnect and deliver the entry again.
+			s.health.recordError(err)
+			conn.close()
+			conn = nil
+			continue
+		}
+		s.health.recordSent(len(data))
+		s.backoff = 0
+		if err := s.queue.pop(); err != nil {
+			s.health.recordError(err)
+			if !s.retry() {
+				return
+			}
+		}
+	}
+}
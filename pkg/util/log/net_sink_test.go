@@ -0,0 +1,103 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !nophonehome
+
+package log
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNetSink(t *testing.T) {
+	t.Run("none", func(t *testing.T) { testNetSink(t, netCompressionNone) })
+	t.Run("gzip", func(t *testing.T) { testNetSink(t, netCompressionGzip) })
+}
+
+func testNetSink(t *testing.T, compression netCompression) {
+	// Reserve an address for the collector, which is not listening yet.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	if err := ln.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sink := newNetSink(addr, netSinkOptions{compression: compression}, newMemQueue(10))
+	defer sink.close()
+
+	// Entries are queued while the collector is unavailable.
+	for _, e := range []string{"a\n", "b\n"} {
+		if err := sink.write([]byte(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if err := ln.(*net.TCPListener).SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var r *bufio.Reader
+	if compression == netCompressionGzip {
+		gz, err := gzip.NewReader(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bufio.NewReader(gz)
+	} else {
+		r = bufio.NewReader(conn)
+	}
+	for _, e := range []string{"a\n", "b\n"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != e {
+			t.Errorf("expected %q, got %q", e, line)
+		}
+	}
+}
+
+func TestNetCompressionFlag(t *testing.T) {
+	var c netCompression
+	for _, name := range []string{"gzip", "none"} {
+		if err := c.Set(name); err != nil {
+			t.Fatal(err)
+		}
+		if s := c.String(); s != name {
+			t.Errorf("expected %s, got %s", name, s)
+		}
+	}
+	if err := c.Set("lz4"); err == nil {
+		t.Error("expected error for unknown compression")
+	}
+}
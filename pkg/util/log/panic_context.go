@@ -0,0 +1,110 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+)
+
+// panicHint is a description of the operation in progress, attached to a
+// context. The hints of a context form a stack, linked through parent.
+type panicHint struct {
+	format string
+	args   []interface{}
+	parent *panicHint
+}
+
+// ctxPanicHintKey is an empty type for the handle associated with the
+// panicHint value (see context.Value).
+type ctxPanicHintKey struct{}
+
+// WithPanicHint returns a context carrying a description of the
+// operation in progress, for instance "applying command at index %d on
+// range %d", on top of the descriptions already carried by ctx. The
+// descriptions are included in the panic reports and the crash reports
+// produced with the context or the contexts derived from it. As for log
+// messages, only the arguments marked as Safe, and numbers, are included
+// in crash reports; the other ones are redacted.
+func WithPanicHint(ctx context.Context, format string, args ...interface{}) context.Context {
+	parent, _ := ctx.Value(ctxPanicHintKey{}).(*panicHint)
+	return context.WithValue(ctx, ctxPanicHintKey{}, &panicHint{
+		format: format,
+		args:   args,
+		parent: parent,
+	})
+}
+
+// panicHints returns the hints carried by ctx, outermost first. If
+// redact is set, the arguments which are not safe to report are
+// anonymized.
+func panicHints(ctx context.Context, redact bool) []string {
+	var hints []string
+	for h, _ := ctx.Value(ctxPanicHintKey{}).(*panicHint); h != nil; h = h.parent {
+		var s string
+		if redact {
+			s = makeAnonymizedMessage(context.Background(), h.format, h.args, spanIDs{})
+		} else {
+			s = fmt.Sprintf(h.format, h.args...)
+		}
+		hints = append(hints, s)
+	}
+	for i, j := 0, len(hints)-1; i < j; i, j = i+1, j-1 {
+		hints[i], hints[j] = hints[j], hints[i]
+	}
+	return hints
+}
+
+// reportTag is a tag attached to a context, included in the crash
+// reports produced with the context. The tags of a context form a list,
+// linked through parent.
+type reportTag struct {
+	key, value string
+	parent     *reportTag
+}
+
+// ctxReportTagKey is an empty type for the handle associated with the
+// reportTag value (see context.Value).
+type ctxReportTagKey struct{}
+
+// WithReportTag returns a context whose crash reports are tagged with
+// the given key and value, which allows searching and grouping reports
+// by the tag. Unlike the log tags, report tags are reported verbatim, so
+// value must not contain user data. A tag overrides the tags with the
+// same key carried by ctx.
+func WithReportTag(ctx context.Context, key, value string) context.Context {
+	parent, _ := ctx.Value(ctxReportTagKey{}).(*reportTag)
+	return context.WithValue(ctx, ctxReportTagKey{}, &reportTag{
+		key:    key,
+		value:  value,
+		parent: parent,
+	})
+}
+
+// reportTags returns the report tags carried by ctx, keyed by name.
+func reportTags(ctx context.Context) map[string]string {
+	var tags map[string]string
+	for t, _ := ctx.Value(ctxReportTagKey{}).(*reportTag); t != nil; t = t.parent {
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		// The innermost tags override the outer ones.
+		if _, ok := tags[t.key]; !ok {
+			tags[t.key] = t.value
+		}
+	}
+	return tags
+}
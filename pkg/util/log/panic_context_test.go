@@ -0,0 +1,75 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestPanicHints(t *testing.T) {
+	ctx := context.Background()
+	if hints := panicHints(ctx, false); hints != nil {
+		t.Errorf("expected no hints, got %v", hints)
+	}
+
+	ctx = WithPanicHint(ctx, "applying command at index %d on range %d", 12, Safe{V: 3})
+	ctx = WithPanicHint(ctx, "evaluating key %s", "secret")
+	// Hints of derived contexts do not leak into the parent.
+	_ = WithPanicHint(ctx, "unrelated")
+
+	expected := []string{"applying command at index 12 on range 3", "evaluating key secret"}
+	if hints := panicHints(ctx, false); !reflect.DeepEqual(hints, expected) {
+		t.Errorf("expected %v, got %v", expected, hints)
+	}
+	expected = []string{"applying command at index 12 on range 3", "evaluating key " + redactedMarker}
+	if hints := panicHints(ctx, true); !reflect.DeepEqual(hints, expected) {
+		t.Errorf("expected %v, got %v", expected, hints)
+	}
+
+	packet := makeReportPacket(ctx, errors.New("boom"), makeStacktrace(capturePCs(0)), nil)
+	if hints := packet.Extra["panic_context"]; !reflect.DeepEqual(hints, expected) {
+		t.Errorf("expected the crash report to contain %v, got %v", expected, hints)
+	}
+}
+
+func TestReportTags(t *testing.T) {
+	ctx := context.Background()
+	if tags := reportTags(ctx); tags != nil {
+		t.Errorf("expected no tags, got %v", tags)
+	}
+
+	ctx = WithReportTag(ctx, "route", "/_admin/v1/")
+	ctx = WithReportTag(ctx, "method", "PUT")
+	ctx = WithReportTag(ctx, "method", "GET")
+	expected := map[string]string{"route": "/_admin/v1/", "method": "GET"}
+	if tags := reportTags(ctx); !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+
+	packet := makeReportPacket(ctx, errors.New("boom"), nil, nil)
+	found := make(map[string]string)
+	for _, tag := range packet.Tags {
+		if _, ok := expected[tag.Key]; ok {
+			found[tag.Key] = tag.Value
+		}
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected the crash report to be tagged with %v, got %v", expected, packet.Tags)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// MaxPayloadSize is the maximum size of the binary payload which can be
+// attached to a log entry. Larger payloads are truncated.
+const MaxPayloadSize = 64 << 10
+
+// Payload is a small binary payload, for instance the encoding of a
+// descriptor proto, attached to a log entry to aid deep debugging.
+type Payload struct {
+	// Name describes the payload. It is included in the name of the file
+	// the payload is written to.
+	Name string
+	Data []byte
+}
+
+// payloadSeq distinguishes the payload files created in the same second.
+var payloadSeq uint64
+
+var unsafePayloadNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// payloadFileName returns the name of the file holding the payload with
+// the given name and sequence number, logged at time t. As for goroutine
+// dumps, it does not match logFileRE.
+func payloadFileName(t time.Time, name string, seq uint64) string {
+	logFile, _ := logName(t)
+	name = unsafePayloadNameChars.ReplaceAllString(name, "_")
+	return fmt.Sprintf("%s.payload.%d.%s.bin", strings.TrimSuffix(logFile, ".log"), seq, name)
+}
+
+// payloadReference stores the payload and returns the text referencing
+// it in the log entry. When the entry goes to the log file, the payload
+// is written to a separate file next to it; otherwise, the payload is
+// included in the entry encoded in base64.
+func payloadReference(s Severity, p Payload) string {
+	data := p.Data
+	var truncated string
+	if len(data) > MaxPayloadSize {
+		data = data[:MaxPayloadSize]
+		truncated = fmt.Sprintf(", truncated from %d bytes", len(p.Data))
+	}
+	if dir, err := logDir.get(); err == nil && s >= logging.fileThreshold.get() {
+		seq := atomic.AddUint64(&payloadSeq, 1)
+		path := filepath.Join(dir, payloadFileName(time.Now(), p.Name, seq))
+		if err := ioutil.WriteFile(path, data, 0644); err == nil {
+			return fmt.Sprintf("\npayload %s (%d bytes%s) written to: %s", p.Name, len(data), truncated, path)
+		}
+	}
+	return fmt.Sprintf("\npayload %s (%d bytes%s, base64): %s",
+		p.Name, len(data), truncated, base64.StdEncoding.EncodeToString(data))
+}
+
+// LogfWithPayload logs a message with the given severity and attaches
+// the payload p to it. The payload is not subject to redaction, so it
+// must not contain user data.
+func LogfWithPayload(
+	ctx context.Context, s Severity, p Payload, format string, args ...interface{},
+) {
+	// The reference is part of the format rather than of the arguments so
+	// that it is not redacted.
+	ref := payloadReference(s, p)
+	logDepth(ctx, 1, s, format+strings.Replace(ref, "%", "%%", -1), args)
+}
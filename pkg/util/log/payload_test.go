@@ -0,0 +1,86 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestPayloadFileName(t *testing.T) {
+	name := payloadFileName(time.Now(), "table/descriptor 52", 7)
+	if !strings.HasSuffix(name, ".payload.7.table_descriptor_52.bin") {
+		t.Errorf("unexpected payload file name %s", name)
+	}
+	if logFileRE.MatchString(name) {
+		t.Errorf("payload file name %s must not match the log file pattern", name)
+	}
+}
+
+func TestLogfWithPayload(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	data := []byte{0, 1, 2, 255}
+	LogfWithPayload(context.Background(), Severity_INFO, Payload{Name: "desc", Data: data}, "found %s", "it")
+	Flush()
+
+	contents, err := ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`found it\npayload desc \(4 bytes\) written to: (\S+)`)
+	m := re.FindStringSubmatch(string(contents))
+	if m == nil {
+		t.Fatalf("entry does not reference the payload:\n%s", contents)
+	}
+	if filepath.Dir(m[1]) != s.logDir {
+		t.Errorf("expected payload in %s, found %s", s.logDir, m[1])
+	}
+	written, err := ioutil.ReadFile(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, data) {
+		t.Errorf("expected payload %v, got %v", data, written)
+	}
+}
+
+func TestPayloadReferenceInline(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	// Entries below the file threshold do not go to the log file, so the
+	// payload is inlined.
+	logging.fileThreshold = Severity_ERROR
+	defer func() { logging.fileThreshold = Severity_INFO }()
+
+	data := bytes.Repeat([]byte{'x'}, MaxPayloadSize+1)
+	ref := payloadReference(Severity_INFO, Payload{Name: "big", Data: data})
+	expected := "\npayload big (65536 bytes, truncated from 65537 bytes, base64): " +
+		base64.StdEncoding.EncodeToString(data[:MaxPayloadSize])
+	if ref != expected {
+		t.Errorf("unexpected payload reference %.100q", ref)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// loggingProfile is a named set of logging parameters that fit a common
+// scenario, so that they do not need to be configured one by one.
+type loggingProfile struct {
+	stderrThreshold    Severity
+	fileThreshold      Severity
+	fileFlushThreshold Severity
+	sinkThreshold      Severity
+	verbosity          level
+	syncWrites         bool
+	// degradeSinks causes the sinks to drop entries below WARNING under
+	// backpressure, regardless of the cluster setting.
+	degradeSinks bool
+	// sinkSampling, if greater than 1, causes only one in sinkSampling of
+	// the entries below WARNING logged at each call site to be sent to the
+	// sinks. The log files and stderr are not sampled.
+	sinkSampling int
+}
+
+var loggingProfiles = map[string]loggingProfile{
+	// production favors throughput and keeps the sinks from falling
+	// behind by sampling the entries below WARNING, and shedding them
+	// under backpressure.
+	"production": {
+		stderrThreshold:    Severity_NONE,
+		fileThreshold:      Severity_INFO,
+		fileFlushThreshold: Severity_WARNING,
+		sinkThreshold:      Severity_INFO,
+		degradeSinks:       true,
+		sinkSampling:       10,
+	},
+	// development shows everything on the terminal as it happens.
+	"development": {
+		stderrThreshold:    Severity_INFO,
+		fileThreshold:      Severity_INFO,
+		fileFlushThreshold: Severity_INFO,
+		sinkThreshold:      Severity_INFO,
+		verbosity:          1,
+	},
+	// debug-incident collects as much detail as possible and makes sure
+	// none of it is lost should the process crash, at the expense of
+	// performance.
+	"debug-incident": {
+		stderrThreshold:    Severity_ERROR,
+		fileThreshold:      Severity_INFO,
+		fileFlushThreshold: Severity_INFO,
+		sinkThreshold:      Severity_INFO,
+		verbosity:          2,
+		syncWrites:         true,
+	},
+	// minimal only keeps track of problems.
+	"minimal": {
+		stderrThreshold:    Severity_NONE,
+		fileThreshold:      Severity_WARNING,
+		fileFlushThreshold: Severity_ERROR,
+		sinkThreshold:      Severity_ERROR,
+		degradeSinks:       true,
+	},
+}
+
+func validateLoggingProfile(name string) error {
+	if _, ok := loggingProfiles[name]; ok {
+		return nil
+	}
+	var names []string
+	for name := range loggingProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown logging profile %q, expected one of: %s",
+		name, strings.Join(names, ", "))
+}
+
+// profileFlag is the flag.Value which selects a logging profile. The
+// profile is applied when the flag is parsed, so that the flags given
+// after it on the command line override the parameters of the profile.
+type profileFlag struct {
+	name string
+}
+
+var logProfile profileFlag
+
+// String is part of the flag.Value interface.
+func (f *profileFlag) String() string {
+	return f.name
+}
+
+// Set is part of the flag.Value interface.
+func (f *profileFlag) Set(value string) error {
+	if err := validateLoggingProfile(value); err != nil {
+		return err
+	}
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	loggingProfiles[value].applyLocked()
+	f.name = value
+	return nil
+}
+
+// profileSetting selects the logging profile of the nodes which were not
+// started with --log-profile, whose profile takes precedence.
+var profileSetting = settings.RegisterValidatedStringSetting(
+	"log.profile",
+	"logging profile applied to the nodes not started with --log-profile "+
+		"(production, development, debug-incident, minimal, or empty for none)",
+	"",
+	func(name string) error {
+		if name == "" {
+			return nil
+		}
+		return validateLoggingProfile(name)
+	},
+)
+
+func init() {
+	profileSetting.OnChange(applyProfileSetting)
+}
+
+// settingProfile is the state of the profile selected by the log.profile
+// setting. It is accessed under logging.mu.
+var settingProfile struct {
+	name string
+	// saved is the configuration in effect before the setting selected a
+	// profile, which is restored when the setting is cleared.
+	saved *savedLoggingConfig
+}
+
+// applyProfileSetting applies the profile selected by the log.profile
+// setting, or restores the previous configuration when it is cleared.
+func applyProfileSetting() {
+	name := profileSetting.Get()
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	if logProfile.name != "" || name == settingProfile.name {
+		return
+	}
+	if name == "" {
+		if settingProfile.saved != nil {
+			settingProfile.saved.restoreLocked()
+			settingProfile.saved = nil
+		}
+	} else {
+		if settingProfile.saved == nil {
+			settingProfile.saved = saveLoggingConfigLocked()
+		}
+		loggingProfiles[name].applyLocked()
+	}
+	settingProfile.name = name
+}
+
+// effectiveProfileLocked returns the name of the profile in effect, if
+// any. logging.mu is held.
+func effectiveProfileLocked() string {
+	if logProfile.name != "" {
+		return logProfile.name
+	}
+	return settingProfile.name
+}
+
+// applyLocked configures logging according to the profile. logging.mu is
+// held.
+func (p loggingProfile) applyLocked() {
+	logging.stderrThreshold.set(p.stderrThreshold)
+	logging.fileThreshold.set(p.fileThreshold)
+	logging.fileFlushThreshold.set(p.fileFlushThreshold)
+	logging.syncWrites = p.syncWrites
+	logging.degradeSinks = p.degradeSinks
+	logging.sinkSampling = p.sinkSampling
+	logging.setVState(p.verbosity, logging.vmodule.filter, false)
+	fifoSinkThreshold.set(p.sinkThreshold)
+	netSinkThreshold.set(p.sinkThreshold)
+	teeSinkThreshold.set(p.sinkThreshold)
+	syncSinkThresholdsLocked()
+}
+
+// syncSinkThresholdsLocked updates the thresholds of the existing sinks
+// configured by flags, which are created with the thresholds of the flags
+// when the first entry is logged. logging.mu is held.
+func syncSinkThresholdsLocked() {
+	for i, c := range logging.sinks {
+		if c.flagThreshold != nil {
+			logging.sinks[i].threshold = c.flagThreshold.get()
+		}
+	}
+}
+
+// savedLoggingConfig is the part of the logging configuration which
+// profiles change.
+type savedLoggingConfig struct {
+	profile        loggingProfile
+	fifo, net, tee Severity
+}
+
+// saveLoggingConfigLocked returns the configuration which profiles
+// change. logging.mu is held.
+func saveLoggingConfigLocked() *savedLoggingConfig {
+	return &savedLoggingConfig{
+		profile: loggingProfile{
+			stderrThreshold:    logging.stderrThreshold.get(),
+			fileThreshold:      logging.fileThreshold.get(),
+			fileFlushThreshold: logging.fileFlushThreshold.get(),
+			verbosity:          logging.verbosity.get(),
+			syncWrites:         logging.syncWrites,
+			degradeSinks:       logging.degradeSinks,
+			sinkSampling:       logging.sinkSampling,
+		},
+		fifo: fifoSinkThreshold.get(),
+		net:  netSinkThreshold.get(),
+		tee:  teeSinkThreshold.get(),
+	}
+}
+
+// restoreLocked restores the saved configuration. logging.mu is held.
+func (c *savedLoggingConfig) restoreLocked() {
+	c.profile.applyLocked()
+	fifoSinkThreshold.set(c.fifo)
+	netSinkThreshold.set(c.net)
+	teeSinkThreshold.set(c.tee)
+	syncSinkThresholdsLocked()
+}
+
+// sampledOut returns true if the entry must not be sent to the sinks
+// because of the sampling of the profile in effect. logging.mu is held.
+func (l *loggingT) sampledOut(entry Entry) bool {
+	if l.sinkSampling <= 1 || entry.Severity >= Severity_WARNING {
+		return false
+	}
+	if l.sinkSamples == nil {
+		l.sinkSamples = make(map[callSite]int)
+	}
+	site := callSite{file: entry.File, line: entry.Line}
+	n := l.sinkSamples[site]
+	l.sinkSamples[site] = n + 1
+	return n%l.sinkSampling != 0
+}
+
+// callSite identifies the place where an entry is logged.
+type callSite struct {
+	file string
+	line int64
+}
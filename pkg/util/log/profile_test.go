@@ -0,0 +1,164 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// saveProfileState returns a function restoring the logging
+// configuration which profiles change.
+func saveProfileState() func() {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	saved := saveLoggingConfigLocked()
+	flagProfile, setting := logProfile, settingProfile
+	return func() {
+		logging.mu.Lock()
+		defer logging.mu.Unlock()
+		saved.restoreLocked()
+		logProfile, settingProfile = flagProfile, setting
+		logging.sinkSamples = nil
+	}
+}
+
+func TestLoggingProfile(t *testing.T) {
+	defer saveProfileState()()
+
+	if err := logProfile.Set("debug-incident"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := GetEffectiveConfig()
+	if cfg.Profile != "debug-incident" || cfg.Verbosity != 2 || !cfg.SyncWrites ||
+		cfg.FileFlushThreshold != Severity_INFO.String() {
+		t.Errorf("profile not applied: %+v", cfg)
+	}
+
+	// Flags parsed after the profile override it.
+	if err := logging.verbosity.Set("0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logProfile.Set("minimal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logging.fileThreshold.Set("ERROR"); err != nil {
+		t.Fatal(err)
+	}
+	cfg = GetEffectiveConfig()
+	if cfg.FileThreshold != Severity_ERROR.String() || cfg.SyncWrites ||
+		fifoSinkThreshold != Severity_ERROR {
+		t.Errorf("unexpected configuration: %+v", cfg)
+	}
+	logging.mu.Lock()
+	degrade := logging.degradeSinks
+	logging.mu.Unlock()
+	if !degrade {
+		t.Error("expected the minimal profile to shed entries under backpressure")
+	}
+
+	if err := logProfile.Set("unknown"); err == nil ||
+		!strings.Contains(err.Error(), "expected one of: debug-incident, development, minimal, production") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLoggingProfileSetting(t *testing.T) {
+	defer saveProfileState()()
+
+	// A sink configured by flags, already created.
+	sink := &testSink{name: "fifo"}
+	logging.mu.Lock()
+	logProfile.name = ""
+	sinks := logging.sinks
+	logging.sinks = append(sinks[:len(sinks):len(sinks)], sinkConfig{
+		sink:          sink,
+		threshold:     fifoSinkThreshold.get(),
+		flagThreshold: &fifoSinkThreshold,
+	})
+	logging.mu.Unlock()
+	defer func() {
+		logging.mu.Lock()
+		logging.sinks = sinks
+		logging.mu.Unlock()
+	}()
+	sinkThreshold := func() Severity {
+		logging.mu.Lock()
+		defer logging.mu.Unlock()
+		return logging.sinks[len(logging.sinks)-1].threshold
+	}
+	before := GetEffectiveConfig()
+	beforeSink := sinkThreshold()
+
+	defer settings.TestingSetString(&profileSetting, "minimal")()
+	applyProfileSetting()
+	cfg := GetEffectiveConfig()
+	if cfg.Profile != "minimal" || cfg.FileThreshold != Severity_WARNING.String() {
+		t.Errorf("profile not applied: %+v", cfg)
+	}
+	if th := sinkThreshold(); th != Severity_ERROR {
+		t.Errorf("expected the existing sink to use the threshold of the profile, got %s", th)
+	}
+
+	// Clearing the setting restores the previous configuration.
+	defer settings.TestingSetString(&profileSetting, "")()
+	applyProfileSetting()
+	if cfg := GetEffectiveConfig(); cfg != before {
+		t.Errorf("expected %+v, got %+v", before, cfg)
+	}
+	if th := sinkThreshold(); th != beforeSink {
+		t.Errorf("expected the sink threshold to be restored to %s, got %s", beforeSink, th)
+	}
+
+	// The profile selected with --log-profile takes precedence.
+	if err := logProfile.Set("development"); err != nil {
+		t.Fatal(err)
+	}
+	defer settings.TestingSetString(&profileSetting, "minimal")()
+	applyProfileSetting()
+	if cfg := GetEffectiveConfig(); cfg.Profile != "development" || cfg.FileThreshold != Severity_INFO.String() {
+		t.Errorf("expected the development profile to remain in effect: %+v", cfg)
+	}
+}
+
+func TestLoggingProfileSampling(t *testing.T) {
+	defer saveProfileState()()
+
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	logging.sinkSampling = 3
+	var sent []bool
+	for i := 0; i < 4; i++ {
+		sent = append(sent,
+			!logging.sampledOut(Entry{Severity: Severity_INFO, File: "a.go", Line: 1}),
+			!logging.sampledOut(Entry{Severity: Severity_INFO, File: "a.go", Line: 2}),
+			!logging.sampledOut(Entry{Severity: Severity_WARNING, File: "a.go", Line: 3}))
+	}
+	// Each call site is sampled independently, and warnings are not
+	// sampled.
+	expected := []bool{
+		true, true, true,
+		false, false, true,
+		false, false, true,
+		true, true, true,
+	}
+	for i := range expected {
+		if sent[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, sent)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// profilerLabelKey is the key of the pprof label which attributes the
+// CPU time spent by the logging package in CPU profiles. Its values are
+// the activities below.
+const profilerLabelKey = "log"
+
+// The activities attributed in CPU profiles.
+const (
+	profilerLabelOutput      = "output"
+	profilerLabelFlush       = "flush"
+	profilerLabelGC          = "gc"
+	profilerLabelSink        = "sink"
+	profilerLabelCrashReport = "crash-report"
+)
+
+// profilerLabelsEnabled governs the labeling of the logging fast path,
+// which costs an allocation per entry. The goroutines of the logging
+// package are labeled regardless.
+var profilerLabelsEnabled = settings.RegisterBoolSetting(
+	"log.profiler_labels.enabled",
+	"label the formatting and output of log entries in CPU profiles",
+	true,
+)
+
+// withOutputLabels runs fn, which formats and outputs an entry logged
+// within ctx, labeled as such in CPU profiles if enabled.
+func withOutputLabels(ctx context.Context, fn func()) {
+	if !profilerLabelsEnabled.Get() {
+		fn()
+		return
+	}
+	withProfilerLabels(ctx, profilerLabelOutput, fn)
+}
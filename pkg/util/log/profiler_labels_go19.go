@@ -0,0 +1,44 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build go1.9
+
+package log
+
+import (
+	"runtime/pprof"
+
+	"golang.org/x/net/context"
+)
+
+// profilerLabelsContext returns a context carrying the labels of ctx and
+// the label of the given activity.
+func profilerLabelsContext(ctx context.Context, activity string) context.Context {
+	return pprof.WithLabels(ctx, pprof.Labels(profilerLabelKey, activity))
+}
+
+// withProfilerLabels runs fn with the goroutine labeled with the labels
+// of ctx and the given activity, and restores the labels of ctx
+// afterwards.
+func withProfilerLabels(ctx context.Context, activity string, fn func()) {
+	pprof.SetGoroutineLabels(profilerLabelsContext(ctx, activity))
+	defer pprof.SetGoroutineLabels(ctx)
+	fn()
+}
+
+// setProfilerLabels labels the calling goroutine, which is dedicated to
+// the given activity.
+func setProfilerLabels(activity string) {
+	pprof.SetGoroutineLabels(profilerLabelsContext(context.Background(), activity))
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build go1.9
+
+package log
+
+import (
+	"runtime/pprof"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestProfilerLabelsContext(t *testing.T) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("user", "root"))
+	ctx = profilerLabelsContext(ctx, profilerLabelOutput)
+	if v, _ := pprof.Label(ctx, profilerLabelKey); v != profilerLabelOutput {
+		t.Errorf("expected the %s activity, got %q", profilerLabelOutput, v)
+	}
+	if v, _ := pprof.Label(ctx, "user"); v != "root" {
+		t.Errorf("expected the labels of the context to be kept, got %q", v)
+	}
+
+	var ran bool
+	withProfilerLabels(ctx, profilerLabelFlush, func() { ran = true })
+	if !ran {
+		t.Error("expected the function to run")
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !go1.9
+
+package log
+
+import "golang.org/x/net/context"
+
+// Profiler labels require go1.9.
+
+func withProfilerLabels(ctx context.Context, activity string, fn func()) {
+	fn()
+}
+
+func setProfilerLabels(activity string) {}
@@ -0,0 +1,84 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"syscall"
+
+	"golang.org/x/net/context"
+)
+
+// IsReadOnlyError returns true if err is the failure to write to a
+// path which is read-only, either because of its permissions or because
+// its file system is mounted read-only.
+func IsReadOnlyError(err error) bool {
+	if os.IsPermission(err) {
+		return true
+	}
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	case *os.SyscallError:
+		err = e.Err
+	}
+	return err == syscall.EROFS
+}
+
+// probeLogDir returns an error if no file can be created in dir. It is
+// overridden in tests.
+var probeLogDir = func(dir string) error {
+	f, err := ioutil.TempFile(dir, ".probe")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+// FallBackIfLogDirReadOnly checks that log files can be created in the
+// log directory. If they can't because the directory is read-only, as is
+// common in locked-down containers, the log files are disabled and the
+// entries they would have received are written to stderr instead, rather
+// than failing the writes once the process runs. The fallback is
+// reported on the ops channel and in the effective configuration. It
+// returns true if the fallback was activated.
+func FallBackIfLogDirReadOnly(ctx context.Context) bool {
+	dir, err := logDir.get()
+	if err != nil {
+		return false
+	}
+	err = probeLogDir(dir)
+	if err == nil || !IsReadOnlyError(err) {
+		// Other errors are reported when the log files are created.
+		return false
+	}
+	reason := fmt.Sprintf("log directory %s is read-only: %s", dir, err)
+	logging.mu.Lock()
+	logging.fileFallback = reason
+	if fileThreshold := logging.fileThreshold.get(); logging.stderrThreshold.get() > fileThreshold {
+		logging.stderrThreshold.set(fileThreshold)
+	}
+	logging.mu.Unlock()
+	// Disabling the log directory disables the log files, and everything
+	// else which writes to the directory.
+	_ = logDir.Set("")
+	Warningf(WithLogTag(ctx, opsTag, nil), "%s; logging to stderr instead", reason)
+	return true
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestIsReadOnlyError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{&os.PathError{Op: "open", Path: "/logs/x", Err: syscall.EROFS}, true},
+		{&os.PathError{Op: "mkdir", Path: "/logs", Err: syscall.EACCES}, true},
+		{os.NewSyscallError("open", syscall.EROFS), true},
+		{&os.PathError{Op: "open", Path: "/logs/x", Err: syscall.ENOSPC}, false},
+		{errors.New("read-only"), false},
+	}
+	for _, tc := range testCases {
+		if actual := IsReadOnlyError(tc.err); actual != tc.expected {
+			t.Errorf("%v: expected %t, got %t", tc.err, tc.expected, actual)
+		}
+	}
+}
+
+func TestFallBackIfLogDirReadOnly(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer setFlags()
+
+	if FallBackIfLogDirReadOnly(context.Background()) {
+		t.Fatal("expected no fallback for a writable log directory")
+	}
+
+	defer func(probe func(string) error) { probeLogDir = probe }(probeLogDir)
+	probeLogDir = func(dir string) error {
+		return &os.PathError{Op: "open", Path: dir, Err: syscall.EROFS}
+	}
+	defer func() {
+		logging.mu.Lock()
+		logging.fileFallback = ""
+		logging.mu.Unlock()
+		// The scope expects to find its log directory.
+		if err := logDir.Set(s.logDir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	logging.stderrThreshold = Severity_NONE
+	defer func() { logging.stderrThreshold = Severity_ERROR }()
+
+	var c LogCapture
+	ctx := WithLogCapture(context.Background(), &c)
+	c.Start()
+	if !FallBackIfLogDirReadOnly(ctx) {
+		t.Fatal("expected a fallback for a read-only log directory")
+	}
+	c.Stop()
+
+	if DirSet() {
+		t.Error("expected the log files to be disabled")
+	}
+	if logging.stderrThreshold.get() != logging.fileThreshold.get() {
+		t.Errorf("expected the entries of the log files to go to stderr, got threshold %s",
+			logging.stderrThreshold.get())
+	}
+	cfg := GetEffectiveConfig()
+	if !strings.Contains(cfg.FileFallback, s.logDir) || !strings.Contains(cfg.FileFallback, "read-only") {
+		t.Errorf("expected the fallback to be reported, got %q", cfg.FileFallback)
+	}
+	entries, _ := c.Entries()
+	if len(entries) != 1 || entries[0].Severity != Severity_WARNING ||
+		!strings.HasPrefix(entries[0].Message, "["+opsTag+"] log directory") {
+		t.Errorf("expected a warning on the ops channel, got %+v", entries)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// recentEntriesSize is the number of entries retained in memory for
+// GetRecentEntries.
+const recentEntriesSize = 256
+
+// recentEntriesThreshold is the minimum severity of the entries retained
+// for GetRecentEntries.
+const recentEntriesThreshold = Severity_WARNING
+
+// recentEntries is a ring buffer of the latest entries logged at or above
+// recentEntriesThreshold, which allows surfacing recent problems without
+// reading the log files.
+type recentEntries struct {
+	buf [recentEntriesSize]Entry
+	// next is the index in buf of the next entry to be recorded, and n the
+	// number of entries recorded, up to recentEntriesSize.
+	next, n int
+}
+
+func (r *recentEntries) add(entry Entry) {
+	r.buf[r.next] = entry
+	r.next = (r.next + 1) % recentEntriesSize
+	if r.n < recentEntriesSize {
+		r.n++
+	}
+}
+
+// since returns the recorded entries more recent than the given time, in
+// the order in which they were logged.
+func (r *recentEntries) since(t int64) []Entry {
+	var entries []Entry
+	for i := 0; i < r.n; i++ {
+		e := r.buf[(r.next-r.n+i+recentEntriesSize)%recentEntriesSize]
+		if e.Time > t {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// GetRecentEntries returns the latest entries of severity WARNING or
+// above which were logged after the given time, expressed in nanoseconds
+// since the epoch, in the order in which they were logged. At most a few
+// hundred entries are retained.
+func GetRecentEntries(since int64) []Entry {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	return logging.recent.since(since)
+}
+
+const httpRecentEntriesPath = "/debug/logs/recent"
+
+// handleRecentEntries serves the recent entries as JSON. The optional
+// "since" parameter, in nanoseconds since the epoch, lets pollers only
+// retrieve the entries they have not seen yet.
+func handleRecentEntries(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if s := r.URL.Query().Get("since"); s != "" {
+		var err error
+		if since, err = strconv.ParseInt(s, 10, 64); err != nil {
+			http.Error(w, "invalid since parameter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	entries := GetRecentEntries(since)
+	if entries == nil {
+		entries = []Entry{}
+	}
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestRecentEntriesRing(t *testing.T) {
+	var r recentEntries
+	for i := 1; i <= recentEntriesSize+10; i++ {
+		r.add(Entry{Time: int64(i), Message: fmt.Sprint(i)})
+	}
+	entries := r.since(0)
+	if len(entries) != recentEntriesSize {
+		t.Fatalf("expected %d entries, got %d", recentEntriesSize, len(entries))
+	}
+	if first, last := entries[0].Time, entries[len(entries)-1].Time; first != 11 ||
+		last != recentEntriesSize+10 {
+		t.Errorf("expected entries 11 to %d, got %d to %d", recentEntriesSize+10, first, last)
+	}
+	if entries := r.since(recentEntriesSize + 8); len(entries) != 2 {
+		t.Errorf("expected 2 entries, got %+v", entries)
+	}
+}
+
+func TestRecentEntries(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	ctx := context.Background()
+	// Earlier tests may have filled the ring, so only look at the entries
+	// logged from now on.
+	start := time.Now().UnixNano() - 1
+	Info(ctx, "unremarkable")
+	Warning(ctx, "recent problem")
+
+	w := httptest.NewRecorder()
+	handleRecentEntries(w, httptest.NewRequest("GET",
+		fmt.Sprintf("%s?since=%d", httpRecentEntriesPath, start), nil))
+	var entries []Entry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("%s: %s", err, w.Body.String())
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one new entry, got %+v", entries)
+	}
+	last := entries[0]
+	if last.Severity != Severity_WARNING || last.Message != "recent problem" {
+		t.Errorf("unexpected entry %+v", last)
+	}
+
+	// Pollers only get the entries they have not seen.
+	w = httptest.NewRecorder()
+	handleRecentEntries(w, httptest.NewRequest("GET",
+		fmt.Sprintf("%s?since=%d", httpRecentEntriesPath, last.Time), nil))
+	if body := w.Body.String(); body != "[]\n" {
+		t.Errorf("expected no entries, got %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	handleRecentEntries(w, httptest.NewRequest("GET", httpRecentEntriesPath+"?since=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
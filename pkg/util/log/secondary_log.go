@@ -0,0 +1,167 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/petermattis/goid"
+)
+
+// secondaryLogFile writes the log files of a channel kept apart from the
+// main log files, such as the audit log, to a subdirectory of the log
+// directory. The files are rotated according to a policy of their own.
+// The owner of a secondaryLogFile serializes the calls to its methods.
+type secondaryLogFile struct {
+	// name identifies the channel in the name of the subdirectory.
+	name string
+	// maxSize and combinedMaxSize point to the maximum size in bytes of a
+	// file, and of all the files, past which the oldest are removed. A zero
+	// combined size means that files are never removed. Both are accessed
+	// atomically.
+	maxSize, combinedMaxSize *int64
+
+	// dir is the directory of the files, set by setDir.
+	dir          string
+	file         *os.File
+	nbytes       int64
+	lastRotation int64
+}
+
+// secondaryLogDir returns the path of the directory of the files of the
+// channel name, in the log directory.
+func secondaryLogDir(name string) (string, error) {
+	dir, err := logDir.get()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, removePeriods(program)+"."+name), nil
+}
+
+// setDir switches to the directory of the files in the current log
+// directory, creating it if needed. The boolean return value is true if
+// it differs from the directory of the files written so far, if any,
+// which are closed. errDirectoryNotSet is returned without a log
+// directory.
+func (s *secondaryLogFile) setDir() (bool, error) {
+	dir, err := secondaryLogDir(s.name)
+	if err != nil {
+		return false, err
+	}
+	if dir == s.dir {
+		return false, nil
+	}
+	if s.file != nil {
+		_ = s.file.Close()
+	}
+	s.dir, s.file, s.nbytes, s.lastRotation = "", nil, 0, 0
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+	s.dir = dir
+	return true, nil
+}
+
+// writeEntry appends an entry with message msg, logged at time t from
+// file:line, to the current file. setDir must have been called.
+func (s *secondaryLogFile) writeEntry(t time.Time, file string, line int, msg string) error {
+	buf := formatLogEntry(Entry{
+		Severity:  Severity_INFO,
+		Time:      t.UnixNano(),
+		Goroutine: goid.Get(),
+		File:      file,
+		Line:      int64(line),
+		Message:   msg,
+	}, nil, nil)
+	defer logging.putBuffer(buf)
+	return s.write(buf.Bytes())
+}
+
+// write appends data, a formatted entry, to the current file, after
+// rotating it if it would otherwise exceed the maximum size.
+func (s *secondaryLogFile) write(data []byte) error {
+	if s.file == nil || s.nbytes+int64(len(data)) >= atomic.LoadInt64(s.maxSize) {
+		if err := s.rotate(time.Now()); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(data)
+	s.nbytes += int64(n)
+	return err
+}
+
+// sync commits the current file to stable storage.
+func (s *secondaryLogFile) sync() error {
+	return s.file.Sync()
+}
+
+// rotate closes the current file, if any, starts a new one and removes
+// the oldest files in excess of the combined maximum size.
+func (s *secondaryLogFile) rotate(now time.Time) error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		s.file = nil
+	}
+	// Ensure that the timestamp of the new file name is greater than
+	// the timestamp of the previous generated file name.
+	unix := now.Unix()
+	if unix <= s.lastRotation {
+		unix = s.lastRotation + 1
+	}
+	s.lastRotation = unix
+	name, _ := logName(time.Unix(unix, 0))
+	f, err := os.OpenFile(filepath.Join(s.dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	s.file, s.nbytes = f, 0
+	s.gcOldFiles()
+	return nil
+}
+
+// gcOldFiles removes the oldest files in excess of the combined maximum
+// size, always keeping the current file.
+func (s *secondaryLogFile) gcOldFiles() {
+	maxSize := atomic.LoadInt64(s.combinedMaxSize)
+	if maxSize <= 0 {
+		return
+	}
+	files, err := listLogFiles(s.dir)
+	if err != nil {
+		metaLogf("gc", "unable to GC %s log files: %s", s.name, err)
+		return
+	}
+	files = selectFiles(files, math.MaxInt64)
+	if len(files) == 0 {
+		return
+	}
+	sum := files[0].SizeBytes
+	for _, f := range files[1:] {
+		sum += f.SizeBytes
+		if sum < maxSize {
+			continue
+		}
+		path := filepath.Join(s.dir, f.Name)
+		if err := os.Remove(path); err != nil {
+			metaLogf("gc", "unable to remove %s log file %s: %s", s.name, path, err)
+		}
+	}
+}
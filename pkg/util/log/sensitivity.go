@@ -0,0 +1,39 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import "golang.org/x/net/context"
+
+// ctxSensitivityKey is the key of the Sensitivity embedded in a context.
+type ctxSensitivityKey struct{}
+
+// WithSensitivity returns a context (derived from the given context) in
+// which the entries logged are tagged with the given class, so that
+// downstream pipelines can route them, for instance to keep the
+// restricted entries away from the destinations shared with support.
+//
+// The class is carried by the Entry protos handed to the sinks,
+// interceptors, log captures and the recent entries endpoint, but not by
+// the text format of the log files.
+func WithSensitivity(ctx context.Context, s Sensitivity) context.Context {
+	return context.WithValue(ctx, ctxSensitivityKey{}, s)
+}
+
+// SensitivityFromContext returns the class of the entries logged within
+// ctx, UNCLASSIFIED if none was set.
+func SensitivityFromContext(ctx context.Context) Sensitivity {
+	s, _ := ctx.Value(ctxSensitivityKey{}).(Sensitivity)
+	return s
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestSensitivity(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	var seen, seenRedacted []Sensitivity
+	defer RegisterEntryInterceptor("sensitivity", func(e, r *Entry) bool {
+		seen = append(seen, e.Sensitivity)
+		seenRedacted = append(seenRedacted, r.Sensitivity)
+		return true
+	})()
+
+	var c LogCapture
+	ctx := WithLogCapture(context.Background(), &c)
+	c.Start()
+	Info(ctx, "unclassified")
+	Info(WithSensitivity(ctx, Sensitivity_RESTRICTED), "restricted")
+	Info(WithSensitivity(WithSensitivity(ctx, Sensitivity_RESTRICTED), Sensitivity_PUBLIC), "public")
+	c.Stop()
+
+	expected := []Sensitivity{Sensitivity_UNCLASSIFIED, Sensitivity_RESTRICTED, Sensitivity_PUBLIC}
+	entries, _ := c.Entries()
+	if len(seen) != len(expected) || len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %v and %+v", len(expected), seen, entries)
+	}
+	for i, s := range expected {
+		if seen[i] != s || seenRedacted[i] != s || entries[i].Sensitivity != s {
+			t.Errorf("%d: expected %s, got %s, %s and %s",
+				i, s, seen[i], seenRedacted[i], entries[i].Sensitivity)
+		}
+	}
+
+	// The class survives the protobuf encoding of the entry.
+	entry := entries[1]
+	data, err := entry.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Entry
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != entry {
+		t.Errorf("expected %+v, got %+v", entry, decoded)
+	}
+}
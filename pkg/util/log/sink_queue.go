@@ -0,0 +1,320 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// errSinkQueueFull is returned when an entry is dropped because the
+// destination of a sink does not keep up.
+var errSinkQueueFull = errors.New("log: sink buffer full, entry dropped")
+
+// sinkQueue holds the entries accepted by an asynchronous sink until
+// they are delivered. Entries are pushed by the loggers, and consumed by
+// a single goroutine delivering them, which peeks at the oldest entry
+// and pops it once it is delivered.
+type sinkQueue interface {
+	// push adds an entry to the queue, or returns errSinkQueueFull.
+	push(data []byte) error
+	// peek waits for an entry and returns the oldest one. The boolean
+	// return value is false if stopper was closed first.
+	peek(stopper <-chan struct{}) ([]byte, bool, error)
+	// pop removes the oldest entry.
+	pop() error
+	// length returns the number of queued entries.
+	length() int
+	// fill returns the fraction of the capacity of the queue in use.
+	fill() float64
+}
+
+// memQueue is a bounded sinkQueue in memory.
+type memQueue struct {
+	entries chan []byte
+	// pending is the entry returned by the last call to peek. It is only
+	// accessed by the consumer.
+	pending []byte
+	// npending is 1 if pending is set. Accessed atomically.
+	npending int32
+}
+
+var _ sinkQueue = &memQueue{}
+
+func newMemQueue(size int) *memQueue {
+	return &memQueue{entries: make(chan []byte, size)}
+}
+
+func (q *memQueue) push(data []byte) error {
+	select {
+	case q.entries <- append([]byte(nil), data...):
+		return nil
+	default:
+		return errSinkQueueFull
+	}
+}
+
+func (q *memQueue) peek(stopper <-chan struct{}) ([]byte, bool, error) {
+	if q.pending == nil {
+		select {
+		case q.pending = <-q.entries:
+			atomic.StoreInt32(&q.npending, 1)
+		case <-stopper:
+			return nil, false, nil
+		}
+	}
+	return q.pending, true, nil
+}
+
+func (q *memQueue) pop() error {
+	q.pending = nil
+	atomic.StoreInt32(&q.npending, 0)
+	return nil
+}
+
+func (q *memQueue) length() int {
+	return len(q.entries) + int(atomic.LoadInt32(&q.npending))
+}
+
+func (q *memQueue) fill() float64 {
+	return float64(q.length()) / float64(cap(q.entries))
+}
+
+// diskQueue is a bounded sinkQueue spooled to disk, which provides
+// at-least-once delivery across reconnections and process restarts.
+// Entries are appended to a data file, prefixed by their length. The
+// offset of the oldest entry not yet delivered is persisted in a
+// separate file, so that delivery resumes where it stopped. The data
+// file is truncated whenever all its entries have been delivered.
+//
+// The offset is only persisted every diskQueueOffsetSyncEntries
+// delivered entries, when the data file is truncated and when the queue
+// is closed, so a crash can cause a few entries to be delivered again.
+type diskQueue struct {
+	dataPath, offsetPath string
+	maxSize              int64
+	notify               chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		f *os.File
+		// size is the size of the data file, offset that of the oldest
+		// entry, and count the number of entries after offset.
+		size, offset int64
+		count        int
+		// unsynced is the number of entries popped since the offset was
+		// last persisted.
+		unsynced int
+	}
+}
+
+// diskQueueOffsetSyncEntries is the number of delivered entries after
+// which the offset of a diskQueue is persisted.
+const diskQueueOffsetSyncEntries = 64
+
+var _ sinkQueue = &diskQueue{}
+
+// openDiskQueue opens the spool with the given path prefix, creating it
+// if it does not exist, and resumes from the persisted offset.
+func openDiskQueue(path string, maxSize int64) (*diskQueue, error) {
+	q := &diskQueue{
+		dataPath:   path,
+		offsetPath: path + ".offset",
+		maxSize:    maxSize,
+		notify:     make(chan struct{}, 1),
+	}
+	f, err := os.OpenFile(q.dataPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	q.mu.f = f
+	q.mu.size = info.Size()
+	if data, err := ioutil.ReadFile(q.offsetPath); err == nil {
+		offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err == nil && offset >= 0 && offset <= q.mu.size {
+			q.mu.offset = offset
+		}
+	} else if !os.IsNotExist(err) {
+		_ = f.Close()
+		return nil, err
+	}
+	// Count the entries left over by a previous process. A partially
+	// written trailing entry is discarded.
+	for pos := q.mu.offset; pos < q.mu.size; {
+		n, err := q.entryLenAtLocked(pos)
+		if err != nil || pos+4+n > q.mu.size {
+			q.mu.size = pos
+			if err := f.Truncate(pos); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			break
+		}
+		pos += 4 + n
+		q.mu.count++
+	}
+	if q.mu.count > 0 {
+		q.notify <- struct{}{}
+	}
+	return q, nil
+}
+
+func (q *diskQueue) entryLenAtLocked(pos int64) (int64, error) {
+	var hdr [4]byte
+	if _, err := q.mu.f.ReadAt(hdr[:], pos); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint32(hdr[:])), nil
+}
+
+func (q *diskQueue) push(data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.mu.size+4+int64(len(data)) > q.maxSize {
+		return errSinkQueueFull
+	}
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	if _, err := q.mu.f.WriteAt(buf, q.mu.size); err != nil {
+		return err
+	}
+	q.mu.size += int64(len(buf))
+	q.mu.count++
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *diskQueue) peek(stopper <-chan struct{}) ([]byte, bool, error) {
+	for {
+		q.mu.Lock()
+		if q.mu.count > 0 {
+			defer q.mu.Unlock()
+			n, err := q.entryLenAtLocked(q.mu.offset)
+			if err != nil {
+				return nil, false, err
+			}
+			data := make([]byte, n)
+			if _, err := q.mu.f.ReadAt(data, q.mu.offset+4); err != nil && err != io.EOF {
+				return nil, false, err
+			}
+			return data, true, nil
+		}
+		q.mu.Unlock()
+		select {
+		case <-q.notify:
+		case <-stopper:
+			return nil, false, nil
+		}
+	}
+}
+
+func (q *diskQueue) pop() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.mu.count == 0 {
+		return nil
+	}
+	n, err := q.entryLenAtLocked(q.mu.offset)
+	if err != nil {
+		return err
+	}
+	q.mu.offset += 4 + n
+	q.mu.count--
+	q.mu.unsynced++
+	if q.mu.count == 0 {
+		// Everything was delivered: reclaim the space. The offset must be
+		// persisted before new entries are appended, otherwise a stale
+		// offset would skip them after a restart.
+		if err := q.mu.f.Truncate(0); err != nil {
+			return err
+		}
+		q.mu.size, q.mu.offset = 0, 0
+		return q.syncOffsetLocked()
+	}
+	if q.mu.unsynced >= diskQueueOffsetSyncEntries {
+		return q.syncOffsetLocked()
+	}
+	return nil
+}
+
+// syncOffsetLocked persists the offset of the oldest entry. The offset
+// file is replaced atomically, so that a crash leaves either the old or
+// the new offset.
+func (q *diskQueue) syncOffsetLocked() error {
+	tmpPath := q.offsetPath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strconv.FormatInt(q.mu.offset, 10)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, q.offsetPath); err != nil {
+		return err
+	}
+	q.mu.unsynced = 0
+	return nil
+}
+
+func (q *diskQueue) length() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.mu.count
+}
+
+func (q *diskQueue) fill() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return float64(q.mu.size) / float64(q.maxSize)
+}
+
+// close persists the offset and closes the spool. Undelivered entries
+// remain on disk.
+func (q *diskQueue) close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.mu.unsynced > 0 {
+		if err := q.syncOffsetLocked(); err != nil {
+			_ = q.mu.f.Close()
+			return err
+		}
+	}
+	return q.mu.f.Close()
+}
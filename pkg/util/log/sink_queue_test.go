@@ -0,0 +1,194 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestMemQueue(t *testing.T) {
+	q := newMemQueue(2)
+	for _, e := range []string{"a", "b"} {
+		if err := q.push([]byte(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.push([]byte("c")); err != errSinkQueueFull {
+		t.Errorf("expected %v, got %v", errSinkQueueFull, err)
+	}
+	stopper := make(chan struct{})
+	peek := func(expected string) {
+		data, ok, err := q.peek(stopper)
+		if err != nil || !ok {
+			t.Fatalf("unexpected peek result %t, %v", ok, err)
+		}
+		if string(data) != expected {
+			t.Errorf("expected %s, got %s", expected, data)
+		}
+	}
+	// Peeking returns the same entry until it is popped.
+	peek("a")
+	peek("a")
+	if n := q.length(); n != 2 {
+		t.Errorf("expected 2 entries, got %d", n)
+	}
+	if err := q.pop(); err != nil {
+		t.Fatal(err)
+	}
+	peek("b")
+	if err := q.pop(); err != nil {
+		t.Fatal(err)
+	}
+	close(stopper)
+	if _, ok, _ := q.peek(stopper); ok {
+		t.Error("expected peek to return once stopped")
+	}
+}
+
+func TestDiskQueue(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	path := filepath.Join(s.logDir, "spool")
+	q, err := openDiskQueue(path, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []string{"first", "second"} {
+		if err := q.push([]byte(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.push([]byte("third")); err != errSinkQueueFull {
+		t.Errorf("expected %v, got %v", errSinkQueueFull, err)
+	}
+	stopper := make(chan struct{})
+	defer close(stopper)
+	if data, _, err := q.peek(stopper); err != nil || string(data) != "first" {
+		t.Fatalf("expected first, got %q (%v)", data, err)
+	}
+	if err := q.pop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delivery resumes after the last delivered entry.
+	q, err = openDiskQueue(path, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = q.close() }()
+	if n := q.length(); n != 1 {
+		t.Errorf("expected 1 entry after reopening, got %d", n)
+	}
+	if data, _, err := q.peek(stopper); err != nil || string(data) != "second" {
+		t.Fatalf("expected second, got %q (%v)", data, err)
+	}
+	if err := q.pop(); err != nil {
+		t.Fatal(err)
+	}
+	// Once everything was delivered, the space is reclaimed.
+	if err := q.push([]byte("third")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiskQueueOffsetSync(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	path := filepath.Join(s.logDir, "spool")
+	q, err := openDiskQueue(path, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = q.close() }()
+	for i := 0; i < diskQueueOffsetSyncEntries+2; i++ {
+		if err := q.push([]byte(fmt.Sprintf("entry %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// reopen simulates a crash: the queue is reopened without being closed.
+	reopen := func() *diskQueue {
+		q2, err := openDiskQueue(path, 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q2
+	}
+
+	// The offset is not persisted for every delivered entry, so the
+	// entries delivered before a crash can be delivered again.
+	if err := q.pop(); err != nil {
+		t.Fatal(err)
+	}
+	q2 := reopen()
+	if n := q2.length(); n != diskQueueOffsetSyncEntries+2 {
+		t.Errorf("expected %d entries after a crash, got %d", diskQueueOffsetSyncEntries+2, n)
+	}
+	_ = q2.close()
+
+	// Once enough entries were delivered, the offset is persisted
+	// atomically.
+	for i := 1; i < diskQueueOffsetSyncEntries; i++ {
+		if err := q.pop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path + ".offset.tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary offset file to be renamed, got %v", err)
+	}
+	q2 = reopen()
+	if n := q2.length(); n != 2 {
+		t.Errorf("expected 2 entries after a crash, got %d", n)
+	}
+	_ = q2.close()
+}
+
+func TestShedUnderBackpressure(t *testing.T) {
+	// The sink cannot connect, so entries accumulate in its queue.
+	sink := newNetSink("127.0.0.1:0", netSinkOptions{}, newMemQueue(4))
+	defer sink.close()
+
+	for _, e := range []string{"a", "b"} {
+		if err := sink.write([]byte(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if shedUnderBackpressure(sink, Severity_INFO) {
+		t.Error("expected no entries to be dropped unless enabled")
+	}
+	defer settings.TestingSetBool(&degradeUnderBackpressure, true)()
+	if shedUnderBackpressure(sink, Severity_WARNING) {
+		t.Error("expected warnings to be preserved")
+	}
+	if !shedUnderBackpressure(sink, Severity_INFO) {
+		t.Error("expected info entries to be dropped under backpressure")
+	}
+	if d := sink.status().Dropped; d != 1 {
+		t.Errorf("expected 1 dropped entry, got %d", d)
+	}
+	if shedUnderBackpressure(&testSink{name: "sync"}, Severity_INFO) {
+		t.Error("expected synchronous sinks to be unaffected")
+	}
+}
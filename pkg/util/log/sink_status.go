@@ -0,0 +1,144 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// SinkStatus describes the health of one of the sinks to which log
+// entries are delivered besides stderr and the log files.
+type SinkStatus struct {
+	Name string `json:"name"`
+	// Connected is true if the sink is currently able to deliver entries
+	// to its destination.
+	Connected bool `json:"connected"`
+	// LastError is the last error encountered while delivering entries.
+	LastError string `json:"last_error,omitempty"`
+	// Backlog is the number of entries waiting to be delivered.
+	Backlog int `json:"backlog"`
+	// BytesSent is the number of bytes delivered to the destination.
+	BytesSent int64 `json:"bytes_sent"`
+	// Dropped is the number of entries which could not be delivered.
+	Dropped uint64 `json:"dropped"`
+}
+
+// sinkHealth tracks the health of an asynchronous sink. It is updated by
+// the goroutine delivering the entries, and read concurrently.
+type sinkHealth struct {
+	// name is the name of the sink in the messages reporting its failures.
+	name string
+	// The integer fields are accessed atomically.
+	bytesSent int64
+	dropped   uint64
+	connected int32
+	lastErr   atomic.Value // string
+}
+
+func (h *sinkHealth) setConnected(connected bool) {
+	var v int32
+	if connected {
+		v = 1
+	}
+	atomic.StoreInt32(&h.connected, v)
+}
+
+func (h *sinkHealth) recordError(err error) {
+	h.setConnected(false)
+	h.lastErr.Store(err.Error())
+	metaLogf("error:"+h.name, "unable to deliver entries to %s: %s", h.name, err)
+}
+
+func (h *sinkHealth) recordSent(n int) {
+	atomic.AddInt64(&h.bytesSent, int64(n))
+}
+
+func (h *sinkHealth) recordDropped() {
+	n := atomic.AddUint64(&h.dropped, 1)
+	metaLogf("dropped:"+h.name, "%d entries bound for %s dropped so far", n, h.name)
+}
+
+func (h *sinkHealth) status(name string, backlog int) SinkStatus {
+	lastErr, _ := h.lastErr.Load().(string)
+	return SinkStatus{
+		Name:      name,
+		Connected: atomic.LoadInt32(&h.connected) == 1,
+		LastError: lastErr,
+		Backlog:   backlog,
+		BytesSent: atomic.LoadInt64(&h.bytesSent),
+		Dropped:   atomic.LoadUint64(&h.dropped),
+	}
+}
+
+// statusReporter is implemented by the sinks which track their health.
+type statusReporter interface {
+	status() SinkStatus
+}
+
+// sinkStatuses returns the status of sink, or of each of the sinks of a
+// failover chain. Sinks which do not track their health are reported as
+// connected. l.mu is held.
+func sinkStatuses(sink logSink) []SinkStatus {
+	switch s := sink.(type) {
+	case *failoverSink:
+		var res []SinkStatus
+		for i, member := range s.sinks {
+			for _, st := range sinkStatuses(member) {
+				if !s.healthy(i) {
+					st.Connected = false
+					if st.LastError == "" && s.lastErr[i] != nil {
+						st.LastError = s.lastErr[i].Error()
+					}
+				}
+				res = append(res, st)
+			}
+		}
+		return res
+	case *teeSink:
+		return s.statuses()
+	case *chaosSink:
+		return sinkStatuses(s.sink)
+	case statusReporter:
+		return []SinkStatus{s.status()}
+	}
+	return []SinkStatus{{Name: sink.String(), Connected: true}}
+}
+
+// GetSinkStatuses returns the health of the sinks to which log entries
+// are delivered besides stderr and the log files.
+func GetSinkStatuses() []SinkStatus {
+	logging.mu.Lock()
+	defer logging.mu.Unlock()
+	var res []SinkStatus
+	for _, c := range logging.sinks {
+		res = append(res, sinkStatuses(c.sink)...)
+	}
+	return res
+}
+
+const httpLogSinksPath = "/debug/logsinks"
+
+func handleLogSinks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	statuses := GetSinkStatuses()
+	if statuses == nil {
+		statuses = []SinkStatus{}
+	}
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
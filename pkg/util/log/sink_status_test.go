@@ -0,0 +1,68 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSinkStatuses(t *testing.T) {
+	primary := &testSink{name: "primary", err: errors.New("boom")}
+	var health sinkHealth
+	health.setConnected(true)
+	health.recordSent(10)
+	health.recordDropped()
+	secondary := &testStatusSink{testSink: testSink{name: "secondary"}, health: &health}
+
+	chain := newFailoverSink([]logSink{primary, secondary}, time.Minute)
+	if err := chain.write([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SinkStatus{
+		{Name: "primary", Connected: false, LastError: "boom"},
+		{Name: "secondary", Connected: true, Backlog: 3, BytesSent: 10, Dropped: 1},
+	}
+	if statuses := sinkStatuses(chain); !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected %+v, got %+v", expected, statuses)
+	}
+}
+
+// testStatusSink is a testSink which tracks its health.
+type testStatusSink struct {
+	testSink
+	health *sinkHealth
+}
+
+func (s *testStatusSink) status() SinkStatus {
+	return s.health.status(s.name, 3)
+}
+
+func TestHandleLogSinks(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleLogSinks(w, httptest.NewRequest("GET", httpLogSinksPath, nil))
+	var statuses []SinkStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("%s: %s", err, w.Body.String())
+	}
+	if statuses == nil {
+		t.Error("expected an empty list rather than null")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// sinkTLSOptions configures the TLS connections of the network sinks.
+// The certificate files are read again whenever a connection is
+// established, so that rotated certificates are picked up without a
+// restart.
+type sinkTLSOptions struct {
+	// caFile is the certificate authority used to verify the collector.
+	// The system roots are used if empty.
+	caFile string
+	// certFile and keyFile are the client certificate and key presented
+	// to the collector, if set.
+	certFile, keyFile string
+	// serverName overrides the name verified in the collector's
+	// certificate. It defaults to the host of the collector's address.
+	serverName string
+	// minVersion is the minimum TLS version, e.g. "1.2".
+	minVersion string
+}
+
+// sinkTLS holds the TLS options shared by all the network sinks. They are
+// used by the network sink when --log-net-tls is set, and by the syslog
+// and fluentd sinks when their address starts with tls://.
+var sinkTLS sinkTLSOptions
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+}
+
+// config returns the TLS configuration to connect to the collector with
+// the given host name.
+func (o *sinkTLSOptions) config(host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: host,
+		MinVersion: tls.VersionTLS12,
+	}
+	if o.serverName != "" {
+		cfg.ServerName = o.serverName
+	}
+	if o.minVersion != "" {
+		v, ok := tlsVersions[o.minVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %q, expected 1.0, 1.1 or 1.2", o.minVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if o.caFile != "" {
+		pem, err := ioutil.ReadFile(o.caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.caFile)
+		}
+	}
+	if o.certFile != "" || o.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// client establishes a TLS session over conn, a connection to addr, which
+// is closed if the handshake fails.
+func (o *sinkTLSOptions) client(conn net.Conn, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	cfg, err := o.config(host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.SetDeadline(time.Now().Add(netSinkDialTimeout)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := tlsConn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// dialTLS connects to addr over TCP and establishes a TLS session.
+func dialTLS(addr string, o *sinkTLSOptions) (net.Conn, error) {
+	conn, err := dialTCP(addr)
+	if err != nil {
+		return nil, err
+	}
+	return o.client(conn, addr)
+}
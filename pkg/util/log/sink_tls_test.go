@@ -0,0 +1,181 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !nophonehome
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testCertsDir = filepath.Join("..", "..", "security", "securitytest", "test_certs")
+
+func TestSinkTLSOptions(t *testing.T) {
+	cfg, err := (&sinkTLSOptions{}).config("collector")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ServerName != "collector" || cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("unexpected defaults: server name %q, min version %x", cfg.ServerName, cfg.MinVersion)
+	}
+
+	cfg, err = (&sinkTLSOptions{serverName: "other", minVersion: "1.1"}).config("collector")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ServerName != "other" || cfg.MinVersion != tls.VersionTLS11 {
+		t.Errorf("unexpected overrides: server name %q, min version %x", cfg.ServerName, cfg.MinVersion)
+	}
+
+	for _, opts := range []sinkTLSOptions{
+		{minVersion: "0.9"},
+		{caFile: filepath.Join(testCertsDir, "missing.crt")},
+		{caFile: filepath.Join(testCertsDir, "ca.key")},
+		{certFile: filepath.Join(testCertsDir, "client.root.crt")},
+	} {
+		if _, err := opts.config("collector"); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
+
+// listenTLS returns a TLS listener with the node certificate, and the
+// options of the sinks connecting to it.
+func listenTLS(t *testing.T) (net.Listener, *sinkTLSOptions) {
+	cert, err := tls.LoadX509KeyPair(
+		filepath.Join(testCertsDir, "node.crt"), filepath.Join(testCertsDir, "node.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln, &sinkTLSOptions{
+		caFile:   filepath.Join(testCertsDir, "ca.crt"),
+		certFile: filepath.Join(testCertsDir, "client.root.crt"),
+		keyFile:  filepath.Join(testCertsDir, "client.root.key"),
+	}
+}
+
+// acceptTLS accepts a connection on ln and returns a reader of what is
+// sent on it.
+func acceptTLS(t *testing.T, ln net.Listener) (net.Conn, *bufio.Reader) {
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	return conn, bufio.NewReader(conn)
+}
+
+func TestNetSinkTLS(t *testing.T) {
+	ln, opts := listenTLS(t)
+	defer ln.Close()
+
+	sink := newNetSink(ln.Addr().String(), netSinkOptions{tls: opts}, newMemQueue(10))
+	defer sink.close()
+	if err := sink.write([]byte("secure\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, r := acceptTLS(t, ln)
+	defer conn.Close()
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "secure\n" {
+		t.Errorf("expected %q, got %q", "secure\n", line)
+	}
+}
+
+func TestFluentSinkTLS(t *testing.T) {
+	ln, opts := listenTLS(t)
+	defer ln.Close()
+
+	sink, err := makeFluentSink("tls://"+ln.Addr().String(), "crdb", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.(*fluentSink).close()
+	if err := sink.(entryWriter).writeEntry(fluentTestEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, r := acceptTLS(t, ln)
+	defer conn.Close()
+	expected := []byte{0x93, 0xa4, 'c', 'r', 'd', 'b', 0xd7, 0, 0, 0, 0, 5, 0, 0, 0, 1}
+	expected = appendFluentRecord(expected, fluentTestEntry)
+	b := make([]byte, len(expected))
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("expected %x, got %x", expected, b)
+	}
+}
+
+func TestSyslogSinkTLS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("syslog is not supported on Windows")
+	}
+	ln, opts := listenTLS(t)
+	defer ln.Close()
+
+	sink, err := newSyslogSink("tls://"+ln.Addr().String(), "crdb", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sink connects when writing the entry, so the connection must be
+	// accepted concurrently.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sink.Output(Entry{Severity: Severity_WARNING, Time: 5e9, File: "f.go", Line: 9, Message: "hello"})
+	}()
+
+	conn, r := acceptTLS(t, ln)
+	defer conn.Close()
+	// The message is preceded by its length, and its priority is that of
+	// LOG_DAEMON and LOG_WARNING.
+	var n int
+	if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	msg := string(b)
+	if !strings.HasPrefix(msg, "<28>1 1970-01-01T00:00:05Z ") || !strings.Contains(msg, " crdb ") ||
+		!strings.HasSuffix(msg, " - - f.go:9 hello") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
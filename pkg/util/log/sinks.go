@@ -0,0 +1,290 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// logSink is a destination for log entries in addition to stderr and the
+// log files.
+type logSink interface {
+	// write hands a formatted entry over to the sink. The sink must not
+	// retain data after write returns. Since write is called with
+	// logging.mu held, sinks whose destination may stall must not block:
+	// they buffer or drop entries instead, and account for the entries
+	// they fail to deliver themselves. An error is returned if the entry
+	// could not be delivered.
+	write(data []byte) error
+	// String describes the sink.
+	String() string
+}
+
+// sinkConfig associates a sink with the configuration of the entries it
+// receives.
+type sinkConfig struct {
+	sink      logSink
+	threshold Severity
+	format    outputFormat
+	// flagThreshold, if set, is the flag from which threshold was
+	// initialized, which logging profiles change.
+	flagThreshold *Severity
+}
+
+// fifoSinkPath, fifoSinkThreshold and fifoSinkFailover configure the
+// FIFO sink.
+var (
+	fifoSinkPath      string
+	fifoSinkThreshold = Severity_INFO
+	fifoSinkFailover  string
+)
+
+// teeSinkSpec and teeSinkThreshold configure the tee sink.
+var (
+	teeSinkSpec      string
+	teeSinkThreshold = Severity_INFO
+)
+
+// netSinkAddr, netSinkThreshold, netSinkSpoolMaxSize, netSinkOpts and
+// netSinkTLS configure the network sink.
+var (
+	netSinkAddr         string
+	netSinkThreshold    = Severity_INFO
+	netSinkSpoolMaxSize int64
+	netSinkOpts         netSinkOptions
+	netSinkTLS          bool
+)
+
+// syslogSinkSpec, syslogSinkTag and syslogSinkThreshold configure the
+// syslog sink.
+var (
+	syslogSinkSpec      string
+	syslogSinkTag       string
+	syslogSinkThreshold = Severity_INFO
+)
+
+// fluentSinkAddr, fluentSinkTag and fluentSinkThreshold configure the
+// fluentd sink.
+var (
+	fluentSinkAddr      string
+	fluentSinkTag       string
+	fluentSinkThreshold = Severity_INFO
+)
+
+// initSinks creates the sinks configured by flags. l.mu is held.
+func (l *loggingT) initSinks() {
+	l.sinksInitialized = true
+	if netSinkAddr != "" && !phoneHome {
+		fmt.Fprintf(OrigStderr, "log: not sending log messages to %s: outbound connections are disabled in this build\n", netSinkAddr)
+	} else if netSinkAddr != "" {
+		if queue, err := makeNetSinkQueue(); err != nil {
+			fmt.Fprintf(OrigStderr, "log: unable to set up network sink: %s\n", err)
+		} else {
+			opts := netSinkOpts
+			if netSinkTLS {
+				opts.tls = &sinkTLS
+			}
+			l.sinks = append(l.sinks, sinkConfig{
+				sink:          maybeChaos(newNetSink(netSinkAddr, opts, queue)),
+				threshold:     netSinkThreshold.get(),
+				format:        formatCrdbV1,
+				flagThreshold: &netSinkThreshold,
+			})
+		}
+	}
+	if fifoSinkPath != "" {
+		sink := maybeChaos(newFIFOSink(fifoSinkPath, fifoSinkBufferSize))
+		if fifoSinkFailover != "" {
+			chain, err := makeFailoverChain(sink, fifoSinkFailover)
+			if err != nil {
+				fmt.Fprintf(OrigStderr, "log: invalid failover for %s: %s\n", sink, err)
+			} else {
+				sink = chain
+			}
+		}
+		l.sinks = append(l.sinks, sinkConfig{
+			sink:          sink,
+			threshold:     fifoSinkThreshold.get(),
+			format:        formatCrdbV1,
+			flagThreshold: &fifoSinkThreshold,
+		})
+	}
+	if teeSinkSpec != "" {
+		if sink, err := makeTeeSink(teeSinkSpec); err != nil {
+			fmt.Fprintf(OrigStderr, "log: unable to set up tee sink: %s\n", err)
+		} else {
+			l.sinks = append(l.sinks, sinkConfig{
+				sink:          sink,
+				threshold:     teeSinkThreshold.get(),
+				format:        formatCrdbV1,
+				flagThreshold: &teeSinkThreshold,
+			})
+		}
+	}
+	if syslogSinkSpec != "" && syslogSinkSpec != syslogLocal && !phoneHome {
+		fmt.Fprintf(OrigStderr, "log: not sending log messages to %s: outbound connections are disabled in this build\n", syslogSinkSpec)
+	} else if syslogSinkSpec != "" {
+		if sink, err := newSyslogSink(syslogSinkSpec, defaultSinkTag(syslogSinkTag), &sinkTLS); err != nil {
+			fmt.Fprintf(OrigStderr, "log: unable to set up syslog sink: %s\n", err)
+		} else {
+			l.sinks = append(l.sinks, sinkConfig{
+				sink:      maybeChaos(newBufferedSink("syslog:"+syslogSinkSpec, sink, entrySinkBufferSize)),
+				threshold: syslogSinkThreshold.get(),
+				format:    formatJSON,
+			})
+		}
+	}
+	if fluentSinkAddr != "" && !phoneHome {
+		fmt.Fprintf(OrigStderr, "log: not sending log messages to %s: outbound connections are disabled in this build\n", fluentSinkAddr)
+	} else if fluentSinkAddr != "" {
+		if sink, err := makeFluentSink(fluentSinkAddr, defaultSinkTag(fluentSinkTag), &sinkTLS); err != nil {
+			fmt.Fprintf(OrigStderr, "log: unable to set up fluentd sink: %s\n", err)
+		} else {
+			l.sinks = append(l.sinks, sinkConfig{
+				sink:      maybeChaos(sink),
+				threshold: fluentSinkThreshold.get(),
+				format:    formatJSON,
+			})
+		}
+	}
+}
+
+// defaultSinkTag returns tag, or the name of the program if tag is empty.
+func defaultSinkTag(tag string) string {
+	if tag == "" {
+		return removePeriods(program)
+	}
+	return tag
+}
+
+// splitSinkAddr splits the address of a sink into its network, which is
+// def if the address does not start with NETWORK://, and the address
+// proper. The network must be udp, tcp or tls, TCP with TLS.
+func splitSinkAddr(spec, def string) (network, addr string, err error) {
+	network, addr = def, spec
+	if i := strings.Index(spec, "://"); i >= 0 {
+		network, addr = spec[:i], spec[i+len("://"):]
+	}
+	if network != "udp" && network != "tcp" && network != "tls" {
+		return "", "", fmt.Errorf("unknown network %q in %q, expected udp, tcp or tls", network, spec)
+	}
+	if addr == "" {
+		return "", "", fmt.Errorf("missing address in %q", spec)
+	}
+	return network, addr, nil
+}
+
+// makeNetSinkQueue returns the queue of the network sink: a spool in the
+// log directory if a spool size is configured, and a queue in memory
+// otherwise.
+func makeNetSinkQueue() (sinkQueue, error) {
+	if netSinkSpoolMaxSize <= 0 {
+		return newMemQueue(netSinkBufferSize), nil
+	}
+	dir, err := logDir.get()
+	if err != nil {
+		return nil, err
+	}
+	return openDiskQueue(filepath.Join(dir, removePeriods(program)+".net-spool"), netSinkSpoolMaxSize)
+}
+
+// makeFailoverChain returns a failover sink which writes to primary and,
+// when it fails, to the fallback sinks named in the comma-separated list
+// spec, in order.
+func makeFailoverChain(primary logSink, spec string) (*failoverSink, error) {
+	sinks := []logSink{primary}
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(name) {
+		case "spill":
+			dir, err := logDir.get()
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, newSpillFileSink(filepath.Join(dir, spillFileName())))
+		case "stderr":
+			sinks = append(sinks, stderrSink{})
+		default:
+			return nil, fmt.Errorf("unknown failover sink %q, expected spill or stderr", name)
+		}
+	}
+	return newFailoverSink(sinks, failoverRetryInterval), nil
+}
+
+// backpressureReporter is implemented by the sinks which buffer entries
+// until their destination accepts them.
+type backpressureReporter interface {
+	// backpressure returns the fraction of the sink's buffer in use.
+	backpressure() float64
+	// recordDropped accounts for an entry dropped before reaching the
+	// sink.
+	recordDropped()
+}
+
+// degradeUnderBackpressure governs what happens to the entries below
+// WARNING when a sink does not keep up.
+var degradeUnderBackpressure = settings.RegisterBoolSetting(
+	"log.sinks.degrade_under_backpressure.enabled",
+	"when a log sink's buffer is more than half full, drop entries below WARNING "+
+		"to leave room for more important ones, rather than dropping entries "+
+		"regardless of their severity once the buffer is full",
+	false,
+)
+
+// backpressureFill is the fraction of a sink's buffer in use above which
+// the sink is considered to be under backpressure.
+const backpressureFill = 0.5
+
+// shedUnderBackpressure returns true if an entry with severity s must be
+// dropped to relieve the backpressure of sink.
+// logging.mu is held.
+func shedUnderBackpressure(sink logSink, s Severity) bool {
+	if s >= Severity_WARNING || !(logging.degradeSinks || degradeUnderBackpressure.Get()) {
+		return false
+	}
+	b, ok := sink.(backpressureReporter)
+	if !ok || b.backpressure() < backpressureFill {
+		return false
+	}
+	b.recordDropped()
+	return true
+}
+
+// outputToSinks writes the entry to the sinks whose threshold it meets.
+// l.mu is held.
+func (l *loggingT) outputToSinks(entry Entry, stacks []byte) {
+	if !l.sinksInitialized {
+		l.initSinks()
+	}
+	if l.sampledOut(entry) {
+		return
+	}
+	for _, c := range l.sinks {
+		if entry.Severity < c.threshold || shedUnderBackpressure(c.sink, entry.Severity) {
+			continue
+		}
+		// Sinks account for their failures themselves.
+		if w, ok := c.sink.(entryWriter); ok {
+			_ = w.writeEntry(entry)
+			continue
+		}
+		buf := c.format.formatEntry(entry, stacks, colorProfile256)
+		_ = c.sink.write(buf.Bytes())
+		l.putBuffer(buf)
+	}
+}
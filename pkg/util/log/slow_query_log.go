@@ -0,0 +1,112 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// slowQueryTag is the channel of the statements recorded in the slow
+// query log.
+const slowQueryTag = "slow_query"
+
+// slowQueryEventPrefix prefixes the message of the entries of the slow
+// query log files, which is followed by the JSON encoding of the
+// SlowQueryEvent.
+const slowQueryEventPrefix = "slow query: "
+
+// slowQueryEventType is the name of the event type of SlowQueryEvent in
+// the catalog of the structured log surface.
+const slowQueryEventType = "slow_query"
+
+func init() {
+	RegisterChannel(slowQueryTag, "statements slower than a threshold, recorded in the slow query log files")
+	RegisterEventType(slowQueryEventType, slowQueryTag,
+		"a statement whose execution exceeded the slow query latency threshold",
+		slowQueryEventPrefix, SlowQueryEvent{})
+}
+
+// SlowQueryLogFileMaxSize is the maximum size in bytes of a slow query
+// log file.
+var SlowQueryLogFileMaxSize int64 = 10 << 20 // 10MiB
+
+// SlowQueryLogFilesCombinedMaxSize is the maximum total size in bytes of
+// the slow query log files, past which the oldest ones are removed.
+var SlowQueryLogFilesCombinedMaxSize = SlowQueryLogFileMaxSize * 10 // 100MiB
+
+// SlowQueryEvent describes the execution of a statement recorded in the
+// slow query log. None of its fields contain user data.
+type SlowQueryEvent struct {
+	Time time.Time `json:"time"`
+	// Statement is the text of the statement, with its constants hidden.
+	Statement string `json:"statement"`
+	// Plan is the logical plan of the statement, without its expressions.
+	Plan string `json:"plan"`
+	// Rows is the number of rows returned or affected by the statement.
+	Rows int `json:"rows"`
+	// LatencyNanos is the service latency of the statement, from the start
+	// of its parsing to the end of its execution.
+	LatencyNanos int64 `json:"latency_ns"`
+	DistSQL      bool  `json:"distsql"`
+	// Retries is the number of automatic retries of the transaction of the
+	// statement so far.
+	Retries int  `json:"retries"`
+	Failed  bool `json:"failed"`
+}
+
+var slowQueryLog = struct {
+	syncutil.Mutex
+	file secondaryLogFile
+}{
+	file: secondaryLogFile{
+		name:            slowQueryTag,
+		maxSize:         &SlowQueryLogFileMaxSize,
+		combinedMaxSize: &SlowQueryLogFilesCombinedMaxSize,
+	},
+}
+
+// LogSlowQuery records ev in the slow query log files, filling in its
+// time if it is zero. Failures to record it are logged as warnings.
+func LogSlowQuery(ctx context.Context, ev SlowQueryEvent) {
+	file, line, _ := caller.Lookup(1)
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	if err := recordSlowQuery(ev, file, line); err != nil {
+		Warningf(ctx, "unable to record slow query in the slow query log: %s", err)
+	}
+}
+
+func recordSlowQuery(ev SlowQueryEvent, file string, line int) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	slowQueryLog.Lock()
+	defer slowQueryLog.Unlock()
+	if _, err := slowQueryLog.file.setDir(); err == errDirectoryNotSet {
+		// Without a log directory, there are no slow query log files.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return slowQueryLog.file.writeEntry(ev.Time, file, line, slowQueryEventPrefix+string(payload))
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestLogSlowQuery(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	LogSlowQuery(context.Background(), SlowQueryEvent{
+		Statement:    "SELECT * FROM t WHERE k = _",
+		Plan:         "0 scan\n0 .table t@primary\n",
+		Rows:         3,
+		LatencyNanos: 1e9,
+	})
+
+	dir, err := secondaryLogDir(slowQueryTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := listLogFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 slow query log file, found %d", len(files))
+	}
+	// The slow query log is kept apart from the main log files.
+	if strings.Contains(contents(), slowQueryEventPrefix) {
+		t.Errorf("unexpected slow query in the main log files")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e Entry
+	if err := NewEntryDecoder(strings.NewReader(string(data))).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(e.Message, slowQueryEventPrefix) {
+		t.Fatalf("expected a slow query, got %q", e.Message)
+	}
+	var ev SlowQueryEvent
+	if err := json.Unmarshal([]byte(e.Message[len(slowQueryEventPrefix):]), &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Statement != "SELECT * FROM t WHERE k = _" || ev.Rows != 3 || ev.LatencyNanos != 1e9 {
+		t.Errorf("unexpected slow query %+v", ev)
+	}
+	if ev.Time.IsZero() {
+		t.Errorf("expected the time to be set")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// sqlMemoryWarningPrefix prefixes the message of the entries recording
+// SQL memory warnings.
+const sqlMemoryWarningPrefix = "SQL memory usage above warning threshold: "
+
+// sqlMemoryWarningEvent is the name of the event type of SQLMemoryWarning
+// in the catalog of the structured log surface.
+const sqlMemoryWarningEvent = "sql_memory_warning"
+
+func init() {
+	RegisterEventType(sqlMemoryWarningEvent, opsTag,
+		"the memory used by SQL sessions crossed the warning threshold of the --max-sql-memory pool",
+		sqlMemoryWarningPrefix, SQLMemoryWarning{})
+}
+
+// SQLMemoryWarning describes the memory usage of the SQL sessions of a
+// node when it crosses the warning threshold, ahead of queries failing
+// for lack of memory. It is logged as a warning tagged with "ops", whose
+// message is sqlMemoryWarningPrefix followed by the JSON encoding of the
+// warning.
+type SQLMemoryWarning struct {
+	Time time.Time `json:"time"`
+	// AllocBytes is the memory allocated from the SQL memory pool, whose
+	// size is PoolBytes.
+	AllocBytes int64 `json:"alloc_bytes"`
+	PoolBytes  int64 `json:"pool_bytes"`
+	// TopSessions lists the sessions using the most memory, largest first.
+	TopSessions []SQLMemoryConsumer `json:"top_sessions"`
+}
+
+// SQLMemoryConsumer describes the memory usage of a SQL session.
+type SQLMemoryConsumer struct {
+	SessionID       string `json:"session_id"`
+	Username        string `json:"username"`
+	ApplicationName string `json:"application_name"`
+	AllocBytes      int64  `json:"alloc_bytes"`
+	// QueryIDs are the IDs of the queries running in the session.
+	QueryIDs []string `json:"query_ids"`
+}
+
+// LogSQLMemoryWarning logs w, filling in its time if it is zero.
+func LogSQLMemoryWarning(ctx context.Context, w SQLMemoryWarning) {
+	if w.Time.IsZero() {
+		w.Time = time.Now().UTC()
+	}
+	if w.TopSessions == nil {
+		w.TopSessions = []SQLMemoryConsumer{}
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		Warningf(ctx, "unable to log SQL memory warning: %s", err)
+		return
+	}
+	Warningf(WithLogTag(ctx, opsTag, nil), sqlMemoryWarningPrefix+"%s", data)
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// startupBannerPrefix prefixes the message of the entry recording the
+// startup banner.
+const startupBannerPrefix = "startup: "
+
+// startupBannerEvent is the name of the event type of StartupBanner in
+// the catalog of the structured log surface.
+const startupBannerEvent = "startup_banner"
+
+func init() {
+	RegisterEventType(startupBannerEvent, opsTag,
+		"the summary of the configuration of the process, logged once per start",
+		startupBannerPrefix, StartupBanner{})
+}
+
+// StartupBanner summarizes the configuration of a process once it has
+// started. It is logged once per start as an entry tagged with "ops",
+// whose message is startupBannerPrefix followed by the JSON encoding of
+// the banner, so that the configuration of the nodes of a fleet can be
+// compared automatically to detect drift.
+type StartupBanner struct {
+	Time time.Time `json:"time"`
+	// Version is the tag of the build, and Build its full description.
+	Version string `json:"version"`
+	Build   string `json:"build"`
+	// SettingsSources lists where the configuration came from besides the
+	// defaults, for instance "--store" for a command line flag, whose
+	// value is omitted as it may contain secrets, or
+	// "env:COCKROACH_SKIP_UPDATE_CHECK=true" for an environment variable.
+	SettingsSources []string `json:"settings_sources"`
+	// Sinks lists the sinks to which entries are delivered besides stderr
+	// and the log files.
+	Sinks []string `json:"sinks"`
+	// Directories maps the role of each directory used by the process to
+	// its path, for instance "logs" or "store[0]".
+	Directories map[string]string `json:"directories"`
+	// Details holds other properties of the process, such as its node ID.
+	Details   map[string]string `json:"details,omitempty"`
+	LogConfig EffectiveConfig   `json:"log_config"`
+}
+
+var startupBanner struct {
+	syncutil.Mutex
+	logged bool
+	banner StartupBanner
+}
+
+// LogStartupBanner logs the startup banner b, after filling in the
+// version, the sinks, the log directory and the logging configuration.
+// Only the first call of the process logs; the following ones are
+// ignored. The banner is then served by the startup banner endpoint.
+func LogStartupBanner(ctx context.Context, b StartupBanner) {
+	startupBanner.Lock()
+	if startupBanner.logged {
+		startupBanner.Unlock()
+		return
+	}
+	info := build.GetInfo()
+	b.Time = time.Now().UTC()
+	b.Version = info.Tag
+	b.Build = info.Short()
+	if b.SettingsSources == nil {
+		b.SettingsSources = []string{}
+	}
+	b.Sinks = []string{}
+	for _, s := range GetSinkStatuses() {
+		b.Sinks = append(b.Sinks, s.Name)
+	}
+	if b.Directories == nil {
+		b.Directories = make(map[string]string)
+	}
+	if dir := logDir.String(); dir != "" {
+		if _, ok := b.Directories["logs"]; !ok {
+			b.Directories["logs"] = dir
+		}
+	}
+	b.LogConfig = GetEffectiveConfig()
+	startupBanner.logged = true
+	startupBanner.banner = b
+	startupBanner.Unlock()
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		Warningf(ctx, "unable to log the startup banner: %s", err)
+		return
+	}
+	Infof(WithLogTag(ctx, opsTag, nil), startupBannerPrefix+"%s", data)
+}
+
+// GetStartupBanner returns the banner logged by LogStartupBanner. The
+// boolean return value is false if it was not logged yet.
+func GetStartupBanner() (StartupBanner, bool) {
+	startupBanner.Lock()
+	defer startupBanner.Unlock()
+	return startupBanner.banner, startupBanner.logged
+}
+
+const httpStartupBannerPath = "/debug/logs/startup"
+
+// handleStartupBanner serves the startup banner as JSON.
+func handleStartupBanner(w http.ResponseWriter, r *http.Request) {
+	b, ok := GetStartupBanner()
+	if !ok {
+		http.Error(w, "the process has not finished starting", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestLogStartupBanner(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+	defer func() {
+		startupBanner.Lock()
+		startupBanner.logged = false
+		startupBanner.banner = StartupBanner{}
+		startupBanner.Unlock()
+	}()
+
+	w := httptest.NewRecorder()
+	handleStartupBanner(w, httptest.NewRequest("GET", httpStartupBannerPath, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the banner to be unavailable before startup, got %d", w.Code)
+	}
+
+	var c LogCapture
+	ctx := WithLogCapture(context.Background(), &c)
+	c.Start()
+	LogStartupBanner(ctx, StartupBanner{
+		SettingsSources: []string{"--store"},
+		Details:         map[string]string{"node_id": "1"},
+	})
+	LogStartupBanner(ctx, StartupBanner{Details: map[string]string{"node_id": "2"}})
+	c.Stop()
+
+	entries, _ := c.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected the banner to be logged once, got %+v", entries)
+	}
+	prefix := "[" + opsTag + "] " + startupBannerPrefix
+	if !strings.HasPrefix(entries[0].Message, prefix) {
+		t.Fatalf("expected %q to start with %q", entries[0].Message, prefix)
+	}
+	var logged StartupBanner
+	if err := json.Unmarshal([]byte(entries[0].Message[len(prefix):]), &logged); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Details["node_id"] != "1" || logged.Version == "" ||
+		logged.Directories["logs"] != logDir.String() || logged.LogConfig.LogDir != logDir.String() {
+		t.Errorf("unexpected banner %+v", logged)
+	}
+
+	w = httptest.NewRecorder()
+	handleStartupBanner(w, httptest.NewRequest("GET", httpStartupBannerPath, nil))
+	var served StartupBanner
+	if err := json.NewDecoder(w.Body).Decode(&served); err != nil {
+		t.Fatal(err)
+	}
+	if !served.Time.Equal(logged.Time) || served.Details["node_id"] != "1" {
+		t.Errorf("expected the endpoint to serve %+v, got %+v", logged, served)
+	}
+}
@@ -19,12 +19,16 @@ package log
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	opentracing "github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
 )
 
@@ -109,25 +113,72 @@ func (b *msgBuf) EmitLazyLogger(value otlog.LazyLogger) {
 // formatTags appends the tags to a bytes.Buffer. If there are no tags,
 // returns false.
 func formatTags(ctx context.Context, buf *msgBuf) bool {
+	return formatTagsAndSpan(ctx, buf, spanIDs{})
+}
+
+// formatTagsAndSpan is like formatTags, but the tags are followed by the
+// IDs of the trace and span in ids, if set.
+func formatTagsAndSpan(ctx context.Context, buf *msgBuf, ids spanIDs) bool {
 	tags := contextLogTags(ctx, buf.tagBuf[:0])
-	if len(tags) > 0 {
-		buf.WriteByte('[')
-		for i, t := range tags {
-			if i > 0 {
-				buf.WriteByte(',')
-			}
-			t.Field.Marshal(buf)
+	if len(tags) == 0 && !ids.isSet() {
+		return false
+	}
+	buf.WriteByte('[')
+	for i, t := range tags {
+		if i > 0 {
+			buf.WriteByte(',')
 		}
-		buf.WriteString("] ")
-		return true
+		t.Field.Marshal(buf)
 	}
-	return false
+	ids.format(buf, len(tags) > 0)
+	buf.WriteString("] ")
+	return true
+}
+
+// spanIDs are the IDs of the trace and span in which an entry is logged.
+// They are included in the entries written to the log outputs so that
+// they can be correlated with the traces collected by external systems.
+type spanIDs struct {
+	traceID, spanID uint64
+}
+
+// spanIDsFromCtx returns the IDs of the span of ctx. They are not set if
+// ctx has no span, or if the span is not actually collected.
+func spanIDsFromCtx(ctx context.Context) spanIDs {
+	sp := opentracing.SpanFromContext(ctx)
+	if sp == nil {
+		return spanIDs{}
+	}
+	traceID, spanID, _ := tracing.GetSpanIDs(sp)
+	return spanIDs{traceID: traceID, spanID: spanID}
+}
+
+func (ids spanIDs) isSet() bool {
+	return ids.traceID != 0
+}
+
+// format appends the IDs, if set, to buf in the format of log tags,
+// preceded by a separator if sep is set.
+func (ids spanIDs) format(buf *msgBuf, sep bool) {
+	if !ids.isSet() {
+		return
+	}
+	if sep {
+		buf.WriteByte(',')
+	}
+	fmt.Fprintf(buf, "trace=%x,span=%x", ids.traceID, ids.spanID)
 }
 
 // MakeMessage creates a structured log entry.
 func MakeMessage(ctx context.Context, format string, args []interface{}) string {
+	return makeMessage(ctx, format, args, spanIDs{})
+}
+
+// makeMessage is like MakeMessage, but the log tags are followed by the
+// IDs in ids, if set.
+func makeMessage(ctx context.Context, format string, args []interface{}, ids spanIDs) string {
 	var buf msgBuf
-	formatTags(ctx, &buf)
+	formatTagsAndSpan(ctx, &buf, ids)
 	if len(format) == 0 {
 		fmt.Fprint(&buf, args...)
 	} else {
@@ -136,6 +187,137 @@ func MakeMessage(ctx context.Context, format string, args []interface{}) string
 	return buf.String()
 }
 
+// makeRedactedMessage is like makeMessage, except that the values of
+// the log tags and the arguments which may contain user data are
+// replaced by redactedMarker.
+func makeRedactedMessage(
+	ctx context.Context, format string, args []interface{}, ids spanIDs,
+) string {
+	return makeReportableMessage(ctx, format, args, ids, reportableValue)
+}
+
+// makeMarkedMessage is like makeMessage, except that the values of the
+// log tags and the arguments wrapped in Unsafe are delimited by
+// unsafeOpen and unsafeClose, so that they can be stripped from the log
+// files.
+func makeMarkedMessage(ctx context.Context, format string, args []interface{}, ids spanIDs) string {
+	return makeReportableMessage(ctx, format, args, ids, markedValue)
+}
+
+// makeAnonymizedMessage is like makeMessage, except that the values of
+// the log tags and the arguments which may contain user data are
+// anonymized, as for crash reports.
+func makeAnonymizedMessage(
+	ctx context.Context, format string, args []interface{}, ids spanIDs,
+) string {
+	return makeReportableMessage(ctx, format, args, ids, anonymizedValue)
+}
+
+// makeReportableMessage is like makeMessage, except that the values of
+// the log tags and the arguments are passed through reportable.
+func makeReportableMessage(
+	ctx context.Context,
+	format string,
+	args []interface{},
+	ids spanIDs,
+	reportable func(interface{}) interface{},
+) string {
+	var buf msgBuf
+	if tags := contextLogTags(ctx, buf.tagBuf[:0]); len(tags) > 0 || ids.isSet() {
+		buf.WriteByte('[')
+		for i, t := range tags {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			v := t.Value()
+			buf.writeKey(t.Key(), v != nil)
+			if v != nil {
+				fmt.Fprint(&buf, reportable(v))
+			}
+		}
+		ids.format(&buf, len(tags) > 0)
+		buf.WriteString("] ")
+	}
+	redacted := make([]interface{}, len(args))
+	for i, arg := range args {
+		r := reportable(arg)
+		if str, ok := r.(string); ok {
+			if _, ok := arg.(string); !ok {
+				r = reportedArg(str)
+			}
+		}
+		redacted[i] = r
+	}
+	if len(format) == 0 {
+		fmt.Fprint(&buf, redacted...)
+	} else {
+		fmt.Fprintf(&buf, format, redacted...)
+	}
+	return buf.String()
+}
+
+// reportedArg is the reportable rendition of an argument which is not a
+// string, such as a redacted Unsafe number. It is formatted as is
+// whatever the verb, so that the verb of the argument does not turn it
+// into a formatting error.
+type reportedArg string
+
+// Format implements fmt.Formatter.
+func (a reportedArg) Format(s fmt.State, _ rune) {
+	_, _ = s.Write([]byte(a))
+}
+
+// unsafeOpen and unsafeClose delimit the values wrapped in Unsafe in the
+// messages written to the log files.
+const (
+	unsafeOpen  = "\u2039" // ‹
+	unsafeClose = "\u203a" // ›
+)
+
+// markedUnsafe is the rendition of an Unsafe value in the messages
+// written to the log files: its value delimited by unsafeOpen and
+// unsafeClose. The delimiters are escaped from the value so that the
+// whole value is stripped along with them.
+type markedUnsafe Unsafe
+
+// Format implements fmt.Formatter.
+func (m markedUnsafe) Format(s fmt.State, verb rune) {
+	v := fmt.Sprintf(formatDirective(s, verb), m.V)
+	v = strings.Replace(v, unsafeOpen, "?", -1)
+	v = strings.Replace(v, unsafeClose, "?", -1)
+	_, _ = io.WriteString(s, unsafeOpen+v+unsafeClose)
+}
+
+// markedValue returns the rendition of v in the messages written to the
+// log files: markedUnsafe if v is wrapped in Unsafe, and v otherwise.
+func markedValue(v interface{}) interface{} {
+	switch u := v.(type) {
+	case Unsafe:
+		return markedUnsafe(u)
+	case *Unsafe:
+		return markedUnsafe(*u)
+	}
+	return v
+}
+
+// hasUnsafe returns whether args or the log tags of ctx include values
+// wrapped in Unsafe, and thus whether the messages written to the log
+// files differ from those formatted by makeMessage.
+func hasUnsafe(ctx context.Context, args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := markedValue(arg).(markedUnsafe); ok {
+			return true
+		}
+	}
+	var tagBuf [8]*logTag
+	for _, t := range contextLogTags(ctx, tagBuf[:0]) {
+		if _, ok := markedValue(t.Value()).(markedUnsafe); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // addStructured creates a structured log entry to be written to the
 // specified facility of the logger.
 func addStructured(ctx context.Context, s Severity, depth int, format string, args []interface{}) {
@@ -143,17 +325,38 @@ func addStructured(ctx context.Context, s Severity, depth int, format string, ar
 	msg := MakeMessage(ctx, format, args)
 
 	if s == Severity_FATAL {
-		// we send the `format` str, not the formatted message, as args may be not
-		// be okay to share.
-		reportable := format
-		if reportable == "" && len(args) > 0 {
-			reportable = fmt.Sprintf("%T", args[0])
-		}
-		reportable = fmt.Sprintf("%s:%d %s", filepath.Base(file), line, reportable)
-		sendCrashReport(ctx, reportable, depth+1)
+		// Make sure the process terminates even if sending the crash report
+		// or flushing the log sinks below hangs.
+		defer startFatalDrain()()
+
+		// The exit reason is fingerprinted from the `format` str, not the
+		// formatted message, so that it does not vary with the arguments.
+		reportable := fmt.Sprintf("%s:%d %s", filepath.Base(file), line, reportableTemplate(format, args))
+		recordExitReason(ExitClassFatal, reportable)
+		// The crash report includes the arguments, except that those which
+		// may contain user data are anonymized. The log tags are reported
+		// separately.
+		sendCrashReport(ctx, fmt.Sprintf("%s:%d %s", filepath.Base(file), line,
+			makeAnonymizedMessage(context.Background(), format, args, spanIDs{})), depth+1)
 	}
-	// MakeMessage already added the tags when forming msg, we don't want
-	// eventInternal to prepend them again.
-	eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, "%s:%d %s", file, line, msg)
-	logging.outputLogEntry(s, file, line, msg)
+	withOutputLabels(ctx, func() {
+		// MakeMessage already added the tags when forming msg, we don't want
+		// eventInternal to prepend them again.
+		eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, "%s:%d %s", file, line, msg)
+		captureEntry(ctx, s, file, line, msg)
+		// The entries sent to the trace do not need to refer to it, but those
+		// written to the log outputs do.
+		ids := spanIDsFromCtx(ctx)
+		if ids.isSet() {
+			msg = makeMessage(ctx, format, args, ids)
+		}
+		var redactedMsg, markedMsg string
+		if logging.stderrRedact || logging.fileRedact {
+			redactedMsg = makeRedactedMessage(ctx, format, args, ids)
+		}
+		if logDir.isSet() && !logging.fileRedact && hasUnsafe(ctx, args) {
+			markedMsg = makeMarkedMessage(ctx, format, args, ids)
+		}
+		logging.outputLogEntry(s, SensitivityFromContext(ctx), file, line, msg, redactedMsg, markedMsg, format, args)
+	})
 }
@@ -0,0 +1,334 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	raven "github.com/getsentry/raven-go"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// defaultBundleMaxLogBytes is the default bound of the combined size of
+// the log files included in a support bundle.
+const defaultBundleMaxLogBytes = 100 << 20 // 100 MiB
+
+// redactedFileHeader is the message of the log file header entry which
+// records that the messages of the file are redacted.
+const redactedFileHeader = "[config] messages redacted\n"
+
+// markedFileHeader is the message of the log file header entry which
+// records that the values wrapped in Unsafe are delimited in the messages
+// of the file, see markedUnsafe.
+const markedFileHeader = "[config] unsafe values marked\n"
+
+// SupportBundleOptions configures WriteSupportBundle.
+type SupportBundleOptions struct {
+	// MaxLogBytes bounds the combined size of the log files included in
+	// the bundle, the most recent first. If zero, it is
+	// defaultBundleMaxLogBytes.
+	MaxLogBytes int64
+}
+
+// SupportBundleFile describes a file of a support bundle.
+type SupportBundleFile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Size        int64  `json:"size"`
+}
+
+// SupportBundleManifest describes the contents of a support bundle. It
+// is included in the bundle as manifest.json.
+type SupportBundleManifest struct {
+	CreatedAt time.Time           `json:"created_at"`
+	Binary    string              `json:"binary"`
+	Files     []SupportBundleFile `json:"files"`
+	// Errors describes the parts of the bundle which could not be
+	// collected.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// WriteSupportBundle writes to w a zip archive gathering the data needed
+// to troubleshoot the process: the recent log files, the stacks of all
+// goroutines, a heap profile, the effective logging configuration and
+// the recent crash reports, along with a manifest. The values wrapped in
+// Unsafe are stripped from the log messages. It is the node-local
+// half of support bundles. The parts which can't be collected are
+// recorded in the manifest rather than failing the whole bundle; an
+// error is returned if the archive itself can't be written.
+func WriteSupportBundle(w io.Writer, opts SupportBundleOptions) (SupportBundleManifest, error) {
+	if opts.MaxLogBytes == 0 {
+		opts.MaxLogBytes = defaultBundleMaxLogBytes
+	}
+	b := &bundleWriter{
+		zw: zip.NewWriter(w),
+		manifest: SupportBundleManifest{
+			CreatedAt: time.Now().UTC(),
+			Binary:    build.GetInfo().Short(),
+			Files:     []SupportBundleFile{},
+		},
+	}
+
+	b.add("config.json", "effective logging configuration", func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(GetEffectiveConfig())
+	})
+	b.add("goroutines.txt", "stacks of all goroutines", func(w io.Writer) error {
+		_, err := w.Write(getStacks(true))
+		return err
+	})
+	b.add("heap.pprof", "heap profile", func(w io.Writer) error {
+		return pprof.Lookup("heap").WriteTo(w, 0)
+	})
+	for i, packet := range getRecentCrashPackets() {
+		b.add(fmt.Sprintf("crash_reports/%d.json", i+1), "crash report "+packet.EventID,
+			func(w io.Writer) error {
+				data, err := packet.JSON()
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(data)
+				return err
+			})
+	}
+	b.addLogFiles(opts)
+
+	if b.err != nil {
+		return b.manifest, b.err
+	}
+	mw, err := b.zw.Create("manifest.json")
+	if err != nil {
+		return b.manifest, err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b.manifest); err != nil {
+		return b.manifest, err
+	}
+	return b.manifest, b.zw.Close()
+}
+
+// bundleWriter accumulates the files of a support bundle.
+type bundleWriter struct {
+	zw       *zip.Writer
+	manifest SupportBundleManifest
+	// err is the error which prevented writing the archive.
+	err error
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// add adds a file to the bundle, whose contents are written by fn. If fn
+// fails, the file is kept as far as it was written and the error is
+// recorded in the manifest.
+func (b *bundleWriter) add(name, description string, fn func(io.Writer) error) {
+	if b.err != nil {
+		return
+	}
+	fw, err := b.zw.Create(name)
+	if err != nil {
+		b.err = err
+		return
+	}
+	cw := &countingWriter{w: fw}
+	if err := fn(cw); err != nil {
+		b.manifest.Errors = append(b.manifest.Errors, fmt.Sprintf("%s: %s", name, err))
+	}
+	b.manifest.Files = append(b.manifest.Files, SupportBundleFile{
+		Name:        name,
+		Description: description,
+		Size:        cw.n,
+	})
+}
+
+// addLogFiles adds the most recent log files to the bundle. The files
+// which would exceed the size budget are skipped, so that the older,
+// smaller files can still be included.
+func (b *bundleWriter) addLogFiles(opts SupportBundleOptions) {
+	Flush()
+	files, err := ListLogFiles()
+	if err != nil {
+		b.manifest.Errors = append(b.manifest.Errors, fmt.Sprintf("listing log files: %s", err))
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTimeNanos > files[j].ModTimeNanos })
+	var total int64
+	for _, f := range files {
+		if total+f.SizeBytes > opts.MaxLogBytes {
+			continue
+		}
+		total += f.SizeBytes
+		f := f
+		b.add("logs/"+f.Name, "log file", func(w io.Writer) error {
+			return copyRedactedLogFile(w, f.Name)
+		})
+	}
+}
+
+// copyRedactedLogFile writes the given log file to w, stripping the
+// values wrapped in Unsafe from the messages of its entries, see
+// redactEntries.
+func copyRedactedLogFile(w io.Writer, name string) error {
+	r, err := GetLogReader(name, true /* restricted */)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return redactEntries(w, NewEntryDecoder(r))
+}
+
+// redactEntries writes to w the entries decoded from d, which are those
+// of a log file, redacted by an entryRedactor.
+func redactEntries(w io.Writer, d *EntryDecoder) error {
+	r := makeEntryRedactor()
+	for {
+		var e Entry
+		if err := d.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		r.redact(&e)
+		buf := formatLogEntry(e, nil, nil)
+		_, err := w.Write(buf.Bytes())
+		logging.putBuffer(buf)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// entryRedactor replaces the values wrapped in Unsafe by redactedMarker
+// in the messages of the entries of a log file, which are passed to it in
+// order. The entries of the files written redacted are left as is, and
+// the messages of the files whose Unsafe values are not delimited,
+// written by previous versions, are replaced by redactedMarker entirely,
+// except for those of the file headers.
+type entryRedactor struct {
+	// header is set until the first entry following the file header.
+	header bool
+	// redacted and marked record the headers redactedFileHeader and
+	// markedFileHeader.
+	redacted, marked bool
+}
+
+func makeEntryRedactor() entryRedactor {
+	return entryRedactor{header: true}
+}
+
+// redact redacts the message of e, the next entry of the file.
+func (r *entryRedactor) redact(e *Entry) {
+	if r.header {
+		r.header = strings.HasPrefix(e.Message, "[config] ") || strings.HasPrefix(e.Message, "line format: ")
+		r.redacted = r.redacted || (r.header && e.Message == strings.TrimSpace(redactedFileHeader))
+		r.marked = r.marked || (r.header && e.Message == strings.TrimSpace(markedFileHeader))
+	}
+	if r.header || r.redacted {
+		return
+	}
+	if r.marked {
+		e.Message = stripUnsafe(e.Message)
+	} else {
+		e.Message = redactedMarker
+	}
+}
+
+// stripUnsafe replaces the values delimited by unsafeOpen and
+// unsafeClose in msg by redactedMarker. An unterminated value extends to
+// the end of msg.
+func stripUnsafe(msg string) string {
+	if !strings.Contains(msg, unsafeOpen) {
+		return msg
+	}
+	var buf bytes.Buffer
+	for {
+		i := strings.Index(msg, unsafeOpen)
+		if i < 0 {
+			break
+		}
+		buf.WriteString(msg[:i])
+		buf.WriteString(redactedMarker)
+		j := strings.Index(msg[i:], unsafeClose)
+		if j < 0 {
+			msg = ""
+			break
+		}
+		msg = msg[i+j+len(unsafeClose):]
+	}
+	buf.WriteString(msg)
+	return buf.String()
+}
+
+// recentCrashPacketsSize is the number of crash reports retained for
+// support bundles.
+const recentCrashPacketsSize = 10
+
+// recentCrashPackets retains the latest crash reports, whether or not
+// they were sent.
+var recentCrashPackets struct {
+	syncutil.Mutex
+	packets []*raven.Packet
+}
+
+func recordCrashPacket(packet *raven.Packet) {
+	recentCrashPackets.Lock()
+	defer recentCrashPackets.Unlock()
+	if len(recentCrashPackets.packets) == recentCrashPacketsSize {
+		copy(recentCrashPackets.packets, recentCrashPackets.packets[1:])
+		recentCrashPackets.packets = recentCrashPackets.packets[:recentCrashPacketsSize-1]
+	}
+	recentCrashPackets.packets = append(recentCrashPackets.packets, packet)
+}
+
+// getRecentCrashPackets returns the latest crash reports, oldest first.
+func getRecentCrashPackets() []*raven.Packet {
+	recentCrashPackets.Lock()
+	defer recentCrashPackets.Unlock()
+	return append([]*raven.Packet(nil), recentCrashPackets.packets...)
+}
+
+const httpSupportBundlePath = "/debug/logs/bundle"
+
+// handleSupportBundle serves a support bundle.
+func handleSupportBundle(w http.ResponseWriter, r *http.Request) {
+	var opts SupportBundleOptions
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", removePeriods(program)+".bundle.zip"))
+	if _, err := WriteSupportBundle(w, opts); err != nil {
+		Warningf(r.Context(), "unable to write support bundle: %s", err)
+	}
+}
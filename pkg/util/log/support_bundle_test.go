@@ -0,0 +1,175 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	raven "github.com/getsentry/raven-go"
+	"golang.org/x/net/context"
+)
+
+func readBundle(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestSupportBundle(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	Infof(context.Background(), "the password of %s is %s", "alice", Unsafe{V: "hunter2"})
+	Infof(context.Background(), "the key is %s", Unsafe{V: "\u203ahunter3"})
+	recordCrashPacket(raven.NewPacket("boom"))
+
+	var buf bytes.Buffer
+	manifest, err := WriteSupportBundle(&buf, SupportBundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := readBundle(t, buf.Bytes())
+
+	var decoded SupportBundleManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Files) != len(manifest.Files) {
+		t.Errorf("unexpected manifest %+v", decoded)
+	}
+	var logs []string
+	for _, f := range decoded.Files {
+		if _, ok := files[f.Name]; !ok {
+			t.Errorf("%s is listed in the manifest but missing", f.Name)
+		}
+		if strings.HasPrefix(f.Name, "logs/") {
+			logs = append(logs, files[f.Name])
+		}
+	}
+	for _, name := range []string{"config.json", "goroutines.txt", "heap.pprof"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in the bundle, got %+v", name, decoded.Files)
+		}
+	}
+	if !strings.Contains(files["goroutines.txt"], "TestSupportBundle") {
+		t.Errorf("expected the stacks of the test, got %s", files["goroutines.txt"])
+	}
+
+	var crashReport bool
+	for name, contents := range files {
+		if strings.HasPrefix(name, "crash_reports/") && strings.Contains(contents, "boom") {
+			crashReport = true
+		}
+	}
+	if !crashReport {
+		t.Errorf("expected the crash report in the bundle, got %+v", decoded.Files)
+	}
+
+	if len(logs) == 0 {
+		t.Fatalf("expected log files in the bundle, got %+v", decoded.Files)
+	}
+	all := strings.Join(logs, "")
+	if !strings.Contains(all, "[config] binary: ") {
+		t.Errorf("expected the headers of the log files to be kept\n%s", all)
+	}
+	for _, expected := range []string{
+		"the password of alice is <redacted>", "the key is <redacted>",
+	} {
+		if !strings.Contains(all, expected) {
+			t.Errorf("expected %q in the log files\n%s", expected, all)
+		}
+	}
+	if strings.Contains(all, "hunter") {
+		t.Errorf("unsafe values leaked in the log files\n%s", all)
+	}
+}
+
+func TestStripUnsafe(t *testing.T) {
+	testCases := []struct {
+		msg, expected string
+	}{
+		{"no values", "no values"},
+		{"a \u2039b\u203a c \u2039d\u203a", "a <redacted> c <redacted>"},
+		{"a \u2039b", "a <redacted>"},
+		{"a \u2039\u2039b\u203a c\u203a", "a <redacted> c\u203a"},
+	}
+	for _, tc := range testCases {
+		if actual := stripUnsafe(tc.msg); actual != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.msg, tc.expected, actual)
+		}
+	}
+}
+
+func TestSupportBundleSkipsLargeLogFiles(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	defer func(previous bool) {
+		logging.mu.Lock()
+		logging.disableDaemons = previous
+		logging.mu.Unlock()
+	}(logging.disableDaemons)
+
+	small := createRotatedLogFiles(t, 2)
+	func() {
+		defer func(previous int64) { LogFileMaxSize = previous }(LogFileMaxSize)
+		LogFileMaxSize = 1
+		Infof(context.Background(), "large entry %s", strings.Repeat("x", 100<<10))
+		Flush()
+	}()
+
+	var budget int64
+	for _, f := range small {
+		budget += f.SizeBytes
+	}
+	var buf bytes.Buffer
+	if _, err := WriteSupportBundle(&buf, SupportBundleOptions{MaxLogBytes: budget}); err != nil {
+		t.Fatal(err)
+	}
+	var logs []string
+	for name, contents := range readBundle(t, buf.Bytes()) {
+		if strings.HasPrefix(name, "logs/") {
+			logs = append(logs, contents)
+		}
+	}
+	if len(logs) != len(small) {
+		t.Fatalf("expected the %d smaller log files in the bundle, got %d", len(small), len(logs))
+	}
+	for _, l := range logs {
+		if strings.Contains(l, "large entry") {
+			t.Errorf("expected the file over the budget to be skipped\n%s", l)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import "fmt"
+
+// syslogLocal is the specification of the syslog sink which writes to
+// the local syslog daemon.
+const syslogLocal = "local"
+
+// syslogMessage returns the message under which an entry is written to
+// syslog, whose header already records the time, the process and, in
+// the priority, the severity.
+func syslogMessage(entry Entry) string {
+	return fmt.Sprintf("%s:%d %s", entry.File, entry.Line, entry.Message)
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !windows
+
+package log
+
+import (
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"time"
+)
+
+// syslogSink writes entries to a syslog daemon. It connects on the first
+// entry, from the goroutine of the buffered sink wrapping it, so that an
+// unreachable daemon does not hold up logging; once connected, the
+// syslog package reconnects as needed. Over TLS, which the syslog
+// package does not support, the sink writes the messages itself and
+// reconnects on the entry following a failure.
+type syslogSink struct {
+	network, addr, tag string
+	w                  *syslog.Writer
+
+	// tls, hostname and conn are used over TLS.
+	tls      *sinkTLSOptions
+	hostname string
+	conn     net.Conn
+}
+
+// newSyslogSink returns a sink writing to the syslog daemon described by
+// spec: syslogLocal for the local daemon, or udp://ADDR, tcp://ADDR or
+// tls://ADDR, in which case tlsOpts configures the connection.
+func newSyslogSink(spec, tag string, tlsOpts *sinkTLSOptions) (Sink, error) {
+	s := &syslogSink{tag: tag}
+	if spec != syslogLocal {
+		var err error
+		if s.network, s.addr, err = splitSinkAddr(spec, "udp"); err != nil {
+			return nil, err
+		}
+	}
+	if s.network == "tls" {
+		s.tls = tlsOpts
+		s.hostname = "-"
+		if h, err := os.Hostname(); err == nil && h != "" {
+			s.hostname = h
+		}
+	}
+	return s, nil
+}
+
+// Output is part of the Sink interface.
+func (s *syslogSink) Output(entry Entry) error {
+	if s.network == "tls" {
+		return s.outputTLS(entry)
+	}
+	if s.w == nil {
+		w, err := syslog.Dial(s.network, s.addr, syslog.LOG_DAEMON|syslog.LOG_INFO, s.tag)
+		if err != nil {
+			return err
+		}
+		s.w = w
+	}
+	msg := syslogMessage(entry)
+	switch entry.Severity {
+	case Severity_FATAL:
+		return s.w.Crit(msg)
+	case Severity_ERROR:
+		return s.w.Err(msg)
+	case Severity_WARNING:
+		return s.w.Warning(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+// outputTLS writes an entry in the format of RFC 5424, framed by its
+// length as per RFC 5425.
+func (s *syslogSink) outputTLS(entry Entry) error {
+	if s.conn == nil {
+		conn, err := dialTLS(s.addr, s.tls)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	var sev syslog.Priority
+	switch entry.Severity {
+	case Severity_FATAL:
+		sev = syslog.LOG_CRIT
+	case Severity_ERROR:
+		sev = syslog.LOG_ERR
+	case Severity_WARNING:
+		sev = syslog.LOG_WARNING
+	default:
+		sev = syslog.LOG_INFO
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		syslog.LOG_DAEMON|sev, time.Unix(0, entry.Time).UTC().Format(time.RFC3339Nano),
+		s.hostname, s.tag, os.Getpid(), syslogMessage(entry))
+	if _, err := fmt.Fprintf(s.conn, "%d %s", len(msg), msg); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	sink, err := newSyslogSink("udp://"+pc.LocalAddr().String(), "crdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Output(Entry{Severity: Severity_WARNING, File: "f.go", Line: 9, Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pc.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The priority is that of LOG_DAEMON and LOG_WARNING.
+	msg := string(b[:n])
+	if !strings.HasPrefix(msg, "<28>") || !strings.Contains(msg, " crdb[") ||
+		!strings.HasSuffix(msg, "f.go:9 hello\n") {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	if _, err := newSyslogSink("sctp://127.0.0.1:1", "crdb", nil); err == nil {
+		t.Error("expected an error for an unknown network")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// teeSinkBufferSize is the number of entries buffered for each
+// destination of a tee sink.
+const teeSinkBufferSize = 1000
+
+// teeSink delivers every entry to each of its destinations. Each
+// destination is written to by a goroutine of its own, from a buffer of
+// its own, so that a destination which fails or stalls never delays or
+// prevents the delivery to the others: once the buffer of a destination
+// is full, its entries are dropped and accounted for in its status.
+type teeSink struct {
+	dests []*teeDest
+}
+
+// teeDest is a destination of a tee sink.
+type teeDest struct {
+	health  sinkHealth
+	sink    logSink
+	entries chan []byte
+	stopper chan struct{}
+	done    chan struct{}
+}
+
+func newTeeSink(sinks []logSink, bufferSize int) *teeSink {
+	t := &teeSink{}
+	for _, sink := range sinks {
+		d := &teeDest{
+			health:  sinkHealth{name: sink.String()},
+			sink:    sink,
+			entries: make(chan []byte, bufferSize),
+			stopper: make(chan struct{}),
+			done:    make(chan struct{}),
+		}
+		go d.run()
+		t.dests = append(t.dests, d)
+	}
+	return t
+}
+
+func (t *teeSink) String() string {
+	names := make([]string, len(t.dests))
+	for i, d := range t.dests {
+		names[i] = d.sink.String()
+	}
+	return "tee:" + strings.Join(names, "+")
+}
+
+// errTeeSinkFull is returned when an entry is dropped for a destination
+// of a tee sink which does not keep up.
+var errTeeSinkFull = errors.New("log: tee buffer full, entry dropped")
+
+// write hands the entry over to every destination. It returns an error
+// if the entry was dropped for any of them, after handing it over to the
+// others.
+func (t *teeSink) write(data []byte) error {
+	var err error
+	for _, d := range t.dests {
+		select {
+		case d.entries <- append([]byte(nil), data...):
+		default:
+			d.health.recordDropped()
+			err = errTeeSinkFull
+		}
+	}
+	return err
+}
+
+func (t *teeSink) statuses() []SinkStatus {
+	res := make([]SinkStatus, len(t.dests))
+	for i, d := range t.dests {
+		res[i] = d.health.status(d.sink.String(), len(d.entries))
+	}
+	return res
+}
+
+// close stops the sink. Buffered entries are discarded.
+func (t *teeSink) close() {
+	for _, d := range t.dests {
+		close(d.stopper)
+		<-d.done
+	}
+}
+
+func (d *teeDest) run() {
+	defer close(d.done)
+	setProfilerLabels(profilerLabelSink)
+	for {
+		select {
+		case data := <-d.entries:
+			if err := d.sink.write(data); err != nil {
+				d.health.recordError(err)
+				d.health.recordDropped()
+				continue
+			}
+			d.health.setConnected(true)
+			d.health.recordSent(len(data))
+		case <-d.stopper:
+			return
+		}
+	}
+}
+
+// makeTeeSink returns a tee sink to the destinations named in the
+// comma-separated list spec: "stderr", "spill", "file:PATH" or
+// "fifo:PATH".
+func makeTeeSink(spec string) (*teeSink, error) {
+	var sinks []logSink
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "stderr":
+			sinks = append(sinks, stderrSink{})
+		case name == "spill":
+			dir, err := logDir.get()
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, newSpillFileSink(filepath.Join(dir, spillFileName())))
+		case strings.HasPrefix(name, "file:"):
+			sinks = append(sinks, newSpillFileSink(strings.TrimPrefix(name, "file:")))
+		case strings.HasPrefix(name, "fifo:"):
+			sinks = append(sinks, newFIFOSink(strings.TrimPrefix(name, "fifo:"), fifoSinkBufferSize))
+		default:
+			return nil, fmt.Errorf("unknown tee destination %q, expected stderr, spill, file:PATH or fifo:PATH", name)
+		}
+	}
+	for i := range sinks {
+		sinks[i] = maybeChaos(sinks[i])
+	}
+	return newTeeSink(sinks, teeSinkBufferSize), nil
+}
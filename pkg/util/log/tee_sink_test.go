@@ -0,0 +1,94 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stalledSink blocks every write until it is released.
+type stalledSink struct {
+	release chan struct{}
+}
+
+func (s *stalledSink) String() string { return "stalled" }
+
+func (s *stalledSink) write(data []byte) error {
+	<-s.release
+	return nil
+}
+
+// chanSink sends the entries written to it on a channel.
+type chanSink struct {
+	entries chan string
+}
+
+func (s *chanSink) String() string { return "chan" }
+
+func (s *chanSink) write(data []byte) error {
+	s.entries <- string(data)
+	return nil
+}
+
+func TestTeeSink(t *testing.T) {
+	stalled := &stalledSink{release: make(chan struct{})}
+	failing := &testSink{name: "failing", err: errors.New("boom")}
+	healthy := &chanSink{entries: make(chan string, 100)}
+	sink := newTeeSink([]logSink{stalled, failing, healthy}, 2)
+	defer sink.close()
+	defer close(stalled.release)
+
+	if e, a := "tee:stalled+failing+chan", sink.String(); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+
+	// Neither the stalled nor the failing destination delays or prevents
+	// the delivery to the healthy one.
+	const n = 10
+	for i := 0; i < n; i++ {
+		_ = sink.write([]byte(fmt.Sprintf("entry %d\n", i)))
+		select {
+		case e := <-healthy.entries:
+			if expected := fmt.Sprintf("entry %d\n", i); e != expected {
+				t.Errorf("expected %q, got %q", expected, e)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("entry %d not delivered", i)
+		}
+	}
+
+	statuses := sink.statuses()
+	// The goroutine of the stalled destination holds one entry, and two
+	// are buffered.
+	if s := statuses[0]; s.Dropped < n-3 || s.Backlog != 2 {
+		t.Errorf("expected at least %d dropped entries and a backlog of 2, got %+v", n-3, s)
+	}
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		s := sink.statuses()[1]
+		if s.Dropped == n && s.LastError == "boom" && !s.Connected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d failed entries, got %+v", n, s)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if s := statuses[2]; s.Dropped != 0 || !s.Connected || s.BytesSent == 0 {
+		t.Errorf("expected all entries to be delivered, got %+v", s)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxInternedTemplates bounds the number of message templates interned
+// by the process. The entries using other templates are written in full.
+const maxInternedTemplates = 1000
+
+// templateDefPrefix prefixes the message of the entries defining a
+// message template in the log files written in the crdb-v1-interned
+// format. The prefix is followed by the ID of the template, a colon and
+// its quoted format string. The log file header defines all the templates
+// known when the file is created, and the templates used for the first
+// time later on are defined just before their first use.
+const templateDefPrefix = "[config] message template "
+
+// The message of an entry using a template is its ID between
+// internedOpen and internedClose, followed by a JSON array of strings:
+// the log tags preceding the formatted message, then the rendition of
+// each argument by its verb.
+const (
+	internedOpen  = "⟦"
+	internedClose = "⟧ "
+)
+
+// messageTemplate is a format string split around its verbs: the format
+// string is literals[0] verbs[0] literals[1] ... verbs[n-1] literals[n].
+type messageTemplate struct {
+	literals []string
+	verbs    []string
+}
+
+// parseTemplate splits a format string around its verbs. The boolean
+// return value is false if the format string can't be interned, because
+// a verb does not consume exactly one argument.
+func parseTemplate(format string) (messageTemplate, bool) {
+	var t messageTemplate
+	var lit []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			lit = append(lit, format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			lit = append(lit, '%')
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.", format[j]) >= 0 {
+			j++
+		}
+		// Explicit argument indexes and widths or precisions taken from
+		// the arguments are not supported.
+		if j == len(format) || !isVerb(format[j]) {
+			return messageTemplate{}, false
+		}
+		t.literals = append(t.literals, string(lit))
+		t.verbs = append(t.verbs, format[i:j+1])
+		lit = lit[:0]
+		i = j
+	}
+	t.literals = append(t.literals, string(lit))
+	return t, true
+}
+
+func isVerb(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// expand returns the message formatted by the template, given the
+// rendition of each of its arguments.
+func (t messageTemplate) expand(rendered []string) string {
+	var buf bytes.Buffer
+	for i, v := range rendered {
+		buf.WriteString(t.literals[i])
+		buf.WriteString(v)
+	}
+	buf.WriteString(t.literals[len(t.literals)-1])
+	return buf.String()
+}
+
+// internedTemplate is a template interned by the process.
+type internedTemplate struct {
+	id int
+	messageTemplate
+}
+
+// formatTemplateDef returns the message of the entry defining the
+// template of the given ID and format string.
+func formatTemplateDef(id int, format string) string {
+	return fmt.Sprintf("%s%d: %s\n", templateDefPrefix, id, strconv.Quote(format))
+}
+
+// formatInterned returns the rendition of an entry, whose message was
+// formatted from format and args, in the crdb-v1-interned format,
+// preceded by the definition of its template if it is used for the first
+// time. The boolean return value is false if the entry can't be interned
+// and is to be written in full: because its format string or arguments
+// can't be interned, or because its message does not match them, after
+// changes by interceptors for instance. l.mu is held.
+func (l *loggingT) formatInterned(
+	entry Entry, stacks []byte, format string, args []interface{},
+) (*buffer, bool) {
+	if format == "" || len(stacks) > 0 {
+		return nil, false
+	}
+	t, known := l.templates[format]
+	if !known {
+		mt, ok := parseTemplate(format)
+		if !ok || len(l.templates) >= maxInternedTemplates {
+			return nil, false
+		}
+		t = &internedTemplate{messageTemplate: mt}
+	}
+	if len(args) != len(t.verbs) {
+		return nil, false
+	}
+	parts := make([]string, len(args)+1)
+	for i, arg := range args {
+		parts[i+1] = fmt.Sprintf(t.verbs[i], arg)
+	}
+	formatted := t.expand(parts[1:])
+	if !strings.HasSuffix(entry.Message, formatted) {
+		return nil, false
+	}
+	parts[0] = entry.Message[:len(entry.Message)-len(formatted)]
+	for _, p := range parts {
+		// JSON does not preserve invalid UTF-8.
+		if !utf8.ValidString(p) {
+			return nil, false
+		}
+	}
+	encoded, err := json.Marshal(parts)
+	if err != nil {
+		return nil, false
+	}
+
+	var buf *buffer
+	if !known {
+		if l.templates == nil {
+			l.templates = make(map[string]*internedTemplate)
+		}
+		l.templateFormats = append(l.templateFormats, format)
+		t.id = len(l.templateFormats)
+		l.templates[format] = t
+		def := entry
+		def.Message = formatTemplateDef(t.id, format)
+		buf = formatLogEntry(def, nil, nil)
+	}
+	entry.Message = internedOpen + strconv.Itoa(t.id) + internedClose + string(encoded)
+	b := formatLogEntry(entry, nil, nil)
+	if buf == nil {
+		return b, true
+	}
+	_, _ = buf.Write(b.Bytes())
+	l.putBuffer(b)
+	return buf, true
+}
+
+// defineTemplate records the definition of a template, the message of
+// a log file entry stripped of templateDefPrefix.
+func (d *EntryDecoder) defineTemplate(def string) error {
+	i := strings.Index(def, ": ")
+	if i < 0 {
+		return fmt.Errorf("malformed message template definition: %q", def)
+	}
+	id, err := strconv.Atoi(def[:i])
+	if err != nil {
+		return fmt.Errorf("malformed message template ID: %s", err)
+	}
+	format, err := strconv.Unquote(def[i+2:])
+	if err != nil {
+		return fmt.Errorf("malformed message template: %s", err)
+	}
+	t, ok := parseTemplate(format)
+	if !ok {
+		return fmt.Errorf("unsupported message template: %q", format)
+	}
+	if d.templates == nil {
+		d.templates = make(map[int]messageTemplate)
+	}
+	d.templates[id] = t
+	return nil
+}
+
+// expandInterned returns the message of an entry using a template
+// defined earlier in the log file, given its interned rendition. The
+// boolean return value is false if msg is not the rendition of such an
+// entry.
+func (d *EntryDecoder) expandInterned(msg string) (string, bool) {
+	if !strings.HasPrefix(msg, internedOpen) {
+		return "", false
+	}
+	i := strings.Index(msg, internedClose)
+	if i < 0 {
+		return "", false
+	}
+	id, err := strconv.Atoi(msg[len(internedOpen):i])
+	if err != nil {
+		return "", false
+	}
+	t, ok := d.templates[id]
+	if !ok {
+		return "", false
+	}
+	var parts []string
+	if err := json.Unmarshal([]byte(msg[i+len(internedClose):]), &parts); err != nil ||
+		len(parts) != len(t.verbs)+1 {
+		return "", false
+	}
+	return parts[0] + t.expand(parts[1:]), true
+}
@@ -0,0 +1,133 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestParseTemplate(t *testing.T) {
+	testCases := []struct {
+		format   string
+		literals []string
+		verbs    []string
+		ok       bool
+	}{
+		{"plain", []string{"plain"}, nil, true},
+		{"%d items in %s", []string{"", " items in ", ""}, []string{"%d", "%s"}, true},
+		{"100%% of %+v.", []string{"100% of ", "."}, []string{"%+v"}, true},
+		{"%x %5.2f", []string{"", " ", ""}, []string{"%x", "%5.2f"}, true},
+		{"%[1]d", nil, nil, false},
+		{"%*d", nil, nil, false},
+		{"trailing %", nil, nil, false},
+		{"%é", nil, nil, false},
+	}
+	for _, tc := range testCases {
+		tpl, ok := parseTemplate(tc.format)
+		if ok != tc.ok {
+			t.Errorf("%q: expected %t, got %t", tc.format, tc.ok, ok)
+			continue
+		}
+		if ok && (!reflect.DeepEqual(tpl.literals, tc.literals) || !reflect.DeepEqual(tpl.verbs, tc.verbs)) {
+			t.Errorf("%q: expected %q and %q, got %+v", tc.format, tc.literals, tc.verbs, tpl)
+		}
+	}
+}
+
+func TestInternedLogFile(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+
+	setFlags()
+	logging.fileFormat = formatCrdbV1Interned
+	defer func() {
+		logging.mu.Lock()
+		defer logging.mu.Unlock()
+		logging.fileFormat = formatCrdbV1
+		logging.templates = nil
+		logging.templateFormats = nil
+	}()
+
+	ctx := WithLogTag(context.Background(), "n", 1)
+	Infof(ctx, "%d items in %s", 3, "/path")
+	Infof(ctx, "%d items in %s", 4, "/other")
+	Info(ctx, "not a template")
+	Flush()
+
+	readLog := func() (string, []string) {
+		contents, err := ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewEntryDecoder(bytes.NewReader(contents))
+		var messages []string
+		for {
+			var e Entry
+			if err := d.Decode(&e); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			messages = append(messages, e.Message)
+		}
+		return string(contents), messages
+	}
+	contains := func(messages []string, msg string) bool {
+		for _, m := range messages {
+			if m == msg {
+				return true
+			}
+		}
+		return false
+	}
+
+	contents, messages := readLog()
+	if n := strings.Count(contents, templateDefPrefix); n != 1 {
+		t.Errorf("expected the template to be defined once, got %d definitions\n%s", n, contents)
+	}
+	if !strings.Contains(contents, "/path") || strings.Contains(contents, "3 items in /path") {
+		t.Errorf("expected the arguments to be written apart from the template\n%s", contents)
+	}
+	for _, msg := range []string{"[n1] 3 items in /path", "[n1] 4 items in /other", "[n1] not a template"} {
+		if !contains(messages, msg) {
+			t.Errorf("expected %q to be decoded, got %q", msg, messages)
+		}
+	}
+
+	// The header of new files defines the templates known so far.
+	logging.mu.Lock()
+	err := logging.file.(*syncBuffer).rotateFile(time.Now())
+	logging.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	Infof(ctx, "%d items in %s", 5, "/new")
+	Flush()
+	contents, messages = readLog()
+	if n := strings.Count(contents, templateDefPrefix); n != 1 {
+		t.Errorf("expected the template to be defined in the header, got %d definitions\n%s", n, contents)
+	}
+	if !contains(messages, "[n1] 5 items in /new") {
+		t.Errorf("expected the entry to be decoded, got %q", messages)
+	}
+}
@@ -18,6 +18,8 @@ package log
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -27,6 +29,19 @@ import (
 	"golang.org/x/net/trace"
 )
 
+// zipkinErrorEvery rate-limits the reports of the errors of the zipkin
+// collectors, which recur for as long as a collector is unreachable.
+var zipkinErrorEvery = Every(time.Minute)
+
+func init() {
+	tracing.SetZipkinErrorLogger(func(keyvals ...interface{}) {
+		if zipkinErrorEvery.ShouldLog() {
+			Warningf(context.Background(), "zipkin collector: %s",
+				strings.TrimSuffix(fmt.Sprintln(keyvals...), "\n"))
+		}
+	})
+}
+
 // ctxEventLogKey is an empty type for the handle associated with the
 // ctxEventLog value (see context.Value).
 type ctxEventLogKey struct{}
@@ -183,9 +198,10 @@ func ErrEventf(ctx context.Context, format string, args ...interface{}) {
 
 // VEvent either logs a message to the log files (which also outputs to the
 // active trace or event log) or to the trace/event log alone, depending on
-// whether the specified verbosity level is active.
+// whether the specified verbosity level is active, either globally or for
+// the verbosity scope of the context.
 func VEvent(ctx context.Context, level level, msg string) {
-	if VDepth(level, 1) {
+	if VDepth(level, 1) || escalatedVerbosity(ctx) >= level {
 		// Log to INFO (which also logs an event).
 		logDepth(ctx, 1, Severity_INFO, "", []interface{}{msg})
 	} else {
@@ -195,9 +211,10 @@ func VEvent(ctx context.Context, level level, msg string) {
 
 // VEventf either logs a message to the log files (which also outputs to the
 // active trace or event log) or to the trace/event log alone, depending on
-// whether the specified verbosity level is active.
+// whether the specified verbosity level is active, either globally or for
+// the verbosity scope of the context.
 func VEventf(ctx context.Context, level level, format string, args ...interface{}) {
-	if VDepth(level, 1) {
+	if VDepth(level, 1) || escalatedVerbosity(ctx) >= level {
 		// Log to INFO (which also logs an event).
 		logDepth(ctx, 1, Severity_INFO, format, args)
 	} else {
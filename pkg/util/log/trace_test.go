@@ -24,6 +24,7 @@ import (
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
 
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	basictracer "github.com/opentracing/basictracer-go"
 	opentracing "github.com/opentracing/opentracing-go"
 )
@@ -230,3 +231,39 @@ func TestEventLogAndTrace(t *testing.T) {
 		t.Errorf("expected events '%s', got '%s'", elExpected, evStr)
 	}
 }
+
+func TestEntrySpanIDs(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	ctx := WithLogTag(context.Background(), "n", 1)
+	Info(ctx, "untraced")
+	if !contains("[n1] untraced", t) {
+		t.Errorf("expected no span IDs without a span:\n%s", contents())
+	}
+
+	ctx, sp, err := tracing.StartSnowballTrace(ctx, tracing.NewTracer(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sp.Finish()
+	traceID, spanID, ok := tracing.GetSpanIDs(sp)
+	if !ok {
+		t.Fatal("expected a span with IDs")
+	}
+	Info(ctx, "traced")
+	expected := fmt.Sprintf("[n1,trace=%x,span=%x] traced", traceID, spanID)
+	if !contains(expected, t) {
+		t.Errorf("expected %q in:\n%s", expected, contents())
+	}
+	// The IDs are not repeated in the span itself.
+	for _, l := range tracing.GetRecording(sp)[0].Logs {
+		for _, f := range l.Fields {
+			if regexp.MustCompile(`trace=`).MatchString(f.Value) {
+				t.Errorf("unexpected span IDs in the trace: %s", f.Value)
+			}
+		}
+	}
+}
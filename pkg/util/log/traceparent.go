@@ -0,0 +1,82 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// TraceparentHeader is the name of the HTTP header, and of the gRPC
+// metadata key, in which external tracing systems propagate the trace
+// context of a request, as specified by the W3C Trace Context
+// recommendation.
+const TraceparentHeader = "traceparent"
+
+// externalTraceTag is the log tag which carries the ID of the external
+// trace of a request.
+const externalTraceTag = "ext-trace"
+
+// parseTraceparent returns the trace ID of a traceparent header of the
+// form "<version>-<trace-id>-<parent-id>-<flags>". The boolean return
+// value is false if the header is malformed.
+func parseTraceparent(header string) (traceID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	// Future versions may append fields, but keep the first four.
+	if len(parts) < 4 {
+		return "", false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || !isHex(version) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", false
+	}
+	if len(traceID) != 32 || !isHex(traceID) || strings.Trim(traceID, "0") == "" {
+		return "", false
+	}
+	if len(parentID) != 16 || !isHex(parentID) || strings.Trim(parentID, "0") == "" {
+		return "", false
+	}
+	if len(flags) != 2 || !isHex(flags) {
+		return "", false
+	}
+	return traceID, true
+}
+
+// isHex returns true if s only consists of lowercase hexadecimal digits.
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// WithTraceparent returns a context whose log entries are tagged with
+// the ID of the external trace propagated in the traceparent header, so
+// that they can be correlated with the traces of the system which
+// originated the request. ctx is returned unchanged if the header is
+// empty or malformed.
+func WithTraceparent(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+	traceID, ok := parseTraceparent(header)
+	if !ok {
+		return ctx
+	}
+	return WithLogTagStr(ctx, externalTraceTag, traceID)
+}
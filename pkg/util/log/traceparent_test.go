@@ -0,0 +1,59 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testCases := []struct {
+		header string
+		ok     bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{" 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00 ", true},
+		// Future versions can add fields.
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6-00f067aa0ba902b7-01", false},
+		{"garbage", false},
+	}
+	for _, tc := range testCases {
+		id, ok := parseTraceparent(tc.header)
+		if ok != tc.ok || (ok && id != traceID) {
+			t.Errorf("%q: expected %t, got %q, %t", tc.header, tc.ok, id, ok)
+		}
+	}
+}
+
+func TestWithTraceparent(t *testing.T) {
+	ctx := WithLogTag(context.Background(), "n", 1)
+	if c := WithTraceparent(ctx, "invalid"); c != ctx {
+		t.Error("expected malformed headers to be ignored")
+	}
+	ctx = WithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if msg, expected := MakeMessage(ctx, "test", nil),
+		"[n1,ext-trace=4bf92f3577b34da6a3ce929d0e0e4736] test"; msg != expected {
+		t.Errorf("expected %q, got %q", expected, msg)
+	}
+}
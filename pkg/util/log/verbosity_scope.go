@@ -0,0 +1,170 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// VerbosityScope identifies a group of operations, for instance those of
+// the SQL sessions of an application, whose verbosity can be raised with
+// EscalateVerbosity independently of the rest of the process. The zero
+// value is a scope with an empty name.
+type VerbosityScope struct {
+	name atomic.Value // string
+}
+
+// SetName changes the name of the scope, which determines the
+// escalations applying to it.
+func (s *VerbosityScope) SetName(name string) {
+	s.name.Store(name)
+}
+
+// Name returns the name of the scope.
+func (s *VerbosityScope) Name() string {
+	name, _ := s.name.Load().(string)
+	return name
+}
+
+// ctxVerbosityScopeKey is an empty type for the handle associated with
+// the VerbosityScope value (see context.Value).
+type ctxVerbosityScopeKey struct{}
+
+// WithVerbosityScope returns a context in which the VEvent family of
+// functions log to the log files when the verbosity of the scope is
+// escalated to their level.
+func WithVerbosityScope(ctx context.Context, s *VerbosityScope) context.Context {
+	return context.WithValue(ctx, ctxVerbosityScopeKey{}, s)
+}
+
+// escalation is a verbosity level in effect until expiry.
+type escalation struct {
+	level  level
+	expiry time.Time
+}
+
+func (e escalation) String() string {
+	return fmt.Sprintf("%d until %s", e.level, e.expiry.UTC().Format(time.RFC3339))
+}
+
+var escalations struct {
+	// active is the number of entries in m, and maxLevel their highest
+	// level. They are read atomically so that the common case, where
+	// nothing is escalated, remains cheap.
+	active, maxLevel int32
+	syncutil.Mutex
+	m map[string]escalation
+}
+
+// updateEscalationsLocked updates the fields of escalations read
+// atomically after m changed. escalations is locked.
+func updateEscalationsLocked() {
+	var maxLevel level
+	for _, e := range escalations.m {
+		if e.level > maxLevel {
+			maxLevel = e.level
+		}
+	}
+	atomic.StoreInt32(&escalations.active, int32(len(escalations.m)))
+	atomic.StoreInt32(&escalations.maxLevel, int32(maxLevel))
+}
+
+// EscalateVerbosity raises the verbosity of the operations associated
+// with the scopes named name to v for the duration d. A duration which is
+// not positive cancels the escalation. Since V cannot tell which scope an
+// operation belongs to, the messages it guards are logged for all the
+// operations while the escalation lasts. The change is recorded as
+// requested within ctx.
+func EscalateVerbosity(ctx context.Context, name string, v int, d time.Duration) {
+	escalations.Lock()
+	if escalations.m == nil {
+		escalations.m = make(map[string]escalation)
+	}
+	old, ok := escalations.m[name]
+	if ok && !old.expiry.After(time.Now()) {
+		ok = false
+	}
+	oldValue, newValue := "none", "none"
+	if ok {
+		oldValue = old.String()
+	}
+	if d <= 0 {
+		delete(escalations.m, name)
+	} else {
+		e := escalation{level: level(v), expiry: time.Now().Add(d)}
+		escalations.m[name] = e
+		newValue = e.String()
+	}
+	updateEscalationsLocked()
+	escalations.Unlock()
+	recordConfigChange(ctx, fmt.Sprintf("verbosity of application %q", name), oldValue, newValue)
+}
+
+// escalatedVerbosity returns the verbosity to which the scope embedded in
+// ctx, if any, is escalated.
+func escalatedVerbosity(ctx context.Context) level {
+	if atomic.LoadInt32(&escalations.active) == 0 {
+		return 0
+	}
+	s, ok := ctx.Value(ctxVerbosityScopeKey{}).(*VerbosityScope)
+	if !ok {
+		return 0
+	}
+	name := s.Name()
+	escalations.Lock()
+	defer escalations.Unlock()
+	e, ok := escalations.m[name]
+	if !ok {
+		return 0
+	}
+	if time.Now().After(e.expiry) {
+		delete(escalations.m, name)
+		updateEscalationsLocked()
+		return 0
+	}
+	return e.level
+}
+
+// verbosityEscalatedTo returns true if the verbosity of any scope is
+// escalated to v or higher. V, which has no context to tell which scope
+// the operation belongs to, uses it: while a scope is escalated, the
+// verbose messages guarded by V are logged for all the operations, until
+// the escalation expires or is cancelled. VEvent and VEventf only log
+// those of the escalated scope.
+func verbosityEscalatedTo(v level) bool {
+	if atomic.LoadInt32(&escalations.active) == 0 ||
+		level(atomic.LoadInt32(&escalations.maxLevel)) < v {
+		return false
+	}
+	escalations.Lock()
+	defer escalations.Unlock()
+	now := time.Now()
+	escalated := false
+	for name, e := range escalations.m {
+		if now.After(e.expiry) {
+			delete(escalations.m, name)
+		} else if e.level >= v {
+			escalated = true
+		}
+	}
+	updateEscalationsLocked()
+	return escalated
+}
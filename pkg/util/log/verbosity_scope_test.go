@@ -0,0 +1,97 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestEscalateVerbosity(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer logging.swap(logging.newBuffers())
+
+	var scope VerbosityScope
+	scope.SetName("app")
+	ctx := WithVerbosityScope(context.Background(), &scope)
+
+	VEventf(ctx, 2, "before")
+	if contains("before", t) {
+		t.Error("expected the event not to be logged without escalation")
+	}
+
+	EscalateVerbosity(context.Background(), "app", 2, time.Minute)
+	defer EscalateVerbosity(context.Background(), "app", 0, 0)
+	VEventf(ctx, 2, "escalated")
+	VEventf(context.Background(), 2, "unscoped")
+	VEventf(ctx, 3, "too verbose")
+	if !contains("escalated", t) {
+		t.Error("expected the event to be logged once escalated")
+	}
+	if contains("unscoped", t) || contains("too verbose", t) {
+		t.Errorf("unexpected events logged:\n%s", contents())
+	}
+
+	// Renaming the scope moves it out of the escalation.
+	scope.SetName("other")
+	VEventf(ctx, 2, "renamed")
+	if contains("renamed", t) {
+		t.Error("expected the escalation to follow the scope's name")
+	}
+
+	// Escalations expire.
+	scope.SetName("app")
+	EscalateVerbosity(context.Background(), "app", 2, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	VEventf(ctx, 2, "expired")
+	if contains("expired", t) {
+		t.Error("expected the escalation to expire")
+	}
+	if len(escalations.m) != 0 {
+		t.Errorf("expected expired escalations to be removed, found %v", escalations.m)
+	}
+}
+
+func TestEscalateVerbosityV(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	if V(2) {
+		t.Fatal("expected V(2) to be false without escalation")
+	}
+	// V has no context, so it honors the escalations of all scopes.
+	EscalateVerbosity(context.Background(), "app", 2, time.Minute)
+	if !V(2) || V(3) {
+		t.Errorf("expected V to follow the escalation: V(2)=%t V(3)=%t", V(2), V(3))
+	}
+	EscalateVerbosity(context.Background(), "app", 0, 0)
+	if V(2) {
+		t.Error("expected V(2) to be false once the escalation is cancelled")
+	}
+
+	EscalateVerbosity(context.Background(), "app", 2, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if V(2) {
+		t.Error("expected V(2) to be false once the escalation expired")
+	}
+	if len(escalations.m) != 0 {
+		t.Errorf("expected expired escalations to be removed, found %v", escalations.m)
+	}
+}
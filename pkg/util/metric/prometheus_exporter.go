@@ -82,6 +82,17 @@ func (pm *PrometheusExporter) ScrapeRegistry(registry *Registry) {
 			}
 		})
 	}
+	for _, metric := range registry.labeled {
+		metric.EachChild(func(prom PrometheusExportable) {
+			m := prom.ToPrometheusMetric()
+			// The children carry their own labels in addition to the
+			// registry ones.
+			m.Label = append(labels, prom.GetLabels()...)
+
+			family := pm.findOrCreateFamily(prom)
+			family.Metric = append(family.Metric, m)
+		})
+	}
 }
 
 // PrintAsText writes all metrics in the families map to the io.Writer in
@@ -38,6 +38,7 @@ type Registry struct {
 	syncutil.Mutex
 	labels  []*prometheusgo.LabelPair
 	tracked []Iterable
+	labeled []LabeledMetric
 }
 
 // Struct can be implemented by the types of members of a metric
@@ -81,8 +82,19 @@ func (r *Registry) AddMetric(metric Iterable) {
 	}
 }
 
+// AddLabeledMetric adds the passed-in labeled metric to the registry. It
+// is only exported to prometheus.
+func (r *Registry) AddLabeledMetric(metric LabeledMetric) {
+	r.Lock()
+	defer r.Unlock()
+	r.labeled = append(r.labeled, metric)
+	if log.V(2) {
+		log.Infof(context.TODO(), "Added labeled metric: %s (%T)", metric.GetName(), metric)
+	}
+}
+
 // AddMetricStruct examines all fields of metricStruct and adds
-// all Iterable or metricGroup objects to the registry.
+// all Iterable, LabeledMetric or metricGroup objects to the registry.
 func (r *Registry) AddMetricStruct(metricStruct interface{}) {
 	v := reflect.ValueOf(metricStruct)
 	if v.Kind() == reflect.Ptr {
@@ -102,6 +114,8 @@ func (r *Registry) AddMetricStruct(metricStruct interface{}) {
 		switch typ := val.(type) {
 		case Iterable:
 			r.AddMetric(typ)
+		case LabeledMetric:
+			r.AddLabeledMetric(typ)
 		case Struct:
 			r.AddMetricStruct(typ)
 		default:
@@ -0,0 +1,152 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	prometheusgo "github.com/prometheus/client_model/go"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// OverflowLabelValue is the value of the labels of the child which
+// aggregates the values in excess of the cardinality limit of a vector.
+const OverflowLabelValue = "other"
+
+// LabeledMetric is implemented by the metrics made of several children
+// distinguished by the values of their labels. Since the time series
+// database does not support labels, such metrics are only exported to
+// prometheus.
+type LabeledMetric interface {
+	// GetName returns the fully-qualified name of the metric.
+	GetName() string
+	// EachChild calls the given closure with each child of the metric.
+	EachChild(func(PrometheusExportable))
+}
+
+var _ LabeledMetric = &GaugeVec{}
+
+// A GaugeVec is a set of gauges sharing a name, each of which is
+// identified by the values of a fixed list of labels, e.g. the database
+// and table a size relates to.
+//
+// The number of children is bounded by a limit, past which the values
+// are aggregated into a single child whose labels are all set to
+// OverflowLabelValue, so that exporting the vector never produces an
+// unbounded number of series.
+type GaugeVec struct {
+	Metadata
+	labelNames  []string
+	maxChildren func() int
+
+	mu struct {
+		syncutil.Mutex
+		children map[string]*GaugeFloat64
+	}
+}
+
+// NewGaugeVec creates a GaugeVec with the given label names. maxChildren
+// is called on each update to get the cardinality limit of the vector.
+func NewGaugeVec(metadata Metadata, maxChildren func() int, labelNames ...string) *GaugeVec {
+	v := &GaugeVec{
+		Metadata:    metadata,
+		labelNames:  labelNames,
+		maxChildren: maxChildren,
+	}
+	v.mu.children = map[string]*GaugeFloat64{}
+	return v
+}
+
+// Update replaces the children of the vector with the ones built by fn,
+// which calls add with the value of each child and the values of its
+// labels, in the order of the label names of the vector. Values added
+// several times for the same labels are summed. Children are created in
+// the order in which they are added until the limit is reached, so that
+// the callers can ensure that the most significant ones are kept.
+func (v *GaugeVec) Update(fn func(add func(value float64, labelValues ...string))) {
+	limit := v.maxChildren()
+	children := map[string]*GaugeFloat64{}
+	fn(func(value float64, labelValues ...string) {
+		if len(labelValues) != len(v.labelNames) {
+			panic("wrong number of label values")
+		}
+		key := strings.Join(labelValues, "\x00")
+		child, ok := children[key]
+		if !ok && len(children) >= limit {
+			labelValues = make([]string, len(v.labelNames))
+			for i := range labelValues {
+				labelValues[i] = OverflowLabelValue
+			}
+			key = strings.Join(labelValues, "\x00")
+			child, ok = children[key]
+		}
+		if !ok {
+			child = v.newChild(labelValues)
+			children[key] = child
+		}
+		child.Update(child.Value() + value)
+	})
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mu.children = children
+}
+
+func (v *GaugeVec) newChild(labelValues []string) *GaugeFloat64 {
+	md := Metadata{Name: v.Name, Help: v.Help}
+	md.labels = append(md.labels, v.labels...)
+	for i, name := range v.labelNames {
+		md.labels = append(md.labels, &prometheusgo.LabelPair{
+			Name:  proto.String(exportedLabel(name)),
+			Value: proto.String(labelValues[i]),
+		})
+	}
+	return NewGaugeFloat64(md)
+}
+
+// Value returns the value of the child with the given label values, and
+// whether the child exists.
+func (v *GaugeVec) Value(labelValues ...string) (float64, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	child, ok := v.mu.children[strings.Join(labelValues, "\x00")]
+	if !ok {
+		return 0, false
+	}
+	return child.Value(), true
+}
+
+// EachChild calls the closure with each child of the vector, in the order
+// of their label values.
+func (v *GaugeVec) EachChild(f func(PrometheusExportable)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.mu.children))
+	for k := range v.mu.children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	children := make([]*GaugeFloat64, len(keys))
+	for i, k := range keys {
+		children[i] = v.mu.children[k]
+	}
+	v.mu.Unlock()
+
+	for _, child := range children {
+		f(child)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGaugeVec(t *testing.T) {
+	limit := 2
+	v := NewGaugeVec(Metadata{Name: "table.bytes"}, func() int { return limit }, "database", "table")
+
+	v.Update(func(add func(float64, ...string)) {
+		add(10, "db", "a")
+		add(5, "db", "b")
+		add(1, "db", "a")
+		add(3, "db", "c")
+		add(4, "other_db", "d")
+	})
+
+	for _, tc := range []struct {
+		labels []string
+		value  float64
+		exists bool
+	}{
+		{[]string{"db", "a"}, 11, true},
+		{[]string{"db", "b"}, 5, true},
+		{[]string{"db", "c"}, 0, false},
+		{[]string{OverflowLabelValue, OverflowLabelValue}, 7, true},
+	} {
+		value, ok := v.Value(tc.labels...)
+		if ok != tc.exists || value != tc.value {
+			t.Errorf("%v: expected (%f, %t), got (%f, %t)", tc.labels, tc.value, tc.exists, value, ok)
+		}
+	}
+
+	// An update replaces all the children.
+	limit = 10
+	v.Update(func(add func(float64, ...string)) {
+		add(2, "db", "c")
+	})
+	if _, ok := v.Value("db", "a"); ok {
+		t.Errorf("expected child to be removed")
+	}
+	if value, ok := v.Value("db", "c"); !ok || value != 2 {
+		t.Errorf("expected (2, true), got (%f, %t)", value, ok)
+	}
+}
+
+func TestGaugeVecPrometheusExport(t *testing.T) {
+	r := NewRegistry()
+	r.AddLabel("node", "1")
+	v := NewGaugeVec(Metadata{Name: "table.bytes"}, func() int { return 10 }, "database", "table")
+	r.AddMetricStruct(struct{ V *GaugeVec }{v})
+	v.Update(func(add func(float64, ...string)) {
+		add(10, "db", "a")
+		add(20, "db", "b")
+	})
+
+	pe := MakePrometheusExporter()
+	pe.ScrapeRegistry(r)
+	var buf bytes.Buffer
+	if err := pe.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"# TYPE table_bytes gauge\n",
+		`table_bytes{node="1",database="db",table="a"} 10`,
+		`table_bytes{node="1",database="db",table="b"} 20`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+
+	// Labeled metrics are not part of the metrics recorded in the time
+	// series database.
+	r.Each(func(name string, _ interface{}) {
+		t.Errorf("unexpected metric %s", name)
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var sampleRate = settings.RegisterValidatedFloatSetting(
+	"trace.sampling.rate",
+	"fraction of the traces started on each node which are recorded and kept for inspection "+
+		"in crdb_internal.sampled_traces (e.g. 0.001 for 1 in 1000; set to 0 to disable)",
+	0,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("cannot set trace.sampling.rate to %f: must be between 0 and 1", v)
+		}
+		return nil
+	},
+)
+
+var maxSampledTraces = settings.RegisterValidatedIntSetting(
+	"trace.sampling.max_traces",
+	"maximum number of sampled traces kept on each node; the oldest ones are discarded first",
+	100,
+	func(v int64) error {
+		if v < 1 {
+			return errors.Errorf("cannot set trace.sampling.max_traces to %d: must be positive", v)
+		}
+		return nil
+	},
+)
+
+// shouldSample decides whether a new root span is sampled.
+func shouldSample(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+// SampledTrace is the recording of a trace kept by the sampler.
+type SampledTrace struct {
+	TraceID   uint64
+	Operation string
+	StartTime time.Time
+	Duration  time.Duration
+	Spans     []RecordedSpan
+}
+
+// sampledTraces retains the latest sampled traces, oldest first, up to
+// trace.sampling.max_traces of them.
+var sampledTraces struct {
+	syncutil.Mutex
+	traces []SampledTrace
+}
+
+// recordSampledTrace retains the recording of s, a sampled root span
+// which just finished.
+func recordSampledTrace(s *span) {
+	spans := GetRecording(s)
+	if len(spans) == 0 {
+		return
+	}
+	t := SampledTrace{
+		TraceID:   s.TraceID,
+		Operation: s.operation,
+		StartTime: spans[0].StartTime,
+		Duration:  spans[0].Duration,
+		Spans:     spans,
+	}
+	max := int(maxSampledTraces.Get())
+	sampledTraces.Lock()
+	defer sampledTraces.Unlock()
+	sampledTraces.traces = append(sampledTraces.traces, t)
+	if n := len(sampledTraces.traces); n > max {
+		sampledTraces.traces = sampledTraces.traces[n-max:]
+	}
+}
+
+// GetSampledTraces returns the sampled traces retained on this node, in
+// the order in which they finished.
+func GetSampledTraces() []SampledTrace {
+	sampledTraces.Lock()
+	defer sampledTraces.Unlock()
+	return append([]SampledTrace(nil), sampledTraces.traces...)
+}
+
+const httpSampledTracesPath = "/debug/traces/sampled"
+
+// handleSampledTraces prints the sampled traces, the most recent first.
+func handleSampledTraces(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	traces := GetSampledTraces()
+	if len(traces) == 0 {
+		fmt.Fprintf(w, "no sampled traces; the sampling rate is %g (see trace.sampling.rate)\n",
+			sampleRate.Get())
+		return
+	}
+	for i := len(traces) - 1; i >= 0; i-- {
+		t := traces[i]
+		fmt.Fprintf(w, "=== trace %d: %s, started at %s, took %s\n%s\n",
+			t.TraceID, t.Operation, t.StartTime.UTC().Format(time.RFC3339Nano), t.Duration,
+			FormatRecordedSpans(t.Spans))
+	}
+}
+
+func init() {
+	http.Handle(httpSampledTracesPath, http.HandlerFunc(handleSampledTraces))
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+func TestTraceSampling(t *testing.T) {
+	defer func() {
+		sampledTraces.Lock()
+		sampledTraces.traces = nil
+		sampledTraces.Unlock()
+	}()
+	tr := NewTracer()
+
+	// Nothing is sampled by default.
+	if sp := tr.StartSpan("unsampled"); !IsNoopSpan(sp) {
+		t.Error("expected noop span")
+	}
+
+	defer settings.TestingSetFloat(&sampleRate, 1)()
+	defer settings.TestingSetInt(&maxSampledTraces, 2)()
+
+	for _, op := range []string{"a", "b", "c"} {
+		sp := tr.StartSpan(op)
+		if IsNoopSpan(sp) {
+			t.Fatal("expected a sampled span")
+		}
+		sp.LogKV("event", op)
+		child := tr.StartSpan(op+".child", opentracing.ChildOf(sp.Context()))
+		child.LogKV("event", "child of "+op)
+		child.Finish()
+		sp.Finish()
+	}
+
+	// Only the latest traces are kept.
+	traces := GetSampledTraces()
+	if len(traces) != 2 {
+		t.Fatalf("expected 2 traces, got %d", len(traces))
+	}
+	for i, op := range []string{"b", "c"} {
+		if traces[i].Operation != op {
+			t.Errorf("%d: expected operation %s, got %s", i, op, traces[i].Operation)
+		}
+		checkRecordedSpans(t, traces[i].Spans, `
+span `+op+`:
+  event: `+op+`
+span `+op+`.child:
+  event: child of `+op)
+	}
+
+	w := httptest.NewRecorder()
+	handleSampledTraces(w, httptest.NewRequest("GET", httpSampledTracesPath, nil))
+	body := w.Body.String()
+	if i, j := strings.Index(body, ": c,"), strings.Index(body, ": b,"); i < 0 || j < i {
+		t.Errorf("expected the traces, most recent first, got:\n%s", body)
+	}
+}
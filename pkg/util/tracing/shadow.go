@@ -0,0 +1,189 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+	opentracing "github.com/opentracing/opentracing-go"
+	zipkin "github.com/openzipkin/zipkin-go-opentracing"
+)
+
+// shadowTracerManager abstracts over the external tracers to which our
+// spans can be "shadowed".
+type shadowTracerManager interface {
+	Name() string
+	// Close releases the resources of tr, once it is no longer used for new
+	// spans and its spans are finished.
+	Close(tr opentracing.Tracer)
+}
+
+type lightstepManager struct{}
+
+func (lightstepManager) Name() string {
+	return "lightstep"
+}
+
+func (lightstepManager) Close(tr opentracing.Tracer) {
+	// TODO(radu): the background task of the lightstep tracer lives on.
+	// Filed https://github.com/lightstep/lightstep-tracer-go/issues/82.
+}
+
+type zipkinManager struct {
+	collector zipkin.Collector
+}
+
+func (*zipkinManager) Name() string {
+	return "zipkin"
+}
+
+func (m *zipkinManager) Close(tr opentracing.Tracer) {
+	_ = m.collector.Close()
+}
+
+// shadowTracer is an external tracer which receives a copy of the events
+// of our spans, through a "shadow" span maintained inside each of them.
+type shadowTracer struct {
+	opentracing.Tracer
+	manager shadowTracerManager
+	// refs counts the open shadow spans of the tracer, plus one while it is
+	// installed. The tracer is closed once it drops to zero, so that the
+	// spans started before the tracer was replaced are still sent.
+	refs int32
+}
+
+func newShadowTracer(tr opentracing.Tracer, manager shadowTracerManager) *shadowTracer {
+	return &shadowTracer{Tracer: tr, manager: manager, refs: 1}
+}
+
+// tryAcquire takes a reference on st for a new shadow span. It returns
+// false if st is already closed, in which case the span can't use it.
+func (st *shadowTracer) tryAcquire() bool {
+	for {
+		refs := atomic.LoadInt32(&st.refs)
+		if refs == 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&st.refs, refs, refs+1) {
+			return true
+		}
+	}
+}
+
+// release drops a reference on st, taken by tryAcquire or when st was
+// installed, and closes st once the last one is dropped.
+func (st *shadowTracer) release() {
+	if atomic.AddInt32(&st.refs, -1) == 0 {
+		st.manager.Close(st.Tracer)
+	}
+}
+
+// sharesCarrierFormat returns true if the shadow tracer propagates its
+// contexts with the same keys as we do, in which case Inject does not need
+// to be called on it separately.
+func (st *shadowTracer) sharesCarrierFormat() bool {
+	_, ok := st.manager.(lightstepManager)
+	return ok
+}
+
+// spanIDs returns the IDs of the shadow span with the given context, which
+// our span adopts so that the traces can be correlated.
+func (st *shadowTracer) spanIDs(spanCtx opentracing.SpanContext) (traceID, spanID uint64) {
+	switch st.manager.(type) {
+	case lightstepManager:
+		return getLightstepSpanIDs(st.Tracer, spanCtx)
+	case *zipkinManager:
+		zipkinCtx := spanCtx.(zipkin.SpanContext)
+		return zipkinCtx.TraceID.Low, zipkinCtx.SpanID
+	default:
+		panic(fmt.Sprintf("unknown shadow tracer %s", st.manager.Name()))
+	}
+}
+
+// Atomic pointer of type *shadowTracer. We don't use sync.Value because we
+// can't set it to nil.
+var shadowTracerPtr unsafe.Pointer
+
+func getShadowTracer() *shadowTracer {
+	return (*shadowTracer)(atomic.LoadPointer(&shadowTracerPtr))
+}
+
+// updateShadowTracer installs the shadow tracer selected by the cluster
+// settings, lightstep taking precedence over zipkin. The previous one is
+// closed once its open spans are finished.
+func updateShadowTracer() {
+	var st *shadowTracer
+	if token := lightstepToken.Get(); token != "" {
+		st = newShadowTracer(lightstep.NewTracer(lightstep.Options{
+			AccessToken:    token,
+			MaxLogsPerSpan: maxLogsPerSpan,
+			UseGRPC:        true,
+		}), lightstepManager{})
+	} else if addr := zipkinCollector.Get(); addr != "" {
+		var err error
+		if st, err = createZipkinTracer(addr); err != nil {
+			reportZipkinError("msg", "unable to create collector", "addr", addr, "err", err)
+		}
+	}
+	if old := (*shadowTracer)(atomic.SwapPointer(&shadowTracerPtr, unsafe.Pointer(st))); old != nil {
+		old.release()
+	}
+}
+
+// createZipkinTracer creates a tracer which sends the spans, in the
+// zipkin v1 HTTP format also accepted by jaeger, to the collector at the
+// given host:port address.
+func createZipkinTracer(collectorAddr string) (*shadowTracer, error) {
+	collector, err := zipkin.NewHTTPCollector(
+		fmt.Sprintf("http://%s/api/v1/spans", collectorAddr),
+		zipkin.HTTPLogger(zipkin.LoggerFunc(func(keyvals ...interface{}) error {
+			// These are the errors of the collector, e.g. when it can't send
+			// the spans.
+			reportZipkinError(keyvals...)
+			return nil
+		})),
+	)
+	if err != nil {
+		return nil, err
+	}
+	recorder := zipkin.NewRecorder(collector, false /* debug */, "0.0.0.0:0", "cockroach")
+	tr, err := zipkin.NewTracer(recorder)
+	if err != nil {
+		_ = collector.Close()
+		return nil, err
+	}
+	return newShadowTracer(tr, &zipkinManager{collector: collector}), nil
+}
+
+// zipkinErrorLogger reports the errors of the zipkin collectors, given as
+// alternating keys and values. The log package, which depends on this one,
+// sets it with SetZipkinErrorLogger.
+var zipkinErrorLogger atomic.Value // func(keyvals ...interface{})
+
+// SetZipkinErrorLogger sets the function which reports the errors of the
+// zipkin collectors, given as alternating keys and values. The errors are
+// dropped until it is set.
+func SetZipkinErrorLogger(fn func(keyvals ...interface{})) {
+	zipkinErrorLogger.Store(fn)
+}
+
+func reportZipkinError(keyvals ...interface{}) {
+	if fn, ok := zipkinErrorLogger.Load().(func(keyvals ...interface{})); ok {
+		fn(keyvals...)
+	}
+}
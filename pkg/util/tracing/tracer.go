@@ -23,15 +23,12 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
-	"unsafe"
 
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
-	lightstep "github.com/lightstep/lightstep-tracer-go"
 	opentracing "github.com/opentracing/opentracing-go"
 )
 
@@ -67,36 +64,17 @@ var lightstepToken = settings.RegisterStringSetting(
 	"",
 )
 
+var zipkinCollector = settings.RegisterStringSetting(
+	"trace.zipkin.collector",
+	"if set, traces go to the given Zipkin or Jaeger collector (example: '127.0.0.1:9411'); "+
+		"ignored if trace.lightstep.token is set",
+	"",
+)
+
 // We don't call OnChange inline above because it causes an "initialization
 // loop" compile error.
-var _ = lightstepToken.OnChange(updateLightstep)
-
-// Atomic pointer of type *opentracing.Tracer which itself points to a lightstep
-// tracer. We don't use sync.Value because we can't set it to nil.
-var lightstepPtr unsafe.Pointer
-
-func updateLightstep() {
-	if token := lightstepToken.Get(); token == "" {
-		// TODO(radu): if we had a lightstep tracer allocated, its background task
-		// will live on.
-		// Filed https://github.com/lightstep/lightstep-tracer-go/issues/82.
-		atomic.StorePointer(&lightstepPtr, nil)
-	} else {
-		lsTr := lightstep.NewTracer(lightstep.Options{
-			AccessToken:    token,
-			MaxLogsPerSpan: maxLogsPerSpan,
-			UseGRPC:        true,
-		})
-		atomic.StorePointer(&lightstepPtr, unsafe.Pointer(&lsTr))
-	}
-}
-
-func getLightstep() opentracing.Tracer {
-	if ptr := atomic.LoadPointer(&lightstepPtr); ptr != nil {
-		return *(*opentracing.Tracer)(ptr)
-	}
-	return nil
-}
+var _ = lightstepToken.OnChange(updateShadowTracer)
+var _ = zipkinCollector.OnChange(updateShadowTracer)
 
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
@@ -106,11 +84,11 @@ func getLightstep() opentracing.Tracer {
 //    the Snowball baggage and can be started explicitly as well. Recorded
 //    events can be retrieved at any time.
 //
-//  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
-//    span inside each of our spans.
+//  - lightstep and zipkin traces. This is implemented by maintaining a
+//    "shadow" span of the external tracer inside each of our spans.
 //
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
-// lightstep disabled) because of its recording capability (snowball
+// the shadow tracer disabled) because of its recording capability (snowball
 // tracing needs to work in all cases).
 //
 // Tracer is currently stateless so we could have a single instance; however,
@@ -118,14 +96,14 @@ func getLightstep() opentracing.Tracer {
 // state.
 type Tracer struct {
 	// Preallocated noopSpan, used to avoid creating spans when we are not using
-	// x/net/trace or lightstep and we are not recording.
+	// x/net/trace or a shadow tracer and we are not recording.
 	noopSpan noopSpan
 }
 
 var _ opentracing.Tracer = &Tracer{}
 
 // NewTracer creates a Tracer. The cluster settings control whether
-// we trace to net/trace and/or lightstep.
+// we trace to net/trace and/or a shadow tracer (lightstep or zipkin).
 func NewTracer() opentracing.Tracer {
 	t := &Tracer{}
 	t.noopSpan.tracer = t
@@ -198,9 +176,10 @@ func (t *Tracer) StartSpan(
 	}
 
 	netTrace := enableNetTrace.Get()
-	lsTr := getLightstep()
+	shadowTr := getShadowTracer()
+	rate := sampleRate.Get()
 
-	if len(opts) == 0 && !netTrace && lsTr == nil {
+	if len(opts) == 0 && !netTrace && shadowTr == nil && rate == 0 {
 		return &t.noopSpan
 	}
 
@@ -243,16 +222,27 @@ func (t *Tracer) StartSpan(
 		// TODO(radu): can we do something for multiple references?
 		break
 	}
-	if hasParent && parentCtx.lightstep == nil {
-		// If a lightstep tracer was configured, don't use it if the parent span
+	// A fraction of the root spans are sampled: their traces are recorded,
+	// including on the other nodes, and retained once they finish.
+	sampled := !hasParent && shouldSample(rate)
+	if sampled {
+		recordingGroup = new(spanGroup)
+		recordingType = SnowballRecording
+	}
+	if hasParent && parentCtx.shadowTr != shadowTr {
+		// If a shadow tracer was configured, don't use it if the parent span
 		// isn't using it.
-		lsTr = nil
+		shadowTr = nil
+	}
+	if shadowTr != nil && !shadowTr.tryAcquire() {
+		// The shadow tracer was replaced and closed in the meantime.
+		shadowTr = nil
 	}
 
 	// If tracing is disabled, the Recordable option wasn't passed, and we're not
 	// part of a recording or snowball trace, avoid overhead and return a noop
 	// span.
-	if !recordable && recordingGroup == nil && lsTr == nil && !netTrace {
+	if !recordable && recordingGroup == nil && shadowTr == nil && !netTrace {
 		return &t.noopSpan
 	}
 
@@ -260,36 +250,39 @@ func (t *Tracer) StartSpan(
 		tracer:    t,
 		operation: operationName,
 		startTime: sso.StartTime,
+		sampled:   sampled,
 	}
 	if s.startTime.IsZero() {
 		s.startTime = time.Now()
 	}
 	s.mu.duration = -1
 
-	// If we are using lightstep, we create a new lightstep span and use the
+	// If we are using a shadow tracer, we create a new shadow span and use the
 	// metadata (TraceID, SpanID, Baggage) from that span. Otherwise, we generate
 	// our own IDs.
-	if lsTr != nil {
-		// Create the shadow lightstep span.
-		var lsOpts []opentracing.StartSpanOption
-		// Replicate the options, using the lightstep context in the reference.
+	if shadowTr != nil {
+		// Create the shadow span.
+		var shadowOpts []opentracing.StartSpanOption
+		// Replicate the options, using the shadow context in the reference.
 		if !sso.StartTime.IsZero() {
-			lsOpts = append(lsOpts, opentracing.StartTime(sso.StartTime))
+			shadowOpts = append(shadowOpts, opentracing.StartTime(sso.StartTime))
 		}
 		if sso.Tags != nil {
-			lsOpts = append(lsOpts, opentracing.Tags(sso.Tags))
+			shadowOpts = append(shadowOpts, opentracing.Tags(sso.Tags))
 		}
 		if hasParent {
-			if parentCtx.lightstep == nil {
-				panic("lightstep span derived from non-lightstep span")
+			if parentCtx.shadowCtx == nil {
+				panic(fmt.Sprintf("%s span derived from non-%s span",
+					shadowTr.manager.Name(), shadowTr.manager.Name()))
 			}
-			lsOpts = append(lsOpts, opentracing.SpanReference{
+			shadowOpts = append(shadowOpts, opentracing.SpanReference{
 				Type:              parentType,
-				ReferencedContext: parentCtx.lightstep,
+				ReferencedContext: parentCtx.shadowCtx,
 			})
 		}
-		s.lightstep = lsTr.StartSpan(operationName, lsOpts...)
-		s.TraceID, s.SpanID = getLightstepSpanIDs(lsTr, s.lightstep.Context())
+		s.shadowTr = shadowTr
+		s.shadowSpan = shadowTr.StartSpan(operationName, shadowOpts...)
+		s.TraceID, s.SpanID = shadowTr.spanIDs(s.shadowSpan.Context())
 		if hasParent && s.TraceID != parentCtx.TraceID {
 			panic(fmt.Sprintf(
 				"TraceID doesn't match between parent (%d) and child (%d) spans",
@@ -339,8 +332,9 @@ func (t *Tracer) StartSpan(
 		s.SetTag(k, v)
 	}
 
-	if netTrace || lsTr != nil {
-		// Copy baggage items to tags so they show up in the Lightstep UI or x/net/trace.
+	if netTrace || shadowTr != nil {
+		// Copy baggage items to tags so they show up in the shadow tracer's UI or
+		// x/net/trace.
 		for k, v := range s.mu.Baggage {
 			s.SetTag(k, v)
 		}
@@ -382,6 +376,11 @@ func (t *Tracer) Inject(
 		mapWriter.Set(prefixBaggage+k, v)
 	}
 
+	if sc.shadowTr != nil && !sc.shadowTr.sharesCarrierFormat() {
+		// Inject the shadow context as well, so that the remote node can
+		// continue the shadow trace.
+		return sc.shadowTr.Inject(sc.shadowCtx, format, carrier)
+	}
 	return nil
 }
 
@@ -432,12 +431,13 @@ func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.S
 		return noopSpanContext{}, nil
 	}
 
-	if lightstep := getLightstep(); lightstep != nil {
-		// Extract the lightstep context. For this to work, our key-value "schema"
-		// must match lightstep's exactly (otherwise we get an error here).
-		sc.lightstep, err = lightstep.Extract(format, carrier)
-		if err != nil {
-			return noopSpanContext{}, err
+	if shadowTr := getShadowTracer(); shadowTr != nil {
+		// Extract the shadow context. This only works if the remote node uses
+		// the same shadow tracer; otherwise the shadow context is ignored and
+		// the trace is not continued in the shadow tracer.
+		if shadowCtx, err := shadowTr.Extract(format, carrier); err == nil {
+			sc.shadowTr = shadowTr
+			sc.shadowCtx = shadowCtx
 		}
 	}
 	return &sc, nil
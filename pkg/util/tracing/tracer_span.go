@@ -42,8 +42,9 @@ type spanMeta struct {
 type spanContext struct {
 	spanMeta
 
-	// Underlying lightstep span context, if using lightstep.
-	lightstep opentracing.SpanContext
+	// Underlying shadow tracer and span context, if using a shadow tracer.
+	shadowTr  *shadowTracer
+	shadowCtx opentracing.SpanContext
 
 	// If set, all spans derived from this context are being recorded as a group.
 	recordingGroup *spanGroup
@@ -84,11 +85,14 @@ type span struct {
 
 	// x/net/trace.Trace instance; nil if not tracing to x/net/trace.
 	netTr trace.Trace
-	// "Shadow" lightstep span; nil if not using lightstep.
-	lightstep opentracing.Span
+	// Shadow tracer and span; nil if not using a shadow tracer.
+	shadowTr   *shadowTracer
+	shadowSpan opentracing.Span
 
 	operation string
 	startTime time.Time
+	// sampled is set on the root spans picked by the trace sampler.
+	sampled bool
 
 	// Atomic flag used to avoid taking the mutex in the hot path.
 	recording int32
@@ -252,6 +256,17 @@ func ClearRecordedLogs(os opentracing.Span) {
 	}
 }
 
+// GetSpanIDs returns the IDs of the trace and of the span. The boolean
+// return value is false if the span does not have IDs, for instance
+// because it is a noop span.
+func GetSpanIDs(os opentracing.Span) (traceID, spanID uint64, ok bool) {
+	sp, ok := os.(*span)
+	if !ok {
+		return 0, 0, false
+	}
+	return sp.TraceID, sp.SpanID, true
+}
+
 // IsNoopSpan returns true if events for this span are just dropped. This is the
 // case when tracing is disable and we're not recording.
 func IsNoopSpan(s opentracing.Span) bool {
@@ -273,8 +288,12 @@ func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
 	s.mu.Lock()
 	s.mu.duration = finishTime.Sub(s.startTime)
 	s.mu.Unlock()
-	if s.lightstep != nil {
-		s.lightstep.Finish()
+	if s.sampled {
+		recordSampledTrace(s)
+	}
+	if s.shadowSpan != nil {
+		s.shadowSpan.Finish()
+		s.shadowTr.release()
 	}
 	if s.netTr != nil {
 		s.netTr.Finish()
@@ -293,8 +312,9 @@ func (s *span) Context() opentracing.SpanContext {
 		spanMeta: s.spanMeta,
 		Baggage:  baggageCopy,
 	}
-	if s.lightstep != nil {
-		sc.lightstep = s.lightstep.Context()
+	if s.shadowSpan != nil {
+		sc.shadowTr = s.shadowTr
+		sc.shadowCtx = s.shadowSpan.Context()
 	}
 
 	if s.isRecording() {
@@ -306,8 +326,8 @@ func (s *span) Context() opentracing.SpanContext {
 
 // SetOperationName is part of the opentracing.Span interface.
 func (s *span) SetOperationName(operationName string) opentracing.Span {
-	if s.lightstep != nil {
-		s.lightstep.SetOperationName(operationName)
+	if s.shadowSpan != nil {
+		s.shadowSpan.SetOperationName(operationName)
 	}
 	s.operation = operationName
 	return s
@@ -319,8 +339,8 @@ func (s *span) SetTag(key string, value interface{}) opentracing.Span {
 }
 
 func (s *span) setTagInner(key string, value interface{}, locked bool) opentracing.Span {
-	if s.lightstep != nil {
-		s.lightstep.SetTag(key, value)
+	if s.shadowSpan != nil {
+		s.shadowSpan.SetTag(key, value)
 	}
 	if s.netTr != nil {
 		s.netTr.LazyPrintf("%s:%v", key, value)
@@ -342,8 +362,8 @@ func (s *span) setTagInner(key string, value interface{}, locked bool) opentraci
 
 // LogFields is part of the opentracing.Span interface.
 func (s *span) LogFields(fields ...otlog.Field) {
-	if s.lightstep != nil {
-		s.lightstep.LogFields(fields...)
+	if s.shadowSpan != nil {
+		s.shadowSpan.LogFields(fields...)
 	}
 	if s.netTr != nil {
 		// TODO(radu): when LightStep supports arbitrary fields, we should make
@@ -398,10 +418,10 @@ func (s *span) setBaggageItemLocked(restrictedKey, value string) opentracing.Spa
 	}
 	s.mu.Baggage[restrictedKey] = value
 
-	if s.lightstep != nil {
-		s.lightstep.SetBaggageItem(restrictedKey, value)
+	if s.shadowSpan != nil {
+		s.shadowSpan.SetBaggageItem(restrictedKey, value)
 	}
-	// Also set a tag so it shows up in the Lightstep UI or x/net/trace.
+	// Also set a tag so it shows up in the shadow tracer's UI or x/net/trace.
 	s.setTagInner(restrictedKey, value, true /* locked */)
 	return s
 }
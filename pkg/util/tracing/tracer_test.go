@@ -264,7 +264,60 @@ func TestLightstepContext(t *testing.T) {
 		MaxLogsPerSpan: maxLogsPerSpan,
 		UseGRPC:        true,
 	})
-	atomic.StorePointer(&lightstepPtr, unsafe.Pointer(&lsTr))
+	testShadowContext(t, newShadowTracer(lsTr, lightstepManager{}))
+}
+
+func TestZipkinContext(t *testing.T) {
+	zipkinTr, err := createZipkinTracer("127.0.0.1:65535")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testShadowContext(t, zipkinTr)
+}
+
+type countingManager struct {
+	closed int32
+}
+
+func (*countingManager) Name() string {
+	return "counting"
+}
+
+func (m *countingManager) Close(opentracing.Tracer) {
+	atomic.AddInt32(&m.closed, 1)
+}
+
+// TestShadowTracerRefs verifies that a shadow tracer is closed once it is
+// replaced and its spans are finished, and not used by new spans after.
+func TestShadowTracerRefs(t *testing.T) {
+	m := &countingManager{}
+	st := newShadowTracer(opentracing.NoopTracer{}, m)
+	if !st.tryAcquire() {
+		t.Fatal("expected to acquire the installed tracer")
+	}
+	// The tracer is replaced while its span is open.
+	st.release()
+	if closed := atomic.LoadInt32(&m.closed); closed != 0 {
+		t.Fatalf("expected the tracer to be kept open, closed %d times", closed)
+	}
+	// The span is finished.
+	st.release()
+	if closed := atomic.LoadInt32(&m.closed); closed != 1 {
+		t.Fatalf("expected the tracer to be closed once, closed %d times", closed)
+	}
+	if st.tryAcquire() {
+		t.Fatal("expected the closed tracer not to be acquired")
+	}
+}
+
+// testShadowContext verifies that the context of spans shadowed by st is
+// propagated along with ours.
+func testShadowContext(t *testing.T, st *shadowTracer) {
+	atomic.StorePointer(&shadowTracerPtr, unsafe.Pointer(st))
+	defer func() {
+		atomic.StorePointer(&shadowTracerPtr, nil)
+		st.release()
+	}()
 	tr := NewTracer()
 	s := tr.StartSpan("test")
 
@@ -276,29 +329,36 @@ func TestLightstepContext(t *testing.T) {
 	if err := tr.Inject(s.Context(), opentracing.HTTPHeaders, carrier); err != nil {
 		t.Fatal(err)
 	}
-	traceID, spanID := getLightstepSpanIDs(lsTr, s.(*span).lightstep.Context())
+	traceID, spanID := st.spanIDs(s.(*span).shadowSpan.Context())
 	if traceID == 0 || spanID == 0 {
 		t.Errorf("invalid trace/span IDs: %d %d", traceID, spanID)
 	}
+	if sTraceID, sSpanID, _ := GetSpanIDs(s); sTraceID != traceID || sSpanID != spanID {
+		t.Errorf("expected the IDs of the shadow span %d %d, got %d %d",
+			traceID, spanID, sTraceID, sSpanID)
+	}
 
-	// Extract also extracts the context in lightstep; this will fail if the
-	// contexts are not compatible.
+	// Extract also extracts the shadow context; the shadow span of the child
+	// isn't created otherwise.
 	wireContext, err := tr.Extract(opentracing.HTTPHeaders, carrier)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	s2 := tr.StartSpan("child", opentracing.FollowsFrom(wireContext))
-	s2Ctx := s2.(*span).lightstep.Context()
+	if s2.(*span).shadowSpan == nil {
+		t.Fatalf("expected a %s span", st.manager.Name())
+	}
+	s2Ctx := s2.(*span).shadowSpan.Context()
 
-	traceID2, spanID2 := getLightstepSpanIDs(lsTr, s2Ctx)
+	traceID2, spanID2 := st.spanIDs(s2Ctx)
 
 	if traceID2 != traceID || spanID2 == 0 {
 		t.Errorf("invalid child trace/span IDs: %d %d", traceID2, spanID2)
 	}
 
 	// Verify that the baggage is correct in both the tracer context and in the
-	// lightstep context.
+	// shadow context.
 	for i, spanCtx := range []opentracing.SpanContext{s2.Context(), s2Ctx} {
 		baggage := make(map[string]string)
 		spanCtx.ForeachBaggageItem(func(k, v string) bool {
@@ -309,4 +369,6 @@ func TestLightstepContext(t *testing.T) {
 			t.Errorf("%d: expected baggage %s=%s, got %v", i, testBaggageKey, testBaggageVal, baggage)
 		}
 	}
+	s2.Finish()
+	s.Finish()
 }
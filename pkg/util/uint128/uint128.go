@@ -18,6 +18,8 @@ package uint128
 
 import (
 	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 )
 
 // Uint128 is a big-endian 128 bit unsigned integer which wraps two uint64s.
@@ -59,3 +61,36 @@ func FromBytes(b []byte) Uint128 {
 	lo := binary.BigEndian.Uint64(b[8:])
 	return Uint128{hi, lo}
 }
+
+// FromInts takes in two unsigned 64-bit integers and constructs a Uint128.
+func FromInts(hi uint64, lo uint64) Uint128 {
+	return Uint128{hi, lo}
+}
+
+// Hi returns the 64 most significant bits.
+func (u Uint128) Hi() uint64 {
+	return u.hi
+}
+
+// Lo returns the 64 least significant bits.
+func (u Uint128) Lo() uint64 {
+	return u.lo
+}
+
+// String returns a hexadecimal string representation.
+func (u Uint128) String() string {
+	return hex.EncodeToString(u.GetBytes())
+}
+
+// FromString parses a hexadecimal string as a 128 bit big-endian unsigned
+// integer, as returned by String.
+func FromString(s string) (Uint128, error) {
+	if len(s) > 32 {
+		return Uint128{}, fmt.Errorf("input string %s too large for uint128", s)
+	}
+	b, err := hex.DecodeString(fmt.Sprintf("%032s", s))
+	if err != nil {
+		return Uint128{}, err
+	}
+	return FromBytes(b), nil
+}
@@ -72,3 +72,38 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestString(t *testing.T) {
+	testData := []struct {
+		num Uint128
+		str string
+	}{
+		{Uint128{0, 0}, "00000000000000000000000000000000"},
+		{Uint128{0, 1}, "00000000000000000000000000000001"},
+		{Uint128{1, 255}, "000000000000000100000000000000ff"},
+		{Uint128{18446744073709551615, 18446744073709551615}, "ffffffffffffffffffffffffffffffff"},
+	}
+
+	for _, test := range testData {
+		if s := test.num.String(); s != test.str {
+			t.Errorf("expected %v to be formatted as %s but got %s", test.num, test.str, s)
+		}
+		res, err := FromString(test.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != test.num {
+			t.Errorf("expected %s to be parsed as %v but got %v", test.str, test.num, res)
+		}
+	}
+
+	// Leading zeroes may be omitted.
+	if res, err := FromString("1ff"); err != nil || res != FromInts(0, 511) {
+		t.Errorf("expected 1ff to be parsed as 511, got %v (%v)", res, err)
+	}
+	for _, s := range []string{"xyz", "1000000000000000000000000000000000"} {
+		if _, err := FromString(s); err == nil {
+			t.Errorf("expected %s to be rejected", s)
+		}
+	}
+}